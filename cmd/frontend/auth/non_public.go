@@ -62,6 +62,7 @@ var (
 		router.SignUp:            {},
 		router.SiteInit:          {},
 		router.SignIn:            {},
+		router.SignInMFA:         {},
 		router.SignOut:           {},
 		router.ResetPasswordInit: {},
 		router.ResetPasswordCode: {},
@@ -101,6 +102,12 @@ func AllowAnonymousRequest(req *http.Request) bool {
 		return true
 	}
 
+	// Permission is checked later by verifying the webhook secret of the
+	// code host's external service.
+	if strings.HasPrefix(req.URL.Path, "/.api/webhooks/") {
+		return true
+	}
+
 	apiRouteName := matchedRouteName(req, router.Router())
 	if apiRouteName == router.UI {
 		// Test against UI router. (Some of its handlers inject private data into the title or meta tags.)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/sergi/go-diff/diffmatchpatch"
@@ -52,6 +53,7 @@ func TestGetAndSaveUser(t *testing.T) {
 	}
 
 	unexpectedErr := errors.New("unexpected err")
+	suspendedAt := time.Now()
 
 	oneUser := []userInfo{{
 		user: types.User{ID: 1, Username: "u1"},
@@ -328,6 +330,23 @@ func TestGetAndSaveUser(t *testing.T) {
 				expErr:                     unexpectedErr,
 			}},
 		},
+		{
+			description: "suspended user",
+			mock: mockParams{userInfos: []userInfo{{
+				user:     types.User{ID: 1, Username: "u1", SuspendedAt: &suspendedAt},
+				extAccts: []extsvc.ExternalAccountSpec{ext("st1", "s1", "c1", "s1/u1")},
+				emails:   []string{"u1@example.com"},
+			}}},
+			innerCases: []innerCase{{
+				op:                         getOneUserOp,
+				createIfNotExistIrrelevant: true,
+				expSafeErr:                 "Your Sourcegraph user account is suspended. Ask a site admin for help.",
+				expErr:                     fmt.Errorf("user 1 is suspended"),
+				expSavedExtAccts: map[int32][]extsvc.ExternalAccountSpec{
+					1: {ext("st1", "s1", "c1", "s1/u1")},
+				},
+			}},
+		},
 		{
 			description: "updateErr",
 			mock:        mockParams{updateErr: unexpectedErr, userInfos: oneUser},
@@ -0,0 +1,26 @@
+package authz
+
+import "context"
+
+// CacheStats counts, for a call to an authz provider's RepoPerms method, how many repositories'
+// permissions the provider computed from its own cache (hits) and by asking its code host
+// (misses). It is used to explain repository permission checks to site admins.
+type CacheStats struct {
+	Hits, Misses int
+}
+
+type cacheStatsKey struct{}
+
+// WithCacheStats returns a context in which authz providers record their cache usage in s.
+func WithCacheStats(ctx context.Context, s *CacheStats) context.Context {
+	return context.WithValue(ctx, cacheStatsKey{}, s)
+}
+
+// RecordCacheStats adds the given numbers of cache hits and misses to the CacheStats of the
+// context, if any. Authz providers that cache permissions call it from RepoPerms.
+func RecordCacheStats(ctx context.Context, hits, misses int) {
+	if s, _ := ctx.Value(cacheStatsKey{}).(*CacheStats); s != nil {
+		s.Hits += hits
+		s.Misses += misses
+	}
+}
@@ -66,3 +66,15 @@ type Provider interface {
 	// problems.
 	Validate() (problems []string)
 }
+
+// RepoUsersProvider is implemented by authz providers that can also list the users that have read
+// access to a repository. It lets the permissions of new repositories be synced once for all users,
+// instead of once per user.
+type RepoUsersProvider interface {
+	Provider
+
+	// RepoUsers returns the account IDs of the external accounts (whose ServiceID and ServiceType
+	// match the provider's) that have read access to the repo. If every account has read access to
+	// it (e.g., because it is public), it returns everyone == true instead.
+	RepoUsers(ctx context.Context, repo *types.Repo) (accountIDs []string, everyone bool, err error)
+}
@@ -1,13 +1,110 @@
 package authz
 
+import (
+	"context"
+	"regexp"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
 const (
 	// Access token scopes.
 	ScopeUserAll       = "user:all"        // Full control of all resources accessible to the user account.
+	ScopeUserRead      = "user:read"       // Read-only access to all resources accessible to the user account.
+	ScopeSearch        = "search"          // Only searching resources accessible to the user account.
 	ScopeSiteAdminSudo = "site-admin:sudo" // Ability to perform any action as any other user.
+	ScopeSCIM          = "scim"            // Only provisioning users and groups with the SCIM API.
 )
 
 // AllScopes is a list of all known access token scopes.
 var AllScopes = []string{
 	ScopeUserAll,
+	ScopeUserRead,
+	ScopeSearch,
 	ScopeSiteAdminSudo,
+	ScopeSCIM,
+}
+
+// UserScopes are the scopes that define what an access token may do on behalf of its subject
+// user. Every access token has exactly one of them.
+var UserScopes = []string{
+	ScopeUserAll,
+	ScopeUserRead,
+	ScopeSearch,
+	ScopeSCIM,
+}
+
+// TokenRestrictions restrict what a request authenticated with an access token may do, beyond
+// the permissions of the token's user.
+type TokenRestrictions struct {
+	Scopes []string
+
+	// RepoPatterns, if any, restrict the accessible repositories to those whose name matches
+	// one of them.
+	RepoPatterns []*regexp.Regexp
+}
+
+// HasScope reports whether the access token has the scope.
+func (r *TokenRestrictions) HasScope(scope string) bool {
+	return HasScope(r.Scopes, scope)
+}
+
+// HasScope reports whether the scopes include the scope. The "site-admin:sudo" scope implies the
+// "user:all" scope.
+func HasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope || (scope == ScopeUserAll && s == ScopeSiteAdminSudo) {
+			return true
+		}
+	}
+	return false
+}
+
+// GraphQLAccess is the part of the GraphQL API an access token may use.
+type GraphQLAccess int
+
+const (
+	GraphQLAccessAll      GraphQLAccess = iota // Queries and mutations.
+	GraphQLAccessReadOnly                      // Only queries.
+	GraphQLAccessSearch                        // Only the search query.
+)
+
+// GraphQLAccess returns the part of the GraphQL API the access token's scopes allow using.
+func (r *TokenRestrictions) GraphQLAccess() GraphQLAccess {
+	switch {
+	case r == nil || r.HasScope(ScopeUserAll):
+		return GraphQLAccessAll
+	case r.HasScope(ScopeUserRead):
+		return GraphQLAccessReadOnly
+	default:
+		return GraphQLAccessSearch
+	}
+}
+
+// AllowsRepo reports whether the access token allows accessing the repository.
+func (r *TokenRestrictions) AllowsRepo(name api.RepoName) bool {
+	if r == nil || len(r.RepoPatterns) == 0 {
+		return true
+	}
+	for _, p := range r.RepoPatterns {
+		if p.MatchString(string(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenRestrictionsKey struct{}
+
+// WithTokenRestrictions returns a context for a request authenticated with an access token with
+// the restrictions.
+func WithTokenRestrictions(ctx context.Context, r *TokenRestrictions) context.Context {
+	return context.WithValue(ctx, tokenRestrictionsKey{}, r)
+}
+
+// TokenRestrictionsFromContext returns the restrictions of the access token the request was
+// authenticated with, or nil if it wasn't authenticated with an access token.
+func TokenRestrictionsFromContext(ctx context.Context) *TokenRestrictions {
+	r, _ := ctx.Value(tokenRestrictionsKey{}).(*TokenRestrictions)
+	return r
 }
@@ -0,0 +1,62 @@
+package authz
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func TestTokenRestrictions_GraphQLAccess(t *testing.T) {
+	tests := []struct {
+		restrictions *TokenRestrictions
+		want         GraphQLAccess
+	}{
+		{nil, GraphQLAccessAll},
+		{&TokenRestrictions{Scopes: []string{ScopeUserAll}}, GraphQLAccessAll},
+		{&TokenRestrictions{Scopes: []string{ScopeSiteAdminSudo}}, GraphQLAccessAll},
+		{&TokenRestrictions{Scopes: []string{ScopeUserRead}}, GraphQLAccessReadOnly},
+		{&TokenRestrictions{Scopes: []string{ScopeUserRead, ScopeSiteAdminSudo}}, GraphQLAccessAll},
+		{&TokenRestrictions{Scopes: []string{ScopeSearch}}, GraphQLAccessSearch},
+		{&TokenRestrictions{Scopes: []string{ScopeSCIM}}, GraphQLAccessSearch},
+	}
+	for _, test := range tests {
+		var scopes []string
+		if test.restrictions != nil {
+			scopes = test.restrictions.Scopes
+		}
+		if got := test.restrictions.GraphQLAccess(); got != test.want {
+			t.Errorf("scopes %q: got access %v, want %v", scopes, got, test.want)
+		}
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	if !HasScope([]string{ScopeSiteAdminSudo}, ScopeUserAll) {
+		t.Errorf("scope %q does not imply %q", ScopeSiteAdminSudo, ScopeUserAll)
+	}
+	if HasScope([]string{ScopeUserAll}, ScopeSiteAdminSudo) {
+		t.Errorf("scope %q implies %q", ScopeUserAll, ScopeSiteAdminSudo)
+	}
+	if HasScope([]string{ScopeSiteAdminSudo}, ScopeUserRead) {
+		t.Errorf("scope %q implies %q", ScopeSiteAdminSudo, ScopeUserRead)
+	}
+}
+
+func TestTokenRestrictions_AllowsRepo(t *testing.T) {
+	var r *TokenRestrictions
+	if !r.AllowsRepo("github.com/a/b") {
+		t.Error("nil restrictions disallow repo")
+	}
+
+	r = &TokenRestrictions{RepoPatterns: []*regexp.Regexp{regexp.MustCompile("^github.com/a/"), regexp.MustCompile("/c$")}}
+	for name, want := range map[api.RepoName]bool{
+		"github.com/a/b": true,
+		"gitlab.com/b/c": true,
+		"github.com/b/a": false,
+	} {
+		if got := r.AllowsRepo(name); got != want {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditSiteConfigUpdate      = "site_config.update"
+	AuditSettingsUpdate        = "settings.update"
+	AuditUserDelete            = "user.delete"
+	AuditUserSiteAdminGrant    = "user.site_admin.grant"
+	AuditUserSiteAdminRevoke   = "user.site_admin.revoke"
+	AuditUserSuspend           = "user.suspend"
+	AuditUserUnsuspend         = "user.unsuspend"
+	AuditUserMFAEnable         = "user.mfa.enable"
+	AuditUserMFAReset          = "user.mfa.reset"
+	AuditUserMFARecoveryCode   = "user.mfa.recovery_code"
+	AuditOrgDelete             = "org.delete"
+	AuditAccessTokenCreate     = "access_token.create"
+	AuditAccessTokenDelete     = "access_token.delete"
+	AuditAccessTokenSudo       = "access_token.sudo"
+	AuditExternalServiceCreate = "external_service.create"
+	AuditExternalServiceUpdate = "external_service.update"
+	AuditExternalServiceDelete = "external_service.delete"
+)
+
+// LogAuditEvent records in the audit log that the current actor performed the action on the
+// subject. The data, if non-nil, is stored as JSON and must not contain secrets.
+//
+// The action has already been performed when this is called, so a failure to record it is only
+// logged.
+func LogAuditEvent(ctx context.Context, action, subject string, data interface{}) {
+	e := &db.AuditLogEntry{
+		ActorUserID: actor.FromContext(ctx).UID,
+		Action:      action,
+		Subject:     subject,
+	}
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			log15.Error("Failed to marshal audit log entry data.", "action", action, "subject", subject, "error", err)
+		}
+		e.Data = b
+	}
+	if err := db.AuditLog.Insert(ctx, e); err != nil {
+		log15.Error("Failed to record audit log entry.", "action", action, "subject", subject, "actor", e.ActorUserID, "error", err)
+	}
+}
@@ -20,3 +20,13 @@ func (symbols) ListTags(ctx context.Context, args protocol.SearchArgs) ([]protoc
 	}
 	return result.Symbols, err
 }
+
+// Definitions returns the identifier at a position in a file and the symbols
+// that are its candidate definitions, most likely first.
+func (symbols) Definitions(ctx context.Context, args protocol.DefinitionArgs) (string, []protocol.Symbol, error) {
+	result, err := symbolsclient.DefaultClient.Definition(ctx, args)
+	if result == nil {
+		return "", nil, err
+	}
+	return result.Identifier, result.Symbols, err
+}
@@ -26,6 +26,26 @@ func MakePasswordResetURL(ctx context.Context, userID int32) (*url.URL, error) {
 	return &url.URL{Path: "/password-reset", RawQuery: query.Encode()}, nil
 }
 
+// SetUserSuspended suspends or unsuspends the user and records it in the audit log, along with
+// the source of the change (such as "scim"), if any.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to suspend users.
+func SetUserSuspended(ctx context.Context, userID int32, suspended bool, source string) error {
+	if err := db.Users.SetSuspended(ctx, userID, suspended); err != nil {
+		return err
+	}
+	action := AuditUserUnsuspend
+	if suspended {
+		action = AuditUserSuspend
+	}
+	var data interface{}
+	if source != "" {
+		data = map[string]string{"source": source}
+	}
+	LogAuditEvent(ctx, action, fmt.Sprintf("user:%d", userID), data)
+	return nil
+}
+
 // CheckActorHasTag reports whether the context actor has the given tag. If not, or if an error
 // occurs, a non-nil error is returned.
 func CheckActorHasTag(ctx context.Context, tag string) error {
@@ -24,6 +24,17 @@ type AccessToken struct {
 	CreatorUserID int32
 	CreatedAt     time.Time
 	LastUsedAt    *time.Time
+	AccessTokenRestrictions
+}
+
+// AccessTokenRestrictions restrict what an access token may be used for, beyond its scopes.
+type AccessTokenRestrictions struct {
+	// RepoPatterns, if any, restrict the repositories the access token may be used to access to
+	// those whose name matches one of these regular expressions.
+	RepoPatterns []string
+
+	// ExpiresAt, if set, is when the access token expires.
+	ExpiresAt *time.Time
 }
 
 // ErrAccessTokenNotFound occurs when a database operation expects a specific access token to exist
@@ -52,7 +63,21 @@ func (s *accessTokens) Create(ctx context.Context, subjectUserID int32, scopes [
 	if Mocks.AccessTokens.Create != nil {
 		return Mocks.AccessTokens.Create(subjectUserID, scopes, note, creatorUserID)
 	}
+	return s.create(ctx, subjectUserID, scopes, note, creatorUserID, AccessTokenRestrictions{})
+}
 
+// CreateRestricted is like Create, but the access token is further restricted.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to create tokens for the
+// specified user (i.e., that the actor is either the user or a site admin).
+func (s *accessTokens) CreateRestricted(ctx context.Context, subjectUserID int32, scopes []string, note string, creatorUserID int32, restrictions AccessTokenRestrictions) (id int64, token string, err error) {
+	if Mocks.AccessTokens.CreateRestricted != nil {
+		return Mocks.AccessTokens.CreateRestricted(subjectUserID, scopes, note, creatorUserID, restrictions)
+	}
+	return s.create(ctx, subjectUserID, scopes, note, creatorUserID, restrictions)
+}
+
+func (s *accessTokens) create(ctx context.Context, subjectUserID int32, scopes []string, note string, creatorUserID int32, restrictions AccessTokenRestrictions) (id int64, token string, err error) {
 	var b [20]byte
 	if _, err := rand.Read(b[:]); err != nil {
 		return 0, "", err
@@ -65,6 +90,11 @@ func (s *accessTokens) Create(ctx context.Context, subjectUserID int32, scopes [
 		return 0, "", errors.New("access tokens without scopes are not supported")
 	}
 
+	repoPatterns := restrictions.RepoPatterns
+	if repoPatterns == nil {
+		repoPatterns = []string{}
+	}
+
 	if err := dbconn.Global.QueryRowContext(ctx,
 		// Include users table query (with "FOR UPDATE") to ensure that subject/creator users have
 		// not been deleted. If they were deleted, the query will return an error.
@@ -76,12 +106,13 @@ creator_user AS (
   SELECT id FROM users WHERE id=$5 AND deleted_at IS NULL FOR UPDATE
 ),
 insert_values AS (
-  SELECT subject_user.id AS subject_user_id, $2::text[] AS scopes, $3::bytea AS value_sha256, $4::text AS note, creator_user.id AS creator_user_id
+  SELECT subject_user.id AS subject_user_id, $2::text[] AS scopes, $3::bytea AS value_sha256, $4::text AS note, creator_user.id AS creator_user_id,
+    $6::text[] AS repo_patterns, $7::timestamp with time zone AS expires_at
   FROM subject_user, creator_user
 )
-INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_user_id) SELECT * FROM insert_values RETURNING id
+INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_user_id, repo_patterns, expires_at) SELECT * FROM insert_values RETURNING id
 `,
-		subjectUserID, pq.Array(scopes), toSHA256Bytes(b[:]), note, creatorUserID,
+		subjectUserID, pq.Array(scopes), toSHA256Bytes(b[:]), note, creatorUserID, pq.Array(repoPatterns), restrictions.ExpiresAt,
 	).Scan(&id); err != nil {
 		return 0, "", err
 	}
@@ -94,7 +125,7 @@ INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_u
 // Calling Lookup also updates the access token's last-used-at date.
 //
 // 🚨 SECURITY: This returns a user ID if and only if the tokenHexEncoded corresponds to a valid,
-// non-deleted access token.
+// non-deleted access token whose subject user is not suspended.
 func (s *accessTokens) Lookup(ctx context.Context, tokenHexEncoded string, requiredScope string) (subjectUserID int32, err error) {
 	if Mocks.AccessTokens.Lookup != nil {
 		return Mocks.AccessTokens.Lookup(tokenHexEncoded, requiredScope)
@@ -110,14 +141,16 @@ func (s *accessTokens) Lookup(ctx context.Context, tokenHexEncoded string, requi
 	}
 
 	if err := dbconn.Global.QueryRowContext(ctx,
-		// Ensure that subject and creator users still exist.
+		// Ensure that subject and creator users still exist, and that the subject user is not
+		// suspended.
 		`
 UPDATE access_tokens t SET last_used_at=now()
 FROM access_tokens t2
 JOIN users subject_user ON t2.subject_user_id=subject_user.id
 JOIN users creator_user ON t2.creator_user_id=creator_user.id
 WHERE t.value_sha256=$1 AND t.deleted_at IS NULL AND
-  subject_user.deleted_at IS NULL AND creator_user.deleted_at IS NULL AND
+  (t.expires_at IS NULL OR t.expires_at > now()) AND
+  subject_user.deleted_at IS NULL AND subject_user.suspended_at IS NULL AND creator_user.deleted_at IS NULL AND
   $2 = ANY (t.scopes)
 RETURNING t.subject_user_id
 `,
@@ -131,6 +164,47 @@ RETURNING t.subject_user_id
 	return subjectUserID, nil
 }
 
+// LookupToken is like Lookup, but returns the access token, whatever its scopes are. The caller
+// is responsible for checking that they allow the request.
+//
+// Calling LookupToken also updates the access token's last-used-at date.
+//
+// 🚨 SECURITY: This returns an access token if and only if the tokenHexEncoded corresponds to a
+// valid, non-deleted, unexpired access token whose subject user is not suspended.
+func (s *accessTokens) LookupToken(ctx context.Context, tokenHexEncoded string) (*AccessToken, error) {
+	if Mocks.AccessTokens.LookupToken != nil {
+		return Mocks.AccessTokens.LookupToken(tokenHexEncoded)
+	}
+
+	token, err := hex.DecodeString(tokenHexEncoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "AccessTokens.LookupToken")
+	}
+
+	var t AccessToken
+	if err := dbconn.Global.QueryRowContext(ctx,
+		// Ensure that subject and creator users still exist, and that the subject user is not
+		// suspended.
+		`
+UPDATE access_tokens t SET last_used_at=now()
+FROM access_tokens t2
+JOIN users subject_user ON t2.subject_user_id=subject_user.id
+JOIN users creator_user ON t2.creator_user_id=creator_user.id
+WHERE t.value_sha256=$1 AND t.id=t2.id AND t.deleted_at IS NULL AND
+  (t.expires_at IS NULL OR t.expires_at > now()) AND
+  subject_user.deleted_at IS NULL AND subject_user.suspended_at IS NULL AND creator_user.deleted_at IS NULL
+RETURNING t.id, t.subject_user_id, t.scopes, t.note, t.creator_user_id, t.created_at, t.last_used_at, t.repo_patterns, t.expires_at
+`,
+		toSHA256Bytes(token),
+	).Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt, pq.Array(&t.RepoPatterns), &t.ExpiresAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAccessTokenNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
 // GetByID retrieves the access token (if any) given its ID.
 //
 // 🚨 SECURITY: The caller must ensure that the actor is permitted to view this access token.
@@ -181,7 +255,7 @@ func (s *accessTokens) List(ctx context.Context, opt AccessTokensListOptions) ([
 
 func (s *accessTokens) list(ctx context.Context, conds []*sqlf.Query, limitOffset *LimitOffset) ([]*AccessToken, error) {
 	q := sqlf.Sprintf(`
-SELECT id, subject_user_id, scopes, note, creator_user_id, created_at, last_used_at FROM access_tokens
+SELECT id, subject_user_id, scopes, note, creator_user_id, created_at, last_used_at, repo_patterns, expires_at FROM access_tokens
 WHERE (%s)
 ORDER BY now() - created_at < interval '5 minutes' DESC, -- show recently created tokens first
 last_used_at DESC NULLS FIRST, -- ensure newly created tokens show first
@@ -200,7 +274,7 @@ created_at DESC
 	var results []*AccessToken
 	for rows.Next() {
 		var t AccessToken
-		if err := rows.Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt, pq.Array(&t.RepoPatterns), &t.ExpiresAt); err != nil {
 			return nil, err
 		}
 		results = append(results, &t)
@@ -264,8 +338,10 @@ func toSHA256Bytes(input []byte) []byte {
 }
 
 type MockAccessTokens struct {
-	Create     func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (id int64, token string, err error)
-	DeleteByID func(id int64, subjectUserID int32) error
-	Lookup     func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error)
-	GetByID    func(id int64) (*AccessToken, error)
+	Create           func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (id int64, token string, err error)
+	CreateRestricted func(subjectUserID int32, scopes []string, note string, creatorUserID int32, restrictions AccessTokenRestrictions) (id int64, token string, err error)
+	DeleteByID       func(id int64, subjectUserID int32) error
+	Lookup           func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error)
+	LookupToken      func(tokenHexEncoded string) (*AccessToken, error)
+	GetByID          func(id int64) (*AccessToken, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
 )
@@ -226,6 +227,55 @@ func TestAccessTokens_Lookup(t *testing.T) {
 	}
 }
 
+// 🚨 SECURITY: This tests that restricted access tokens are looked up with their restrictions, and
+// that expired access tokens are invalid.
+func TestAccessTokens_LookupToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+	_, tv0, err := AccessTokens.CreateRestricted(ctx, user.ID, []string{"a"}, "n0", user.ID, AccessTokenRestrictions{
+		RepoPatterns: []string{"^github.com/a/"},
+		ExpiresAt:    &expiresAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := AccessTokens.LookupToken(ctx, tv0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SubjectUserID != user.ID || !reflect.DeepEqual(got.Scopes, []string{"a"}) || got.LastUsedAt == nil {
+		t.Errorf("got unexpected token %+v", got)
+	}
+	if want := []string{"^github.com/a/"}; !reflect.DeepEqual(got.RepoPatterns, want) {
+		t.Errorf("got repo patterns %q, want %q", got.RepoPatterns, want)
+	}
+	if got.ExpiresAt == nil || !got.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("got expiry %v, want %v", got.ExpiresAt, expiresAt)
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	_, tv1, err := AccessTokens.CreateRestricted(ctx, user.ID, []string{"a"}, "n1", user.ID, AccessTokenRestrictions{ExpiresAt: &expired})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AccessTokens.LookupToken(ctx, tv1); err != ErrAccessTokenNotFound {
+		t.Errorf("got error %v, want %v", err, ErrAccessTokenNotFound)
+	}
+	if _, err := AccessTokens.Lookup(ctx, tv1, "a"); err != ErrAccessTokenNotFound {
+		t.Errorf("got error %v, want %v", err, ErrAccessTokenNotFound)
+	}
+}
+
 // 🚨 SECURITY: This tests that deleting the subject or creator user of an access token invalidates
 // the token, and that no new access tokens may be created for deleted users.
 func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
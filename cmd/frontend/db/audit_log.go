@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// AuditLogEntry is an entry in the audit log, which records security-relevant actions (such as
+// changing the site configuration or creating access tokens). Unlike event logs, audit log
+// entries are never updated or deleted.
+type AuditLogEntry struct {
+	ID int64
+
+	// ActorUserID is the user who performed the action, or 0 if it was performed by an
+	// unauthenticated or internal actor. It is not a reference to the users table, so it may refer
+	// to a deleted user.
+	ActorUserID int32
+
+	Action  string          // what was done, such as "access_token.create"
+	Subject string          // what it was done to, such as "user:123"
+	Data    json.RawMessage // additional details about the action, as a JSON object
+
+	CreatedAt time.Time
+}
+
+type auditLog struct{}
+
+// Insert appends the entry to the audit log. The ID and CreatedAt fields are ignored.
+func (*auditLog) Insert(ctx context.Context, e *AuditLogEntry) error {
+	if Mocks.AuditLog.Insert != nil {
+		return Mocks.AuditLog.Insert(e)
+	}
+
+	data := e.Data
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	_, err := dbconn.Global.ExecContext(
+		ctx,
+		"INSERT INTO audit_log(actor_user_id, action, subject, data) VALUES($1, $2, $3, $4)",
+		e.ActorUserID,
+		e.Action,
+		e.Subject,
+		[]byte(data),
+	)
+	if err != nil {
+		return errors.Wrap(err, "INSERT")
+	}
+	return nil
+}
+
+// AuditLogListOptions contains options for listing audit log entries.
+type AuditLogListOptions struct {
+	ActorUserID int32    // only list entries of actions by this user
+	Actions     []string // only list entries of these actions
+	Subject     string   // only list entries of actions on this subject
+	Since       *time.Time
+	Until       *time.Time
+
+	// AfterID and BeforeID, if set, only list entries after or before the entry with the ID. They
+	// can be used to page through entries while new ones are appended.
+	AfterID  int64
+	BeforeID int64
+
+	// OldestFirst lists the oldest entries first, instead of the most recent ones.
+	OldestFirst bool
+
+	*LimitOffset
+}
+
+func (o AuditLogListOptions) sqlConditions() []*sqlf.Query {
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if o.ActorUserID != 0 {
+		conds = append(conds, sqlf.Sprintf("actor_user_id=%d", o.ActorUserID))
+	}
+	if len(o.Actions) > 0 {
+		actions := make([]*sqlf.Query, len(o.Actions))
+		for i, a := range o.Actions {
+			actions[i] = sqlf.Sprintf("%s", a)
+		}
+		conds = append(conds, sqlf.Sprintf("action IN (%s)", sqlf.Join(actions, ",")))
+	}
+	if o.Subject != "" {
+		conds = append(conds, sqlf.Sprintf("subject=%s", o.Subject))
+	}
+	if o.Since != nil {
+		conds = append(conds, sqlf.Sprintf("created_at>=%s", *o.Since))
+	}
+	if o.Until != nil {
+		conds = append(conds, sqlf.Sprintf("created_at<%s", *o.Until))
+	}
+	if o.AfterID != 0 {
+		conds = append(conds, sqlf.Sprintf("id>%d", o.AfterID))
+	}
+	if o.BeforeID != 0 {
+		conds = append(conds, sqlf.Sprintf("id<%d", o.BeforeID))
+	}
+	return conds
+}
+
+// List lists the audit log entries that satisfy the options, most recent first unless
+// opt.OldestFirst is set.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (*auditLog) List(ctx context.Context, opt AuditLogListOptions) ([]*AuditLogEntry, error) {
+	if Mocks.AuditLog.List != nil {
+		return Mocks.AuditLog.List(opt)
+	}
+
+	order := sqlf.Sprintf("DESC")
+	if opt.OldestFirst {
+		order = sqlf.Sprintf("ASC")
+	}
+	q := sqlf.Sprintf(`
+-- source: cmd/frontend/db/audit_log.go:List
+SELECT id, actor_user_id, action, subject, data, created_at FROM audit_log
+WHERE (%s)
+ORDER BY id %s
+%s`,
+		sqlf.Join(opt.sqlConditions(), ") AND ("),
+		order,
+		opt.LimitOffset.SQL(),
+	)
+
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*AuditLogEntry
+	for rows.Next() {
+		var e AuditLogEntry
+		var data []byte
+		if err := rows.Scan(&e.ID, &e.ActorUserID, &e.Action, &e.Subject, &data, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Data = json.RawMessage(data)
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// Count counts the audit log entries that satisfy the options (ignoring limit and offset).
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (*auditLog) Count(ctx context.Context, opt AuditLogListOptions) (int, error) {
+	if Mocks.AuditLog.Count != nil {
+		return Mocks.AuditLog.Count(opt)
+	}
+
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM audit_log WHERE (%s)", sqlf.Join(opt.sqlConditions(), ") AND ("))
+	var count int
+	if err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+type MockAuditLog struct {
+	Insert func(e *AuditLogEntry) error
+	List   func(opt AuditLogListOptions) ([]*AuditLogEntry, error)
+	Count  func(opt AuditLogListOptions) (int, error)
+}
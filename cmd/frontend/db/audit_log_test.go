@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestAuditLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	for _, e := range []*AuditLogEntry{
+		{ActorUserID: 1, Action: "site_config.update"},
+		{ActorUserID: 2, Action: "access_token.create", Subject: "user:2", Data: json.RawMessage(`{"scopes":["user:all"]}`)},
+		{ActorUserID: 1, Action: "access_token.delete", Subject: "user:2"},
+	} {
+		if err := AuditLog.Insert(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := func(opt AuditLogListOptions) []string {
+		t.Helper()
+		entries, err := AuditLog.List(ctx, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count, err := AuditLog.Count(ctx, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opt.LimitOffset == nil && count != len(entries) {
+			t.Errorf("got count %d, want %d", count, len(entries))
+		}
+		var actions []string
+		for _, e := range entries {
+			actions = append(actions, e.Action)
+		}
+		return actions
+	}
+
+	tests := map[string]struct {
+		opt  AuditLogListOptions
+		want []string
+	}{
+		"all":          {AuditLogListOptions{}, []string{"access_token.delete", "access_token.create", "site_config.update"}},
+		"oldest first": {AuditLogListOptions{OldestFirst: true}, []string{"site_config.update", "access_token.create", "access_token.delete"}},
+		"actor":        {AuditLogListOptions{ActorUserID: 1}, []string{"access_token.delete", "site_config.update"}},
+		"actions":      {AuditLogListOptions{Actions: []string{"site_config.update", "access_token.create"}}, []string{"access_token.create", "site_config.update"}},
+		"subject":      {AuditLogListOptions{Subject: "user:2"}, []string{"access_token.delete", "access_token.create"}},
+		"before id":    {AuditLogListOptions{BeforeID: 3}, []string{"access_token.create", "site_config.update"}},
+		"after id":     {AuditLogListOptions{AfterID: 1, OldestFirst: true}, []string{"access_token.create", "access_token.delete"}},
+		"limit":        {AuditLogListOptions{LimitOffset: &LimitOffset{Limit: 1}}, []string{"access_token.delete"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := list(test.opt); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	entries, err := AuditLog.List(ctx, AuditLogListOptions{Actions: []string{"access_token.create"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(entries[0].Data), `{"scopes": ["user:all"]}`; got != want {
+		t.Errorf("got data %s, want %s", got, want)
+	}
+
+	// The audit log is append-only.
+	if _, err := dbconn.Global.ExecContext(ctx, "DELETE FROM audit_log"); err == nil {
+		t.Error("got no error deleting audit log entries")
+	}
+	if _, err := dbconn.Global.ExecContext(ctx, "UPDATE audit_log SET action='x'"); err == nil {
+		t.Error("got no error updating audit log entries")
+	}
+}
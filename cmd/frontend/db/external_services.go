@@ -31,6 +31,8 @@ type ExternalServicesStore struct {
 	GitHubValidators          []func(*schema.GitHubConnection) error
 	GitLabValidators          []func(*schema.GitLabConnection, []schema.AuthProviders) error
 	BitbucketServerValidators []func(*schema.BitbucketServerConnection, []schema.AuthProviders) error
+	BitbucketCloudValidators  []func(*schema.BitbucketCloudConnection, []schema.AuthProviders) error
+	GitoliteValidators        []func(*schema.GitoliteConnection) error
 }
 
 // ExternalServiceKinds contains a map of all supported kinds of
@@ -140,6 +142,20 @@ func (e *ExternalServicesStore) ValidateConfig(kind, config string, ps []schema.
 		}
 		err = e.validateBitbucketServerConnection(&c, ps)
 
+	case "BITBUCKETCLOUD":
+		var c schema.BitbucketCloudConnection
+		if err = json.Unmarshal(normalized, &c); err != nil {
+			return err
+		}
+		err = e.validateBitbucketCloudConnection(&c, ps)
+
+	case "GITOLITE":
+		var c schema.GitoliteConnection
+		if err = json.Unmarshal(normalized, &c); err != nil {
+			return err
+		}
+		err = e.validateGitoliteConnection(&c)
+
 	case "OTHER":
 		var c schema.OtherExternalServiceConnection
 		if err = json.Unmarshal(normalized, &c); err != nil {
@@ -214,6 +230,24 @@ func (e *ExternalServicesStore) validateBitbucketServerConnection(c *schema.Bitb
 	return err.ErrorOrNil()
 }
 
+func (e *ExternalServicesStore) validateBitbucketCloudConnection(c *schema.BitbucketCloudConnection, ps []schema.AuthProviders) error {
+	err := new(multierror.Error)
+	for _, validate := range e.BitbucketCloudValidators {
+		err = multierror.Append(err, validate(c, ps))
+	}
+
+	return err.ErrorOrNil()
+}
+
+func (e *ExternalServicesStore) validateGitoliteConnection(c *schema.GitoliteConnection) error {
+	err := new(multierror.Error)
+	for _, validate := range e.GitoliteValidators {
+		err = multierror.Append(err, validate(c))
+	}
+
+	return err.ErrorOrNil()
+}
+
 // Create creates a external service.
 //
 // Since this method is used before the configuration server has started
@@ -22,7 +22,13 @@ type MockStores struct {
 
 	ExternalAccounts MockExternalAccounts
 
+	Permissions MockPermissions
+
 	OrgInvitations MockOrgInvitations
 
 	ExternalServices MockExternalServices
+
+	AuditLog MockAuditLog
+
+	TOTPEnrollments MockTOTPEnrollments
 }
@@ -152,6 +152,10 @@ func (*orgs) getBySQL(ctx context.Context, query string, args ...interface{}) ([
 }
 
 func (*orgs) Create(ctx context.Context, name string, displayName *string) (*types.Org, error) {
+	if Mocks.Orgs.Create != nil {
+		return Mocks.Orgs.Create(ctx, name, displayName)
+	}
+
 	tx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
@@ -201,6 +205,10 @@ func (*orgs) Create(ctx context.Context, name string, displayName *string) (*typ
 }
 
 func (o *orgs) Update(ctx context.Context, id int32, displayName *string) (*types.Org, error) {
+	if Mocks.Orgs.Update != nil {
+		return Mocks.Orgs.Update(ctx, id, displayName)
+	}
+
 	org, err := o.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -225,6 +233,10 @@ func (o *orgs) Update(ctx context.Context, id int32, displayName *string) (*type
 }
 
 func (o *orgs) Delete(ctx context.Context, id int32) error {
+	if Mocks.Orgs.Delete != nil {
+		return Mocks.Orgs.Delete(ctx, id)
+	}
+
 	// Wrap in transaction because we delete from multiple tables.
 	tx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
@@ -12,6 +12,9 @@ type MockOrgs struct {
 	GetByName func(ctx context.Context, name string) (*types.Org, error)
 	Count     func(ctx context.Context, opt OrgsListOptions) (int, error)
 	List      func(ctx context.Context, opt *OrgsListOptions) ([]*types.Org, error)
+	Create    func(ctx context.Context, name string, displayName *string) (*types.Org, error)
+	Update    func(ctx context.Context, id int32, displayName *string) (*types.Org, error)
+	Delete    func(ctx context.Context, id int32) error
 }
 
 func (s *MockOrgs) MockGetByID_Return(t *testing.T, returns *types.Org, returnsErr error) (called *bool) {
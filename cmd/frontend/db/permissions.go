@@ -0,0 +1,442 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbutil"
+)
+
+// UserPermissions are the repositories a user has a permission on according
+// to one authz provider, as of the last time they were synced from it.
+type UserPermissions struct {
+	UserID      int32
+	Perm        authz.Perms
+	ServiceType string
+	ServiceID   string
+
+	// RepoIDs are the IDs of the repositories the user has the permission on.
+	RepoIDs *roaring.Bitmap
+
+	// MaxRepoID is the highest ID of the repositories that were checked when
+	// the permissions were synced. Repositories with higher IDs were added
+	// since, so the permissions say nothing about them.
+	MaxRepoID api.RepoID
+
+	SyncedAt time.Time
+}
+
+// Covers reports whether the permissions say whether the user has the
+// permission on the repository with the given ID.
+func (p *UserPermissions) Covers(id api.RepoID) bool {
+	return id != 0 && id <= p.MaxRepoID
+}
+
+// Stale reports whether the permissions were synced longer than maxAge ago.
+func (p *UserPermissions) Stale(maxAge time.Duration, now time.Time) bool {
+	return p.SyncedAt.IsZero() || now.Sub(p.SyncedAt) > maxAge
+}
+
+// RepoPermissions are the users that have a permission on a repository.
+type RepoPermissions struct {
+	RepoID    api.RepoID
+	Perm      authz.Perms
+	UserIDs   *roaring.Bitmap
+	UpdatedAt time.Time
+}
+
+// permissions provides access to the `user_repo_permissions` and
+// `repo_permissions` tables, which store the repository permissions synced
+// from authz providers, and to the `repo_explicit_permissions` table, which
+// stores the repository permissions granted explicitly by site admins.
+type permissions struct{}
+
+// ListUserPermissions returns the stored permissions of the user for perm,
+// one per authz provider they were synced from.
+func (*permissions) ListUserPermissions(ctx context.Context, userID int32, perm authz.Perms) ([]*UserPermissions, error) {
+	if Mocks.Permissions.ListUserPermissions != nil {
+		return Mocks.Permissions.ListUserPermissions(ctx, userID, perm)
+	}
+
+	q := sqlf.Sprintf(listUserPermissionsQueryFmtStr, userID, perm.String())
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ps []*UserPermissions
+	for rows.Next() {
+		p := UserPermissions{UserID: userID, Perm: perm}
+		var ids []byte
+		if err := rows.Scan(&p.ServiceType, &p.ServiceID, &ids, &p.MaxRepoID, &p.SyncedAt); err != nil {
+			return nil, err
+		}
+		if p.RepoIDs, err = unmarshalBitmap(ids); err != nil {
+			return nil, err
+		}
+		ps = append(ps, &p)
+	}
+	return ps, rows.Err()
+}
+
+const listUserPermissionsQueryFmtStr = `
+-- source: cmd/frontend/db/permissions.go:permissions.ListUserPermissions
+SELECT service_type, service_id, repo_ids, max_repo_id, synced_at
+FROM user_repo_permissions
+WHERE user_id = %s AND permission = %s
+ORDER BY service_type, service_id
+`
+
+// ListSyncTimes returns when the permissions of each user for perm were last
+// synced from the authz provider with the given service type and ID, by user
+// ID. Users whose permissions were never synced from it are omitted.
+func (*permissions) ListSyncTimes(ctx context.Context, perm authz.Perms, serviceType, serviceID string) (map[int32]time.Time, error) {
+	q := sqlf.Sprintf(listSyncTimesQueryFmtStr, perm.String(), serviceType, serviceID)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := map[int32]time.Time{}
+	for rows.Next() {
+		var (
+			userID   int32
+			syncedAt time.Time
+		)
+		if err := rows.Scan(&userID, &syncedAt); err != nil {
+			return nil, err
+		}
+		times[userID] = syncedAt
+	}
+	return times, rows.Err()
+}
+
+const listSyncTimesQueryFmtStr = `
+-- source: cmd/frontend/db/permissions.go:permissions.ListSyncTimes
+SELECT user_id, synced_at
+FROM user_repo_permissions
+WHERE permission = %s AND service_type = %s AND service_id = %s
+`
+
+// LoadRepoPermissions returns the users that have perm on the repository.
+// The returned permissions have a zero UpdatedAt if none are stored.
+func (*permissions) LoadRepoPermissions(ctx context.Context, repoID api.RepoID, perm authz.Perms) (*RepoPermissions, error) {
+	p := &RepoPermissions{RepoID: repoID, Perm: perm, UserIDs: roaring.NewBitmap()}
+
+	q := sqlf.Sprintf(loadRepoPermissionsQueryFmtStr, repoID, perm.String())
+	var ids []byte
+	err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&ids, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if p.UserIDs, err = unmarshalBitmap(ids); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+const loadRepoPermissionsQueryFmtStr = `
+-- source: cmd/frontend/db/permissions.go:permissions.LoadRepoPermissions
+SELECT user_ids, updated_at
+FROM repo_permissions
+WHERE repo_id = %s AND permission = %s
+`
+
+// SetUserPermissions stores p, replacing the permissions of the user that
+// were previously synced from the same authz provider. The stored users of
+// the repositories that were granted or revoked since are updated to match.
+func (*permissions) SetUserPermissions(ctx context.Context, p *UserPermissions) error {
+	if p.SyncedAt.IsZero() {
+		return errors.New("SyncedAt timestamp must be set")
+	}
+
+	return dbutil.Transaction(ctx, dbconn.Global, func(tx *sql.Tx) error {
+		q := sqlf.Sprintf(lockUserPermissionsQueryFmtStr, p.UserID, p.Perm.String(), p.ServiceType, p.ServiceID)
+		var ids []byte
+		err := tx.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&ids)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		old, err := unmarshalBitmap(ids)
+		if err != nil {
+			return err
+		}
+
+		if ids, err = p.RepoIDs.ToBytes(); err != nil {
+			return err
+		}
+		q = sqlf.Sprintf(upsertUserPermissionsQueryFmtStr, p.UserID, p.Perm.String(), p.ServiceType, p.ServiceID, ids, p.MaxRepoID, p.SyncedAt.UTC())
+		if _, err := tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+			return err
+		}
+
+		return updateRepoPermissions(ctx, tx, p.Perm, []repoUsersChange{{
+			userID:  p.UserID,
+			granted: roaring.AndNot(p.RepoIDs, old),
+			revoked: roaring.AndNot(old, p.RepoIDs),
+		}}, p.SyncedAt)
+	})
+}
+
+const lockUserPermissionsQueryFmtStr = `
+-- source: cmd/frontend/db/permissions.go:permissions.SetUserPermissions
+SELECT repo_ids
+FROM user_repo_permissions
+WHERE user_id = %s AND permission = %s AND service_type = %s AND service_id = %s
+ORDER BY user_id
+FOR UPDATE
+`
+
+const upsertUserPermissionsQueryFmtStr = `
+-- source: cmd/frontend/db/permissions.go:permissions.SetUserPermissions
+INSERT INTO user_repo_permissions
+  (user_id, permission, service_type, service_id, repo_ids, max_repo_id, synced_at)
+VALUES
+  (%s, %s, %s, %s, %s, %s, %s)
+ON CONFLICT ON CONSTRAINT
+  user_repo_permissions_unique
+DO UPDATE SET
+  repo_ids = excluded.repo_ids,
+  max_repo_id = excluded.max_repo_id,
+  synced_at = excluded.synced_at
+`
+
+// repoUsersChange is a change of the repositories a user has a permission on.
+type repoUsersChange struct {
+	userID           int32
+	granted, revoked *roaring.Bitmap
+}
+
+// updateRepoPermissions adds each user to the stored users of the
+// repositories they were granted and removes them from those of the
+// repositories they were revoked.
+func updateRepoPermissions(ctx context.Context, tx *sql.Tx, perm authz.Perms, changes []repoUsersChange, now time.Time) error {
+	changed := roaring.NewBitmap()
+	for _, c := range changes {
+		changed.Or(c.granted)
+		changed.Or(c.revoked)
+	}
+	if changed.IsEmpty() {
+		return nil
+	}
+
+	repoIDs := make([]int64, 0, changed.GetCardinality())
+	for it := changed.Iterator(); it.HasNext(); {
+		repoIDs = append(repoIDs, int64(it.Next()))
+	}
+
+	q := sqlf.Sprintf(lockRepoPermissionsQueryFmtStr, pq.Array(repoIDs), perm.String())
+	rows, err := tx.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return err
+	}
+
+	users := make(map[uint32]*roaring.Bitmap, len(repoIDs))
+	for rows.Next() {
+		var (
+			repoID uint32
+			ids    []byte
+		)
+		if err := rows.Scan(&repoID, &ids); err != nil {
+			rows.Close()
+			return err
+		}
+		if users[repoID], err = unmarshalBitmap(ids); err != nil {
+			rows.Close()
+			return err
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	for _, id := range repoIDs {
+		repoID := uint32(id)
+		userIDs, ok := users[repoID]
+		if !ok {
+			userIDs = roaring.NewBitmap()
+		}
+		for _, c := range changes {
+			if c.granted.Contains(repoID) {
+				userIDs.Add(uint32(c.userID))
+			} else if c.revoked.Contains(repoID) {
+				userIDs.Remove(uint32(c.userID))
+			}
+		}
+
+		ids, err := userIDs.ToBytes()
+		if err != nil {
+			return err
+		}
+		q := sqlf.Sprintf(upsertRepoPermissionsQueryFmtStr, perm.String(), ids, now.UTC(), repoID)
+		if _, err := tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The rows are locked in the order of their repository IDs (which is also
+// the order they are upserted in) so that concurrent updates can't deadlock.
+const lockRepoPermissionsQueryFmtStr = `
+-- source: cmd/frontend/db/permissions.go:updateRepoPermissions
+SELECT repo_id, user_ids
+FROM repo_permissions
+WHERE repo_id = ANY(%s) AND permission = %s
+ORDER BY repo_id
+FOR UPDATE
+`
+
+// Repositories that were deleted since the permissions were synced are
+// skipped rather than violating the foreign key.
+const upsertRepoPermissionsQueryFmtStr = `
+-- source: cmd/frontend/db/permissions.go:updateRepoPermissions
+INSERT INTO repo_permissions
+  (repo_id, permission, user_ids, updated_at)
+SELECT id, %s::text, %s::bytea, %s::timestamptz FROM repo WHERE id = %s
+ON CONFLICT ON CONSTRAINT
+  repo_permissions_unique
+DO UPDATE SET
+  user_ids = excluded.user_ids,
+  updated_at = excluded.updated_at
+`
+
+// MinMaxRepoID returns the lowest MaxRepoID of the users' permissions for perm
+// that were synced from the authz provider with the given service type and
+// ID, i.e. the highest repository ID that the permissions of all of them
+// cover. It returns ok == false if no permissions were synced from it.
+func (*permissions) MinMaxRepoID(ctx context.Context, perm authz.Perms, serviceType, serviceID string) (id api.RepoID, ok bool, err error) {
+	q := sqlf.Sprintf(minMaxRepoIDQueryFmtStr, perm.String(), serviceType, serviceID)
+	var min sql.NullInt64
+	if err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&min); err != nil {
+		return 0, false, err
+	}
+	return api.RepoID(min.Int64), min.Valid, nil
+}
+
+const minMaxRepoIDQueryFmtStr = `
+-- source: cmd/frontend/db/permissions.go:permissions.MinMaxRepoID
+SELECT MIN(max_repo_id)
+FROM user_repo_permissions
+WHERE permission = %s AND service_type = %s AND service_id = %s
+`
+
+// ExtendUserPermissions extends the users' permissions for perm that were
+// synced from the authz provider with the given service type and ID to cover
+// the repositories up to maxRepoID. repoIDs are the repositories that each
+// user (by user ID) has the permission on, of those that were added since.
+// Repositories that a user's permissions already cover are ignored, and
+// permissions that already cover maxRepoID are left alone.
+//
+// Unlike SetUserPermissions, it doesn't change when the permissions were
+// synced, because they are still only as fresh as the last sync of the user.
+func (*permissions) ExtendUserPermissions(ctx context.Context, perm authz.Perms, serviceType, serviceID string, maxRepoID api.RepoID, repoIDs map[int32]*roaring.Bitmap) error {
+	now := time.Now()
+	return dbutil.Transaction(ctx, dbconn.Global, func(tx *sql.Tx) error {
+		q := sqlf.Sprintf(lockUncoveredUserPermissionsQueryFmtStr, perm.String(), serviceType, serviceID, maxRepoID)
+		rows, err := tx.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+		if err != nil {
+			return err
+		}
+
+		type userPerms struct {
+			userID    int32
+			repoIDs   *roaring.Bitmap
+			maxRepoID api.RepoID
+		}
+		var ps []userPerms
+		for rows.Next() {
+			var (
+				p   userPerms
+				ids []byte
+			)
+			if err := rows.Scan(&p.userID, &ids, &p.maxRepoID); err != nil {
+				rows.Close()
+				return err
+			}
+			if p.repoIDs, err = unmarshalBitmap(ids); err != nil {
+				rows.Close()
+				return err
+			}
+			ps = append(ps, p)
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+
+		changes := make([]repoUsersChange, 0, len(ps))
+		for _, p := range ps {
+			granted := roaring.NewBitmap()
+			if ids, ok := repoIDs[p.userID]; ok {
+				granted.Or(ids)
+				granted.RemoveRange(0, uint64(p.maxRepoID)+1)
+				granted.RemoveRange(uint64(maxRepoID)+1, 1<<32)
+			}
+			p.repoIDs.Or(granted)
+			p.repoIDs.RunOptimize()
+
+			ids, err := p.repoIDs.ToBytes()
+			if err != nil {
+				return err
+			}
+			q := sqlf.Sprintf(extendUserPermissionsQueryFmtStr, ids, maxRepoID, p.userID, perm.String(), serviceType, serviceID)
+			if _, err := tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+				return err
+			}
+			changes = append(changes, repoUsersChange{userID: p.userID, granted: granted, revoked: roaring.NewBitmap()})
+		}
+
+		// The stored users of the repositories are updated for all users at
+		// once, so that the repositories are locked in order.
+		return updateRepoPermissions(ctx, tx, perm, changes, now)
+	})
+}
+
+const lockUncoveredUserPermissionsQueryFmtStr = `
+-- source: cmd/frontend/db/permissions.go:permissions.ExtendUserPermissions
+SELECT user_id, repo_ids, max_repo_id
+FROM user_repo_permissions
+WHERE permission = %s AND service_type = %s AND service_id = %s AND max_repo_id < %s
+ORDER BY user_id
+FOR UPDATE
+`
+
+const extendUserPermissionsQueryFmtStr = `
+-- source: cmd/frontend/db/permissions.go:permissions.ExtendUserPermissions
+UPDATE user_repo_permissions
+SET repo_ids = %s, max_repo_id = %s
+WHERE user_id = %s AND permission = %s AND service_type = %s AND service_id = %s
+`
+
+// unmarshalBitmap returns the roaring bitmap serialized in b, which may be
+// empty.
+func unmarshalBitmap(b []byte) (*roaring.Bitmap, error) {
+	bm := roaring.NewBitmap()
+	if len(b) == 0 {
+		return bm, nil
+	}
+	if err := bm.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return bm, nil
+}
+
+// MockPermissions mocks the permissions store.
+type MockPermissions struct {
+	ListUserPermissions func(ctx context.Context, userID int32, perm authz.Perms) ([]*UserPermissions, error)
+	LoadExplicit        func(ctx context.Context, userID int32, perm authz.Perms, repoIDs []api.RepoID) (restricted, granted *roaring.Bitmap, err error)
+}
@@ -0,0 +1,222 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbutil"
+)
+
+// ExplicitGrant is a permission on a repository granted explicitly by a site
+// admin, to a user or to the members of an org.
+type ExplicitGrant struct {
+	RepoID    api.RepoID
+	UserID    int32 // zero if granted to an org
+	OrgID     int32 // zero if granted to a user
+	Perm      authz.Perms
+	CreatedAt time.Time
+}
+
+// ExplicitGrantees are the users and orgs a permission is explicitly granted
+// to or revoked from.
+type ExplicitGrantees struct {
+	UserIDs []int32
+	OrgIDs  []int32
+}
+
+// GrantExplicit grants perm on the repositories to the grantees. Granting a
+// permission that's already granted is not an error.
+func (*permissions) GrantExplicit(ctx context.Context, repoIDs []api.RepoID, grantees ExplicitGrantees, perm authz.Perms) error {
+	return dbutil.Transaction(ctx, dbconn.Global, func(tx *sql.Tx) error {
+		return grantExplicit(ctx, tx, repoIDs, grantees, perm)
+	})
+}
+
+func grantExplicit(ctx context.Context, tx *sql.Tx, repoIDs []api.RepoID, grantees ExplicitGrantees, perm authz.Perms) error {
+	if len(repoIDs) == 0 {
+		return nil
+	}
+
+	ids := repoIDsArray(repoIDs)
+	for _, userID := range grantees.UserIDs {
+		q := sqlf.Sprintf(grantExplicitQueryFmtStr, userID, nil, perm.String(), ids)
+		if _, err := tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+			return err
+		}
+	}
+	for _, orgID := range grantees.OrgIDs {
+		q := sqlf.Sprintf(grantExplicitQueryFmtStr, nil, orgID, perm.String(), ids)
+		if _, err := tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const grantExplicitQueryFmtStr = `
+-- source: cmd/frontend/db/permissions_explicit.go:grantExplicit
+INSERT INTO repo_explicit_permissions (repo_id, user_id, org_id, permission)
+SELECT id, %s::integer, %s::integer, %s FROM repo WHERE id = ANY(%s)
+ON CONFLICT DO NOTHING
+`
+
+// RevokeExplicit revokes perm on the repositories from the grantees.
+// Revoking a permission that isn't granted is not an error. Grantees may
+// still have the permission through an authz provider.
+func (*permissions) RevokeExplicit(ctx context.Context, repoIDs []api.RepoID, grantees ExplicitGrantees, perm authz.Perms) error {
+	if len(repoIDs) == 0 {
+		return nil
+	}
+
+	q := sqlf.Sprintf(revokeExplicitQueryFmtStr,
+		repoIDsArray(repoIDs),
+		perm.String(),
+		int32sArray(grantees.UserIDs),
+		int32sArray(grantees.OrgIDs),
+	)
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+const revokeExplicitQueryFmtStr = `
+-- source: cmd/frontend/db/permissions_explicit.go:permissions.RevokeExplicit
+DELETE FROM repo_explicit_permissions
+WHERE repo_id = ANY(%s) AND permission = %s
+AND (user_id = ANY(%s) OR org_id = ANY(%s))
+`
+
+// SetExplicit replaces the explicit grants of perm on the repository with
+// grants to the grantees. With no grantees, the repository no longer has
+// explicit grants.
+func (*permissions) SetExplicit(ctx context.Context, repoID api.RepoID, grantees ExplicitGrantees, perm authz.Perms) error {
+	return dbutil.Transaction(ctx, dbconn.Global, func(tx *sql.Tx) error {
+		q := sqlf.Sprintf(clearExplicitQueryFmtStr, repoID, perm.String())
+		if _, err := tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+			return err
+		}
+		return grantExplicit(ctx, tx, []api.RepoID{repoID}, grantees, perm)
+	})
+}
+
+const clearExplicitQueryFmtStr = `
+-- source: cmd/frontend/db/permissions_explicit.go:permissions.SetExplicit
+DELETE FROM repo_explicit_permissions WHERE repo_id = %s AND permission = %s
+`
+
+// ListExplicit returns the explicit grants on the repository, oldest first.
+func (*permissions) ListExplicit(ctx context.Context, repoID api.RepoID) ([]*ExplicitGrant, error) {
+	q := sqlf.Sprintf(listExplicitQueryFmtStr, repoID)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []*ExplicitGrant
+	for rows.Next() {
+		g := ExplicitGrant{RepoID: repoID}
+		var (
+			userID, orgID sql.NullInt64
+			perm          string
+		)
+		if err := rows.Scan(&userID, &orgID, &perm, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		g.UserID, g.OrgID = int32(userID.Int64), int32(orgID.Int64)
+		g.Perm = parsePerms(perm)
+		grants = append(grants, &g)
+	}
+	return grants, rows.Err()
+}
+
+const listExplicitQueryFmtStr = `
+-- source: cmd/frontend/db/permissions_explicit.go:permissions.ListExplicit
+SELECT user_id, org_id, permission, created_at
+FROM repo_explicit_permissions
+WHERE repo_id = %s
+ORDER BY created_at, user_id, org_id
+`
+
+// LoadExplicit returns which of the repositories have explicit grants of
+// perm, and which of those perm is granted on to the user, directly or
+// through the orgs they're a member of. A zero userID is an unauthenticated
+// user, who is granted nothing.
+func (*permissions) LoadExplicit(ctx context.Context, userID int32, perm authz.Perms, repoIDs []api.RepoID) (restricted, granted *roaring.Bitmap, err error) {
+	if Mocks.Permissions.LoadExplicit != nil {
+		return Mocks.Permissions.LoadExplicit(ctx, userID, perm, repoIDs)
+	}
+
+	restricted, granted = roaring.NewBitmap(), roaring.NewBitmap()
+	if len(repoIDs) == 0 {
+		return restricted, granted, nil
+	}
+
+	q := sqlf.Sprintf(loadExplicitQueryFmtStr, userID, userID, repoIDsArray(repoIDs), perm.String())
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			repoID uint32
+			ok     bool
+		)
+		if err := rows.Scan(&repoID, &ok); err != nil {
+			return nil, nil, err
+		}
+		restricted.Add(repoID)
+		if ok {
+			granted.Add(repoID)
+		}
+	}
+	return restricted, granted, rows.Err()
+}
+
+const loadExplicitQueryFmtStr = `
+-- source: cmd/frontend/db/permissions_explicit.go:permissions.LoadExplicit
+SELECT repo_id, COALESCE(bool_or(
+  user_id = %s OR org_id IN (SELECT org_id FROM org_members WHERE user_id = %s)
+), false)
+FROM repo_explicit_permissions
+WHERE repo_id = ANY(%s) AND permission = %s
+GROUP BY repo_id
+`
+
+func repoIDsArray(ids []api.RepoID) interface{} {
+	a := make([]int64, len(ids))
+	for i, id := range ids {
+		a[i] = int64(id)
+	}
+	return pq.Array(a)
+}
+
+func int32sArray(ids []int32) interface{} {
+	a := make([]int64, len(ids))
+	for i, id := range ids {
+		a[i] = int64(id)
+	}
+	return pq.Array(a)
+}
+
+// parsePerms is the inverse of authz.Perms.String.
+func parsePerms(s string) authz.Perms {
+	switch s {
+	case "read":
+		return authz.Read
+	case "write":
+		return authz.Write
+	case "read,write":
+		return authz.Read | authz.Write
+	default:
+		return authz.None
+	}
+}
@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestPermissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := actor.WithActor(context.Background(), &actor.Actor{Internal: true})
+
+	var userIDs []int32
+	for _, username := range []string{"u1", "u2"} {
+		u, err := Users.Create(ctx, NewUser{Username: username})
+		if err != nil {
+			t.Fatal(err)
+		}
+		userIDs = append(userIDs, u.ID)
+	}
+	repos := mustCreate(ctx, t, &types.Repo{Name: "a/r"}, &types.Repo{Name: "b/r"}, &types.Repo{Name: "c/r"})
+
+	set := func(userID int32, syncedAt time.Time, repos ...*types.Repo) {
+		t.Helper()
+		p := &UserPermissions{
+			UserID:      userID,
+			Perm:        authz.Read,
+			ServiceType: "gitlab",
+			ServiceID:   "https://gitlab.com/",
+			RepoIDs:     roaring.NewBitmap(),
+			MaxRepoID:   3,
+			SyncedAt:    syncedAt,
+		}
+		for _, r := range repos {
+			p.RepoIDs.Add(uint32(r.ID))
+		}
+		if err := Permissions.SetUserPermissions(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkRepo := func(repoID api.RepoID, want ...int32) {
+		t.Helper()
+		p, err := Permissions.LoadRepoPermissions(ctx, repoID, authz.Read)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []int32{}
+		for _, id := range p.UserIDs.ToArray() {
+			got = append(got, int32(id))
+		}
+		if want == nil {
+			want = []int32{}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("repo %d: got users %v, want %v", repoID, got, want)
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	set(userIDs[0], now, repos[0], repos[1])
+	set(userIDs[1], now, repos[1])
+	checkRepo(repos[0].ID, userIDs[0])
+	checkRepo(repos[1].ID, userIDs[0], userIDs[1])
+	checkRepo(repos[2].ID)
+
+	// Syncing again revokes repos the user lost access to.
+	set(userIDs[0], now.Add(time.Minute), repos[1], repos[2])
+	checkRepo(repos[0].ID)
+	checkRepo(repos[1].ID, userIDs[0], userIDs[1])
+	checkRepo(repos[2].ID, userIDs[0])
+
+	ps, err := Permissions.ListUserPermissions(ctx, userIDs[0], authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 {
+		t.Fatalf("got %d permissions, want 1", len(ps))
+	}
+	if got, want := ps[0].RepoIDs.ToArray(), []uint32{uint32(repos[1].ID), uint32(repos[2].ID)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got repo IDs %v, want %v", got, want)
+	}
+	if !ps[0].SyncedAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("got synced at %s, want %s", ps[0].SyncedAt, now.Add(time.Minute))
+	}
+
+	times, err := Permissions.ListSyncTimes(ctx, authz.Read, "gitlab", "https://gitlab.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 2 || !times[userIDs[1]].Equal(now) {
+		t.Errorf("got sync times %v", times)
+	}
+
+	// Extending the permissions to cover new repos only grants the new repos.
+	newRepos := mustCreate(ctx, t, &types.Repo{Name: "d/r"}, &types.Repo{Name: "e/r"})
+	if id, ok, err := Permissions.MinMaxRepoID(ctx, authz.Read, "gitlab", "https://gitlab.com/"); err != nil {
+		t.Fatal(err)
+	} else if !ok || id != 3 {
+		t.Errorf("got min max repo ID %d (ok=%v), want 3", id, ok)
+	}
+	err = Permissions.ExtendUserPermissions(ctx, authz.Read, "gitlab", "https://gitlab.com/", newRepos[1].ID, map[int32]*roaring.Bitmap{
+		userIDs[0]: roaring.BitmapOf(uint32(repos[0].ID), uint32(newRepos[0].ID)),
+		userIDs[1]: roaring.BitmapOf(uint32(newRepos[0].ID), uint32(newRepos[1].ID)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRepo(repos[0].ID)
+	checkRepo(newRepos[0].ID, userIDs[0], userIDs[1])
+	checkRepo(newRepos[1].ID, userIDs[1])
+	if id, _, err := Permissions.MinMaxRepoID(ctx, authz.Read, "gitlab", "https://gitlab.com/"); err != nil {
+		t.Fatal(err)
+	} else if id != newRepos[1].ID {
+		t.Errorf("got min max repo ID %d, want %d", id, newRepos[1].ID)
+	}
+}
+
+func TestPermissions_explicit(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := actor.WithActor(context.Background(), &actor.Actor{Internal: true})
+
+	var userIDs []int32
+	for _, username := range []string{"u1", "u2", "u3"} {
+		u, err := Users.Create(ctx, NewUser{Username: username})
+		if err != nil {
+			t.Fatal(err)
+		}
+		userIDs = append(userIDs, u.ID)
+	}
+	org, err := Orgs.Create(ctx, "o", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OrgMembers.Create(ctx, org.ID, userIDs[1]); err != nil {
+		t.Fatal(err)
+	}
+	repos := mustCreate(ctx, t, &types.Repo{Name: "a/r"}, &types.Repo{Name: "b/r"}, &types.Repo{Name: "c/r"})
+	repoIDs := []api.RepoID{repos[0].ID, repos[1].ID, repos[2].ID}
+
+	check := func(userID int32, wantRestricted, wantGranted []api.RepoID) {
+		t.Helper()
+		restricted, granted, err := Permissions.LoadExplicit(ctx, userID, authz.Read, repoIDs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		toIDs := func(b *roaring.Bitmap) []api.RepoID {
+			ids := []api.RepoID{}
+			for _, id := range b.ToArray() {
+				ids = append(ids, api.RepoID(id))
+			}
+			return ids
+		}
+		if got := toIDs(restricted); !reflect.DeepEqual(got, wantRestricted) {
+			t.Errorf("user %d: got restricted %v, want %v", userID, got, wantRestricted)
+		}
+		if got := toIDs(granted); !reflect.DeepEqual(got, wantGranted) {
+			t.Errorf("user %d: got granted %v, want %v", userID, got, wantGranted)
+		}
+	}
+
+	err = Permissions.GrantExplicit(ctx, repoIDs[:2], ExplicitGrantees{UserIDs: userIDs[:1], OrgIDs: []int32{org.ID}}, authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Granting again is a no-op.
+	err = Permissions.GrantExplicit(ctx, repoIDs[:1], ExplicitGrantees{UserIDs: userIDs[:1]}, authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(userIDs[0], repoIDs[:2], repoIDs[:2])
+	check(userIDs[1], repoIDs[:2], repoIDs[:2]) // through the org
+	check(userIDs[2], repoIDs[:2], []api.RepoID{})
+	check(0, repoIDs[:2], []api.RepoID{})
+
+	err = Permissions.RevokeExplicit(ctx, repoIDs[1:2], ExplicitGrantees{UserIDs: userIDs[:1]}, authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(userIDs[0], repoIDs[:2], repoIDs[:1])
+
+	if err := Permissions.SetExplicit(ctx, repoIDs[0], ExplicitGrantees{UserIDs: userIDs[2:]}, authz.Read); err != nil {
+		t.Fatal(err)
+	}
+	check(userIDs[0], repoIDs[:2], []api.RepoID{})
+	check(userIDs[2], repoIDs[:2], repoIDs[:1])
+
+	grants, err := Permissions.ListExplicit(ctx, repoIDs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grants) != 1 || grants[0].UserID != userIDs[2] || grants[0].OrgID != 0 || grants[0].Perm != authz.Read {
+		t.Errorf("got unexpected grants %+v", grants)
+	}
+
+	// Setting no grantees removes the restriction.
+	if err := Permissions.SetExplicit(ctx, repoIDs[0], ExplicitGrantees{}, authz.Read); err != nil {
+		t.Fatal(err)
+	}
+	check(userIDs[2], repoIDs[1:2], []api.RepoID{})
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db/query"
@@ -133,6 +134,8 @@ var getBySQLColumns = []string{
 	"uri",
 	"description",
 	"language",
+	"missing_upstream_at",
+	"quarantined",
 }
 
 func (s *repos) getBySQL(ctx context.Context, querySuffix *sqlf.Query) ([]*types.Repo, error) {
@@ -197,6 +200,8 @@ func scanRepo(rows *sql.Rows, r *types.Repo) (err error) {
 		&dbutil.NullString{S: &r.URI},
 		&r.Description,
 		&r.Language,
+		&r.MissingUpstreamAt,
+		&r.Quarantined,
 	)
 }
 
@@ -238,6 +243,30 @@ type ReposListOptions struct {
 	// OnlyArchived excludes non-archived repositories from the list.
 	OnlyArchived bool
 
+	// Topics, if non-empty, includes only repositories labeled with all of
+	// the given topics on their code host. Topics are matched case-insensitively.
+	Topics []string
+
+	// ExcludeTopics excludes repositories labeled with any of the given topics.
+	ExcludeTopics []string
+
+	// Languages, if non-empty, includes only repositories whose primary language
+	// is any of the given languages. Languages are matched case-insensitively.
+	Languages []string
+
+	// Visibilities, if non-empty, includes only repositories with any of the
+	// given visibilities on their code host ("public", "private" or "internal").
+	Visibilities []string
+
+	// ServiceID, if non-empty, includes only repositories from the external
+	// service with the given ID (e.g. "https://github.com/").
+	ServiceID string
+
+	// MissingUpstream, if true, includes only repositories that went missing
+	// from their code host and are pending deletion, including quarantined
+	// ones. Otherwise, such repositories are excluded unless quarantined.
+	MissingUpstream bool
+
 	// OnlyRepoIDs fetches only the RepoIDs fields in each Repo.
 	OnlyRepoIDs bool
 
@@ -434,6 +463,27 @@ func (*repos) listSQL(opt ReposListOptions) (conds []*sqlf.Query, err error) {
 	if opt.OnlyArchived {
 		conds = append(conds, sqlf.Sprintf("archived"))
 	}
+	if len(opt.Topics) > 0 {
+		conds = append(conds, sqlf.Sprintf("topics @> %s", pq.Array(lowerAll(opt.Topics))))
+	}
+	if len(opt.ExcludeTopics) > 0 {
+		conds = append(conds, sqlf.Sprintf("NOT (topics && %s)", pq.Array(lowerAll(opt.ExcludeTopics))))
+	}
+	if len(opt.Languages) > 0 {
+		conds = append(conds, sqlf.Sprintf("lower(language) = ANY(%s)", pq.Array(lowerAll(opt.Languages))))
+	}
+	if len(opt.Visibilities) > 0 {
+		conds = append(conds, sqlf.Sprintf("visibility = ANY(%s)", pq.Array(lowerAll(opt.Visibilities))))
+	}
+	if opt.ServiceID != "" {
+		conds = append(conds, sqlf.Sprintf("external_service_id = %s", opt.ServiceID))
+	}
+
+	if opt.MissingUpstream {
+		conds = append(conds, sqlf.Sprintf("missing_upstream_at IS NOT NULL"))
+	} else {
+		conds = append(conds, sqlf.Sprintf("(missing_upstream_at IS NULL OR quarantined)"))
+	}
 
 	if opt.Index != nil {
 		// We don't currently have an index column, but when we want the
@@ -448,6 +498,15 @@ func (*repos) listSQL(opt ReposListOptions) (conds []*sqlf.Query, err error) {
 	return conds, nil
 }
 
+// lowerAll returns a copy of the given strings in lower case.
+func lowerAll(ss []string) []string {
+	lower := make([]string, len(ss))
+	for i, s := range ss {
+		lower[i] = strings.ToLower(s)
+	}
+	return lower
+}
+
 // parseIncludePattern either (1) parses the pattern into a list of exact possible
 // string values and LIKE patterns if such a list can be determined from the pattern,
 // and (2) returns the original regexp if those patterns are not equivalent to the
@@ -631,6 +690,33 @@ func (s *repos) SetEnabled(ctx context.Context, id api.RepoID, enabled bool) err
 	return nil
 }
 
+// RestoreQuarantined clears the missing upstream and quarantined state of the
+// given quarantined repository. If the repository is still missing from its
+// code host, the next sync marks it again.
+//
+// Repositories that are missing upstream but not quarantined can't be
+// restored, since they were likely removed from their code host on purpose.
+// They're restored by the next sync if they reappear on their code host.
+func (s *repos) RestoreQuarantined(ctx context.Context, id api.RepoID) error {
+	if Mocks.Repos.RestoreQuarantined != nil {
+		return Mocks.Repos.RestoreQuarantined(ctx, id)
+	}
+
+	q := sqlf.Sprintf("UPDATE repo SET missing_upstream_at=NULL, quarantined=false WHERE id=%d AND deleted_at IS NULL AND quarantined", id)
+	res, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.Errorf("repository %d not found or not quarantined", id)
+	}
+	return nil
+}
+
 func (s *repos) UpdateLanguage(ctx context.Context, repo api.RepoID, language string) error {
 	_, err := dbconn.Global.ExecContext(ctx, "UPDATE repo SET language=$1 WHERE id=$2", language, repo)
 	return err
@@ -7,11 +7,13 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/lib/pq"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db/query"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
 	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
 )
 
@@ -161,6 +163,145 @@ func TestRepos_List_fork(t *testing.T) {
 	}
 }
 
+func TestRepos_List_metadata(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	MockAuthzFilter = func(ctx context.Context, repos []*types.Repo, p authz.Perms) ([]*types.Repo, error) {
+		return repos, nil
+	}
+	defer func() { MockAuthzFilter = nil }()
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+	ctx = actor.WithActor(ctx, &actor.Actor{})
+
+	for _, r := range []struct {
+		name       api.RepoName
+		language   string
+		topics     []string
+		visibility string
+		serviceID  string
+	}{
+		{"a/r", "Go", []string{"go", "search"}, "public", "https://github.com/"},
+		{"b/r", "TypeScript", []string{"search"}, "private", "https://github.com/"},
+		{"c/r", "go", nil, "internal", "https://gitlab.com/"},
+	} {
+		createRepo(ctx, t, &types.Repo{Name: r.name})
+		_, err := dbconn.Global.ExecContext(ctx,
+			"UPDATE repo SET language = $1, topics = $2, visibility = $3, external_service_id = $4 WHERE name = $5",
+			r.language, pq.Array(r.topics), r.visibility, r.serviceID, r.name,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		opt  ReposListOptions
+		want []api.RepoName
+	}{
+		{"topic", ReposListOptions{Topics: []string{"search"}}, []api.RepoName{"a/r", "b/r"}},
+		{"topics are intersected", ReposListOptions{Topics: []string{"Search", "GO"}}, []api.RepoName{"a/r"}},
+		{"exclude topics", ReposListOptions{ExcludeTopics: []string{"go"}}, []api.RepoName{"b/r", "c/r"}},
+		{"languages", ReposListOptions{Languages: []string{"GO"}}, []api.RepoName{"a/r", "c/r"}},
+		{"visibilities", ReposListOptions{Visibilities: []string{"private", "internal"}}, []api.RepoName{"b/r", "c/r"}},
+		{"service ID", ReposListOptions{ServiceID: "https://github.com/"}, []api.RepoName{"a/r", "b/r"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.opt.Enabled = true
+			repos, err := Repos.List(ctx, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := repoNames(repos); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got repos %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRepos_List_missingUpstream(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	MockAuthzFilter = func(ctx context.Context, repos []*types.Repo, p authz.Perms) ([]*types.Repo, error) {
+		return repos, nil
+	}
+	defer func() { MockAuthzFilter = nil }()
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+	ctx = actor.WithActor(ctx, &actor.Actor{})
+
+	for _, r := range []struct {
+		name        api.RepoName
+		missing     bool
+		quarantined bool
+	}{
+		{"a/r", false, false},
+		{"b/r", true, false},
+		{"c/r", true, true},
+	} {
+		createRepo(ctx, t, &types.Repo{Name: r.name})
+		if !r.missing {
+			continue
+		}
+		_, err := dbconn.Global.ExecContext(ctx,
+			"UPDATE repo SET missing_upstream_at = now(), quarantined = $1 WHERE name = $2",
+			r.quarantined, r.name,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := func(opt ReposListOptions) []api.RepoName {
+		t.Helper()
+		opt.Enabled = true
+		repos, err := Repos.List(ctx, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repoNames(repos)
+	}
+
+	if got, want := list(ReposListOptions{}), []api.RepoName{"a/r", "c/r"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got repos %q, want %q", got, want)
+	}
+
+	if got, want := list(ReposListOptions{MissingUpstream: true}), []api.RepoName{"b/r", "c/r"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got missing upstream repos %q, want %q", got, want)
+	}
+
+	repo, err := Repos.GetByName(ctx, "b/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.MissingUpstreamAt == nil {
+		t.Errorf("got nil MissingUpstreamAt for %q", repo.Name)
+	}
+
+	// Repos that aren't quarantined were likely removed on purpose.
+	if err := Repos.RestoreQuarantined(ctx, repo.ID); err == nil {
+		t.Errorf("restored repo %q that isn't quarantined", repo.Name)
+	}
+
+	repo, err = Repos.GetByName(ctx, "c/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Repos.RestoreQuarantined(ctx, repo.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := list(ReposListOptions{MissingUpstream: true}), []api.RepoName{"b/r"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got missing upstream repos after restore %q, want %q", got, want)
+	}
+}
+
 func TestRepos_List_pagination(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
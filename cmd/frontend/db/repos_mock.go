@@ -16,6 +16,8 @@ type MockRepos struct {
 	Delete    func(ctx context.Context, repo api.RepoID) error
 	Count     func(ctx context.Context, opt ReposListOptions) (int, error)
 	Upsert    func(api.InsertRepoOp) error
+
+	RestoreQuarantined func(ctx context.Context, repo api.RepoID) error
 }
 
 func (s *MockRepos) MockGet(t *testing.T, wantRepo api.RepoID) (called *bool) {
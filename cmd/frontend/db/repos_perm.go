@@ -3,12 +3,15 @@ package db
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/RoaringBitmap/roaring"
 	otlog "github.com/opentracing/opentracing-go/log"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
 	"github.com/sourcegraph/sourcegraph/pkg/trace"
 	"gopkg.in/inconshreveable/log15.v2"
@@ -27,6 +30,9 @@ var MockAuthzFilter func(ctx context.Context, repos []*types.Repo, p authz.Perms
 //
 // The enforcement policy:
 //
+// - If the request was authenticated with an access token restricted to some repositories, other
+//   repositories are never returned, even to site admins.
+//
 // - If there are no authz providers and `authzAllowByDefault` is true, then the repository is
 //   accessible to everyone.
 //
@@ -35,11 +41,22 @@ var MockAuthzFilter func(ctx context.Context, repos []*types.Repo, p authz.Perms
 //   *never* return the repository.
 //
 // - Scan through the list of authz providers until we find one that matches the repository. Return
-//   whether or not the repository accessible according to that authz provider.
+//   whether or not the repository accessible according to that authz provider. If permissions
+//   are synced in the background, the user's fresh stored permissions from that authz provider
+//   are used for the repositories they cover, and the authz provider is only asked about the rest.
+//
+// - If permissions granted explicitly by site admins are enforced, return the repositories they're
+//   granted on to the user or to an org the user is a member of.
 //
 // - If no authz providers match the repository, consult `authzAllowByDefault`. If true, then return
-//   the repository; otherwise, do not.
-func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filtered []*types.Repo, err error) {
+//   the repository, unless it has explicitly granted permissions; otherwise, do not.
+func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) ([]*types.Repo, error) {
+	return explainedAuthzFilter(ctx, repos, p, nil)
+}
+
+// explainedAuthzFilter is authzFilter, but if d is non-nil, it also records in d how it decided
+// whether to return the repository. It must only be given a single repository then.
+func explainedAuthzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms, d *AuthzDecision) (filtered []*types.Repo, err error) {
 	var currentUser *types.User
 
 	tr, ctx := trace.New(ctx, "authzFilter", "")
@@ -71,6 +88,21 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 		return repos, nil
 	}
 
+	// 🚨 SECURITY: Access tokens may be restricted to some repositories, whatever the permissions
+	// of their user are.
+	if tr := authz.TokenRestrictionsFromContext(ctx); tr != nil && len(tr.RepoPatterns) > 0 {
+		allowed := repos[:0]
+		for _, r := range repos {
+			if tr.AllowsRepo(r.Name) {
+				allowed = append(allowed, r)
+			}
+		}
+		clear(repos[len(allowed):])
+		if repos = allowed; len(repos) == 0 {
+			return repos, nil
+		}
+	}
+
 	if isInternalActor(ctx) {
 		return repos, nil
 	}
@@ -82,12 +114,23 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 			return nil, err
 		}
 		if currentUser.SiteAdmin {
+			if d != nil {
+				d.SiteAdminBypass = true
+			}
 			return repos, nil
 		}
 	}
 
 	authzAllowByDefault, authzProviders := authz.GetProviders()
-	if authzAllowByDefault && len(authzProviders) == 0 {
+	explicit := conf.ExplicitPermissionsEnabled()
+	if d != nil {
+		d.AuthzAllowByDefault = authzAllowByDefault
+		d.ExplicitPermissionsEnabled = explicit
+	}
+	if authzAllowByDefault && len(authzProviders) == 0 && !explicit {
+		if d != nil {
+			d.AllowedByDefault = true
+		}
 		return repos, nil
 	}
 
@@ -99,6 +142,16 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 		}
 	}
 
+	// If permissions are synced in the background, use the stored permissions
+	// of the user for the repos they cover instead of asking the providers.
+	var stored map[storedPermissionsKey]*UserPermissions
+	if len(authzProviders) > 0 && currentUser != nil && conf.PermissionsSyncEnabled() {
+		stored, err = freshUserPermissions(ctx, currentUser.ID, p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	toverify := make(map[string]*[]*types.Repo, len(authzProviders))
 	for _, r := range repos {
 		group := toverify[r.ExternalRepo.ServiceID]
@@ -117,6 +170,12 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 	// repo, we use its permissions for that repo.
 	verified := roaring.NewBitmap()
 	for _, authzProvider := range authzProviders {
+		var pd *ProviderDecision
+		if d != nil {
+			pd = &ProviderDecision{ServiceType: authzProvider.ServiceType(), ServiceID: authzProvider.ServiceID()}
+			d.Providers = append(d.Providers, pd)
+		}
+
 		// determine external account to use
 		var providerAcct *extsvc.ExternalAccount
 		for _, acct := range accts {
@@ -129,7 +188,10 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 		if providerAcct == nil && currentUser != nil { // no existing external account for authz provider
 			if pr, err := authzProvider.FetchAccount(ctx, currentUser, accts); err == nil {
 				providerAcct = pr
-				if providerAcct != nil {
+				if providerAcct != nil && pd != nil {
+					// Explaining a decision must not have side effects, so the account isn't saved.
+					pd.AccountFetched = true
+				} else if providerAcct != nil {
 					err := ExternalAccounts.AssociateUserAndSave(ctx, currentUser.ID, providerAcct.ExternalAccountSpec, providerAcct.ExternalAccountData)
 					if err != nil {
 						return nil, err
@@ -137,17 +199,54 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 				}
 			} else {
 				log15.Warn("Could not fetch authz provider account for user", "username", currentUser.Username, "authzProvider", authzProvider.ServiceID(), "error", err)
+				if pd != nil {
+					pd.FetchAccountError = err.Error()
+				}
 			}
 		}
+		if pd != nil {
+			pd.Account = providerAcct
+		}
 
 		serviceID := authzProvider.ServiceID()
 		ours, ok := toverify[serviceID]
 		if !ok {
 			continue
 		}
+		if pd != nil {
+			pd.OwnsRepo = true
+		}
+
+		if sp, ok := stored[storedPermissionsKey{authzProvider.ServiceType(), serviceID}]; ok {
+			rest := make([]*types.Repo, 0, len(*ours))
+			for _, r := range *ours {
+				switch {
+				case !sp.Covers(r.ID):
+					rest = append(rest, r)
+					continue
+				case sp.RepoIDs.Contains(uint32(r.ID)):
+					verified.Add(uint32(r.ID))
+					if pd != nil {
+						pd.Perms = p
+					}
+				}
+				if pd != nil {
+					pd.FromStore = true
+				}
+			}
+			if len(rest) == 0 {
+				delete(toverify, serviceID)
+				continue
+			}
+			ours = &rest
+		}
 
 		// check the perms on our repos
-		perms, err := authzProvider.RepoPerms(ctx, providerAcct, *ours)
+		rctx := ctx
+		if pd != nil {
+			rctx = authz.WithCacheStats(ctx, &pd.Cache)
+		}
+		perms, err := authzProvider.RepoPerms(rctx, providerAcct, *ours)
 		if err != nil {
 			return nil, err
 		}
@@ -156,21 +255,58 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 			if r.Perms.Include(p) {
 				verified.Add(uint32(r.Repo.ID))
 			}
+			if pd != nil {
+				pd.Perms |= r.Perms
+			}
 		}
 
 		delete(toverify, serviceID)
 	}
 
+	// Permissions granted explicitly by site admins are an additional source of permissions,
+	// for repos whose code host has no permissions API.
+	restricted := roaring.NewBitmap()
+	if explicit {
+		var userID int32
+		if currentUser != nil {
+			userID = currentUser.ID
+		}
+
+		ids := make([]api.RepoID, len(repos))
+		for i, r := range repos {
+			ids[i] = r.ID
+		}
+
+		var granted *roaring.Bitmap
+		if restricted, granted, err = Permissions.LoadExplicit(ctx, userID, p, ids); err != nil {
+			return nil, err
+		}
+		verified.Or(granted)
+
+		if d != nil {
+			d.ExplicitlyRestricted = restricted.Contains(uint32(repos[0].ID))
+			d.ExplicitlyGranted = granted.Contains(uint32(repos[0].ID))
+		}
+	}
+
 	if authzAllowByDefault {
 		for serviceID, rs := range toverify {
 			// 🚨 SECURITY: Defensively bar access to repos with no external repo spec (we don't know
-			// where they came from, so can't reliably enforce permissions).
-			if serviceID == "" {
+			// where they came from, so can't reliably enforce permissions), unless there are no
+			// authz providers at all.
+			if serviceID == "" && len(authzProviders) > 0 {
 				continue
 			}
 
 			for _, r := range *rs {
-				verified.Add(uint32(r.ID))
+				// 🚨 SECURITY: Repos with explicitly granted permissions are never accessible by
+				// default.
+				if !restricted.Contains(uint32(r.ID)) {
+					verified.Add(uint32(r.ID))
+					if d != nil {
+						d.AllowedByDefault = true
+					}
+				}
 			}
 		}
 	}
@@ -187,6 +323,35 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 	return filtered, nil
 }
 
+// storedPermissionsKey identifies the authz provider stored permissions were
+// synced from.
+type storedPermissionsKey struct {
+	serviceType, serviceID string
+}
+
+// freshUserPermissions returns the stored permissions of the user for perm
+// that were synced recently enough to be used, by the authz provider they
+// were synced from. Permissions that missed more than a couple of background
+// syncs are ignored, so that the providers are asked directly if the syncer
+// falls behind.
+func freshUserPermissions(ctx context.Context, userID int32, perm authz.Perms) (map[storedPermissionsKey]*UserPermissions, error) {
+	ps, err := Permissions.ListUserPermissions(ctx, userID, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAge := 3 * conf.PermissionsSyncInterval()
+	now := time.Now()
+
+	fresh := make(map[storedPermissionsKey]*UserPermissions, len(ps))
+	for _, p := range ps {
+		if !p.Stale(maxAge, now) {
+			fresh[storedPermissionsKey{p.ServiceType, p.ServiceID}] = p
+		}
+	}
+	return fresh, nil
+}
+
 // isInternalActor returns true if the actor represents an internal agent (i.e., non-user-bound
 // request that originates from within Sourcegraph itself).
 //
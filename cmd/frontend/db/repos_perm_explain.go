@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+)
+
+// AuthzDecision describes how authzFilter decided whether a user has a
+// permission on a repository. It is meant for site admins debugging
+// repository permissions.
+type AuthzDecision struct {
+	Perm    authz.Perms
+	Allowed bool
+
+	// SiteAdminBypass is whether the user is a site admin, who has access to
+	// all repositories. Nothing else is checked then.
+	SiteAdminBypass bool
+
+	// AuthzAllowByDefault is the authzAllowByDefault setting of the authz
+	// providers.
+	AuthzAllowByDefault bool
+
+	// Providers are the authz providers checked, in order.
+	Providers []*ProviderDecision
+
+	ExplicitPermissionsEnabled bool
+	ExplicitlyRestricted       bool // the repository has explicitly granted permissions
+	ExplicitlyGranted          bool // the permission is explicitly granted to the user
+
+	// AllowedByDefault is whether the repository was allowed because no authz
+	// provider owns it and authzAllowByDefault is true.
+	AllowedByDefault bool
+}
+
+// ProviderDecision describes what an authz provider contributed to an
+// AuthzDecision.
+type ProviderDecision struct {
+	ServiceType, ServiceID string
+
+	// Account is the external account of the user on the provider, if any.
+	// AccountFetched is whether it was fetched from the provider by this
+	// check, instead of being already associated with the user. Unlike in
+	// authzFilter, a fetched account is not saved.
+	Account           *extsvc.ExternalAccount
+	AccountFetched    bool
+	FetchAccountError string
+
+	// OwnsRepo is whether the repository is on the provider's code host. The
+	// fields below are only set if it is.
+	OwnsRepo bool
+
+	// FromStore is whether the permissions were read from the permissions
+	// synced in the background, instead of asking the provider.
+	FromStore bool
+	Perms     authz.Perms
+
+	// Cache is how many repositories' permissions the provider computed from
+	// its own cache, if it asked the provider and the provider reports it.
+	Cache authz.CacheStats
+
+	// LastSyncedAt is when the permissions of the user were last synced in
+	// the background from the provider, or zero if they never were.
+	LastSyncedAt time.Time
+}
+
+// Explain checks whether the user has perm on the repository, as authzFilter
+// would for a request by the user (not restricted by the caller's access
+// token), and returns how it was decided. It does not save the external
+// accounts it fetches from authz providers.
+//
+// 🚨 SECURITY: The decision reveals the user's permissions and external
+// accounts. Callers must ensure only site admins can see it.
+func (*permissions) Explain(ctx context.Context, userID int32, repo *types.Repo, perm authz.Perms) (*AuthzDecision, error) {
+	d := &AuthzDecision{Perm: perm}
+
+	// The caller's access token restrictions don't apply to the user.
+	ctx = authz.WithTokenRestrictions(ctx, nil)
+	ctx = actor.WithActor(ctx, &actor.Actor{UID: userID})
+	filtered, err := explainedAuthzFilter(ctx, []*types.Repo{repo}, perm, d)
+	if err != nil {
+		return nil, err
+	}
+	d.Allowed = len(filtered) == 1
+
+	if len(d.Providers) == 0 || userID == 0 {
+		return d, nil
+	}
+
+	ps, err := Permissions.ListUserPermissions(ctx, userID, perm)
+	if err != nil {
+		return nil, err
+	}
+	for _, pd := range d.Providers {
+		for _, p := range ps {
+			if p.ServiceType == pd.ServiceType && p.ServiceID == pd.ServiceID {
+				pd.LastSyncedAt = p.SyncedAt
+			}
+		}
+	}
+	return d, nil
+}
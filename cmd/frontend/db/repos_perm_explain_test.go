@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestPermissions_Explain(t *testing.T) {
+	users := map[int32]*types.User{
+		1: {ID: 1},
+		2: {ID: 2, SiteAdmin: true},
+		3: {ID: 3},
+	}
+	userAcct := acct(1, "gitlab", "https://gitlab.mine/", "u1")
+	oktaAcct := acct(3, "okta", "https://okta.mine/", "u3")
+	accts := map[int32][]*extsvc.ExternalAccount{1: {userAcct}, 3: {oktaAcct}}
+	repos := makeRepos("gitlab.mine/a", "gitlab.mine/b", "gitolite.mine/c")
+
+	authz.SetProviders(true, []authz.Provider{
+		cachingAuthzProvider{&MockAuthzProvider{
+			serviceID:    "https://gitlab.mine/",
+			serviceType:  "gitlab",
+			okServiceIDs: map[string]struct{}{"https://okta.mine/": {}},
+			perms: map[extsvc.ExternalAccount]map[api.RepoName]authz.Perms{
+				*userAcct: {"gitlab.mine/b": authz.Read},
+				*acct(3, "gitlab", "https://gitlab.mine/", "u3"): {"gitlab.mine/b": authz.Read},
+			},
+		}},
+	})
+	defer authz.SetProviders(true, nil)
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		PermissionsBackgroundSync: &schema.PermissionsBackgroundSync{Enabled: true},
+	}})
+	defer conf.Mock(nil)
+
+	syncedAt := time.Now().Add(-time.Minute)
+	Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return users[actor.FromContext(ctx).UID], nil
+	}
+	Mocks.ExternalAccounts.List = func(opt ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) {
+		return accts[opt.UserID], nil
+	}
+	Mocks.ExternalAccounts.AssociateUserAndSave = func(int32, extsvc.ExternalAccountSpec, extsvc.ExternalAccountData) error {
+		t.Error("Explain must not save external accounts")
+		return nil
+	}
+	Mocks.Permissions.ListUserPermissions = func(ctx context.Context, userID int32, perm authz.Perms) ([]*UserPermissions, error) {
+		// Covers a, but not b.
+		return []*UserPermissions{{
+			UserID:      userID,
+			Perm:        perm,
+			ServiceType: "gitlab",
+			ServiceID:   "https://gitlab.mine/",
+			RepoIDs:     roaring.BitmapOf(1),
+			MaxRepoID:   1,
+			SyncedAt:    syncedAt,
+		}}, nil
+	}
+	defer func() { Mocks = MockStores{} }()
+
+	explain := func(ctx context.Context, userID int32, repo *types.Repo) *AuthzDecision {
+		t.Helper()
+		d, err := Permissions.Explain(ctx, userID, repo, authz.Read)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	ctx := context.Background()
+
+	t.Run("site admin", func(t *testing.T) {
+		d := explain(ctx, 2, repos[0])
+		if !d.Allowed || !d.SiteAdminBypass || len(d.Providers) != 0 {
+			t.Errorf("got unexpected decision %+v", d)
+		}
+	})
+
+	t.Run("stored permissions", func(t *testing.T) {
+		d := explain(ctx, 1, repos[0])
+		if !d.Allowed || d.SiteAdminBypass || !d.AuthzAllowByDefault || d.AllowedByDefault || len(d.Providers) != 1 {
+			t.Fatalf("got unexpected decision %+v", d)
+		}
+		pd := d.Providers[0]
+		if pd.Account != userAcct || pd.AccountFetched || !pd.OwnsRepo || !pd.FromStore || pd.Perms != authz.Read || !pd.LastSyncedAt.Equal(syncedAt) {
+			t.Errorf("got unexpected provider decision %+v", pd)
+		}
+	})
+
+	t.Run("authz provider", func(t *testing.T) {
+		d := explain(ctx, 1, repos[1])
+		if !d.Allowed || len(d.Providers) != 1 {
+			t.Fatalf("got unexpected decision %+v", d)
+		}
+		if pd := d.Providers[0]; !pd.OwnsRepo || pd.FromStore || pd.Perms != authz.Read || pd.Cache != (authz.CacheStats{Hits: 1}) {
+			t.Errorf("got unexpected provider decision %+v", pd)
+		}
+	})
+
+	t.Run("fetched account", func(t *testing.T) {
+		d := explain(ctx, 3, repos[1])
+		if !d.Allowed || len(d.Providers) != 1 {
+			t.Fatalf("got unexpected decision %+v", d)
+		}
+		if pd := d.Providers[0]; !pd.AccountFetched || pd.Account == nil || pd.Account.AccountID != "u3" {
+			t.Errorf("got unexpected provider decision %+v", pd)
+		}
+	})
+
+	t.Run("ignores the caller's token restrictions", func(t *testing.T) {
+		ctx := authz.WithTokenRestrictions(ctx, &authz.TokenRestrictions{
+			RepoPatterns: []*regexp.Regexp{regexp.MustCompile(`^gitlab\.mine/a$`)},
+		})
+		if d := explain(ctx, 1, repos[1]); !d.Allowed {
+			t.Errorf("got unexpected decision %+v", d)
+		}
+	})
+
+	t.Run("allowed by default", func(t *testing.T) {
+		d := explain(ctx, 1, repos[2])
+		if !d.Allowed || !d.AllowedByDefault || len(d.Providers) != 1 || d.Providers[0].OwnsRepo {
+			t.Errorf("got unexpected decision %+v", d)
+		}
+	})
+}
+
+// cachingAuthzProvider is a MockAuthzProvider that reports computing all permissions from its
+// cache.
+type cachingAuthzProvider struct{ *MockAuthzProvider }
+
+func (p cachingAuthzProvider) RepoPerms(ctx context.Context, acct *extsvc.ExternalAccount, repos []*types.Repo) ([]authz.RepoPerms, error) {
+	authz.RecordCacheStats(ctx, len(repos), 0)
+	return p.MockAuthzProvider.RepoPerms(ctx, acct, repos)
+}
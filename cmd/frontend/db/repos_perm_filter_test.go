@@ -5,14 +5,19 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"regexp"
 	"strconv"
 	"testing"
+	"time"
 
+	"github.com/RoaringBitmap/roaring"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 type authzFilter_Test struct {
@@ -77,6 +82,14 @@ func (r authzFilter_Test) run(t *testing.T) {
 	}
 }
 
+func repoNamesToStrings(names []api.RepoName) []string {
+	ss := make([]string, len(names))
+	for i, name := range names {
+		ss[i] = string(name)
+	}
+	return ss
+}
+
 func getNames(rs []*types.Repo) []string {
 	a := make([]string, len(rs))
 	for i, v := range rs {
@@ -778,6 +791,154 @@ func Test_authzFilter_createsNewUsers(t *testing.T) {
 	}
 }
 
+func Test_authzFilter_storedPermissions(t *testing.T) {
+	user := &types.User{ID: 1}
+	userAcct := acct(1, "gitlab", "https://gitlab.mine/", "u1")
+	repos := makeRepos("gitlab.mine/a", "gitlab.mine/b", "gitlab.mine/c")
+
+	authz.SetProviders(false, []authz.Provider{
+		&MockAuthzProvider{
+			serviceID:   "https://gitlab.mine/",
+			serviceType: "gitlab",
+			perms: map[extsvc.ExternalAccount]map[api.RepoName]authz.Perms{
+				*userAcct: {
+					"gitlab.mine/a": authz.Read,
+					"gitlab.mine/c": authz.Read,
+				},
+			},
+		},
+	})
+	defer authz.SetProviders(true, nil)
+
+	Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) { return user, nil }
+	Mocks.ExternalAccounts.List = func(ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) {
+		return []*extsvc.ExternalAccount{userAcct}, nil
+	}
+	defer func() { Mocks = MockStores{} }()
+
+	// The stored permissions grant b, which the provider doesn't, and cover
+	// a and b but not c, which was added after they were synced.
+	var syncedAt time.Time
+	Mocks.Permissions.ListUserPermissions = func(ctx context.Context, userID int32, perm authz.Perms) ([]*UserPermissions, error) {
+		return []*UserPermissions{{
+			UserID:      userID,
+			Perm:        perm,
+			ServiceType: "gitlab",
+			ServiceID:   "https://gitlab.mine/",
+			RepoIDs:     roaring.BitmapOf(2),
+			MaxRepoID:   2,
+			SyncedAt:    syncedAt,
+		}}, nil
+	}
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		syncedAt time.Time
+		want     []api.RepoName
+	}{
+		{"sync disabled", false, time.Now(), []api.RepoName{"gitlab.mine/a", "gitlab.mine/c"}},
+		{"fresh", true, time.Now().Add(-time.Hour), []api.RepoName{"gitlab.mine/b", "gitlab.mine/c"}},
+		{"stale", true, time.Now().Add(-4 * time.Hour), []api.RepoName{"gitlab.mine/a", "gitlab.mine/c"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				PermissionsBackgroundSync: &schema.PermissionsBackgroundSync{Enabled: test.enabled},
+			}})
+			defer conf.Mock(nil)
+			syncedAt = test.syncedAt
+
+			ctx := actor.WithActor(context.Background(), &actor.Actor{UID: user.ID})
+			filtered, err := authzFilter(ctx, append([]*types.Repo(nil), repos...), authz.Read)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []api.RepoName
+			for _, r := range filtered {
+				got = append(got, r.Name)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func Test_authzFilter_explicitPermissions(t *testing.T) {
+	authz.SetProviders(true, nil)
+
+	// gitolite.mine/b and gitolite.mine/c have explicit grants, and c is
+	// granted to user 1.
+	Mocks.Permissions.LoadExplicit = func(ctx context.Context, userID int32, perm authz.Perms, repoIDs []api.RepoID) (restricted, granted *roaring.Bitmap, err error) {
+		if want := []api.RepoID{1, 2, 3, 4}; !reflect.DeepEqual(repoIDs, want) {
+			t.Errorf("got repo IDs %v, want %v", repoIDs, want)
+		}
+		granted = roaring.NewBitmap()
+		if userID == 1 {
+			granted.Add(3)
+		}
+		return roaring.BitmapOf(2, 3), granted, nil
+	}
+	Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: actor.FromContext(ctx).UID}, nil
+	}
+	defer func() { Mocks = MockStores{} }()
+
+	tests := []struct {
+		name    string
+		enabled bool
+		userID  int32
+		want    []api.RepoName
+	}{
+		{"disabled", false, 1, []api.RepoName{"gitolite.mine/a", "gitolite.mine/b", "gitolite.mine/c", "no-spec"}},
+		{"granted", true, 1, []api.RepoName{"gitolite.mine/a", "gitolite.mine/c", "no-spec"}},
+		{"not granted", true, 2, []api.RepoName{"gitolite.mine/a", "no-spec"}},
+		{"unauthenticated", true, 0, []api.RepoName{"gitolite.mine/a", "no-spec"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				PermissionsExplicit: &schema.PermissionsExplicit{Enabled: test.enabled},
+			}})
+			defer conf.Mock(nil)
+
+			repos := append(makeRepos("gitolite.mine/a", "gitolite.mine/b", "gitolite.mine/c"), &types.Repo{ID: 4, Name: "no-spec"})
+			ctx := actor.WithActor(context.Background(), &actor.Actor{UID: test.userID})
+			filtered, err := authzFilter(ctx, repos, authz.Read)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := getNames(filtered); !reflect.DeepEqual(got, repoNamesToStrings(test.want)) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func Test_authzFilter_tokenRepoPatterns(t *testing.T) {
+	authz.SetProviders(true, nil)
+	Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	defer func() { Mocks = MockStores{} }()
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	ctx = authz.WithTokenRestrictions(ctx, &authz.TokenRestrictions{
+		Scopes:       []string{authz.ScopeUserRead},
+		RepoPatterns: []*regexp.Regexp{regexp.MustCompile("^gitlab.mine/a"), regexp.MustCompile("c$")},
+	})
+	filtered, err := authzFilter(ctx, makeRepos("gitlab.mine/a", "gitlab.mine/b", "gitlab.mine/c"), authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := getNames(filtered), []string{"gitlab.mine/a", "gitlab.mine/c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
 func acct(userID int32, serviceType, serviceID, accountID string) *extsvc.ExternalAccount {
 	return &extsvc.ExternalAccount{
 		UserID: userID,
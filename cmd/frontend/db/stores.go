@@ -17,10 +17,14 @@ var (
 	Users                     = &users{}
 	UserEmails                = &userEmails{}
 	EventLogs                 = &eventLogs{}
+	AuditLog                  = &auditLog{}
+	TOTPEnrollments           = &totpEnrollments{}
 
 	SurveyResponses = &surveyResponses{}
 
 	ExternalAccounts = &userExternalAccounts{}
 
+	Permissions = &permissions{}
+
 	OrgInvitations = &orgInvitations{}
 )
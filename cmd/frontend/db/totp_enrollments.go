@@ -0,0 +1,240 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/pkg/randstring"
+	"github.com/sourcegraph/sourcegraph/pkg/totp"
+)
+
+// TOTPEnrollment describes a user's enrollment in multi-factor authentication with time-based one-time
+// passwords (TOTP) for signing in with the builtin username-password auth provider.
+type TOTPEnrollment struct {
+	UserID int32
+
+	// Secret is the secret that the user's authenticator app generates codes from. It is stored
+	// unencrypted.
+	Secret string
+
+	// LastUsedCounter is the time step counter of the last code that was accepted. Codes for
+	// this or an earlier time step are rejected, so that a code can't be used more than once.
+	LastUsedCounter int64
+
+	// RecoveryCodesRemaining is the number of unused recovery codes. Sourcegraph only stores
+	// hashes of recovery codes, so the codes themselves can't be retrieved after enrollment.
+	RecoveryCodesRemaining int
+
+	CreatedAt time.Time
+
+	// EnabledAt is when the user confirmed the enrollment by entering a valid code. If nil, the
+	// enrollment is pending and is not required (or accepted) when signing in, except to confirm
+	// it.
+	EnabledAt *time.Time
+}
+
+// Enabled reports whether the enrollment was confirmed, so the user must enter a code to sign in.
+func (t *TOTPEnrollment) Enabled() bool { return t.EnabledAt != nil }
+
+// ErrTOTPEnrollmentNotFound occurs when a database operation expects a user to be enrolled in TOTP
+// multi-factor authentication but the user is not.
+var ErrTOTPEnrollmentNotFound = errors.New("user is not enrolled in multi-factor authentication")
+
+// ErrTOTPEnrollmentAlreadyEnabled occurs when creating a TOTP enrollment for a user who already
+// has an enabled enrollment. The existing enrollment must be reset (deleted) first.
+var ErrTOTPEnrollmentAlreadyEnabled = errors.New("user is already enrolled in multi-factor authentication")
+
+const (
+	// numRecoveryCodes is the number of recovery codes generated for each enrollment.
+	numRecoveryCodes = 10
+
+	recoveryCodeLen = 10
+)
+
+var recoveryCodeChars = []byte("abcdefghijkmnpqrstuvwxyz23456789") // no easily confused characters
+
+type totpEnrollments struct{}
+
+// CreatePending creates a pending TOTP enrollment for the user, replacing any existing pending
+// enrollment, and returns its secret and recovery codes. The caller is responsible for presenting
+// these to the end user; Sourcegraph retains the secret, but only hashes of the recovery codes.
+//
+// The enrollment takes effect when it is enabled (with Enable) after the user has proven that they
+// added the secret to their authenticator app. If the user already has an enabled enrollment,
+// ErrTOTPEnrollmentAlreadyEnabled is returned.
+//
+// Recovery codes are long random strings (not passwords), so they are hashed with SHA-256 (like
+// access tokens) instead of bcrypt.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to enroll the user (i.e., that the
+// actor is the user or has just authenticated as the user with their password).
+func (*totpEnrollments) CreatePending(ctx context.Context, userID int32) (secret string, recoveryCodes []string, err error) {
+	if Mocks.TOTPEnrollments.CreatePending != nil {
+		return Mocks.TOTPEnrollments.CreatePending(userID)
+	}
+
+	secret, err = totp.GenerateSecret()
+	if err != nil {
+		return "", nil, err
+	}
+	recoveryCodes = make([]string, numRecoveryCodes)
+	hashes := make([][]byte, numRecoveryCodes)
+	for i := range recoveryCodes {
+		code := randstring.NewLenChars(recoveryCodeLen, recoveryCodeChars)
+		recoveryCodes[i] = code[:recoveryCodeLen/2] + "-" + code[recoveryCodeLen/2:]
+		hashes[i] = toSHA256Bytes([]byte(code))
+	}
+
+	res, err := dbconn.Global.ExecContext(ctx, `
+INSERT INTO user_totp(user_id, secret, recovery_code_hashes) VALUES($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE SET secret=excluded.secret, recovery_code_hashes=excluded.recovery_code_hashes, last_used_counter=0, created_at=now()
+WHERE user_totp.enabled_at IS NULL`,
+		userID, secret, pq.Array(hashes),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return "", nil, err
+	}
+	if nrows == 0 {
+		return "", nil, ErrTOTPEnrollmentAlreadyEnabled
+	}
+	return secret, recoveryCodes, nil
+}
+
+// GetByUserID returns the user's TOTP enrollment (which may be pending). If the user has none,
+// ErrTOTPEnrollmentNotFound is returned.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to view the user's enrollment,
+// which includes its secret.
+func (*totpEnrollments) GetByUserID(ctx context.Context, userID int32) (*TOTPEnrollment, error) {
+	if Mocks.TOTPEnrollments.GetByUserID != nil {
+		return Mocks.TOTPEnrollments.GetByUserID(userID)
+	}
+
+	t := TOTPEnrollment{UserID: userID}
+	if err := dbconn.Global.QueryRowContext(ctx,
+		"SELECT secret, last_used_counter, cardinality(recovery_code_hashes), created_at, enabled_at FROM user_totp WHERE user_id=$1",
+		userID,
+	).Scan(&t.Secret, &t.LastUsedCounter, &t.RecoveryCodesRemaining, &t.CreatedAt, &t.EnabledAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTOTPEnrollmentNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Enable enables the user's pending TOTP enrollment. If the user has no pending enrollment,
+// ErrTOTPEnrollmentNotFound is returned.
+//
+// 🚨 SECURITY: The caller must ensure that the user entered a valid code for the enrollment (and
+// used it with UseCounter).
+func (*totpEnrollments) Enable(ctx context.Context, userID int32) error {
+	if Mocks.TOTPEnrollments.Enable != nil {
+		return Mocks.TOTPEnrollments.Enable(userID)
+	}
+
+	res, err := dbconn.Global.ExecContext(ctx, "UPDATE user_totp SET enabled_at=now() WHERE user_id=$1 AND enabled_at IS NULL", userID)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return ErrTOTPEnrollmentNotFound
+	}
+	return nil
+}
+
+// Delete deletes the user's TOTP enrollment (which may be pending), so the user can sign in with
+// only their password (unless multi-factor authentication is required) or enroll again. If the
+// user has none, ErrTOTPEnrollmentNotFound is returned.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to reset the user's
+// multi-factor authentication (i.e., that the actor is either the user or a site admin).
+func (*totpEnrollments) Delete(ctx context.Context, userID int32) error {
+	if Mocks.TOTPEnrollments.Delete != nil {
+		return Mocks.TOTPEnrollments.Delete(userID)
+	}
+
+	res, err := dbconn.Global.ExecContext(ctx, "DELETE FROM user_totp WHERE user_id=$1", userID)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return ErrTOTPEnrollmentNotFound
+	}
+	return nil
+}
+
+// UseCounter records that a code for the time step counter was accepted for the user. It returns
+// false if a code for the same or a later time step was already accepted, in which case the caller
+// must reject the code (because it is being reused).
+func (*totpEnrollments) UseCounter(ctx context.Context, userID int32, counter int64) (ok bool, err error) {
+	if Mocks.TOTPEnrollments.UseCounter != nil {
+		return Mocks.TOTPEnrollments.UseCounter(userID, counter)
+	}
+
+	res, err := dbconn.Global.ExecContext(ctx, "UPDATE user_totp SET last_used_counter=$2 WHERE user_id=$1 AND last_used_counter < $2", userID, counter)
+	if err != nil {
+		return false, err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return nrows == 1, nil
+}
+
+// UseRecoveryCode checks whether the recovery code is one of the unused recovery codes of the
+// user's enabled TOTP enrollment. If so, it marks the recovery code as used (so it can't be used
+// again) and returns true.
+func (*totpEnrollments) UseRecoveryCode(ctx context.Context, userID int32, recoveryCode string) (ok bool, err error) {
+	if Mocks.TOTPEnrollments.UseRecoveryCode != nil {
+		return Mocks.TOTPEnrollments.UseRecoveryCode(userID, recoveryCode)
+	}
+
+	hash := toSHA256Bytes([]byte(normalizeRecoveryCode(recoveryCode)))
+	res, err := dbconn.Global.ExecContext(ctx, `
+UPDATE user_totp SET recovery_code_hashes=array_remove(recovery_code_hashes, $2::bytea)
+WHERE user_id=$1 AND enabled_at IS NOT NULL AND $2::bytea = ANY(recovery_code_hashes)`,
+		userID, hash,
+	)
+	if err != nil {
+		return false, err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return nrows == 1, nil
+}
+
+// normalizeRecoveryCode removes the separators and case differences that users might introduce
+// when entering a recovery code.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+type MockTOTPEnrollments struct {
+	CreatePending   func(userID int32) (secret string, recoveryCodes []string, err error)
+	GetByUserID     func(userID int32) (*TOTPEnrollment, error)
+	Enable          func(userID int32) error
+	Delete          func(userID int32) error
+	UseCounter      func(userID int32, counter int64) (bool, error)
+	UseRecoveryCode func(userID int32, recoveryCode string) (bool, error)
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+// 🚨 SECURITY: This tests the routines that enroll users in multi-factor authentication and accept
+// their codes and recovery codes.
+func TestTOTPEnrollments(t *testing.T) {
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := TOTPEnrollments.GetByUserID(ctx, user.ID); err != ErrTOTPEnrollmentNotFound {
+		t.Fatalf("got err %v, want %v", err, ErrTOTPEnrollmentNotFound)
+	}
+
+	// Creating a pending enrollment again replaces it.
+	if _, _, err := TOTPEnrollments.CreatePending(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	secret, recoveryCodes, err := TOTPEnrollments.CreatePending(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recoveryCodes) != numRecoveryCodes {
+		t.Errorf("got %d recovery codes, want %d", len(recoveryCodes), numRecoveryCodes)
+	}
+	e, err := TOTPEnrollments.GetByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Secret != secret || e.Enabled() || e.RecoveryCodesRemaining != numRecoveryCodes {
+		t.Errorf("got %+v, want pending enrollment with secret %q", e, secret)
+	}
+
+	// Recovery codes can't be used for pending enrollments.
+	if ok, err := TOTPEnrollments.UseRecoveryCode(ctx, user.ID, recoveryCodes[0]); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Error("recovery code accepted for pending enrollment")
+	}
+
+	if err := TOTPEnrollments.Enable(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := TOTPEnrollments.Enable(ctx, user.ID); err != ErrTOTPEnrollmentNotFound {
+		t.Errorf("enable twice: got err %v, want %v", err, ErrTOTPEnrollmentNotFound)
+	}
+	if _, _, err := TOTPEnrollments.CreatePending(ctx, user.ID); err != ErrTOTPEnrollmentAlreadyEnabled {
+		t.Errorf("got err %v, want %v", err, ErrTOTPEnrollmentAlreadyEnabled)
+	}
+
+	// Codes can't be reused.
+	for _, test := range []struct {
+		counter int64
+		want    bool
+	}{{10, true}, {10, false}, {9, false}, {11, true}} {
+		if ok, err := TOTPEnrollments.UseCounter(ctx, user.ID, test.counter); err != nil {
+			t.Fatal(err)
+		} else if ok != test.want {
+			t.Errorf("counter %d: got %v, want %v", test.counter, ok, test.want)
+		}
+	}
+
+	// Recovery codes can be used once, and are normalized.
+	for _, test := range []struct {
+		code string
+		want bool
+	}{
+		{strings.ToUpper(strings.Replace(recoveryCodes[0], "-", " ", -1)), true},
+		{recoveryCodes[0], false},
+		{"not-a-recovery-code", false},
+		{recoveryCodes[1], true},
+	} {
+		if ok, err := TOTPEnrollments.UseRecoveryCode(ctx, user.ID, test.code); err != nil {
+			t.Fatal(err)
+		} else if ok != test.want {
+			t.Errorf("recovery code %q: got %v, want %v", test.code, ok, test.want)
+		}
+	}
+	e, err = TOTPEnrollments.GetByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := numRecoveryCodes - 2; e.RecoveryCodesRemaining != want {
+		t.Errorf("got %d recovery codes remaining, want %d", e.RecoveryCodesRemaining, want)
+	}
+
+	if err := TOTPEnrollments.Delete(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := TOTPEnrollments.Delete(ctx, user.ID); err != ErrTOTPEnrollmentNotFound {
+		t.Errorf("delete twice: got err %v, want %v", err, ErrTOTPEnrollmentNotFound)
+	}
+}
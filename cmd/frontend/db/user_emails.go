@@ -83,12 +83,20 @@ func (*userEmails) Get(ctx context.Context, userID int32, email string) (emailCa
 
 // Add adds new user email. When added, it is always unverified.
 func (*userEmails) Add(ctx context.Context, userID int32, email string, verificationCode *string) error {
+	if Mocks.UserEmails.Add != nil {
+		return Mocks.UserEmails.Add(userID, email, verificationCode)
+	}
+
 	_, err := dbconn.Global.ExecContext(ctx, "INSERT INTO user_emails(user_id, email, verification_code) VALUES($1, $2, $3)", userID, email, verificationCode)
 	return err
 }
 
 // Remove removes a user email. It returns an error if there is no such email associated with the user.
 func (*userEmails) Remove(ctx context.Context, userID int32, email string) error {
+	if Mocks.UserEmails.Remove != nil {
+		return Mocks.UserEmails.Remove(userID, email)
+	}
+
 	res, err := dbconn.Global.ExecContext(ctx, "DELETE FROM user_emails WHERE user_id=$1 AND email=$2", userID, email)
 	if err != nil {
 		return err
@@ -127,6 +135,10 @@ func (*userEmails) Verify(ctx context.Context, userID int32, email, code string)
 // SetVerified bypasses the normal email verification code process and manually sets the verified
 // status for an email.
 func (*userEmails) SetVerified(ctx context.Context, userID int32, email string, verified bool) error {
+	if Mocks.UserEmails.SetVerified != nil {
+		return Mocks.UserEmails.SetVerified(userID, email, verified)
+	}
+
 	var res sql.Result
 	var err error
 	if verified {
@@ -6,4 +6,7 @@ type MockUserEmails struct {
 	GetPrimaryEmail func(ctx context.Context, id int32) (email string, verified bool, err error)
 	Get             func(userID int32, email string) (emailCanonicalCase string, verified bool, err error)
 	ListByUser      func(id int32) ([]*UserEmail, error)
+	Add             func(userID int32, email string, verificationCode *string) error
+	Remove          func(userID int32, email string) error
+	SetVerified     func(userID int32, email string, verified bool) error
 }
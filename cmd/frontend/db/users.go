@@ -24,7 +24,9 @@ import (
 // For a detailed overview of the schema, see schema.txt.
 type users struct {
 	// PreCreateUser (if set) is a hook called before creating a new user in the DB by any means
-	// (e.g., both directly via Users.Create or via ExternalAccounts.CreateUserAndSave).
+	// (e.g., both directly via Users.Create or via ExternalAccounts.CreateUserAndSave). It is also
+	// called before unsuspending a user, because suspended users don't count toward the licensed
+	// user count.
 	PreCreateUser func(context.Context) error
 }
 
@@ -120,6 +122,10 @@ type NewUser struct {
 // order to avoid a race condition where multiple initial site admins could be created or zero site
 // admins could be created.
 func (u *users) Create(ctx context.Context, info NewUser) (newUser *types.User, err error) {
+	if Mocks.Users.Create != nil {
+		return Mocks.Users.Create(ctx, info)
+	}
+
 	tx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
@@ -510,6 +516,78 @@ func (u *users) SetIsSiteAdmin(ctx context.Context, id int32, isSiteAdmin bool)
 	return err
 }
 
+// SetSuspended suspends or unsuspends the user. Suspending a user also revokes the user's access
+// tokens and invalidates the user's sessions. The user's data is retained. Unsuspending a suspended
+// user runs the PreCreateUser hook first, so that it can't be used to exceed the licensed user
+// count.
+func (u *users) SetSuspended(ctx context.Context, id int32, suspended bool) (err error) {
+	if Mocks.Users.SetSuspended != nil {
+		return Mocks.Users.SetSuspended(id, suspended)
+	}
+
+	if !suspended {
+		var isSuspended bool
+		if err := dbconn.Global.QueryRowContext(ctx, "SELECT suspended_at IS NOT NULL FROM users WHERE id=$1 AND deleted_at IS NULL", id).Scan(&isSuspended); err != nil {
+			if err == sql.ErrNoRows {
+				return userNotFoundErr{args: []interface{}{id}}
+			}
+			return err
+		}
+		if !isSuspended {
+			return nil
+		}
+
+		if u.PreCreateUser != nil {
+			if err := u.PreCreateUser(ctx); err != nil {
+				return err
+			}
+		}
+
+		res, err := dbconn.Global.ExecContext(ctx, "UPDATE users SET suspended_at=NULL, updated_at=now() WHERE id=$1 AND deleted_at IS NULL", id)
+		if err != nil {
+			return err
+		}
+		return checkUserUpdated(res, id)
+	}
+
+	// Wrap in transaction because we update multiple tables.
+	tx, err := dbconn.Global.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			rollErr := tx.Rollback()
+			if rollErr != nil {
+				err = multierror.Append(err, rollErr)
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(ctx, "UPDATE users SET suspended_at=COALESCE(suspended_at, now()), invalidated_sessions_at=now(), updated_at=now() WHERE id=$1 AND deleted_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+	if err := checkUserUpdated(res, id); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE access_tokens SET deleted_at=now() WHERE subject_user_id=$1 AND deleted_at IS NULL", id)
+	return err
+}
+
+func checkUserUpdated(res sql.Result, id int32) error {
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return userNotFoundErr{args: []interface{}{id}}
+	}
+	return nil
+}
+
 // CheckAndDecrementInviteQuota should be called before the user (identified
 // by userID) is allowed to invite any other user. If ok is false, then the
 // user is not allowed to invite any other user (either because they've
@@ -599,6 +677,9 @@ type UsersListOptions struct {
 
 	Tag string // only include users with this tag
 
+	// ExcludeSuspended excludes suspended users.
+	ExcludeSuspended bool
+
 	*LimitOffset
 }
 
@@ -644,6 +725,9 @@ func (*users) listSQL(opt UsersListOptions) (conds []*sqlf.Query) {
 	if opt.Tag != "" {
 		conds = append(conds, sqlf.Sprintf("%s::text = ANY(u.tags)", opt.Tag))
 	}
+	if opt.ExcludeSuspended {
+		conds = append(conds, sqlf.Sprintf("u.suspended_at IS NULL"))
+	}
 	return conds
 }
 
@@ -660,7 +744,7 @@ func (u *users) getOneBySQL(ctx context.Context, query string, args ...interface
 
 // getBySQL returns users matching the SQL query, if any exist.
 func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) ([]*types.User, error) {
-	rows, err := dbconn.Global.QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.tags FROM users u "+query, args...)
+	rows, err := dbconn.Global.QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.tags, u.suspended_at, u.invalidated_sessions_at FROM users u "+query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -670,7 +754,7 @@ func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) (
 	for rows.Next() {
 		var u types.User
 		var displayName, avatarURL sql.NullString
-		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, pq.Array(&u.Tags))
+		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, pq.Array(&u.Tags), &u.SuspendedAt, &u.InvalidatedSessionsAt)
 		if err != nil {
 			return nil, err
 		}
@@ -11,6 +11,7 @@ type MockUsers struct {
 	Create               func(ctx context.Context, info NewUser) (newUser *types.User, err error)
 	Update               func(userID int32, update UserUpdate) error
 	SetIsSiteAdmin       func(id int32, isSiteAdmin bool) error
+	SetSuspended         func(id int32, suspended bool) error
 	GetByID              func(ctx context.Context, id int32) (*types.User, error)
 	GetByUsername        func(ctx context.Context, username string) (*types.User, error)
 	GetByCurrentAuthUser func(ctx context.Context) (*types.User, error)
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -392,6 +393,50 @@ func TestUsers_GetByVerifiedEmail(t *testing.T) {
 	}
 }
 
+func TestUsers_SetSuspended(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Users.SetSuspended(ctx, user.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { Users.PreCreateUser = nil }()
+	errLicense := errors.New("license user count exceeded")
+	Users.PreCreateUser = func(context.Context) error { return errLicense }
+	if err := Users.SetSuspended(ctx, user.ID, false); err != errLicense {
+		t.Fatalf("got error %v, want %v", err, errLicense)
+	}
+	if u, err := Users.GetByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if u.SuspendedAt == nil {
+		t.Fatal("user was unsuspended despite PreCreateUser error")
+	}
+
+	Users.PreCreateUser = nil
+	if err := Users.SetSuspended(ctx, user.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if u, err := Users.GetByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if u.SuspendedAt != nil {
+		t.Fatal("user is still suspended")
+	}
+
+	// Unsuspending a user who isn't suspended doesn't run the hook.
+	Users.PreCreateUser = func(context.Context) error { return errLicense }
+	if err := Users.SetSuspended(ctx, user.ID, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestUsers_Delete(t *testing.T) {
 	for name, hard := range map[string]bool{"": false, "_Hard": true} {
 		t.Run("TestUsers_Delete"+name, func(t *testing.T) {
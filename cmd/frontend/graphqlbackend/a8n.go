@@ -24,6 +24,7 @@ type CreateCampaignArgs struct {
 		Namespace   graphql.ID
 		Name        string
 		Description string
+		Plan        *graphql.ID
 	}
 }
 
@@ -46,6 +47,27 @@ type CreateChangesetsArgs struct {
 	}
 }
 
+type PreviewCampaignPlanArgs struct {
+	Specification struct {
+		ScopeQuery       string
+		MatchTemplate    string
+		RewriteTemplate  string
+		FileExtension    *string
+		DirectoryExclude *string
+	}
+}
+
+type RunChangesetActionArgs struct {
+	Campaign graphql.ID
+	Input    struct {
+		Action      a8n.ChangesetActionType
+		Body        *string
+		Changesets  *[]graphql.ID
+		State       *a8n.ChangesetState
+		ReviewState *a8n.ChangesetReviewState
+	}
+}
+
 type A8NResolver interface {
 	CreateCampaign(ctx context.Context, args *CreateCampaignArgs) (CampaignResolver, error)
 	UpdateCampaign(ctx context.Context, args *UpdateCampaignArgs) (CampaignResolver, error)
@@ -58,6 +80,12 @@ type A8NResolver interface {
 	Changesets(ctx context.Context, args *graphqlutil.ConnectionArgs) (ChangesetsConnectionResolver, error)
 
 	AddChangesetsToCampaign(ctx context.Context, args *AddChangesetsToCampaignArgs) (CampaignResolver, error)
+
+	PreviewCampaignPlan(ctx context.Context, args PreviewCampaignPlanArgs) (CampaignPlanResolver, error)
+	CampaignPlanByID(ctx context.Context, id graphql.ID) (CampaignPlanResolver, error)
+
+	RunChangesetAction(ctx context.Context, args *RunChangesetActionArgs) (ChangesetActionResolver, error)
+	ChangesetActionByID(ctx context.Context, id graphql.ID) (ChangesetActionResolver, error)
 }
 
 var onlyInEnterprise = errors.New("campaigns and changesets are only available in enterprise")
@@ -111,6 +139,20 @@ func (r *schemaResolver) Changesets(ctx context.Context, args *graphqlutil.Conne
 	return r.a8nResolver.Changesets(ctx, args)
 }
 
+func (r *schemaResolver) PreviewCampaignPlan(ctx context.Context, args PreviewCampaignPlanArgs) (CampaignPlanResolver, error) {
+	if r.a8nResolver == nil {
+		return nil, onlyInEnterprise
+	}
+	return r.a8nResolver.PreviewCampaignPlan(ctx, args)
+}
+
+func (r *schemaResolver) RunChangesetAction(ctx context.Context, args *RunChangesetActionArgs) (ChangesetActionResolver, error) {
+	if r.a8nResolver == nil {
+		return nil, onlyInEnterprise
+	}
+	return r.a8nResolver.RunChangesetAction(ctx, args)
+}
+
 type CampaignResolver interface {
 	ID() graphql.ID
 	Name() string
@@ -121,6 +163,35 @@ type CampaignResolver interface {
 	CreatedAt() DateTime
 	UpdatedAt() DateTime
 	Changesets(ctx context.Context, args struct{ graphqlutil.ConnectionArgs }) ChangesetsConnectionResolver
+	Plan(ctx context.Context) (CampaignPlanResolver, error)
+	ChangesetCreationStatus(ctx context.Context) (BackgroundProcessStatus, error)
+	ChangesetCountsOverTime(ctx context.Context, args *ChangesetCountsArgs) ([]ChangesetCountsResolver, error)
+	ChangesetActions(ctx context.Context, args *graphqlutil.ConnectionArgs) ChangesetActionsConnectionResolver
+	ChangesetCheckCounts(ctx context.Context) (ChangesetCheckCountsResolver, error)
+}
+
+type ChangesetCheckCountsResolver interface {
+	Total() int32
+	Passed() int32
+	Failed() int32
+	Pending() int32
+	Unknown() int32
+}
+
+type ChangesetCountsArgs struct {
+	From *DateTime
+	To   *DateTime
+}
+
+type ChangesetCountsResolver interface {
+	Date() DateTime
+	Total() int32
+	Merged() int32
+	Closed() int32
+	Open() int32
+	OpenApproved() int32
+	OpenChangesRequested() int32
+	OpenPending() int32
 }
 
 type CampaignsConnectionResolver interface {
@@ -144,6 +215,71 @@ type ChangesetResolver interface {
 	State() (a8n.ChangesetState, error)
 	ExternalURL() (*externallink.Resolver, error)
 	ReviewState() (a8n.ChangesetReviewState, error)
+	CheckState() (a8n.ChangesetCheckState, error)
+	Checks() ([]ChangesetCheckResolver, error)
 	Repository(ctx context.Context) (*RepositoryResolver, error)
 	Campaigns(ctx context.Context, args *struct{ graphqlutil.ConnectionArgs }) (CampaignsConnectionResolver, error)
 }
+
+type ChangesetCheckResolver interface {
+	Name() string
+	State() a8n.ChangesetCheckState
+	Description() *string
+	ExternalURL() *string
+}
+
+type CampaignPlanResolver interface {
+	ID() graphql.ID
+	Status(ctx context.Context) (BackgroundProcessStatus, error)
+	Changesets(ctx context.Context, args *graphqlutil.ConnectionArgs) ChangesetPlansConnectionResolver
+}
+
+type ChangesetPlansConnectionResolver interface {
+	Nodes(ctx context.Context) ([]ChangesetPlanResolver, error)
+	TotalCount(ctx context.Context) (int32, error)
+	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
+}
+
+type ChangesetPlanResolver interface {
+	Repository(ctx context.Context) (*RepositoryResolver, error)
+	BaseRef() string
+	Diff() string
+}
+
+type BackgroundProcessStatus interface {
+	CompletedCount() int32
+	PendingCount() int32
+	State() a8n.BackgroundProcessState
+	Errors() []string
+}
+
+type ChangesetActionResolver interface {
+	ID() graphql.ID
+	Action() a8n.ChangesetActionType
+	Body() string
+	Campaign(ctx context.Context) (CampaignResolver, error)
+	Author(ctx context.Context) (*UserResolver, error)
+	Status(ctx context.Context) (BackgroundProcessStatus, error)
+	Results(ctx context.Context, args *graphqlutil.ConnectionArgs) ChangesetActionResultsConnectionResolver
+	CreatedAt() DateTime
+}
+
+type ChangesetActionsConnectionResolver interface {
+	Nodes(ctx context.Context) ([]ChangesetActionResolver, error)
+	TotalCount(ctx context.Context) (int32, error)
+	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
+}
+
+type ChangesetActionResultResolver interface {
+	Changeset(ctx context.Context) (ChangesetResolver, error)
+	State() a8n.BackgroundProcessState
+	Error() *string
+	StartedAt() *DateTime
+	FinishedAt() *DateTime
+}
+
+type ChangesetActionResultsConnectionResolver interface {
+	Nodes(ctx context.Context) ([]ChangesetActionResultResolver, error)
+	TotalCount(ctx context.Context) (int32, error)
+	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
+}
@@ -64,3 +64,12 @@ func (r *accessTokenResolver) CreatedAt() DateTime { return DateTime{Time: r.acc
 func (r *accessTokenResolver) LastUsedAt() *DateTime {
 	return DateTimeOrNil(r.accessToken.LastUsedAt)
 }
+
+func (r *accessTokenResolver) RepositoryPatterns() []string {
+	if r.accessToken.RepoPatterns == nil {
+		return []string{}
+	}
+	return r.accessToken.RepoPatterns
+}
+
+func (r *accessTokenResolver) ExpiresAt() *DateTime { return DateTimeOrNil(r.accessToken.ExpiresAt) }
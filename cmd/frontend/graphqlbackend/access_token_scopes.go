@@ -0,0 +1,108 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+)
+
+// CheckAccessTokenScopes returns an error if the GraphQL query uses parts of the API that the
+// scopes of the access token the request was authenticated with, if any, don't allow.
+//
+// 🚨 SECURITY: It must be called before executing the query of a request authenticated with an
+// access token. The query is validated against a copy of the schema that only contains the parts
+// of the API the token may use, so that the check doesn't depend on how fields are resolved.
+func CheckAccessTokenScopes(ctx context.Context, query string) error {
+	restrictions := authz.TokenRestrictionsFromContext(ctx)
+
+	access := restrictions.GraphQLAccess()
+	if access == authz.GraphQLAccessAll {
+		return nil
+	}
+
+	schemas, err := getRestrictedSchemas()
+	if err != nil {
+		return err
+	}
+
+	for _, err := range schemas[access].Validate(query) {
+		// Variables aren't known to Validate, and they are validated when the query is executed.
+		if err.Rule == "VariablesOfCorrectType" {
+			continue
+		}
+		return fmt.Errorf("access token scopes %q do not allow this query: %s", restrictions.Scopes, err.Message)
+	}
+	return nil
+}
+
+var (
+	restrictedSchemasOnce sync.Once
+	restrictedSchemas     map[authz.GraphQLAccess]*graphql.Schema
+	restrictedSchemasErr  error
+)
+
+// getRestrictedSchemas returns the schemas of the parts of the API that access tokens with
+// restricted GraphQL access may use. They have no resolvers and are only used for validation.
+func getRestrictedSchemas() (map[authz.GraphQLAccess]*graphql.Schema, error) {
+	restrictedSchemasOnce.Do(func() {
+		readOnly, search, err := restrictedSchemaSources(Schema)
+		if err != nil {
+			restrictedSchemasErr = err
+			return
+		}
+
+		restrictedSchemas = map[authz.GraphQLAccess]*graphql.Schema{}
+		for access, source := range map[authz.GraphQLAccess]string{
+			authz.GraphQLAccessReadOnly: readOnly,
+			authz.GraphQLAccessSearch:   search,
+		} {
+			if restrictedSchemas[access], err = graphql.ParseSchema(source, nil); err != nil {
+				restrictedSchemasErr = err
+				return
+			}
+		}
+	})
+	return restrictedSchemas, restrictedSchemasErr
+}
+
+const schemaDefinition = `schema {
+    query: Query
+    mutation: Mutation
+}`
+
+// searchFieldPattern matches the definition of the Query.search field.
+var searchFieldPattern = regexp.MustCompile(`(?s)\n    search\(\n.*?\n    \): Search\n`)
+
+// noMutationType replaces the Mutation type in the restricted schemas. graphql-go doesn't validate
+// the operations of a schema without an entry point for them, so the restricted schemas have a
+// mutation entry point whose only field doesn't exist in the full schema.
+const noMutationType = `
+type NoMutation {
+    noMutations: Boolean
+}
+`
+
+// restrictedSchemaSources returns the sources of the read-only schema, which has no mutations,
+// and of the search schema, whose only query is search, derived from the source of the full
+// schema.
+func restrictedSchemaSources(schema string) (readOnly, search string, err error) {
+	if !strings.Contains(schema, schemaDefinition) {
+		return "", "", fmt.Errorf("schema definition %q not found", schemaDefinition)
+	}
+
+	searchField := searchFieldPattern.FindString(schema)
+	if searchField == "" {
+		return "", "", fmt.Errorf("definition of the search query not found in schema")
+	}
+
+	readOnly = strings.Replace(schema, schemaDefinition, "schema {\n    query: Query\n    mutation: NoMutation\n}", 1) +
+		noMutationType
+	search = strings.Replace(schema, schemaDefinition, "schema {\n    query: SearchQuery\n    mutation: NoMutation\n}", 1) +
+		noMutationType + "\ntype SearchQuery {" + searchField + "}\n"
+	return readOnly, search, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"sort"
 	"sync"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
@@ -17,9 +19,11 @@ import (
 )
 
 type createAccessTokenInput struct {
-	User   graphql.ID
-	Scopes []string
-	Note   string
+	User               graphql.ID
+	Scopes             []string
+	Note               string
+	RepositoryPatterns *[]string
+	ExpiresAt          *DateTime
 }
 
 func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAccessTokenInput) (*createAccessTokenResult, error) {
@@ -46,18 +50,25 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 	}
 
 	// Validate scopes.
-	var hasUserAllScope bool
+	var userScopes int
 	seenScope := map[string]struct{}{}
 	sort.Strings(args.Scopes)
 	for _, scope := range args.Scopes {
 		switch scope {
-		case authz.ScopeUserAll:
-			hasUserAllScope = true
+		case authz.ScopeUserAll, authz.ScopeUserRead, authz.ScopeSearch:
+			userScopes++
 		case authz.ScopeSiteAdminSudo:
 			// 🚨 SECURITY: Only site admins may create a token with the "site-admin:sudo" scope.
 			if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
 				return nil, err
 			}
+		case authz.ScopeSCIM:
+			// 🚨 SECURITY: Only site admins may create a token with the "scim" scope. The SCIM API
+			// also checks that the token's subject user is a site admin on each request.
+			if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+				return nil, err
+			}
+			userScopes++
 		default:
 			return nil, fmt.Errorf("unknown access token scope %q (valid scopes: %q)", scope, authz.AllScopes)
 		}
@@ -67,12 +78,45 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 		}
 		seenScope[scope] = struct{}{}
 	}
-	if !hasUserAllScope {
-		return nil, fmt.Errorf("all access tokens must have scope %q", authz.ScopeUserAll)
+	if userScopes != 1 {
+		return nil, fmt.Errorf("all access tokens must have exactly one of the scopes %q", authz.UserScopes)
+	}
+
+	var restrictions db.AccessTokenRestrictions
+	if args.RepositoryPatterns != nil {
+		for _, pattern := range *args.RepositoryPatterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, &badRequestError{err}
+			}
+		}
+		restrictions.RepoPatterns = *args.RepositoryPatterns
+	}
+	if args.ExpiresAt != nil {
+		if !args.ExpiresAt.After(time.Now()) {
+			return nil, errors.New("access token expiry must be in the future")
+		}
+		restrictions.ExpiresAt = &args.ExpiresAt.Time
 	}
 
-	id, token, err := db.AccessTokens.Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID)
-	return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, err
+	var (
+		id    int64
+		token string
+	)
+	if len(restrictions.RepoPatterns) == 0 && restrictions.ExpiresAt == nil {
+		id, token, err = db.AccessTokens.Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID)
+	} else {
+		id, token, err = db.AccessTokens.CreateRestricted(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID, restrictions)
+	}
+	if err != nil {
+		return nil, err
+	}
+	backend.LogAuditEvent(ctx, backend.AuditAccessTokenCreate, fmt.Sprintf("access_token:%d", id), map[string]interface{}{
+		"subjectUserID":      userID,
+		"scopes":             args.Scopes,
+		"repositoryPatterns": restrictions.RepoPatterns,
+		"expiresAt":          restrictions.ExpiresAt,
+	})
+	return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, nil
 }
 
 type createAccessTokenResult struct {
@@ -115,6 +159,7 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 		if err := db.AccessTokens.DeleteByID(ctx, token.ID, token.SubjectUserID); err != nil {
 			return nil, err
 		}
+		backend.LogAuditEvent(ctx, backend.AuditAccessTokenDelete, fmt.Sprintf("access_token:%d", token.ID), map[string]int32{"subjectUserID": token.SubjectUserID})
 
 	case args.ByToken != nil:
 		// 🚨 SECURITY: This is easier than the ByID case because anyone holding the access token's
@@ -122,6 +167,7 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 		if err := db.AccessTokens.DeleteByToken(ctx, *args.ByToken); err != nil {
 			return nil, err
 		}
+		backend.LogAuditEvent(ctx, backend.AuditAccessTokenDelete, "", map[string]bool{"byToken": true})
 	}
 
 	return &EmptyResponse{}, nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/gqltesting"
@@ -17,6 +18,12 @@ import (
 // 🚨 SECURITY: This tests that users can't create tokens for users they aren't allowed to do so for.
 func TestMutation_CreateAccessToken(t *testing.T) {
 	mockAccessTokensCreate := func(t *testing.T, wantCreatorUserID int32, wantScopes []string) {
+		db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+			if want := (db.AuditLogEntry{ActorUserID: wantCreatorUserID, Action: backend.AuditAccessTokenCreate, Subject: "access_token:1"}); e.ActorUserID != want.ActorUserID || e.Action != want.Action || e.Subject != want.Subject {
+				t.Errorf("got audit log entry %+v, want %+v", e, want)
+			}
+			return nil
+		}
 		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (int64, string, error) {
 			if want := int32(1); subjectUserID != want {
 				t.Errorf("got %v, want %v", subjectUserID, want)
@@ -97,6 +104,27 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 		}
 	})
 
+	t.Run("authenticated as user, using scim scope", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: false}, nil
+		}
+		defer func() { db.Mocks.Users.GetByCurrentAuthUser = nil }()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		result, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:   uid1GQLID,
+			Scopes: []string{authz.ScopeSCIM},
+			Note:   "n",
+		})
+		if want := backend.ErrMustBeSiteAdmin; err != want {
+			t.Errorf("got err %v, want %v", err, want)
+		}
+		if result != nil {
+			t.Errorf("got result %v, want nil", result)
+		}
+	})
+
 	t.Run("authenticated as site admin, using site-admin-only scopes", func(t *testing.T) {
 		resetMocks()
 		mockAccessTokensCreate(t, 1, []string{authz.ScopeSiteAdminSudo, authz.ScopeUserAll})
@@ -205,6 +233,12 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 // 🚨 SECURITY: This tests that users can't delete tokens they shouldn't be allowed to delete.
 func TestMutation_DeleteAccessToken(t *testing.T) {
 	mockAccessTokens := func(t *testing.T) {
+		db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+			if want := "access_token:1"; e.Action != backend.AuditAccessTokenDelete || e.Subject != want {
+				t.Errorf("got audit log entry %+v, want action %q on %q", e, backend.AuditAccessTokenDelete, want)
+			}
+			return nil
+		}
 		db.Mocks.AccessTokens.DeleteByID = func(id int64, subjectUserID int32) error {
 			if want := int64(1); id != want {
 				t.Errorf("got %q, want %q", id, want)
@@ -321,3 +355,114 @@ func TestMutation_DeleteAccessToken(t *testing.T) {
 		}
 	})
 }
+
+func TestMutation_CreateAccessToken_restricted(t *testing.T) {
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: 1}, nil
+	}
+	db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error { return nil }
+	expiresAt := time.Now().Add(time.Hour)
+	var called bool
+	db.Mocks.AccessTokens.CreateRestricted = func(subjectUserID int32, scopes []string, note string, creatorUserID int32, restrictions db.AccessTokenRestrictions) (int64, string, error) {
+		called = true
+		if want := []string{authz.ScopeSearch}; !reflect.DeepEqual(scopes, want) {
+			t.Errorf("got scopes %q, want %q", scopes, want)
+		}
+		if want := []string{"^github.com/a/"}; !reflect.DeepEqual(restrictions.RepoPatterns, want) {
+			t.Errorf("got repo patterns %q, want %q", restrictions.RepoPatterns, want)
+		}
+		if restrictions.ExpiresAt == nil || !restrictions.ExpiresAt.Equal(expiresAt) {
+			t.Errorf("got expiry %v, want %v", restrictions.ExpiresAt, expiresAt)
+		}
+		return 1, "t", nil
+	}
+	defer resetMocks()
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	create := func(scopes []string, patterns []string, expiresAt time.Time) error {
+		_, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:               "VXNlcjox",
+			Scopes:             scopes,
+			Note:               "n",
+			RepositoryPatterns: &patterns,
+			ExpiresAt:          &DateTime{Time: expiresAt},
+		})
+		return err
+	}
+
+	if err := create([]string{authz.ScopeSearch}, []string{"^github.com/a/"}, expiresAt); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("!called")
+	}
+
+	if err := create([]string{authz.ScopeUserAll, authz.ScopeUserRead}, nil, expiresAt); err == nil {
+		t.Error("want error for multiple user scopes")
+	}
+	if err := create([]string{authz.ScopeUserRead}, []string{"("}, expiresAt); err == nil {
+		t.Error("want error for invalid repository pattern")
+	}
+	if err := create([]string{authz.ScopeUserRead}, nil, time.Now().Add(-time.Hour)); err == nil {
+		t.Error("want error for expiry in the past")
+	}
+}
+
+// 🚨 SECURITY: This tests that the scopes of the access token a request was authenticated with
+// are enforced on GraphQL queries.
+func TestCheckAccessTokenScopes(t *testing.T) {
+	check := func(scope, query string) error {
+		ctx := context.Background()
+		if scope != "" {
+			ctx = authz.WithTokenRestrictions(ctx, &authz.TokenRestrictions{Scopes: []string{scope}})
+		}
+		return CheckAccessTokenScopes(ctx, query)
+	}
+
+	const (
+		mutation       = `mutation { logUserEvent(event: SEARCHQUERY, userCookieID: "u") { alwaysNil } }`
+		query          = `{ currentUser { id } }`
+		fragmentQuery  = `query { ...F } fragment F on Query { currentUser { id } }`
+		searchQuery    = `query($query: String!) { search(query: $query) { results { matchCount } } }`
+		typenameQuery  = `{ __typename }`
+		unknownQuery   = `{ doesNotExist }`
+		invalidSyntax  = `{`
+		mutationSearch = `mutation { search(query: "a") { results { matchCount } } }`
+	)
+	tests := []struct {
+		scope   string
+		query   string
+		wantErr bool
+	}{
+		{"", mutation, false},
+		{authz.ScopeUserAll, mutation, false},
+		{authz.ScopeSiteAdminSudo, mutation, false},
+		{authz.ScopeUserRead, mutation, true},
+		{authz.ScopeUserRead, query, false},
+		{authz.ScopeUserRead, searchQuery, false},
+		{authz.ScopeSearch, mutation, true},
+		{authz.ScopeSearch, mutationSearch, true},
+		{authz.ScopeSearch, query, true},
+		{authz.ScopeSearch, fragmentQuery, true},
+		{authz.ScopeSearch, searchQuery, false},
+		{authz.ScopeSearch, typenameQuery, false},
+		{authz.ScopeSearch, unknownQuery, true},
+		{authz.ScopeSearch, invalidSyntax, true},
+	}
+	for _, test := range tests {
+		err := check(test.scope, test.query)
+		if (err != nil) != test.wantErr {
+			t.Errorf("scope %q, query %q: got error %v, want error %v", test.scope, test.query, err, test.wantErr)
+		}
+	}
+}
+
+func TestRestrictedSchemaSources(t *testing.T) {
+	if _, _, err := restrictedSchemaSources(Schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := restrictedSchemaSources("schema { query: Query }"); err == nil {
+		t.Error("want error for a schema without mutations")
+	}
+}
@@ -0,0 +1,154 @@
+package graphqlbackend
+
+import (
+	"context"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+type auditLogArgs struct {
+	graphqlutil.ConnectionArgs
+	Before  *graphql.ID
+	Actor   *graphql.ID
+	Actions *[]string
+	Subject *string
+	Since   *DateTime
+	Until   *DateTime
+}
+
+func (r *siteResolver) AuditLog(ctx context.Context, args *auditLogArgs) (*auditLogEntryConnectionResolver, error) {
+	// 🚨 SECURITY: Only site admins can view the audit log.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var opt db.AuditLogListOptions
+	args.ConnectionArgs.Set(&opt.LimitOffset)
+	if args.Before != nil {
+		var err error
+		opt.BeforeID, err = unmarshalAuditLogEntryID(*args.Before)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if args.Actor != nil {
+		var err error
+		opt.ActorUserID, err = UnmarshalUserID(*args.Actor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if args.Actions != nil {
+		opt.Actions = *args.Actions
+	}
+	if args.Subject != nil {
+		opt.Subject = *args.Subject
+	}
+	if args.Since != nil {
+		opt.Since = &args.Since.Time
+	}
+	if args.Until != nil {
+		opt.Until = &args.Until.Time
+	}
+	return &auditLogEntryConnectionResolver{opt: opt}, nil
+}
+
+// auditLogEntryConnectionResolver resolves a list of audit log entries.
+//
+// 🚨 SECURITY: When instantiating an auditLogEntryConnectionResolver value, the caller MUST check
+// permissions.
+type auditLogEntryConnectionResolver struct {
+	opt db.AuditLogListOptions
+
+	// cache results because they are used by multiple fields
+	once    sync.Once
+	entries []*db.AuditLogEntry
+	err     error
+}
+
+func (r *auditLogEntryConnectionResolver) compute(ctx context.Context) ([]*db.AuditLogEntry, error) {
+	r.once.Do(func() {
+		opt2 := r.opt
+		if opt2.LimitOffset != nil {
+			tmp := *opt2.LimitOffset
+			opt2.LimitOffset = &tmp
+			opt2.Limit++ // so we can detect if there is a next page
+		}
+
+		r.entries, r.err = db.AuditLog.List(ctx, opt2)
+	})
+	return r.entries, r.err
+}
+
+func (r *auditLogEntryConnectionResolver) Nodes(ctx context.Context) ([]*auditLogEntryResolver, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.LimitOffset != nil && len(entries) > r.opt.LimitOffset.Limit {
+		entries = entries[:r.opt.LimitOffset.Limit]
+	}
+
+	l := make([]*auditLogEntryResolver, len(entries))
+	for i, e := range entries {
+		l[i] = &auditLogEntryResolver{entry: e}
+	}
+	return l, nil
+}
+
+func (r *auditLogEntryConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := db.AuditLog.Count(ctx, r.opt)
+	return int32(count), err
+}
+
+func (r *auditLogEntryConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(r.opt.LimitOffset != nil && len(entries) > r.opt.Limit), nil
+}
+
+type auditLogEntryResolver struct {
+	entry *db.AuditLogEntry
+}
+
+func marshalAuditLogEntryID(id int64) graphql.ID { return relay.MarshalID("AuditLogEntry", id) }
+
+func unmarshalAuditLogEntryID(id graphql.ID) (entryID int64, err error) {
+	err = relay.UnmarshalSpec(id, &entryID)
+	return
+}
+
+func (r *auditLogEntryResolver) ID() graphql.ID { return marshalAuditLogEntryID(r.entry.ID) }
+
+func (r *auditLogEntryResolver) Actor(ctx context.Context) (*UserResolver, error) {
+	if r.entry.ActorUserID == 0 {
+		return nil, nil
+	}
+	user, err := UserByIDInt32(ctx, r.entry.ActorUserID)
+	if errcode.IsNotFound(err) {
+		// The user was deleted after performing the action.
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *auditLogEntryResolver) ActorID() *graphql.ID {
+	if r.entry.ActorUserID == 0 {
+		return nil
+	}
+	id := marshalUserID(r.entry.ActorUserID)
+	return &id
+}
+
+func (r *auditLogEntryResolver) Action() string      { return r.entry.Action }
+func (r *auditLogEntryResolver) Subject() string     { return r.entry.Subject }
+func (r *auditLogEntryResolver) Data() string        { return string(r.entry.Data) }
+func (r *auditLogEntryResolver) CreatedAt() DateTime { return DateTime{Time: r.entry.CreatedAt} }
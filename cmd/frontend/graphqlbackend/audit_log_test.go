@@ -0,0 +1,133 @@
+package graphqlbackend
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+)
+
+func TestSiteAuditLog(t *testing.T) {
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "alice"}, nil
+	}
+	createdAt := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+	wantOpt := db.AuditLogListOptions{
+		ActorUserID: 1,
+		Actions:     []string{backend.AuditAccessTokenCreate},
+		BeforeID:    10,
+		Since:       &createdAt,
+	}
+	db.Mocks.AuditLog.List = func(opt db.AuditLogListOptions) ([]*db.AuditLogEntry, error) {
+		if want := (db.LimitOffset{Limit: 2}); opt.LimitOffset == nil || *opt.LimitOffset != want {
+			t.Errorf("got limit %+v, want %+v", opt.LimitOffset, want)
+		}
+		opt.LimitOffset = nil
+		if !reflect.DeepEqual(opt, wantOpt) {
+			t.Errorf("got options %+v, want %+v", opt, wantOpt)
+		}
+		return []*db.AuditLogEntry{
+			{ID: 2, ActorUserID: 1, Action: backend.AuditAccessTokenCreate, Subject: "access_token:3", Data: json.RawMessage(`{"scopes":["user:all"]}`), CreatedAt: createdAt},
+			{ID: 1, ActorUserID: 1, Action: backend.AuditAccessTokenCreate, Subject: "access_token:2", Data: json.RawMessage(`{}`), CreatedAt: createdAt},
+		}, nil
+	}
+	db.Mocks.AuditLog.Count = func(opt db.AuditLogListOptions) (int, error) {
+		return 2, nil
+	}
+	defer resetMocks()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+			Schema:  mustParseGraphQLSchema(t, nil),
+			Query: `
+				{
+					site {
+						auditLog(first: 1, before: "QXVkaXRMb2dFbnRyeToxMA==", actor: "VXNlcjox", actions: ["access_token.create"], since: "2019-10-01T00:00:00Z") {
+							nodes {
+								id
+								actor {
+									username
+								}
+								actorID
+								action
+								subject
+								data
+								createdAt
+							}
+							totalCount
+							pageInfo {
+								hasNextPage
+							}
+						}
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"site": {
+						"auditLog": {
+							"nodes": [
+								{
+									"id": "QXVkaXRMb2dFbnRyeToy",
+									"actor": {
+										"username": "alice"
+									},
+									"actorID": "VXNlcjox",
+									"action": "access_token.create",
+									"subject": "access_token:3",
+									"data": "{\"scopes\":[\"user:all\"]}",
+									"createdAt": "2019-10-01T00:00:00Z"
+								}
+							],
+							"totalCount": 2,
+							"pageInfo": {
+								"hasNextPage": true
+							}
+						}
+					}
+				}
+			`,
+		},
+	})
+}
+
+func TestSiteAuditLog_nonSiteAdmin(t *testing.T) {
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 2}, nil
+	}
+	defer resetMocks()
+
+	_, err := (&siteResolver{}).AuditLog(actor.WithActor(context.Background(), &actor.Actor{UID: 2}), &auditLogArgs{})
+	if err != backend.ErrMustBeSiteAdmin {
+		t.Errorf("got error %v, want %v", err, backend.ErrMustBeSiteAdmin)
+	}
+}
+
+func TestChangedProperties(t *testing.T) {
+	before := `{
+		// comment
+		"a": 1,
+		"b": {"c": true},
+		"d": "x",
+	}`
+	after := `{"a": 1, "b": {"c": false}, "e": []}`
+	if got, want := changedProperties(before, after), []string{"b", "d", "e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := changedProperties(before, "{"); got != nil {
+		t.Errorf("got %q for invalid JSON, want nil", got)
+	}
+}
@@ -17,9 +17,12 @@ import (
 	otlog "github.com/opentracing/opentracing-go/log"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/go-diff/diff"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
 	"github.com/sourcegraph/sourcegraph/pkg/errcode"
 	"github.com/sourcegraph/sourcegraph/pkg/trace"
@@ -38,6 +41,9 @@ type args struct {
 	rewriteTemplate   string
 	includeFileFilter string
 	excludeFileFilter string
+	engine            string
+	image             string
+	script            string
 }
 
 // codemodResultResolver is a resolver for the GraphQL type `CodemodResult`
@@ -140,7 +146,42 @@ func validateQuery(q *query.Query) (*args, error) {
 		}
 	}
 
-	return &args{matchTemplate, rewriteTemplate, includeFileFilterText, excludeFileFilterText}, nil
+	engine, _ := q.StringValue(query.FieldEngine)
+	switch engine {
+	case "", protocol.EngineComby, protocol.EngineRegexp, protocol.EngineScript:
+	default:
+		return nil, fmt.Errorf("unknown rewrite engine %q in the 'engine:' filter. Valid engines are %q, %q and %q", engine, protocol.EngineComby, protocol.EngineRegexp, protocol.EngineScript)
+	}
+	image, _ := q.StringValue(query.FieldImage)
+	script, _ := q.StringValue(query.FieldScript)
+	if (image != "" || script != "") && engine != protocol.EngineScript {
+		return nil, errors.New("the 'image:' and 'script:' filters require 'engine:script'")
+	}
+
+	return &args{
+		matchTemplate:     matchTemplate,
+		rewriteTemplate:   rewriteTemplate,
+		includeFileFilter: includeFileFilterText,
+		excludeFileFilter: excludeFileFilterText,
+		engine:            engine,
+		image:             image,
+		script:            script,
+	}, nil
+}
+
+// checkEngineAllowed returns an error if the current user may not use the
+// rewrite engine of the given args.
+//
+// 🚨 SECURITY: The script engine runs arbitrary code in container images on
+// the replacer, so only site admins may use it.
+func checkEngineAllowed(ctx context.Context, cmodArgs *args) error {
+	if cmodArgs.engine != protocol.EngineScript {
+		return nil
+	}
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return errors.Wrap(err, "the 'engine:script' filter is only available to site admins")
+	}
+	return nil
 }
 
 // Calls the codemod backend replacer service for a set of repository revisions.
@@ -149,6 +190,9 @@ func performCodemod(ctx context.Context, args *search.Args) ([]searchResultResol
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := checkEngineAllowed(ctx, cmodArgs); err != nil {
+		return nil, nil, err
+	}
 
 	title := fmt.Sprintf("pattern: %+v, replace: %+v, includeFileFilter: %+v, excludeFileFilter: %+v, numRepoRevs: %d", cmodArgs.matchTemplate, cmodArgs.rewriteTemplate, cmodArgs.includeFileFilter, cmodArgs.excludeFileFilter, len(args.Repos))
 	tr, ctx := trace.New(ctx, "callCodemod", title)
@@ -240,17 +284,102 @@ func callCodemodInRepo(ctx context.Context, repoRevs *search.RepositoryRevisions
 		return nil, errors.Wrap(err, "codemod repo lookup failed: it's possible that the repo is not cloned in gitserver. Try force a repo update another way.")
 	}
 
+	raws, err := callReplacer(ctx, repoRevs.Repo.Name, commit, protocol.RewriteSpecification{
+		MatchTemplate:    args.matchTemplate,
+		RewriteTemplate:  args.rewriteTemplate,
+		FileExtension:    args.includeFileFilter,
+		DirectoryExclude: args.excludeFileFilter,
+		Engine:           args.engine,
+		Image:            args.image,
+		Script:           args.script,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range raws {
+		fileURL := fileMatchURI(repoRevs.Repo.Name, repoRevs.Revs[0].RevSpec, raw.URI)
+		matches, err := toMatchResolver(fileURL, raw)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, codemodResultResolver{
+			commit: &GitCommitResolver{
+				repo:     &RepositoryResolver{repo: repoRevs.Repo},
+				inputRev: &repoRevs.Revs[0].RevSpec,
+				oid:      GitObjectID(commit),
+			},
+			path:    raw.URI,
+			fileURL: fileURL,
+			diff:    raw.Diff,
+			matches: matches,
+		})
+	}
+
+	return results, nil
+}
+
+// CodemodDiff runs the given rewrite over a commit of a repository and
+// returns the changes to all files as a single unified diff that can be
+// applied with `git apply`. An empty diff means nothing matched.
+func CodemodDiff(ctx context.Context, repo api.RepoName, commit api.CommitID, spec protocol.RewriteSpecification) (_ string, err error) {
+	tr, ctx := trace.New(ctx, "CodemodDiff", fmt.Sprintf("repo: %s, commit: %s, pattern %+v, replace: %+v", repo, commit, spec.MatchTemplate, spec.RewriteTemplate))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	raws, err := callReplacer(ctx, repo, commit, spec)
+	if err != nil {
+		return "", err
+	}
+
+	return unifiedDiff(raws)
+}
+
+// unifiedDiff combines the per-file diffs returned by the replacer into a
+// single unified diff with "a/" and "b/" prefixed file names, as expected
+// by `git apply`.
+func unifiedDiff(raws []*rawCodemodResult) (string, error) {
+	var b strings.Builder
+	for _, raw := range raws {
+		i := strings.Index(raw.Diff, "@@")
+		if i < 0 {
+			return "", errors.Errorf("Invalid diff does not contain expected @@: %v", raw.Diff)
+		}
+
+		fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", raw.URI, raw.URI)
+		b.WriteString(raw.Diff[i:])
+		if !strings.HasSuffix(raw.Diff, "\n") {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String(), nil
+}
+
+// callReplacer calls the replacer service to run the given rewrite over a
+// commit of a repository, returning the diff of each changed file.
+func callReplacer(ctx context.Context, repo api.RepoName, commit api.CommitID, spec protocol.RewriteSpecification) (results []*rawCodemodResult, err error) {
 	u, err := url.Parse(replacerURL)
 	if err != nil {
 		return nil, err
 	}
 	q := u.Query()
-	q.Set("repo", string(repoRevs.Repo.Name))
+	q.Set("repo", string(repo))
 	q.Set("commit", string(commit))
-	q.Set("matchtemplate", args.matchTemplate)
-	q.Set("rewritetemplate", args.rewriteTemplate)
-	q.Set("fileextension", args.includeFileFilter)
-	q.Set("directoryexclude", args.excludeFileFilter)
+	if spec.Engine != "" {
+		q.Set("engine", spec.Engine)
+	}
+	q.Set("matchtemplate", spec.MatchTemplate)
+	q.Set("rewritetemplate", spec.RewriteTemplate)
+	q.Set("fileextension", spec.FileExtension)
+	q.Set("directoryexclude", spec.DirectoryExclude)
+	if spec.Image != "" {
+		q.Set("image", spec.Image)
+	}
+	if spec.Script != "" {
+		q.Set("script", spec.Script)
+	}
 	u.RawQuery = q.Encode()
 
 	req, err := http.NewRequest("GET", u.String(), nil)
@@ -305,22 +434,7 @@ func callCodemodInRepo(ctx context.Context, repoRevs *search.RepositoryRevisions
 			// responses if dependencies are not installed)
 			continue
 		}
-		fileURL := fileMatchURI(repoRevs.Repo.Name, repoRevs.Revs[0].RevSpec, raw.URI)
-		matches, err := toMatchResolver(fileURL, raw)
-		if err != nil {
-			return nil, err
-		}
-		results = append(results, codemodResultResolver{
-			commit: &GitCommitResolver{
-				repo:     &RepositoryResolver{repo: repoRevs.Repo},
-				inputRev: &repoRevs.Revs[0].RevSpec,
-				oid:      GitObjectID(commit),
-			},
-			path:    raw.URI,
-			fileURL: fileURL,
-			diff:    raw.Diff,
-			matches: matches,
-		})
+		results = append(results, raw)
 	}
 
 	return results, nil
@@ -0,0 +1,330 @@
+package graphqlbackend
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	gitprotocol "github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/trace"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// codemodPatchesConcurrency is the maximum number of repositories
+// CodemodPatches runs the codemod over concurrently.
+const codemodPatchesConcurrency = 8
+
+// A CodemodPatch is the change a codemod makes to a revision of a
+// repository, as a unified diff that can be applied with `git apply`.
+type CodemodPatch struct {
+	Repo *types.Repo
+	// Rev is the revision specifier the codemod was run against, e.g. a
+	// branch name. It's empty for the default branch.
+	Rev string
+	// Commit is the commit Rev resolved to, which the patch applies to.
+	Commit api.CommitID
+	Patch  string
+}
+
+// CodemodPatches runs the codemod of the given search query, which must have
+// a replace: filter, over every repository revision the query matches. It
+// returns a patch for each revision the codemod changes, ordered by
+// repository name.
+func CodemodPatches(ctx context.Context, q string) (patches []*CodemodPatch, err error) {
+	tr, ctx := trace.New(ctx, "CodemodPatches", q)
+	defer func() {
+		tr.LazyPrintf("%d patches", len(patches))
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	parsed, err := query.ParseAndCheck(q)
+	if err != nil {
+		return nil, &badRequestError{err}
+	}
+
+	if len(parsed.Values(query.FieldReplace)) == 0 {
+		return nil, &badRequestError{errors.New("the query has no 'replace:' filter")}
+	}
+
+	cmodArgs, err := validateQuery(parsed)
+	if err != nil {
+		return nil, &badRequestError{err}
+	}
+	if err := checkEngineAllowed(ctx, cmodArgs); err != nil {
+		return nil, err
+	}
+
+	r := &searchResolver{query: parsed}
+	repoRevs, _, overLimit, err := r.resolveRepositories(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if overLimit {
+		return nil, errors.Errorf("query %q matches more than %d repositories", q, maxReposToSearch())
+	}
+
+	spec := protocol.RewriteSpecification{
+		MatchTemplate:    cmodArgs.matchTemplate,
+		RewriteTemplate:  cmodArgs.rewriteTemplate,
+		FileExtension:    cmodArgs.includeFileFilter,
+		DirectoryExclude: cmodArgs.excludeFileFilter,
+		Engine:           cmodArgs.engine,
+		Image:            cmodArgs.image,
+		Script:           cmodArgs.script,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		sem      = make(chan struct{}, codemodPatchesConcurrency)
+		firstErr error
+	)
+	for _, repoRev := range repoRevs {
+		wg.Add(1)
+		repoRev := repoRev // shadow variable so it doesn't change while goroutine is running
+		goroutine.Go(func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			rev := repoRev.Revs[0].RevSpec
+			commit, err := git.ResolveRevision(ctx, repoRev.GitserverRepo(), nil, rev, &git.ResolveRevisionOptions{NoEnsureRevision: true})
+			if err != nil {
+				err = errors.Wrapf(err, "resolving revision %q of %s", rev, repoRev.Repo.Name)
+			}
+
+			var patch string
+			if err == nil {
+				if patch, err = CodemodDiff(ctx, repoRev.Repo.Name, commit, spec); err != nil {
+					err = errors.Wrapf(err, "running codemod in %s", repoRev.Repo.Name)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				// Report the first error rather than the cancelation of the
+				// other repositories it causes.
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+
+			if patch != "" {
+				patches = append(patches, &CodemodPatch{
+					Repo:   repoRev.Repo,
+					Rev:    rev,
+					Commit: commit,
+					Patch:  patch,
+				})
+			}
+		})
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	sort.Slice(patches, func(i, j int) bool {
+		return patches[i].Repo.Name < patches[j].Repo.Name
+	})
+
+	return patches, nil
+}
+
+// codemodRefPrefix is the namespace of the refs CreateCodemodCommits creates
+// commits on, so that it can never overwrite branches or tags.
+const codemodRefPrefix = "refs/codemod/"
+
+// CreateCodemodCommits creates a commit from each of the given patches on the
+// given ref of its repository in gitserver, so that it can be fetched from
+// there. It returns the created commits in the order of the patches. If
+// creating any of the commits fails, the refs updated so far are restored.
+func CreateCodemodCommits(ctx context.Context, patches []*CodemodPatch, ref string, info gitprotocol.PatchCommitInfo) (_ []api.CommitID, err error) {
+	if !strings.HasPrefix(ref, codemodRefPrefix) || ref == codemodRefPrefix {
+		return nil, &badRequestError{errors.Errorf("invalid ref %q, it must start with %s", ref, codemodRefPrefix)}
+	}
+
+	var updated []codemodRefUpdate
+	defer func() {
+		if err != nil {
+			rollbackCodemodRefUpdates(ref, updated)
+		}
+	}()
+
+	commits := make([]api.CommitID, 0, len(patches))
+	for _, p := range patches {
+		prev, err := resolveCodemodRef(ctx, p.Repo.Name, ref)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving %s in %s", ref, p.Repo.Name)
+		}
+
+		rev, err := gitserver.DefaultClient.CreateCommitFromPatch(ctx, gitprotocol.CreateCommitFromPatchRequest{
+			Repo:       p.Repo.Name,
+			BaseCommit: p.Commit,
+			Patch:      p.Patch,
+			TargetRef:  ref,
+			CommitInfo: info,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating commit in %s", p.Repo.Name)
+		}
+
+		commit := api.CommitID(strings.TrimSpace(rev))
+		updated = append(updated, codemodRefUpdate{repo: p.Repo.Name, prev: prev, commit: commit})
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+// codemodRefUpdate is a ref CreateCodemodCommits pointed to a new commit.
+type codemodRefUpdate struct {
+	repo   api.RepoName
+	prev   api.CommitID // empty if the ref didn't exist
+	commit api.CommitID
+}
+
+// resolveCodemodRef returns the commit the given ref points to in the
+// repository, or an empty commit ID if it doesn't exist.
+func resolveCodemodRef(ctx context.Context, repo api.RepoName, ref string) (api.CommitID, error) {
+	cmd := gitserver.DefaultClient.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	cmd.Repo = gitserver.Repo{Name: repo}
+	out, err := cmd.Output(ctx)
+	if err != nil {
+		if cmd.ExitStatus == 1 && len(out) == 0 {
+			return "", nil
+		}
+		return "", err
+	}
+	return api.CommitID(strings.TrimSpace(string(out))), nil
+}
+
+// rollbackCodemodRefUpdates points the refs updated by CreateCodemodCommits
+// back to their previous commits, or deletes them if they didn't exist
+// before. Refs that were updated again in the meantime are left alone.
+func rollbackCodemodRefUpdates(ref string, updated []codemodRefUpdate) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for _, u := range updated {
+		args := []string{"update-ref", "-d", ref, string(u.commit)}
+		if u.prev != "" {
+			args = []string{"update-ref", ref, string(u.prev), string(u.commit)}
+		}
+
+		cmd := gitserver.DefaultClient.Command("git", args...)
+		cmd.Repo = gitserver.Repo{Name: u.repo}
+		if out, err := cmd.CombinedOutput(ctx); err != nil {
+			log15.Error("Failed to roll back codemod commit.", "repo", u.repo, "ref", ref, "commit", u.commit, "error", err, "output", string(out))
+		}
+	}
+}
+
+func (r *schemaResolver) CodemodPatches(ctx context.Context, args *struct {
+	Query string
+}) ([]*codemodPatchResolver, error) {
+	patches, err := CodemodPatches(ctx, args.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*codemodPatchResolver, 0, len(patches))
+	for _, p := range patches {
+		resolvers = append(resolvers, &codemodPatchResolver{patch: p})
+	}
+	return resolvers, nil
+}
+
+func (r *schemaResolver) CreateCodemodCommits(ctx context.Context, args *struct {
+	Query   string
+	Ref     string
+	Message string
+}) ([]*codemodPatchResolver, error) {
+	// 🚨 SECURITY: Only site admins may create refs in gitserver.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := db.Users.GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info := gitprotocol.PatchCommitInfo{
+		Message:    args.Message,
+		AuthorName: user.Username,
+		Date:       time.Now(),
+	}
+
+	if email, _, err := db.UserEmails.GetPrimaryEmail(ctx, user.ID); err == nil {
+		info.AuthorEmail = email
+	}
+
+	patches, err := CodemodPatches(ctx, args.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := CreateCodemodCommits(ctx, patches, args.Ref, info)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*codemodPatchResolver, 0, len(patches))
+	for i, p := range patches {
+		resolvers = append(resolvers, &codemodPatchResolver{patch: p, commit: commits[i]})
+	}
+	return resolvers, nil
+}
+
+type codemodPatchResolver struct {
+	patch  *CodemodPatch
+	commit api.CommitID
+}
+
+func (r *codemodPatchResolver) Repository() *RepositoryResolver {
+	return &RepositoryResolver{repo: r.patch.Repo}
+}
+
+func (r *codemodPatchResolver) BaseCommit() *GitCommitResolver {
+	return r.commitResolver(r.patch.Commit)
+}
+
+func (r *codemodPatchResolver) Patch() string { return r.patch.Patch }
+
+func (r *codemodPatchResolver) Commit() *GitCommitResolver {
+	if r.commit == "" {
+		return nil
+	}
+	return r.commitResolver(r.commit)
+}
+
+func (r *codemodPatchResolver) commitResolver(commit api.CommitID) *GitCommitResolver {
+	return &GitCommitResolver{
+		repo: r.Repository(),
+		oid:  GitObjectID(commit),
+	}
+}
@@ -1,10 +1,16 @@
 package graphqlbackend
 
 import (
+	"context"
 	"strings"
 	"testing"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	gitprotocol "github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
 )
 
 func TestCodemod_validateArgsNoRegex(t *testing.T) {
@@ -26,6 +32,46 @@ func TestCodemod_validateArgsOk(t *testing.T) {
 	}
 }
 
+func TestCodemod_validateArgsEngine(t *testing.T) {
+	q, _ := query.ParseAndCheck(`engine:script image:alpine script:"sed -i s/a/b/ *.txt" replace:""`)
+	args, err := validateQuery(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.engine != "script" || args.image != "alpine" || args.script != "sed -i s/a/b/ *.txt" {
+		t.Errorf("got engine %q, image %q, script %q", args.engine, args.image, args.script)
+	}
+
+	for _, input := range []string{
+		`engine:sed "a" replace:"b"`,
+		`engine:regexp script:"true" "a" replace:"b"`,
+		`image:alpine "a" replace:"b"`,
+	} {
+		q, _ := query.ParseAndCheck(input)
+		if _, err := validateQuery(q); err == nil {
+			t.Errorf("Expected query %q to fail", input)
+		}
+	}
+}
+
+// 🚨 SECURITY: This tests that only site admins can run scripts on the replacer.
+func TestCodemod_checkEngineAllowed(t *testing.T) {
+	defer func() { db.Mocks.Users.GetByCurrentAuthUser = nil }()
+	for _, siteAdmin := range []bool{false, true} {
+		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: siteAdmin}, nil
+		}
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+
+		if err := checkEngineAllowed(ctx, &args{engine: protocol.EngineComby}); err != nil {
+			t.Errorf("siteAdmin %t: comby engine: got error %v", siteAdmin, err)
+		}
+		if err := checkEngineAllowed(ctx, &args{engine: protocol.EngineScript}); (err == nil) != siteAdmin {
+			t.Errorf("siteAdmin %t: script engine: got error %v", siteAdmin, err)
+		}
+	}
+}
+
 func TestCodemod_resolver(t *testing.T) {
 	raw := &rawCodemodResult{
 		URI:  "",
@@ -39,3 +85,47 @@ func TestCodemod_resolver(t *testing.T) {
 		t.Fatalf("Expected error %q", err)
 	}
 }
+
+func TestCodemod_unifiedDiff(t *testing.T) {
+	raws := []*rawCodemodResult{
+		{
+			URI:  "main.go",
+			Diff: "--- main.go\n+++ main.go\n@@ -1,1 +1,1 @@\n-func main() {\n+derp main() {",
+		},
+		{
+			URI:  "cmd/foo/foo.go",
+			Diff: "--- cmd/foo/foo.go\n+++ cmd/foo/foo.go\n@@ -2,1 +2,1 @@\n-foo\n+bar\n",
+		},
+	}
+
+	have, err := unifiedDiff(raws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "--- a/main.go\n+++ b/main.go\n@@ -1,1 +1,1 @@\n-func main() {\n+derp main() {\n" +
+		"--- a/cmd/foo/foo.go\n+++ b/cmd/foo/foo.go\n@@ -2,1 +2,1 @@\n-foo\n+bar\n"
+	if have != want {
+		t.Errorf("unifiedDiff:\nhave %q\nwant %q", have, want)
+	}
+
+	if _, err := unifiedDiff([]*rawCodemodResult{{Diff: "Not a valid diff"}}); err == nil {
+		t.Error("expected error for invalid diff")
+	}
+}
+
+func TestCodemodPatches_noReplace(t *testing.T) {
+	_, err := CodemodPatches(context.Background(), "repo:foo bar")
+	if err == nil || err.Error() != "bad request: the query has no 'replace:' filter" {
+		t.Fatalf("expected missing replace filter error, got %v", err)
+	}
+}
+
+func TestCreateCodemodCommits_invalidRef(t *testing.T) {
+	for _, ref := range []string{"heads/master", "refs/heads/master", "refs/tags/v1", "refs/codemod/"} {
+		_, err := CreateCodemodCommits(context.Background(), nil, ref, gitprotocol.PatchCommitInfo{})
+		if err == nil || !strings.HasPrefix(err.Error(), "bad request: invalid ref") {
+			t.Errorf("expected invalid ref error for %q, got %v", ref, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gituri"
+	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
+)
+
+// maxReferenceDefinitions is the maximum number of definitions of an
+// identifier that are excluded from its references.
+const maxReferenceDefinitions = 100
+
+type positionArgs struct {
+	graphqlutil.ConnectionArgs
+	Line      int32
+	Character int32
+}
+
+// Definitions returns the candidate definitions of the identifier at the
+// position in the blob, most likely first. They're found by looking up the
+// symbols with the identifier's name, so they're approximate.
+func (r *gitTreeEntryResolver) Definitions(ctx context.Context, args *positionArgs) (*symbolConnectionResolver, error) {
+	_, symbols, err := r.definitions(ctx, args.Line, args.Character, limitOrDefault(args.First)+1) // add 1 so we can determine PageInfo.hasNextPage
+	if err != nil && len(symbols) == 0 {
+		return nil, err
+	}
+
+	baseURI, err := gituri.Parse("git://" + string(r.commit.repo.repo.Name) + "?" + string(r.commit.oid))
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*symbolResolver, 0, len(symbols))
+	for _, symbol := range symbols {
+		resolvers = append(resolvers, toSymbolResolver(symbol, baseURI, strings.ToLower(symbol.Language), r.commit))
+	}
+	return &symbolConnectionResolver{symbols: resolvers, first: args.First}, nil
+}
+
+// References returns the locations in the repository where the identifier at
+// the position in the blob occurs as a whole word, other than its
+// definitions. Only files with the blob's extension are searched.
+func (r *gitTreeEntryResolver) References(ctx context.Context, args *positionArgs) (*locationConnectionResolver, error) {
+	identifier, definitions, err := r.definitions(ctx, args.Line, args.Character, maxReferenceDefinitions)
+	if err != nil {
+		return nil, err
+	}
+	if identifier == "" {
+		return &locationConnectionResolver{first: args.First}, nil
+	}
+
+	cachedRepo, err := backend.CachedGitRepo(ctx, r.commit.repo.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := limitOrDefault(args.First)
+	info := &search.PatternInfo{
+		Pattern:                `\b` + regexp.QuoteMeta(identifier) + `\b`,
+		IsRegExp:               true,
+		IsCaseSensitive:        true,
+		FileMatchLimit:         int32(limit) + 1,
+		PathPatternsAreRegExps: true,
+		PatternMatchesContent:  true,
+	}
+	if ext := path.Ext(r.Path()); ext != "" {
+		info.IncludePatterns = []string{regexp.QuoteMeta(ext) + "$"}
+	}
+
+	matches, limitHit, err := searchFilesInRepo(ctx, search.SearcherURLs(), r.commit.repo.repo, *cachedRepo, string(r.commit.oid), info, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return &locationConnectionResolver{
+		locations: referenceLocations(r.commit, matches, definitions),
+		first:     args.First,
+		limitHit:  limitHit,
+	}, nil
+}
+
+// definitions returns the identifier at the given position in the blob and
+// its first n candidate definitions.
+func (r *gitTreeEntryResolver) definitions(ctx context.Context, line, character int32, n int) (string, []protocol.Symbol, error) {
+	if r.IsDirectory() {
+		return "", nil, errors.New("definitions can only be found in files")
+	}
+
+	ctx, done := context.WithTimeout(ctx, 10*time.Second)
+	defer done()
+
+	identifier, symbols, err := backend.Symbols.Definitions(ctx, protocol.DefinitionArgs{
+		Repo:      r.commit.repo.repo.Name,
+		CommitID:  api.CommitID(r.commit.oid),
+		Path:      r.Path(),
+		Line:      int(line),
+		Character: int(character),
+		First:     n,
+	})
+	if ctx.Err() != nil && len(symbols) == 0 {
+		err = errors.New("processing symbols is taking longer than expected. Try again in a while")
+	}
+	return identifier, symbols, err
+}
+
+// referenceLocations returns the locations of the given text search matches
+// of an identifier in the commit, excluding those on the lines of its
+// definitions.
+func referenceLocations(commit *GitCommitResolver, matches []*fileMatchResolver, definitions []protocol.Symbol) []*locationResolver {
+	type fileLine struct {
+		path string
+		line int
+	}
+	definitionLines := make(map[fileLine]bool, len(definitions))
+	for _, d := range definitions {
+		definitionLines[fileLine{d.Path, d.Line - 1}] = true // symbol lines are 1-based
+	}
+
+	var locations []*locationResolver
+	for _, fm := range matches {
+		resource := &gitTreeEntryResolver{
+			commit: commit,
+			stat:   createFileInfo(fm.JPath, false),
+		}
+		for _, lm := range fm.JLineMatches {
+			line := int(lm.JLineNumber)
+			if definitionLines[fileLine{fm.JPath, line}] {
+				continue
+			}
+			for _, ol := range lm.JOffsetAndLengths {
+				locations = append(locations, &locationResolver{
+					resource: resource,
+					lspRange: &lsp.Range{
+						Start: lsp.Position{Line: line, Character: int(ol[0])},
+						End:   lsp.Position{Line: line, Character: int(ol[0] + ol[1])},
+					},
+				})
+			}
+		}
+	}
+	return locations
+}
+
+type locationConnectionResolver struct {
+	first     *int32
+	locations []*locationResolver
+	limitHit  bool
+}
+
+func (r *locationConnectionResolver) Nodes() []*locationResolver {
+	if len(r.locations) > limitOrDefault(r.first) {
+		return r.locations[:limitOrDefault(r.first)]
+	}
+	return r.locations
+}
+
+func (r *locationConnectionResolver) PageInfo() *graphqlutil.PageInfo {
+	return graphqlutil.HasNextPage(r.limitHit || len(r.locations) > limitOrDefault(r.first))
+}
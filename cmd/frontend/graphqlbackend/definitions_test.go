@@ -0,0 +1,46 @@
+package graphqlbackend
+
+import (
+	"reflect"
+	"testing"
+
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
+)
+
+func TestReferenceLocations(t *testing.T) {
+	matches := []*fileMatchResolver{
+		{
+			JPath: "a.go",
+			JLineMatches: []*lineMatch{
+				{JLineNumber: 2, JOffsetAndLengths: [][2]int32{{5, 3}}},
+				{JLineNumber: 7, JOffsetAndLengths: [][2]int32{{0, 3}, {10, 3}}},
+			},
+		},
+		{
+			JPath: "b/b.go",
+			JLineMatches: []*lineMatch{
+				{JLineNumber: 2, JOffsetAndLengths: [][2]int32{{1, 3}}},
+			},
+		},
+	}
+	definitions := []protocol.Symbol{{Name: "foo", Path: "a.go", Line: 3}}
+
+	type location struct {
+		Path  string
+		Range lsp.Range
+	}
+	var got []location
+	for _, l := range referenceLocations(&GitCommitResolver{}, matches, definitions) {
+		got = append(got, location{Path: l.resource.Path(), Range: *l.lspRange})
+	}
+
+	want := []location{
+		{Path: "a.go", Range: lsp.Range{Start: lsp.Position{Line: 7, Character: 0}, End: lsp.Position{Line: 7, Character: 3}}},
+		{Path: "a.go", Range: lsp.Range{Start: lsp.Position{Line: 7, Character: 10}, End: lsp.Position{Line: 7, Character: 13}}},
+		{Path: "b/b.go", Range: lsp.Range{Start: lsp.Position{Line: 2, Character: 1}, End: lsp.Position{Line: 2, Character: 4}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
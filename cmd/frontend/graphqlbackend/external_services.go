@@ -46,6 +46,10 @@ func (r *schemaResolver) AddExternalService(ctx context.Context, args *struct {
 	if err := db.ExternalServices.Create(ctx, conf.Get, externalService); err != nil {
 		return nil, err
 	}
+	backend.LogAuditEvent(ctx, backend.AuditExternalServiceCreate, fmt.Sprintf("external_service:%d", externalService.ID), map[string]string{
+		"kind":        externalService.Kind,
+		"displayName": externalService.DisplayName,
+	})
 
 	res := &externalServiceResolver{externalService: externalService}
 	if err := syncExternalService(ctx, externalService); err != nil {
@@ -87,6 +91,11 @@ func (*schemaResolver) UpdateExternalService(ctx context.Context, args *struct {
 	if err := db.ExternalServices.Update(ctx, ps, externalServiceID, update); err != nil {
 		return nil, err
 	}
+	// The configuration itself is not recorded, because it contains secrets.
+	backend.LogAuditEvent(ctx, backend.AuditExternalServiceUpdate, fmt.Sprintf("external_service:%d", externalServiceID), map[string]interface{}{
+		"displayName":   update.DisplayName,
+		"configChanged": update.Config != nil,
+	})
 
 	externalService, err := db.ExternalServices.GetByID(ctx, externalServiceID)
 	if err != nil {
@@ -143,6 +152,10 @@ func (*schemaResolver) DeleteExternalService(ctx context.Context, args *struct {
 	if err := db.ExternalServices.Delete(ctx, id); err != nil {
 		return nil, err
 	}
+	backend.LogAuditEvent(ctx, backend.AuditExternalServiceDelete, fmt.Sprintf("external_service:%d", id), map[string]string{
+		"kind":        externalService.Kind,
+		"displayName": externalService.DisplayName,
+	})
 
 	if err = syncExternalService(ctx, externalService); err != nil {
 		return nil, errors.Wrap(err, "warning: external service deleted, but sync request failed")
@@ -80,11 +80,21 @@ func (r *NodeResolver) ToCampaign() (CampaignResolver, bool) {
 	return n, ok
 }
 
+func (r *NodeResolver) ToCampaignPlan() (CampaignPlanResolver, bool) {
+	n, ok := r.Node.(CampaignPlanResolver)
+	return n, ok
+}
+
 func (r *NodeResolver) ToChangeset() (ChangesetResolver, bool) {
 	n, ok := r.Node.(ChangesetResolver)
 	return n, ok
 }
 
+func (r *NodeResolver) ToChangesetAction() (ChangesetActionResolver, bool) {
+	n, ok := r.Node.(ChangesetActionResolver)
+	return n, ok
+}
+
 func (r *NodeResolver) ToDiscussionComment() (*discussionCommentResolver, bool) {
 	n, ok := r.Node.(*discussionCommentResolver)
 	return n, ok
@@ -191,11 +201,21 @@ func (r *schemaResolver) nodeByID(ctx context.Context, id graphql.ID) (Node, err
 			return nil, onlyInEnterprise
 		}
 		return r.a8nResolver.CampaignByID(ctx, id)
+	case "CampaignPlan":
+		if r.a8nResolver == nil {
+			return nil, onlyInEnterprise
+		}
+		return r.a8nResolver.CampaignPlanByID(ctx, id)
 	case "Changeset":
 		if r.a8nResolver == nil {
 			return nil, onlyInEnterprise
 		}
 		return r.a8nResolver.ChangesetByID(ctx, id)
+	case "ChangesetAction":
+		if r.a8nResolver == nil {
+			return nil, onlyInEnterprise
+		}
+		return r.a8nResolver.ChangesetActionByID(ctx, id)
 	case "DiscussionComment":
 		return discussionCommentByID(ctx, id)
 	case "DiscussionThread":
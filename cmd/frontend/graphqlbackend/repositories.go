@@ -21,7 +21,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/pkg/repoupdater"
 )
 
-func (r *schemaResolver) Repositories(args *struct {
+func (r *schemaResolver) Repositories(ctx context.Context, args *struct {
 	graphqlutil.ConnectionArgs
 	Query           *string
 	Names           *[]string
@@ -32,6 +32,10 @@ func (r *schemaResolver) Repositories(args *struct {
 	NotCloned       bool
 	Indexed         bool
 	NotIndexed      bool
+	Topics          *[]string
+	Languages       *[]string
+	Visibility      *string
+	MissingUpstream bool
 	OrderBy         string
 	Descending      bool
 }) (*repositoryConnectionResolver, error) {
@@ -59,6 +63,23 @@ func (r *schemaResolver) Repositories(args *struct {
 	if args.Query != nil {
 		opt.Query = *args.Query
 	}
+	if args.Topics != nil {
+		opt.Topics = *args.Topics
+	}
+	if args.Languages != nil {
+		opt.Languages = *args.Languages
+	}
+	if args.Visibility != nil {
+		opt.Visibilities = []string{strings.ToLower(*args.Visibility)}
+	}
+	if args.MissingUpstream {
+		// 🚨 SECURITY: Only site admins can list repositories pending deletion, since they are
+		// otherwise hidden from users.
+		if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+			return nil, err
+		}
+		opt.MissingUpstream = true
+	}
 	args.ConnectionArgs.Set(&opt.LimitOffset)
 	return &repositoryConnectionResolver{
 		opt:             opt,
@@ -370,6 +391,31 @@ func (r *schemaResolver) DeleteRepository(ctx context.Context, args *struct {
 	return &EmptyResponse{}, nil
 }
 
+func (r *schemaResolver) RestoreRepositories(ctx context.Context, args *struct {
+	Repositories []graphql.ID
+}) (*EmptyResponse, error) {
+	// 🚨 SECURITY: Only site admins can restore repositories, because it's a site-wide action.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	ids := make([]api.RepoID, 0, len(args.Repositories))
+	for _, repo := range args.Repositories {
+		id, err := unmarshalRepositoryID(repo)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	for _, id := range ids {
+		if err := db.Repos.RestoreQuarantined(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return &EmptyResponse{}, nil
+}
+
 func repoNamesToStrings(repoNames []api.RepoName) []string {
 	strings := make([]string, len(repoNames))
 	for i, repoName := range repoNames {
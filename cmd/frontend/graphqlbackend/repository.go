@@ -194,6 +194,20 @@ func (r *RepositoryResolver) UpdatedAt() *DateTime {
 	return nil
 }
 
+func (r *RepositoryResolver) MissingUpstreamAt(ctx context.Context) (*DateTime, error) {
+	if err := r.hydrate(ctx); err != nil {
+		return nil, err
+	}
+	return DateTimeOrNil(r.repo.MissingUpstreamAt), nil
+}
+
+func (r *RepositoryResolver) Quarantined(ctx context.Context) (bool, error) {
+	if err := r.hydrate(ctx); err != nil {
+		return false, err
+	}
+	return r.repo.Quarantined, nil
+}
+
 func (r *RepositoryResolver) URL() string { return "/" + string(r.repo.Name) }
 
 func (r *RepositoryResolver) ExternalURLs(ctx context.Context) ([]*externallink.Resolver, error) {
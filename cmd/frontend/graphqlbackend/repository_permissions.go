@@ -0,0 +1,215 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+type repositoryPermissionsArgs struct {
+	Repositories          *[]graphql.ID
+	RepositoryNamePattern *string
+	Usernames             *[]string
+	Emails                *[]string
+	Orgs                  *[]string
+}
+
+func (r *schemaResolver) GrantRepositoryPermissions(ctx context.Context, args *repositoryPermissionsArgs) (*updateRepositoryPermissionsResult, error) {
+	// 🚨 SECURITY: Only site admins may grant repository permissions.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	repoIDs, grantees, err := resolveRepositoryPermissionsArgs(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Permissions.GrantExplicit(ctx, repoIDs, grantees, authz.Read); err != nil {
+		return nil, err
+	}
+	return &updateRepositoryPermissionsResult{repositoryCount: len(repoIDs)}, nil
+}
+
+func (r *schemaResolver) RevokeRepositoryPermissions(ctx context.Context, args *repositoryPermissionsArgs) (*updateRepositoryPermissionsResult, error) {
+	// 🚨 SECURITY: Only site admins may revoke repository permissions.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	repoIDs, grantees, err := resolveRepositoryPermissionsArgs(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Permissions.RevokeExplicit(ctx, repoIDs, grantees, authz.Read); err != nil {
+		return nil, err
+	}
+	return &updateRepositoryPermissionsResult{repositoryCount: len(repoIDs)}, nil
+}
+
+func (r *schemaResolver) SetRepositoryPermissions(ctx context.Context, args *struct {
+	Repository graphql.ID
+	Usernames  *[]string
+	Emails     *[]string
+	Orgs       *[]string
+}) (*EmptyResponse, error) {
+	// 🚨 SECURITY: Only site admins may set repository permissions.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	repoID, err := unmarshalRepositoryID(args.Repository)
+	if err != nil {
+		return nil, err
+	}
+	grantees, err := resolveGrantees(ctx, args.Usernames, args.Emails, args.Orgs)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Permissions.SetExplicit(ctx, repoID, grantees, authz.Read); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
+
+// resolveRepositoryPermissionsArgs returns the repositories and grantees of a
+// grantRepositoryPermissions or revokeRepositoryPermissions mutation.
+func resolveRepositoryPermissionsArgs(ctx context.Context, args *repositoryPermissionsArgs) ([]api.RepoID, db.ExplicitGrantees, error) {
+	var grantees db.ExplicitGrantees
+
+	repoIDs, err := resolveRepositoryIDs(ctx, args.Repositories, args.RepositoryNamePattern)
+	if err != nil {
+		return nil, grantees, err
+	}
+	grantees, err = resolveGrantees(ctx, args.Usernames, args.Emails, args.Orgs)
+	return repoIDs, grantees, err
+}
+
+// resolveRepositoryIDs returns the IDs of the given repositories and of the
+// repositories whose name matches pattern, without duplicates.
+func resolveRepositoryIDs(ctx context.Context, ids *[]graphql.ID, pattern *string) ([]api.RepoID, error) {
+	var repoIDs []api.RepoID
+	seen := map[api.RepoID]bool{}
+	add := func(id api.RepoID) {
+		if !seen[id] {
+			seen[id] = true
+			repoIDs = append(repoIDs, id)
+		}
+	}
+
+	if ids != nil {
+		for _, id := range *ids {
+			repoID, err := unmarshalRepositoryID(id)
+			if err != nil {
+				return nil, err
+			}
+			add(repoID)
+		}
+	}
+
+	if pattern != nil {
+		if _, err := regexp.Compile(*pattern); err != nil {
+			return nil, &badRequestError{err}
+		}
+		repos, err := db.Repos.List(ctx, db.ReposListOptions{
+			IncludePatterns: []string{*pattern},
+			Enabled:         true,
+			Disabled:        true,
+			OnlyRepoIDs:     true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repos {
+			add(repo.ID)
+		}
+	}
+
+	return repoIDs, nil
+}
+
+// resolveGrantees looks up the users with the given usernames or verified
+// emails, and the orgs with the given names.
+func resolveGrantees(ctx context.Context, usernames, emails, orgs *[]string) (db.ExplicitGrantees, error) {
+	var grantees db.ExplicitGrantees
+	if usernames != nil {
+		for _, username := range *usernames {
+			user, err := db.Users.GetByUsername(ctx, username)
+			if err != nil {
+				return grantees, err
+			}
+			grantees.UserIDs = append(grantees.UserIDs, user.ID)
+		}
+	}
+	if emails != nil {
+		for _, email := range *emails {
+			user, err := db.Users.GetByVerifiedEmail(ctx, email)
+			if err != nil {
+				return grantees, fmt.Errorf("no user with verified email %q: %s", email, err)
+			}
+			grantees.UserIDs = append(grantees.UserIDs, user.ID)
+		}
+	}
+	if orgs != nil {
+		for _, name := range *orgs {
+			org, err := db.Orgs.GetByName(ctx, name)
+			if err != nil {
+				return grantees, err
+			}
+			grantees.OrgIDs = append(grantees.OrgIDs, org.ID)
+		}
+	}
+	return grantees, nil
+}
+
+type updateRepositoryPermissionsResult struct {
+	repositoryCount int
+}
+
+func (r *updateRepositoryPermissionsResult) RepositoryCount() int32 { return int32(r.repositoryCount) }
+
+func (r *RepositoryResolver) PermissionGrants(ctx context.Context) ([]*repositoryPermissionGrantResolver, error) {
+	// 🚨 SECURITY: Only site admins may see who repositories are explicitly shared with.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	grants, err := db.Permissions.ListExplicit(ctx, r.repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*repositoryPermissionGrantResolver, len(grants))
+	for i, g := range grants {
+		resolvers[i] = &repositoryPermissionGrantResolver{grant: g}
+	}
+	return resolvers, nil
+}
+
+type repositoryPermissionGrantResolver struct {
+	grant *db.ExplicitGrant
+}
+
+func (r *repositoryPermissionGrantResolver) User(ctx context.Context) (*UserResolver, error) {
+	if r.grant.UserID == 0 {
+		return nil, nil
+	}
+	return UserByIDInt32(ctx, r.grant.UserID)
+}
+
+func (r *repositoryPermissionGrantResolver) Organization(ctx context.Context) (*OrgResolver, error) {
+	if r.grant.OrgID == 0 {
+		return nil, nil
+	}
+	return OrgByIDInt32(ctx, r.grant.OrgID)
+}
+
+func (r *repositoryPermissionGrantResolver) Permission() string { return r.grant.Perm.String() }
+
+func (r *repositoryPermissionGrantResolver) CreatedAt() DateTime {
+	return DateTime{Time: r.grant.CreatedAt}
+}
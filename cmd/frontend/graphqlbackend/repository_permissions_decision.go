@@ -0,0 +1,132 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+)
+
+func (r *schemaResolver) RepositoryPermissionsDecision(ctx context.Context, args *struct {
+	User       graphql.ID
+	Repository graphql.ID
+}) (*repositoryPermissionsDecisionResolver, error) {
+	// 🚨 SECURITY: Only site admins may see how another user's repository permissions are
+	// decided, which reveals their permissions and external accounts.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	userID, err := UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+	user, err := UserByIDInt32(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := repositoryByID(ctx, args.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	decision, err := db.Permissions.Explain(ctx, userID, repo.repo, authz.Read)
+	if err != nil {
+		return nil, err
+	}
+	return &repositoryPermissionsDecisionResolver{user: user, repo: repo, decision: decision}, nil
+}
+
+type repositoryPermissionsDecisionResolver struct {
+	user     *UserResolver
+	repo     *RepositoryResolver
+	decision *db.AuthzDecision
+}
+
+func (r *repositoryPermissionsDecisionResolver) User() *UserResolver             { return r.user }
+func (r *repositoryPermissionsDecisionResolver) Repository() *RepositoryResolver { return r.repo }
+func (r *repositoryPermissionsDecisionResolver) Permission() string              { return r.decision.Perm.String() }
+func (r *repositoryPermissionsDecisionResolver) Allowed() bool                   { return r.decision.Allowed }
+func (r *repositoryPermissionsDecisionResolver) SiteAdminBypass() bool {
+	return r.decision.SiteAdminBypass
+}
+func (r *repositoryPermissionsDecisionResolver) AuthzAllowByDefault() bool {
+	return r.decision.AuthzAllowByDefault
+}
+
+func (r *repositoryPermissionsDecisionResolver) Providers() []*authorizationProviderDecisionResolver {
+	resolvers := make([]*authorizationProviderDecisionResolver, len(r.decision.Providers))
+	for i, pd := range r.decision.Providers {
+		resolvers[i] = &authorizationProviderDecisionResolver{decision: pd}
+	}
+	return resolvers
+}
+
+func (r *repositoryPermissionsDecisionResolver) ExplicitPermissionsEnabled() bool {
+	return r.decision.ExplicitPermissionsEnabled
+}
+func (r *repositoryPermissionsDecisionResolver) ExplicitlyRestricted() bool {
+	return r.decision.ExplicitlyRestricted
+}
+func (r *repositoryPermissionsDecisionResolver) ExplicitlyGranted() bool {
+	return r.decision.ExplicitlyGranted
+}
+func (r *repositoryPermissionsDecisionResolver) AllowedByDefault() bool {
+	return r.decision.AllowedByDefault
+}
+
+type authorizationProviderDecisionResolver struct {
+	decision *db.ProviderDecision
+}
+
+func (r *authorizationProviderDecisionResolver) ServiceType() string { return r.decision.ServiceType }
+func (r *authorizationProviderDecisionResolver) ServiceID() string   { return r.decision.ServiceID }
+
+func (r *authorizationProviderDecisionResolver) ExternalAccount() *externalAccountResolver {
+	if r.decision.Account == nil {
+		return nil
+	}
+	return &externalAccountResolver{account: *r.decision.Account}
+}
+
+func (r *authorizationProviderDecisionResolver) ExternalAccountFetched() bool {
+	return r.decision.AccountFetched
+}
+
+func (r *authorizationProviderDecisionResolver) FetchAccountError() *string {
+	if r.decision.FetchAccountError == "" {
+		return nil
+	}
+	return strptr(r.decision.FetchAccountError)
+}
+
+func (r *authorizationProviderDecisionResolver) OwnsRepository() bool { return r.decision.OwnsRepo }
+
+func (r *authorizationProviderDecisionResolver) FromStoredPermissions() bool {
+	return r.decision.FromStore
+}
+
+func (r *authorizationProviderDecisionResolver) Permissions() *string {
+	if !r.decision.OwnsRepo {
+		return nil
+	}
+	perms := r.decision.Perms.String()
+	return &perms
+}
+
+func (r *authorizationProviderDecisionResolver) CacheHits() int32 {
+	return int32(r.decision.Cache.Hits)
+}
+
+func (r *authorizationProviderDecisionResolver) CacheMisses() int32 {
+	return int32(r.decision.Cache.Misses)
+}
+
+func (r *authorizationProviderDecisionResolver) LastSyncedAt() *DateTime {
+	if r.decision.LastSyncedAt.IsZero() {
+		return nil
+	}
+	return &DateTime{Time: r.decision.LastSyncedAt}
+}
@@ -0,0 +1,59 @@
+package graphqlbackend
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func TestResolveRepositoryPermissionsArgs(t *testing.T) {
+	resetMocks()
+	db.Mocks.Repos.List = func(ctx context.Context, opt db.ReposListOptions) ([]*types.Repo, error) {
+		if want := []string{"^gitolite"}; !reflect.DeepEqual(opt.IncludePatterns, want) {
+			t.Errorf("got patterns %q, want %q", opt.IncludePatterns, want)
+		}
+		if !opt.Enabled || !opt.Disabled {
+			t.Error("want enabled and disabled repositories")
+		}
+		return []*types.Repo{{ID: 2}, {ID: 3}}, nil
+	}
+	db.Mocks.Users.GetByUsername = func(ctx context.Context, username string) (*types.User, error) {
+		return &types.User{ID: 10}, nil
+	}
+	db.Mocks.Users.GetByVerifiedEmail = func(ctx context.Context, email string) (*types.User, error) {
+		return &types.User{ID: 11}, nil
+	}
+	db.Mocks.Orgs.GetByName = func(ctx context.Context, name string) (*types.Org, error) {
+		return &types.Org{ID: 20}, nil
+	}
+	defer resetMocks()
+
+	pattern := "^gitolite"
+	repoIDs, grantees, err := resolveRepositoryPermissionsArgs(context.Background(), &repositoryPermissionsArgs{
+		Repositories:          &[]graphql.ID{marshalRepositoryID(1), marshalRepositoryID(2)},
+		RepositoryNamePattern: &pattern,
+		Usernames:             &[]string{"alice"},
+		Emails:                &[]string{"bob@example.com"},
+		Orgs:                  &[]string{"acme"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []api.RepoID{1, 2, 3}; !reflect.DeepEqual(repoIDs, want) {
+		t.Errorf("got repo IDs %v, want %v", repoIDs, want)
+	}
+	if want := (db.ExplicitGrantees{UserIDs: []int32{10, 11}, OrgIDs: []int32{20}}); !reflect.DeepEqual(grantees, want) {
+		t.Errorf("got grantees %+v, want %+v", grantees, want)
+	}
+
+	invalid := "("
+	_, _, err = resolveRepositoryPermissionsArgs(context.Background(), &repositoryPermissionsArgs{RepositoryNamePattern: &invalid})
+	if _, ok := err.(*badRequestError); !ok {
+		t.Errorf("got error %v, want a bad request error", err)
+	}
+}
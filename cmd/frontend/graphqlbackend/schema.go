@@ -33,10 +33,20 @@ type Mutation {
     addChangesetsToCampaign(campaign: ID!, changesets: [ID!]!): Campaign!
     # Create a campaign in a namespace. The newly created campaign is returned.
     createCampaign(input: CreateCampaignInput!): Campaign!
+    # Previews the changesets a campaign would create by running the rewrite of
+    # the given specification over every repository matched by its scope query.
+    # The diffs are computed in the background; the returned plan can be passed
+    # to createCampaign once its status is COMPLETED or ERRORED.
+    previewCampaignPlan(specification: CampaignPlanSpecification!): CampaignPlan!
     # Updates a campaign.
     updateCampaign(input: UpdateCampaignInput!): Campaign!
     # Deletes a campaign.
     deleteCampaign(campaign: ID!): EmptyResponse
+    # Runs an action (e.g. closing or commenting) on the changesets of a
+    # campaign that match the given input. The action is run on one changeset
+    # after the other in the background; its results can be followed on the
+    # returned changeset action.
+    runChangesetAction(campaign: ID!, input: ChangesetActionInput!): ChangesetAction!
     # Updates the user profile information for the user with the given ID.
     #
     # Only the user and site admins may perform this mutation.
@@ -117,6 +127,65 @@ type Mutation {
     #
     # Only site admins may perform this mutation.
     deleteRepository(repository: ID!): EmptyResponse @deprecated(reason: "update external service exclude setting.")
+    # Restores quarantined repositories (see Repository.quarantined), which went missing from their code
+    # host in a sync that removed an unusually large share of its repositories. If a repository is still
+    # missing from its code host, it is marked as missing again on the next sync. Repositories that are
+    # missing from their code host but not quarantined can't be restored, since they were likely removed
+    # on purpose; they are restored automatically if they reappear on their code host.
+    #
+    # Only site admins may perform this mutation.
+    restoreRepositories(repositories: [ID!]!): EmptyResponse!
+    # Explicitly grants read access to repositories to users and to the members of organizations. This is
+    # meant for repositories whose code host has no permissions API (such as repositories from Gitolite,
+    # Phabricator or other external services). It has no effect unless the "permissions.explicit" site
+    # configuration property is enabled. A repository with explicitly granted permissions is no longer
+    # accessible by default, only to the users and organizations it is granted to.
+    #
+    # The repositories are those given by ID and those whose name matches the pattern, if any.
+    #
+    # Only site admins may perform this mutation.
+    grantRepositoryPermissions(
+        # The repositories to grant access to.
+        repositories: [ID!]
+        # A regular expression matching the names of the repositories to grant access to.
+        repositoryNamePattern: String
+        # The usernames of the users to grant access to.
+        usernames: [String!]
+        # The verified email addresses of the users to grant access to.
+        emails: [String!]
+        # The names of the organizations whose members to grant access to.
+        orgs: [String!]
+    ): UpdateRepositoryPermissionsResult!
+    # Revokes read access to repositories that was explicitly granted with grantRepositoryPermissions. The
+    # users may still have access through the repository's code host or other explicit grants.
+    #
+    # Only site admins may perform this mutation.
+    revokeRepositoryPermissions(
+        # The repositories to revoke access to.
+        repositories: [ID!]
+        # A regular expression matching the names of the repositories to revoke access to.
+        repositoryNamePattern: String
+        # The usernames of the users to revoke access from.
+        usernames: [String!]
+        # The verified email addresses of the users to revoke access from.
+        emails: [String!]
+        # The names of the organizations whose members to revoke access from.
+        orgs: [String!]
+    ): UpdateRepositoryPermissionsResult!
+    # Replaces the explicitly granted read access to a repository (see grantRepositoryPermissions). With no
+    # users or organizations, the repository no longer has explicitly granted permissions.
+    #
+    # Only site admins may perform this mutation.
+    setRepositoryPermissions(
+        # The repository.
+        repository: ID!
+        # The usernames of the users to grant access to.
+        usernames: [String!]
+        # The verified email addresses of the users to grant access to.
+        emails: [String!]
+        # The names of the organizations whose members to grant access to.
+        orgs: [String!]
+    ): EmptyResponse!
     # Creates a new user account.
     #
     # Only site admins may perform this mutation.
@@ -159,9 +228,29 @@ type Mutation {
     # - User, Organization, or Global settings authored by the user.
     # - Discussion threads and comments created by the user.
     #
+    # To block a user from using Sourcegraph while retaining their data, use setUserSuspended instead.
     deleteUser(user: ID!, hard: Boolean): EmptyResponse
     # Updates the current user's password. The oldPassword arg must match the user's current password.
     updatePassword(oldPassword: String!, newPassword: String!): EmptyResponse
+    # Starts enrolling the current user in multi-factor authentication with time-based one-time passwords
+    # (TOTP), which users who sign in with a username and password must then use to sign in. The result is the
+    # new enrollment's secret and recovery codes, which the caller is responsible for showing to the user (the
+    # recovery codes are not accessible by Sourcegraph after enrollment).
+    #
+    # The enrollment takes effect when it is confirmed with confirmTOTPEnrollment. Calling this again before
+    # then replaces the pending enrollment. If the user is already enrolled, it is an error.
+    enrollTOTP: TOTPEnrollment!
+    # Confirms the current user's pending multi-factor authentication enrollment (from enrollTOTP). The code
+    # must be a valid code from the user's authenticator app for the enrollment.
+    confirmTOTPEnrollment(code: String!): EmptyResponse
+    # Resets the user's multi-factor authentication enrollment, so they can sign in without a code from their
+    # authenticator app (unless multi-factor authentication is required by site configuration, in which case
+    # they must enroll again when they next sign in).
+    #
+    # Only the user or site admins may perform this mutation. Unless they are a site admin, the user must
+    # also provide a code from their authenticator app, one of their recovery codes or their password (if they
+    # are enrolled).
+    resetTOTP(user: ID!, code: String, recoveryCode: String, password: String): EmptyResponse
     # Creates an access token that grants the privileges of the specified user (referred to as the access token's
     # "subject" user after token creation). The result is the access token value, which the caller is responsible
     # for storing (it is not accessible by Sourcegraph after creation).
@@ -169,11 +258,28 @@ type Mutation {
     # The supported scopes are:
     #
     # - "user:all": Full control of all resources accessible to the user account.
+    # - "user:read": Read-only access to all resources accessible to the user account. The token can only be
+    #   used for GraphQL queries (not mutations) and GET requests.
+    # - "search": Only searching resources accessible to the user account. The token can only be used for the
+    #   GraphQL search query.
     # - "site-admin:sudo": Ability to perform any action as any other user. (Only site admins may create tokens
     #   with this scope.)
+    # - "scim": Only provisioning users and groups with the SCIM API. The token can only be used for the SCIM
+    #   API. (Only site admins may create tokens with this scope.)
+    #
+    # Every token must have exactly one of the "user:all", "user:read", "search" and "scim" scopes.
     #
     # Only the user or site admins may perform this mutation.
-    createAccessToken(user: ID!, scopes: [String!]!, note: String!): CreateAccessTokenResult!
+    createAccessToken(
+        user: ID!
+        scopes: [String!]!
+        note: String!
+        # If given, the token can only be used to access repositories whose name matches one of these regular
+        # expressions.
+        repositoryPatterns: [String!]
+        # If given, the token expires at this time.
+        expiresAt: DateTime
+    ): CreateAccessTokenResult!
     # Deletes and immediately revokes the specified access token, specified by either its ID or by the token
     # itself.
     #
@@ -325,6 +431,13 @@ type Mutation {
     #
     # Only site admins may perform this mutation.
     setUserIsSiteAdmin(userID: ID!, siteAdmin: Boolean!): EmptyResponse
+    # Suspends or unsuspends a user. Suspended users can't sign in or use access tokens, and they
+    # don't count toward the licensed user count. Their data (such as their settings and the
+    # discussions they authored) is retained. Suspending a user also revokes their access tokens and
+    # signs them out of all sessions.
+    #
+    # Only site admins may perform this mutation, and they can't suspend themselves.
+    setUserSuspended(user: ID!, suspended: Boolean!): EmptyResponse
     # Reloads the site by restarting the server. This is not supported for all deployment
     # types. This may cause downtime.
     #
@@ -364,6 +477,21 @@ type Mutation {
     ): SavedSearch!
     # Deletes a saved search
     deleteSavedSearch(id: ID!): EmptyResponse
+    # Runs the codemod of a search query with a 'replace:' filter and creates a
+    # commit from each changed repository's patch on the given ref (e.g.
+    # "refs/codemod/my-codemod") in Sourcegraph's copy of the repository, from
+    # where it can be fetched or pushed. If creating any of the commits fails,
+    # the refs updated so far are restored. Only site admins may perform this
+    # mutation.
+    createCodemodCommits(
+        # The search query, which must contain a 'replace:' filter.
+        query: String!
+        # The fully qualified ref to create the commits on. It must start with
+        # "refs/codemod/", so that branches and tags can't be overwritten.
+        ref: String!
+        # The commit message.
+        message: String!
+    ): [CodemodPatch!]!
 }
 
 # Input arguments for creating a campaign.
@@ -376,6 +504,48 @@ input CreateCampaignInput {
 
     # The description of the campaign as Markdown.
     description: String!
+
+    # The ID of a campaign plan whose diffs are turned into changesets on the
+    # code hosts, in the background, once the campaign is created.
+    plan: ID
+}
+
+# The specification of a campaign plan, which rewrites code in many
+# repositories using structural search and replace.
+input CampaignPlanSpecification {
+    # The search query whose repository filters (e.g. repo:, repogroup:) select
+    # the repositories to rewrite.
+    scopeQuery: String!
+
+    # The comby match template.
+    matchTemplate: String!
+
+    # The comby rewrite template.
+    rewriteTemplate: String!
+
+    # Only rewrite files with this extension (e.g. ".go").
+    fileExtension: String
+
+    # Exclude files in this directory.
+    directoryExclude: String
+}
+
+# Input arguments for running an action on the changesets of a campaign.
+input ChangesetActionInput {
+    # The action to run.
+    action: ChangesetActionType!
+
+    # The body of the comment as Markdown. Required for COMMENT actions.
+    body: String
+
+    # Only run the action on these changesets of the campaign.
+    changesets: [ID!]
+
+    # Only run the action on changesets in this state.
+    state: ChangesetState
+
+    # Only run the action on changesets in this review state.
+    reviewState: ChangesetReviewState
 }
 
 # Input arguments for updating a campaign.
@@ -418,6 +588,220 @@ type Campaign implements Node {
 
     # The changesets in this campaign.
     changesets(first: Int): ChangesetConnection!
+
+    # The plan this campaign was created from, if any.
+    plan: CampaignPlan
+
+    # The status of the creation of the changesets of the campaign's plan on
+    # the code hosts.
+    changesetCreationStatus: BackgroundProcessStatus!
+
+    # The changeset counts of the campaign for every day between from and to,
+    # computed from the history of its changesets.
+    changesetCountsOverTime(
+        # Only include changeset counts from this point in time onwards
+        # (inclusive). Defaults to the time the campaign was created.
+        from: DateTime
+        # Only include changeset counts up to this point in time (inclusive).
+        # Defaults to now.
+        to: DateTime
+    ): [ChangesetCounts!]!
+
+    # The actions run on the changesets of this campaign.
+    changesetActions(first: Int): ChangesetActionConnection!
+
+    # The counts of the campaign's open changesets by the state of their CI
+    # checks.
+    changesetCheckCounts: ChangesetCheckCounts!
+}
+
+# The counts of changesets by the overall state of their CI checks.
+type ChangesetCheckCounts {
+    # The total number of changesets.
+    total: Int!
+
+    # The number of changesets whose checks all passed.
+    passed: Int!
+
+    # The number of changesets with at least one failed check.
+    failed: Int!
+
+    # The number of changesets with checks still running and none failed.
+    pending: Int!
+
+    # The number of changesets without checks or whose check state is unknown.
+    unknown: Int!
+}
+
+# The counts of changesets by state at a given point in time.
+type ChangesetCounts {
+    # The point in time these counts were computed for.
+    date: DateTime!
+
+    # The total number of changesets.
+    total: Int!
+
+    # The number of merged changesets.
+    merged: Int!
+
+    # The number of closed changesets.
+    closed: Int!
+
+    # The number of open changesets (independent of review state).
+    open: Int!
+
+    # The number of open changesets that were approved.
+    openApproved: Int!
+
+    # The number of open changesets with changes requested.
+    openChangesRequested: Int!
+
+    # The number of open changesets still pending review.
+    openPending: Int!
+}
+
+# A preview of the changesets a campaign creates.
+type CampaignPlan implements Node {
+    # The unique ID for the campaign plan.
+    id: ID!
+
+    # The status of the computation of the plan's changesets.
+    status: BackgroundProcessStatus!
+
+    # The changesets that will be created by a campaign using this plan. Only
+    # repositories where the rewrite produced a diff are included.
+    changesets(first: Int): ChangesetPlanConnection!
+}
+
+# A changeset that will be created on a code host once its campaign plan is
+# used to create a campaign.
+type ChangesetPlan {
+    # The repository where this changeset will be created.
+    repository: Repository!
+
+    # The fully qualified name of the branch the changeset will be based on.
+    baseRef: String!
+
+    # The unified diff of the changeset.
+    diff: String!
+}
+
+# A list of changeset plans.
+type ChangesetPlanConnection {
+    # A list of changeset plans.
+    nodes: [ChangesetPlan!]!
+
+    # The total number of changeset plans in the connection.
+    totalCount: Int!
+
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# The state of a background process.
+enum BackgroundProcessState {
+    PROCESSING
+    ERRORED
+    COMPLETED
+}
+
+# The status of a process running in the background.
+type BackgroundProcessStatus {
+    # The number of jobs of the process that completed.
+    completedCount: Int!
+
+    # The number of jobs of the process that are still pending.
+    pendingCount: Int!
+
+    # The state of the process.
+    state: BackgroundProcessState!
+
+    # The errors of the jobs of the process that failed.
+    errors: [String!]!
+}
+
+# The type of an action run on changesets.
+enum ChangesetActionType {
+    # Comment on the changesets.
+    COMMENT
+    # Close the changesets.
+    CLOSE
+    # Reopen the changesets.
+    REOPEN
+    # Request another review from the previous reviewers of the changesets.
+    REQUEST_REVIEW
+    # Merge the changesets once their checks passed. Changesets whose checks
+    # failed are not merged.
+    MERGE
+}
+
+# An action run on the changesets of a campaign.
+type ChangesetAction implements Node {
+    # The unique ID for the changeset action.
+    id: ID!
+
+    # The type of the action.
+    action: ChangesetActionType!
+
+    # The body of the comment as Markdown, for COMMENT actions.
+    body: String!
+
+    # The campaign whose changesets the action was run on.
+    campaign: Campaign!
+
+    # The user who ran the action.
+    author: User!
+
+    # The status of the action's run over its changesets.
+    status: BackgroundProcessStatus!
+
+    # The results of the action for every changeset it was run on.
+    results(first: Int): ChangesetActionResultConnection!
+
+    # The date and time when the action was created.
+    createdAt: DateTime!
+}
+
+# The result of running a changeset action on a single changeset.
+type ChangesetActionResult {
+    # The changeset the action was run on.
+    changeset: Changeset!
+
+    # The state of the action on this changeset.
+    state: BackgroundProcessState!
+
+    # The error the action failed with on this changeset, if any.
+    error: String
+
+    # The date and time when the action started running on this changeset.
+    startedAt: DateTime
+
+    # The date and time when the action finished running on this changeset.
+    finishedAt: DateTime
+}
+
+# A list of changeset action results.
+type ChangesetActionResultConnection {
+    # A list of changeset action results.
+    nodes: [ChangesetActionResult!]!
+
+    # The total number of changeset action results in the connection.
+    totalCount: Int!
+
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# A list of changeset actions.
+type ChangesetActionConnection {
+    # A list of changeset actions.
+    nodes: [ChangesetAction!]!
+
+    # The total number of changeset actions in the connection.
+    totalCount: Int!
+
+    # Pagination information.
+    pageInfo: PageInfo!
 }
 
 # A list of campaigns.
@@ -446,6 +830,34 @@ enum ChangesetReviewState {
     PENDING
 }
 
+# The overall state of the CI checks of a changeset.
+enum ChangesetCheckState {
+    # Some checks are still running and none failed.
+    PENDING
+    # All checks passed.
+    PASSED
+    # At least one check failed.
+    FAILED
+    # The changeset has no checks or their state is unknown.
+    UNKNOWN
+}
+
+# A CI check run on the head commit of a changeset (e.g. a GitHub check run or
+# commit status, or a GitLab pipeline job).
+type ChangesetCheck {
+    # The name of the check.
+    name: String!
+
+    # The state of the check.
+    state: ChangesetCheckState!
+
+    # A description of the check, if any.
+    description: String
+
+    # The URL to the details of the check on the CI system, if any.
+    externalURL: String
+}
+
 # The input to the createChangesets mutation.
 input CreateChangesetInput {
     # The repository ID that this Changeset belongs to.
@@ -486,6 +898,12 @@ type Changeset implements Node {
 
     # The review state of this changeset.
     reviewState: ChangesetReviewState!
+
+    # The overall state of the CI checks of this changeset's head commit.
+    checkState: ChangesetCheckState!
+
+    # The CI checks of this changeset's head commit.
+    checks: [ChangesetCheck!]!
 }
 
 # A list of changesets.
@@ -798,6 +1216,23 @@ type CreateUserResult {
     resetPasswordURL: String
 }
 
+# The result for Mutation.grantRepositoryPermissions and Mutation.revokeRepositoryPermissions.
+type UpdateRepositoryPermissionsResult {
+    # The number of repositories that were updated.
+    repositoryCount: Int!
+}
+
+# The result for Mutation.enrollTOTP.
+type TOTPEnrollment {
+    # The secret, which the user adds to their authenticator app (encoded in base32).
+    secret: String!
+    # The otpauth:// URL of the secret, which authenticator apps can read from a QR code.
+    url: String!
+    # The recovery codes, which the user can use to sign in (each once) if they lose access to their
+    # authenticator app.
+    recoveryCodes: [String!]!
+}
+
 # The result for Mutation.randomizeUserPassword.
 type RandomizeUserPasswordResult {
     # The reset password URL that the user must visit to sign into their account again. If the builtin
@@ -873,11 +1308,29 @@ type Query {
         indexed: Boolean = true
         # Include repositories that do not have a text search index.
         notIndexed: Boolean = true
+        # Return only repositories labeled with all of these topics on their code host.
+        topics: [String!]
+        # Return only repositories whose primary language is any of these languages.
+        languages: [String!]
+        # Return only repositories with this visibility on their code host.
+        visibility: RepositoryVisibility
+        # Return only repositories that went missing from their code host and are pending deletion,
+        # including quarantined ones. Only site admins may list them.
+        missingUpstream: Boolean = false
         # Sort field.
         orderBy: RepositoryOrderBy = REPOSITORY_NAME
         # Sort direction.
         descending: Boolean = false
     ): RepositoryConnection!
+    # Explains whether a user can read a repository: checks the permission as it would be checked for a
+    # request by the user, and returns how it was decided. This is meant for debugging repository
+    # permissions.
+    #
+    # Checking may associate the user with external accounts fetched from authorization providers, as a
+    # request by the user would.
+    #
+    # Only site admins may perform this query.
+    repositoryPermissionsDecision(user: ID!, repository: ID!): RepositoryPermissionsDecision!
     # Looks up a Phabricator repository by name.
     phabricatorRepo(
         # The name, for example "github.com/gorilla/mux".
@@ -974,6 +1427,13 @@ type Query {
         # The search query (such as "foo" or "repo:myrepo foo").
         query: String = ""
     ): Search
+    # Runs the codemod of a search query with a 'replace:' filter and returns a
+    # unified patch for each repository revision the codemod changes, ordered
+    # by repository name.
+    codemodPatches(
+        # The search query, which must contain a 'replace:' filter.
+        query: String!
+    ): [CodemodPatch!]!
     # All saved searches configured for the current user, merged from all configurations.
     savedSearches: [SavedSearch!]!
     # All repository groups for the current user, merged from all configurations.
@@ -1251,6 +1711,19 @@ type CodemodResult implements GenericSearchResultInterface {
     rawDiff: String!
 }
 
+# The changes a codemod makes to a revision of a repository.
+type CodemodPatch {
+    # The repository.
+    repository: Repository!
+    # The commit the codemod was run against and the patch applies to.
+    baseCommit: GitCommit!
+    # The changes to all files as a unified diff that can be applied with
+    # "git apply".
+    patch: String!
+    # The commit created from the patch by createCodemodCommits, if any.
+    commit: GitCommit
+}
+
 # A search result that is a diff between two diffable Git objects.
 type DiffSearchResult {
     # The diff that matched the search query.
@@ -1372,6 +1845,15 @@ type Repository implements Node & GenericSearchResultInterface {
     #
     # The date when this repository's metadata was last updated on Sourcegraph.
     updatedAt: DateTime
+    # The date when this repository went missing from its code host, or null if it isn't missing.
+    # Repositories missing from their code host are hidden from users (unless quarantined) and
+    # deleted after the grace period configured in the repoDeletionGracePeriod site setting.
+    missingUpstreamAt: DateTime
+    # Whether this repository went missing from its code host in a sync that removed more than
+    # the share of its external service's repositories configured in the
+    # repoDeletionQuarantineThreshold site setting. Quarantined repositories remain accessible
+    # and are never deleted automatically.
+    quarantined: Boolean!
     # Returns information about the given commit in the repository, or null if no commit exists with the given rev.
     commit(
         # The Git revision specifier (revspec) for the commit.
@@ -1457,6 +1939,11 @@ type Repository implements Node & GenericSearchResultInterface {
     redirectURL: String
     # Whether the viewer has admin privileges on this repository.
     viewerCanAdminister: Boolean!
+    # The permissions on this repository explicitly granted by site admins (see
+    # Mutation.grantRepositoryPermissions).
+    #
+    # Only site admins may access this field.
+    permissionGrants: [RepositoryPermissionGrant!]!
     # Base64 data uri to an icon.
     icon: String!
     # A markdown string that is rendered prominently.
@@ -1467,6 +1954,80 @@ type Repository implements Node & GenericSearchResultInterface {
     matches: [SearchResultMatch!]!
 }
 
+# A permission on a repository explicitly granted by a site admin to a user or to the members of an
+# organization.
+type RepositoryPermissionGrant {
+    # The user the permission is granted to, if any.
+    user: User
+    # The organization whose members the permission is granted to, if any.
+    organization: Org
+    # The granted permission, such as "read".
+    permission: String!
+    # When the permission was granted.
+    createdAt: DateTime!
+}
+
+# How a repository permission check for a user was decided (see Query.repositoryPermissionsDecision).
+type RepositoryPermissionsDecision {
+    # The user.
+    user: User!
+    # The repository.
+    repository: Repository!
+    # The checked permission, such as "read".
+    permission: String!
+    # Whether the user has the permission on the repository.
+    allowed: Boolean!
+    # Whether the user is a site admin, who has access to all repositories. Nothing else is checked then.
+    siteAdminBypass: Boolean!
+    # The authzAllowByDefault setting of the authorization providers, which allows access to repositories
+    # not owned by any authorization provider.
+    authzAllowByDefault: Boolean!
+    # The authorization providers that were checked, in order.
+    providers: [AuthorizationProviderDecision!]!
+    # Whether explicitly granted permissions are enforced (the "permissions.explicit" site configuration
+    # property).
+    explicitPermissionsEnabled: Boolean!
+    # Whether the repository has explicitly granted permissions, which makes it no longer accessible by
+    # default.
+    explicitlyRestricted: Boolean!
+    # Whether the permission is explicitly granted to the user, directly or through an organization.
+    explicitlyGranted: Boolean!
+    # Whether access was allowed because no authorization provider owns the repository and
+    # authzAllowByDefault is true.
+    allowedByDefault: Boolean!
+}
+
+# What an authorization provider contributed to a repository permission check.
+type AuthorizationProviderDecision {
+    # The type of the provider's external service, such as "gitlab".
+    serviceType: String!
+    # The ID of the provider's external service, such as "https://gitlab.example.com/".
+    serviceID: String!
+    # The user's external account on the provider, if any.
+    externalAccount: ExternalAccount
+    # Whether the external account was fetched from the provider by this check, instead of being already
+    # associated with the user. A fetched account is not saved.
+    externalAccountFetched: Boolean!
+    # The error fetching the user's external account from the provider, if any.
+    fetchAccountError: String
+    # Whether the repository is on the provider's code host. The provider decides the permission if so.
+    ownsRepository: Boolean!
+    # Whether the permissions were read from the permissions synced in the background, instead of asking
+    # the provider (which may use its own cache).
+    fromStoredPermissions: Boolean!
+    # The permissions of the user on the repository according to the provider, such as "read" or "none".
+    # Null if the provider doesn't own the repository.
+    permissions: String
+    # The number of repositories whose permissions the provider computed from its own cache. Zero if the
+    # provider wasn't asked or doesn't report its cache usage.
+    cacheHits: Int!
+    # The number of repositories whose permissions the provider computed by asking its code host. Zero if
+    # the provider wasn't asked or doesn't report its cache usage.
+    cacheMisses: Int!
+    # When the user's permissions were last synced in the background from the provider, if ever.
+    lastSyncedAt: DateTime
+}
+
 # A URL to a resource on an external service, such as the URL to a repository on its external (origin) code host.
 type ExternalLink {
     # The URL to the resource.
@@ -1751,6 +2312,14 @@ type Location {
     canonicalURL: String!
 }
 
+# A list of locations.
+type LocationConnection {
+    # A list of locations.
+    nodes: [Location!]!
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
 # A range inside a file. The start position is inclusive, and the end position is exclusive.
 type Range {
     # The start position of the range (inclusive).
@@ -2240,6 +2809,28 @@ type GitBlob implements TreeEntry & File2 {
         # Return symbols matching the query.
         query: String
     ): SymbolConnection!
+    # The candidate definitions of the identifier at the position in this blob, most likely first.
+    # Symbols in this blob rank first, then those in the same directory, then those in the same
+    # language. This is based on symbol names, so it's approximate.
+    definitions(
+        # The zero-based line of the position.
+        line: Int!
+        # The zero-based character offset of the position on the line.
+        character: Int!
+        # Returns the first n definitions from the list.
+        first: Int
+    ): SymbolConnection!
+    # The locations in the repository where the identifier at the position in this blob occurs
+    # as a whole word, except for its definitions. Only files with this blob's extension are
+    # searched. This is based on text search, so it's approximate.
+    references(
+        # The zero-based line of the position.
+        line: Int!
+        # The zero-based character offset of the position on the line.
+        character: Int!
+        # Returns the first n references from the list.
+        first: Int
+    ): LocationConnection!
     # Always false, since a blob is a file, not directory.
     isSingleChild(
         # Returns the first n files in the tree.
@@ -2361,6 +2952,13 @@ type User implements Node & SettingsSubject & Namespace {
     #
     # Only the user and site admins can access this field.
     siteAdmin: Boolean!
+    # Whether the user is suspended, which means they can't sign in or use access tokens.
+    suspended: Boolean!
+    # Whether the user is enrolled in multi-factor authentication, which means they must enter a code from their
+    # authenticator app to sign in with a username and password.
+    #
+    # Only the user and site admins can access this field.
+    totpEnabled: Boolean!
     # The latest settings for the user.
     #
     # Only the user and site admins can access this field.
@@ -2451,6 +3049,11 @@ type AccessToken implements Node {
     createdAt: DateTime!
     # The date when the access token was last used to authenticate a request.
     lastUsedAt: DateTime
+    # The regular expressions matching the names of the repositories the access token can be used to access. If
+    # empty, the access token can be used to access all repositories accessible to the user account.
+    repositoryPatterns: [String!]!
+    # The date when the access token expires, if ever.
+    expiresAt: DateTime
 }
 
 # A list of access tokens.
@@ -2464,6 +3067,37 @@ type AccessTokenConnection {
     pageInfo: PageInfo!
 }
 
+# A list of audit log entries.
+type AuditLogEntryConnection {
+    # A list of audit log entries.
+    nodes: [AuditLogEntry!]!
+    # The total count of audit log entries in the connection. This total count may be larger than the number of
+    # nodes in this object when the result is paginated.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# An entry in the audit log, which records a security-relevant action. Entries are never changed or deleted.
+type AuditLogEntry {
+    # The unique ID for the entry.
+    id: ID!
+    # The user who performed the action, or null if it was performed by an unauthenticated or internal actor or
+    # the user has since been deleted.
+    actor: User
+    # The ID of the user who performed the action, even if the user has since been deleted, or null if it was
+    # performed by an unauthenticated or internal actor.
+    actorID: ID
+    # The action performed, such as "site_config.update" or "access_token.create".
+    action: String!
+    # What the action was performed on, such as "user:123" or "external_service:4", or the empty string.
+    subject: String!
+    # Additional details about the action, as a JSON object. It never contains secrets.
+    data: String!
+    # The date when the action was performed.
+    createdAt: DateTime!
+}
+
 # A list of authentication providers.
 type AuthProviderConnection {
     # A list of authentication providers.
@@ -2910,6 +3544,16 @@ enum RepositoryOrderBy {
     REPOSITORY_CREATED_AT
 }
 
+# The visibility of a repository on its code host.
+enum RepositoryVisibility {
+    # The repository is visible to everyone.
+    PUBLIC
+    # The repository is only visible to users explicitly granted access.
+    PRIVATE
+    # The repository is visible to all users of the code host (e.g. GitLab internal projects).
+    INTERNAL
+}
+
 # The default settings for the Sourcegraph instance. This is hardcoded in
 # Sourcegraph, but may change from release to release.
 type DefaultSettings implements SettingsSubject {
@@ -2970,6 +3614,27 @@ type Site implements SettingsSubject {
         # Returns the first n access tokens from the list.
         first: Int
     ): AccessTokenConnection!
+    # The audit log of security-relevant actions on this site, such as changing the site configuration, creating
+    # access tokens, or using sudo access tokens. The most recent entries are listed first.
+    #
+    # Only site admins can access this field.
+    auditLog(
+        # Returns the first n entries from the list.
+        first: Int
+        # Include only entries older than the entry with this ID. To get the next page of entries, pass the ID of
+        # the last entry of the current page.
+        before: ID
+        # Include only entries of actions performed by this user.
+        actor: ID
+        # Include only entries of these actions, such as "access_token.create".
+        actions: [String!]
+        # Include only entries of actions on this subject, such as "user:123".
+        subject: String
+        # Include only entries created at or after this time.
+        since: DateTime
+        # Include only entries created before this time.
+        until: DateTime
+    ): AuditLogEntryConnection!
     # A list of all authentication providers. This information is visible to all viewers and does not contain any
     # secret information.
     authProviders: AuthProviderConnection!
@@ -235,6 +235,14 @@ func (r *searchResolver) resolveRepositories(ctx context.Context, effectiveRepoF
 
 	commitAfter, _ := r.query.StringValue(query.FieldRepoHasCommitAfter)
 
+	topics, minusTopics := r.query.StringValues(query.FieldTopic)
+
+	visibilityStr, _ := r.query.StringValue(query.FieldVisibility)
+	visibilities, err := parseRepoVisibility(visibilityStr)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
 	tr.LazyPrintf("resolveRepositories - start")
 	repoRevs, missingRepoRevs, overLimit, err = resolveRepositories(ctx, resolveRepoOp{
 		repoFilters:      repoFilters,
@@ -245,6 +253,9 @@ func (r *searchResolver) resolveRepositories(ctx context.Context, effectiveRepoF
 		onlyArchived:     archived == Only || archived == True,
 		noArchived:       archived == No || archived == False,
 		commitAfter:      commitAfter,
+		topics:           topics,
+		minusTopics:      minusTopics,
+		visibilities:     visibilities,
 	})
 	tr.LazyPrintf("resolveRepositories - done")
 	if effectiveRepoFieldValues == nil {
@@ -256,6 +267,33 @@ func (r *searchResolver) resolveRepositories(ctx context.Context, effectiveRepoF
 	return repoRevs, missingRepoRevs, overLimit, err
 }
 
+// ResolveRepositoriesForQuery returns the repositories matched by the
+// repository filters (e.g. repo:, repogroup:, fork:) of the given search
+// query. It returns an error if the query matches more repositories than
+// the configured maximum number of repositories to search.
+func ResolveRepositoriesForQuery(ctx context.Context, q string) ([]*types.Repo, error) {
+	parsed, err := query.ParseAndCheck(q)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &searchResolver{query: parsed}
+	repoRevs, _, overLimit, err := r.resolveRepositories(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if overLimit {
+		return nil, errors.Errorf("query %q matches more than %d repositories", q, maxReposToSearch())
+	}
+
+	repos := make([]*types.Repo, 0, len(repoRevs))
+	for _, rr := range repoRevs {
+		repos = append(repos, rr.Repo)
+	}
+	return repos, nil
+}
+
 // a patternRevspec maps an include pattern to a list of revisions
 // for repos matching that pattern. "map" in this case does not mean
 // an actual map, because we want regexp matches, not identity matches.
@@ -358,6 +396,23 @@ type resolveRepoOp struct {
 	noArchived       bool
 	onlyArchived     bool
 	commitAfter      string
+	topics           []string
+	minusTopics      []string
+	visibilities     []string
+}
+
+// parseRepoVisibility parses the value of the visibility: search field
+// into the list of repository visibilities to include. An empty value or "any"
+// includes repositories of any visibility.
+func parseRepoVisibility(s string) ([]string, error) {
+	switch v := strings.ToLower(s); v {
+	case "", "any":
+		return nil, nil
+	case "public", "private", "internal":
+		return []string{v}, nil
+	default:
+		return nil, fmt.Errorf("invalid visibility: value %q (must be one of public, private, internal or any)", s)
+	}
 }
 
 func resolveRepositories(ctx context.Context, op resolveRepoOp) (repoRevisions, missingRepoRevisions []*search.RepositoryRevisions, overLimit bool, err error) {
@@ -407,7 +462,8 @@ func resolveRepositories(ctx context.Context, op resolveRepoOp) (repoRevisions,
 	}
 
 	var defaultRepos []*types.Repo
-	if envvar.SourcegraphDotComMode() && len(includePatterns) == 0 {
+	hasMetadataFilters := len(op.topics) > 0 || len(op.minusTopics) > 0 || len(op.visibilities) > 0
+	if envvar.SourcegraphDotComMode() && len(includePatterns) == 0 && !hasMetadataFilters {
 		getIndexedRepos := func(ctx context.Context, revs []*search.RepositoryRevisions) (indexed, unindexed []*search.RepositoryRevisions, err error) {
 			return zoektIndexedRepos(ctx, search.Indexed(), revs, nil)
 		}
@@ -431,11 +487,14 @@ func resolveRepositories(ctx context.Context, op resolveRepoOp) (repoRevisions,
 			ExcludePattern:  unionRegExps(excludePatterns),
 			Enabled:         true,
 			// List N+1 repos so we can see if there are repos omitted due to our repo limit.
-			LimitOffset:  &db.LimitOffset{Limit: maxRepoListSize + 1},
-			NoForks:      op.noForks,
-			OnlyForks:    op.onlyForks,
-			NoArchived:   op.noArchived,
-			OnlyArchived: op.onlyArchived,
+			LimitOffset:   &db.LimitOffset{Limit: maxRepoListSize + 1},
+			NoForks:       op.noForks,
+			OnlyForks:     op.onlyForks,
+			NoArchived:    op.noArchived,
+			OnlyArchived:  op.onlyArchived,
+			Topics:        op.topics,
+			ExcludeTopics: op.minusTopics,
+			Visibilities:  op.visibilities,
 		})
 		tr.LazyPrintf("Repos.List - done")
 		if err != nil {
@@ -35,6 +35,9 @@ func searchRepositories(ctx context.Context, args *search.Args, limit int32) (re
 		query.FieldFork:        {},
 		query.FieldArchived:    {},
 		query.FieldRepoHasFile: {},
+
+		query.FieldTopic:      {},
+		query.FieldVisibility: {},
 	}
 	// Don't return repo results if the search contains fields that aren't on the whitelist.
 	// Matching repositories based whether they contain files at a certain path (etc.) is not yet implemented.
@@ -299,3 +299,25 @@ func Test_defaultRepositories(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRepoVisibility(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "any", want: nil},
+		{in: "Private", want: []string{"private"}},
+		{in: "internal", want: []string{"internal"}},
+		{in: "secret", wantErr: true},
+	} {
+		have, err := parseRepoVisibility(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseRepoVisibility(%q): unexpected error: %v", tc.in, err)
+		}
+		if !reflect.DeepEqual(have, tc.want) {
+			t.Errorf("parseRepoVisibility(%q): have %q, want %q", tc.in, have, tc.want)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/jsonx"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
@@ -159,10 +160,11 @@ func (r *settingsMutation) editSettings(ctx context.Context, keyPath jsonx.Path,
 func (r *settingsMutation) OverwriteSettings(ctx context.Context, args *struct {
 	Contents string
 }) (*updateSettingsPayload, error) {
-	_, err := settingsCreateIfUpToDate(ctx, r.subject, r.input.LastID, actor.FromContext(ctx).UID, args.Contents)
+	updatedSettings, err := settingsCreateIfUpToDate(ctx, r.subject, r.input.LastID, actor.FromContext(ctx).UID, args.Contents)
 	if err != nil {
 		return nil, err
 	}
+	r.logAuditEvent(ctx, updatedSettings.ID)
 	return &updateSettingsPayload{}, nil
 }
 
@@ -187,9 +189,25 @@ func (r *settingsMutation) doUpdateSettings(ctx context.Context, computeEdits fu
 	if err != nil {
 		return 0, err
 	}
+	r.logAuditEvent(ctx, updatedSettings.ID)
 	return updatedSettings.ID, nil
 }
 
+// logAuditEvent records the update of the settings in the audit log. The settings contents are
+// not recorded, but are kept in the settings history under settingsID.
+func (r *settingsMutation) logAuditEvent(ctx context.Context, settingsID int32) {
+	var subject string
+	switch s := r.subject.toSubject(); {
+	case s.Site:
+		subject = "site"
+	case s.Org != nil:
+		subject = fmt.Sprintf("org:%d", *s.Org)
+	case s.User != nil:
+		subject = fmt.Sprintf("user:%d", *s.User)
+	}
+	backend.LogAuditEvent(ctx, backend.AuditSettingsUpdate, subject, map[string]int32{"settingsID": settingsID})
+}
+
 func (r *settingsMutation) getCurrentSettings(ctx context.Context) (string, error) {
 	// Get the settings file whose contents to mutate.
 	settings, err := db.Settings.GetLatest(ctx, r.subject.toSubject())
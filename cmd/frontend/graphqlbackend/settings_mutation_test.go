@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
@@ -19,6 +20,12 @@ func TestSettingsMutation_EditSettings(t *testing.T) {
 	db.Mocks.Settings.GetLatest = func(context.Context, api.SettingsSubject) (*api.Settings, error) {
 		return &api.Settings{ID: 1, Contents: "{}"}, nil
 	}
+	db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+		if e.Action != backend.AuditSettingsUpdate || e.Subject != "user:1" || string(e.Data) != `{"settingsID":2}` {
+			t.Errorf("got unexpected audit log entry %+v", e)
+		}
+		return nil
+	}
 	db.Mocks.Settings.CreateIfUpToDate = func(ctx context.Context, subject api.SettingsSubject, lastID, authorUserID *int32, contents string) (*api.Settings, error) {
 		if want := `{
   "p": {
@@ -67,6 +74,12 @@ func TestSettingsMutation_OverwriteSettings(t *testing.T) {
 	db.Mocks.Settings.GetLatest = func(context.Context, api.SettingsSubject) (*api.Settings, error) {
 		return &api.Settings{ID: 1, Contents: "{}"}, nil
 	}
+	db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+		if e.Action != backend.AuditSettingsUpdate || e.Subject != "user:1" || string(e.Data) != `{"settingsID":2}` {
+			t.Errorf("got unexpected audit log entry %+v", e)
+		}
+		return nil
+	}
 	db.Mocks.Settings.CreateIfUpToDate = func(ctx context.Context, subject api.SettingsSubject, lastID, authorUserID *int32, contents string) (*api.Settings, error) {
 		if want := `x`; contents != want {
 			t.Errorf("got %q, want %q", contents, want)
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/db/globalstatedb"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
+	"github.com/sourcegraph/sourcegraph/pkg/jsonc"
 	"github.com/sourcegraph/sourcegraph/pkg/version"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
@@ -198,10 +201,41 @@ func (r *schemaResolver) UpdateSiteConfiguration(ctx context.Context, args *stru
 		return false, fmt.Errorf("blank site configuration is invalid (you can clear the site configuration by entering an empty JSON object: {})")
 	}
 	prev := globals.ConfigurationServerFrontendOnly.Raw()
+	prevSite := prev.Site
 	prev.Site = args.Input
 	// TODO(slimsag): future: actually pass lastID through to prevent race conditions
 	if err := globals.ConfigurationServerFrontendOnly.Write(ctx, prev); err != nil {
 		return false, err
 	}
+	// The site configuration contains secrets, so only the names of the changed properties are
+	// recorded.
+	backend.LogAuditEvent(ctx, backend.AuditSiteConfigUpdate, "site", map[string][]string{
+		"changedProperties": changedProperties(prevSite, args.Input),
+	})
 	return globals.ConfigurationServerFrontendOnly.NeedServerRestart(), nil
 }
+
+// changedProperties returns the sorted names of the top-level properties that differ between the
+// two JSONC objects. If either is invalid, it returns nil.
+func changedProperties(before, after string) []string {
+	var a, b map[string]interface{}
+	if err := jsonc.Unmarshal(before, &a); err != nil {
+		return nil
+	}
+	if err := jsonc.Unmarshal(after, &b); err != nil {
+		return nil
+	}
+	changed := []string{}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !reflect.DeepEqual(v, w) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
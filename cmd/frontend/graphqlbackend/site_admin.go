@@ -3,6 +3,7 @@ package graphqlbackend
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
@@ -31,7 +32,8 @@ func (*schemaResolver) DeleteUser(ctx context.Context, args *struct {
 		return nil, errors.New("unable to delete current user")
 	}
 
-	if args.Hard != nil && *args.Hard {
+	hard := args.Hard != nil && *args.Hard
+	if hard {
 		if err := db.Users.HardDelete(ctx, userID); err != nil {
 			return nil, err
 		}
@@ -40,6 +42,7 @@ func (*schemaResolver) DeleteUser(ctx context.Context, args *struct {
 			return nil, err
 		}
 	}
+	backend.LogAuditEvent(ctx, backend.AuditUserDelete, fmt.Sprintf("user:%d", userID), map[string]bool{"hard": hard})
 	return &EmptyResponse{}, nil
 }
 
@@ -59,6 +62,7 @@ func (*schemaResolver) DeleteOrganization(ctx context.Context, args *struct {
 	if err := db.Orgs.Delete(ctx, orgID); err != nil {
 		return nil, err
 	}
+	backend.LogAuditEvent(ctx, backend.AuditOrgDelete, fmt.Sprintf("org:%d", orgID), nil)
 	return &EmptyResponse{}, nil
 }
 
@@ -88,5 +92,38 @@ func (*schemaResolver) SetUserIsSiteAdmin(ctx context.Context, args *struct {
 	if err := db.Users.SetIsSiteAdmin(ctx, userID, args.SiteAdmin); err != nil {
 		return nil, err
 	}
+	action := backend.AuditUserSiteAdminRevoke
+	if args.SiteAdmin {
+		action = backend.AuditUserSiteAdminGrant
+	}
+	backend.LogAuditEvent(ctx, action, fmt.Sprintf("user:%d", userID), nil)
+	return &EmptyResponse{}, nil
+}
+
+func (*schemaResolver) SetUserSuspended(ctx context.Context, args *struct {
+	User      graphql.ID
+	Suspended bool
+}) (*EmptyResponse, error) {
+	// 🚨 SECURITY: Only site admins can suspend or unsuspend users.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := CurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID() == args.User && args.Suspended {
+		return nil, errors.New("refusing to suspend current user")
+	}
+
+	userID, err := UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := backend.SetUserSuspended(ctx, userID, args.Suspended, ""); err != nil {
+		return nil, err
+	}
 	return &EmptyResponse{}, nil
 }
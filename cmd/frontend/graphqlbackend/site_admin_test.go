@@ -0,0 +1,106 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+)
+
+func TestSetUserSuspended(t *testing.T) {
+	const (
+		uid1GQLID = "VXNlcjox"
+		uid2GQLID = "VXNlcjoy"
+	)
+
+	t.Run("non-site admin", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+			return &types.User{ID: 1}, nil
+		}
+		db.Mocks.Users.SetSuspended = func(id int32, suspended bool) error {
+			t.Error("SetSuspended called")
+			return nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		_, err := (&schemaResolver{}).SetUserSuspended(ctx, &struct {
+			User      graphql.ID
+			Suspended bool
+		}{User: uid2GQLID, Suspended: true})
+		if want := backend.ErrMustBeSiteAdmin; err != want {
+			t.Errorf("got err %v, want %v", err, want)
+		}
+	})
+
+	t.Run("current user", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: true}, nil
+		}
+		db.Mocks.Users.SetSuspended = func(id int32, suspended bool) error {
+			t.Error("SetSuspended called")
+			return nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		_, err := (&schemaResolver{}).SetUserSuspended(ctx, &struct {
+			User      graphql.ID
+			Suspended bool
+		}{User: uid1GQLID, Suspended: true})
+		if err == nil {
+			t.Error("got nil error")
+		}
+	})
+
+	t.Run("site admin", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: true}, nil
+		}
+		var suspended []int32
+		db.Mocks.Users.SetSuspended = func(id int32, s bool) error {
+			if s {
+				suspended = append(suspended, id)
+			}
+			return nil
+		}
+		var audited []string
+		db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+			audited = append(audited, e.Action+" "+e.Subject)
+			return nil
+		}
+
+		gqltesting.RunTests(t, []*gqltesting.Test{
+			{
+				Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+				Schema:  mustParseGraphQLSchema(t, nil),
+				Query: `
+				mutation {
+					setUserSuspended(user: "` + uid2GQLID + `", suspended: true) {
+						alwaysNil
+					}
+				}
+			`,
+				ExpectedResult: `
+				{
+					"setUserSuspended": {
+						"alwaysNil": null
+					}
+				}
+			`,
+			},
+		})
+		if len(suspended) != 1 || suspended[0] != 2 {
+			t.Errorf("got suspended users %v, want [2]", suspended)
+		}
+		if len(audited) != 1 || audited[0] != "user.suspend user:2" {
+			t.Errorf("got audit log entries %q, want [user.suspend user:2]", audited)
+		}
+	})
+}
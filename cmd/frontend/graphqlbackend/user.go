@@ -167,6 +167,8 @@ func (r *UserResolver) SiteAdmin(ctx context.Context) (bool, error) {
 	return r.user.SiteAdmin, nil
 }
 
+func (r *UserResolver) Suspended() bool { return r.user.SuspendedAt != nil }
+
 func (*schemaResolver) UpdateUser(ctx context.Context, args *struct {
 	User        graphql.ID
 	Username    *string
@@ -0,0 +1,116 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/auth/userpasswd"
+)
+
+func (r *UserResolver) TOTPEnabled(ctx context.Context) (bool, error) {
+	// 🚨 SECURITY: Only the user and admins are allowed to determine if the user is enrolled in
+	// multi-factor authentication.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, r.user.ID); err != nil {
+		return false, err
+	}
+
+	enrollment, err := db.TOTPEnrollments.GetByUserID(ctx, r.user.ID)
+	if err == db.ErrTOTPEnrollmentNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return enrollment.Enabled(), nil
+}
+
+type totpEnrollmentResolver struct {
+	secrets *userpasswd.TOTPEnrollmentSecrets
+}
+
+func (r *totpEnrollmentResolver) Secret() string          { return r.secrets.Secret }
+func (r *totpEnrollmentResolver) URL() string             { return r.secrets.URL }
+func (r *totpEnrollmentResolver) RecoveryCodes() []string { return r.secrets.RecoveryCodes }
+
+func (*schemaResolver) EnrollTOTP(ctx context.Context) (*totpEnrollmentResolver, error) {
+	if !userpasswd.MFAEnabled() {
+		return nil, errors.New("multi-factor authentication requires the builtin auth provider")
+	}
+
+	// 🚨 SECURITY: A user can only enroll themselves.
+	user, err := db.Users.GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("no authenticated user")
+	}
+
+	secrets, err := userpasswd.EnrollTOTP(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &totpEnrollmentResolver{secrets: secrets}, nil
+}
+
+func (*schemaResolver) ConfirmTOTPEnrollment(ctx context.Context, args *struct {
+	Code string
+}) (*EmptyResponse, error) {
+	// 🚨 SECURITY: A user can only confirm their own enrollment.
+	user, err := db.Users.GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("no authenticated user")
+	}
+
+	if err := userpasswd.ConfirmTOTPEnrollment(ctx, user.ID, args.Code); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
+
+func (*schemaResolver) ResetTOTP(ctx context.Context, args *struct {
+	User         graphql.ID
+	Code         *string
+	RecoveryCode *string
+	Password     *string
+}) (*EmptyResponse, error) {
+	userID, err := UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+
+	// 🚨 SECURITY: Only the user and site admins can reset the user's multi-factor authentication.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	// 🚨 SECURITY: Users who aren't site admins must prove that they are the user (and not just
+	// someone with their session) with a code, a recovery code or their password.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		ok, err := userpasswd.CheckTOTPResetCredentials(ctx, userID, strOrEmpty(args.Code), strOrEmpty(args.RecoveryCode), strOrEmpty(args.Password))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errors.New("a valid code, recovery code or password is required to reset multi-factor authentication")
+		}
+	}
+
+	if err := db.TOTPEnrollments.Delete(ctx, userID); err != nil {
+		return nil, err
+	}
+	backend.LogAuditEvent(ctx, backend.AuditUserMFAReset, fmt.Sprintf("user:%d", userID), nil)
+	return &EmptyResponse{}, nil
+}
+
+func strOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
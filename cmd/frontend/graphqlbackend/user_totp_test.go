@@ -0,0 +1,188 @@
+package graphqlbackend
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/totp"
+)
+
+func TestUser_TOTPEnabled(t *testing.T) {
+	resetMocks()
+	db.Mocks.Users.GetByUsername = func(ctx context.Context, username string) (*types.User, error) {
+		return &types.User{ID: 1, Username: username}, nil
+	}
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: 1}, nil
+	}
+	db.Mocks.TOTPEnrollments.GetByUserID = func(userID int32) (*db.TOTPEnrollment, error) {
+		now := time.Now()
+		return &db.TOTPEnrollment{UserID: userID, EnabledAt: &now}, nil
+	}
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+			Schema:  mustParseGraphQLSchema(t, nil),
+			Query: `
+				{
+					user(username: "alice") {
+						totpEnabled
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"user": {
+						"totpEnabled": true
+					}
+				}
+			`,
+		},
+	})
+}
+
+func TestResetTOTP(t *testing.T) {
+	const (
+		uid1GQLID = "VXNlcjox"
+		uid2GQLID = "VXNlcjoy"
+	)
+
+	t.Run("other user", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+			return &types.User{ID: 1}, nil
+		}
+		db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+			return &types.User{ID: id, Username: "bob"}, nil
+		}
+		db.Mocks.TOTPEnrollments.Delete = func(userID int32) error {
+			t.Error("Delete called")
+			return nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		_, err := (&schemaResolver{}).ResetTOTP(ctx, &struct {
+			User         graphql.ID
+			Code         *string
+			RecoveryCode *string
+			Password     *string
+		}{User: uid2GQLID})
+		if _, ok := err.(*backend.InsufficientAuthorizationError); !ok {
+			t.Errorf("got err %v, want *backend.InsufficientAuthorizationError", err)
+		}
+	})
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockEnrollment := func() {
+		db.Mocks.TOTPEnrollments.GetByUserID = func(userID int32) (*db.TOTPEnrollment, error) {
+			now := time.Now()
+			return &db.TOTPEnrollment{UserID: userID, Secret: secret, EnabledAt: &now}, nil
+		}
+		db.Mocks.TOTPEnrollments.UseCounter = func(userID int32, counter int64) (bool, error) {
+			return true, nil
+		}
+	}
+
+	t.Run("same user without credentials", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+			return &types.User{ID: 1}, nil
+		}
+		mockEnrollment()
+		db.Mocks.TOTPEnrollments.Delete = func(userID int32) error {
+			t.Error("Delete called")
+			return nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		invalid := "000000"
+		if _, err := (&schemaResolver{}).ResetTOTP(ctx, &struct {
+			User         graphql.ID
+			Code         *string
+			RecoveryCode *string
+			Password     *string
+		}{User: uid1GQLID}); err == nil {
+			t.Error("got nil error without a code")
+		}
+		if _, err := (&schemaResolver{}).ResetTOTP(ctx, &struct {
+			User         graphql.ID
+			Code         *string
+			RecoveryCode *string
+			Password     *string
+		}{User: uid1GQLID, Code: &invalid}); err == nil {
+			t.Error("got nil error with an invalid code")
+		}
+	})
+
+	tests := []struct {
+		name      string
+		siteAdmin bool
+		args      string
+	}{
+		{name: "same user with code", args: `, code: "CODE"`},
+		{name: "site admin without credentials", siteAdmin: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+				return &types.User{ID: 1, SiteAdmin: test.siteAdmin}, nil
+			}
+			mockEnrollment()
+			var deleted []int32
+			db.Mocks.TOTPEnrollments.Delete = func(userID int32) error {
+				deleted = append(deleted, userID)
+				return nil
+			}
+			var audited []string
+			db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+				audited = append(audited, e.Action+" "+e.Subject)
+				return nil
+			}
+
+			code, err := totp.Code(secret, totp.Counter(time.Now()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gqltesting.RunTests(t, []*gqltesting.Test{
+				{
+					Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+					Schema:  mustParseGraphQLSchema(t, nil),
+					Query: `
+					mutation {
+						resetTOTP(user: "` + uid1GQLID + `"` + strings.Replace(test.args, "CODE", code, 1) + `) {
+							alwaysNil
+						}
+					}
+				`,
+					ExpectedResult: `
+					{
+						"resetTOTP": {
+							"alwaysNil": null
+						}
+					}
+				`,
+				},
+			})
+			if len(deleted) != 1 || deleted[0] != 1 {
+				t.Errorf("got deleted enrollments of users %v, want [1]", deleted)
+			}
+			if len(audited) != 1 || audited[0] != "user.mfa.reset user:1" {
+				t.Errorf("got audit log entries %q, want [user.mfa.reset user:1]", audited)
+			}
+		})
+	}
+}
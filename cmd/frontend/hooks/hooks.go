@@ -10,3 +10,12 @@ var PreAuthMiddleware func(http.Handler) http.Handler
 // AfterDBInit is called after the database is initialized, and can be used to
 // e.g. launch background services that depend on the database.
 var AfterDBInit func()
+
+// GitHubWebhook and GitLabWebhook, if set, handle the webhook events sent by
+// GitHub and GitLab to the /.api/webhooks/github and /.api/webhooks/gitlab
+// endpoints. They must be set before the HTTP handlers are created, e.g. in
+// AfterDBInit.
+var (
+	GitHubWebhook http.Handler
+	GitLabWebhook http.Handler
+)
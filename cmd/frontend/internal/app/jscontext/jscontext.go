@@ -30,6 +30,7 @@ var BillingPublishableKey string
 
 type authProviderInfo struct {
 	IsBuiltin         bool   `json:"isBuiltin"`
+	ServiceType       string `json:"serviceType"`
 	DisplayName       string `json:"displayName"`
 	AuthenticationURL string `json:"authenticationURL"`
 }
@@ -75,6 +76,8 @@ type JSContext struct {
 
 	ResetPasswordEnabled bool `json:"resetPasswordEnabled"`
 
+	MFAEnabled bool `json:"mfaEnabled"`
+
 	AuthProviders []authProviderInfo `json:"authProviders"`
 
 	Branding *schema.Branding `json:"branding"`
@@ -121,6 +124,7 @@ func NewJSContextFromRequest(req *http.Request) JSContext {
 		if info != nil {
 			authProviders = append(authProviders, authProviderInfo{
 				IsBuiltin:         p.Config().Builtin != nil,
+				ServiceType:       conf.AuthProviderType(p.Config()),
 				DisplayName:       info.DisplayName,
 				AuthenticationURL: info.AuthenticationURL,
 			})
@@ -169,6 +173,8 @@ func NewJSContextFromRequest(req *http.Request) JSContext {
 
 		ResetPasswordEnabled: userpasswd.ResetPasswordEnabled(),
 
+		MFAEnabled: userpasswd.MFAEnabled(),
+
 		AllowSignup: conf.AuthAllowSignup(),
 
 		AuthProviders: authProviders,
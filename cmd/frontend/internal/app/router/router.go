@@ -21,6 +21,7 @@ const (
 	Logout = "logout"
 
 	SignIn            = "sign-in"
+	SignInMFA         = "sign-in.mfa"
 	SignOut           = "sign-out"
 	SignUp            = "sign-up"
 	SiteInit          = "site-init"
@@ -66,6 +67,7 @@ func newRouter() *mux.Router {
 	base.Path("/-/site-init").Methods("POST").Name(SiteInit)
 	base.Path("/-/verify-email").Methods("GET").Name(VerifyEmail)
 	base.Path("/-/sign-in").Methods("POST").Name(SignIn)
+	base.Path("/-/sign-in/mfa").Methods("POST").Name(SignInMFA)
 	base.Path("/-/sign-out").Methods("GET").Name(SignOut)
 	base.Path("/-/reset-password-init").Methods("POST").Name(ResetPasswordInit)
 	base.Path("/-/reset-password-code").Methods("POST").Name(ResetPasswordCode)
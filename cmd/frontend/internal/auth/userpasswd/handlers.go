@@ -128,15 +128,22 @@ func handleSignUp(w http.ResponseWriter, r *http.Request, failIfNewUserIsNotInit
 		}
 	}
 
-	// Write the session cookie
-	if session.SetActor(w, r, actor, 0); err != nil {
-		httpLogAndError(w, "Could not create new user session", http.StatusInternalServerError)
-	}
-
 	// Track user data
 	if r.UserAgent() != "Sourcegraph e2etest-bot" {
 		go tracking.SyncUser(creds.Email, hubspotutil.SignupEventID, nil)
 	}
+
+	// 🚨 SECURITY: New users who must use multi-factor authentication (such as the initial site
+	// admin, if it is required for site admins) aren't signed in until they enroll and enter a code
+	// (with HandleSignInMFA).
+	if startMFA(w, r, usr) {
+		return
+	}
+
+	// Write the session cookie
+	if session.SetActor(w, r, actor, 0); err != nil {
+		httpLogAndError(w, "Could not create new user session", http.StatusInternalServerError)
+	}
 }
 
 func getByEmailOrUsername(ctx context.Context, emailOrUsername string) (*types.User, error) {
@@ -147,7 +154,8 @@ func getByEmailOrUsername(ctx context.Context, emailOrUsername string) (*types.U
 }
 
 // HandleSignIn accepts a POST containing username-password credentials and authenticates the
-// current session if the credentials are valid.
+// current session if the credentials are valid. If the user must use multi-factor authentication,
+// the session is authenticated by HandleSignInMFA instead.
 func HandleSignIn(w http.ResponseWriter, r *http.Request) {
 	if handleEnabledCheck(w) {
 		return
@@ -181,6 +189,17 @@ func HandleSignIn(w http.ResponseWriter, r *http.Request) {
 		httpLogAndError(w, "Authentication failed", http.StatusUnauthorized)
 		return
 	}
+	// 🚨 SECURITY: Suspended users can't sign in. This is checked after the password so that it
+	// doesn't reveal which users are suspended.
+	if usr.SuspendedAt != nil {
+		httpLogAndError(w, "Your user account is suspended. Ask a site admin for help.", http.StatusForbidden, "userID", usr.ID)
+		return
+	}
+	// 🚨 SECURITY: Users who must use multi-factor authentication aren't signed in until they enter
+	// a code (with HandleSignInMFA).
+	if startMFA(w, r, usr) {
+		return
+	}
 	actor := &actor.Actor{UID: usr.ID}
 
 	// Write the session cookie
@@ -0,0 +1,342 @@
+package userpasswd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/session"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/totp"
+)
+
+// Users who sign in with a username and password can be required to also enter a code from an
+// authenticator app (multi-factor authentication with time-based one-time passwords, or TOTP).
+//
+// When such a user enters a valid password, HandleSignIn does not sign them in. Instead, it stores
+// the pending sign-in in the session and responds with an mfaResponse that tells the client to ask
+// for a code (or, if the user must enroll first, shows them the new enrollment's secret and
+// recovery codes). The client then submits the code to HandleSignInMFA, which signs the user in.
+// HandleSignUp and HandleSiteInit do the same for new users, who must always enroll first.
+
+const (
+	// pendingMFASessionKey is the session data key for the pendingMFA of a sign-in that is
+	// waiting for the user to enter a code.
+	pendingMFASessionKey = "userpasswd.pending_mfa"
+
+	// pendingMFAExpiry is how long the user has to enter a code after entering their password.
+	pendingMFAExpiry = 5 * time.Minute
+
+	// maxMFAAttempts is the number of invalid codes that the user can enter before they must enter
+	// their password again.
+	maxMFAAttempts = 5
+
+	// totpIssuer is the name shown for the account in authenticator apps.
+	totpIssuer = "Sourcegraph"
+)
+
+// pendingMFA is a sign-in for which the user entered a valid password but has not yet entered a
+// valid code. It is stored in the (server-side) session data.
+type pendingMFA struct {
+	UserID   int32
+	Expiry   time.Time
+	Attempts int
+}
+
+// mfaResponse is the response to a sign-in request whose user must enter a code to sign in.
+type mfaResponse struct {
+	// MFA is "verify" if the user must enter a code, or "enroll" if the user must add the TOTP
+	// secret to their authenticator app and then enter a code.
+	MFA string `json:"mfa"`
+
+	*TOTPEnrollmentSecrets
+}
+
+// TOTPEnrollmentSecrets are the secrets of a new TOTP enrollment, which are shown to the user
+// once: the TOTP secret (to add to their authenticator app) and the recovery codes (to sign in
+// without it).
+type TOTPEnrollmentSecrets struct {
+	Secret        string   `json:"totpSecret"`
+	URL           string   `json:"totpURL"` // otpauth:// URL, for QR codes
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MFAEnabled reports whether users can enroll in multi-factor authentication (per site config).
+func MFAEnabled() bool {
+	pc, multiple := getProviderConfig()
+	return pc != nil && !multiple
+}
+
+// mfaRequired reports whether the user must use multi-factor authentication to sign in with their
+// password (per site config).
+func mfaRequired(usr *types.User) bool {
+	pc, _ := getProviderConfig()
+	if pc == nil {
+		return false
+	}
+	switch pc.RequireMFA {
+	case "all":
+		return true
+	case "siteAdmins":
+		return usr.SiteAdmin
+	default:
+		return false
+	}
+}
+
+// EnrollTOTP creates a pending TOTP enrollment for the user, which takes effect when the user
+// confirms it with ConfirmTOTPEnrollment. If the user is already enrolled, it returns
+// db.ErrTOTPEnrollmentAlreadyEnabled (the existing enrollment must be reset first).
+//
+// 🚨 SECURITY: The caller must ensure that the actor is the user (or has just authenticated as the
+// user with their password).
+func EnrollTOTP(ctx context.Context, usr *types.User) (*TOTPEnrollmentSecrets, error) {
+	if enrollment, err := db.TOTPEnrollments.GetByUserID(ctx, usr.ID); err == nil && enrollment.Enabled() {
+		return nil, db.ErrTOTPEnrollmentAlreadyEnabled
+	} else if err != nil && err != db.ErrTOTPEnrollmentNotFound {
+		return nil, err
+	}
+
+	secret, recoveryCodes, err := db.TOTPEnrollments.CreatePending(ctx, usr.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollmentSecrets{
+		Secret:        secret,
+		URL:           totp.URL(totpIssuer, usr.Username+"@"+globals.ExternalURL().Host, secret),
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// errInvalidTOTPCode is returned when the user enters an invalid (or already used) code.
+var errInvalidTOTPCode = errors.New("invalid code")
+
+// ConfirmTOTPEnrollment enables the user's pending TOTP enrollment if the code is valid for it,
+// which proves that the user added it to their authenticator app.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is the user (or has just authenticated as the
+// user with their password).
+func ConfirmTOTPEnrollment(ctx context.Context, userID int32, code string) error {
+	enrollment, err := db.TOTPEnrollments.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if enrollment.Enabled() {
+		return db.ErrTOTPEnrollmentAlreadyEnabled
+	}
+	if ok, err := checkTOTPCode(ctx, enrollment, code); err != nil {
+		return err
+	} else if !ok {
+		return errInvalidTOTPCode
+	}
+	if err := db.TOTPEnrollments.Enable(ctx, userID); err != nil {
+		return err
+	}
+	backend.LogAuditEvent(actor.WithActor(ctx, &actor.Actor{UID: userID}), backend.AuditUserMFAEnable, fmt.Sprintf("user:%d", userID), nil)
+	return nil
+}
+
+// CheckTOTPResetCredentials reports whether the user may reset their own TOTP enrollment with the
+// given credentials: a code from their authenticator app, a recovery code or their password
+// (whichever is non-empty). A pending enrollment is not required to sign in, so it can be reset
+// without any.
+//
+// 🚨 SECURITY: This prevents someone who has taken over a user's session from turning off the
+// user's multi-factor authentication. Site admins can reset any user's enrollment without
+// credentials, so the caller need not call this for them.
+func CheckTOTPResetCredentials(ctx context.Context, userID int32, code, recoveryCode, password string) (bool, error) {
+	enrollment, err := db.TOTPEnrollments.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case !enrollment.Enabled():
+		return true, nil
+	case code != "":
+		return checkTOTPCode(ctx, enrollment, code)
+	case recoveryCode != "":
+		ok, err := db.TOTPEnrollments.UseRecoveryCode(ctx, userID, recoveryCode)
+		if ok {
+			backend.LogAuditEvent(ctx, backend.AuditUserMFARecoveryCode, fmt.Sprintf("user:%d", userID), nil)
+		}
+		return ok, err
+	case password != "":
+		return db.Users.IsPassword(ctx, userID, password)
+	default:
+		return false, nil
+	}
+}
+
+// checkTOTPCode reports whether the code is valid for the enrollment and has not been used before.
+func checkTOTPCode(ctx context.Context, enrollment *db.TOTPEnrollment, code string) (bool, error) {
+	counter, ok, err := totp.Validate(enrollment.Secret, code, time.Now())
+	if err != nil || !ok {
+		return false, err
+	}
+	return db.TOTPEnrollments.UseCounter(ctx, enrollment.UserID, counter)
+}
+
+// startMFA is called by HandleSignIn after the user entered a valid password, and by the sign-up
+// handlers after they created the user. If the user must enter a code to sign in, it stores the
+// pending sign-in in the session, writes an mfaResponse and returns true. Otherwise it returns
+// false, and the caller should sign the user in.
+//
+// 🚨 SECURITY: The caller must ensure that the user entered a valid password (or just chose it).
+func startMFA(w http.ResponseWriter, r *http.Request, usr *types.User) (handled bool) {
+	ctx := r.Context()
+	enrollment, err := db.TOTPEnrollments.GetByUserID(ctx, usr.ID)
+	if err != nil && err != db.ErrTOTPEnrollmentNotFound {
+		httpLogAndError(w, "Error checking multi-factor authentication", http.StatusInternalServerError, "userID", usr.ID, "err", err)
+		return true
+	}
+	enabled := enrollment != nil && enrollment.Enabled()
+	if !enabled && !mfaRequired(usr) {
+		return false
+	}
+
+	resp := mfaResponse{MFA: "verify"}
+	if !enabled {
+		// The user must enroll before signing in. Because they just entered their password, they
+		// are permitted to do so.
+		resp.MFA = "enroll"
+		resp.TOTPEnrollmentSecrets, err = EnrollTOTP(ctx, usr)
+		if err != nil {
+			httpLogAndError(w, "Error enrolling in multi-factor authentication", http.StatusInternalServerError, "userID", usr.ID, "err", err)
+			return true
+		}
+	}
+
+	if err := session.SetData(w, r, pendingMFASessionKey, &pendingMFA{UserID: usr.ID, Expiry: time.Now().Add(pendingMFAExpiry)}); err != nil {
+		httpLogAndError(w, "Could not create new user session", http.StatusInternalServerError, "err", err)
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		httpLogAndError(w, "Error encoding response", http.StatusInternalServerError, "err", err)
+	}
+	return true
+}
+
+type mfaCredentials struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// HandleSignInMFA accepts a code from an authenticator app (or a recovery code) for a sign-in
+// whose user entered a valid password and must use multi-factor authentication. If the code is
+// valid, the user is signed in.
+//
+// 🚨 SECURITY: Any change to this function could allow users to sign in without a valid code. Be
+// careful.
+func HandleSignInMFA(w http.ResponseWriter, r *http.Request) {
+	if handleEnabledCheck(w) {
+		return
+	}
+
+	ctx := r.Context()
+
+	if r.Method != "POST" {
+		http.Error(w, fmt.Sprintf("Unsupported method %s", r.Method), http.StatusBadRequest)
+		return
+	}
+	var creds mfaCredentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, "Could not decode request body", http.StatusBadRequest)
+		return
+	}
+
+	var pending *pendingMFA
+	if err := session.GetData(r, pendingMFASessionKey, &pending); err != nil {
+		httpLogAndError(w, "Could not read user session", http.StatusInternalServerError, "err", err)
+		return
+	}
+	if pending == nil || time.Now().After(pending.Expiry) {
+		httpLogAndError(w, "Sign-in expired. Enter your password again.", http.StatusUnauthorized)
+		return
+	}
+
+	usr, err := db.Users.GetByID(ctx, pending.UserID)
+	if err != nil {
+		httpLogAndError(w, "Authentication failed", http.StatusUnauthorized, "err", err)
+		return
+	}
+	// 🚨 SECURITY: The user might have been suspended since they entered their password.
+	if usr.SuspendedAt != nil {
+		httpLogAndError(w, "Your user account is suspended. Ask a site admin for help.", http.StatusForbidden, "userID", usr.ID)
+		return
+	}
+
+	// 🚨 SECURITY: check code
+	ok, usedRecoveryCode, err := checkMFACredentials(ctx, usr.ID, creds)
+	if err != nil {
+		httpLogAndError(w, "Error checking code", http.StatusInternalServerError, "userID", usr.ID, "err", err)
+		return
+	}
+	if !ok {
+		// 🚨 SECURITY: Limit the number of codes that can be tried for each password entry.
+		pending.Attempts++
+		if pending.Attempts >= maxMFAAttempts {
+			pending = nil
+		}
+		if err := session.SetData(w, r, pendingMFASessionKey, pending); err != nil {
+			httpLogAndError(w, "Could not save user session", http.StatusInternalServerError, "err", err)
+			return
+		}
+		httpLogAndError(w, "Authentication failed", http.StatusUnauthorized, "userID", usr.ID)
+		return
+	}
+
+	ctx = actor.WithActor(ctx, &actor.Actor{UID: usr.ID})
+	if usedRecoveryCode {
+		backend.LogAuditEvent(ctx, backend.AuditUserMFARecoveryCode, fmt.Sprintf("user:%d", usr.ID), nil)
+	}
+
+	if err := session.SetData(w, r, pendingMFASessionKey, nil); err != nil {
+		httpLogAndError(w, "Could not save user session", http.StatusInternalServerError, "err", err)
+		return
+	}
+	if err := session.SetActor(w, r, actor.FromContext(ctx), 0); err != nil {
+		httpLogAndError(w, "Could not create new user session", http.StatusInternalServerError, "err", err)
+		return
+	}
+}
+
+// checkMFACredentials reports whether the code or recovery code is valid for the user's TOTP
+// enrollment. If the enrollment is pending, the code confirms it (and recovery codes are not
+// accepted).
+func checkMFACredentials(ctx context.Context, userID int32, creds mfaCredentials) (ok, usedRecoveryCode bool, err error) {
+	enrollment, err := db.TOTPEnrollments.GetByUserID(ctx, userID)
+	if err == db.ErrTOTPEnrollmentNotFound {
+		// The enrollment was reset since the user entered their password.
+		return false, false, nil
+	} else if err != nil {
+		return false, false, err
+	}
+
+	switch {
+	case !enrollment.Enabled():
+		if creds.RecoveryCode != "" {
+			return false, false, nil
+		}
+		err := ConfirmTOTPEnrollment(ctx, userID, creds.Code)
+		if err == errInvalidTOTPCode {
+			return false, false, nil
+		}
+		return err == nil, false, err
+
+	case creds.RecoveryCode != "":
+		ok, err := db.TOTPEnrollments.UseRecoveryCode(ctx, userID, creds.RecoveryCode)
+		return ok, ok, err
+
+	default:
+		ok, err := checkTOTPCode(ctx, enrollment, creds.Code)
+		return ok, false, err
+	}
+}
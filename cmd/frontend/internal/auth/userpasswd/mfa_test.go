@@ -0,0 +1,299 @@
+package userpasswd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/session"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/totp"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func mockRequireMFA(requireMFA string) {
+	conf.Mock(&conf.Unified{Critical: schema.CriticalConfiguration{AuthProviders: []schema.AuthProviders{
+		{Builtin: &schema.BuiltinAuthProvider{Type: "builtin", RequireMFA: requireMFA}},
+	}}})
+}
+
+func TestMFARequired(t *testing.T) {
+	defer conf.Mock(nil)
+	tests := []struct {
+		requireMFA string
+		siteAdmin  bool
+		want       bool
+	}{
+		{requireMFA: "", siteAdmin: true, want: false},
+		{requireMFA: "none", siteAdmin: true, want: false},
+		{requireMFA: "siteAdmins", siteAdmin: false, want: false},
+		{requireMFA: "siteAdmins", siteAdmin: true, want: true},
+		{requireMFA: "all", siteAdmin: false, want: true},
+	}
+	for _, test := range tests {
+		mockRequireMFA(test.requireMFA)
+		if got := mfaRequired(&types.User{SiteAdmin: test.siteAdmin}); got != test.want {
+			t.Errorf("requireMFA %q, siteAdmin %v: got %v, want %v", test.requireMFA, test.siteAdmin, got, test.want)
+		}
+	}
+}
+
+// 🚨 SECURITY: This tests that enrolling again can't replace an enabled enrollment (which would let
+// someone with the user's session take over their second factor).
+func TestEnrollTOTP_alreadyEnabled(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+	db.Mocks.TOTPEnrollments.GetByUserID = func(userID int32) (*db.TOTPEnrollment, error) {
+		now := time.Now()
+		return &db.TOTPEnrollment{UserID: userID, EnabledAt: &now}, nil
+	}
+	db.Mocks.TOTPEnrollments.CreatePending = func(userID int32) (string, []string, error) {
+		t.Error("CreatePending called")
+		return "", nil, nil
+	}
+
+	if _, err := EnrollTOTP(context.Background(), &types.User{ID: 1}); err != db.ErrTOTPEnrollmentAlreadyEnabled {
+		t.Errorf("got err %v, want %v", err, db.ErrTOTPEnrollmentAlreadyEnabled)
+	}
+}
+
+// 🚨 SECURITY: This tests that users who must use multi-factor authentication are only signed in
+// with a valid code.
+func TestSignInMFA(t *testing.T) {
+	cleanup := session.ResetMockSessionStore(t)
+	defer cleanup()
+	defer func() { db.Mocks = db.MockStores{} }()
+	defer conf.Mock(nil)
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validCode := func() string {
+		code, err := totp.Code(secret, totp.Counter(time.Now()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	usr := &types.User{ID: 1, Username: "alice"}
+	var enrollment *db.TOTPEnrollment
+	setup := func(t *testing.T, requireMFA string, e *db.TOTPEnrollment) {
+		db.Mocks = db.MockStores{}
+		mockRequireMFA(requireMFA)
+		enrollment = e
+		db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+			if id != usr.ID {
+				return nil, db.NewUserNotFoundError(id)
+			}
+			return usr, nil
+		}
+		db.Mocks.TOTPEnrollments.GetByUserID = func(userID int32) (*db.TOTPEnrollment, error) {
+			if enrollment == nil || userID != usr.ID {
+				return nil, db.ErrTOTPEnrollmentNotFound
+			}
+			return enrollment, nil
+		}
+		db.Mocks.TOTPEnrollments.CreatePending = func(userID int32) (string, []string, error) {
+			enrollment = &db.TOTPEnrollment{UserID: userID, Secret: secret}
+			return secret, []string{"aaaaa-bbbbb"}, nil
+		}
+		db.Mocks.TOTPEnrollments.Enable = func(userID int32) error {
+			now := time.Now()
+			enrollment.EnabledAt = &now
+			return nil
+		}
+		db.Mocks.TOTPEnrollments.UseCounter = func(userID int32, counter int64) (bool, error) {
+			if counter <= enrollment.LastUsedCounter {
+				return false, nil
+			}
+			enrollment.LastUsedCounter = counter
+			return true, nil
+		}
+		db.Mocks.TOTPEnrollments.UseRecoveryCode = func(userID int32, recoveryCode string) (bool, error) {
+			return recoveryCode == "aaaaa-bbbbb", nil
+		}
+		db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error { return nil }
+	}
+	enabled := func() *db.TOTPEnrollment {
+		now := time.Now()
+		return &db.TOTPEnrollment{UserID: usr.ID, Secret: secret, EnabledAt: &now}
+	}
+
+	// signIn simulates a sign-in with a valid password and returns the session cookie.
+	signIn := func(t *testing.T) (resp *mfaResponse, cookies []*http.Cookie) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		if !startMFA(rr, httptest.NewRequest("POST", "/-/sign-in", nil), usr) {
+			return nil, rr.Result().Cookies()
+		}
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body %q)", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp, rr.Result().Cookies()
+	}
+	submit := func(t *testing.T, cookies []*http.Cookie, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/-/sign-in/mfa", strings.NewReader(body))
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rr := httptest.NewRecorder()
+		HandleSignInMFA(rr, req)
+		return rr
+	}
+	// signedInUserID returns the UID of the actor authenticated by the session cookie.
+	signedInUserID := func(cookies []*http.Cookie) int32 {
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		var uid int32
+		session.CookieMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			uid = actor.FromContext(r.Context()).UID
+		})).ServeHTTP(httptest.NewRecorder(), req)
+		return uid
+	}
+
+	t.Run("not enrolled and not required", func(t *testing.T) {
+		setup(t, "siteAdmins", nil)
+		if resp, _ := signIn(t); resp != nil {
+			t.Errorf("got MFA response %+v, want none", resp)
+		}
+	})
+
+	t.Run("valid code", func(t *testing.T) {
+		setup(t, "none", enabled())
+		resp, cookies := signIn(t)
+		if resp == nil || resp.MFA != "verify" || resp.TOTPEnrollmentSecrets != nil {
+			t.Fatalf("got MFA response %+v, want verify", resp)
+		}
+		if uid := signedInUserID(cookies); uid != 0 {
+			t.Fatalf("signed in as %d before entering code", uid)
+		}
+		if rr := submit(t, cookies, `{"code":"000000"}`); rr.Code != http.StatusUnauthorized && validCode() != "000000" {
+			t.Errorf("invalid code: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+		}
+		rr := submit(t, cookies, fmt.Sprintf(`{"code":%q}`, validCode()))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body %q)", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		cookies = append(rr.Result().Cookies(), cookies...)
+		if uid := signedInUserID(cookies); uid != usr.ID {
+			t.Errorf("got signed in user %d, want %d", uid, usr.ID)
+		}
+
+		// The code and the pending sign-in can't be reused.
+		if rr := submit(t, cookies, fmt.Sprintf(`{"code":%q}`, validCode())); rr.Code != http.StatusUnauthorized {
+			t.Errorf("reused code: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("recovery code", func(t *testing.T) {
+		setup(t, "none", enabled())
+		_, cookies := signIn(t)
+		if rr := submit(t, cookies, `{"recoveryCode":"wrong"}`); rr.Code != http.StatusUnauthorized {
+			t.Errorf("invalid recovery code: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+		}
+		if rr := submit(t, cookies, `{"recoveryCode":"aaaaa-bbbbb"}`); rr.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d (body %q)", rr.Code, http.StatusOK, rr.Body.String())
+		}
+	})
+
+	t.Run("too many attempts", func(t *testing.T) {
+		setup(t, "none", enabled())
+		_, cookies := signIn(t)
+		for i := 0; i < maxMFAAttempts; i++ {
+			if rr := submit(t, cookies, `{"code":"wrong"}`); rr.Code != http.StatusUnauthorized {
+				t.Fatalf("got status %d, want %d", rr.Code, http.StatusUnauthorized)
+			}
+		}
+		if rr := submit(t, cookies, fmt.Sprintf(`{"code":%q}`, validCode())); rr.Code != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("no pending sign-in", func(t *testing.T) {
+		setup(t, "none", enabled())
+		if rr := submit(t, nil, fmt.Sprintf(`{"code":%q}`, validCode())); rr.Code != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("required enrollment", func(t *testing.T) {
+		setup(t, "all", nil)
+		resp, cookies := signIn(t)
+		if resp == nil || resp.MFA != "enroll" || resp.TOTPEnrollmentSecrets == nil || resp.Secret != secret || len(resp.RecoveryCodes) != 1 {
+			t.Fatalf("got MFA response %+v, want enroll", resp)
+		}
+		// Recovery codes can't be used to confirm an enrollment.
+		if rr := submit(t, cookies, `{"recoveryCode":"aaaaa-bbbbb"}`); rr.Code != http.StatusUnauthorized {
+			t.Errorf("recovery code: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+		}
+		rr := submit(t, cookies, fmt.Sprintf(`{"code":%q}`, validCode()))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body %q)", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if !enrollment.Enabled() {
+			t.Error("enrollment was not enabled")
+		}
+	})
+
+	t.Run("sign-up", func(t *testing.T) {
+		setup(t, "siteAdmins", nil)
+		db.Mocks.Users.Create = func(ctx context.Context, info db.NewUser) (*types.User, error) {
+			if !info.FailIfNotInitialUser {
+				t.Error("want FailIfNotInitialUser")
+			}
+			return &types.User{ID: usr.ID, Username: info.Username, SiteAdmin: true}, nil
+		}
+		req := httptest.NewRequest("POST", "/-/site-init", strings.NewReader(`{"email":"alice@example.com","username":"alice","password":"p"}`))
+		req.Header.Set("User-Agent", "Sourcegraph e2etest-bot")
+		rr := httptest.NewRecorder()
+		HandleSiteInit(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body %q)", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		var resp mfaResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.MFA != "enroll" || resp.TOTPEnrollmentSecrets == nil {
+			t.Fatalf("got MFA response %+v, want enroll", resp)
+		}
+		cookies := rr.Result().Cookies()
+		if uid := signedInUserID(cookies); uid != 0 {
+			t.Fatalf("signed in as %d before enrolling", uid)
+		}
+		rr = submit(t, cookies, fmt.Sprintf(`{"code":%q}`, validCode()))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body %q)", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if uid := signedInUserID(append(rr.Result().Cookies(), cookies...)); uid != usr.ID {
+			t.Errorf("got signed in user %d, want %d", uid, usr.ID)
+		}
+	})
+
+	t.Run("suspended since entering password", func(t *testing.T) {
+		setup(t, "none", enabled())
+		_, cookies := signIn(t)
+		suspendedAt := time.Now()
+		db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+			return &types.User{ID: id, SuspendedAt: &suspendedAt}, nil
+		}
+		if rr := submit(t, cookies, fmt.Sprintf(`{"code":%q}`, validCode())); rr.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusForbidden)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func TestServeAuditLogExport(t *testing.T) {
+	c := newTest()
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	t.Run("non site admin", func(t *testing.T) {
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 2}, nil
+		}
+		resp, err := c.Get("/audit-log")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusForbidden)
+		}
+	})
+
+	t.Run("site admin", func(t *testing.T) {
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: true}, nil
+		}
+		createdAt := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+		var calls int
+		db.Mocks.AuditLog.List = func(opt db.AuditLogListOptions) ([]*db.AuditLogEntry, error) {
+			calls++
+			if !opt.OldestFirst || opt.Since == nil || !opt.Since.Equal(createdAt) {
+				t.Errorf("got unexpected options %+v", opt)
+			}
+			if want := int64(5); opt.AfterID != want {
+				t.Errorf("got after ID %d, want %d", opt.AfterID, want)
+			}
+			return []*db.AuditLogEntry{
+				{ID: 6, ActorUserID: 1, Action: "site_config.update", Subject: "site", Data: json.RawMessage(`{}`), CreatedAt: createdAt},
+				{ID: 7, Action: "access_token.delete", Data: json.RawMessage(`{"byToken":true}`), CreatedAt: createdAt},
+			}, nil
+		}
+		resp, err := c.GetOK("/audit-log?since=2019-10-01T00:00:00Z&after=5")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Errorf("got content type %q", ct)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Join([]string{
+			`{"id":6,"actorUserID":1,"action":"site_config.update","subject":"site","data":{},"createdAt":"2019-10-01T00:00:00Z"}`,
+			`{"id":7,"action":"access_token.delete","data":{"byToken":true},"createdAt":"2019-10-01T00:00:00Z"}`,
+		}, "\n") + "\n"
+		if string(body) != want {
+			t.Errorf("got body\n%s\nwant\n%s", body, want)
+		}
+		if calls != 1 {
+			t.Errorf("got %d calls to List, want 1", calls)
+		}
+	})
+}
@@ -1,7 +1,10 @@
 package httpapi
 
 import (
+	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
@@ -32,7 +35,14 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 		if headerValue := r.Header.Get("Authorization"); headerValue != "" && token == "" {
 			// Handle Authorization header
 			var err error
-			token, sudoUser, err = authz.ParseAuthorizationHeader(headerValue)
+			if bearer := strings.TrimPrefix(headerValue, "Bearer "); bearer != headerValue && isSCIMRequest(r) {
+				// SCIM clients only support the "Bearer" scheme. Only accept it for the SCIM API,
+				// so that other Authorization headers (e.g., from an authentication proxy) are
+				// ignored elsewhere.
+				token = strings.TrimSpace(bearer)
+			} else {
+				token, sudoUser, err = authz.ParseAuthorizationHeader(headerValue)
+			}
 			if err != nil {
 				if authz.IsUnrecognizedScheme(err) {
 					// Ignore Authorization headers that we don't handle.
@@ -61,18 +71,27 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 			//
 			// 🚨 SECURITY: It's important we check for the correct scopes to know what this token
 			// is allowed to do.
-			var requiredScope string
-			if sudoUser == "" {
-				requiredScope = authz.ScopeUserAll
-			} else {
-				requiredScope = authz.ScopeSiteAdminSudo
+			accessToken, err := db.AccessTokens.LookupToken(r.Context(), token)
+			if err == nil {
+				err = checkAccessTokenScopes(accessToken, sudoUser)
 			}
-			subjectUserID, err := db.AccessTokens.Lookup(r.Context(), token, requiredScope)
 			if err != nil {
 				log15.Error("Invalid access token.", "token", token, "err", err)
 				http.Error(w, "Invalid access token.", http.StatusUnauthorized)
 				return
 			}
+			subjectUserID := accessToken.SubjectUserID
+
+			restrictions, err := tokenRestrictions(accessToken)
+			if err != nil {
+				log15.Error("Invalid access token repository patterns.", "token", token, "err", err)
+				http.Error(w, "Invalid access token.", http.StatusUnauthorized)
+				return
+			}
+			if !checkAccessTokenRequest(restrictions, r) {
+				http.Error(w, "The access token's scopes do not allow this request.", http.StatusForbidden)
+				return
+			}
 
 			// Determine the actor's user ID.
 			var actorUserID int32
@@ -101,13 +120,85 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 					http.Error(w, message, http.StatusForbidden)
 					return
 				}
+				if user.SuspendedAt != nil {
+					http.Error(w, "Unable to sudo to a suspended user.", http.StatusForbidden)
+					return
+				}
 				actorUserID = user.ID
 				log15.Debug("HTTP request used sudo token.", "requestURI", r.URL.RequestURI(), "tokenSubjectUserID", subjectUserID, "actorUserID", actorUserID, "actorUsername", user.Username)
+
+				// The token's subject is the one impersonating the sudo user.
+				backend.LogAuditEvent(actor.WithActor(r.Context(), &actor.Actor{UID: subjectUserID}), backend.AuditAccessTokenSudo, fmt.Sprintf("user:%d", actorUserID), map[string]interface{}{
+					"accessTokenID": accessToken.ID,
+					"username":      user.Username,
+					"method":        r.Method,
+					"requestURI":    r.URL.RequestURI(),
+					"remoteAddr":    r.RemoteAddr,
+				})
 			}
 
-			r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: actorUserID}))
+			ctx := authz.WithTokenRestrictions(r.Context(), restrictions)
+			r = r.WithContext(actor.WithActor(ctx, &actor.Actor{UID: actorUserID}))
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// checkAccessTokenScopes returns an error if the access token's scopes don't allow authenticating,
+// as the sudo user if any. The "site-admin:sudo" scope implies the "user:all" scope.
+func checkAccessTokenScopes(t *db.AccessToken, sudoUser string) error {
+	if sudoUser != "" {
+		if !authz.HasScope(t.Scopes, authz.ScopeSiteAdminSudo) {
+			return fmt.Errorf("access token is missing scope %q", authz.ScopeSiteAdminSudo)
+		}
+		return nil
+	}
+	for _, scope := range authz.UserScopes {
+		if authz.HasScope(t.Scopes, scope) {
+			return nil
+		}
+	}
+	return fmt.Errorf("access token has none of the scopes %q", authz.UserScopes)
+}
+
+// checkAccessTokenRequest reports whether an access token with the restrictions may be used for
+// the request.
+//
+// 🚨 SECURITY: Tokens with the "scim" scope may only be used for the SCIM API. Other tokens without
+// the "user:all" scope may only be used for the GraphQL API, where their scopes are enforced on
+// each field, and, if read-only, for GET requests.
+func checkAccessTokenRequest(restrictions *authz.TokenRestrictions, r *http.Request) bool {
+	switch {
+	case restrictions.HasScope(authz.ScopeSCIM):
+		return isSCIMRequest(r)
+	case restrictions.HasScope(authz.ScopeUserAll), isGraphQLRequest(r):
+		return true
+	case restrictions.HasScope(authz.ScopeUserRead):
+		return r.Method == "GET" || r.Method == "HEAD"
+	}
+	return false
+}
+
+// tokenRestrictions returns the restrictions of requests authenticated with the access token.
+func tokenRestrictions(t *db.AccessToken) (*authz.TokenRestrictions, error) {
+	r := &authz.TokenRestrictions{Scopes: t.Scopes}
+	for _, pattern := range t.RepoPatterns {
+		p, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		r.RepoPatterns = append(r.RepoPatterns, p)
+	}
+	return r, nil
+}
+
+// isGraphQLRequest reports whether the request is for the GraphQL API.
+func isGraphQLRequest(r *http.Request) bool {
+	return r.Method == "POST" && r.URL.Path == "/.api/graphql"
+}
+
+// isSCIMRequest reports whether the request is for the SCIM API.
+func isSCIMRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/.api/scim/")
+}
@@ -8,8 +8,10 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
@@ -68,15 +70,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 	t.Run("valid header with invalid token", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", "token badbad")
-		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
-			calledAccessTokensLookup = true
-			return 0, errors.New("x")
+		var calledAccessTokensLookupToken bool
+		db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+			calledAccessTokensLookupToken = true
+			return nil, errors.New("x")
 		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusUnauthorized, "Invalid access token.\n")
-		if !calledAccessTokensLookup {
-			t.Error("!calledAccessTokensLookup")
+		if !calledAccessTokensLookupToken {
+			t.Error("!calledAccessTokensLookupToken")
 		}
 	})
 
@@ -84,21 +86,18 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		t.Run("valid non-sudo token: "+headerValue, func(t *testing.T) {
 			req, _ := http.NewRequest("GET", "/", nil)
 			req.Header.Set("Authorization", headerValue)
-			var calledAccessTokensLookup bool
-			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
-				calledAccessTokensLookup = true
+			var calledAccessTokensLookupToken bool
+			db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+				calledAccessTokensLookupToken = true
 				if want := "abcdef"; tokenHexEncoded != want {
 					t.Errorf("got %q, want %q", tokenHexEncoded, want)
 				}
-				if want := authz.ScopeUserAll; requiredScope != want {
-					t.Errorf("got %q, want %q", requiredScope, want)
-				}
-				return 123, nil
+				return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll}}, nil
 			}
 			defer func() { db.Mocks = db.MockStores{} }()
 			checkHTTPResponse(t, req, http.StatusOK, "user 123")
-			if !calledAccessTokensLookup {
-				t.Error("!calledAccessTokensLookup")
+			if !calledAccessTokensLookupToken {
+				t.Error("!calledAccessTokensLookupToken")
 			}
 		})
 	}
@@ -108,21 +107,18 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", "token abcdef")
 		req = req.WithContext(actor.WithActor(context.Background(), &actor.Actor{UID: 456}))
-		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
-			calledAccessTokensLookup = true
+		var calledAccessTokensLookupToken bool
+		db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+			calledAccessTokensLookupToken = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeUserAll; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
-			}
-			return 123, nil
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll}}, nil
 		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusOK, "user 123")
-		if !calledAccessTokensLookup {
-			t.Error("!calledAccessTokensLookup")
+		if !calledAccessTokensLookupToken {
+			t.Error("!calledAccessTokensLookupToken")
 		}
 	})
 
@@ -142,21 +138,18 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 				req.SetBasicAuth("abcdef", "")
 			}
 			req = req.WithContext(actor.WithActor(context.Background(), &actor.Actor{UID: 456}))
-			var calledAccessTokensLookup bool
-			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
-				calledAccessTokensLookup = true
+			var calledAccessTokensLookupToken bool
+			db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+				calledAccessTokensLookupToken = true
 				if want := "abcdef"; tokenHexEncoded != want {
 					t.Errorf("got %q, want %q", tokenHexEncoded, want)
 				}
-				if want := authz.ScopeUserAll; requiredScope != want {
-					t.Errorf("got %q, want %q", requiredScope, want)
-				}
-				return 123, nil
+				return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll}}, nil
 			}
 			defer func() { db.Mocks = db.MockStores{} }()
 			checkHTTPResponse(t, req, http.StatusOK, "user 123")
-			if !calledAccessTokensLookup {
-				t.Error("!calledAccessTokensLookup")
+			if !calledAccessTokensLookupToken {
+				t.Error("!calledAccessTokensLookupToken")
 			}
 		})
 	}
@@ -164,16 +157,13 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 	t.Run("valid sudo token", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
-		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
-			calledAccessTokensLookup = true
+		var calledAccessTokensLookupToken bool
+		db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+			calledAccessTokensLookupToken = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
-			}
-			return 123, nil
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll, authz.ScopeSiteAdminSudo}}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -191,10 +181,21 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			}
 			return &types.User{ID: 456, SiteAdmin: true}, nil
 		}
+		var calledAuditLogInsert bool
+		db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+			calledAuditLogInsert = true
+			if e.ActorUserID != 123 || e.Action != backend.AuditAccessTokenSudo || e.Subject != "user:456" {
+				t.Errorf("got unexpected audit log entry %+v", e)
+			}
+			return nil
+		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusOK, "user 456")
-		if !calledAccessTokensLookup {
-			t.Error("!calledAccessTokensLookup")
+		if !calledAuditLogInsert {
+			t.Error("!calledAuditLogInsert")
+		}
+		if !calledAccessTokensLookupToken {
+			t.Error("!calledAccessTokensLookupToken")
 		}
 		if !calledUsersGetByID {
 			t.Error("!calledUsersGetByID")
@@ -209,16 +210,13 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 	t.Run("valid sudo token, subject is not site admin", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
-		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
-			calledAccessTokensLookup = true
+		var calledAccessTokensLookupToken bool
+		db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+			calledAccessTokensLookupToken = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
-			}
-			return 123, nil
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll, authz.ScopeSiteAdminSudo}}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -230,8 +228,8 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusForbidden, "The subject user of a sudo access token must be a site admin.\n")
-		if !calledAccessTokensLookup {
-			t.Error("!calledAccessTokensLookup")
+		if !calledAccessTokensLookupToken {
+			t.Error("!calledAccessTokensLookupToken")
 		}
 		if !calledUsersGetByID {
 			t.Error("!calledUsersGetByID")
@@ -241,16 +239,13 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 	t.Run("valid sudo token, invalid sudo user", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="doesntexist"`)
-		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
-			calledAccessTokensLookup = true
+		var calledAccessTokensLookupToken bool
+		db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+			calledAccessTokensLookupToken = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
-			}
-			return 123, nil
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll, authz.ScopeSiteAdminSudo}}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -270,8 +265,8 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusForbidden, "Unable to sudo to nonexistent user.\n")
-		if !calledAccessTokensLookup {
-			t.Error("!calledAccessTokensLookup")
+		if !calledAccessTokensLookupToken {
+			t.Error("!calledAccessTokensLookupToken")
 		}
 		if !calledUsersGetByID {
 			t.Error("!calledUsersGetByID")
@@ -280,4 +275,87 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			t.Error("!calledUsersGetByUsername")
 		}
 	})
+
+	t.Run("valid sudo token, suspended sudo user", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
+		db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll, authz.ScopeSiteAdminSudo}}, nil
+		}
+		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
+			return &types.User{ID: userID, SiteAdmin: true}, nil
+		}
+		db.Mocks.Users.GetByUsername = func(ctx context.Context, username string) (*types.User, error) {
+			suspendedAt := time.Now()
+			return &types.User{ID: 456, Username: username, SuspendedAt: &suspendedAt}, nil
+		}
+		defer func() { db.Mocks = db.MockStores{} }()
+		checkHTTPResponse(t, req, http.StatusForbidden, "Unable to sudo to a suspended user.\n")
+	})
+
+	t.Run("sudo token without sudo scope", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
+		db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll}}, nil
+		}
+		defer func() { db.Mocks = db.MockStores{} }()
+		checkHTTPResponse(t, req, http.StatusUnauthorized, "Invalid access token.\n")
+	})
+
+	t.Run("sudo scope implies user:all scope", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/.api/repos/r/-/refresh", nil)
+		req.Header.Set("Authorization", "token abcdef")
+		db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeSiteAdminSudo}}, nil
+		}
+		defer func() { db.Mocks = db.MockStores{} }()
+		checkHTTPResponse(t, req, http.StatusOK, "user 123")
+	})
+
+	// 🚨 SECURITY: Tests that tokens without the "user:all" scope can only be used for the requests
+	// their scopes allow.
+	for _, test := range []struct {
+		scope, method, path string
+		wantStatusCode      int
+		wantBody            string
+	}{
+		{authz.ScopeUserRead, "GET", "/", http.StatusOK, "user 123"},
+		{authz.ScopeUserRead, "POST", "/.api/graphql", http.StatusOK, "user 123"},
+		{authz.ScopeUserRead, "POST", "/.api/repos/r/-/refresh", http.StatusForbidden, "The access token's scopes do not allow this request.\n"},
+		{authz.ScopeSearch, "POST", "/.api/graphql", http.StatusOK, "user 123"},
+		{authz.ScopeSearch, "GET", "/", http.StatusForbidden, "The access token's scopes do not allow this request.\n"},
+		{authz.ScopeSCIM, "POST", "/.api/scim/v2/Users", http.StatusOK, "user 123"},
+		{authz.ScopeSCIM, "POST", "/.api/graphql", http.StatusForbidden, "The access token's scopes do not allow this request.\n"},
+		{authz.ScopeSCIM, "GET", "/", http.StatusForbidden, "The access token's scopes do not allow this request.\n"},
+	} {
+		t.Run(fmt.Sprintf("%s token %s %s", test.scope, test.method, test.path), func(t *testing.T) {
+			req, _ := http.NewRequest(test.method, test.path, nil)
+			req.Header.Set("Authorization", "token abcdef")
+			db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+				return &db.AccessToken{SubjectUserID: 123, Scopes: []string{test.scope}}, nil
+			}
+			defer func() { db.Mocks = db.MockStores{} }()
+			checkHTTPResponse(t, req, test.wantStatusCode, test.wantBody)
+		})
+	}
+
+	t.Run("bearer token", func(t *testing.T) {
+		db.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*db.AccessToken, error) {
+			if want := "abcdef"; tokenHexEncoded != want {
+				t.Errorf("got %q, want %q", tokenHexEncoded, want)
+			}
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeSCIM}}, nil
+		}
+		defer func() { db.Mocks = db.MockStores{} }()
+
+		// The "Bearer" scheme is only accepted for the SCIM API.
+		req, _ := http.NewRequest("GET", "/.api/scim/v2/Users", nil)
+		req.Header.Set("Authorization", "Bearer abcdef")
+		checkHTTPResponse(t, req, http.StatusOK, "user 123")
+
+		req, _ = http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer abcdef")
+		checkHTTPResponse(t, req, http.StatusOK, "no user")
+	})
 }
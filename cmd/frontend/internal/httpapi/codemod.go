@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"archive/tar"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+// serveCodemodPatches runs the codemod of the search query in the "q"
+// parameter and responds with the patches of the repositories it changes.
+// With "format=tar" (the default) the response is a tarball with a patch
+// file per repository. With "format=patch" it's the single patch of the one
+// repository the query matches.
+func serveCodemodPatches(w http.ResponseWriter, r *http.Request) error {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "tar" && format != "patch" {
+		return &errcode.HTTPErr{Status: http.StatusBadRequest, Err: errors.Errorf("unknown format %q", format)}
+	}
+
+	patches, err := graphqlbackend.CodemodPatches(r.Context(), r.URL.Query().Get("q"))
+	if err != nil {
+		return err
+	}
+
+	if format == "patch" {
+		if len(patches) > 1 {
+			return &errcode.HTTPErr{Status: http.StatusBadRequest, Err: errors.New("the codemod changes more than one repository, use format=tar")}
+		}
+
+		w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+		if len(patches) == 1 {
+			_, err = io.WriteString(w, patches[0].Patch)
+		}
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", `attachment; filename="codemod.tar"`)
+	return writeCodemodTar(w, patches, time.Now())
+}
+
+// writeCodemodTar writes a tarball to w with a file named
+// "<repo>[@<rev>].patch" for each of the given patches.
+func writeCodemodTar(w io.Writer, patches []*graphqlbackend.CodemodPatch, modTime time.Time) error {
+	tw := tar.NewWriter(w)
+	for _, p := range patches {
+		name := string(p.Repo.Name)
+		if p.Rev != "" {
+			name += "@" + p.Rev
+		}
+
+		hdr := &tar.Header{
+			Name:    name + ".patch",
+			Mode:    0644,
+			Size:    int64(len(p.Patch)),
+			ModTime: modTime,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(tw, p.Patch); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func TestWriteCodemodTar(t *testing.T) {
+	patches := []*graphqlbackend.CodemodPatch{
+		{Repo: &types.Repo{Name: "github.com/foo/bar"}, Patch: "--- a/x\n+++ b/x\n"},
+		{Repo: &types.Repo{Name: "github.com/foo/baz"}, Rev: "dev", Patch: "--- a/y\n+++ b/y\n"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeCodemodTar(&buf, patches, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	have := map[string]string{}
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have[hdr.Name] = string(b)
+	}
+
+	want := map[string]string{
+		"github.com/foo/bar.patch":     "--- a/x\n+++ b/x\n",
+		"github.com/foo/baz@dev.patch": "--- a/y\n+++ b/y\n",
+	}
+	if diff := cmp.Diff(have, want); diff != "" {
+		t.Error(diff)
+	}
+}
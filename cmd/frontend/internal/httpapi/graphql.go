@@ -1,15 +1,16 @@
 package httpapi
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 
 	"github.com/graph-gophers/graphql-go"
-	"github.com/graph-gophers/graphql-go/relay"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 )
 
 func serveGraphQL(schema *graphql.Schema) func(w http.ResponseWriter, r *http.Request) (err error) {
-	relayHandler := &relay.Handler{Schema: schema}
 	return func(w http.ResponseWriter, r *http.Request) (err error) {
 		if r.Method != "POST" {
 			// The URL router should not have routed to this handler if method is not POST, but just in
@@ -17,7 +18,25 @@ func serveGraphQL(schema *graphql.Schema) func(w http.ResponseWriter, r *http.Re
 			return errors.New("method must be POST")
 		}
 
-		relayHandler.ServeHTTP(w, r)
-		return nil
+		var params struct {
+			Query         string                 `json:"query"`
+			OperationName string                 `json:"operationName"`
+			Variables     map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil
+		}
+
+		// 🚨 SECURITY: Enforce the scopes of the access token the request was authenticated with,
+		// if any, before executing the query.
+		var response *graphql.Response
+		if err := graphqlbackend.CheckAccessTokenScopes(r.Context(), params.Query); err != nil {
+			response = &graphql.Response{Errors: []*gqlerrors.QueryError{{Message: err.Error()}}}
+		} else {
+			response = schema.Exec(r.Context(), params.Query, params.OperationName, params.Variables)
+		}
+
+		return json.NewEncoder(w).Encode(response)
 	}
 }
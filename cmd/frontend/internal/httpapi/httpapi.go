@@ -14,9 +14,11 @@ import (
 	"github.com/graph-gophers/graphql-go"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/hooks"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/app/pkg/updatecheck"
 	apirouter "github.com/sourcegraph/sourcegraph/cmd/frontend/internal/httpapi/router"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/handlerutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/scim"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/registry"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
 	"github.com/sourcegraph/sourcegraph/pkg/trace"
@@ -50,6 +52,12 @@ func NewHandler(m *mux.Router, schema *graphql.Schema) http.Handler {
 
 	m.Get(apirouter.GraphQL).Handler(trace.TraceRoute(handler(serveGraphQL(schema))))
 
+	m.Get(apirouter.CodemodPatches).Handler(trace.TraceRoute(handler(serveCodemodPatches)))
+
+	m.Get(apirouter.AuditLogExport).Handler(trace.TraceRoute(handler(serveAuditLogExport)))
+
+	m.Get(apirouter.SCIM).Handler(trace.TraceRoute(http.StripPrefix("/.api/scim/v2", scim.NewHandler())))
+
 	lsifServerURL, err := url.Parse(lsifServerURLFromEnv)
 	if err != nil {
 		log15.Error("skipping initialization of the LSIF HTTP API because the environment variable LSIF_SERVER_URL is not a valid URL", "parse_error", err, "value", lsifServerURLFromEnv)
@@ -63,6 +71,14 @@ func NewHandler(m *mux.Router, schema *graphql.Schema) http.Handler {
 
 	m.Get(apirouter.Registry).Handler(trace.TraceRoute(handler(registry.HandleRegistry)))
 
+	if hooks.GitHubWebhook != nil {
+		m.Get(apirouter.GitHubWebhooks).Handler(trace.TraceRoute(hooks.GitHubWebhook))
+	}
+
+	if hooks.GitLabWebhook != nil {
+		m.Get(apirouter.GitLabWebhooks).Handler(trace.TraceRoute(hooks.GitLabWebhook))
+	}
+
 	m.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("API no route: %s %s from %s", r.Method, r.URL, r.Referer())
 		http.Error(w, "no route", http.StatusNotFound)
@@ -13,12 +13,21 @@ const (
 	LSIFVerify    = "lsif.verify"
 	GraphQL       = "graphql"
 
+	CodemodPatches = "codemod.patches"
+
+	GitHubWebhooks = "webhooks.github"
+	GitLabWebhooks = "webhooks.gitlab"
+
 	Registry = "registry"
 
 	RepoShield  = "repo.shield"
 	RepoRefresh = "repo.refresh"
 	Telemetry   = "telemetry"
 
+	AuditLogExport = "audit-log.export"
+
+	SCIM = "scim"
+
 	SavedQueriesListAll    = "internal.saved-queries.list-all"
 	SavedQueriesGetInfo    = "internal.saved-queries.get-info"
 	SavedQueriesSetInfo    = "internal.saved-queries.set-info"
@@ -64,6 +73,11 @@ func New(base *mux.Router) *mux.Router {
 	base.Path("/lsif/challenge").Methods("GET").Name(LSIFChallenge)
 	base.Path("/lsif/verify").Methods("GET").Name(LSIFVerify)
 	base.Path("/lsif/{rest:.*}").Methods("POST").Name(LSIF)
+	base.Path("/codemod/patches").Methods("GET").Name(CodemodPatches)
+	base.Path("/audit-log").Methods("GET").Name(AuditLogExport)
+	base.PathPrefix("/scim/v2/").Name(SCIM)
+	base.Path("/webhooks/github").Methods("POST").Name(GitHubWebhooks)
+	base.Path("/webhooks/gitlab").Methods("POST").Name(GitLabWebhooks)
 
 	// repo contains routes that are NOT specific to a revision. In these routes, the URL may not contain a revspec after the repo (that is, no "github.com/foo/bar@myrevspec").
 	repoPath := `/repos/` + routevar.Repo
@@ -20,6 +20,8 @@ const (
 	FieldType               = "type"
 	FieldRepoHasFile        = "repohasfile"
 	FieldRepoHasCommitAfter = "repohascommitafter"
+	FieldTopic              = "topic"
+	FieldVisibility         = "visibility"
 
 	// For diff and commit search only:
 	FieldBefore    = "before"
@@ -34,6 +36,9 @@ const (
 	FieldMax     = "max"   // Deprecated alias for count
 	FieldTimeout = "timeout"
 	FieldReplace = "replace"
+	FieldEngine  = "engine" // The replacer rewrite engine, used with `replace:`
+	FieldImage   = "image"  // The container image of the replacer script engine
+	FieldScript  = "script" // The shell script of the replacer script engine
 )
 
 var (
@@ -54,6 +59,8 @@ var (
 
 			FieldRepoHasFile:        regexpNegatableFieldType,
 			FieldRepoHasCommitAfter: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+			FieldTopic:              {Literal: types.StringType, Quoted: types.StringType, Negatable: true},
+			FieldVisibility:         {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 
 			FieldBefore:    stringFieldType,
 			FieldAfter:     stringFieldType,
@@ -67,6 +74,9 @@ var (
 			FieldMax:     {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldTimeout: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldReplace: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+			FieldEngine:  {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+			FieldImage:   {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+			FieldScript:  {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 		},
 		FieldAliases: map[string]string{
 			"r":        FieldRepo,
@@ -89,6 +89,27 @@ func TestScanner(t *testing.T) {
 	}
 }
 
+// Text with dots before a colon (such as property names and method calls) must
+// be scanned as a literal, not as a field.
+func TestScanner_dottedTextBeforeColon(t *testing.T) {
+	tests := map[string][]string{
+		"a.b:c":                      {"a.b:c", ""},
+		"spring.datasource.url: foo": {"spring.datasource.url:", " ", "foo", ""},
+		"console.log:":               {"console.log:", ""},
+	}
+	for input, want := range tests {
+		tokens := Scan(input)
+		for _, tok := range tokens {
+			if tok.Type == TokenColon {
+				t.Errorf("%s: got a colon token, want none", input)
+			}
+		}
+		if got := tokenValues(tokens); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %q, want %q", input, got, want)
+		}
+	}
+}
+
 func tokenTypes(tokens []Token) []TokenType {
 	types := make([]TokenType, len(tokens))
 	for i, t := range tokens {
@@ -0,0 +1,33 @@
+package scim
+
+import (
+	"strconv"
+	"strings"
+)
+
+// equalityFilter is a filter of the form `attr eq "value"`, the only form of filter (RFC 7644
+// section 3.4.2.2) supported. Identity providers use it to look up existing resources before
+// provisioning them.
+type equalityFilter struct {
+	Attr  string // the attribute path (e.g., "userName" or "emails.value")
+	Value string
+}
+
+// parseFilter parses an equality filter.
+func parseFilter(filter string) (*equalityFilter, error) {
+	filter = strings.TrimSpace(filter)
+	i := strings.IndexByte(filter, ' ')
+	if i == -1 {
+		return nil, badRequest("invalidFilter", "unsupported filter %q (only `attribute eq \"value\"` is supported)", filter)
+	}
+	attr, rest := filter[:i], strings.TrimLeft(filter[i:], " ")
+	j := strings.IndexByte(rest, ' ')
+	if j == -1 || !strings.EqualFold(rest[:j], "eq") {
+		return nil, badRequest("invalidFilter", "unsupported filter %q (only `attribute eq \"value\"` is supported)", filter)
+	}
+	value, err := strconv.Unquote(strings.TrimSpace(rest[j:]))
+	if err != nil {
+		return nil, badRequest("invalidFilter", "invalid filter value in %q (must be a quoted string)", filter)
+	}
+	return &equalityFilter{Attr: attr, Value: value}, nil
+}
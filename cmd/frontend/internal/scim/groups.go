@@ -0,0 +1,309 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+// groupResource is the SCIM representation of a group, which is an organization.
+//
+// The organization's name is derived from the displayName attribute when the group is created and
+// does not change afterwards (because organization names are immutable). The members' values are
+// the IDs of the users.
+type groupResource struct {
+	Schemas     []string      `json:"schemas"`
+	ID          string        `json:"id,omitempty"`
+	DisplayName string        `json:"displayName"`
+	Members     []groupMember `json:"members"`
+	Meta        *meta         `json:"meta,omitempty"`
+}
+
+type groupMember struct {
+	Value string `json:"value"`
+	Ref   string `json:"$ref,omitempty"`
+}
+
+// toGroupResource returns the SCIM representation of the organization.
+func toGroupResource(ctx context.Context, org *types.Org) (*groupResource, error) {
+	members, err := db.OrgMembers.GetByOrgID(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &groupResource{
+		Schemas:     []string{schemaGroup},
+		ID:          strconv.Itoa(int(org.ID)),
+		DisplayName: orgDisplayName(org),
+		Members:     []groupMember{},
+		Meta: &meta{
+			ResourceType: "Group",
+			Created:      org.CreatedAt.Format(time.RFC3339),
+			LastModified: org.UpdatedAt.Format(time.RFC3339),
+			Location:     location("Groups", org.ID),
+		},
+	}
+	for _, m := range members {
+		res.Members = append(res.Members, groupMember{
+			Value: strconv.Itoa(int(m.UserID)),
+			Ref:   location("Users", m.UserID),
+		})
+	}
+	return res, nil
+}
+
+func orgDisplayName(org *types.Org) string {
+	if org.DisplayName != nil && *org.DisplayName != "" {
+		return *org.DisplayName
+	}
+	return org.Name
+}
+
+func writeGroup(ctx context.Context, w http.ResponseWriter, status int, org *types.Org) error {
+	res, err := toGroupResource(ctx, org)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusCreated {
+		w.Header().Set("Location", res.Meta.Location)
+	}
+	return writeJSON(w, status, res)
+}
+
+func getGroup(ctx context.Context, id string) (*types.Org, error) {
+	orgID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	return db.Orgs.GetByID(ctx, orgID)
+}
+
+func serveListGroups(w http.ResponseWriter, r *http.Request) error {
+	startIndex, count, err := pagination(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		orgs  []*types.Org
+		total int
+	)
+	if filter := r.URL.Query().Get("filter"); filter != "" {
+		f, err := parseFilter(filter)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(f.Attr, "displayName") {
+			return badRequest("invalidFilter", "filtering by attribute %q is not supported (supported attributes: displayName)", f.Attr)
+		}
+		all, err := db.Orgs.List(r.Context(), nil)
+		if err != nil {
+			return err
+		}
+		for _, org := range all {
+			if strings.EqualFold(orgDisplayName(org), f.Value) {
+				orgs = append(orgs, org)
+			}
+		}
+		total = len(orgs)
+		if start := startIndex - 1; start < len(orgs) {
+			orgs = orgs[start:]
+		} else {
+			orgs = nil
+		}
+		if len(orgs) > count {
+			orgs = orgs[:count]
+		}
+	} else {
+		total, err = db.Orgs.Count(r.Context(), db.OrgsListOptions{})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			orgs, err = db.Orgs.List(r.Context(), &db.OrgsListOptions{LimitOffset: &db.LimitOffset{Limit: count, Offset: startIndex - 1}})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	resp := listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(orgs),
+		Resources:    []interface{}{},
+	}
+	for _, org := range orgs {
+		res, err := toGroupResource(r.Context(), org)
+		if err != nil {
+			return err
+		}
+		resp.Resources = append(resp.Resources, res)
+	}
+	return writeJSON(w, http.StatusOK, resp)
+}
+
+func serveGetGroup(w http.ResponseWriter, r *http.Request, id string) error {
+	org, err := getGroup(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	return writeGroup(r.Context(), w, http.StatusOK, org)
+}
+
+func serveCreateGroup(w http.ResponseWriter, r *http.Request) error {
+	var res groupResource
+	if err := readJSON(r, &res); err != nil {
+		return err
+	}
+	if res.DisplayName == "" {
+		return badRequest("invalidValue", "displayName is required")
+	}
+	name, err := auth.NormalizeUsername(res.DisplayName)
+	if err != nil {
+		return badRequest("invalidValue", "invalid displayName %q: %s", res.DisplayName, err)
+	}
+	memberIDs, err := parseMemberIDs(r.Context(), res.Members)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Orgs.GetByName(r.Context(), name); err == nil {
+		return &scimError{Status: http.StatusConflict, ScimType: "uniqueness", Detail: fmt.Sprintf("an organization named %q already exists", name)}
+	} else if !errcode.IsNotFound(err) {
+		return err
+	}
+	org, err := db.Orgs.Create(r.Context(), name, &res.DisplayName)
+	if err != nil {
+		return err
+	}
+	if err := setMembers(r.Context(), org.ID, memberIDs); err != nil {
+		return err
+	}
+	return writeGroup(r.Context(), w, http.StatusCreated, org)
+}
+
+func serveReplaceGroup(w http.ResponseWriter, r *http.Request, id string) error {
+	org, err := getGroup(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	var res groupResource
+	if err := readJSON(r, &res); err != nil {
+		return err
+	}
+	return updateGroup(r.Context(), w, org, &res)
+}
+
+func servePatchGroup(w http.ResponseWriter, r *http.Request, id string) error {
+	org, err := getGroup(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	var req patchRequest
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	res, err := toGroupResource(r.Context(), org)
+	if err != nil {
+		return err
+	}
+	if err := patch(res, &req); err != nil {
+		return err
+	}
+	return updateGroup(r.Context(), w, org, res)
+}
+
+func serveDeleteGroup(w http.ResponseWriter, r *http.Request, id string) error {
+	org, err := getGroup(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	if err := db.Orgs.Delete(r.Context(), org.ID); err != nil {
+		return err
+	}
+	backend.LogAuditEvent(r.Context(), backend.AuditOrgDelete, fmt.Sprintf("org:%d", org.ID), nil)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// updateGroup updates the organization to match the SCIM representation and writes the updated
+// representation.
+func updateGroup(ctx context.Context, w http.ResponseWriter, org *types.Org, res *groupResource) error {
+	if res.DisplayName != "" && res.DisplayName != orgDisplayName(org) {
+		var err error
+		if org, err = db.Orgs.Update(ctx, org.ID, &res.DisplayName); err != nil {
+			return err
+		}
+	}
+	if res.Members != nil {
+		memberIDs, err := parseMemberIDs(ctx, res.Members)
+		if err != nil {
+			return err
+		}
+		if err := setMembers(ctx, org.ID, memberIDs); err != nil {
+			return err
+		}
+	}
+	return writeGroup(ctx, w, http.StatusOK, org)
+}
+
+// parseMemberIDs returns the IDs of the members' users, checking that the users exist.
+func parseMemberIDs(ctx context.Context, members []groupMember) ([]int32, error) {
+	userIDs := make([]int32, 0, len(members))
+	for _, m := range members {
+		userID, err := strconv.ParseInt(m.Value, 10, 32)
+		if err != nil {
+			return nil, badRequest("invalidValue", "member %q is not a user", m.Value)
+		}
+		if _, err := db.Users.GetByID(ctx, int32(userID)); err != nil {
+			if errcode.IsNotFound(err) {
+				return nil, badRequest("invalidValue", "member %q is not a user", m.Value)
+			}
+			return nil, err
+		}
+		userIDs = append(userIDs, int32(userID))
+	}
+	return userIDs, nil
+}
+
+// setMembers sets the organization's members to the users with the IDs.
+func setMembers(ctx context.Context, orgID int32, userIDs []int32) error {
+	members, err := db.OrgMembers.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	isMember := make(map[int32]bool, len(members))
+	for _, m := range members {
+		isMember[m.UserID] = true
+	}
+
+	want := make(map[int32]bool, len(userIDs))
+	for _, userID := range userIDs {
+		want[userID] = true
+		if !isMember[userID] {
+			if _, err := db.OrgMembers.Create(ctx, orgID, userID); err != nil {
+				return err
+			}
+			isMember[userID] = true
+		}
+	}
+	for _, m := range members {
+		if !want[m.UserID] {
+			if err := db.OrgMembers.Remove(ctx, orgID, m.UserID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
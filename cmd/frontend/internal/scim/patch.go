@@ -0,0 +1,217 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// patchRequest is the body of a PATCH request (RFC 7644 section 3.5.2).
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// patch applies the PATCH request's operations to the resource, which is decoded into and encoded
+// from its JSON representation to apply them.
+//
+// Attribute paths may refer to top-level attributes ("active"), sub-attributes ("name.givenName"),
+// and values of multi-valued attributes with an equality filter ("members[value eq \"1\"]" or
+// "emails[type eq \"work\"].value").
+func patch(resource interface{}, req *patchRequest) error {
+	b, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+
+	for _, op := range req.Operations {
+		opName := strings.ToLower(op.Op)
+		if opName != "add" && opName != "replace" && opName != "remove" {
+			return badRequest("invalidSyntax", "invalid patch operation %q", op.Op)
+		}
+		if op.Path == "" {
+			// Without a path, the value is an object with the attributes to add or replace.
+			values, ok := op.Value.(map[string]interface{})
+			if !ok || opName == "remove" {
+				return badRequest("noTarget", "patch operation %q requires a path", op.Op)
+			}
+			for attr, value := range values {
+				if err := patchAttribute(doc, opName, attr, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := patchAttribute(doc, opName, op.Path, op.Value); err != nil {
+			return err
+		}
+	}
+
+	b, err = json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, resource); err != nil {
+		return badRequest("invalidValue", "invalid patch value: %s", err)
+	}
+	return nil
+}
+
+func patchAttribute(doc map[string]interface{}, op, path string, value interface{}) error {
+	// Paths may be prefixed with the resource's schema URN.
+	for _, schema := range []string{schemaUser, schemaGroup} {
+		if strings.HasPrefix(path, schema+":") {
+			path = strings.TrimPrefix(path, schema+":")
+		}
+	}
+
+	var (
+		attr, subAttr string
+		filter        *equalityFilter
+	)
+	if i := strings.IndexByte(path, '['); i != -1 {
+		j := strings.IndexByte(path, ']')
+		if j < i {
+			return badRequest("invalidPath", "invalid path %q", path)
+		}
+		var err error
+		if filter, err = parseFilter(path[i+1 : j]); err != nil {
+			return badRequest("invalidPath", "invalid filter in path %q", path)
+		}
+		attr, subAttr = path[:i], strings.TrimPrefix(path[j+1:], ".")
+	} else if i := strings.IndexByte(path, '.'); i != -1 {
+		attr, subAttr = path[:i], path[i+1:]
+	} else {
+		attr = path
+	}
+	key := lookupKey(doc, attr)
+
+	if filter != nil {
+		values, _ := doc[key].([]interface{})
+		var (
+			result  = []interface{}{} // not nil, so that removing all values is not ignored
+			matched bool
+		)
+		for _, v := range values {
+			m, ok := v.(map[string]interface{})
+			if !ok || !strings.EqualFold(fmt.Sprint(m[lookupKey(m, filter.Attr)]), filter.Value) {
+				result = append(result, v)
+				continue
+			}
+			matched = true
+			switch {
+			case op == "remove" && subAttr == "":
+				continue // remove the value
+			case op == "remove":
+				delete(m, lookupKey(m, subAttr))
+			case subAttr == "":
+				if fields, ok := value.(map[string]interface{}); ok {
+					for k, v := range fields {
+						m[lookupKey(m, k)] = v
+					}
+				}
+			default:
+				m[lookupKey(m, subAttr)] = value
+			}
+			result = append(result, m)
+		}
+		if !matched && op != "remove" {
+			m := map[string]interface{}{filter.Attr: filter.Value}
+			if subAttr == "" {
+				if fields, ok := value.(map[string]interface{}); ok {
+					for k, v := range fields {
+						m[k] = v
+					}
+				}
+			} else {
+				m[subAttr] = value
+			}
+			result = append(result, m)
+		}
+		doc[key] = result
+		return nil
+	}
+
+	if subAttr != "" {
+		m, _ := doc[key].(map[string]interface{})
+		if m == nil {
+			if op == "remove" {
+				return nil
+			}
+			m = map[string]interface{}{}
+			doc[key] = m
+		}
+		if op == "remove" {
+			delete(m, lookupKey(m, subAttr))
+		} else {
+			m[lookupKey(m, subAttr)] = value
+		}
+		return nil
+	}
+
+	existing, isMultiValued := doc[key].([]interface{})
+	newValues, valueIsMultiValued := value.([]interface{})
+	switch {
+	case op == "remove" && isMultiValued && valueIsMultiValued:
+		// Remove the given values (e.g., members) from the multi-valued attribute.
+		result := []interface{}{}
+		for _, v := range existing {
+			if !containsValue(newValues, v) {
+				result = append(result, v)
+			}
+		}
+		doc[key] = result
+	case op == "remove" && isMultiValued:
+		doc[key] = []interface{}{}
+	case op == "remove":
+		delete(doc, key)
+	case op == "add" && isMultiValued && valueIsMultiValued:
+		for _, v := range newValues {
+			if !containsValue(existing, v) {
+				existing = append(existing, v)
+			}
+		}
+		doc[key] = existing
+	default:
+		doc[key] = value
+	}
+	return nil
+}
+
+// lookupKey returns the key in m that case-insensitively equals attr (because attribute names are
+// case-insensitive), or attr if there is none.
+func lookupKey(m map[string]interface{}, attr string) string {
+	for k := range m {
+		if strings.EqualFold(k, attr) {
+			return k
+		}
+	}
+	return attr
+}
+
+// containsValue reports whether values contains v, comparing the "value" sub-attributes of complex
+// values.
+func containsValue(values []interface{}, v interface{}) bool {
+	key := func(v interface{}) string {
+		if m, ok := v.(map[string]interface{}); ok {
+			return fmt.Sprint(m[lookupKey(m, "value")])
+		}
+		return fmt.Sprint(v)
+	}
+	for _, w := range values {
+		if key(w) == key(v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package scim
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPatch(t *testing.T) {
+	tests := map[string]struct {
+		doc  string
+		ops  string
+		want string
+	}{
+		"replace top-level attribute": {
+			doc:  `{"userName": "a", "active": true}`,
+			ops:  `[{"op": "replace", "path": "active", "value": false}]`,
+			want: `{"userName": "a", "active": false}`,
+		},
+		"attribute names are case-insensitive": {
+			doc:  `{"displayName": "A"}`,
+			ops:  `[{"op": "Replace", "path": "DISPLAYNAME", "value": "B"}]`,
+			want: `{"displayName": "B"}`,
+		},
+		"path with schema URN": {
+			doc:  `{"userName": "a"}`,
+			ops:  `[{"op": "replace", "path": "urn:ietf:params:scim:schemas:core:2.0:User:userName", "value": "b"}]`,
+			want: `{"userName": "b"}`,
+		},
+		"no path": {
+			doc:  `{"userName": "a", "active": true}`,
+			ops:  `[{"op": "replace", "value": {"userName": "b", "active": "False"}}]`,
+			want: `{"userName": "b", "active": "False"}`,
+		},
+		"sub-attribute": {
+			doc:  `{"name": {"givenName": "A"}}`,
+			ops:  `[{"op": "add", "path": "name.familyName", "value": "B"}, {"op": "remove", "path": "name.givenName"}]`,
+			want: `{"name": {"familyName": "B"}}`,
+		},
+		"add to multi-valued attribute": {
+			doc:  `{"members": [{"value": "1"}]}`,
+			ops:  `[{"op": "add", "path": "members", "value": [{"value": "1"}, {"value": "2"}]}]`,
+			want: `{"members": [{"value": "1"}, {"value": "2"}]}`,
+		},
+		"remove values from multi-valued attribute": {
+			doc:  `{"members": [{"value": "1"}, {"value": "2"}]}`,
+			ops:  `[{"op": "remove", "path": "members", "value": [{"value": "1"}]}]`,
+			want: `{"members": [{"value": "2"}]}`,
+		},
+		"remove all values of multi-valued attribute": {
+			doc:  `{"members": [{"value": "1"}, {"value": "2"}]}`,
+			ops:  `[{"op": "remove", "path": "members"}]`,
+			want: `{"members": []}`,
+		},
+		"remove with filter": {
+			doc:  `{"members": [{"value": "1"}, {"value": "2"}]}`,
+			ops:  `[{"op": "remove", "path": "members[value eq \"2\"]"}]`,
+			want: `{"members": [{"value": "1"}]}`,
+		},
+		"replace sub-attribute with filter": {
+			doc:  `{"emails": [{"type": "work", "value": "a@example.com"}, {"type": "home", "value": "b@example.com"}]}`,
+			ops:  `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "c@example.com"}]`,
+			want: `{"emails": [{"type": "work", "value": "c@example.com"}, {"type": "home", "value": "b@example.com"}]}`,
+		},
+		"add sub-attribute with unmatched filter": {
+			doc:  `{}`,
+			ops:  `[{"op": "add", "path": "emails[type eq \"work\"].value", "value": "a@example.com"}]`,
+			want: `{"emails": [{"type": "work", "value": "a@example.com"}]}`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var req patchRequest
+			if err := json.Unmarshal([]byte(`{"Operations": `+test.ops+`}`), &req); err != nil {
+				t.Fatal(err)
+			}
+			var doc, want map[string]interface{}
+			if err := json.Unmarshal([]byte(test.doc), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(test.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if err := patch(&doc, &req); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(doc, want) {
+				t.Errorf("got %v, want %v", doc, want)
+			}
+		})
+	}
+
+	for _, ops := range []string{
+		`[{"op": "move", "path": "userName"}]`,
+		`[{"op": "remove"}]`,
+		`[{"op": "replace", "value": "x"}]`,
+		`[{"op": "remove", "path": "members[value]"}]`,
+	} {
+		t.Run("invalid "+ops, func(t *testing.T) {
+			var req patchRequest
+			if err := json.Unmarshal([]byte(`{"Operations": `+ops+`}`), &req); err != nil {
+				t.Fatal(err)
+			}
+			doc := map[string]interface{}{}
+			if err := patch(&doc, &req); err == nil {
+				t.Error("got nil error")
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := map[string]*equalityFilter{
+		`userName eq "alice"`:             {Attr: "userName", Value: "alice"},
+		`userName  EQ  "alice"`:           {Attr: "userName", Value: "alice"},
+		`emails.value eq "a@example.com"`: {Attr: "emails.value", Value: "a@example.com"},
+		`displayName eq "a \"b\""`:        {Attr: "displayName", Value: `a "b"`},
+	}
+	for filter, want := range tests {
+		got, err := parseFilter(filter)
+		if err != nil {
+			t.Errorf("%s: %s", filter, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", filter, got, want)
+		}
+	}
+
+	for _, filter := range []string{"", "userName", `userName co "a"`, `userName eq alice`, `userName eq "a" and active eq true`} {
+		if _, err := parseFilter(filter); err == nil {
+			t.Errorf("%q: got nil error", filter)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Package scim implements a SCIM 2.0 (RFC 7643 and RFC 7644) service provider, which identity
+// providers use to provision users and groups (organizations) on the site.
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// Schema URNs defined by RFC 7643 and RFC 7644.
+const (
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// basePath is the path of the SCIM API, which is the base of all resource locations.
+const basePath = "/.api/scim/v2"
+
+// maxResults is the maximum number of resources returned in a list response.
+const maxResults = 1000
+
+// NewHandler returns the handler for the SCIM API. Request paths must be relative to the base path
+// (e.g., "/Users/1").
+//
+// 🚨 SECURITY: The handler only serves requests authenticated with an access token with the "scim"
+// scope whose user is a site admin.
+func NewHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := serve(w, r); err != nil {
+			writeError(w, r, err)
+		}
+	})
+}
+
+func serve(w http.ResponseWriter, r *http.Request) error {
+	// 🚨 SECURITY: Only site admins may provision users and groups, and only with an access token
+	// dedicated to SCIM.
+	if restrictions := authz.TokenRestrictionsFromContext(r.Context()); restrictions == nil || !restrictions.HasScope(authz.ScopeSCIM) {
+		return &scimError{Status: http.StatusUnauthorized, Detail: fmt.Sprintf("an access token with the %q scope is required", authz.ScopeSCIM)}
+	}
+	if err := backend.CheckCurrentUserIsSiteAdmin(r.Context()); err != nil {
+		return &scimError{Status: http.StatusForbidden, Detail: err.Error()}
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "ServiceProviderConfig" && r.Method == "GET":
+		return writeJSON(w, http.StatusOK, serviceProviderConfig())
+	case len(parts) == 1 && parts[0] == "Users":
+		switch r.Method {
+		case "GET":
+			return serveListUsers(w, r)
+		case "POST":
+			return serveCreateUser(w, r)
+		}
+	case len(parts) == 2 && parts[0] == "Users":
+		switch r.Method {
+		case "GET":
+			return serveGetUser(w, r, parts[1])
+		case "PUT":
+			return serveReplaceUser(w, r, parts[1])
+		case "PATCH":
+			return servePatchUser(w, r, parts[1])
+		case "DELETE":
+			return serveDeleteUser(w, r, parts[1])
+		}
+	case len(parts) == 1 && parts[0] == "Groups":
+		switch r.Method {
+		case "GET":
+			return serveListGroups(w, r)
+		case "POST":
+			return serveCreateGroup(w, r)
+		}
+	case len(parts) == 2 && parts[0] == "Groups":
+		switch r.Method {
+		case "GET":
+			return serveGetGroup(w, r, parts[1])
+		case "PUT":
+			return serveReplaceGroup(w, r, parts[1])
+		case "PATCH":
+			return servePatchGroup(w, r, parts[1])
+		case "DELETE":
+			return serveDeleteGroup(w, r, parts[1])
+		}
+	default:
+		return &scimError{Status: http.StatusNotFound, Detail: "unknown endpoint"}
+	}
+	return &scimError{Status: http.StatusMethodNotAllowed, Detail: fmt.Sprintf("method %s is not supported", r.Method)}
+}
+
+// scimError is an error returned to the client as a SCIM error response (RFC 7644 section 3.12).
+type scimError struct {
+	Status   int
+	ScimType string // one of the error types defined in RFC 7644, if any (e.g., "invalidFilter")
+	Detail   string
+}
+
+func (e *scimError) Error() string { return e.Detail }
+
+func badRequest(scimType, format string, args ...interface{}) error {
+	return &scimError{Status: http.StatusBadRequest, ScimType: scimType, Detail: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	e, ok := err.(*scimError)
+	if !ok {
+		if errcode.IsNotFound(err) {
+			e = &scimError{Status: http.StatusNotFound, Detail: err.Error()}
+		} else if msg := errcode.PresentationMessage(err); msg != "" {
+			e = &scimError{Status: http.StatusBadRequest, Detail: msg} // e.g., the licensed user count was reached
+		} else {
+			log15.Error("SCIM request failed.", "method", r.Method, "path", r.URL.Path, "error", err)
+			e = &scimError{Status: http.StatusInternalServerError, Detail: "internal error"}
+		}
+	}
+	_ = writeJSON(w, e.Status, struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail"`
+	}{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(e.Status),
+		ScimType: e.ScimType,
+		Detail:   e.Detail,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+// readJSON decodes the request body into v.
+func readJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequest("invalidSyntax", "invalid request body: %s", err)
+	}
+	return nil
+}
+
+// meta is the metadata of a resource.
+type meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location"`
+}
+
+// location returns the URL of the resource with the type (e.g., "Users") and ID.
+func location(typ string, id int32) string {
+	return globals.ExternalURL().ResolveReference(&url.URL{Path: fmt.Sprintf("%s/%s/%d", basePath, typ, id)}).String()
+}
+
+// parseID parses a resource ID. IDs of users and groups are their database IDs.
+func parseID(id string) (int32, error) {
+	n, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return 0, &scimError{Status: http.StatusNotFound, Detail: fmt.Sprintf("resource %q not found", id)}
+	}
+	return int32(n), nil
+}
+
+// listResponse is a page of resources (RFC 7644 section 3.4.2).
+type listResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// pagination returns the 1-based start index and the count of the requested page.
+func pagination(r *http.Request) (startIndex, count int, err error) {
+	startIndex, count = 1, maxResults
+	if v := r.URL.Query().Get("startIndex"); v != "" {
+		if startIndex, err = strconv.Atoi(v); err != nil {
+			return 0, 0, badRequest("invalidValue", "invalid startIndex %q", v)
+		}
+		if startIndex < 1 {
+			startIndex = 1
+		}
+	}
+	if v := r.URL.Query().Get("count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil {
+			return 0, 0, badRequest("invalidValue", "invalid count %q", v)
+		}
+		if count < 0 {
+			count = 0
+		} else if count > maxResults {
+			count = maxResults
+		}
+	}
+	return startIndex, count, nil
+}
+
+func serviceProviderConfig() interface{} {
+	type supported struct {
+		Supported bool `json:"supported"`
+	}
+	return map[string]interface{}{
+		"schemas":          []string{schemaServiceProviderConfig},
+		"documentationUri": "https://docs.sourcegraph.com/admin/auth/scim",
+		"patch":            supported{true},
+		"bulk":             map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":           map[string]interface{}{"supported": true, "maxResults": maxResults},
+		"changePassword":   supported{false},
+		"sort":             supported{false},
+		"etag":             supported{false},
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "Access token",
+			"description": fmt.Sprintf("A site admin's access token with the %q scope, in the Authorization header with the Bearer scheme.", authz.ScopeSCIM),
+			"primary":     true,
+		}},
+	}
+}
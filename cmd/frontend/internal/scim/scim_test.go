@@ -0,0 +1,497 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+// fakeStore is an in-memory store of users, email addresses, organizations, and memberships that
+// the db mocks are backed by.
+type fakeStore struct {
+	users   map[int32]*types.User
+	emails  map[int32][]*db.UserEmail
+	orgs    map[int32]*types.Org
+	members map[int32]map[int32]bool // org ID -> user ID -> is member
+	nextID  int32
+	audit   []string // audit log entries, as "action subject"
+}
+
+func newFakeStore(t *testing.T) *fakeStore {
+	s := &fakeStore{
+		users:   map[int32]*types.User{1: {ID: 1, Username: "admin", SiteAdmin: true}},
+		emails:  map[int32][]*db.UserEmail{},
+		orgs:    map[int32]*types.Org{},
+		members: map[int32]map[int32]bool{},
+		nextID:  100,
+	}
+	notFound := func() error { return &errcode.Mock{IsNotFound: true} }
+	now := time.Now()
+
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		if u := s.users[actor.FromContext(ctx).UID]; u != nil {
+			return u, nil
+		}
+		return nil, db.ErrNoCurrentUser
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		if u := s.users[id]; u != nil {
+			return u, nil
+		}
+		return nil, notFound()
+	}
+	db.Mocks.Users.GetByUsername = func(ctx context.Context, username string) (*types.User, error) {
+		for _, u := range s.users {
+			if u.Username == username {
+				return u, nil
+			}
+		}
+		return nil, notFound()
+	}
+	db.Mocks.Users.GetByVerifiedEmail = func(ctx context.Context, email string) (*types.User, error) {
+		for userID, emails := range s.emails {
+			for _, e := range emails {
+				if strings.EqualFold(e.Email, email) && e.VerifiedAt != nil {
+					return s.users[userID], nil
+				}
+			}
+		}
+		return nil, notFound()
+	}
+	db.Mocks.Users.Create = func(ctx context.Context, info db.NewUser) (*types.User, error) {
+		if _, err := db.Mocks.Users.GetByUsername(ctx, info.Username); err == nil {
+			t.Fatalf("username %q already exists", info.Username)
+		}
+		s.nextID++
+		u := &types.User{ID: s.nextID, Username: info.Username, DisplayName: info.DisplayName, CreatedAt: now, UpdatedAt: now}
+		s.users[u.ID] = u
+		if info.Email != "" {
+			if !info.EmailIsVerified {
+				t.Errorf("got unverified email %q", info.Email)
+			}
+			s.emails[u.ID] = []*db.UserEmail{{UserID: u.ID, Email: info.Email, VerifiedAt: &now}}
+		}
+		return u, nil
+	}
+	db.Mocks.Users.Update = func(userID int32, update db.UserUpdate) error {
+		u := *s.users[userID]
+		if update.Username != "" {
+			u.Username = update.Username
+		}
+		if update.DisplayName != nil {
+			u.DisplayName = *update.DisplayName
+		}
+		s.users[userID] = &u
+		return nil
+	}
+	db.Mocks.Users.SetSuspended = func(id int32, suspended bool) error {
+		u := *s.users[id]
+		u.SuspendedAt = nil
+		if suspended {
+			u.SuspendedAt = &now
+		}
+		s.users[id] = &u
+		return nil
+	}
+	db.Mocks.Users.Count = func(ctx context.Context, opt *db.UsersListOptions) (int, error) {
+		return len(s.users), nil
+	}
+	db.Mocks.Users.List = func(ctx context.Context, opt *db.UsersListOptions) ([]*types.User, error) {
+		var users []*types.User
+		for _, u := range s.users {
+			users = append(users, u)
+		}
+		sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+		if opt.Offset < len(users) {
+			users = users[opt.Offset:]
+		} else {
+			users = nil
+		}
+		if len(users) > opt.Limit {
+			users = users[:opt.Limit]
+		}
+		return users, nil
+	}
+
+	db.Mocks.UserEmails.ListByUser = func(id int32) ([]*db.UserEmail, error) {
+		return s.emails[id], nil
+	}
+	db.Mocks.UserEmails.GetPrimaryEmail = func(ctx context.Context, id int32) (string, bool, error) {
+		if len(s.emails[id]) == 0 {
+			return "", false, notFound()
+		}
+		return s.emails[id][0].Email, s.emails[id][0].VerifiedAt != nil, nil
+	}
+	db.Mocks.UserEmails.Add = func(userID int32, email string, verificationCode *string) error {
+		s.emails[userID] = append(s.emails[userID], &db.UserEmail{UserID: userID, Email: email, VerificationCode: verificationCode})
+		return nil
+	}
+	db.Mocks.UserEmails.SetVerified = func(userID int32, email string, verified bool) error {
+		for _, e := range s.emails[userID] {
+			if e.Email == email && verified {
+				e.VerifiedAt = &now
+			}
+		}
+		return nil
+	}
+	db.Mocks.UserEmails.Remove = func(userID int32, email string) error {
+		var emails []*db.UserEmail
+		for _, e := range s.emails[userID] {
+			if e.Email != email {
+				emails = append(emails, e)
+			}
+		}
+		s.emails[userID] = emails
+		return nil
+	}
+
+	db.Mocks.Orgs.GetByID = func(ctx context.Context, id int32) (*types.Org, error) {
+		if o := s.orgs[id]; o != nil {
+			return o, nil
+		}
+		return nil, notFound()
+	}
+	db.Mocks.Orgs.GetByName = func(ctx context.Context, name string) (*types.Org, error) {
+		for _, o := range s.orgs {
+			if o.Name == name {
+				return o, nil
+			}
+		}
+		return nil, notFound()
+	}
+	db.Mocks.Orgs.List = func(ctx context.Context, opt *db.OrgsListOptions) ([]*types.Org, error) {
+		var orgs []*types.Org
+		for _, o := range s.orgs {
+			orgs = append(orgs, o)
+		}
+		sort.Slice(orgs, func(i, j int) bool { return orgs[i].ID < orgs[j].ID })
+		return orgs, nil
+	}
+	db.Mocks.Orgs.Count = func(ctx context.Context, opt db.OrgsListOptions) (int, error) {
+		return len(s.orgs), nil
+	}
+	db.Mocks.Orgs.Create = func(ctx context.Context, name string, displayName *string) (*types.Org, error) {
+		s.nextID++
+		o := &types.Org{ID: s.nextID, Name: name, DisplayName: displayName, CreatedAt: now, UpdatedAt: now}
+		s.orgs[o.ID] = o
+		s.members[o.ID] = map[int32]bool{}
+		return o, nil
+	}
+	db.Mocks.Orgs.Update = func(ctx context.Context, id int32, displayName *string) (*types.Org, error) {
+		o := *s.orgs[id]
+		o.DisplayName = displayName
+		s.orgs[id] = &o
+		return &o, nil
+	}
+	db.Mocks.Orgs.Delete = func(ctx context.Context, id int32) error {
+		delete(s.orgs, id)
+		return nil
+	}
+	db.Mocks.OrgMembers.GetByOrgID = func(ctx context.Context, orgID int32) ([]*types.OrgMembership, error) {
+		var members []*types.OrgMembership
+		for userID := range s.members[orgID] {
+			members = append(members, &types.OrgMembership{OrgID: orgID, UserID: userID})
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+		return members, nil
+	}
+	db.Mocks.OrgMembers.Create = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+		s.members[orgID][userID] = true
+		return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+	}
+	db.Mocks.OrgMembers.Remove = func(ctx context.Context, orgID, userID int32) error {
+		delete(s.members[orgID], userID)
+		return nil
+	}
+	db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+		s.audit = append(s.audit, e.Action+" "+e.Subject)
+		return nil
+	}
+	return s
+}
+
+// do performs a request to the SCIM API as the site admin with a SCIM access token and decodes
+// the response body into v (if non-nil).
+func do(t *testing.T, method, path, body string, v interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	ctx = authz.WithTokenRestrictions(ctx, &authz.TokenRestrictions{Scopes: []string{authz.ScopeSCIM}})
+	rr := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rr, req.WithContext(ctx))
+	if v != nil {
+		if err := json.Unmarshal(rr.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: %s (body %q)", method, path, err, rr.Body.String())
+		}
+	}
+	return rr
+}
+
+func TestHandler_Authorization(t *testing.T) {
+	s := newFakeStore(t)
+	s.users[2] = &types.User{ID: 2, Username: "alice"}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	tests := map[string]struct {
+		uid            int32
+		restrictions   *authz.TokenRestrictions
+		wantStatusCode int
+	}{
+		"session cookie":  {uid: 1, wantStatusCode: http.StatusUnauthorized},
+		"user:all token":  {uid: 1, restrictions: &authz.TokenRestrictions{Scopes: []string{authz.ScopeUserAll}}, wantStatusCode: http.StatusUnauthorized},
+		"non-site-admin":  {uid: 2, restrictions: &authz.TokenRestrictions{Scopes: []string{authz.ScopeSCIM}}, wantStatusCode: http.StatusForbidden},
+		"site admin":      {uid: 1, restrictions: &authz.TokenRestrictions{Scopes: []string{authz.ScopeSCIM}}, wantStatusCode: http.StatusOK},
+		"unauthenticated": {restrictions: &authz.TokenRestrictions{Scopes: []string{authz.ScopeSCIM}}, wantStatusCode: http.StatusForbidden},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := actor.WithActor(context.Background(), &actor.Actor{UID: test.uid})
+			if test.restrictions != nil {
+				ctx = authz.WithTokenRestrictions(ctx, test.restrictions)
+			}
+			rr := httptest.NewRecorder()
+			NewHandler().ServeHTTP(rr, httptest.NewRequest("GET", "/Users", nil).WithContext(ctx))
+			if rr.Code != test.wantStatusCode {
+				t.Errorf("got status %d, want %d (body %q)", rr.Code, test.wantStatusCode, rr.Body.String())
+			}
+			if got, want := rr.Header().Get("Content-Type"), "application/scim+json"; got != want {
+				t.Errorf("got Content-Type %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestHandler_Users(t *testing.T) {
+	s := newFakeStore(t)
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	var created userResource
+	rr := do(t, "POST", "/Users", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "alice@example.com",
+		"name": {"givenName": "Alice", "familyName": "Smith"},
+		"emails": [{"value": "alice2@example.com"}, {"value": "alice@example.com", "primary": true}],
+		"active": true
+	}`, &created)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d (body %q)", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if created.UserName != "alice" || created.DisplayName != "Alice Smith" || created.Active == nil || !*created.Active {
+		t.Errorf("got unexpected user %+v", created)
+	}
+	if want := []userEmail{{Value: "alice@example.com", Primary: true}, {Value: "alice2@example.com"}}; !emailsEqual(created.Emails, want) {
+		t.Errorf("got emails %+v, want %+v", created.Emails, want)
+	}
+	if got, want := rr.Header().Get("Location"), "http://example.com/.api/scim/v2/Users/"+created.ID; got != want {
+		t.Errorf("got Location %q, want %q", got, want)
+	}
+
+	t.Run("conflict", func(t *testing.T) {
+		rr := do(t, "POST", "/Users", `{"userName": "bob", "emails": [{"value": "alice@example.com"}]}`, nil)
+		if rr.Code != http.StatusConflict {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("list with filter", func(t *testing.T) {
+		for filter, wantTotal := range map[string]int{
+			`userName eq "alice@example.com"`:      1,
+			`userName eq "alice"`:                  1,
+			`emails.value eq "alice2@example.com"`: 1,
+			`userName eq "carol"`:                  0,
+		} {
+			var resp listResponse
+			do(t, "GET", "/Users?filter="+url.QueryEscape(filter), "", &resp)
+			if resp.TotalResults != wantTotal || len(resp.Resources) != wantTotal {
+				t.Errorf("filter %s: got %d results (%d resources), want %d", filter, resp.TotalResults, len(resp.Resources), wantTotal)
+			}
+		}
+		if rr := do(t, "GET", "/Users?filter="+url.QueryEscape(`title eq "x"`), "", nil); rr.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		var resp listResponse
+		do(t, "GET", "/Users?startIndex=2&count=1", "", &resp)
+		if resp.TotalResults != 2 || resp.StartIndex != 2 || resp.ItemsPerPage != 1 {
+			t.Errorf("got %+v", resp)
+		}
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		var patched userResource
+		rr := do(t, "PATCH", "/Users/"+created.ID, `{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [
+				{"op": "Replace", "path": "displayName", "value": "Alice Jones"},
+				{"op": "Remove", "path": "emails[value eq \"alice2@example.com\"]"},
+				{"op": "Add", "path": "emails", "value": [{"value": "alice3@example.com"}]},
+				{"op": "Replace", "value": {"active": "False"}}
+			]
+		}`, &patched)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body %q)", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if patched.DisplayName != "Alice Jones" || patched.Active == nil || *patched.Active {
+			t.Errorf("got unexpected user %+v", patched)
+		}
+		if want := []userEmail{{Value: "alice@example.com", Primary: true}, {Value: "alice3@example.com"}}; !emailsEqual(patched.Emails, want) {
+			t.Errorf("got emails %+v, want %+v", patched.Emails, want)
+		}
+		userID, _ := parseID(created.ID)
+		for _, e := range s.emails[userID] {
+			if e.VerifiedAt == nil {
+				t.Errorf("email %q is not verified", e.Email)
+			}
+		}
+		if want := []string{"user.suspend user:" + created.ID}; !stringsEqual(s.audit, want) {
+			t.Errorf("got audit log %q, want %q", s.audit, want)
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		var replaced userResource
+		rr := do(t, "PUT", "/Users/"+created.ID, `{"userName": "alice.smith", "displayName": "Alice", "active": true}`, &replaced)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body %q)", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if replaced.UserName != "alice.smith" || replaced.DisplayName != "Alice" || !*replaced.Active || len(replaced.Emails) != 2 {
+			t.Errorf("got unexpected user %+v", replaced)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		s.audit = nil
+		if rr := do(t, "DELETE", "/Users/"+created.ID, "", nil); rr.Code != http.StatusNoContent {
+			t.Fatalf("got status %d, want %d", rr.Code, http.StatusNoContent)
+		}
+		var got userResource
+		do(t, "GET", "/Users/"+created.ID, "", &got)
+		if got.Active == nil || *got.Active {
+			t.Errorf("got active user after deletion")
+		}
+		if want := []string{"user.suspend user:" + created.ID}; !stringsEqual(s.audit, want) {
+			t.Errorf("got audit log %q, want %q", s.audit, want)
+		}
+	})
+
+	t.Run("suspend self", func(t *testing.T) {
+		if rr := do(t, "DELETE", "/Users/1", "", nil); rr.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusBadRequest)
+		}
+		if s.users[1].SuspendedAt != nil {
+			t.Error("suspended the token's user")
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		for _, path := range []string{"/Users/12345", "/Users/x"} {
+			var resp struct {
+				Schemas []string
+				Status  string
+			}
+			if rr := do(t, "GET", path, "", &resp); rr.Code != http.StatusNotFound || resp.Status != "404" || resp.Schemas[0] != schemaError {
+				t.Errorf("%s: got status %d, body %q", path, rr.Code, rr.Body.String())
+			}
+		}
+	})
+}
+
+func TestHandler_Groups(t *testing.T) {
+	s := newFakeStore(t)
+	s.users[2] = &types.User{ID: 2, Username: "alice"}
+	s.users[3] = &types.User{ID: 3, Username: "bob"}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	var created groupResource
+	rr := do(t, "POST", "/Groups", `{"displayName": "Platform Team", "members": [{"value": "2"}]}`, &created)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d (body %q)", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	org := s.orgs[s.nextID]
+	if org.Name != "Platform-Team" || created.DisplayName != "Platform Team" {
+		t.Errorf("got org %+v, group %+v", org, created)
+	}
+	if got := memberIDs(created); got != "2" {
+		t.Errorf("got members %s, want 2", got)
+	}
+
+	if rr := do(t, "POST", "/Groups", `{"displayName": "Platform Team"}`, nil); rr.Code != http.StatusConflict {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusConflict)
+	}
+	if rr := do(t, "POST", "/Groups", `{"displayName": "Other", "members": [{"value": "999"}]}`, nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+
+	var list listResponse
+	do(t, "GET", "/Groups?filter="+url.QueryEscape(`displayName eq "platform team"`), "", &list)
+	if list.TotalResults != 1 {
+		t.Errorf("got %d results, want 1", list.TotalResults)
+	}
+
+	var patched groupResource
+	do(t, "PATCH", "/Groups/"+created.ID, `{"Operations": [
+		{"op": "add", "path": "members", "value": [{"value": "3"}, {"value": "2"}]},
+		{"op": "replace", "path": "displayName", "value": "Platform"}
+	]}`, &patched)
+	if got := memberIDs(patched); got != "2,3" || patched.DisplayName != "Platform" {
+		t.Errorf("got group %+v", patched)
+	}
+	do(t, "PATCH", "/Groups/"+created.ID, `{"Operations": [{"op": "remove", "path": "members[value eq \"2\"]"}]}`, &patched)
+	if got := memberIDs(patched); got != "3" {
+		t.Errorf("got members %s, want 3", got)
+	}
+	do(t, "PATCH", "/Groups/"+created.ID, `{"Operations": [{"op": "remove", "path": "members", "value": [{"value": "3"}]}]}`, &patched)
+	if got := memberIDs(patched); got != "" {
+		t.Errorf("got members %s, want none", got)
+	}
+
+	if rr := do(t, "DELETE", "/Groups/"+created.ID, "", nil); rr.Code != http.StatusNoContent {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusNoContent)
+	}
+	if len(s.orgs) != 0 {
+		t.Error("org was not deleted")
+	}
+	if want := []string{backend.AuditOrgDelete + " org:" + created.ID}; !stringsEqual(s.audit, want) {
+		t.Errorf("got audit log %q, want %q", s.audit, want)
+	}
+}
+
+func memberIDs(g groupResource) string {
+	var ids []string
+	for _, m := range g.Members {
+		ids = append(ids, m.Value)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+func emailsEqual(a, b []userEmail) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func stringsEqual(a, b []string) bool {
+	return strings.Join(a, "\n") == strings.Join(b, "\n")
+}
@@ -0,0 +1,461 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+// userResource is the SCIM representation of a user.
+//
+// The user's display name is the displayName attribute or, if that is empty, the name attribute.
+// The emails attribute is authoritative: all of the user's email addresses are verified, and
+// email addresses not in it are removed (unless it is absent). The externalId attribute is not
+// stored.
+type userResource struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	UserName    string      `json:"userName"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Name        *userName   `json:"name,omitempty"`
+	Emails      []userEmail `json:"emails,omitempty"`
+	Active      *boolean    `json:"active,omitempty"`
+	Meta        *meta       `json:"meta,omitempty"`
+}
+
+type userName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type userEmail struct {
+	Value   string  `json:"value"`
+	Type    string  `json:"type,omitempty"`
+	Primary boolean `json:"primary,omitempty"`
+}
+
+// boolean is a boolean that may also be given as a string ("True" or "False"), which some identity
+// providers send.
+type boolean bool
+
+func (b *boolean) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := strconv.ParseBool(strings.ToLower(s))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		*b = boolean(v)
+		return nil
+	}
+	return json.Unmarshal(data, (*bool)(b))
+}
+
+// displayName returns the user's display name.
+func (u *userResource) displayName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name == nil {
+		return ""
+	}
+	if u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+	return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+}
+
+// emailAddresses returns the user's distinct email addresses, primary first.
+func (u *userResource) emailAddresses() []string {
+	var emails []string
+	add := func(email string) {
+		email = strings.TrimSpace(email)
+		if email == "" {
+			return
+		}
+		for _, e := range emails {
+			if strings.EqualFold(e, email) {
+				return
+			}
+		}
+		emails = append(emails, email)
+	}
+	for _, e := range u.Emails {
+		if e.Primary {
+			add(e.Value)
+		}
+	}
+	for _, e := range u.Emails {
+		add(e.Value)
+	}
+	return emails
+}
+
+// toUserResource returns the SCIM representation of the user.
+func toUserResource(ctx context.Context, user *types.User) (*userResource, error) {
+	emails, err := db.UserEmails.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	primaryEmail, _, err := db.UserEmails.GetPrimaryEmail(ctx, user.ID)
+	if err != nil && !errcode.IsNotFound(err) {
+		return nil, err
+	}
+
+	active := boolean(user.SuspendedAt == nil)
+	res := &userResource{
+		Schemas:     []string{schemaUser},
+		ID:          strconv.Itoa(int(user.ID)),
+		UserName:    user.Username,
+		DisplayName: user.DisplayName,
+		Active:      &active,
+		Meta: &meta{
+			ResourceType: "User",
+			Created:      user.CreatedAt.Format(time.RFC3339),
+			LastModified: user.UpdatedAt.Format(time.RFC3339),
+			Location:     location("Users", user.ID),
+		},
+	}
+	if user.DisplayName != "" {
+		res.Name = &userName{Formatted: user.DisplayName}
+	}
+	for _, e := range emails {
+		res.Emails = append(res.Emails, userEmail{Value: e.Email, Primary: boolean(e.Email == primaryEmail)})
+	}
+	return res, nil
+}
+
+func writeUser(ctx context.Context, w http.ResponseWriter, status int, user *types.User) error {
+	res, err := toUserResource(ctx, user)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusCreated {
+		w.Header().Set("Location", res.Meta.Location)
+	}
+	return writeJSON(w, status, res)
+}
+
+func getUser(ctx context.Context, id string) (*types.User, error) {
+	userID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	return db.Users.GetByID(ctx, userID)
+}
+
+func serveListUsers(w http.ResponseWriter, r *http.Request) error {
+	startIndex, count, err := pagination(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		users []*types.User
+		total int
+	)
+	if filter := r.URL.Query().Get("filter"); filter != "" {
+		f, err := parseFilter(filter)
+		if err != nil {
+			return err
+		}
+		var user *types.User
+		switch strings.ToLower(f.Attr) {
+		case "username":
+			// Look up the username that the userName would be normalized to when creating the
+			// user, so that identity providers find users they created.
+			if username, normErr := auth.NormalizeUsername(f.Value); normErr == nil {
+				user, err = db.Users.GetByUsername(r.Context(), username)
+			}
+		case "emails", "emails.value":
+			user, err = db.Users.GetByVerifiedEmail(r.Context(), f.Value)
+		default:
+			return badRequest("invalidFilter", "filtering by attribute %q is not supported (supported attributes: userName, emails.value)", f.Attr)
+		}
+		if err != nil && !errcode.IsNotFound(err) {
+			return err
+		}
+		if user != nil {
+			total = 1
+			if startIndex == 1 && count > 0 {
+				users = []*types.User{user}
+			}
+		}
+	} else {
+		total, err = db.Users.Count(r.Context(), nil)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			users, err = db.Users.List(r.Context(), &db.UsersListOptions{LimitOffset: &db.LimitOffset{Limit: count, Offset: startIndex - 1}})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	resp := listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(users),
+		Resources:    []interface{}{},
+	}
+	for _, user := range users {
+		res, err := toUserResource(r.Context(), user)
+		if err != nil {
+			return err
+		}
+		resp.Resources = append(resp.Resources, res)
+	}
+	return writeJSON(w, http.StatusOK, resp)
+}
+
+func serveGetUser(w http.ResponseWriter, r *http.Request, id string) error {
+	user, err := getUser(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	return writeUser(r.Context(), w, http.StatusOK, user)
+}
+
+func serveCreateUser(w http.ResponseWriter, r *http.Request) error {
+	var res userResource
+	if err := readJSON(r, &res); err != nil {
+		return err
+	}
+	username, err := normalizeUsername(res.UserName)
+	if err != nil {
+		return err
+	}
+	emails := res.emailAddresses()
+	if err := checkEmailsAvailable(r.Context(), 0, emails); err != nil {
+		return err
+	}
+
+	newUser := db.NewUser{
+		Username:    username,
+		DisplayName: res.displayName(),
+		// 🚨 SECURITY: The identity provider is trusted to have verified the email addresses.
+		EmailIsVerified: true,
+	}
+	if len(emails) > 0 {
+		newUser.Email = emails[0]
+	}
+	user, err := db.Users.Create(r.Context(), newUser)
+	if err != nil {
+		if db.IsUsernameExists(err) || db.IsEmailExists(err) {
+			return &scimError{Status: http.StatusConflict, ScimType: "uniqueness", Detail: err.Error()}
+		}
+		return err
+	}
+	if len(emails) > 1 {
+		if err := setEmails(r.Context(), user.ID, emails); err != nil {
+			return err
+		}
+	}
+	if res.Active != nil && !*res.Active {
+		if err := setSuspended(r.Context(), user.ID, true); err != nil {
+			return err
+		}
+		if user, err = db.Users.GetByID(r.Context(), user.ID); err != nil {
+			return err
+		}
+	}
+	return writeUser(r.Context(), w, http.StatusCreated, user)
+}
+
+func serveReplaceUser(w http.ResponseWriter, r *http.Request, id string) error {
+	user, err := getUser(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	var res userResource
+	if err := readJSON(r, &res); err != nil {
+		return err
+	}
+	return updateUser(r.Context(), w, user, &res)
+}
+
+func servePatchUser(w http.ResponseWriter, r *http.Request, id string) error {
+	user, err := getUser(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	var req patchRequest
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	res, err := toUserResource(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	if err := patch(res, &req); err != nil {
+		return err
+	}
+	return updateUser(r.Context(), w, user, res)
+}
+
+func serveDeleteUser(w http.ResponseWriter, r *http.Request, id string) error {
+	// Users are suspended, not deleted, so that their data (and their identity, in the history of
+	// the content they authored) is retained.
+	user, err := getUser(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	if user.SuspendedAt == nil {
+		if err := setSuspended(r.Context(), user.ID, true); err != nil {
+			return err
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// updateUser updates the user to match the SCIM representation and writes the updated
+// representation.
+func updateUser(ctx context.Context, w http.ResponseWriter, user *types.User, res *userResource) error {
+	username, err := normalizeUsername(res.UserName)
+	if err != nil {
+		return err
+	}
+	var (
+		update      db.UserUpdate
+		needsUpdate bool
+	)
+	if username != user.Username {
+		update.Username = username
+		needsUpdate = true
+	}
+	if displayName := res.displayName(); displayName != user.DisplayName {
+		update.DisplayName = &displayName
+		needsUpdate = true
+	}
+	if needsUpdate {
+		if err := db.Users.Update(ctx, user.ID, update); err != nil {
+			if db.IsUsernameExists(err) {
+				return &scimError{Status: http.StatusConflict, ScimType: "uniqueness", Detail: err.Error()}
+			}
+			return err
+		}
+	}
+
+	if res.Emails != nil {
+		emails := res.emailAddresses()
+		if err := checkEmailsAvailable(ctx, user.ID, emails); err != nil {
+			return err
+		}
+		if err := setEmails(ctx, user.ID, emails); err != nil {
+			return err
+		}
+	}
+
+	if res.Active != nil && bool(*res.Active) == (user.SuspendedAt != nil) {
+		if err := setSuspended(ctx, user.ID, !bool(*res.Active)); err != nil {
+			return err
+		}
+	}
+
+	user, err = db.Users.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	return writeUser(ctx, w, http.StatusOK, user)
+}
+
+func normalizeUsername(userName string) (string, error) {
+	if userName == "" {
+		return "", badRequest("invalidValue", "userName is required")
+	}
+	username, err := auth.NormalizeUsername(userName)
+	if err != nil {
+		return "", badRequest("invalidValue", "invalid userName %q: %s", userName, err)
+	}
+	return username, nil
+}
+
+// checkEmailsAvailable returns an error if any of the email addresses is a verified email address
+// of a user other than the user with the ID (or any user, if userID is 0).
+func checkEmailsAvailable(ctx context.Context, userID int32, emails []string) error {
+	for _, email := range emails {
+		user, err := db.Users.GetByVerifiedEmail(ctx, email)
+		if err != nil {
+			if errcode.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if user.ID != userID {
+			return &scimError{Status: http.StatusConflict, ScimType: "uniqueness", Detail: fmt.Sprintf("email address %q is used by another user", email)}
+		}
+	}
+	return nil
+}
+
+// setEmails sets the user's email addresses, which are all verified, to emails.
+func setEmails(ctx context.Context, userID int32, emails []string) error {
+	existing, err := db.UserEmails.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, email := range emails {
+		var found *db.UserEmail
+		for _, e := range existing {
+			if strings.EqualFold(e.Email, email) {
+				found = e
+				break
+			}
+		}
+		if found == nil {
+			if err := db.UserEmails.Add(ctx, userID, email, nil); err != nil {
+				return err
+			}
+		} else if found.VerifiedAt != nil {
+			continue
+		} else {
+			email = found.Email
+		}
+		// 🚨 SECURITY: The identity provider is trusted to have verified the email addresses.
+		if err := db.UserEmails.SetVerified(ctx, userID, email, true); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range existing {
+		var keep bool
+		for _, email := range emails {
+			if strings.EqualFold(e.Email, email) {
+				keep = true
+				break
+			}
+		}
+		if !keep {
+			if err := db.UserEmails.Remove(ctx, userID, e.Email); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setSuspended suspends or unsuspends the user.
+func setSuspended(ctx context.Context, userID int32, suspended bool) error {
+	// Suspending the user of the access token would make the SCIM API unusable.
+	if suspended && userID == actor.FromContext(ctx).UID {
+		return badRequest("mutability", "refusing to suspend the user of the access token used for SCIM")
+	}
+	return backend.SetUserSuspended(ctx, userID, suspended, "scim")
+}
@@ -56,6 +56,7 @@ type sessionInfo struct {
 	Actor        *actor.Actor  `json:"actor"`
 	LastActive   time.Time     `json:"lastActive"`
 	ExpiryPeriod time.Duration `json:"expiryPeriod"`
+	LoginTime    time.Time     `json:"loginTime"` // when the session was created
 }
 
 // SetSessionStore sets the backing store used for storing sessions on the server. It should be called exactly once.
@@ -210,7 +211,8 @@ func SetActor(w http.ResponseWriter, r *http.Request, actor *actor.Actor, expiry
 				expiryPeriod = defaultExpiryPeriod
 			}
 		}
-		value = &sessionInfo{Actor: actor, ExpiryPeriod: expiryPeriod, LastActive: time.Now()}
+		now := time.Now()
+		value = &sessionInfo{Actor: actor, ExpiryPeriod: expiryPeriod, LastActive: now, LoginTime: now}
 	}
 	return SetData(w, r, "actor", value)
 }
@@ -330,7 +332,8 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 		}
 
 		// Check that user still exists.
-		if _, err := db.Users.GetByID(r.Context(), info.Actor.UID); err != nil {
+		user, err := db.Users.GetByID(r.Context(), info.Actor.UID)
+		if err != nil {
 			if errcode.IsNotFound(err) {
 				_ = deleteSession(w, r) // clear the bad value
 			} else {
@@ -341,6 +344,13 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 			return r.Context() // not authenticated
 		}
 
+		// Check that the user is not suspended and that the session was created after the
+		// user's sessions were last invalidated.
+		if user.SuspendedAt != nil || (user.InvalidatedSessionsAt != nil && info.LoginTime.Before(*user.InvalidatedSessionsAt)) {
+			_ = deleteSession(w, r)
+			return r.Context() // not authenticated
+		}
+
 		// Renew session
 		if time.Since(info.LastActive) > 5*time.Minute {
 			info.LastActive = time.Now()
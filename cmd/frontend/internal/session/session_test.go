@@ -174,14 +174,20 @@ func TestCookieMiddleware(t *testing.T) {
 	cleanup := ResetMockSessionStore(t)
 	defer cleanup()
 
-	actors := []*actor.Actor{{UID: 123, FromSessionCookie: true}, {UID: 456}, {UID: 789}}
+	actors := []*actor.Actor{{UID: 123, FromSessionCookie: true}, {UID: 456}, {UID: 789}, {UID: 1011}, {UID: 1213}}
 
+	now := time.Now()
+	later := now.Add(time.Minute)
 	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
-		if id == actors[0].UID {
-			return &types.User{ID: id}, nil
-		}
-		if id == actors[1].UID {
+		switch id {
+		case actors[0].UID:
+			return &types.User{ID: id, InvalidatedSessionsAt: &now}, nil // invalidated before session was created
+		case actors[1].UID:
 			return nil, &errcode.Mock{IsNotFound: true}
+		case actors[3].UID:
+			return &types.User{ID: id, SuspendedAt: &now}, nil
+		case actors[4].UID:
+			return &types.User{ID: id, InvalidatedSessionsAt: &later}, nil
 		}
 		return nil, errors.New("x") // other error
 	}
@@ -224,6 +230,16 @@ func TestCookieMiddleware(t *testing.T) {
 			req:      authedReqs[2],
 			expActor: &actor.Actor{},
 		},
+		{
+			req:      authedReqs[3],
+			expActor: &actor.Actor{},
+			deleted:  true,
+		},
+		{
+			req:      authedReqs[4],
+			expActor: &actor.Actor{},
+			deleted:  true,
+		},
 	}
 	for _, testcase := range testcases {
 		rr := httptest.NewRecorder()
@@ -24,6 +24,15 @@ type RepoFields struct {
 
 	// Fork is whether this repository is a fork of another repository.
 	Fork bool
+
+	// MissingUpstreamAt is when this repository went missing from its code host,
+	// or nil if it isn't missing. Such repositories are deleted after a grace period.
+	MissingUpstreamAt *time.Time
+
+	// Quarantined is whether this repository went missing from its code host in a
+	// sync that removed an unusually large share of its external service's
+	// repositories. Quarantined repositories aren't deleted automatically.
+	Quarantined bool
 }
 
 // Repo represents a source code repository.
@@ -78,6 +87,15 @@ type User struct {
 	UpdatedAt   time.Time
 	SiteAdmin   bool
 	Tags        []string
+
+	// SuspendedAt is when the user was suspended, or nil if the user is not suspended. Suspended
+	// users can't sign in or use access tokens, and they don't count toward the licensed user
+	// count.
+	SuspendedAt *time.Time
+
+	// InvalidatedSessionsAt is when the user's sessions were last invalidated. Sessions started
+	// before then are no longer valid.
+	InvalidatedSessionsAt *time.Time
 }
 
 type Org struct {
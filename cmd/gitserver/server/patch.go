@@ -182,6 +182,22 @@ func (s *Server) handleCreateCommitFromPatch(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if req.Push {
+		// We push to the remote origin, which is the last known working
+		// URL of the repository, and never log the output since it may
+		// contain credentials. We never force push, so that we can't
+		// overwrite commits of an existing branch.
+		cmd = exec.CommandContext(ctx, "git", "push", "origin", fmt.Sprintf("%s:%s", cmtHash, req.TargetRef))
+		cmd.Dir = repoGitDir
+
+		if _, err = runWithRemoteOpts(ctx, cmd, nil); err != nil {
+			log15.Error("Failed to push commit.", "ref", req.TargetRef, "commit", cmtHash, "error", err)
+
+			http.Error(w, "gitserver: pushing ref - "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
 	sendResp(w, "refs/"+ref)
 }
 
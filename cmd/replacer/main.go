@@ -1,5 +1,6 @@
 // Command replacer is an interface to replace and rewrite code. It passes a zipped repo
-// to external tools and streams back JSON lines results.
+// to a rewrite engine (comby, regular expressions or sandboxed scripts) and streams back
+// JSON lines results.
 package main
 
 import (
@@ -12,6 +13,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/opentracing-contrib/go-stdlib/nethttp"
@@ -30,6 +32,9 @@ import (
 
 var cacheDir = env.Get("CACHE_DIR", "/tmp", "directory to store cached archives.")
 var cacheSizeMB = env.Get("REPLACER_CACHE_SIZE_MB", "100000", "maximum size of the on disk cache in megabytes")
+var scriptRuntime = env.Get("REPLACER_SCRIPT_RUNTIME", "", "container runtime (e.g. docker) to sandbox user-provided rewrite scripts with. The script engine is disabled if empty.")
+var scriptImage = env.Get("REPLACER_SCRIPT_IMAGE", "alpine:3.10", "default container image to run user-provided rewrite scripts in")
+var scriptAllowedImages = env.Get("REPLACER_SCRIPT_ALLOWED_IMAGES", "", "comma-separated list of other container images that user-provided rewrite scripts may request to run in")
 
 const port = "3185"
 
@@ -61,6 +66,22 @@ func main() {
 		Store: &store,
 		Log:   log15.Root(),
 	}
+	if scriptRuntime != "" {
+		service.Script = &replace.Script{
+			Runtime:      scriptRuntime,
+			DefaultImage: scriptImage,
+		}
+		for _, image := range strings.Split(scriptAllowedImages, ",") {
+			if image = strings.TrimSpace(image); image != "" {
+				service.Script.AllowedImages = append(service.Script.AllowedImages, image)
+			}
+		}
+		for _, image := range append([]string{scriptImage}, service.Script.AllowedImages...) {
+			if err := replace.ValidImageReference(image); err != nil {
+				log.Fatalf("invalid REPLACER_SCRIPT_IMAGE or REPLACER_SCRIPT_ALLOWED_IMAGES: %s", err)
+			}
+		}
+	}
 	handler := nethttp.Middleware(opentracing.GlobalTracer(), service)
 
 	host := ""
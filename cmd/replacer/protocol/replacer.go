@@ -37,7 +37,24 @@ type Request struct {
 	RewriteSpecification
 }
 
+// The rewrite engines supported by replacer.
+const (
+	// EngineComby rewrites with comby's structural match and rewrite
+	// templates. It's the default engine.
+	EngineComby = "comby"
+	// EngineRegexp rewrites the matches of a regular expression in
+	// MatchTemplate with RewriteTemplate, which may reference capture groups
+	// as $1 or ${name}.
+	EngineRegexp = "regexp"
+	// EngineScript runs Script, or the entrypoint of Image, in a sandboxed
+	// container over a checkout of the repository.
+	EngineScript = "script"
+)
+
 type RewriteSpecification struct {
+	// The rewrite engine to use. Defaults to EngineComby.
+	Engine string
+
 	// A template pattern that expresses what to match.
 	MatchTemplate string
 
@@ -49,6 +66,12 @@ type RewriteSpecification struct {
 
 	// A directory prefix to exclude (e.g., vendor)
 	DirectoryExclude string
+
+	// The container image EngineScript runs in (optional).
+	Image string
+
+	// The shell script EngineScript runs (optional).
+	Script string
 }
 
 // GitserverRepo returns the repository information necessary to perform gitserver requests.
@@ -0,0 +1,101 @@
+package replace
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+type diffLine struct {
+	op   diffmatchpatch.Operation
+	text string
+}
+
+// unifiedDiff returns the unified diff between the old and new contents of
+// the file with the given name, formatted like the diffs comby emits.
+func unifiedDiff(name, old, new string) string {
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToChars(old, new)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lines)
+
+	var ls []diffLine
+	for _, d := range diffs {
+		for _, text := range splitLines(d.Text) {
+			ls = append(ls, diffLine{op: d.Type, text: text})
+		}
+	}
+
+	// Keep the changed lines and the unchanged lines within diffContext of
+	// them. Runs of kept lines form the hunks.
+	keep := make([]bool, len(ls))
+	for i, l := range ls {
+		if l.op == diffmatchpatch.DiffEqual {
+			continue
+		}
+		for j := i - diffContext; j <= i+diffContext; j++ {
+			if j >= 0 && j < len(ls) {
+				keep[j] = true
+			}
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s", name, name)
+
+	var oldLine, newLine int
+	for i := 0; i < len(ls); {
+		if !keep[i] {
+			oldLine, newLine = oldLine+1, newLine+1
+			i++
+			continue
+		}
+
+		var body strings.Builder
+		oldStart, newStart := oldLine, newLine
+		for ; i < len(ls) && keep[i]; i++ {
+			l := ls[i]
+			switch l.op {
+			case diffmatchpatch.DiffEqual:
+				body.WriteString("\n ")
+				oldLine, newLine = oldLine+1, newLine+1
+			case diffmatchpatch.DiffDelete:
+				body.WriteString("\n-")
+				oldLine++
+			case diffmatchpatch.DiffInsert:
+				body.WriteString("\n+")
+				newLine++
+			}
+			body.WriteString(strings.TrimSuffix(l.text, "\n"))
+			if !strings.HasSuffix(l.text, "\n") {
+				body.WriteString("\n\\ No newline at end of file")
+			}
+		}
+
+		fmt.Fprintf(&out, "\n@@ -%s +%s @@", hunkRange(oldStart, oldLine-oldStart), hunkRange(newStart, newLine-newStart))
+		out.WriteString(body.String())
+	}
+
+	return out.String()
+}
+
+// hunkRange formats the range of a hunk that starts after the given number
+// of lines and spans n lines.
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+// splitLines splits s after each newline.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
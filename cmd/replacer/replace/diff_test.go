@@ -0,0 +1,44 @@
+package replace
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "single change",
+			old:  "a\nb\nc\n",
+			new:  "a\nB\nc\n",
+			want: "--- f\n+++ f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: "--- f\n+++ f\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten",
+		},
+		{
+			name: "insertion into empty file",
+			old:  "",
+			new:  "a\n",
+			want: "--- f\n+++ f\n@@ -0,0 +1,1 @@\n+a",
+		},
+		{
+			name: "no newline at end of file",
+			old:  "a\nb",
+			new:  "a\nc",
+			want: "--- f\n+++ f\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if have := unifiedDiff("f", tc.old, tc.new); have != tc.want {
+				t.Errorf("have:\n%s\nwant:\n%s", have, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package replace
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/store"
+)
+
+// An Engine rewrites the files of a repository archive. It writes the diff
+// of every file it changes to w as a JSON line with "uri" and "diff" keys,
+// which is the format of comby's -json-lines output.
+type Engine interface {
+	Replace(ctx context.Context, spec *protocol.RewriteSpecification, zipPath string, zf *store.ZipFile, w io.Writer) error
+}
+
+// engine returns the Engine requested by the given RewriteSpecification.
+func (s *Service) engine(spec *protocol.RewriteSpecification) (Engine, error) {
+	switch spec.Engine {
+	case "", protocol.EngineComby:
+		return &ExternalTool{Name: "comby", BinaryPath: "comby"}, nil
+
+	case protocol.EngineRegexp:
+		re, err := regexp.Compile(spec.MatchTemplate)
+		if err != nil {
+			return nil, badRequestError{"MatchTemplate is not a valid regular expression: " + err.Error()}
+		}
+		return &Regexp{Regexp: re}, nil
+
+	case protocol.EngineScript:
+		if s.Script == nil {
+			return nil, badRequestError{"the script engine is disabled"}
+		}
+		if spec.Image == "" && spec.Script == "" {
+			return nil, badRequestError{"Image or Script must be non-empty"}
+		}
+		if err := s.Script.checkImage(spec.Image); err != nil {
+			return nil, badRequestError{err.Error()}
+		}
+		return s.Script, nil
+
+	default:
+		return nil, badRequestError{"unknown engine " + spec.Engine}
+	}
+}
+
+// result is a single JSON line written by an Engine.
+type result struct {
+	URI  string `json:"uri"`
+	Diff string `json:"diff"`
+}
+
+// writeResult writes the diff between the old and new contents of the file
+// with the given name to w as a JSON line.
+func writeResult(w io.Writer, name string, old, new []byte) error {
+	b, err := json.Marshal(result{URI: name, Diff: unifiedDiff(name, string(old), string(new))})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// includeFile returns true if the file with the given name passes the file
+// filters of the given RewriteSpecification, which are applied the same way
+// comby applies them.
+func includeFile(spec *protocol.RewriteSpecification, name string) bool {
+	if strings.HasSuffix(name, "/") {
+		return false
+	}
+
+	if spec.FileExtension != "" && !strings.HasSuffix(name, spec.FileExtension) {
+		return false
+	}
+
+	if spec.DirectoryExclude != "" {
+		dir := spec.DirectoryExclude + "/"
+		if strings.HasPrefix(name, dir) || strings.Contains(name, "/"+dir) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type badRequestError struct{ msg string }
+
+func (e badRequestError) Error() string    { return e.msg }
+func (e badRequestError) BadRequest() bool { return true }
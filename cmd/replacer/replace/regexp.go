@@ -0,0 +1,50 @@
+package replace
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/store"
+)
+
+// Regexp is an Engine that replaces the matches of a regular expression
+// with a template that may reference its capture groups, e.g. $1 or
+// ${name}. It runs in process over the archive and skips binary files.
+type Regexp struct {
+	*regexp.Regexp
+}
+
+// Replace implements the Engine interface.
+func (e *Regexp) Replace(ctx context.Context, spec *protocol.RewriteSpecification, _ string, zf *store.ZipFile, w io.Writer) error {
+	template := []byte(spec.RewriteTemplate)
+
+	for i := range zf.Files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		f := &zf.Files[i]
+		if !includeFile(spec, f.Name) {
+			continue
+		}
+
+		old := zf.DataFor(f)
+		if bytes.IndexByte(old, 0) >= 0 {
+			continue
+		}
+
+		new := e.ReplaceAll(old, template)
+		if bytes.Equal(old, new) {
+			continue
+		}
+
+		if err := writeResult(w, f.Name, old, new); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -9,9 +9,9 @@
 // * On disk cache of fetched archives to reduce load on gitserver
 //
 // - Here is where replacer.go differs
-// * Pass the zip file path to external replacer tool(s) after validating
-// * Read tool stdout and write it out on the HTTP connection
-// * Input from stdout is expected to use JSON lines format, but the format isn't checked here: line-buffering is done on the frontend
+// * Pass the zip file to the requested rewrite Engine after validating
+// * Engines write JSON lines results out on the HTTP connection
+// * External tools like comby are expected to use JSON lines format, but the format isn't checked here: line-buffering is done on the frontend
 
 package replace
 
@@ -42,15 +42,54 @@ import (
 type Service struct {
 	Store *store.Store
 	Log   log15.Logger
+
+	// Script is the Engine used for EngineScript requests. The script engine
+	// is disabled if it's nil.
+	Script *Script
 }
 
+// ExternalTool is an Engine that runs an external tool over the zip file.
 type ExternalTool struct {
 	Name       string
 	BinaryPath string
 }
 
+// Replace implements the Engine interface.
+func (t *ExternalTool) Replace(ctx context.Context, spec *protocol.RewriteSpecification, zipPath string, _ *store.ZipFile, w io.Writer) error {
+	cmd, err := t.command(ctx, spec, zipPath)
+	if err != nil {
+		log15.Info("Invalid command: " + err.Error())
+		return err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		log15.Info("Could not connect to command stdout: " + err.Error())
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		log15.Info("Error starting command: " + err.Error())
+		return errors.New(err.Error())
+	}
+
+	_, err = io.Copy(w, stdout)
+	if err != nil {
+		log15.Info("Error copying external command output to HTTP writer: " + err.Error())
+		return err
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			log15.Info("Error after executing command: " + string(exitErr.Stderr))
+		}
+	}
+
+	return nil
+}
+
 // Configure the command line options and return the command to execute using an external tool
-func (t *ExternalTool) command(spec *protocol.RewriteSpecification, zipPath string) (cmd *exec.Cmd, err error) {
+func (t *ExternalTool) command(ctx context.Context, spec *protocol.RewriteSpecification, zipPath string) (cmd *exec.Cmd, err error) {
 	switch t.Name {
 	case "comby":
 		_, err = exec.LookPath("comby")
@@ -72,7 +111,7 @@ func (t *ExternalTool) command(spec *protocol.RewriteSpecification, zipPath stri
 		}
 
 		log15.Info(fmt.Sprintf("running command: comby %q", strings.Join(args[:], " ")))
-		return exec.Command(t.BinaryPath, args...), nil
+		return exec.CommandContext(ctx, t.BinaryPath, args...), nil
 
 	default:
 		return nil, errors.Errorf("Unknown external replace tool %q.", t.Name)
@@ -110,7 +149,13 @@ func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deadlineHit, err := s.replace(ctx, &p, w, r)
+	engine, err := s.engine(&p.RewriteSpecification)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deadlineHit, err := s.replace(ctx, &p, engine, w, r)
 	if err != nil {
 		code := http.StatusInternalServerError
 		if isBadRequest(err) || ctx.Err() == context.Canceled {
@@ -131,7 +176,7 @@ func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Service) replace(ctx context.Context, p *protocol.Request, w http.ResponseWriter, r *http.Request) (deadlineHit bool, err error) {
+func (s *Service) replace(ctx context.Context, p *protocol.Request, engine Engine, w http.ResponseWriter, r *http.Request) (deadlineHit bool, err error) {
 	tr := trace.New("replace", fmt.Sprintf("%s@%s", p.Repo, p.Commit))
 	tr.LazyPrintf("%s", p.RewriteSpecification)
 
@@ -214,36 +259,8 @@ func (s *Service) replace(ctx context.Context, p *protocol.Request, w http.Respo
 	w.Header().Set("Transfer-Encoding", "chunked")
 	w.WriteHeader(http.StatusOK)
 
-	t := &ExternalTool{
-		Name:       "comby",
-		BinaryPath: "comby",
-	}
-
-	cmd, err := t.command(&p.RewriteSpecification, zipPath)
-	if err != nil {
-		log15.Info("Invalid command: " + err.Error())
-		return
-	}
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		log15.Info("Could not connect to command stdout: " + err.Error())
-		return
-	}
-
-	if err := cmd.Start(); err != nil {
-		log15.Info("Error starting command: " + err.Error())
-		return false, errors.New(err.Error())
-	}
-
-	_, err = io.Copy(w, stdout)
-	if err != nil {
-		log15.Info("Error copying external command output to HTTP writer: " + err.Error())
-		return
-	}
-
-	if err := cmd.Wait(); err != nil {
-		log15.Info("Error after executing command: " + string(err.(*exec.ExitError).Stderr))
+	if err = engine.Replace(ctx, &p.RewriteSpecification, zipPath, zf, w); err != nil {
+		return false, err
 	}
 
 	return false, nil
@@ -258,7 +275,7 @@ func validateParams(p *protocol.Request) error {
 		return errors.Errorf("Commit must be resolved (Commit=%q)", p.Commit)
 	}
 
-	if p.RewriteSpecification.MatchTemplate == "" {
+	if p.RewriteSpecification.Engine != protocol.EngineScript && p.RewriteSpecification.MatchTemplate == "" {
 		return errors.New("MatchTemplate must be non-empty")
 	}
 	return nil
@@ -95,6 +95,81 @@ func main() {
 	}
 }
 
+func TestReplace_regexp(t *testing.T) {
+	files := map[string]string{
+		"README.md": `# Hello World
+
+Hello world example in go`,
+		"main.go": `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("Hello foo")
+}
+`,
+		"vendor/foo.go": `package foo
+
+func Foo() { fmt.Println("Hello foo") }
+`,
+	}
+
+	cases := []struct {
+		arg  protocol.RewriteSpecification
+		want string
+	}{
+		{protocol.RewriteSpecification{
+			Engine:           protocol.EngineRegexp,
+			MatchTemplate:    `Hello (\w+)"`,
+			RewriteTemplate:  `Goodbye ${1}"`,
+			FileExtension:    ".go",
+			DirectoryExclude: "vendor",
+		}, `
+{"uri":"main.go","diff":"--- main.go\n+++ main.go\n@@ -3,5 +3,5 @@\n import \"fmt\"\n \n func main() {\n-\tfmt.Println(\"Hello foo\")\n+\tfmt.Println(\"Goodbye foo\")\n }"}
+`},
+		{protocol.RewriteSpecification{
+			Engine:        protocol.EngineRegexp,
+			MatchTemplate: `nomatch`,
+		}, ``},
+	}
+
+	store, cleanup, err := newStore(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	ts := httptest.NewServer(&replace.Service{Store: store})
+	defer ts.Close()
+
+	for _, test := range cases {
+		req := protocol.Request{
+			Repo:                 "foo",
+			URL:                  "u",
+			Commit:               "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			RewriteSpecification: test.arg,
+			FetchTimeout:         "500ms",
+		}
+		got, err := doReplace(ts.URL, &req)
+		if err != nil {
+			t.Errorf("%v failed: %s", test.arg, err)
+			continue
+		}
+
+		// We have an extra newline to make expected readable
+		if len(test.want) > 0 {
+			test.want = test.want[1:]
+		}
+
+		if got != test.want {
+			d, err := diff(test.want, got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Errorf("%v unexpected response:\n%s", test.arg, d)
+		}
+	}
+}
+
 func TestReplace_badrequest(t *testing.T) {
 	cases := []protocol.Request{
 		{
@@ -103,6 +178,34 @@ func TestReplace_badrequest(t *testing.T) {
 			Commit: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
 			// No MatchTemplate
 		},
+		{
+			Repo:   "foo",
+			URL:    "u",
+			Commit: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			RewriteSpecification: protocol.RewriteSpecification{
+				Engine:        "sed",
+				MatchTemplate: "foo",
+			},
+		},
+		{
+			Repo:   "foo",
+			URL:    "u",
+			Commit: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			RewriteSpecification: protocol.RewriteSpecification{
+				Engine:        protocol.EngineRegexp,
+				MatchTemplate: "(foo",
+			},
+		},
+		{
+			Repo:   "foo",
+			URL:    "u",
+			Commit: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			RewriteSpecification: protocol.RewriteSpecification{
+				// The script engine is disabled
+				Engine: protocol.EngineScript,
+				Script: "true",
+			},
+		},
 	}
 
 	store, cleanup, err := newStore(nil)
@@ -133,6 +236,15 @@ func doReplace(u string, p *protocol.Request) (string, error) {
 		"RewriteTemplate": []string{p.RewriteSpecification.RewriteTemplate},
 		"FileExtension":   []string{p.RewriteSpecification.FileExtension},
 	}
+	if p.RewriteSpecification.Engine != "" {
+		form.Set("Engine", p.RewriteSpecification.Engine)
+	}
+	if p.RewriteSpecification.DirectoryExclude != "" {
+		form.Set("DirectoryExclude", p.RewriteSpecification.DirectoryExclude)
+	}
+	if p.RewriteSpecification.Script != "" {
+		form.Set("Script", p.RewriteSpecification.Script)
+	}
 	resp, err := http.PostForm(u, form)
 	if err != nil {
 		return "", err
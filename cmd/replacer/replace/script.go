@@ -0,0 +1,224 @@
+package replace
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/store"
+)
+
+// Script is an Engine that runs a user-provided container image or shell
+// script over a checkout of the archive and reports the files it modified.
+// Files it creates or deletes are not reported.
+//
+// 🚨 SECURITY: Scripts are arbitrary code, so they're run in a container
+// without network access, capabilities or writable paths other than the
+// checkout. Requests can only choose among the images an admin allowed.
+type Script struct {
+	// Runtime is the container runtime binary, e.g. docker.
+	Runtime string
+	// DefaultImage is the image scripts are run in if the request doesn't
+	// specify one.
+	DefaultImage string
+	// AllowedImages are the images (other than DefaultImage) that requests
+	// may specify.
+	AllowedImages []string
+}
+
+// imageReferencePattern matches container image references, such as
+// "alpine", "alpine:3.10" or "registry.example.com:5000/team/image@sha256:...".
+// A reference can't start with a "-", so it can't be parsed as a flag of the
+// container runtime.
+var imageReferencePattern = regexp.MustCompile(`^` +
+	// Optional registry host, with an optional port.
+	`(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+	// Path components.
+	`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+	// Optional tag and digest.
+	`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?(?:@[a-z0-9]+:[a-f0-9]{32,})?$`)
+
+// ValidImageReference returns an error if image is not a valid container
+// image reference.
+func ValidImageReference(image string) error {
+	if !imageReferencePattern.MatchString(image) {
+		return errors.Errorf("invalid image reference %q", image)
+	}
+	return nil
+}
+
+// checkImage returns an error if requests may not run scripts in the given
+// image. The empty image stands for DefaultImage.
+//
+// 🚨 SECURITY: Images are arbitrary code, and pulling them from arbitrary
+// registries could leak data or pull in malicious code, so only the images
+// that an admin allowed may be used.
+func (e *Script) checkImage(image string) error {
+	if image == "" || image == e.DefaultImage {
+		return nil
+	}
+	if err := ValidImageReference(image); err != nil {
+		return err
+	}
+	for _, allowed := range e.AllowedImages {
+		if image == allowed {
+			return nil
+		}
+	}
+	return errors.Errorf("image %q is not allowed (ask an admin to add it to REPLACER_SCRIPT_ALLOWED_IMAGES)", image)
+}
+
+// Replace implements the Engine interface.
+func (e *Script) Replace(ctx context.Context, spec *protocol.RewriteSpecification, _ string, zf *store.ZipFile, w io.Writer) error {
+	dir, err := ioutil.TempDir("", "replacer-script")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// The checkout's path must not contain symlinks, so that readCheckoutFile can tell whether
+	// the script replaced files with symlinks.
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return err
+	}
+
+	for i := range zf.Files {
+		f := &zf.Files[i]
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+
+		path, err := checkoutPath(dir, f.Name)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(path, zf.DataFor(f), 0644); err != nil {
+			return err
+		}
+	}
+
+	if out, err := exec.CommandContext(ctx, e.Runtime, e.args(spec, dir)...).CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return errors.Wrapf(err, "running script: %s", out)
+	}
+
+	for i := range zf.Files {
+		f := &zf.Files[i]
+		if !includeFile(spec, f.Name) {
+			continue
+		}
+
+		new, ok, err := readCheckoutFile(dir, f.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		old := zf.DataFor(f)
+		if bytes.Equal(old, new) {
+			continue
+		}
+
+		if err := writeResult(w, f.Name, old, new); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// args returns the arguments to the container runtime to run the script of
+// the given RewriteSpecification over the checkout in dir.
+func (e *Script) args(spec *protocol.RewriteSpecification, dir string) []string {
+	image := spec.Image
+	if image == "" {
+		image = e.DefaultImage
+	}
+
+	args := []string{
+		"run", "--rm",
+		"--network=none",
+		"--read-only",
+		"--cap-drop=ALL",
+		"--security-opt=no-new-privileges",
+		"--memory=1g",
+		"--cpus=1",
+		"--pids-limit=256",
+		"--tmpfs=/tmp",
+		"--volume=" + dir + ":/work",
+		"--workdir=/work",
+		image,
+	}
+
+	if spec.Script != "" {
+		args = append(args, "sh", "-c", spec.Script)
+	}
+
+	return args
+}
+
+// readCheckoutFile reads the file with the given name in the checkout in dir
+// after the script ran. It returns ok == false if the script deleted the file
+// or replaced it (or one of its parent directories) with something other than
+// a regular file or directory.
+//
+// 🚨 SECURITY: The script controls the checkout, so it can replace files with
+// symlinks to files outside of it (which the replacer process, unlike the
+// script, can read). Symlinks must never be followed.
+func readCheckoutFile(dir, name string) (data []byte, ok bool, err error) {
+	path, err := checkoutPath(dir, name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	if resolved != path {
+		return nil, false, nil
+	}
+
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, false, nil
+	}
+
+	data, err = ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// checkoutPath returns the path of the archive file with the given name in
+// the checkout in dir.
+func checkoutPath(dir, name string) (string, error) {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if !strings.HasPrefix(path, dir+string(os.PathSeparator)) {
+		return "", errors.Errorf("invalid file name %q", name)
+	}
+	return path, nil
+}
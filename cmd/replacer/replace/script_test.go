@@ -0,0 +1,99 @@
+package replace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 🚨 SECURITY: This tests that files a script replaced with symlinks are not
+// read, so scripts can't exfiltrate files outside of the checkout.
+func TestReadCheckoutFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replacer-script-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	outside := filepath.Join(dir, "outside")
+	checkout := filepath.Join(dir, "checkout")
+	for _, d := range []string{filepath.Join(outside, "d"), filepath.Join(checkout, "d")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, data := range map[string]string{
+		filepath.Join(outside, "secret"):  "secret",
+		filepath.Join(outside, "d", "b"):  "secret",
+		filepath.Join(checkout, "a"):      "a",
+		filepath.Join(checkout, "d", "b"): "b",
+	} {
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret"), filepath.Join(checkout, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "d"), filepath.Join(checkout, "linkdir")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(checkout, "notfile"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{name: "a", want: "a", wantOK: true},
+		{name: "d/b", want: "b", wantOK: true},
+		{name: "deleted"},
+		{name: "link"},
+		{name: "linkdir/b"},
+		{name: "notfile"},
+	}
+	for _, test := range tests {
+		data, ok, err := readCheckoutFile(checkout, test.name)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if ok != test.wantOK || string(data) != test.want {
+			t.Errorf("%s: got %q (ok=%v), want %q (ok=%v)", test.name, data, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+// 🚨 SECURITY: This tests that requests can only run scripts in allowed images,
+// and that images can't be parsed as flags of the container runtime.
+func TestScript_checkImage(t *testing.T) {
+	s := &Script{DefaultImage: "alpine:3.10", AllowedImages: []string{"golang:1.13", "registry.example.com:5000/team/tool@sha256:0123456789abcdef0123456789abcdef"}}
+	for image, wantOK := range map[string]bool{
+		"":            true,
+		"alpine:3.10": true,
+		"golang:1.13": true,
+		"registry.example.com:5000/team/tool@sha256:0123456789abcdef0123456789abcdef": true,
+
+		"alpine":                       false,
+		"golang:1.12":                  false,
+		"evil.example.com/golang:1.13": false,
+		"--privileged":                 false,
+		"-v/:/host":                    false,
+	} {
+		if err := s.checkImage(image); (err == nil) != wantOK {
+			t.Errorf("checkImage(%q): got error %v, want ok %t", image, err, wantOK)
+		}
+	}
+
+	for _, image := range []string{"--privileged", "-alpine", "alpine:", "Alpine", "alpine latest", ""} {
+		if err := ValidImageReference(image); err == nil {
+			t.Errorf("ValidImageReference(%q): got no error", image)
+		}
+	}
+}
@@ -92,8 +92,10 @@ func (s BitbucketCloudSource) makeRepo(r *bitbucketcloud.Repo) *Repo {
 			ServiceID:   host.String(),
 		},
 		Description: r.Description,
+		Language:    r.Language,
 		Fork:        r.Parent != nil,
 		Enabled:     true,
+		Visibility:  visibility(r.IsPrivate),
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
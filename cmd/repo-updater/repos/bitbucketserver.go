@@ -108,6 +108,88 @@ func (s BitbucketServerSource) ExternalServices() ExternalServices {
 	return ExternalServices{s.svc}
 }
 
+// LoadChangesets loads the latest state of the given Changesets from the codehost.
+func (s BitbucketServerSource) LoadChangesets(ctx context.Context, cs ...*Changeset) error {
+	for i := range cs {
+		repo := cs[i].Repo.Metadata.(*bitbucketserver.Repo)
+		number, err := strconv.Atoi(cs[i].ExternalID)
+		if err != nil {
+			return err
+		}
+
+		pr := &bitbucketserver.PullRequest{ID: number}
+		pr.ToRef.Repository.Slug = repo.Slug
+		pr.ToRef.Repository.Project = &bitbucketserver.Project{Key: repo.Project.Key}
+
+		if err = s.client.LoadPullRequest(ctx, pr); err != nil {
+			return err
+		}
+
+		cs[i].Changeset.Metadata = pr
+	}
+
+	return nil
+}
+
+// CreateChangeset creates the given *Changeset in the code host, or uses the
+// open pull request between its refs if there already is one.
+func (s BitbucketServerSource) CreateChangeset(ctx context.Context, c *Changeset) error {
+	repo := c.Repo.Metadata.(*bitbucketserver.Repo)
+
+	pr := &bitbucketserver.PullRequest{Title: c.Title, Description: c.Body}
+
+	pr.FromRef.ID = c.HeadRef
+	pr.FromRef.Repository.Slug = repo.Slug
+	pr.FromRef.Repository.Project = &bitbucketserver.Project{Key: repo.Project.Key}
+
+	pr.ToRef.ID = c.BaseRef
+	pr.ToRef.Repository.Slug = repo.Slug
+	pr.ToRef.Repository.Project = &bitbucketserver.Project{Key: repo.Project.Key}
+
+	if err := s.client.CreatePullRequest(ctx, pr); err != nil {
+		if findErr := s.client.FindOpenPullRequest(ctx, pr); findErr != nil {
+			return err
+		}
+	}
+
+	c.Changeset.Metadata = pr
+	c.Changeset.ExternalID = strconv.Itoa(pr.ID)
+	c.Changeset.ExternalServiceType = bitbucketserver.ServiceType
+
+	return nil
+}
+
+// CommentOnChangeset adds a comment to the pull request of the given *Changeset.
+func (s BitbucketServerSource) CommentOnChangeset(ctx context.Context, c *Changeset, body string) error {
+	pr := c.Changeset.Metadata.(*bitbucketserver.PullRequest)
+	return s.client.CreatePullRequestComment(ctx, pr, body)
+}
+
+// CloseChangeset declines the pull request of the given *Changeset.
+func (s BitbucketServerSource) CloseChangeset(ctx context.Context, c *Changeset) error {
+	pr := c.Changeset.Metadata.(*bitbucketserver.PullRequest)
+	return s.client.DeclinePullRequest(ctx, pr)
+}
+
+// ReopenChangeset reopens the declined pull request of the given *Changeset.
+func (s BitbucketServerSource) ReopenChangeset(ctx context.Context, c *Changeset) error {
+	pr := c.Changeset.Metadata.(*bitbucketserver.PullRequest)
+	return s.client.ReopenPullRequest(ctx, pr)
+}
+
+// RequestChangesetReview is not supported by Bitbucket Server, which has no
+// API to request a new review of a pull request.
+func (s BitbucketServerSource) RequestChangesetReview(ctx context.Context, c *Changeset) error {
+	return ErrChangesetActionNotSupported
+}
+
+// MergeChangeset merges the pull request of the given *Changeset. Bitbucket
+// Server itself refuses to merge it until its merge checks passed.
+func (s BitbucketServerSource) MergeChangeset(ctx context.Context, c *Changeset) error {
+	pr := c.Changeset.Metadata.(*bitbucketserver.PullRequest)
+	return s.client.MergePullRequest(ctx, pr)
+}
+
 func (s BitbucketServerSource) makeRepo(repo *bitbucketserver.Repo) *Repo {
 	host, err := url.Parse(s.config.Url)
 	if err != nil {
@@ -118,8 +200,12 @@ func (s BitbucketServerSource) makeRepo(repo *bitbucketserver.Repo) *Repo {
 
 	// Name
 	project := "UNKNOWN"
+	var topics []string
 	if repo.Project != nil {
 		project = repo.Project.Key
+		// Bitbucket Server has no repository topics, so we use the
+		// project key as the closest equivalent.
+		topics = normalizeTopics(repo.Project.Key)
 	}
 
 	// Clone URL
@@ -178,6 +264,8 @@ func (s BitbucketServerSource) makeRepo(repo *bitbucketserver.Repo) *Repo {
 		Description: repo.Name,
 		Fork:        repo.Origin != nil,
 		Enabled:     true,
+		Topics:      topics,
+		Visibility:  visibility(!repo.Public && (repo.Project == nil || !repo.Project.Public)),
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
@@ -13,3 +13,23 @@ func GetUpdateInterval() time.Duration {
 	}
 	return time.Duration(v) * time.Minute
 }
+
+// GetDeletionGracePeriod returns how long repos missing from their code hosts
+// are kept before being deleted.
+func GetDeletionGracePeriod() time.Duration {
+	v := conf.Get().RepoDeletionGracePeriod
+	if v == nil { // default to 3 days
+		return 72 * time.Hour
+	}
+	return time.Duration(*v) * time.Hour
+}
+
+// GetDeletionQuarantineThreshold returns the fraction of an external service's
+// repos that a single sync may find missing before quarantining them.
+func GetDeletionQuarantineThreshold() float64 {
+	v := conf.Get().RepoDeletionQuarantineThreshold
+	if v == nil { // default to 50%
+		return 0.5
+	}
+	return float64(*v) / 100
+}
@@ -0,0 +1,54 @@
+package repos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestGetDeletionSettings(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	for _, tc := range []struct {
+		name          string
+		config        schema.SiteConfiguration
+		wantGrace     time.Duration
+		wantThreshold float64
+	}{
+		{
+			name:          "defaults",
+			wantGrace:     72 * time.Hour,
+			wantThreshold: 0.5,
+		},
+		{
+			name: "configured",
+			config: schema.SiteConfiguration{
+				RepoDeletionGracePeriod:         intPtr(24),
+				RepoDeletionQuarantineThreshold: intPtr(10),
+			},
+			wantGrace:     24 * time.Hour,
+			wantThreshold: 0.1,
+		},
+		{
+			name: "disabled",
+			config: schema.SiteConfiguration{
+				RepoDeletionGracePeriod:         intPtr(0),
+				RepoDeletionQuarantineThreshold: intPtr(0),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conf.Mock(&conf.Unified{SiteConfiguration: tc.config})
+			defer conf.Mock(nil)
+
+			if have := GetDeletionGracePeriod(); have != tc.wantGrace {
+				t.Errorf("have grace period %s, want %s", have, tc.wantGrace)
+			}
+			if have := GetDeletionQuarantineThreshold(); have != tc.wantThreshold {
+				t.Errorf("have quarantine threshold %v, want %v", have, tc.wantThreshold)
+			}
+		})
+	}
+}
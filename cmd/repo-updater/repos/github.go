@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/a8n"
 	"github.com/sourcegraph/sourcegraph/pkg/conf/reposource"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
 	"github.com/sourcegraph/sourcegraph/pkg/httpcli"
@@ -174,6 +175,110 @@ func (s GithubSource) LoadChangesets(ctx context.Context, cs ...*Changeset) erro
 	return nil
 }
 
+// CreateChangeset creates the given *Changeset in the code host, or uses the
+// open pull request between its refs if there already is one.
+func (s GithubSource) CreateChangeset(ctx context.Context, c *Changeset) error {
+	repo := c.Repo.Metadata.(*github.Repository)
+	head, base := abbreviateRef(c.HeadRef), abbreviateRef(c.BaseRef)
+
+	pr, err := s.client.CreatePullRequest(ctx, &github.CreatePullRequestInput{
+		RepositoryID: repo.ID,
+		Title:        c.Title,
+		Body:         c.Body,
+		HeadRefName:  head,
+		BaseRefName:  base,
+	})
+	if err != nil {
+		existing, findErr := s.client.GetOpenPullRequestByRefs(ctx, repo.NameWithOwner, head, base)
+		if findErr != nil {
+			return err
+		}
+		pr = existing
+	}
+
+	pr.RepoWithOwner = repo.NameWithOwner
+	c.Changeset.Metadata = pr
+	c.Changeset.ExternalID = strconv.Itoa(pr.Number)
+	c.Changeset.ExternalServiceType = github.ServiceType
+
+	return nil
+}
+
+// CommentOnChangeset adds a comment to the pull request of the given *Changeset.
+func (s GithubSource) CommentOnChangeset(ctx context.Context, c *Changeset, body string) error {
+	pr := c.Changeset.Metadata.(*github.PullRequest)
+	return s.client.AddComment(ctx, pr.ID, body)
+}
+
+// CloseChangeset closes the pull request of the given *Changeset.
+func (s GithubSource) CloseChangeset(ctx context.Context, c *Changeset) error {
+	pr := c.Changeset.Metadata.(*github.PullRequest)
+	return s.client.ClosePullRequest(ctx, pr)
+}
+
+// ReopenChangeset reopens the pull request of the given *Changeset.
+func (s GithubSource) ReopenChangeset(ctx context.Context, c *Changeset) error {
+	pr := c.Changeset.Metadata.(*github.PullRequest)
+	return s.client.ReopenPullRequest(ctx, pr)
+}
+
+// RequestChangesetReview requests a new review of the pull request of the
+// given *Changeset from everyone who reviewed it before.
+func (s GithubSource) RequestChangesetReview(ctx context.Context, c *Changeset) error {
+	pr := c.Changeset.Metadata.(*github.PullRequest)
+
+	var logins []string
+	seen := map[string]bool{}
+	for _, r := range pr.Reviews {
+		if login := r.Author.Login; login != "" && !seen[login] {
+			seen[login] = true
+			logins = append(logins, login)
+		}
+	}
+
+	return s.client.RequestReviews(ctx, pr, logins...)
+}
+
+// MergeChangeset merges the pull request of the given *Changeset if all the
+// checks of its head commit passed, commit statuses and check runs alike, or
+// if it has none. GitHub can't merge a pull request by itself once its checks
+// passed, so it returns ErrChangesetChecksPending while they are running. The
+// metadata of the *Changeset must be up to date.
+func (s GithubSource) MergeChangeset(ctx context.Context, c *Changeset) error {
+	pr := c.Changeset.Metadata.(*github.PullRequest)
+
+	if err := checkChangesetChecksPassed(c.Changeset); err != nil {
+		return err
+	}
+
+	return s.client.MergePullRequest(ctx, pr)
+}
+
+// checkChangesetChecksPassed returns ErrChangesetChecksPending if the given
+// Changeset has CI checks that are still running, and
+// ErrChangesetChecksNotPassed if not all of them passed.
+func checkChangesetChecksPassed(c *a8n.Changeset) error {
+	checks, err := c.Checks()
+	if err != nil || len(checks) == 0 {
+		return err
+	}
+
+	state, err := c.CheckState()
+	if err != nil {
+		return err
+	}
+
+	if state == a8n.ChangesetCheckStatePending {
+		return ErrChangesetChecksPending
+	}
+
+	if state != a8n.ChangesetCheckStatePassed {
+		return errors.Wrapf(ErrChangesetChecksNotPassed, "checks %s", strings.ToLower(string(state)))
+	}
+
+	return nil
+}
+
 // GetRepo returns the Github repository with the given name and owner
 // ("org/repo-name")
 func (s GithubSource) GetRepo(ctx context.Context, nameWithOwner string) (*Repo, error) {
@@ -199,9 +304,13 @@ func (s GithubSource) makeRepo(r *github.Repository) *Repo {
 		)),
 		ExternalRepo: github.ExternalRepoSpec(r, *s.baseURL),
 		Description:  r.Description,
+		Language:     r.Language,
 		Fork:         r.IsFork,
 		Enabled:      true,
 		Archived:     r.IsArchived,
+		Topics:       normalizeTopics(r.Topics...),
+		Stars:        r.StargazerCount,
+		Visibility:   visibility(r.IsPrivate),
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
@@ -128,6 +128,46 @@ func TestGithubSource_LoadChangesets(t *testing.T) {
 	}
 }
 
+func TestCheckChangesetChecksPassed(t *testing.T) {
+	commit := func(statusState, checkRunConclusion string) *github.Commit {
+		c := &github.Commit{}
+		if statusState != "" {
+			c.Status.Contexts = []github.StatusContext{{Context: "ci/build", State: statusState}}
+		}
+		if checkRunConclusion != "" {
+			c.CheckSuites = []github.CheckSuite{{
+				CheckRuns: []github.CheckRun{{
+					Name:       "test",
+					Status:     github.CheckStatusCompleted,
+					Conclusion: checkRunConclusion,
+				}},
+			}}
+		}
+		return c
+	}
+
+	for _, tc := range []struct {
+		name   string
+		commit *github.Commit
+		err    error
+	}{
+		{name: "no head commit"},
+		{name: "no checks", commit: commit("", "")},
+		{name: "status and check run passed", commit: commit("SUCCESS", github.CheckConclusionSuccess)},
+		{name: "status pending", commit: commit("PENDING", github.CheckConclusionSuccess), err: ErrChangesetChecksPending},
+		{name: "check run failed", commit: commit("SUCCESS", github.CheckConclusionFailure), err: ErrChangesetChecksNotPassed},
+		{name: "only check run failed", commit: commit("", github.CheckConclusionFailure), err: ErrChangesetChecksNotPassed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &a8n.Changeset{Metadata: &github.PullRequest{HeadCommit: tc.commit}}
+
+			if have, want := errors.Cause(checkChangesetChecksPassed(c)), tc.err; have != want {
+				t.Fatalf("have error %v, want %v", have, want)
+			}
+		})
+	}
+}
+
 func TestGithubSource_GetRepo(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -101,6 +101,125 @@ func (s GitLabSource) ExternalServices() ExternalServices {
 	return ExternalServices{s.svc}
 }
 
+// LoadChangesets loads the latest state of the given Changesets from the codehost.
+func (s GitLabSource) LoadChangesets(ctx context.Context, cs ...*Changeset) error {
+	for i := range cs {
+		proj := cs[i].Repo.Metadata.(*gitlab.Project)
+		iid, err := strconv.Atoi(cs[i].ExternalID)
+		if err != nil {
+			return err
+		}
+
+		mr, err := s.client.GetMergeRequest(ctx, gitlab.GetMergeRequestOp{
+			ProjID: proj.ID,
+			IID:    iid,
+		})
+		if err != nil {
+			return err
+		}
+
+		cs[i].Changeset.Metadata = mr
+	}
+
+	return nil
+}
+
+// CreateChangeset creates the given *Changeset in the code host, or uses the
+// open merge request between its refs if there already is one.
+func (s GitLabSource) CreateChangeset(ctx context.Context, c *Changeset) error {
+	proj := c.Repo.Metadata.(*gitlab.Project)
+	source, target := abbreviateRef(c.HeadRef), abbreviateRef(c.BaseRef)
+
+	mr, err := s.client.CreateMergeRequest(ctx, gitlab.CreateMergeRequestOp{
+		ProjID:       proj.ID,
+		SourceBranch: source,
+		TargetBranch: target,
+		Title:        c.Title,
+		Description:  c.Body,
+	})
+	if err != nil {
+		existing, findErr := s.client.FindOpenMergeRequest(ctx, gitlab.FindOpenMergeRequestOp{
+			ProjID:       proj.ID,
+			SourceBranch: source,
+			TargetBranch: target,
+		})
+		if findErr != nil {
+			return err
+		}
+		mr = existing
+	}
+
+	c.Changeset.Metadata = mr
+	c.Changeset.ExternalID = strconv.Itoa(mr.IID)
+	c.Changeset.ExternalServiceType = gitlab.ServiceType
+
+	return nil
+}
+
+// CommentOnChangeset adds a note to the merge request of the given *Changeset.
+func (s GitLabSource) CommentOnChangeset(ctx context.Context, c *Changeset, body string) error {
+	mr := c.Changeset.Metadata.(*gitlab.MergeRequest)
+	return s.client.CreateMergeRequestNote(ctx, gitlab.CreateMergeRequestNoteOp{
+		ProjID: mr.ProjectID,
+		IID:    mr.IID,
+		Body:   body,
+	})
+}
+
+// CloseChangeset closes the merge request of the given *Changeset.
+func (s GitLabSource) CloseChangeset(ctx context.Context, c *Changeset) error {
+	return s.updateMergeRequestState(ctx, c, gitlab.MergeRequestStateEventClose)
+}
+
+// ReopenChangeset reopens the merge request of the given *Changeset.
+func (s GitLabSource) ReopenChangeset(ctx context.Context, c *Changeset) error {
+	return s.updateMergeRequestState(ctx, c, gitlab.MergeRequestStateEventReopen)
+}
+
+func (s GitLabSource) updateMergeRequestState(ctx context.Context, c *Changeset, ev gitlab.MergeRequestStateEvent) error {
+	mr := c.Changeset.Metadata.(*gitlab.MergeRequest)
+
+	updated, err := s.client.UpdateMergeRequest(ctx, gitlab.UpdateMergeRequestOp{
+		ProjID:     mr.ProjectID,
+		IID:        mr.IID,
+		StateEvent: ev,
+	})
+	if err != nil {
+		return err
+	}
+
+	updated.Approvals = mr.Approvals
+	c.Changeset.Metadata = updated
+
+	return nil
+}
+
+// RequestChangesetReview is not supported by GitLab, which has no API to
+// request a new review of a merge request.
+func (s GitLabSource) RequestChangesetReview(ctx context.Context, c *Changeset) error {
+	return ErrChangesetActionNotSupported
+}
+
+// MergeChangeset makes GitLab merge the merge request of the given
+// *Changeset once its pipeline succeeds.
+func (s GitLabSource) MergeChangeset(ctx context.Context, c *Changeset) error {
+	mr := c.Changeset.Metadata.(*gitlab.MergeRequest)
+
+	updated, err := s.client.AcceptMergeRequest(ctx, gitlab.AcceptMergeRequestOp{
+		ProjID:                    mr.ProjectID,
+		IID:                       mr.IID,
+		MergeWhenPipelineSucceeds: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	updated.Approvals = mr.Approvals
+	c.Changeset.Metadata = updated
+
+	return nil
+}
+
 func (s GitLabSource) makeRepo(proj *gitlab.Project) *Repo {
 	urn := s.svc.URN()
 	return &Repo{
@@ -121,6 +240,9 @@ func (s GitLabSource) makeRepo(proj *gitlab.Project) *Repo {
 		Fork:         proj.ForkedFromProject != nil,
 		Enabled:      true,
 		Archived:     proj.Archived,
+		Topics:       normalizeTopics(proj.TagList...),
+		Stars:        proj.StarCount,
+		Visibility:   RepoVisibility(proj.Visibility),
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
@@ -1,8 +1,15 @@
 package repos
 
 import (
+	"context"
+	"errors"
 	"reflect"
+	"strconv"
 	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/a8n"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 func Test_projectQueryToURL(t *testing.T) {
@@ -48,3 +55,66 @@ func Test_projectQueryToURL(t *testing.T) {
 		}
 	}
 }
+
+func TestGitLabSource_CreateChangeset(t *testing.T) {
+	defer func() {
+		gitlab.MockCreateMergeRequest = nil
+		gitlab.MockFindOpenMergeRequest = nil
+	}()
+
+	src, err := newGitLabSource(&ExternalService{}, &schema.GitLabConnection{Url: "https://gitlab.example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existing := &gitlab.MergeRequest{IID: 3, ProjectID: 1}
+	for _, tc := range []struct {
+		name      string
+		createErr error
+		found     *gitlab.MergeRequest
+		wantIID   int
+		err       string
+	}{
+		{name: "created", wantIID: 5},
+		{name: "already exists", createErr: errors.New("409 Conflict"), found: existing, wantIID: 3},
+		{name: "failed", createErr: errors.New("500 Internal Server Error"), err: "500 Internal Server Error"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gitlab.MockCreateMergeRequest = func(c *gitlab.Client, ctx context.Context, op gitlab.CreateMergeRequestOp) (*gitlab.MergeRequest, error) {
+				if tc.createErr != nil {
+					return nil, tc.createErr
+				}
+				return &gitlab.MergeRequest{IID: 5, ProjectID: op.ProjID}, nil
+			}
+			gitlab.MockFindOpenMergeRequest = func(c *gitlab.Client, ctx context.Context, op gitlab.FindOpenMergeRequestOp) (*gitlab.MergeRequest, error) {
+				if op.ProjID != 1 || op.SourceBranch != "campaign" || op.TargetBranch != "master" {
+					t.Errorf("unexpected op %+v", op)
+				}
+				if tc.found == nil {
+					return nil, gitlab.ErrMergeRequestNotFound
+				}
+				return tc.found, nil
+			}
+
+			cs := &Changeset{
+				HeadRef:   "refs/heads/campaign",
+				BaseRef:   "refs/heads/master",
+				Repo:      &Repo{Metadata: &gitlab.Project{ProjectCommon: gitlab.ProjectCommon{ID: 1}}},
+				Changeset: &a8n.Changeset{},
+			}
+			err := src.CreateChangeset(context.Background(), cs)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("got error %v, want %q", err, tc.err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if cs.ExternalID != strconv.Itoa(tc.wantIID) {
+				t.Errorf("got external ID %q, want %d", cs.ExternalID, tc.wantIID)
+			}
+		})
+	}
+}
@@ -243,7 +243,7 @@ func (s *updateScheduler) Update(rs ...*Repo) {
 
 	known := 0
 	for _, r := range rs {
-		if r.IsDeleted() {
+		if r.IsDeleted() || r.IsMissingUpstream() {
 			s.remove(r)
 		} else {
 			known++
@@ -260,7 +260,7 @@ func (s *updateScheduler) Set(rs ...*Repo) {
 	s.Update(rs...)
 	known := 0
 	for _, r := range rs {
-		if !r.IsDeleted() {
+		if !r.IsDeleted() && !r.IsMissingUpstream() {
 			known++
 		}
 	}
@@ -87,11 +87,53 @@ type Source interface {
 	ExternalServices() ExternalServices
 }
 
-// A ChangesetSource can load the latest state of a list of Changesets.
+// A ChangesetSource can load the latest state of a list of Changesets
+// and create new Changesets on the code host.
 type ChangesetSource interface {
+	// LoadChangesets loads the given Changesets from the sources and updates
+	// them in place.
 	LoadChangesets(context.Context, ...*Changeset) error
+	// CreateChangeset creates the given Changeset on the code host from its
+	// HeadRef into its BaseRef, setting its ExternalID and Metadata. If the
+	// creation fails because an open Changeset from HeadRef into BaseRef
+	// already exists, that Changeset is used instead.
+	CreateChangeset(context.Context, *Changeset) error
 }
 
+// A ChangesetActionSource can run actions on existing Changesets on the
+// code host. Every method expects the Changeset's Metadata to be loaded and
+// updates it in place when the action changes the Changeset. Actions the
+// code host doesn't support return ErrChangesetActionNotSupported.
+type ChangesetActionSource interface {
+	// CommentOnChangeset adds a comment with the given body to the Changeset.
+	CommentOnChangeset(ctx context.Context, c *Changeset, body string) error
+	// CloseChangeset closes the Changeset without merging it.
+	CloseChangeset(context.Context, *Changeset) error
+	// ReopenChangeset reopens the closed Changeset.
+	ReopenChangeset(context.Context, *Changeset) error
+	// RequestChangesetReview asks the reviewers of the Changeset to review
+	// it again.
+	RequestChangesetReview(context.Context, *Changeset) error
+	// MergeChangeset merges the Changeset once its checks passed. If the
+	// code host can't merge the Changeset by itself once they passed, it
+	// returns ErrChangesetChecksPending while they are still running, so that
+	// the caller can try again later, and ErrChangesetChecksNotPassed if they
+	// failed.
+	MergeChangeset(context.Context, *Changeset) error
+}
+
+// ErrChangesetActionNotSupported is returned by the methods of a
+// ChangesetActionSource for actions the code host doesn't support.
+var ErrChangesetActionNotSupported = errors.New("changeset action not supported by code host")
+
+// ErrChangesetChecksNotPassed is returned by MergeChangeset when the checks
+// of a Changeset didn't pass.
+var ErrChangesetChecksNotPassed = errors.New("changeset checks did not pass")
+
+// ErrChangesetChecksPending is returned by MergeChangeset when the checks of
+// a Changeset are still running.
+var ErrChangesetChecksPending = errors.New("changeset checks are pending")
+
 // A SourceResult is sent by a Source over a channel for each repository it
 // yields when listing repositories
 type SourceResult struct {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/db/dbutil"
@@ -327,6 +328,11 @@ SELECT
   enabled,
   archived,
   fork,
+  topics,
+  stars,
+  visibility,
+  missing_upstream_at,
+  quarantined,
   sources,
   metadata
 FROM repo
@@ -547,6 +553,11 @@ func batchReposQuery(fmtstr string, repos []*Repo) (_ *sqlf.Query, err error) {
 		Enabled             bool            `json:"enabled"`
 		Archived            bool            `json:"archived"`
 		Fork                bool            `json:"fork"`
+		Topics              []string        `json:"topics"`
+		Stars               int             `json:"stars"`
+		Visibility          *string         `json:"visibility,omitempty"`
+		MissingUpstreamAt   *time.Time      `json:"missing_upstream_at,omitempty"`
+		Quarantined         bool            `json:"quarantined"`
 		Sources             json.RawMessage `json:"sources"`
 		Metadata            json.RawMessage `json:"metadata"`
 	}
@@ -578,6 +589,11 @@ func batchReposQuery(fmtstr string, repos []*Repo) (_ *sqlf.Query, err error) {
 			Enabled:             r.Enabled,
 			Archived:            r.Archived,
 			Fork:                r.Fork,
+			Topics:              topicsColumn(r.Topics),
+			Stars:               r.Stars,
+			Visibility:          nullStringColumn(string(r.Visibility)),
+			MissingUpstreamAt:   nullTimeColumn(r.MissingUpstreamAt.UTC()),
+			Quarantined:         r.Quarantined,
 			Sources:             sources,
 			Metadata:            metadata,
 		})
@@ -622,6 +638,11 @@ WITH batch AS (
       enabled               boolean,
       archived              boolean,
       fork                  boolean,
+      topics                jsonb,
+      stars                 integer,
+      visibility            text,
+      missing_upstream_at   timestamptz,
+      quarantined           boolean,
       sources               jsonb,
       metadata              jsonb
     )
@@ -646,6 +667,11 @@ updated AS (
     enabled               = batch.enabled,
     archived              = batch.archived,
     fork                  = batch.fork,
+    topics                = ARRAY(SELECT jsonb_array_elements_text(batch.topics)),
+    stars                 = batch.stars,
+    visibility            = batch.visibility,
+    missing_upstream_at   = batch.missing_upstream_at,
+    quarantined           = batch.quarantined,
     sources               = batch.sources,
     metadata              = batch.metadata
   FROM batch
@@ -667,6 +693,11 @@ SELECT
   updated.enabled,
   updated.archived,
   updated.fork,
+  updated.topics,
+  updated.stars,
+  updated.visibility,
+  updated.missing_upstream_at,
+  updated.quarantined,
   updated.sources,
   updated.metadata
 FROM updated
@@ -697,6 +728,11 @@ inserted AS (
     enabled,
     archived,
     fork,
+    topics,
+    stars,
+    visibility,
+    missing_upstream_at,
+    quarantined,
     sources,
     metadata
   )
@@ -714,6 +750,11 @@ inserted AS (
     enabled,
     archived,
     fork,
+    ARRAY(SELECT jsonb_array_elements_text(topics)),
+    stars,
+    visibility,
+    missing_upstream_at,
+    quarantined,
     sources,
     metadata
   FROM batch
@@ -734,6 +775,11 @@ SELECT
   inserted.enabled,
   inserted.archived,
   inserted.fork,
+  inserted.topics,
+  inserted.stars,
+  inserted.visibility,
+  inserted.missing_upstream_at,
+  inserted.quarantined,
   inserted.sources,
   inserted.metadata
 FROM inserted
@@ -755,6 +801,13 @@ func nullStringColumn(s string) *string {
 	return &s
 }
 
+func topicsColumn(topics []string) []string {
+	if topics == nil {
+		return []string{}
+	}
+	return topics
+}
+
 func metadataColumn(metadata interface{}) (msg json.RawMessage, err error) {
 	switch m := metadata.(type) {
 	case nil:
@@ -830,6 +883,11 @@ func scanRepo(r *Repo, s scanner) error {
 		&r.Enabled,
 		&r.Archived,
 		&r.Fork,
+		pq.Array(&r.Topics),
+		&r.Stars,
+		&dbutil.NullString{S: (*string)(&r.Visibility)},
+		&dbutil.NullTime{Time: &r.MissingUpstreamAt},
+		&r.Quarantined,
 		&sources,
 		&metadata,
 	)
@@ -837,6 +895,10 @@ func scanRepo(r *Repo, s scanner) error {
 		return err
 	}
 
+	if len(r.Topics) == 0 {
+		r.Topics = nil
+	}
+
 	if err = json.Unmarshal(sources, &r.Sources); err != nil {
 		return errors.Wrap(err, "scanRepo: failed to unmarshal sources")
 	}
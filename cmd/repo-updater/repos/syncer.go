@@ -30,6 +30,18 @@ type Syncer struct {
 	// Sourcegraph.com
 	FailFullSync bool
 
+	// DeletionGracePeriod returns how long repos that went missing from all
+	// of their sources are kept around before being deleted. It's called on
+	// each sync, so that configuration changes apply right away. If it's nil
+	// or returns zero, such repos are deleted right away.
+	DeletionGracePeriod func() time.Duration
+
+	// QuarantineThreshold returns the fraction of an external service's repos
+	// that a single Sync may find missing before quarantining them instead,
+	// so that they're kept until a site admin reviews them. It's called on
+	// each sync. If it's nil or returns zero, quarantining is disabled.
+	QuarantineThreshold func() float64
+
 	// Synced is sent Repos that were synced by Sync (only if Synced is non-nil)
 	Synced chan Repos
 
@@ -48,6 +60,10 @@ type Syncer struct {
 	lastSyncErr   error
 	lastSyncErrMu sync.Mutex
 
+	// lastSyncStart is when the last successful Sync started. It's only
+	// accessed by Sync.
+	lastSyncStart time.Time
+
 	syncSignal signal
 }
 
@@ -85,6 +101,8 @@ func (s *Syncer) Sync(ctx context.Context) (err error) {
 		return errors.New("Syncer is not enabled")
 	}
 
+	began := s.Now()
+
 	var streamingInserter func(*Repo)
 	if s.DisableStreaming {
 		streamingInserter = func(*Repo) {} //noop
@@ -95,8 +113,13 @@ func (s *Syncer) Sync(ctx context.Context) (err error) {
 		}
 	}
 
+	var svcs []*ExternalService
+	if svcs, err = s.Store.ListExternalServices(ctx, StoreListExternalServicesArgs{}); err != nil {
+		return errors.Wrap(err, "syncer.sync.store.list-external-services")
+	}
+
 	var sourced Repos
-	if sourced, err = s.sourced(ctx, streamingInserter); err != nil {
+	if sourced, err = s.sourced(ctx, svcs, streamingInserter); err != nil {
 		return errors.Wrap(err, "syncer.sync.sourced")
 	}
 
@@ -116,6 +139,7 @@ func (s *Syncer) Sync(ctx context.Context) (err error) {
 	}
 
 	diff = NewDiff(sourced, stored)
+	s.quarantine(diff, svcs)
 	upserts := s.upserts(diff)
 
 	if err = store.UpsertRepos(ctx, upserts...); err != nil {
@@ -126,6 +150,8 @@ func (s *Syncer) Sync(ctx context.Context) (err error) {
 		s.Synced <- diff.Repos()
 	}
 
+	s.lastSyncStart = began
+
 	return nil
 }
 
@@ -203,9 +229,29 @@ func (s *Syncer) syncSubset(ctx context.Context, insertOnly bool, sourcedSubset
 
 func (s *Syncer) upserts(diff Diff) []*Repo {
 	now := s.Now()
+	var grace time.Duration
+	if s.DeletionGracePeriod != nil {
+		grace = s.DeletionGracePeriod()
+	}
 	upserts := make([]*Repo, 0, len(diff.Added)+len(diff.Deleted)+len(diff.Modified))
 
+	// Deleted repos can't be kept around if a sourced repo took their name.
+	taken := make(map[string]bool, len(diff.Added)+len(diff.Modified))
+	for _, rs := range []Repos{diff.Added, diff.Modified} {
+		for _, r := range rs {
+			taken[strings.ToLower(r.Name)] = true
+		}
+	}
+
 	for _, repo := range diff.Deleted {
+		if !taken[strings.ToLower(repo.Name)] && s.retain(repo, now, grace) {
+			if !repo.IsMissingUpstream() {
+				repo.UpdatedAt, repo.MissingUpstreamAt = now, now
+				upserts = append(upserts, repo)
+			}
+			continue
+		}
+
 		repo.UpdatedAt, repo.DeletedAt = now, now
 		repo.Sources = map[string]*SourceInfo{}
 		repo.Enabled = true
@@ -227,6 +273,81 @@ func (s *Syncer) upserts(diff Diff) []*Repo {
 	return upserts
 }
 
+// retain returns true if the given repo, which went missing from all of its
+// sources, should be kept around rather than deleted, given the deletion
+// grace period.
+func (s *Syncer) retain(r *Repo, now time.Time, grace time.Duration) bool {
+	switch {
+	case r.Quarantined:
+		return true
+	case grace <= 0:
+		return false
+	case !r.IsMissingUpstream():
+		return true
+	default:
+		return now.Sub(r.MissingUpstreamAt) < grace
+	}
+}
+
+// quarantine marks the repos that went missing in this Sync as Quarantined
+// if they belong to an external service that lost more than the
+// QuarantineThreshold of its repos at once. Services that were deleted or
+// re-configured since the last Sync are exempt, since the removal of their
+// repos is then likely intended.
+func (s *Syncer) quarantine(diff Diff, svcs []*ExternalService) {
+	var threshold float64
+	if s.QuarantineThreshold != nil {
+		threshold = s.QuarantineThreshold()
+	}
+	if threshold <= 0 || len(diff.Deleted) == 0 {
+		return
+	}
+
+	unchanged := make(map[int64]bool, len(svcs))
+	for _, svc := range svcs {
+		unchanged[svc.ID] = s.lastSyncStart.IsZero() || svc.UpdatedAt.Before(s.lastSyncStart)
+	}
+
+	// Repos that were already missing upstream before this Sync don't count.
+	total := make(map[int64]int, len(svcs))
+	for _, rs := range []Repos{diff.Deleted, diff.Modified, diff.Unmodified} {
+		for _, r := range rs {
+			if !r.IsMissingUpstream() {
+				for _, id := range r.ExternalServiceIDs() {
+					total[id]++
+				}
+			}
+		}
+	}
+
+	missing := make(map[int64]int, len(svcs))
+	for _, r := range diff.Deleted {
+		if !r.IsMissingUpstream() {
+			for _, id := range r.ExternalServiceIDs() {
+				missing[id]++
+			}
+		}
+	}
+
+	var quarantined []string
+	for _, r := range diff.Deleted {
+		if r.IsMissingUpstream() {
+			continue
+		}
+		for _, id := range r.ExternalServiceIDs() {
+			if unchanged[id] && float64(missing[id]) > threshold*float64(total[id]) {
+				r.Quarantined = true
+				quarantined = append(quarantined, r.Name)
+				break
+			}
+		}
+	}
+
+	if len(quarantined) > 0 && s.Logger != nil {
+		s.Logger.Warn("syncer.quarantine", "repos", quarantined)
+	}
+}
+
 // A Diff of two sets of Diffables.
 type Diff struct {
 	Added      Repos
@@ -343,12 +464,7 @@ func merge(o, n *Repo) {
 	o.Update(n)
 }
 
-func (s *Syncer) sourced(ctx context.Context, observe ...func(*Repo)) ([]*Repo, error) {
-	svcs, err := s.Store.ListExternalServices(ctx, StoreListExternalServicesArgs{})
-	if err != nil {
-		return nil, err
-	}
-
+func (s *Syncer) sourced(ctx context.Context, svcs []*ExternalService, observe ...func(*Repo)) ([]*Repo, error) {
 	srcs, err := s.Sourcer(svcs...)
 	if err != nil {
 		return nil, err
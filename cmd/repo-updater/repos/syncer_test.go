@@ -620,6 +620,153 @@ func testSyncerSync(s repos.Store) func(*testing.T) {
 	}
 }
 
+func TestSyncer_Sync_missingUpstream(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	svc := &repos.ExternalService{ID: 1, Kind: "GITHUB", UpdatedAt: now.Add(-time.Hour)}
+
+	repo := func(name string, opts ...func(*repos.Repo)) *repos.Repo {
+		r := (&repos.Repo{
+			Name:     name,
+			Metadata: &github.Repository{},
+			Enabled:  true,
+			ExternalRepo: api.ExternalRepoSpec{
+				ID:          name,
+				ServiceID:   "https://github.com/",
+				ServiceType: "github",
+			},
+		}).With(repos.Opt.RepoSources(svc.URN()))
+		return r.With(opts...)
+	}
+
+	missingSince := func(ts time.Time) func(*repos.Repo) {
+		return func(r *repos.Repo) { r.MissingUpstreamAt = ts }
+	}
+
+	quarantined := func(r *repos.Repo) { r.Quarantined = true }
+
+	// state summarizes a stored repo as "name", "name (missing)" or
+	// "name (quarantined)".
+	state := func(r *repos.Repo) string {
+		switch {
+		case r.Quarantined:
+			return r.Name + " (quarantined)"
+		case r.IsMissingUpstream():
+			return r.Name + " (missing)"
+		default:
+			return r.Name
+		}
+	}
+
+	for _, tc := range []struct {
+		name      string
+		grace     time.Duration
+		threshold float64
+		stored    repos.Repos
+		sourced   repos.Repos
+		want      []string
+	}{
+		{
+			name:    "no grace period deletes missing repos",
+			stored:  repos.Repos{repo("a"), repo("b"), repo("c")},
+			sourced: repos.Repos{repo("a"), repo("b")},
+			want:    []string{"a", "b"},
+		},
+		{
+			name:    "missing repos are kept during the grace period",
+			grace:   time.Hour,
+			stored:  repos.Repos{repo("a"), repo("b"), repo("c"), repo("d", missingSince(now.Add(-time.Minute)))},
+			sourced: repos.Repos{repo("a"), repo("b")},
+			want:    []string{"a", "b", "c (missing)", "d (missing)"},
+		},
+		{
+			name:    "missing repos are deleted after the grace period",
+			grace:   time.Hour,
+			stored:  repos.Repos{repo("a"), repo("b", missingSince(now.Add(-2*time.Hour)))},
+			sourced: repos.Repos{repo("a")},
+			want:    []string{"a"},
+		},
+		{
+			name:    "missing repos are restored when they reappear",
+			grace:   time.Hour,
+			stored:  repos.Repos{repo("a"), repo("b", missingSince(now.Add(-time.Minute)), quarantined)},
+			sourced: repos.Repos{repo("a"), repo("b")},
+			want:    []string{"a", "b"},
+		},
+		{
+			name:    "missing repos are deleted when their name is taken",
+			grace:   time.Hour,
+			stored:  repos.Repos{repo("a"), repo("b")},
+			sourced: repos.Repos{repo("a"), repo("b", func(r *repos.Repo) { r.ExternalRepo.ID = "b2" })},
+			want:    []string{"a", "b"},
+		},
+		{
+			name:      "below the quarantine threshold",
+			threshold: 0.5,
+			stored:    repos.Repos{repo("a"), repo("b"), repo("c")},
+			sourced:   repos.Repos{repo("a"), repo("b")},
+			want:      []string{"a", "b"},
+		},
+		{
+			name:      "above the quarantine threshold",
+			threshold: 0.5,
+			stored:    repos.Repos{repo("a"), repo("b"), repo("c")},
+			sourced:   repos.Repos{repo("a")},
+			want:      []string{"a", "b (quarantined)", "c (quarantined)"},
+		},
+		{
+			name:      "quarantined repos are kept after the grace period",
+			grace:     time.Hour,
+			threshold: 0.5,
+			stored:    repos.Repos{repo("a"), repo("b", missingSince(now.Add(-2*time.Hour)), quarantined)},
+			sourced:   repos.Repos{repo("a")},
+			want:      []string{"a", "b (quarantined)"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := new(repos.FakeStore)
+
+			if err := store.UpsertExternalServices(ctx, svc.Clone()); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.UpsertRepos(ctx, tc.stored.Clone()...); err != nil {
+				t.Fatal(err)
+			}
+
+			syncer := &repos.Syncer{
+				Store:               store,
+				Sourcer:             repos.NewFakeSourcer(nil, repos.NewFakeSource(svc.Clone(), nil, tc.sourced.Clone()...)),
+				Now:                 func() time.Time { return now },
+				DeletionGracePeriod: func() time.Duration { return tc.grace },
+				QuarantineThreshold: func() float64 { return tc.threshold },
+			}
+
+			if err := syncer.Sync(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			stored, err := store.ListRepos(ctx, repos.StoreListReposArgs{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			have := make([]string, 0, len(stored))
+			for _, r := range stored {
+				have = append(have, state(r))
+			}
+			sort.Strings(have)
+
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Errorf("stored repos:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestSync_SyncSubset(t *testing.T) {
 	t.Parallel()
 
@@ -26,10 +26,23 @@ import (
 
 // A Changeset of an existing Repo.
 type Changeset struct {
+	// Title, Body, HeadRef and BaseRef are only used when creating a new
+	// Changeset on the code host with a ChangesetSource. HeadRef and BaseRef
+	// are fully qualified refs (e.g. "refs/heads/master").
+	Title   string
+	Body    string
+	HeadRef string
+	BaseRef string
+
 	*a8n.Changeset
 	*Repo
 }
 
+// abbreviateRef removes the "refs/heads/" prefix from a given ref.
+func abbreviateRef(ref string) string {
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
+
 // An ExternalService is defines a Source that yields Repos.
 type ExternalService struct {
 	ID          int64
@@ -547,12 +560,28 @@ type Repo struct {
 	Enabled bool
 	// Archived is whether the repository has been archived.
 	Archived bool
+	// Topics are the topics (or tags) the repository is labeled with on the
+	// code host.
+	Topics []string
+	// Stars is the number of stars the repository has on the code host.
+	Stars int
+	// Visibility is the visibility of the repository on the code host. It is
+	// one of the RepoVisibility constants, or empty if unknown.
+	Visibility RepoVisibility
 	// CreatedAt is when this repository was created on Sourcegraph.
 	CreatedAt time.Time
 	// UpdatedAt is when this repository's metadata was last updated on Sourcegraph.
 	UpdatedAt time.Time
 	// DeletedAt is when this repository was soft-deleted from Sourcegraph.
 	DeletedAt time.Time
+	// MissingUpstreamAt is when this repository was first found missing from
+	// all of its sources. Such repositories are kept around for a grace period
+	// before being deleted.
+	MissingUpstreamAt time.Time
+	// Quarantined is whether this repository went missing upstream in a sync
+	// that removed an unusually large share of an external service's
+	// repositories. Quarantined repositories aren't deleted automatically.
+	Quarantined bool
 	// ExternalRepo identifies this repository by its ID on the external service where it resides (and the external
 	// service itself).
 	ExternalRepo api.ExternalRepoSpec
@@ -562,6 +591,46 @@ type Repo struct {
 	Metadata interface{}
 }
 
+// RepoVisibility is the visibility of a Repo on its code host.
+type RepoVisibility string
+
+// RepoVisibility constants.
+const (
+	RepoVisibilityPublic   RepoVisibility = "public"
+	RepoVisibilityPrivate  RepoVisibility = "private"
+	RepoVisibilityInternal RepoVisibility = "internal"
+)
+
+// visibility returns the RepoVisibility of a repo on a code host that only
+// distinguishes between public and private repos.
+func visibility(private bool) RepoVisibility {
+	if private {
+		return RepoVisibilityPrivate
+	}
+	return RepoVisibilityPublic
+}
+
+// normalizeTopics returns the given code host topics lower cased and
+// de-duplicated, so that they can be matched case-insensitively.
+func normalizeTopics(topics ...string) []string {
+	if len(topics) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(topics))
+	normalized := make([]string, 0, len(topics))
+	for _, t := range topics {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		normalized = append(normalized, t)
+	}
+
+	return normalized
+}
+
 // A SourceInfo represents a source a Repo belongs to (such as an external service).
 type SourceInfo struct {
 	ID       string
@@ -608,6 +677,9 @@ func (r *Repo) ExternalServiceIDs() []int64 {
 // IsDeleted returns true if the repo is deleted.
 func (r *Repo) IsDeleted() bool { return !r.DeletedAt.IsZero() }
 
+// IsMissingUpstream returns true if the repo is missing from all of its sources.
+func (r *Repo) IsMissingUpstream() bool { return !r.MissingUpstreamAt.IsZero() }
+
 // Update updates Repo r with the fields from the given newer Repo n,
 // returning true if modified.
 func (r *Repo) Update(n *Repo) (modified bool) {
@@ -640,10 +712,30 @@ func (r *Repo) Update(n *Repo) (modified bool) {
 		r.Fork, modified = n.Fork, true
 	}
 
+	if !reflect.DeepEqual(r.Topics, n.Topics) {
+		r.Topics, modified = n.Topics, true
+	}
+
+	if r.Stars != n.Stars {
+		r.Stars, modified = n.Stars, true
+	}
+
+	if r.Visibility != n.Visibility {
+		r.Visibility, modified = n.Visibility, true
+	}
+
 	if !reflect.DeepEqual(r.Sources, n.Sources) {
 		r.Sources, modified = n.Sources, true
 	}
 
+	if !r.MissingUpstreamAt.Equal(n.MissingUpstreamAt) {
+		r.MissingUpstreamAt, modified = n.MissingUpstreamAt, true
+	}
+
+	if r.Quarantined != n.Quarantined {
+		r.Quarantined, modified = n.Quarantined, true
+	}
+
 	if !reflect.DeepEqual(r.Metadata, n.Metadata) {
 		r.Metadata, modified = n.Metadata, true
 	}
@@ -657,6 +749,9 @@ func (r *Repo) Clone() *Repo {
 		return nil
 	}
 	clone := *r
+	if r.Topics != nil {
+		clone.Topics = append([]string(nil), r.Topics...)
+	}
 	if r.Sources != nil {
 		clone.Sources = make(map[string]*SourceInfo, len(r.Sources))
 		for k, v := range r.Sources {
@@ -1,6 +1,7 @@
 package repos
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -467,3 +468,18 @@ func formatJSON(t testing.TB, s string) string {
 
 	return formatted
 }
+
+func TestNormalizeTopics(t *testing.T) {
+	for _, tc := range []struct {
+		in   []string
+		want []string
+	}{
+		{in: nil, want: nil},
+		{in: []string{" ", ""}, want: []string{}},
+		{in: []string{"Go", "go", " database "}, want: []string{"go", "database"}},
+	} {
+		if have := normalizeTopics(tc.in...); !reflect.DeepEqual(have, tc.want) {
+			t.Errorf("normalizeTopics(%q): have %q, want %q", tc.in, have, tc.want)
+		}
+	}
+}
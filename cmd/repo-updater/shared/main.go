@@ -127,11 +127,13 @@ func Main() {
 	gps := repos.NewGitolitePhabricatorMetadataSyncer(store)
 
 	syncer := &repos.Syncer{
-		Store:            store,
-		Sourcer:          src,
-		DisableStreaming: !streamingSyncer,
-		Logger:           log15.Root(),
-		Now:              clock,
+		Store:               store,
+		Sourcer:             src,
+		DisableStreaming:    !streamingSyncer,
+		DeletionGracePeriod: repos.GetDeletionGracePeriod,
+		QuarantineThreshold: repos.GetDeletionQuarantineThreshold,
+		Logger:              log15.Root(),
+		Now:                 clock,
 	}
 	if envvar.SourcegraphDotComMode() {
 		syncer.FailFullSync = true
@@ -24,6 +24,7 @@ type Entry struct {
 	ParentKind string
 	Pattern    string
 	Signature  string
+	Access     string
 
 	FileLimited bool
 }
@@ -231,6 +232,7 @@ func (p *ctagsProcess) Parse(name string, content []byte) (entries []Entry, err
 			ParentKind:  rep.ScopeKind,
 			Pattern:     rep.Pattern,
 			Signature:   rep.Signature,
+			Access:      rep.Access,
 			FileLimited: rep.File,
 		})
 	}
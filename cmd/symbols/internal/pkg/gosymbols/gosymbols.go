@@ -0,0 +1,223 @@
+// Package gosymbols extracts the symbols defined in Go files with the Go
+// parser, which knows more about them than ctags does.
+package gosymbols
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
+)
+
+// Extract returns the symbols declared at the top level of the Go file with
+// the given path and content, and the fields and methods of the types
+// declared there. Files with syntax errors are extracted as far as they
+// could be parsed.
+func Extract(path string, content []byte) ([]protocol.Symbol, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, content, 0)
+	if f == nil || !f.Package.IsValid() {
+		return nil, err
+	}
+
+	e := &extractor{
+		fset:  fset,
+		path:  path,
+		lines: strings.Split(string(content), "\n"),
+		pkg:   f.Name.Name,
+		types: map[string]string{},
+	}
+
+	// Record the kinds of the types declared in the file first, so that
+	// methods declared before their receiver type get the right parent kind.
+	for _, decl := range f.Decls {
+		if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.TYPE {
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				e.types[ts.Name.Name] = typeKind(ts)
+			}
+		}
+	}
+
+	e.add(f.Name, "package", "", "", "")
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			e.funcDecl(d)
+		case *ast.GenDecl:
+			e.genDecl(d)
+		}
+	}
+	return e.symbols, nil
+}
+
+type extractor struct {
+	fset  *token.FileSet
+	path  string
+	lines []string
+	pkg   string
+
+	// types are the kinds of the types declared in the file, by name.
+	types map[string]string
+
+	symbols []protocol.Symbol
+}
+
+// add adds a symbol for the identifier with the given kind, signature and
+// parent type.
+func (e *extractor) add(name *ast.Ident, kind, signature, parent, parentKind string) {
+	if name == nil || name.Name == "_" {
+		return
+	}
+
+	line := e.fset.Position(name.Pos()).Line
+	var pattern string
+	if line > 0 && line <= len(e.lines) {
+		pattern = "/^" + e.lines[line-1] + "$/"
+	}
+
+	scope := e.pkg
+	switch {
+	case kind == "package":
+		scope = ""
+	case parent != "":
+		scope += "." + parent
+	}
+
+	e.symbols = append(e.symbols, protocol.Symbol{
+		Name:       name.Name,
+		Path:       e.path,
+		Line:       line,
+		Kind:       kind,
+		Language:   "Go",
+		Parent:     parent,
+		ParentKind: parentKind,
+		Scope:      scope,
+		Signature:  signature,
+		Pattern:    pattern,
+		Exported:   kind == "package" || name.IsExported(),
+	})
+}
+
+func (e *extractor) funcDecl(d *ast.FuncDecl) {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		e.add(d.Name, "func", e.signature(d.Type), "", "")
+		return
+	}
+
+	recv := receiverType(d.Recv.List[0].Type)
+	recvKind, ok := e.types[recv]
+	if !ok {
+		recvKind = "type"
+	}
+	e.add(d.Name, "method", e.signature(d.Type), recv, recvKind)
+}
+
+func (e *extractor) genDecl(d *ast.GenDecl) {
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			kind := e.types[s.Name.Name]
+			e.add(s.Name, kind, "", "", "")
+			e.members(s, kind)
+
+		case *ast.ValueSpec:
+			kind := "var"
+			if d.Tok == token.CONST {
+				kind = "const"
+			}
+			for _, name := range s.Names {
+				e.add(name, kind, "", "", "")
+			}
+		}
+	}
+}
+
+// members adds the fields of struct types and the methods of interface types.
+func (e *extractor) members(s *ast.TypeSpec, kind string) {
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		for _, field := range t.Fields.List {
+			if len(field.Names) == 0 {
+				e.add(embeddedName(field.Type), "field", "", s.Name.Name, kind)
+				continue
+			}
+			for _, name := range field.Names {
+				e.add(name, "field", "", s.Name.Name, kind)
+			}
+		}
+
+	case *ast.InterfaceType:
+		for _, method := range t.Methods.List {
+			ft, ok := method.Type.(*ast.FuncType)
+			if !ok {
+				continue // embedded interface
+			}
+			for _, name := range method.Names {
+				e.add(name, "methodSpec", e.signature(ft), s.Name.Name, kind)
+			}
+		}
+	}
+}
+
+// signature returns the parameters and results of a function type, e.g.
+// "(path string) ([]byte, error)".
+func (e *extractor) signature(t *ast.FuncType) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, e.fset, &ast.FuncType{Params: t.Params, Results: t.Results}); err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(buf.String(), "func")
+}
+
+// typeKind returns the kind of the type declared by s.
+func typeKind(s *ast.TypeSpec) string {
+	if s.Assign.IsValid() {
+		return "alias"
+	}
+	switch s.Type.(type) {
+	case *ast.StructType:
+		return "struct"
+	case *ast.InterfaceType:
+		return "interface"
+	default:
+		return "type"
+	}
+}
+
+// receiverType returns the name of the type of a method receiver, without
+// pointers.
+func receiverType(expr ast.Expr) string {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// embeddedName returns the identifier an embedded field is named after.
+func embeddedName(expr ast.Expr) *ast.Ident {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.SelectorExpr:
+			return t.Sel
+		case *ast.Ident:
+			return t
+		default:
+			return nil
+		}
+	}
+}
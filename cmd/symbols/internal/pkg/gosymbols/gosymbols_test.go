@@ -0,0 +1,90 @@
+package gosymbols
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	src := `package foo
+
+func (p *parser) Parse(name string) ([]Entry, error) { return nil, nil }
+
+type parser struct {
+	io.Reader
+	cmd, Name string
+}
+
+type Parser interface {
+	Parse(name string) ([]Entry, error)
+	io.Closer
+}
+
+type Alias = parser
+
+const Max, _ = 1, 2
+
+var debug bool
+
+func New() *parser { return nil }
+`
+
+	symbols, err := Extract("a/foo.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type symbol struct {
+		Name, Kind, Parent, ParentKind, Scope, Signature string
+		Line                                             int
+		Exported                                         bool
+	}
+	var got []symbol
+	for _, s := range symbols {
+		if s.Path != "a/foo.go" || s.Language != "Go" {
+			t.Errorf("got path %q and language %q for %s", s.Path, s.Language, s.Name)
+		}
+		got = append(got, symbol{s.Name, s.Kind, s.Parent, s.ParentKind, s.Scope, s.Signature, s.Line, s.Exported})
+	}
+
+	want := []symbol{
+		{"foo", "package", "", "", "", "", 1, true},
+		{"Parse", "method", "parser", "struct", "foo.parser", "(name string) ([]Entry, error)", 3, true},
+		{"parser", "struct", "", "", "foo", "", 5, false},
+		{"Reader", "field", "parser", "struct", "foo.parser", "", 6, true},
+		{"cmd", "field", "parser", "struct", "foo.parser", "", 7, false},
+		{"Name", "field", "parser", "struct", "foo.parser", "", 7, true},
+		{"Parser", "interface", "", "", "foo", "", 10, true},
+		{"Parse", "methodSpec", "Parser", "interface", "foo.Parser", "(name string) ([]Entry, error)", 11, true},
+		{"Alias", "alias", "", "", "foo", "", 15, true},
+		{"Max", "const", "", "", "foo", "", 17, true},
+		{"debug", "var", "", "", "foo", "", 19, false},
+		{"New", "func", "", "", "foo", "() *parser", 21, true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%+v\nwant\n%+v", got, want)
+	}
+
+	if want := "/^func New() *parser { return nil }$/"; symbols[len(symbols)-1].Pattern != want {
+		t.Errorf("got pattern %q, want %q", symbols[len(symbols)-1].Pattern, want)
+	}
+}
+
+func TestExtract_syntaxError(t *testing.T) {
+	symbols, err := Extract("foo.go", []byte("package foo\n\nfunc A() {}\n\nfunc B( {\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, s := range symbols {
+		names = append(names, s.Name)
+	}
+	if want := []string{"foo", "A"}; !reflect.DeepEqual(names[:2], want) {
+		t.Errorf("got %v, want %v first", names, want)
+	}
+
+	if _, err := Extract("foo.go", []byte("not go")); err == nil {
+		t.Error("got no error for a file without a package clause")
+	}
+}
@@ -0,0 +1,222 @@
+package symbols
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keegancsmith/sqlf"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// maxDefinitionCandidates is the maximum number of symbols with the name of
+// the identifier that are ranked to find its definitions.
+const maxDefinitionCandidates = 1000
+
+func (s *Service) handleDefinition(w http.ResponseWriter, r *http.Request) {
+	var args protocol.DefinitionArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.definition(r.Context(), args)
+	if err != nil {
+		if err == context.Canceled && r.Context().Err() == context.Canceled {
+			return // client went away
+		}
+		log15.Error("Symbol definition failed", "args", args, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// definition finds the identifier at the position given in args and returns
+// the symbols with its name, ranked by how likely they are its definition.
+// This is approximate: it doesn't resolve imports or scopes.
+func (s *Service) definition(ctx context.Context, args protocol.DefinitionArgs) (result *protocol.DefinitionResult, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "definition")
+	span.SetTag("repo", args.Repo)
+	span.SetTag("commitID", args.CommitID)
+	span.SetTag("path", args.Path)
+	span.SetTag("position", fmt.Sprintf("%d:%d", args.Line, args.Character))
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+
+	data, err := s.ReadFile(ctx, gitserver.Repo{Name: args.Repo}, args.CommitID, args.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &protocol.DefinitionResult{Identifier: identifierAt(data, args.Line, args.Character)}
+	if result.Identifier == "" {
+		return result, nil
+	}
+
+	dbFile, err := s.getDBFile(ctx, protocol.SearchArgs{Repo: args.Repo, CommitID: args.CommitID})
+	if err != nil {
+		return nil, err
+	}
+	db, err := sqlx.Open("sqlite3_with_pcre", dbFile)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	candidates, err := symbolsNamed(ctx, db, result.Identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	language, err := fileLanguage(ctx, db, args.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Symbols = rankDefinitions(candidates, args.Path, language)
+
+	first := args.First
+	if first <= 0 || first > maxDefinitionCandidates {
+		first = maxDefinitionCandidates
+	}
+	if len(result.Symbols) > first {
+		result.Symbols = result.Symbols[:first]
+	}
+
+	span.SetTag("hits", len(result.Symbols))
+	return result, nil
+}
+
+// symbolsNamed returns the symbols with the given name.
+func symbolsNamed(ctx context.Context, db *sqlx.DB, name string) ([]protocol.Symbol, error) {
+	q := sqlf.Sprintf("SELECT * FROM symbols WHERE name = %s LIMIT %s", name, maxDefinitionCandidates)
+
+	var symbolsInDB []symbolInDB
+	if err := db.SelectContext(ctx, &symbolsInDB, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+		return nil, err
+	}
+
+	symbols := make([]protocol.Symbol, 0, len(symbolsInDB))
+	for _, symbolInDB := range symbolsInDB {
+		symbols = append(symbols, symbolInDBToSymbol(symbolInDB))
+	}
+	return symbols, nil
+}
+
+// fileLanguage returns the language of the symbols in the file with the given
+// path, or an empty string if it has none.
+func fileLanguage(ctx context.Context, db *sqlx.DB, path string) (string, error) {
+	q := sqlf.Sprintf("SELECT language FROM symbols WHERE path = %s LIMIT 1", path)
+
+	var languages []string
+	if err := db.SelectContext(ctx, &languages, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+		return "", err
+	}
+
+	if len(languages) == 0 {
+		return "", nil
+	}
+	return languages[0], nil
+}
+
+// identifierAt returns the identifier at the given zero-based line and
+// character in data, or an empty string if there's none. A position right
+// after an identifier counts as being on it.
+func identifierAt(data []byte, line, character int) string {
+	lines := strings.Split(string(data), "\n")
+	if line < 0 || line >= len(lines) {
+		return ""
+	}
+
+	runes := []rune(lines[line])
+	if character < 0 || character > len(runes) {
+		return ""
+	}
+
+	if character == len(runes) || !isIdentifierRune(runes[character]) {
+		if character == 0 || !isIdentifierRune(runes[character-1]) {
+			return ""
+		}
+		character--
+	}
+
+	start, end := character, character+1
+	for start > 0 && isIdentifierRune(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && isIdentifierRune(runes[end]) {
+		end++
+	}
+
+	if unicode.IsDigit(runes[start]) {
+		return ""
+	}
+
+	return string(runes[start:end])
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// rankDefinitions sorts the given candidate definitions of an identifier in
+// the file with the given path and language, best first. Candidates in the
+// same file come first, then those in the same directory (which is the
+// package in many languages), then those of the same language. The
+// language is inferred from the file extension if it's empty.
+func rankDefinitions(candidates []protocol.Symbol, filePath, language string) []protocol.Symbol {
+	dir, ext := path.Dir(filePath), path.Ext(filePath)
+
+	score := func(s protocol.Symbol) int {
+		switch {
+		case s.Path == filePath:
+			return 3
+		case path.Dir(s.Path) == dir:
+			return 2
+		case language != "" && strings.EqualFold(s.Language, language):
+			return 1
+		case language == "" && path.Ext(s.Path) == ext:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	ranked := append([]protocol.Symbol(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		si, sj := score(ranked[i]), score(ranked[j])
+		if si != sj {
+			return si > sj
+		}
+		if ranked[i].Path != ranked[j].Path {
+			return ranked[i].Path < ranked[j].Path
+		}
+		return ranked[i].Line < ranked[j].Line
+	})
+	return ranked
+}
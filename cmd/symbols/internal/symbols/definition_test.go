@@ -0,0 +1,73 @@
+package symbols
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
+)
+
+func TestIdentifierAt(t *testing.T) {
+	data := []byte("package foo\n\nfunc (s *Service) Start() error {\n\treturn s.start(x1, $el)\n}\n")
+
+	for _, test := range []struct {
+		Line, Character int
+		Want            string
+	}{
+		{Line: 0, Character: 0, Want: "package"},
+		{Line: 0, Character: 9, Want: "foo"},
+		{Line: 0, Character: 11, Want: "foo"},
+		{Line: 1, Character: 0, Want: ""},
+		{Line: 2, Character: 9, Want: "Service"},
+		{Line: 2, Character: 16, Want: "Service"},
+		{Line: 2, Character: 8, Want: ""},
+		{Line: 3, Character: 10, Want: "start"},
+		{Line: 3, Character: 16, Want: "x1"},
+		{Line: 3, Character: 20, Want: "$el"},
+		{Line: 3, Character: 100, Want: ""},
+		{Line: 100, Character: 0, Want: ""},
+		{Line: -1, Character: 0, Want: ""},
+	} {
+		if got := identifierAt(data, test.Line, test.Character); got != test.Want {
+			t.Errorf("identifierAt(%d, %d) returned %q, wanted %q", test.Line, test.Character, got, test.Want)
+		}
+	}
+
+	if got := identifierAt([]byte("x = 123"), 0, 5); got != "" {
+		t.Errorf("identifierAt on a number returned %q, wanted an empty string", got)
+	}
+}
+
+func TestRankDefinitions(t *testing.T) {
+	candidates := []protocol.Symbol{
+		{Name: "Foo", Path: "other/foo.py", Language: "Python", Line: 1},
+		{Name: "Foo", Path: "other/foo.go", Language: "Go", Line: 7},
+		{Name: "Foo", Path: "pkg/bar.go", Language: "Go", Line: 3},
+		{Name: "Foo", Path: "pkg/foo.go", Language: "Go", Line: 9},
+		{Name: "Foo", Path: "pkg/foo.go", Language: "Go", Line: 2},
+		{Name: "Foo", Path: "another/foo.go", Language: "Go", Line: 5},
+	}
+
+	var got []string
+	for _, s := range rankDefinitions(candidates, "pkg/foo.go", "Go") {
+		got = append(got, s.Path)
+	}
+	want := []string{"pkg/foo.go", "pkg/foo.go", "pkg/bar.go", "another/foo.go", "other/foo.go", "other/foo.py"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rankDefinitions returned %v, wanted %v", got, want)
+	}
+
+	if ranked := rankDefinitions(candidates, "pkg/foo.go", "Go"); ranked[0].Line != 2 {
+		t.Errorf("rankDefinitions ranked line %d of the same file first, wanted line 2", ranked[0].Line)
+	}
+
+	// Without a language, the file extension is used.
+	got = nil
+	for _, s := range rankDefinitions(candidates, "lib/baz.py", "") {
+		got = append(got, s.Path)
+	}
+	want = []string{"other/foo.py", "another/foo.go", "other/foo.go", "pkg/bar.go", "pkg/foo.go", "pkg/foo.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rankDefinitions without a language returned %v, wanted %v", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package symbols
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/pkg/ctags"
+	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/pkg/gosymbols"
+	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
+)
+
+// A SymbolExtractor extracts the symbols defined in a file.
+type SymbolExtractor interface {
+	Extract(ctx context.Context, path string, content []byte) ([]protocol.Symbol, error)
+}
+
+// ctagsExtractor is a SymbolExtractor that uses the service's pool of ctags
+// parser processes. It supports every language ctags does.
+type ctagsExtractor struct {
+	s *Service
+}
+
+func (e ctagsExtractor) Extract(ctx context.Context, path string, content []byte) ([]protocol.Symbol, error) {
+	entries, err := e.s.parse(ctx, parseRequest{path: path, data: content})
+
+	symbols := make([]protocol.Symbol, 0, len(entries))
+	for _, e := range entries {
+		if e.Name == "" || strings.HasPrefix(e.Name, "__anon") || strings.HasPrefix(e.Parent, "__anon") || strings.HasPrefix(e.Name, "AnonymousFunction") || strings.HasPrefix(e.Parent, "AnonymousFunction") {
+			continue
+		}
+		symbols = append(symbols, entryToSymbol(e))
+	}
+	return symbols, err
+}
+
+// goExtractor is a SymbolExtractor that parses Go files with the Go parser.
+type goExtractor struct{}
+
+func (goExtractor) Extract(ctx context.Context, path string, content []byte) ([]protocol.Symbol, error) {
+	return gosymbols.Extract(path, content)
+}
+
+// extractors are the SymbolExtractors other than ctags, by the name they're
+// selected with in site config, with the language they support.
+var extractors = map[string]struct {
+	language  string
+	extractor SymbolExtractor
+}{
+	"go": {language: "Go", extractor: goExtractor{}},
+}
+
+// extractorLanguages are the languages of files by extension, for languages
+// an extractor other than ctags supports.
+var extractorLanguages = map[string]string{
+	".go": "Go",
+}
+
+// extractorSelector returns a function that returns the SymbolExtractor to
+// use for the file with the given path, as currently configured by
+// s.Extractors. Files use ctags unless another extractor is configured for
+// their language.
+func (s *Service) extractorSelector() func(path string) SymbolExtractor {
+	var names map[string]string
+	if s.Extractors != nil {
+		names = s.Extractors()
+	}
+
+	ctags := ctagsExtractor{s: s}
+	return func(filePath string) SymbolExtractor {
+		language, ok := extractorLanguages[path.Ext(filePath)]
+		if !ok {
+			return ctags
+		}
+		if x, ok := extractors[names[language]]; ok && x.language == language {
+			return x.extractor
+		}
+		return ctags
+	}
+}
+
+func entryToSymbol(e ctags.Entry) protocol.Symbol {
+	return protocol.Symbol{
+		Name:        e.Name,
+		Path:        e.Path,
+		Line:        e.Line,
+		Kind:        e.Kind,
+		Language:    e.Language,
+		Parent:      e.Parent,
+		ParentKind:  e.ParentKind,
+		Scope:       e.Parent, // ctags scopes are fully qualified
+		Signature:   e.Signature,
+		Pattern:     e.Pattern,
+		Visibility:  e.Access,
+		Exported:    e.Access == "public",
+		FileLimited: e.FileLimited,
+	}
+}
@@ -0,0 +1,25 @@
+package symbols
+
+import "testing"
+
+func TestExtractorSelector(t *testing.T) {
+	tests := []struct {
+		extractors map[string]string
+		path       string
+		wantGo     bool
+	}{
+		{extractors: nil, path: "a.go"},
+		{extractors: map[string]string{"Go": "go"}, path: "a/b.go", wantGo: true},
+		{extractors: map[string]string{"Go": "go"}, path: "a.js"},
+		{extractors: map[string]string{"Go": "ctags"}, path: "a.go"},
+		{extractors: map[string]string{"JavaScript": "go"}, path: "a.js"},
+		{extractors: map[string]string{"Go": "unknown"}, path: "a.go"},
+	}
+	for _, test := range tests {
+		s := &Service{Extractors: func() map[string]string { return test.extractors }}
+		extractor := s.extractorSelector()(test.path)
+		if _, isGo := extractor.(goExtractor); isGo != test.wantGo {
+			t.Errorf("extractors %v: got %T for %s", test.extractors, extractor, test.path)
+		}
+	}
+}
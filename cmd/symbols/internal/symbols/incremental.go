@@ -0,0 +1,172 @@
+package symbols
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keegancsmith/sqlf"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/diskcache"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+const (
+	// maxIncrementalAncestors is the number of ancestors of a commit that are
+	// checked for a database to index the commit incrementally from.
+	maxIncrementalAncestors = 25
+
+	// maxIncrementalChangedPaths is the maximum number of files that may have
+	// changed since the ancestor for a commit to be indexed incrementally.
+	// Beyond that, indexing from scratch is about as fast.
+	maxIncrementalChangedPaths = 5000
+)
+
+// writeSymbolsToNewDB writes the symbols of repo@commit to the blank database
+// file dbFile. If the database of a recent ancestor of the commit is in the
+// disk cache, it's copied and only the files that changed since then are
+// parsed. Otherwise every file is parsed.
+func (s *Service) writeSymbolsToNewDB(ctx context.Context, dbFile string, repoName api.RepoName, commitID api.CommitID) error {
+	if s.ListAncestors == nil || s.DiffPaths == nil || s.FetchTarPaths == nil {
+		return s.writeAllSymbolsToNewDB(ctx, dbFile, repoName, commitID)
+	}
+
+	ok, err := s.writeSymbolsIncrementally(ctx, dbFile, repoName, commitID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log15.Warn("Incremental symbol indexing failed, indexing from scratch.", "repo", repoName, "commitID", commitID, "error", err)
+	}
+	if ok && err == nil {
+		indexCount.WithLabelValues("incremental").Inc()
+		return nil
+	}
+	indexCount.WithLabelValues("full").Inc()
+
+	// Start over from a blank database.
+	if err := os.Truncate(dbFile, 0); err != nil {
+		return err
+	}
+	return s.writeAllSymbolsToNewDB(ctx, dbFile, repoName, commitID)
+}
+
+// writeSymbolsIncrementally copies the database of the nearest ancestor of
+// repo@commit that's in the disk cache to dbFile and updates the symbols of
+// the files that changed since. It reports false if there's no such ancestor
+// or too many files changed, in which case dbFile may have been written to.
+func (s *Service) writeSymbolsIncrementally(ctx context.Context, dbFile string, repoName api.RepoName, commitID api.CommitID) (ok bool, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "writeSymbolsIncrementally")
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.SetTag("ok", ok)
+		span.Finish()
+	}()
+
+	repo := gitserver.Repo{Name: repoName}
+	ancestors, err := s.ListAncestors(ctx, repo, commitID, maxIncrementalAncestors)
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		base     api.CommitID
+		baseFile *diskcache.File
+	)
+	for _, ancestor := range ancestors {
+		f, err := s.cache.OpenIfExists(dbKey(repoName, ancestor))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		base, baseFile = ancestor, f
+		break
+	}
+	if baseFile == nil {
+		return false, nil
+	}
+	defer baseFile.Close()
+	span.SetTag("base", string(base))
+
+	changes, err := s.DiffPaths(ctx, repo, base, commitID)
+	if err != nil {
+		return false, err
+	}
+
+	changed := len(changes.Added) + len(changes.Modified) + len(changes.Deleted)
+	span.SetTag("changed", changed)
+	if changed > maxIncrementalChangedPaths {
+		return false, nil
+	}
+
+	if err := copyToFile(dbFile, baseFile); err != nil {
+		return false, err
+	}
+
+	db, err := sqlx.Open("sqlite3_with_pcre", dbFile)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	for _, paths := range [][]string{changes.Modified, changes.Deleted} {
+		for _, path := range paths {
+			q := sqlf.Sprintf("DELETE FROM symbols WHERE path = %s", path)
+			if _, err := tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	// An empty list of paths would fetch every file.
+	if paths := append(append([]string{}, changes.Added...), changes.Modified...); len(paths) > 0 {
+		if err := s.insertSymbols(ctx, tx, repoName, commitID, paths); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// copyToFile overwrites the file at path with the contents of r.
+func copyToFile(path string, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+var indexCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "symbols",
+	Subsystem: "store",
+	Name:      "indexes",
+	Help:      "The total number of commits indexed, by whether they were indexed incrementally or in full.",
+}, []string{"type"})
+
+func init() {
+	prometheus.MustRegister(indexCount)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"runtime"
-	"strings"
 	"sync"
 
 	opentracing "github.com/opentracing/opentracing-go"
@@ -37,7 +36,10 @@ func (s *Service) startParsers() error {
 	return nil
 }
 
-func (s *Service) parseUncached(ctx context.Context, repo api.RepoName, commitID api.CommitID, callback func(symbol protocol.Symbol) error) (err error) {
+// parseUncached parses the symbols of the files of the repository at the
+// commit and calls callback for each. If paths is non-nil, only the files with
+// those paths are parsed.
+func (s *Service) parseUncached(ctx context.Context, repo api.RepoName, commitID api.CommitID, paths []string, callback func(symbol protocol.Symbol) error) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "parseUncached")
 	defer func() {
 		if err != nil {
@@ -48,6 +50,9 @@ func (s *Service) parseUncached(ctx context.Context, repo api.RepoName, commitID
 	}()
 	span.SetTag("repo", string(repo))
 	span.SetTag("commit", string(commitID))
+	if paths != nil {
+		span.SetTag("paths", len(paths))
+	}
 
 	tr := trace.New("parseUncached", string(repo))
 	tr.LazyPrintf("commitID: %s", commitID)
@@ -63,7 +68,7 @@ func (s *Service) parseUncached(ctx context.Context, repo api.RepoName, commitID
 	}()
 
 	tr.LazyPrintf("fetch")
-	parseRequests, errChan, err := s.fetchRepositoryArchive(ctx, repo, commitID)
+	parseRequests, errChan, err := s.fetchRepositoryArchive(ctx, repo, commitID, paths)
 	tr.LazyPrintf("fetch (returned chans)")
 	if err != nil {
 		return err
@@ -77,6 +82,7 @@ func (s *Service) parseUncached(ctx context.Context, repo api.RepoName, commitID
 		wg  sync.WaitGroup
 		sem = make(chan struct{}, runtime.GOMAXPROCS(0))
 	)
+	extractor := s.extractorSelector()
 	tr.LazyPrintf("parse")
 	totalParseRequests := 0
 	for req := range parseRequests {
@@ -96,21 +102,18 @@ func (s *Service) parseUncached(ctx context.Context, repo api.RepoName, commitID
 				wg.Done()
 				<-sem
 			}()
-			entries, parseErr := s.parse(ctx, req)
+			symbols, parseErr := extractor(req.path).Extract(ctx, req.path, req.data)
 			if parseErr != nil && parseErr != context.Canceled && parseErr != context.DeadlineExceeded {
 				log15.Error("Error parsing symbols.", "repo", repo, "commitID", commitID, "path", req.path, "dataSize", len(req.data), "error", parseErr)
 			}
-			if len(entries) > 0 {
+			if len(symbols) > 0 {
 				mu.Lock()
 				defer mu.Unlock()
-				for _, e := range entries {
-					if e.Name == "" || strings.HasPrefix(e.Name, "__anon") || strings.HasPrefix(e.Parent, "__anon") || strings.HasPrefix(e.Name, "AnonymousFunction") || strings.HasPrefix(e.Parent, "AnonymousFunction") {
-						continue
-					}
+				for _, symbol := range symbols {
 					totalSymbols++
-					err = callback(entryToSymbol(e))
+					err = callback(symbol)
 					if err != nil {
-						log15.Error("Failed to add symbol", "symbol", e, "error", err)
+						log15.Error("Failed to add symbol", "symbol", symbol, "error", err)
 						return
 					}
 				}
@@ -175,21 +178,6 @@ func (s *Service) parse(ctx context.Context, req parseRequest) (entries []ctags.
 	}
 }
 
-func entryToSymbol(e ctags.Entry) protocol.Symbol {
-	return protocol.Symbol{
-		Name:        e.Name,
-		Path:        e.Path,
-		Line:        e.Line,
-		Kind:        e.Kind,
-		Language:    e.Language,
-		Parent:      e.Parent,
-		ParentKind:  e.ParentKind,
-		Signature:   e.Signature,
-		Pattern:     e.Pattern,
-		FileLimited: e.FileLimited,
-	}
-}
-
 var (
 	parsing = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: "symbols",
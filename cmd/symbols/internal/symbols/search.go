@@ -115,8 +115,8 @@ func (s *Service) search(ctx context.Context, args protocol.SearchArgs) (result
 // specified in `args`. If the database doesn't already exist in the disk cache,
 // it will create a new one and write all the symbols into it.
 func (s *Service) getDBFile(ctx context.Context, args protocol.SearchArgs) (string, error) {
-	diskcacheFile, err := s.cache.OpenWithPath(ctx, fmt.Sprintf("%d-%s@%s", symbolsDBVersion, args.Repo, args.CommitID), func(fetcherCtx context.Context, tempDBFile string) error {
-		err := s.writeAllSymbolsToNewDB(fetcherCtx, tempDBFile, args.Repo, args.CommitID)
+	diskcacheFile, err := s.cache.OpenWithPath(ctx, dbKey(args.Repo, args.CommitID), func(fetcherCtx context.Context, tempDBFile string) error {
+		err := s.writeSymbolsToNewDB(fetcherCtx, tempDBFile, args.Repo, args.CommitID)
 		if err != nil {
 			if err == context.Canceled {
 				log15.Error("Unable to parse repository symbols within the context", "repo", args.Repo, "commit", args.CommitID, "query", args.Query)
@@ -233,7 +233,12 @@ func filterSymbols(ctx context.Context, db *sqlx.DB, args protocol.SearchArgs) (
 // filenames to prevent a newer version of the symbols service from attempting
 // to read from a database created by an older (and likely incompatible) symbols
 // service. Increment this when you change the database schema.
-const symbolsDBVersion = 2
+const symbolsDBVersion = 3
+
+// dbKey returns the disk cache key of the sqlite3 database for repo@commit.
+func dbKey(repo api.RepoName, commitID api.CommitID) string {
+	return fmt.Sprintf("%d-%s@%s", symbolsDBVersion, repo, commitID)
+}
 
 // symbolInDB is the same as `protocol.Symbol`, but with two additional columns:
 // namelowercase and pathlowercase, which enable indexed case insensitive
@@ -250,6 +255,9 @@ type symbolInDB struct {
 	ParentKind    string
 	Signature     string
 	Pattern       string
+	Scope         string
+	Visibility    string
+	Exported      bool
 
 	FileLimited bool
 }
@@ -267,6 +275,9 @@ func symbolToSymbolInDB(symbol protocol.Symbol) symbolInDB {
 		ParentKind:    symbol.ParentKind,
 		Signature:     symbol.Signature,
 		Pattern:       symbol.Pattern,
+		Scope:         symbol.Scope,
+		Visibility:    symbol.Visibility,
+		Exported:      symbol.Exported,
 
 		FileLimited: symbol.FileLimited,
 	}
@@ -283,6 +294,9 @@ func symbolInDBToSymbol(symbolInDB symbolInDB) protocol.Symbol {
 		ParentKind: symbolInDB.ParentKind,
 		Signature:  symbolInDB.Signature,
 		Pattern:    symbolInDB.Pattern,
+		Scope:      symbolInDB.Scope,
+		Visibility: symbolInDB.Visibility,
+		Exported:   symbolInDB.Exported,
 
 		FileLimited: symbolInDB.FileLimited,
 	}
@@ -319,6 +333,9 @@ func (s *Service) writeAllSymbolsToNewDB(ctx context.Context, dbFile string, rep
 			parentkind VARCHAR(255) NOT NULL,
 			signature VARCHAR(255) NOT NULL,
 			pattern VARCHAR(255) NOT NULL,
+			scope VARCHAR(255) NOT NULL,
+			visibility VARCHAR(255) NOT NULL,
+			exported BOOLEAN NOT NULL,
 			filelimited BOOLEAN NOT NULL
 		)`)
 	if err != nil {
@@ -346,28 +363,35 @@ func (s *Service) writeAllSymbolsToNewDB(ctx context.Context, dbFile string, rep
 		return err
 	}
 
-	insertStatement, err := tx.PrepareNamed(
-		fmt.Sprintf(
-			"INSERT INTO symbols %s VALUES %s",
-			"( name,  namelowercase,  path,  pathlowercase,  line,  kind,  language,  parent,  parentkind,  signature,  pattern,  filelimited)",
-			"(:name, :namelowercase, :path, :pathlowercase, :line, :kind, :language, :parent, :parentkind, :signature, :pattern, :filelimited)"))
+	err = s.insertSymbols(ctx, tx, repoName, commitID, nil)
 	if err != nil {
 		return err
 	}
 
-	err = s.parseUncached(ctx, repoName, commitID, func(symbol protocol.Symbol) error {
-		symbolInDBValue := symbolToSymbolInDB(symbol)
-		_, err := insertStatement.Exec(&symbolInDBValue)
-		return err
-	})
+	err = tx.Commit()
 	if err != nil {
 		return err
 	}
 
-	err = tx.Commit()
+	return nil
+}
+
+// insertSymbols parses the symbols of repo@commit and inserts them into the
+// symbols table. If paths is non-nil, only the files with those paths are
+// parsed.
+func (s *Service) insertSymbols(ctx context.Context, tx *sqlx.Tx, repoName api.RepoName, commitID api.CommitID, paths []string) error {
+	insertStatement, err := tx.PrepareNamed(
+		fmt.Sprintf(
+			"INSERT INTO symbols %s VALUES %s",
+			"( name,  namelowercase,  path,  pathlowercase,  line,  kind,  language,  parent,  parentkind,  signature,  pattern,  scope,  visibility,  exported,  filelimited)",
+			"(:name, :namelowercase, :path, :pathlowercase, :line, :kind, :language, :parent, :parentkind, :signature, :pattern, :scope, :visibility, :exported, :filelimited)"))
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return s.parseUncached(ctx, repoName, commitID, paths, func(symbol protocol.Symbol) error {
+		symbolInDBValue := symbolToSymbolInDB(symbol)
+		_, err := insertStatement.Exec(&symbolInDBValue)
+		return err
+	})
 }
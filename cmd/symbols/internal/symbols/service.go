@@ -13,6 +13,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/diskcache"
 	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
 )
 
 // Service is the symbols service.
@@ -22,12 +23,34 @@ type Service struct {
 	// determine if the error is a bad request (eg invalid repo).
 	FetchTar func(context.Context, gitserver.Repo, api.CommitID) (io.ReadCloser, error)
 
+	// FetchTarPaths is like FetchTar, but the archive only includes the files
+	// with the given paths.
+	FetchTarPaths func(context.Context, gitserver.Repo, api.CommitID, []string) (io.ReadCloser, error)
+
+	// ListAncestors returns up to n ancestors of a commit, nearest first.
+	ListAncestors func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, n int) ([]api.CommitID, error)
+
+	// DiffPaths returns the paths of the files that changed between two
+	// commits. It's used with ListAncestors and FetchTarPaths to index a
+	// commit incrementally from the database of an ancestor. If any of them
+	// is nil, every commit is indexed from scratch.
+	DiffPaths func(ctx context.Context, repo gitserver.Repo, a, b api.CommitID) (*git.PathChanges, error)
+
 	// MaxConcurrentFetchTar is the maximum number of concurrent calls allowed
 	// to FetchTar. It defaults to 15.
 	MaxConcurrentFetchTar int
 
+	// ReadFile returns the contents of a file in a repository at the
+	// specified commit ID.
+	ReadFile func(context.Context, gitserver.Repo, api.CommitID, string) ([]byte, error)
+
 	NewParser func() (ctags.Parser, error)
 
+	// Extractors returns the names of the symbol extractors to use by
+	// language, e.g. {"Go": "go"}. Files in other languages, or all files if
+	// it's nil, are parsed with ctags.
+	Extractors func() map[string]string
+
 	// NumParserProcesses is the maximum number of ctags parser child processes to run.
 	NumParserProcesses int
 
@@ -81,6 +104,7 @@ func (s *Service) Handler() http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/definition", s.handleDefinition)
 	mux.HandleFunc("/healthz", s.handleHealthCheck)
 
 	return mux
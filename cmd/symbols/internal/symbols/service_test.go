@@ -13,6 +13,7 @@ import (
 	"path"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"testing"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
 	symbolsclient "github.com/sourcegraph/sourcegraph/pkg/symbols"
 	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
 )
 
 func init() {
@@ -38,6 +40,7 @@ func init() {
 			panic(fmt.Errorf("can't find the libsqlite3-pcre library because LIBSQLITE3_PCRE was not set and %s doesn't exist at the root of the repository - try building it with `./cmd/symbols/build.sh buildLibsqlite3Pcre`", libSqlite3Pcre))
 		}
 	}
+	MustRegisterSqlite3WithPcre()
 }
 
 func TestIsLiteralEquality(t *testing.T) {
@@ -73,8 +76,6 @@ func TestIsLiteralEquality(t *testing.T) {
 }
 
 func TestService(t *testing.T) {
-	MustRegisterSqlite3WithPcre()
-
 	tmpDir, err := ioutil.TempDir("", "")
 	if err != nil {
 		t.Fatal(err)
@@ -194,3 +195,81 @@ func (m mockParser) Parse(name string, content []byte) ([]ctags.Entry, error) {
 }
 
 func (mockParser) Close() {}
+
+func TestService_incremental(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { os.RemoveAll(tmpDir) }()
+
+	commits := map[api.CommitID]map[string]string{
+		"c1": {"a.js": "x", "b.js": "y"},
+		"c2": {"a.js": "z", "c.js": "w"},
+	}
+	var fetchedPaths []string
+	service := Service{
+		FetchTar: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
+			return createTar(commits[commit])
+		},
+		FetchTarPaths: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+			fetchedPaths = paths
+			files := map[string]string{}
+			for _, p := range paths {
+				files[p] = commits[commit][p]
+			}
+			return createTar(files)
+		},
+		ListAncestors: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, n int) ([]api.CommitID, error) {
+			if commit == "c2" {
+				return []api.CommitID{"c1"}, nil
+			}
+			return nil, nil
+		},
+		DiffPaths: func(ctx context.Context, repo gitserver.Repo, a, b api.CommitID) (*git.PathChanges, error) {
+			return &git.PathChanges{Added: []string{"c.js"}, Modified: []string{"a.js"}, Deleted: []string{"b.js"}}, nil
+		},
+		NewParser: func() (ctags.Parser, error) {
+			return contentParser{}, nil
+		},
+		Path: tmpDir,
+	}
+
+	if err := service.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(commit api.CommitID) []protocol.Symbol {
+		result, err := service.search(context.Background(), protocol.SearchArgs{Repo: "r", CommitID: commit, First: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Slice(result.Symbols, func(i, j int) bool { return result.Symbols[i].Path < result.Symbols[j].Path })
+		return result.Symbols
+	}
+
+	want := []protocol.Symbol{{Name: "x", Path: "a.js"}, {Name: "y", Path: "b.js"}}
+	if got := search("c1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if fetchedPaths != nil {
+		t.Errorf("c1 was indexed incrementally from %v", fetchedPaths)
+	}
+
+	want = []protocol.Symbol{{Name: "z", Path: "a.js"}, {Name: "w", Path: "c.js"}}
+	if got := search("c2"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if wantPaths := []string{"c.js", "a.js"}; !reflect.DeepEqual(fetchedPaths, wantPaths) {
+		t.Errorf("fetched paths %v, want %v", fetchedPaths, wantPaths)
+	}
+}
+
+// contentParser parses a single symbol named after the content of each file.
+type contentParser struct{}
+
+func (contentParser) Parse(name string, content []byte) ([]ctags.Entry, error) {
+	return []ctags.Entry{{Name: string(content), Path: name}}, nil
+}
+
+func (contentParser) Close() {}
@@ -23,14 +23,20 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/pkg/ctags"
 	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/symbols"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/debugserver"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
 	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
 	"github.com/sourcegraph/sourcegraph/pkg/tracer"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
 )
 
 const port = "3184"
 
+// maxDefinitionFileBytes is the maximum number of bytes of a file that are read
+// to find the identifier to look up definitions of.
+const maxDefinitionFileBytes = 1 << 20
+
 func main() {
 	var (
 		cacheDir       = env.Get("CACHE_DIR", "/tmp/symbols-cache", "directory to store cached symbols")
@@ -51,6 +57,24 @@ func main() {
 		FetchTar: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
 			return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar"})
 		},
+		FetchTarPaths: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+			return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar", Paths: paths})
+		},
+		ListAncestors: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, n int) ([]api.CommitID, error) {
+			commits, err := git.Commits(ctx, repo, git.CommitsOptions{Range: string(commit), N: uint(n), Skip: 1})
+			if err != nil {
+				return nil, err
+			}
+			ancestors := make([]api.CommitID, 0, len(commits))
+			for _, c := range commits {
+				ancestors = append(ancestors, c.ID)
+			}
+			return ancestors, nil
+		},
+		DiffPaths: git.DiffPaths,
+		ReadFile: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, path string) ([]byte, error) {
+			return git.ReadFile(ctx, repo, commit, path, maxDefinitionFileBytes)
+		},
 		NewParser: func() (ctags.Parser, error) {
 			parser, err := ctags.NewParser(ctags.GetCommand())
 			if err != nil {
@@ -58,6 +82,9 @@ func main() {
 			}
 			return parser, nil
 		},
+		Extractors: func() map[string]string {
+			return conf.Get().SearchSymbolsExtractors
+		},
 		Path: cacheDir,
 	}
 	if mb, err := strconv.ParseInt(cacheSizeMB, 10, 64); err != nil {
@@ -0,0 +1,61 @@
+package bitbucketcloudoauth
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const PkgName = "bitbucketcloudoauth"
+
+func init() {
+	conf.ContributeValidator(func(cfg conf.Unified) (problems []string) {
+		_, problems = parseConfig(&cfg)
+		return problems
+	})
+	go func() {
+		conf.Watch(func() {
+			newProviders, _ := parseConfig(conf.Get())
+			if len(newProviders) == 0 {
+				providers.Update(PkgName, nil)
+			} else {
+				newProvidersList := make([]providers.Provider, 0, len(newProviders))
+				for _, p := range newProviders {
+					newProvidersList = append(newProvidersList, p)
+				}
+				providers.Update(PkgName, newProvidersList)
+			}
+		})
+	}()
+}
+
+func parseConfig(cfg *conf.Unified) (ps map[schema.BitbucketCloudAuthProvider]providers.Provider, problems []string) {
+	ps = make(map[schema.BitbucketCloudAuthProvider]providers.Provider)
+	for _, pr := range cfg.Critical.AuthProviders {
+		if pr.BitbucketCloud == nil {
+			continue
+		}
+
+		if cfg.Critical.ExternalURL == "" {
+			problems = append(problems, "`externalURL` was empty and it is needed to determine the OAuth callback URL.")
+			continue
+		}
+		externalURL, err := url.Parse(cfg.Critical.ExternalURL)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Could not parse `externalURL`, which is needed to determine the OAuth callback URL."))
+			continue
+		}
+		callbackURL := *externalURL
+		callbackURL.Path = "/.auth/bitbucketcloud/callback"
+
+		provider, providerProblems := parseProvider(callbackURL.String(), pr.BitbucketCloud, pr)
+		problems = append(problems, providerProblems...)
+		if provider != nil {
+			ps[*pr.BitbucketCloud] = provider
+		}
+	}
+	return ps, problems
+}
@@ -0,0 +1,119 @@
+package bitbucketcloudoauth
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/oauth"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/schema"
+	"golang.org/x/oauth2"
+)
+
+func Test_parseConfig(t *testing.T) {
+	spew.Config.DisablePointerAddresses = true
+	spew.Config.SortKeys = true
+	spew.Config.SpewKeys = true
+
+	type args struct {
+		cfg *conf.Unified
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantProviders map[schema.BitbucketCloudAuthProvider]providers.Provider
+		wantProblems  []string
+	}{
+		{
+			name:          "No configs",
+			args:          args{cfg: &conf.Unified{}},
+			wantProviders: map[schema.BitbucketCloudAuthProvider]providers.Provider{},
+		},
+		{
+			name: "1 Bitbucket Cloud config",
+			args: args{cfg: &conf.Unified{Critical: schema.CriticalConfiguration{
+				ExternalURL: "https://sourcegraph.example.com",
+				AuthProviders: []schema.AuthProviders{{
+					BitbucketCloud: &schema.BitbucketCloudAuthProvider{
+						ClientID:     "my-client-id",
+						ClientSecret: "my-client-secret",
+						DisplayName:  "Bitbucket Cloud",
+						Type:         "bitbucketCloud",
+					},
+				}},
+			}}},
+			wantProviders: map[schema.BitbucketCloudAuthProvider]providers.Provider{
+				{
+					ClientID:     "my-client-id",
+					ClientSecret: "my-client-secret",
+					DisplayName:  "Bitbucket Cloud",
+					Type:         "bitbucketCloud",
+				}: provider("https://bitbucket.org/", oauth2.Config{
+					RedirectURL:  "https://sourcegraph.example.com/.auth/bitbucketcloud/callback",
+					ClientID:     "my-client-id",
+					ClientSecret: "my-client-secret",
+					Endpoint: oauth2.Endpoint{
+						AuthURL:  "https://bitbucket.org/site/oauth2/authorize",
+						TokenURL: "https://bitbucket.org/site/oauth2/access_token",
+					},
+				}),
+			},
+		},
+		{
+			name: "No externalURL",
+			args: args{cfg: &conf.Unified{Critical: schema.CriticalConfiguration{
+				AuthProviders: []schema.AuthProviders{{
+					BitbucketCloud: &schema.BitbucketCloudAuthProvider{
+						ClientID:     "my-client-id",
+						ClientSecret: "my-client-secret",
+						Type:         "bitbucketCloud",
+					},
+				}},
+			}}},
+			wantProviders: map[schema.BitbucketCloudAuthProvider]providers.Provider{},
+			wantProblems:  []string{"`externalURL` was empty and it is needed to determine the OAuth callback URL."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotProviders, gotProblems := parseConfig(tt.args.cfg)
+			for _, p := range gotProviders {
+				if p, ok := p.(*oauth.Provider); ok {
+					p.Login, p.Callback = nil, nil
+					p.ProviderOp.Login, p.ProviderOp.Callback = nil, nil
+				}
+			}
+			for k, p := range tt.wantProviders {
+				k := k
+				if q, ok := p.(*oauth.Provider); ok {
+					q.SourceConfig = schema.AuthProviders{BitbucketCloud: &k}
+				}
+			}
+			if !reflect.DeepEqual(gotProviders, tt.wantProviders) {
+				dmp := diffmatchpatch.New()
+
+				t.Errorf("parseConfig() gotProviders != tt.wantProviders, diff:\n%s",
+					dmp.DiffPrettyText(dmp.DiffMain(spew.Sdump(tt.wantProviders), spew.Sdump(gotProviders), false)),
+				)
+			}
+			if !reflect.DeepEqual(gotProblems, tt.wantProblems) {
+				t.Errorf("parseConfig() gotProblems = %v, want %v", gotProblems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+func provider(serviceID string, oauth2Config oauth2.Config) *oauth.Provider {
+	op := oauth.ProviderOp{
+		AuthPrefix:   authPrefix,
+		OAuth2Config: oauth2Config,
+		StateConfig:  getStateConfig(),
+		ServiceID:    serviceID,
+		ServiceType:  bitbucketcloud.ServiceType,
+	}
+	return &oauth.Provider{ProviderOp: op}
+}
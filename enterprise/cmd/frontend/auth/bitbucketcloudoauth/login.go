@@ -0,0 +1,64 @@
+package bitbucketcloudoauth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dghubble/gologin"
+	oauth2Login "github.com/dghubble/gologin/oauth2"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketcloud"
+	"golang.org/x/oauth2"
+)
+
+// Bitbucket Cloud login errors
+
+var ErrUnableToGetBitbucketCloudUser = errors.New("bitbucketcloud: unable to get Bitbucket Cloud User")
+
+func LoginHandler(config *oauth2.Config, failure http.Handler) http.Handler {
+	return oauth2Login.LoginHandler(config, failure)
+}
+
+// CallbackHandler handles the OAuth callback, using the given Bitbucket Cloud API client to look
+// up the user the access token belongs to.
+func CallbackHandler(config *oauth2.Config, client *bitbucketcloud.Client, success, failure http.Handler) http.Handler {
+	success = bitbucketCloudHandler(client, success, failure)
+	return oauth2Login.CallbackHandler(config, success, failure)
+}
+
+func bitbucketCloudHandler(client *bitbucketcloud.Client, success, failure http.Handler) http.Handler {
+	if failure == nil {
+		failure = gologin.DefaultFailureHandler
+	}
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		token, err := oauth2Login.TokenFromContext(ctx)
+		if err != nil {
+			ctx = gologin.WithError(ctx, err)
+			failure.ServeHTTP(w, req.WithContext(ctx))
+			return
+		}
+
+		user, err := client.WithToken(token.AccessToken).CurrentUser(ctx)
+		err = validateResponse(user, err)
+		if err != nil {
+			ctx = gologin.WithError(ctx, err)
+			failure.ServeHTTP(w, req.WithContext(ctx))
+			return
+		}
+		ctx = WithUser(ctx, user)
+		success.ServeHTTP(w, req.WithContext(ctx))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// validateResponse returns an error if the given Bitbucket Cloud user or error are unexpected.
+// Returns nil if they are valid.
+func validateResponse(user *bitbucketcloud.User, err error) error {
+	if err != nil {
+		return ErrUnableToGetBitbucketCloudUser
+	}
+	if user == nil || user.UUID == "" {
+		return ErrUnableToGetBitbucketCloudUser
+	}
+	return nil
+}
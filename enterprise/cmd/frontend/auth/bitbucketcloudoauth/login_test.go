@@ -0,0 +1,75 @@
+package bitbucketcloudoauth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/dghubble/gologin"
+	oauth2Login "github.com/dghubble/gologin/oauth2"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketcloud"
+	"golang.org/x/oauth2"
+)
+
+func TestBitbucketCloudHandler(t *testing.T) {
+	alice := &bitbucketcloud.User{Username: "alice", UUID: "{alice}", DisplayName: "Alice"}
+
+	for _, tc := range []struct {
+		name     string
+		user     *bitbucketcloud.User
+		status   int
+		wantUser *bitbucketcloud.User
+		wantErr  error
+	}{
+		{name: "user", user: alice, status: http.StatusOK, wantUser: alice},
+		{name: "no UUID", user: &bitbucketcloud.User{Username: "alice"}, status: http.StatusOK, wantErr: ErrUnableToGetBitbucketCloudUser},
+		{name: "API error", status: http.StatusUnauthorized, wantErr: ErrUnableToGetBitbucketCloudUser},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/2.0/user" {
+					t.Errorf("unexpected request: %s", r.URL)
+				}
+				if have, want := r.Header.Get("Authorization"), "Bearer access-token"; have != want {
+					t.Errorf("have Authorization header %q, want %q", have, want)
+				}
+				w.WriteHeader(tc.status)
+				if err := json.NewEncoder(w).Encode(tc.user); err != nil {
+					t.Error(err)
+				}
+			}))
+			defer srv.Close()
+
+			client := bitbucketcloud.NewClient(nil)
+			client.URL, _ = url.Parse(srv.URL)
+
+			var (
+				haveUser *bitbucketcloud.User
+				haveErr  error
+			)
+			success := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var err error
+				if haveUser, err = UserFromContext(r.Context()); err != nil {
+					t.Fatal(err)
+				}
+			})
+			failure := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				haveErr = gologin.ErrorFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest("GET", "/callback", nil)
+			req = req.WithContext(oauth2Login.WithToken(req.Context(), &oauth2.Token{AccessToken: "access-token"}))
+			bitbucketCloudHandler(client, success, failure).ServeHTTP(httptest.NewRecorder(), req)
+
+			if !reflect.DeepEqual(haveUser, tc.wantUser) {
+				t.Errorf("have user %+v, want %+v", haveUser, tc.wantUser)
+			}
+			if haveErr != tc.wantErr {
+				t.Errorf("have error %v, want %v", haveErr, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package bitbucketcloudoauth
+
+import (
+	"net/http"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/oauth"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const authPrefix = auth.AuthURLPrefix + "/bitbucketcloud"
+
+func init() {
+	oauth.AddIsOAuth(func(p schema.AuthProviders) bool {
+		return p.BitbucketCloud != nil
+	})
+}
+
+var Middleware = &auth.Middleware{
+	API: func(next http.Handler) http.Handler {
+		return oauth.NewHandler(bitbucketcloud.ServiceType, authPrefix, true, next)
+	},
+	App: func(next http.Handler) http.Handler {
+		return oauth.NewHandler(bitbucketcloud.ServiceType, authPrefix, false, next)
+	},
+}
@@ -0,0 +1,69 @@
+package bitbucketcloudoauth
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/dghubble/gologin"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/oauth"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/schema"
+	"golang.org/x/oauth2"
+)
+
+const sessionKey = "bitbucketcloudoauth@0"
+
+func parseProvider(callbackURL string, p *schema.BitbucketCloudAuthProvider, sourceCfg schema.AuthProviders) (provider *oauth.Provider, problems []string) {
+	rawURL := p.Url
+	if rawURL == "" {
+		rawURL = "https://bitbucket.org/"
+	}
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("Could not parse Bitbucket Cloud URL %q. You will not be able to login via Bitbucket Cloud.", rawURL))
+		return nil, problems
+	}
+	codeHost := extsvc.NewCodeHost(parsedURL, bitbucketcloud.ServiceType)
+	// Bitbucket Cloud ignores requested scopes: the permissions of the access tokens are the
+	// ones configured on the OAuth consumer.
+	oauth2Cfg := oauth2.Config{
+		RedirectURL:  callbackURL,
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		Endpoint:     bitbucketcloud.OAuth2Endpoint(codeHost.BaseURL),
+	}
+	client := bitbucketcloud.NewClient(nil)
+	return oauth.NewProvider(oauth.ProviderOp{
+		AuthPrefix:   authPrefix,
+		OAuth2Config: oauth2Cfg,
+		SourceConfig: sourceCfg,
+		StateConfig:  getStateConfig(),
+		ServiceID:    codeHost.ServiceID,
+		ServiceType:  codeHost.ServiceType,
+		Login:        LoginHandler(&oauth2Cfg, nil),
+		Callback: CallbackHandler(
+			&oauth2Cfg,
+			client,
+			oauth.SessionIssuer(&sessionIssuerHelper{
+				CodeHost:    codeHost,
+				client:      client,
+				clientID:    p.ClientID,
+				allowSignup: p.AllowSignup,
+			}, sessionKey),
+			nil,
+		),
+	}), nil
+}
+
+func getStateConfig() gologin.CookieConfig {
+	cfg := gologin.CookieConfig{
+		Name:     "bitbucketcloud-state-cookie",
+		Path:     "/",
+		MaxAge:   120, // 120 seconds
+		HTTPOnly: true,
+		Secure:   conf.IsExternalURLSecure(),
+	}
+	return cfg
+}
@@ -0,0 +1,140 @@
+package bitbucketcloudoauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/oauth"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketcloud"
+	"golang.org/x/oauth2"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+type sessionIssuerHelper struct {
+	*extsvc.CodeHost
+	client      *bitbucketcloud.Client
+	clientID    string
+	allowSignup bool
+}
+
+func (s *sessionIssuerHelper) GetOrCreateUser(ctx context.Context, token *oauth2.Token) (actr *actor.Actor, safeErrMsg string, err error) {
+	bUser, err := UserFromContext(ctx)
+	if err != nil {
+		return nil, "Could not read Bitbucket Cloud user from callback request.", errors.Wrap(err, "could not read user from context")
+	}
+
+	login, err := auth.NormalizeUsername(bUser.Username)
+	if err != nil {
+		return nil, fmt.Sprintf("Error normalizing the username %q. See https://docs.sourcegraph.com/admin/auth/#username-normalization.", login), err
+	}
+
+	// 🚨 SECURITY: Ensure that the user email is verified
+	verifiedEmails := s.getVerifiedEmails(ctx, token)
+	if len(verifiedEmails) == 0 {
+		return nil, "Could not get verified email for Bitbucket Cloud user. Check that your Bitbucket Cloud account has a confirmed email that matches one of your Sourcegraph verified emails.", errors.New("no verified email")
+	}
+
+	// Try every verified email in succession until the first that succeeds
+	var data extsvc.ExternalAccountData
+	bitbucketcloud.SetExternalAccountData(&data, bUser, token)
+	var (
+		firstSafeErrMsg string
+		firstErr        error
+	)
+	for i, verifiedEmail := range verifiedEmails {
+		userID, safeErrMsg, err := auth.GetAndSaveUser(ctx, auth.GetAndSaveUserOp{
+			UserProps: db.NewUser{
+				Username:        login,
+				Email:           verifiedEmail,
+				EmailIsVerified: true,
+				DisplayName:     bUser.DisplayName,
+				AvatarURL:       bUser.Links.Avatar.Href,
+			},
+			ExternalAccount: extsvc.ExternalAccountSpec{
+				ServiceType: s.ServiceType,
+				ServiceID:   s.ServiceID,
+				ClientID:    s.clientID,
+				AccountID:   bUser.UUID,
+			},
+			ExternalAccountData: data,
+			CreateIfNotExist:    s.allowSignup,
+		})
+		if err == nil {
+			return actor.FromUser(userID), "", nil // success
+		}
+		if i == 0 {
+			firstSafeErrMsg, firstErr = safeErrMsg, err
+		}
+	}
+	// On failure, return the first error
+	return nil, fmt.Sprintf("No user exists matching any of the verified emails: %s.\n\nFirst error was: %s", strings.Join(verifiedEmails, ", "), firstSafeErrMsg), firstErr
+}
+
+func (s *sessionIssuerHelper) DeleteStateCookie(w http.ResponseWriter) {
+	stateConfig := getStateConfig()
+	stateConfig.MaxAge = -1
+	http.SetCookie(w, oauth.NewCookie(stateConfig, ""))
+}
+
+func (s *sessionIssuerHelper) SessionData(token *oauth2.Token) oauth.SessionData {
+	return oauth.SessionData{
+		ID: providers.ConfigID{
+			ID:   s.ServiceID,
+			Type: s.ServiceType,
+		},
+		AccessToken: token.AccessToken,
+		TokenType:   token.Type(),
+	}
+}
+
+// getVerifiedEmails returns the list of user emails that are confirmed. If the primary email is
+// confirmed, it will be the first email in the returned list.
+func (s *sessionIssuerHelper) getVerifiedEmails(ctx context.Context, token *oauth2.Token) (verifiedEmails []string) {
+	cli := s.client.WithToken(token.AccessToken)
+	next := &bitbucketcloud.PageToken{Pagelen: 100}
+	for {
+		emails, page, err := cli.CurrentUserEmails(ctx, next)
+		if err != nil {
+			log15.Warn("Could not get Bitbucket Cloud authenticated user emails", "error", err)
+			return nil
+		}
+
+		for _, email := range emails {
+			if !email.IsConfirmed {
+				continue
+			}
+			if email.IsPrimary {
+				verifiedEmails = append([]string{email.Email}, verifiedEmails...)
+				continue
+			}
+			verifiedEmails = append(verifiedEmails, email.Email)
+		}
+
+		if !page.HasMore() {
+			return verifiedEmails
+		}
+		next = page
+	}
+}
+
+func SignOutURL(bitbucketCloudURL string) (string, error) {
+	if bitbucketCloudURL == "" {
+		bitbucketCloudURL = "https://bitbucket.org"
+	}
+	bbURL, err := url.Parse(bitbucketCloudURL)
+	if err != nil {
+		return "", err
+	}
+	bbURL.Path = path.Join(bbURL.Path, "account/signout/")
+	return bbURL.String(), nil
+}
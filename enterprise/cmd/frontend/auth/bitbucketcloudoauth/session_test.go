@@ -0,0 +1,196 @@
+package bitbucketcloudoauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/pkg/errors"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketcloud"
+	"golang.org/x/oauth2"
+)
+
+func TestGetOrCreateUser(t *testing.T) {
+	bbURL, _ := url.Parse("https://bitbucket.org")
+	codeHost := extsvc.NewCodeHost(bbURL, bitbucketcloud.ServiceType)
+	clientID := "client-id"
+
+	// authSaveableUsers that will be accepted by auth.GetAndSaveUser
+	authSaveableUsers := map[string]int32{
+		"alice": 1,
+	}
+
+	alice := &bitbucketcloud.User{Username: "alice", UUID: "{alice}", DisplayName: "Alice"}
+
+	type input struct {
+		description string
+		bUser       *bitbucketcloud.User
+		bUserEmails []*bitbucketcloud.UserEmail
+		allowSignup bool
+	}
+	cases := []struct {
+		inputs        []input
+		expActor      *actor.Actor
+		expErr        bool
+		expAuthUserOp *auth.GetAndSaveUserOp
+	}{
+		{
+			inputs: []input{{
+				description: "bUser, confirmed email -> session created",
+				bUser:       alice,
+				bUserEmails: []*bitbucketcloud.UserEmail{{
+					Email:       "alice@example.com",
+					IsPrimary:   true,
+					IsConfirmed: true,
+				}},
+			}},
+			expActor: &actor.Actor{UID: 1},
+			expAuthUserOp: &auth.GetAndSaveUserOp{
+				UserProps:       u("alice", "alice@example.com", "Alice"),
+				ExternalAccount: acct(clientID, "{alice}"),
+			},
+		},
+		{
+			inputs: []input{{
+				description: "bUser, primary email not confirmed but another is -> session created",
+				bUser:       alice,
+				bUserEmails: []*bitbucketcloud.UserEmail{{
+					Email:     "alice@example1.com",
+					IsPrimary: true,
+				}, {
+					Email:       "alice@example2.com",
+					IsConfirmed: true,
+				}},
+			}},
+			expActor: &actor.Actor{UID: 1},
+			expAuthUserOp: &auth.GetAndSaveUserOp{
+				UserProps:       u("alice", "alice@example2.com", "Alice"),
+				ExternalAccount: acct(clientID, "{alice}"),
+			},
+		},
+		{
+			inputs: []input{{
+				description: "bUser, no emails -> no session created",
+				bUser:       alice,
+			}, {
+				description: "bUser, no confirmed emails -> no session created",
+				bUser:       alice,
+				bUserEmails: []*bitbucketcloud.UserEmail{{
+					Email:     "alice@example.com",
+					IsPrimary: true,
+				}},
+			}, {
+				description: "no bUser -> no session created",
+			}},
+			expErr: true,
+		},
+		{
+			inputs: []input{{
+				description: "bUser, confirmed email, unsaveable -> no session created",
+				bUser:       &bitbucketcloud.User{Username: "bob", UUID: "{bob}"},
+				bUserEmails: []*bitbucketcloud.UserEmail{{
+					Email:       "bob@example.com",
+					IsPrimary:   true,
+					IsConfirmed: true,
+				}},
+			}},
+			expErr: true,
+			expAuthUserOp: &auth.GetAndSaveUserOp{
+				UserProps:       u("bob", "bob@example.com", ""),
+				ExternalAccount: acct(clientID, "{bob}"),
+			},
+		},
+	}
+	for _, c := range cases {
+		for _, ci := range c.inputs {
+			c, ci := c, ci
+			t.Run(ci.description, func(t *testing.T) {
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != "/2.0/user/emails" {
+						t.Errorf("unexpected request: %s", r.URL)
+						http.NotFound(w, r)
+						return
+					}
+					if have, want := r.Header.Get("Authorization"), "Bearer access-token"; have != want {
+						t.Errorf("have Authorization header %q, want %q", have, want)
+					}
+					if err := json.NewEncoder(w).Encode(map[string]interface{}{"values": ci.bUserEmails}); err != nil {
+						t.Error(err)
+					}
+				}))
+				defer srv.Close()
+
+				var gotAuthUserOp *auth.GetAndSaveUserOp
+				auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (userID int32, safeErrMsg string, err error) {
+					if gotAuthUserOp != nil {
+						t.Fatal("GetAndSaveUser called more than once")
+					}
+					op.ExternalAccountData = extsvc.ExternalAccountData{} // ignore ExternalAccountData value
+					gotAuthUserOp = &op
+
+					if uid, ok := authSaveableUsers[op.UserProps.Username]; ok {
+						return uid, "", nil
+					}
+					return 0, "safeErr", errors.New("auth.GetAndSaveUser error")
+				}
+				defer func() { auth.MockGetAndSaveUser = nil }()
+
+				client := bitbucketcloud.NewClient(nil)
+				client.URL, _ = url.Parse(srv.URL)
+
+				ctx := context.Background()
+				if ci.bUser != nil {
+					ctx = WithUser(ctx, ci.bUser)
+				}
+				s := &sessionIssuerHelper{
+					CodeHost:    codeHost,
+					client:      client,
+					clientID:    clientID,
+					allowSignup: ci.allowSignup,
+				}
+				actr, _, err := s.GetOrCreateUser(ctx, &oauth2.Token{AccessToken: "access-token"})
+				if got, exp := actr, c.expActor; !reflect.DeepEqual(got, exp) {
+					t.Errorf("expected actor %v, got %v", exp, got)
+				}
+				if c.expErr && err == nil {
+					t.Errorf("expected err %v, but was nil", c.expErr)
+				} else if !c.expErr && err != nil {
+					t.Errorf("expected no error, but was %v", err)
+				}
+				if got, exp := gotAuthUserOp, c.expAuthUserOp; !reflect.DeepEqual(got, exp) {
+					dmp := diffmatchpatch.New()
+					t.Errorf("auth.GetOrCreateUser(op) got != exp, diff(got, exp):\n%s",
+						dmp.DiffPrettyText(dmp.DiffMain(spew.Sdump(exp), spew.Sdump(got), false)))
+				}
+			})
+		}
+	}
+}
+
+func u(username, email, displayName string) db.NewUser {
+	return db.NewUser{
+		Username:        username,
+		Email:           email,
+		EmailIsVerified: true,
+		DisplayName:     displayName,
+	}
+}
+
+func acct(clientID, accountID string) extsvc.ExternalAccountSpec {
+	return extsvc.ExternalAccountSpec{
+		ServiceType: bitbucketcloud.ServiceType,
+		ServiceID:   "https://bitbucket.org/",
+		ClientID:    clientID,
+		AccountID:   accountID,
+	}
+}
@@ -0,0 +1,27 @@
+package bitbucketcloudoauth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketcloud"
+)
+
+// unexported key type prevents collisions
+type key int
+
+const userKey key = iota
+
+// WithUser returns a copy of ctx that stores the Bitbucket Cloud User.
+func WithUser(ctx context.Context, user *bitbucketcloud.User) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// UserFromContext returns the Bitbucket Cloud User from the ctx.
+func UserFromContext(ctx context.Context) (*bitbucketcloud.User, error) {
+	user, ok := ctx.Value(userKey).(*bitbucketcloud.User)
+	if !ok {
+		return nil, fmt.Errorf("bitbucketcloud: Context missing Bitbucket Cloud User")
+	}
+	return user, nil
+}
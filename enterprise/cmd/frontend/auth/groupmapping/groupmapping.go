@@ -0,0 +1,117 @@
+// Package groupmapping syncs the organization memberships and site admin status of users from the
+// groups that an authentication provider reports them as belonging to.
+//
+// Groups don't grant access to repositories directly. Instead, repositories are explicitly granted
+// to the organizations that groups are mapped to (see db.Permissions.GrantExplicit).
+package groupmapping
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// Sync updates the user's organization memberships and site admin status to match the groups the
+// user belongs to, according to the mappings. It is called each time the user signs in. Group
+// names are compared case-insensitively.
+//
+// A nil groups slice means that the authentication provider didn't report the user's groups at all
+// (e.g., because the claim or attribute is missing or misconfigured), as opposed to reporting that
+// the user belongs to no groups. Nothing is changed then, so that users don't lose their
+// memberships and site admin status.
+//
+// Only the organizations named in the mappings are changed, and the site admin status is only
+// changed if a mapping sets siteAdmin. Organizations that the user should belong to but don't exist
+// are created.
+//
+// 🚨 SECURITY: The groups must come from the authentication provider that authenticated the user,
+// because they may make the user a site admin.
+func Sync(ctx context.Context, userID int32, groups []string, mappings []*schema.AuthGroupMapping) error {
+	if len(mappings) == 0 || groups == nil {
+		return nil
+	}
+
+	member := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		member[strings.ToLower(g)] = true
+	}
+
+	var (
+		wantOrgs    = map[string]bool{} // org name -> whether the user should be a member
+		manageAdmin bool
+		wantAdmin   bool
+	)
+	for _, m := range mappings {
+		isMember := member[strings.ToLower(m.Group)]
+		for _, org := range m.Orgs {
+			wantOrgs[org] = wantOrgs[org] || isMember
+		}
+		if m.SiteAdmin {
+			manageAdmin = true
+			wantAdmin = wantAdmin || isMember
+		}
+	}
+
+	orgNames := make([]string, 0, len(wantOrgs))
+	for name := range wantOrgs {
+		orgNames = append(orgNames, name)
+	}
+	sort.Strings(orgNames)
+	for _, name := range orgNames {
+		if err := syncOrgMembership(ctx, userID, name, wantOrgs[name]); err != nil {
+			return errors.Wrapf(err, "syncing membership of organization %q", name)
+		}
+	}
+
+	if manageAdmin {
+		user, err := db.Users.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if user.SiteAdmin != wantAdmin {
+			if err := db.Users.SetIsSiteAdmin(ctx, userID, wantAdmin); err != nil {
+				return errors.Wrap(err, "syncing site admin status")
+			}
+			action := backend.AuditUserSiteAdminRevoke
+			if wantAdmin {
+				action = backend.AuditUserSiteAdminGrant
+			}
+			backend.LogAuditEvent(ctx, action, fmt.Sprintf("user:%d", userID), map[string]string{"source": "groupMapping"})
+		}
+	}
+	return nil
+}
+
+func syncOrgMembership(ctx context.Context, userID int32, orgName string, want bool) error {
+	org, err := db.Orgs.GetByName(ctx, orgName)
+	if _, ok := err.(*db.OrgNotFoundError); ok {
+		if !want {
+			return nil
+		}
+		org, err = db.Orgs.Create(ctx, orgName, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = db.OrgMembers.GetByOrgIDAndUserID(ctx, org.ID, userID)
+	if err != nil && !errcode.IsNotFound(err) {
+		return err
+	}
+	isMember := err == nil
+
+	switch {
+	case want && !isMember:
+		_, err = db.OrgMembers.Create(ctx, org.ID, userID)
+	case !want && isMember:
+		err = db.OrgMembers.Remove(ctx, org.ID, userID)
+	}
+	return err
+}
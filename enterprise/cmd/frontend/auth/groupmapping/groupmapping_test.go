@@ -0,0 +1,137 @@
+package groupmapping
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestSync(t *testing.T) {
+	mappings := []*schema.AuthGroupMapping{
+		{Group: "cn=eng,dc=example,dc=com", Orgs: []string{"eng", "all"}},
+		{Group: "cn=sales,dc=example,dc=com", Orgs: []string{"sales", "all"}},
+		{Group: "cn=admins,dc=example,dc=com", SiteAdmin: true},
+	}
+
+	tests := []struct {
+		name          string
+		groups        []string
+		orgs          map[string]int32 // existing orgs
+		members       map[int32]bool   // existing memberships of the user, by org ID
+		siteAdmin     bool
+		wantCreated   []string
+		wantAdded     []int32
+		wantRemoved   []int32
+		wantSiteAdmin *bool
+	}{
+		{
+			name:        "joins the orgs of its groups, creating them",
+			groups:      []string{"CN=Eng,DC=example,DC=com"},
+			orgs:        map[string]int32{"all": 1},
+			wantCreated: []string{"eng"},
+			wantAdded:   []int32{1, 100},
+		},
+		{
+			name:        "leaves the orgs of other groups",
+			groups:      []string{"cn=sales,dc=example,dc=com"},
+			orgs:        map[string]int32{"all": 1, "eng": 2, "sales": 3},
+			members:     map[int32]bool{1: true, 2: true},
+			wantAdded:   []int32{3},
+			wantRemoved: []int32{2},
+		},
+		{
+			name:          "promoted to site admin",
+			groups:        []string{"cn=admins,dc=example,dc=com"},
+			wantSiteAdmin: boolPtr(true),
+		},
+		{
+			name:          "demoted from site admin",
+			groups:        []string{},
+			siteAdmin:     true,
+			wantSiteAdmin: boolPtr(false),
+		},
+		{
+			name:      "groups not reported",
+			orgs:      map[string]int32{"all": 1, "eng": 2},
+			members:   map[int32]bool{1: true, 2: true},
+			siteAdmin: true,
+		},
+		{
+			name:      "already site admin",
+			groups:    []string{"cn=admins,dc=example,dc=com"},
+			siteAdmin: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var created []string
+			var added, removed []int32
+			var setSiteAdmin *bool
+			db.Mocks.Orgs.GetByName = func(ctx context.Context, name string) (*types.Org, error) {
+				if id, ok := test.orgs[name]; ok {
+					return &types.Org{ID: id, Name: name}, nil
+				}
+				return nil, &db.OrgNotFoundError{Message: name}
+			}
+			db.Mocks.Orgs.Create = func(ctx context.Context, name string, displayName *string) (*types.Org, error) {
+				created = append(created, name)
+				return &types.Org{ID: 100, Name: name}, nil
+			}
+			db.Mocks.OrgMembers.GetByOrgIDAndUserID = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+				if test.members[orgID] {
+					return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+				}
+				return nil, &db.ErrOrgMemberNotFound{}
+			}
+			db.Mocks.OrgMembers.Create = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+				added = append(added, orgID)
+				return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+			}
+			db.Mocks.OrgMembers.Remove = func(ctx context.Context, orgID, userID int32) error {
+				removed = append(removed, orgID)
+				return nil
+			}
+			db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+				return &types.User{ID: id, SiteAdmin: test.siteAdmin}, nil
+			}
+			db.Mocks.Users.SetIsSiteAdmin = func(id int32, isSiteAdmin bool) error {
+				setSiteAdmin = &isSiteAdmin
+				return nil
+			}
+			db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error { return nil }
+			defer func() { db.Mocks = db.MockStores{} }()
+
+			if err := Sync(context.Background(), 1, test.groups, mappings); err != nil {
+				t.Fatal(err)
+			}
+			sort.Slice(added, func(i, j int) bool { return added[i] < added[j] })
+			if !reflect.DeepEqual(created, test.wantCreated) {
+				t.Errorf("got created orgs %q, want %q", created, test.wantCreated)
+			}
+			if !reflect.DeepEqual(added, test.wantAdded) {
+				t.Errorf("got added to orgs %v, want %v", added, test.wantAdded)
+			}
+			if !reflect.DeepEqual(removed, test.wantRemoved) {
+				t.Errorf("got removed from orgs %v, want %v", removed, test.wantRemoved)
+			}
+			if !reflect.DeepEqual(setSiteAdmin, test.wantSiteAdmin) {
+				t.Errorf("got site admin set to %v, want %v", fmtBoolPtr(setSiteAdmin), fmtBoolPtr(test.wantSiteAdmin))
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func fmtBoolPtr(b *bool) string {
+	if b == nil {
+		return "<unchanged>"
+	}
+	return fmt.Sprint(*b)
+}
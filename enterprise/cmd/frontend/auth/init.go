@@ -6,9 +6,11 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/app"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/bitbucketcloudoauth"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/githuboauth"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/gitlaboauth"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/httpheader"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/ldap"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/openidconnect"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/saml"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
@@ -23,6 +25,8 @@ func init() {
 		httpheader.Middleware,
 		githuboauth.Middleware,
 		gitlaboauth.Middleware,
+		bitbucketcloudoauth.Middleware,
+		ldap.Middleware,
 	)
 	// Register app-level sign-out handler
 	app.RegisterSSOSignOutHandler(ssoSignOutHandler)
@@ -49,6 +53,10 @@ func ssoSignOutHandler(w http.ResponseWriter, r *http.Request) (signOutURLs []ap
 			e.ProviderDisplayName = p.Gitlab.DisplayName
 			e.ProviderServiceType = p.Gitlab.Type
 			e.URL, err = gitlaboauth.SignOutURL(p.Gitlab.Url)
+		case p.BitbucketCloud != nil:
+			e.ProviderDisplayName = p.BitbucketCloud.DisplayName
+			e.ProviderServiceType = p.BitbucketCloud.Type
+			e.URL, err = bitbucketcloudoauth.SignOutURL(p.BitbucketCloud.Url)
 		}
 		if e.URL != "" {
 			signOutURLs = append(signOutURLs, e)
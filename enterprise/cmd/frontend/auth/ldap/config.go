@@ -0,0 +1,55 @@
+package ldap
+
+import (
+	"fmt"
+	"strings"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// getProviderConfig returns the LDAP auth provider config. At most 1 can be specified in site
+// config; if there is more than 1, it returns multiple == true (which the caller should handle by
+// returning an error and refusing to proceed with auth).
+func getProviderConfig() (pc *schema.LDAPAuthProvider, multiple bool) {
+	for _, p := range conf.Get().Critical.AuthProviders {
+		if p.Ldap != nil {
+			if pc != nil {
+				return pc, true // multiple LDAP auth providers
+			}
+			pc = p.Ldap
+		}
+	}
+	return pc, false
+}
+
+func init() {
+	conf.ContributeValidator(validateConfig)
+}
+
+func validateConfig(c conf.Unified) (problems []string) {
+	var ldapAuthProviders int
+	for _, p := range c.Critical.AuthProviders {
+		if p.Ldap != nil {
+			ldapAuthProviders++
+			if f := userFilter(p.Ldap); !strings.Contains(f, "{username}") {
+				problems = append(problems, `ldap auth provider userFilter must contain "{username}"`)
+			} else if _, err := goldap.CompileFilter(f); err != nil {
+				problems = append(problems, fmt.Sprintf("invalid LDAP filter %q: %s", f, err))
+			}
+			if p.Ldap.StartTLS && strings.HasPrefix(strings.ToLower(p.Ldap.Url), "ldaps:") {
+				problems = append(problems, `ldap auth provider startTLS can't be used with the ldaps scheme`)
+			}
+			if p.Ldap.Certificate != "" {
+				if _, err := newTLSConfig("", p.Ldap.Certificate); err != nil {
+					problems = append(problems, err.Error())
+				}
+			}
+		}
+	}
+	if ldapAuthProviders >= 2 {
+		problems = append(problems, `at most 1 ldap auth provider may be used`)
+	}
+	return problems
+}
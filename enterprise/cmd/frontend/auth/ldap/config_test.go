@@ -0,0 +1,70 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestValidateCustom(t *testing.T) {
+	tests := map[string]struct {
+		input        conf.Unified
+		wantProblems []string
+	}{
+		"single": {
+			input: conf.Unified{Critical: schema.CriticalConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap"}},
+				},
+			}},
+			wantProblems: nil,
+		},
+		"multiple": {
+			input: conf.Unified{Critical: schema.CriticalConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap"}},
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap"}},
+				},
+			}},
+			wantProblems: []string{"at most 1"},
+		},
+		"userFilter without username": {
+			input: conf.Unified{Critical: schema.CriticalConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", UserFilter: "(uid=alice)"}},
+				},
+			}},
+			wantProblems: []string{"must contain"},
+		},
+		"invalid userFilter": {
+			input: conf.Unified{Critical: schema.CriticalConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", UserFilter: "(&(uid={username})"}},
+				},
+			}},
+			wantProblems: []string{"invalid LDAP filter"},
+		},
+		"startTLS with ldaps": {
+			input: conf.Unified{Critical: schema.CriticalConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldaps://ldap.example.com", StartTLS: true}},
+				},
+			}},
+			wantProblems: []string{"startTLS"},
+		},
+		"invalid certificate": {
+			input: conf.Unified{Critical: schema.CriticalConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Certificate: "-----BEGIN CERTIFICATE-----\nx"}},
+				},
+			}},
+			wantProblems: []string{"invalid LDAP certificate"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf.TestValidator(t, test.input, validateConfig, test.wantProblems)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// timeout bounds the duration of dialing and of each LDAP request.
+const timeout = 30 * time.Second
+
+// dial connects to the LDAP server of the auth provider, whose URL is of the form
+// ldap://host[:port] or ldaps://host[:port] (LDAP over TLS). If startTLS is set, the connection
+// is upgraded to TLS before it is returned.
+func dial(ctx context.Context, pc *schema.LDAPAuthProvider) (*goldap.Conn, error) {
+	u, err := url.Parse(pc.Url)
+	if err != nil {
+		return nil, err
+	}
+	scheme := strings.ToLower(u.Scheme)
+	switch {
+	case scheme != "ldap" && scheme != "ldaps":
+		return nil, errors.Errorf("unsupported LDAP URL scheme %q (use ldap or ldaps)", u.Scheme)
+	case scheme == "ldaps" && pc.StartTLS:
+		return nil, errors.New("startTLS can't be used with the ldaps scheme")
+	}
+
+	tlsConfig, err := newTLSConfig(u.Hostname(), pc.Certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &net.Dialer{Timeout: timeout}
+	if deadline, ok := ctx.Deadline(); ok {
+		d.Deadline = deadline
+	}
+	u.Scheme = scheme
+	c, err := goldap.DialURL(u.String(), goldap.DialWithDialer(d), goldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	c.SetTimeout(timeout)
+
+	if pc.StartTLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			c.Close()
+			return nil, errors.Wrap(err, "StartTLS")
+		}
+	}
+	return c, nil
+}
+
+// newTLSConfig returns the TLS config for connecting to the LDAP server with the given host name.
+// If caCert is set, it is the only CA trusted to sign the server's certificate.
+func newTLSConfig(serverName, caCert string) (*tls.Config, error) {
+	config := &tls.Config{ServerName: serverName}
+	if caCert != "" {
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, errors.New("invalid LDAP certificate: no PEM-encoded certificates found")
+		}
+	}
+	return config, nil
+}
+
+// search searches the subtree rooted at baseDN for entries matching the filter (in the string
+// representation of RFC 4515), returning at most sizeLimit entries with the given attributes.
+// Search result references (referrals to other servers) are ignored.
+func search(c *goldap.Conn, baseDN, filter string, attrs []string, sizeLimit int) ([]*goldap.Entry, error) {
+	res, err := c.Search(goldap.NewSearchRequest(
+		baseDN,
+		goldap.ScopeWholeSubtree,
+		goldap.NeverDerefAliases,
+		sizeLimit,
+		int(timeout/time.Second),
+		false, // typesOnly
+		filter,
+		attrs,
+		nil,
+	))
+	if goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) && res != nil && len(res.Entries) >= sizeLimit {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res.Entries, nil
+}
@@ -0,0 +1,95 @@
+// Package ldap implements auth via an LDAP directory (such as OpenLDAP or Active Directory).
+package ldap
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+const providerType = "ldap"
+
+// signInPath is the path of the endpoint that the sign-in form posts the user's LDAP username and
+// password to.
+const signInPath = auth.AuthURLPrefix + "/ldap/sign-in"
+
+// Middleware is middleware for LDAP authentication. It adds the sign-in endpoint to the app. Users
+// who sign in via LDAP receive a session cookie, so the API requires no LDAP-specific handling.
+//
+// 🚨 SECURITY
+var Middleware = &auth.Middleware{
+	API: func(next http.Handler) http.Handler { return next },
+	App: func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == signInPath {
+				handleSignIn(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	},
+}
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// handleSignIn authenticates the username and password in the JSON request body against the LDAP
+// directory and, if they are correct, starts a session for the user.
+func handleSignIn(w http.ResponseWriter, r *http.Request) {
+	pc, multiple := getProviderConfig()
+	if pc == nil {
+		http.Error(w, "LDAP authentication is not enabled.", http.StatusNotFound)
+		return
+	}
+	if multiple {
+		log15.Error("At most 1 LDAP auth provider may be set in site config.")
+		http.Error(w, "Misconfigured LDAP auth provider.", http.StatusInternalServerError)
+		return
+	}
+	if r.Method != "POST" {
+		http.Error(w, "Unsupported method "+r.Method, http.StatusBadRequest)
+		return
+	}
+	// 🚨 SECURITY: This endpoint runs before the app's CSRF middleware, so require the header that
+	// only same-origin scripts can send (to prevent signing the victim into an attacker's account).
+	if r.Header.Get("X-Requested-With") == "" {
+		http.Error(w, "Missing X-Requested-With header.", http.StatusForbidden)
+		return
+	}
+	var creds credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, "Could not decode request body", http.StatusBadRequest)
+		return
+	}
+
+	u, err := authenticate(r.Context(), pc, creds.Username, creds.Password)
+	if err == errInvalidCredentials {
+		log15.Info("LDAP authentication failed.", "username", creds.Username)
+		http.Error(w, "Authentication failed", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log15.Error("Error authenticating with LDAP.", "username", creds.Username, "err", err)
+		http.Error(w, "Unexpected error authenticating with LDAP.", http.StatusInternalServerError)
+		return
+	}
+
+	actor, safeErrMsg, err := getOrCreateUser(r.Context(), pc, u)
+	if err != nil {
+		log15.Error("Error getting or creating user from LDAP.", "dn", u.DN, "err", err, "userErr", safeErrMsg)
+		http.Error(w, safeErrMsg, http.StatusInternalServerError)
+		return
+	}
+
+	// Write the session cookie.
+	if err := session.SetActor(w, r, actor, 0); err != nil {
+		log15.Error("Error starting LDAP user session.", "err", err)
+		http.Error(w, "Could not create new user session.", http.StatusInternalServerError)
+		return
+	}
+}
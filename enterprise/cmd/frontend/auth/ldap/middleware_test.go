@@ -0,0 +1,97 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestMiddleware(t *testing.T) {
+	cleanup := session.ResetMockSessionStore(t)
+	defer cleanup()
+
+	s := newTestDirectory(t)
+	defer s.Close()
+	conf.Mock(&conf.Unified{Critical: schema.CriticalConfiguration{AuthProviders: []schema.AuthProviders{{Ldap: &schema.LDAPAuthProvider{
+		Type:                "ldap",
+		Url:                 s.URL(),
+		UserBaseDN:          "ou=people,dc=example,dc=com",
+		TrustEmailAddresses: true,
+	}}}}})
+	defer conf.Mock(nil)
+
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (userID int32, safeErrMsg string, err error) {
+		if op.ExternalAccount.ServiceType == "ldap" && op.ExternalAccount.ServiceID == s.URL() && op.ExternalAccount.AccountID == "alice" &&
+			op.UserProps.Username == "alice" && op.UserProps.Email == "alice@example.com" && op.UserProps.EmailIsVerified {
+			return 1, "", nil
+		}
+		return 0, "safeErr", fmt.Errorf("account %v not found in mock", op.ExternalAccount)
+	}
+	defer func() { auth.MockGetAndSaveUser = nil }()
+
+	handler := Middleware.App(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "next")
+	}))
+	signIn := func(body string, xhr bool) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", signInPath, strings.NewReader(body))
+		if xhr {
+			req.Header.Set("X-Requested-With", "Sourcegraph")
+		}
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("other path", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+		if got, want := rr.Body.String(), "next"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		rr := signIn(`{"username":"alice","password":"s3cret"}`, true)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body %q)", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if rr.Header().Get("Set-Cookie") == "" {
+			t.Error("got no session cookie")
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		rr := signIn(`{"username":"alice","password":"wrong"}`, true)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusUnauthorized)
+		}
+		if rr.Header().Get("Set-Cookie") != "" {
+			t.Error("got session cookie")
+		}
+	})
+
+	t.Run("no X-Requested-With header", func(t *testing.T) {
+		rr := signIn(`{"username":"alice","password":"s3cret"}`, false)
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusForbidden)
+		}
+	})
+
+	t.Run("user not saved", func(t *testing.T) {
+		rr := signIn(`{"username":"bob","password":"hunter2"}`, true)
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusInternalServerError)
+		}
+		if got, want := strings.TrimSpace(rr.Body.String()), "safeErr"; got != want {
+			t.Errorf("got body %q, want %q", got, want)
+		}
+	})
+}
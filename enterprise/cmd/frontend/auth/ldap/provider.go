@@ -0,0 +1,36 @@
+package ldap
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+type provider struct {
+	c *schema.LDAPAuthProvider
+}
+
+// ConfigID implements providers.Provider.
+func (provider) ConfigID() providers.ConfigID {
+	return providers.ConfigID{Type: providerType}
+}
+
+// Config implements providers.Provider.
+func (p provider) Config() schema.AuthProviders { return schema.AuthProviders{Ldap: p.c} }
+
+// Refresh implements providers.Provider.
+func (p provider) Refresh(context.Context) error { return nil }
+
+// CachedInfo implements providers.Provider.
+func (p provider) CachedInfo() *providers.Info {
+	displayName := p.c.DisplayName
+	if displayName == "" {
+		displayName = "LDAP"
+	}
+	return &providers.Info{
+		ServiceID:         p.c.Url,
+		DisplayName:       displayName,
+		AuthenticationURL: signInPath,
+	}
+}
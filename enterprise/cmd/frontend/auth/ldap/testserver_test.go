@@ -0,0 +1,212 @@
+package ldap
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// startTLSOID is the OID of the StartTLS extended operation (RFC 4511 section 4.14).
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// testServer is an in-process LDAP server for tests. It supports simple binds, StartTLS (if
+// tlsConfig is set) and searches (with and, or, not, equality and presence filters) of a fixed set
+// of entries.
+type testServer struct {
+	l       net.Listener
+	entries []*testEntry
+
+	bindDN, bindPassword string      // the service account
+	tlsConfig            *tls.Config // if set, StartTLS is supported
+
+	mu      sync.Mutex
+	filters []*ber.Packet // the filters of the searches received
+}
+
+type testEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+func newTestServer(t *testing.T, entries ...*testEntry) *testServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{l: l, entries: entries}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) URL() string { return "ldap://" + s.l.Addr().String() }
+
+func (s *testServer) Close() { s.l.Close() }
+
+func (s *testServer) serve(c net.Conn) {
+	defer func() { c.Close() }()
+	for {
+		msg, err := ber.ReadPacket(c)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		id, op := msg.Children[0].Value, msg.Children[1]
+		reply := func(op *ber.Packet) {
+			p := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "MessageID"))
+			p.AppendChild(op)
+			_, _ = c.Write(p.Bytes())
+		}
+		switch op.Tag {
+		case goldap.ApplicationBindRequest:
+			dn, password := op.Children[1].Value.(string), op.Children[2].Data.String()
+			reply(testResult(goldap.ApplicationBindResponse, s.bindResultCode(dn, password)))
+		case goldap.ApplicationExtendedRequest:
+			if s.tlsConfig == nil || op.Children[0].Data.String() != startTLSOID {
+				reply(testResult(goldap.ApplicationExtendedResponse, goldap.LDAPResultProtocolError))
+				continue
+			}
+			reply(testResult(goldap.ApplicationExtendedResponse, goldap.LDAPResultSuccess))
+			c = tls.Server(c, s.tlsConfig)
+		case goldap.ApplicationSearchRequest:
+			baseDN, filter := op.Children[0].Value.(string), op.Children[6]
+			s.mu.Lock()
+			s.filters = append(s.filters, filter)
+			s.mu.Unlock()
+			for _, e := range s.entries {
+				if strings.HasSuffix(strings.ToLower(e.dn), strings.ToLower(baseDN)) && testMatch(filter, e) {
+					reply(testSearchResultEntry(e))
+				}
+			}
+			reply(testResult(goldap.ApplicationSearchResultDone, goldap.LDAPResultSuccess))
+		case goldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (s *testServer) bindResultCode(dn, password string) uint16 {
+	if password == "" {
+		return goldap.LDAPResultSuccess // unauthenticated bind
+	}
+	if s.bindDN != "" && dn == s.bindDN && password == s.bindPassword {
+		return goldap.LDAPResultSuccess
+	}
+	for _, e := range s.entries {
+		if e.dn == dn && e.password == password {
+			return goldap.LDAPResultSuccess
+		}
+	}
+	return goldap.LDAPResultInvalidCredentials
+}
+
+func testResult(tag ber.Tag, code uint16) *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return p
+}
+
+func testSearchResultEntry(e *testEntry) *ber.Packet {
+	attrs := ber.NewSequence("attributes")
+	for name, values := range e.attrs {
+		attr := ber.NewSequence("attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+		for _, v := range values {
+			vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "value"))
+		}
+		attr.AppendChild(vals)
+		attrs.AppendChild(attr)
+	}
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "objectName"))
+	p.AppendChild(attrs)
+	return p
+}
+
+func testMatch(f *ber.Packet, e *testEntry) bool {
+	values := func(attr string) []string {
+		for name, vs := range e.attrs {
+			if strings.EqualFold(name, attr) {
+				return vs
+			}
+		}
+		return nil
+	}
+	switch f.Tag {
+	case goldap.FilterAnd:
+		for _, c := range f.Children {
+			if !testMatch(c, e) {
+				return false
+			}
+		}
+		return true
+	case goldap.FilterOr:
+		for _, c := range f.Children {
+			if testMatch(c, e) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterNot:
+		return !testMatch(f.Children[0], e)
+	case goldap.FilterEqualityMatch:
+		for _, v := range values(f.Children[0].Value.(string)) {
+			if strings.EqualFold(v, f.Children[1].Value.(string)) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterPresent:
+		return len(values(f.Data.String())) > 0
+	}
+	return false
+}
+
+// newTestCertificate returns a self-signed certificate for 127.0.0.1 and its PEM encoding.
+func newTestCertificate(t *testing.T) (tls.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ldap-test"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	return cert, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
@@ -0,0 +1,154 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/groupmapping"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// errInvalidCredentials is returned by authenticate when the username or password is incorrect.
+var errInvalidCredentials = errors.New("invalid LDAP username or password")
+
+// ldapUser is a user entry in the LDAP directory.
+type ldapUser struct {
+	DN          string   `json:"dn"`
+	Username    string   `json:"username"`
+	Email       string   `json:"email,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Groups      []string `json:"groups,omitempty"`
+}
+
+func userFilter(pc *schema.LDAPAuthProvider) string {
+	return valueOrDefault(pc.UserFilter, "(uid={username})")
+}
+
+func valueOrDefault(v, defaultValue string) string {
+	if v == "" {
+		return defaultValue
+	}
+	return v
+}
+
+// authenticate looks up the user in the LDAP directory and verifies the password by binding as the
+// user. If the username or password is incorrect, it returns errInvalidCredentials.
+func authenticate(ctx context.Context, pc *schema.LDAPAuthProvider, username, password string) (*ldapUser, error) {
+	// 🚨 SECURITY: A bind with an empty password is an unauthenticated bind, which most LDAP servers
+	// report as successful.
+	if username == "" || password == "" {
+		return nil, errInvalidCredentials
+	}
+
+	var (
+		usernameAttr    = valueOrDefault(pc.UsernameAttribute, "uid")
+		emailAttr       = valueOrDefault(pc.EmailAttribute, "mail")
+		displayNameAttr = valueOrDefault(pc.DisplayNameAttribute, "cn")
+		groupsAttr      = valueOrDefault(pc.GroupsAttribute, "memberOf")
+	)
+
+	c, err := dial(ctx, pc)
+	if err != nil {
+		return nil, errors.Wrap(err, "connecting to LDAP server")
+	}
+	defer c.Close()
+
+	if pc.BindDN != "" {
+		if err := c.Bind(pc.BindDN, pc.BindPassword); err != nil {
+			return nil, errors.Wrap(err, "binding as bindDN")
+		}
+	}
+
+	// 🚨 SECURITY: The username must be escaped so that it can't change the meaning of the filter.
+	filter := strings.Replace(userFilter(pc), "{username}", goldap.EscapeFilter(username), -1)
+	entries, err := search(c, pc.UserBaseDN, filter, []string{usernameAttr, emailAttr, displayNameAttr, groupsAttr}, 2)
+	if err != nil {
+		return nil, errors.Wrap(err, "searching for LDAP user")
+	}
+	switch len(entries) {
+	case 0:
+		return nil, errInvalidCredentials
+	case 1:
+	default:
+		return nil, fmt.Errorf("multiple LDAP entries match the userFilter for username %q", username)
+	}
+	e := entries[0]
+
+	// 🚨 SECURITY: Verify the password by binding as the user.
+	if err := c.Bind(e.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, errInvalidCredentials
+		}
+		return nil, errors.Wrap(err, "binding as LDAP user")
+	}
+
+	u := &ldapUser{
+		DN:          e.DN,
+		Username:    e.GetEqualFoldAttributeValue(usernameAttr),
+		Email:       e.GetEqualFoldAttributeValue(emailAttr),
+		DisplayName: e.GetEqualFoldAttributeValue(displayNameAttr),
+		Groups:      attributeValues(e, groupsAttr),
+	}
+	if u.Username == "" {
+		return nil, fmt.Errorf("LDAP entry %q has no %q attribute (set the ldap auth provider's usernameAttribute property)", e.DN, usernameAttr)
+	}
+	return u, nil
+}
+
+// attributeValues returns the values of the entry's attribute, or nil if the entry doesn't have
+// the attribute. Attribute names are case-insensitive.
+func attributeValues(e *goldap.Entry, attr string) []string {
+	if vs := e.GetEqualFoldAttributeValues(attr); len(vs) > 0 {
+		return vs
+	}
+	return nil
+}
+
+// getOrCreateUser gets or creates a user account for the LDAP user and syncs its organization
+// memberships and site admin status from its groups. It returns the authenticated actor if
+// successful; otherwise it returns an friendly error message (safeErrMsg) that is safe to display
+// to users, and a non-nil err with lower-level error details.
+func getOrCreateUser(ctx context.Context, pc *schema.LDAPAuthProvider, u *ldapUser) (_ *actor.Actor, safeErrMsg string, err error) {
+	username, err := auth.NormalizeUsername(u.Username)
+	if err != nil {
+		return nil, fmt.Sprintf("Error normalizing the username %q. See https://docs.sourcegraph.com/admin/auth/#username-normalization.", u.Username), err
+	}
+
+	var data extsvc.ExternalAccountData
+	data.SetAccountData(u)
+
+	userID, safeErrMsg, err := auth.GetAndSaveUser(ctx, auth.GetAndSaveUserOp{
+		UserProps: db.NewUser{
+			Username: username,
+			Email:    u.Email,
+			// 🚨 SECURITY: Only trust the email addresses in the directory if the site admin
+			// opted in, because users may be able to change their own email address there.
+			EmailIsVerified: pc.TrustEmailAddresses && u.Email != "",
+			DisplayName:     u.DisplayName,
+		},
+		ExternalAccount: extsvc.ExternalAccountSpec{
+			ServiceType: providerType,
+			ServiceID:   pc.Url,
+			// Store the raw username, not the normalized username, to prevent two users with
+			// distinct pre-normalization usernames from being merged into the same account.
+			AccountID: u.Username,
+		},
+		ExternalAccountData: data,
+		CreateIfNotExist:    true,
+	})
+	if err != nil {
+		return nil, safeErrMsg, err
+	}
+
+	if err := groupmapping.Sync(ctx, userID, u.Groups, pc.GroupMappings); err != nil {
+		return nil, "Unable to sync your organization memberships from your LDAP groups.", err
+	}
+	return actor.FromUser(userID), "", nil
+}
@@ -0,0 +1,218 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"reflect"
+	"strings"
+	"testing"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func newTestDirectory(t *testing.T) *testServer {
+	return newTestServer(t,
+		&testEntry{
+			dn:       "uid=alice,ou=people,dc=example,dc=com",
+			password: "s3cret",
+			attrs: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"alice"},
+				"Mail":        {"alice@example.com"},
+				"cn":          {"Alice Smith"},
+				"memberOf":    {"cn=eng,ou=groups,dc=example,dc=com", "cn=admins,ou=groups,dc=example,dc=com"},
+			},
+		},
+		&testEntry{
+			dn:       "uid=bob,ou=people,dc=example,dc=com",
+			password: "hunter2",
+			attrs: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"bob"},
+			},
+		},
+		&testEntry{
+			dn:       "uid=alice,ou=contractors,dc=example,dc=com",
+			password: "other",
+			attrs: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"alice"},
+			},
+		},
+	)
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := newTestDirectory(t)
+	defer s.Close()
+	pc := &schema.LDAPAuthProvider{
+		Type:       "ldap",
+		Url:        s.URL(),
+		UserBaseDN: "ou=people,dc=example,dc=com",
+	}
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		u, err := authenticate(ctx, pc, "alice", "s3cret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &ldapUser{
+			DN:          "uid=alice,ou=people,dc=example,dc=com",
+			Username:    "alice",
+			Email:       "alice@example.com",
+			DisplayName: "Alice Smith",
+			Groups:      []string{"cn=eng,ou=groups,dc=example,dc=com", "cn=admins,ou=groups,dc=example,dc=com"},
+		}
+		if !reflect.DeepEqual(u, want) {
+			t.Errorf("got %+v, want %+v", u, want)
+		}
+	})
+
+	t.Run("no email or groups", func(t *testing.T) {
+		u, err := authenticate(ctx, pc, "bob", "hunter2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (&ldapUser{DN: "uid=bob,ou=people,dc=example,dc=com", Username: "bob"}); !reflect.DeepEqual(u, want) {
+			t.Errorf("got %+v, want %+v", u, want)
+		}
+	})
+
+	for name, creds := range map[string][2]string{
+		"wrong password":            {"alice", "hunter2"},
+		"unknown user":              {"carol", "s3cret"},
+		"empty password":            {"alice", ""},
+		"empty username":            {"", "s3cret"},
+		"filter injection":          {"*", "s3cret"},
+		"password of other subtree": {"alice", "other"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := authenticate(ctx, pc, creds[0], creds[1]); err != errInvalidCredentials {
+				t.Errorf("got error %v, want %v", err, errInvalidCredentials)
+			}
+		})
+	}
+
+	t.Run("filter injection is escaped", func(t *testing.T) {
+		s.mu.Lock()
+		s.filters = nil
+		s.mu.Unlock()
+		_, _ = authenticate(ctx, pc, "a*)(uid=*", "x")
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if len(s.filters) != 1 {
+			t.Fatalf("got %d searches, want 1", len(s.filters))
+		}
+		got, err := goldap.DecompileFilter(s.filters[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `(uid=a\2a\29\28uid=\2a)`; got != want {
+			t.Errorf("got filter %q, want %q", got, want)
+		}
+	})
+
+	t.Run("multiple matching entries", func(t *testing.T) {
+		pc := *pc
+		pc.UserBaseDN = "dc=example,dc=com"
+		_, err := authenticate(ctx, &pc, "alice", "s3cret")
+		if err == nil || !strings.Contains(err.Error(), "multiple LDAP entries") {
+			t.Errorf("got error %v, want multiple entries error", err)
+		}
+	})
+
+	t.Run("custom attributes and filter", func(t *testing.T) {
+		pc := *pc
+		pc.UserFilter = "(&(objectClass=person)(mail={username}))"
+		pc.UsernameAttribute = "UID"
+		pc.DisplayNameAttribute = "uid"
+		u, err := authenticate(ctx, &pc, "alice@example.com", "s3cret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Username != "alice" || u.DisplayName != "alice" {
+			t.Errorf("got %+v", u)
+		}
+	})
+
+	t.Run("bindDN", func(t *testing.T) {
+		s.bindDN, s.bindPassword = "cn=sourcegraph,dc=example,dc=com", "svc"
+		defer func() { s.bindDN, s.bindPassword = "", "" }()
+
+		pc := *pc
+		pc.BindDN = s.bindDN
+		pc.BindPassword = s.bindPassword
+		if _, err := authenticate(ctx, &pc, "alice", "s3cret"); err != nil {
+			t.Fatal(err)
+		}
+
+		pc.BindPassword = "wrong"
+		_, err := authenticate(ctx, &pc, "alice", "s3cret")
+		if err == nil || err == errInvalidCredentials || !strings.Contains(err.Error(), "binding as bindDN") {
+			t.Errorf("got error %v, want bindDN error", err)
+		}
+	})
+}
+
+func TestAuthenticate_TLS(t *testing.T) {
+	s := newTestDirectory(t)
+	defer s.Close()
+	cert, certPEM := newTestCertificate(t)
+	s.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	pc := &schema.LDAPAuthProvider{
+		Type:        "ldap",
+		Url:         s.URL(),
+		UserBaseDN:  "ou=people,dc=example,dc=com",
+		StartTLS:    true,
+		Certificate: certPEM,
+	}
+	ctx := context.Background()
+
+	t.Run("startTLS with certificate", func(t *testing.T) {
+		if _, err := authenticate(ctx, pc, "alice", "s3cret"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("startTLS with untrusted certificate", func(t *testing.T) {
+		pc := *pc
+		pc.Certificate = ""
+		_, err := authenticate(ctx, &pc, "alice", "s3cret")
+		if err == nil || !strings.Contains(err.Error(), "StartTLS") {
+			t.Errorf("got error %v, want StartTLS error", err)
+		}
+	})
+
+	t.Run("startTLS with ldaps", func(t *testing.T) {
+		pc := *pc
+		pc.Url = "ldaps://" + strings.TrimPrefix(s.URL(), "ldap://")
+		_, err := authenticate(ctx, &pc, "alice", "s3cret")
+		if err == nil || !strings.Contains(err.Error(), "ldaps scheme") {
+			t.Errorf("got error %v, want ldaps scheme error", err)
+		}
+	})
+}
+
+func TestGetOrCreateUser_EmailIsVerified(t *testing.T) {
+	var emailIsVerified bool
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (int32, string, error) {
+		emailIsVerified = op.UserProps.EmailIsVerified
+		return 1, "", nil
+	}
+	defer func() { auth.MockGetAndSaveUser = nil }()
+
+	u := &ldapUser{DN: "uid=alice,ou=people,dc=example,dc=com", Username: "alice", Email: "alice@example.com"}
+	for _, trust := range []bool{false, true} {
+		pc := &schema.LDAPAuthProvider{Type: "ldap", TrustEmailAddresses: trust}
+		if _, _, err := getOrCreateUser(context.Background(), pc, u); err != nil {
+			t.Fatal(err)
+		}
+		if emailIsVerified != trust {
+			t.Errorf("trustEmailAddresses %v: got EmailIsVerified %v", trust, emailIsVerified)
+		}
+	}
+}
@@ -52,6 +52,8 @@ func (p *Provider) CachedInfo() *providers.Info {
 		displayName = p.SourceConfig.Github.DisplayName
 	case p.SourceConfig.Gitlab != nil && p.SourceConfig.Gitlab.DisplayName != "":
 		displayName = p.SourceConfig.Gitlab.DisplayName
+	case p.SourceConfig.BitbucketCloud != nil && p.SourceConfig.BitbucketCloud.DisplayName != "":
+		displayName = p.SourceConfig.BitbucketCloud.DisplayName
 	}
 	return &providers.Info{
 		ServiceID:   p.ServiceID,
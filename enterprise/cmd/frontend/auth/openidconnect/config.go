@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
@@ -60,15 +61,24 @@ func validateConfig(c conf.Unified) (problems []string) {
 		}
 	}
 
-	seen := map[schema.OpenIDConnectAuthProvider]int{}
+	// The configs are compared with reflect.DeepEqual because they contain slices (and so can't be
+	// map keys).
+	var seen []int // indexes of the distinct configs
 	for i, p := range c.Critical.AuthProviders {
-		if p.Openidconnect != nil {
-			if j, ok := seen[*p.Openidconnect]; ok {
+		if p.Openidconnect == nil {
+			continue
+		}
+		duplicate := false
+		for _, j := range seen {
+			if reflect.DeepEqual(p.Openidconnect, c.Critical.AuthProviders[j].Openidconnect) {
 				problems = append(problems, fmt.Sprintf("OpenID Connect auth provider at index %d is duplicate of index %d, ignoring", i, j))
-			} else {
-				seen[*p.Openidconnect] = i
+				duplicate = true
+				break
 			}
 		}
+		if !duplicate {
+			seen = append(seen, i)
+		}
 	}
 
 	return problems
@@ -8,13 +8,16 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/groupmapping"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
-// getOrCreateUser gets or creates a user account based on the OpenID Connect token. It returns the
-// authenticated actor if successful; otherwise it returns an friendly error message (safeErrMsg)
-// that is safe to display to users, and a non-nil err with lower-level error details.
+// getOrCreateUser gets or creates a user account based on the OpenID Connect token, and syncs its
+// organization memberships and site admin status from its groups. It returns the authenticated
+// actor if successful; otherwise it returns an friendly error message (safeErrMsg) that is safe to
+// display to users, and a non-nil err with lower-level error details.
 func getOrCreateUser(ctx context.Context, p *provider, idToken *oidc.IDToken, userInfo *oidc.UserInfo, claims *userClaims) (_ *actor.Actor, safeErrMsg string, err error) {
 	if userInfo.Email == "" {
 		return nil, "Only users with an email address may authenticate to Sourcegraph.", errors.New("no email address in claims")
@@ -48,12 +51,15 @@ func getOrCreateUser(ctx context.Context, p *provider, idToken *oidc.IDToken, us
 		return nil, fmt.Sprintf("Error normalizing the username %q. See https://docs.sourcegraph.com/admin/auth/#username-normalization.", login), err
 	}
 
+	groups := readGroups(groupsClaim(p.config), userInfo, idToken)
+
 	var data extsvc.ExternalAccountData
 	data.SetAccountData(struct {
 		IDToken    *oidc.IDToken  `json:"idToken"`
 		UserInfo   *oidc.UserInfo `json:"userInfo"`
 		UserClaims *userClaims    `json:"userClaims"`
-	}{IDToken: idToken, UserInfo: userInfo, UserClaims: claims})
+		Groups     []string       `json:"groups,omitempty"`
+	}{IDToken: idToken, UserInfo: userInfo, UserClaims: claims, Groups: groups})
 
 	userID, safeErrMsg, err := auth.GetAndSaveUser(ctx, auth.GetAndSaveUserOp{
 		UserProps: db.NewUser{
@@ -75,5 +81,49 @@ func getOrCreateUser(ctx context.Context, p *provider, idToken *oidc.IDToken, us
 	if err != nil {
 		return nil, safeErrMsg, err
 	}
+
+	if err := groupmapping.Sync(ctx, userID, groups, p.config.GroupMappings); err != nil {
+		return nil, "Unable to sync your organization memberships from your OpenID Connect groups.", err
+	}
 	return actor.FromUser(userID), "", nil
 }
+
+func groupsClaim(pc schema.OpenIDConnectAuthProvider) string {
+	if pc.GroupsClaim != "" {
+		return pc.GroupsClaim
+	}
+	return "groups"
+}
+
+// claimsSource is implemented by *oidc.IDToken and *oidc.UserInfo.
+type claimsSource interface {
+	Claims(v interface{}) error
+}
+
+// readGroups returns the names of the groups listed in the claim of the first source that has it.
+// The claim's value may be an array of strings or a single string. Identity providers differ in
+// whether they include groups in the UserInfo response or the ID token, so both are consulted. It
+// returns nil if no source has the claim, and an empty slice if the claim lists no groups.
+func readGroups(claim string, sources ...claimsSource) []string {
+	for _, src := range sources {
+		var claims map[string]interface{}
+		if err := src.Claims(&claims); err != nil {
+			continue
+		}
+		groups := []string{}
+		switch v := claims[claim].(type) {
+		case nil:
+			continue
+		case string:
+			groups = append(groups, v)
+		case []interface{}:
+			for _, g := range v {
+				if g, ok := g.(string); ok {
+					groups = append(groups, g)
+				}
+			}
+		}
+		return groups
+	}
+	return nil
+}
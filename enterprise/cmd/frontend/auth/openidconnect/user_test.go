@@ -0,0 +1,52 @@
+package openidconnect
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type fakeClaims string
+
+func (c fakeClaims) Claims(v interface{}) error {
+	if c == "" {
+		return errors.New("claims not set")
+	}
+	return json.Unmarshal([]byte(c), v)
+}
+
+func TestReadGroups(t *testing.T) {
+	tests := map[string]struct {
+		sources []claimsSource
+		want    []string
+	}{
+		"array": {
+			sources: []claimsSource{fakeClaims(`{"groups": ["eng", "admins", 1]}`)},
+			want:    []string{"eng", "admins"},
+		},
+		"string": {
+			sources: []claimsSource{fakeClaims(`{"groups": "eng"}`)},
+			want:    []string{"eng"},
+		},
+		"first source with the claim": {
+			sources: []claimsSource{fakeClaims(""), fakeClaims(`{"sub": "a"}`), fakeClaims(`{"groups": ["sales"]}`)},
+			want:    []string{"sales"},
+		},
+		"empty claim in first source": {
+			sources: []claimsSource{fakeClaims(`{"groups": []}`), fakeClaims(`{"groups": ["sales"]}`)},
+			want:    []string{},
+		},
+		"no claim": {
+			sources: []claimsSource{fakeClaims(`{"sub": "a"}`)},
+			want:    nil,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := readGroups("groups", test.sources...); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
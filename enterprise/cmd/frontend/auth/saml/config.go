@@ -9,6 +9,7 @@ import (
 	"log"
 	"net/http"
 	"path"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -75,15 +76,24 @@ func validateConfig(c conf.Unified) (problems []string) {
 		}
 	}
 
-	seen := map[schema.SAMLAuthProvider]int{}
+	// The configs are compared with reflect.DeepEqual because they contain slices (and so can't be
+	// map keys).
+	var seen []int // indexes of the distinct configs
 	for i, p := range c.Critical.AuthProviders {
-		if p.Saml != nil {
-			if j, ok := seen[*p.Saml]; ok {
+		if p.Saml == nil {
+			continue
+		}
+		duplicate := false
+		for _, j := range seen {
+			if reflect.DeepEqual(p.Saml, c.Critical.AuthProviders[j].Saml) {
 				problems = append(problems, fmt.Sprintf("SAML auth provider at index %d is duplicate of index %d, ignoring", i, j))
-			} else {
-				seen[*p.Saml] = i
+				duplicate = true
+				break
 			}
 		}
+		if !duplicate {
+			seen = append(seen, i)
+		}
 	}
 
 	return problems
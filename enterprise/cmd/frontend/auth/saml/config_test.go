@@ -22,6 +22,16 @@ func TestValidateCustom(t *testing.T) {
 			}},
 			wantProblems: []string{"SAML auth provider at index 1 is duplicate of index 0"},
 		},
+		"different group mappings": {
+			input: conf.Unified{Critical: schema.CriticalConfiguration{
+				ExternalURL: "x",
+				AuthProviders: []schema.AuthProviders{
+					{Saml: &schema.SAMLAuthProvider{Type: "saml", IdentityProviderMetadataURL: "x", GroupMappings: []*schema.AuthGroupMapping{{Group: "a", SiteAdmin: true}}}},
+					{Saml: &schema.SAMLAuthProvider{Type: "saml", IdentityProviderMetadataURL: "x", GroupMappings: []*schema.AuthGroupMapping{{Group: "b", SiteAdmin: true}}}},
+				},
+			}},
+			wantProblems: nil,
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -10,14 +10,17 @@ import (
 	saml2 "github.com/russellhaering/gosaml2"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/groupmapping"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 type authnResponseInfo struct {
 	spec                 extsvc.ExternalAccountSpec
 	email, displayName   string
 	unnormalizedUsername string
+	groups               []string
 	accountData          interface{}
 }
 
@@ -67,6 +70,7 @@ func readAuthnResponse(p *provider, encodedResp string) (*authnResponseInfo, err
 		email:                email,
 		unnormalizedUsername: firstNonempty(attr.Get("login"), attr.Get("uid"), email),
 		displayName:          firstNonempty(attr.Get("displayName"), attr.Get("givenName")+" "+attr.Get("surname")),
+		groups:               attr.GetAll(groupsAttribute(p.config)),
 		accountData:          assertions,
 	}
 	if assertions.NameID == "" {
@@ -81,10 +85,11 @@ func readAuthnResponse(p *provider, encodedResp string) (*authnResponseInfo, err
 	return &info, nil
 }
 
-// getOrCreateUser gets or creates a user account based on the SAML claims. It returns the
-// authenticated actor if successful; otherwise it returns an friendly error message (safeErrMsg)
-// that is safe to display to users, and a non-nil err with lower-level error details.
-func getOrCreateUser(ctx context.Context, info *authnResponseInfo) (_ *actor.Actor, safeErrMsg string, err error) {
+// getOrCreateUser gets or creates a user account based on the SAML claims, and syncs its
+// organization memberships and site admin status from its groups. It returns the authenticated
+// actor if successful; otherwise it returns an friendly error message (safeErrMsg) that is safe to
+// display to users, and a non-nil err with lower-level error details.
+func getOrCreateUser(ctx context.Context, p *provider, info *authnResponseInfo) (_ *actor.Actor, safeErrMsg string, err error) {
 	var data extsvc.ExternalAccountData
 	data.SetAccountData(info.accountData)
 
@@ -108,9 +113,20 @@ func getOrCreateUser(ctx context.Context, info *authnResponseInfo) (_ *actor.Act
 	if err != nil {
 		return nil, safeErrMsg, err
 	}
+
+	if err := groupmapping.Sync(ctx, userID, info.groups, p.config.GroupMappings); err != nil {
+		return nil, "Unable to sync your organization memberships from your SAML groups.", err
+	}
 	return actor.FromUser(userID), "", nil
 }
 
+func groupsAttribute(pc schema.SAMLAuthProvider) string {
+	if pc.GroupsAttribute != "" {
+		return pc.GroupsAttribute
+	}
+	return "groups"
+}
+
 func mightBeEmail(s string) bool {
 	return strings.Count(s, "@") == 1
 }
@@ -125,3 +141,23 @@ func (v samlAssertionValues) Get(key string) string {
 	}
 	return ""
 }
+
+// GetAll returns all values of the attributes with the name (or friendly name). Empty values are
+// omitted. It returns nil if there is no such attribute, and an empty slice if the attributes have
+// no values.
+func (v samlAssertionValues) GetAll(key string) []string {
+	var values []string
+	for _, a := range v {
+		if a.Name == key || a.FriendlyName == key {
+			if values == nil {
+				values = []string{}
+			}
+			for _, av := range a.Values {
+				if s := strings.TrimSpace(av.Value); s != "" {
+					values = append(values, s)
+				}
+			}
+		}
+	}
+	return values
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	saml2 "github.com/russellhaering/gosaml2"
+	"github.com/russellhaering/gosaml2/types"
 	dsig "github.com/russellhaering/goxmldsig"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
 )
@@ -46,6 +47,35 @@ func TestReadAuthnResponse(t *testing.T) {
 	}
 }
 
+func TestSAMLAssertionValuesGetAll(t *testing.T) {
+	attr := samlAssertionValues{
+		"http://schemas.microsoft.com/ws/2008/06/identity/claims/groups": types.Attribute{
+			Name:   "http://schemas.microsoft.com/ws/2008/06/identity/claims/groups",
+			Values: []types.AttributeValue{{Value: "eng"}, {Value: " "}, {Value: "admins"}},
+		},
+		"urn:oid:1.3.6.1.4.1.5923.1.5.1.1": types.Attribute{
+			Name:         "urn:oid:1.3.6.1.4.1.5923.1.5.1.1",
+			FriendlyName: "isMemberOf",
+			Values:       []types.AttributeValue{{Value: "sales"}},
+		},
+		"empty": types.Attribute{
+			Name:   "empty",
+			Values: []types.AttributeValue{{Value: " "}},
+		},
+	}
+	tests := map[string][]string{
+		"http://schemas.microsoft.com/ws/2008/06/identity/claims/groups": {"eng", "admins"},
+		"isMemberOf": {"sales"},
+		"empty":      {},
+		"groups":     nil,
+	}
+	for key, want := range tests {
+		if got := attr.GetAll(key); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %q, want %q", key, got, want)
+		}
+	}
+}
+
 var idpCert2 = func() *x509.Certificate {
 	b, _ := pem.Decode([]byte(`-----BEGIN CERTIFICATE-----
 MIICmzCCAYMCBgFjcZU/LjANBgkqhkiG9w0BAQsFADARMQ8wDQYDVQQDDAZtYXN0ZXIwHhcNMTgwNTE4MDQ0ODE2WhcNMjgwNTE4MDQ0OTU2WjARMQ8wDQYDVQQDDAZtYXN0ZXIwggEiMA0GCSqGSIb3DQEBAQUAA4IBDwAwggEKAoIBAQDXZpJeHraEt9FPk478+RoMtP9RV83Ew/XRZhNKI4BPoY5MjRVuvaabvMOE5X1AK9Z0cEU++m/Y0LuHg3A4kQdPw3BGPBfGm0WSD6DEN42TcF3dc8XBA/osDNW5i6rZM071che8XtKNHcW9ZAv9ETfJeUb4NHFRkRg3K1lZ5kCwt0JNo+0akQ2EdQXXu/uEeQV49rOADr+Lp6GLhmGeCckC8xzBiNxZwR4pJsz9XWgB6fSdpIGvWhAnBfFZyyZIHnVuRnm2wJ53Exg6h2RB3SFYu3PXXuIHeuH71pel5WwnecTVTwV/RMwkAGLdCNC9jp9tdDtThhWLn4E9D0wZkpU9AgMBAAEwDQYJKoZIhvcNAQELBQADggEBAKT/zyjvSM09Fk2ON4rMSExnyrw6LXuJJOZlB0eD22KruQ53AikfKz5nJLCFLc0PT4PmK06s9OF0HG95k4jiiuvAdNMXZSLUGNcbaODeJ/ZzCJJp0cB2rWEmAqbKruXzBpTFttlgsW4mgpkvGxORztfhksiyAX0bLcNWtsQecl3fpvoVrJiIHXStD3c/v4exE2QPkuvhLCzwI2oXrrhrovyTKjCbyn2//lqOfFziA8X/ini3R/L4UzTVB5SWAz/LtkpgipPOwNpVqwErnZamexm6S38QX+OZ+uhZY/1JfTugs9vpXwRvj/xamGr8r+MqornuQiEBBNiCbCJ6B4iUWh4=
@@ -19,5 +19,11 @@ func NewExternalServicesStore() *db.ExternalServicesStore {
 		BitbucketServerValidators: []func(*schema.BitbucketServerConnection, []schema.AuthProviders) error{
 			authz.ValidateBitbucketServerAuthz,
 		},
+		BitbucketCloudValidators: []func(*schema.BitbucketCloudConnection, []schema.AuthProviders) error{
+			authz.ValidateBitbucketCloudAuthz,
+		},
+		GitoliteValidators: []func(*schema.GitoliteConnection) error{
+			authz.ValidateGitoliteAuthz,
+		},
 	}
 }
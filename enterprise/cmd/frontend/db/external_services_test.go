@@ -271,6 +271,31 @@ func TestExternalServices_ValidateConfig(t *testing.T) {
 			}`,
 			assert: equals(`<nil>`),
 		},
+		{
+			kind: "GITOLITE",
+			desc: "valid with authorization",
+			config: `
+			{
+				"prefix": "/",
+				"host": "gitolite.mycorp.com",
+				"authorization": {
+					"identityProvider": {"type": "email"},
+					"adminRepository": "admin"
+				}
+			}`,
+			assert: equals(`<nil>`),
+		},
+		{
+			kind: "GITOLITE",
+			desc: "authorization without identityProvider",
+			config: `
+			{
+				"prefix": "/",
+				"host": "gitolite.mycorp.com",
+				"authorization": {}
+			}`,
+			assert: includes("authorization: identityProvider is required"),
+		},
 		{
 			kind: "BITBUCKETCLOUD",
 			desc: "valid with url, username, appPassword",
@@ -325,6 +350,45 @@ func TestExternalServices_ValidateConfig(t *testing.T) {
 				`teams.1: Does not match pattern '^\w+$'`,
 			),
 		},
+		{
+			kind: "BITBUCKETCLOUD",
+			desc: "valid with authorization ttl",
+			config: `
+			{
+				"url": "https://bitbucket.org/",
+				"username": "admin",
+				"appPassword": "app-password",
+				"authorization": {"ttl": "15m"}
+			}`,
+			ps: []schema.AuthProviders{
+				{BitbucketCloud: &schema.BitbucketCloudAuthProvider{Url: "https://bitbucket.org/"}},
+			},
+			assert: equals("<nil>"),
+		},
+		{
+			kind: "BITBUCKETCLOUD",
+			desc: "authorization without matching auth provider",
+			config: `
+			{
+				"url": "https://bitbucket.org/",
+				"username": "admin",
+				"appPassword": "app-password",
+				"authorization": {"ttl": "15m"}
+			}`,
+			assert: includes(`Did not find authentication provider matching "https://bitbucket.org/"`),
+		},
+		{
+			kind: "BITBUCKETCLOUD",
+			desc: "authorization with unknown field",
+			config: `
+			{
+				"url": "https://bitbucket.org/",
+				"username": "admin",
+				"appPassword": "app-password",
+				"authorization": {"identityProvider": {}}
+			}`,
+			assert: includes("authorization: Additional property identityProvider is not allowed"),
+		},
 		{
 			kind: "BITBUCKETSERVER",
 			desc: "valid with url, username, token, repositoryQuery",
@@ -0,0 +1,101 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	bbcauthz "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/schema"
+	"golang.org/x/oauth2"
+)
+
+func bitbucketCloudProviders(ctx context.Context, cfg *conf.Unified, conns []*schema.BitbucketCloudConnection) (
+	authzProviders []authz.Provider,
+	seriousProblems []string,
+	warnings []string,
+) {
+	for _, c := range conns {
+		p, err := bitbucketCloudProvider(c, cfg.Critical.AuthProviders)
+		if err != nil {
+			seriousProblems = append(seriousProblems, err.Error())
+			continue
+		}
+		if p != nil {
+			authzProviders = append(authzProviders, p)
+		}
+	}
+	for _, p := range authzProviders {
+		for _, problem := range p.Validate() {
+			warnings = append(warnings, fmt.Sprintf("Bitbucket Cloud config for %s: %s", p.ServiceID(), problem))
+		}
+	}
+	return authzProviders, seriousProblems, warnings
+}
+
+func bitbucketCloudProvider(c *schema.BitbucketCloudConnection, ps []schema.AuthProviders) (authz.Provider, error) {
+	if c.Authorization == nil {
+		return nil, nil
+	}
+
+	baseURL, err := url.Parse(c.Url)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse URL for Bitbucket Cloud instance %q: %s", c.Url, err)
+	}
+
+	ttl, err := parseTTL(c.Authorization.Ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	// Users' permissions are computed with the OAuth tokens of the external accounts created by
+	// the Bitbucket Cloud authn provider, so there must be one for this Bitbucket Cloud.
+	var oauth2Config *oauth2.Config
+	for _, authnProvider := range ps {
+		if authnProvider.BitbucketCloud == nil {
+			continue
+		}
+		authnURL := authnProvider.BitbucketCloud.Url
+		if authnURL == "" {
+			authnURL = "https://bitbucket.org"
+		}
+		authProviderURL, err := url.Parse(authnURL)
+		if err != nil {
+			// Ignore the error here, because the authn provider is responsible for its own validation
+			continue
+		}
+		if authProviderURL.Hostname() == baseURL.Hostname() {
+			oauth2Config = &oauth2.Config{
+				ClientID:     authnProvider.BitbucketCloud.ClientID,
+				ClientSecret: authnProvider.BitbucketCloud.ClientSecret,
+				Endpoint:     bitbucketcloud.OAuth2Endpoint(authProviderURL),
+			}
+			break
+		}
+	}
+	if oauth2Config == nil {
+		return nil, fmt.Errorf("Did not find authentication provider matching %q", c.Url)
+	}
+
+	cli := bitbucketcloud.NewClient(nil)
+	cli.Username = c.Username
+	cli.AppPassword = c.AppPassword
+
+	return bbcauthz.NewProvider(bbcauthz.ProviderOp{
+		BaseURL:      baseURL,
+		Client:       cli,
+		Teams:        c.Teams,
+		OAuth2Config: oauth2Config,
+		CacheTTL:     ttl,
+	}), nil
+}
+
+// ValidateBitbucketCloudAuthz validates the authorization fields of the given Bitbucket Cloud
+// external service config.
+func ValidateBitbucketCloudAuthz(c *schema.BitbucketCloudConnection, ps []schema.AuthProviders) error {
+	_, err := bitbucketCloudProvider(c, ps)
+	return err
+}
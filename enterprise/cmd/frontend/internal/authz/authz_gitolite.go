@@ -0,0 +1,77 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	gitoliteauthz "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/gitolite"
+	"github.com/sourcegraph/sourcegraph/pkg/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitolite"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func gitoliteProviders(ctx context.Context, conns []*schema.GitoliteConnection) (
+	authzProviders []authz.Provider,
+	seriousProblems []string,
+	warnings []string,
+) {
+	for _, c := range conns {
+		p, err := gitoliteProvider(c.Authorization, c.Host, c.Prefix)
+		if err != nil {
+			seriousProblems = append(seriousProblems, err.Error())
+			continue
+		}
+		if p != nil {
+			authzProviders = append(authzProviders, p)
+		}
+	}
+
+	for _, p := range authzProviders {
+		for _, problem := range p.Validate() {
+			warnings = append(warnings, fmt.Sprintf("Gitolite config for %s was invalid: %s", p.ServiceID(), problem))
+		}
+	}
+
+	return authzProviders, seriousProblems, warnings
+}
+
+func gitoliteProvider(a *schema.GitoliteAuthorization, host, prefix string) (authz.Provider, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	ttl, err := parseTTL(a.Ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	adminRepo := a.AdminRepository
+	if adminRepo == "" {
+		adminRepo = "gitolite-admin"
+	}
+
+	var identity string
+	switch idp := a.IdentityProvider; {
+	case idp.Username != nil:
+		identity = gitoliteauthz.IdentityUsername
+	case idp.Email != nil:
+		identity = gitoliteauthz.IdentityEmail
+	default:
+		return nil, fmt.Errorf("No identityProvider was specified")
+	}
+
+	return gitoliteauthz.NewProvider(
+		gitolite.ServiceID(host),
+		reposource.GitoliteRepoName(prefix, adminRepo),
+		identity,
+		ttl,
+	), nil
+}
+
+// ValidateGitoliteAuthz validates the authorization fields of the given Gitolite external
+// service config.
+func ValidateGitoliteAuthz(c *schema.GitoliteConnection) error {
+	_, err := gitoliteProvider(c.Authorization, c.Host, c.Prefix)
+	return err
+}
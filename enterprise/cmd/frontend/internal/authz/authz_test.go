@@ -3,6 +3,7 @@ package authz
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/url"
 	"reflect"
 	"testing"
@@ -11,10 +12,14 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	bbcauthz "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketcloud"
 	bbsauthz "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/gitlab"
+	gitoliteauthz "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/gitolite"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
@@ -69,6 +74,8 @@ func Test_providersFromConfig(t *testing.T) {
 		cfg                          conf.Unified
 		gitlabConnections            []*schema.GitLabConnection
 		bitbucketServerConnections   []*schema.BitbucketServerConnection
+		bitbucketCloudConnections    []*schema.BitbucketCloudConnection
+		gitoliteConnections          []*schema.GitoliteConnection
 		expAuthzAllowAccessByDefault bool
 		expAuthzProviders            func(*testing.T, []authz.Provider)
 		expSeriousProblems           []string
@@ -435,7 +442,113 @@ func Test_providersFromConfig(t *testing.T) {
 				}
 			},
 		},
+		{
+			description: "1 Bitbucket Cloud connection with authz disabled",
+			bitbucketCloudConnections: []*schema.BitbucketCloudConnection{
+				{
+					Authorization: nil,
+					Url:           "https://bitbucket.org",
+					Username:      "admin",
+					AppPassword:   "app-password",
+				},
+			},
+			expAuthzAllowAccessByDefault: true,
+			expAuthzProviders:            providersEqual(),
+		},
+		{
+			description: "1 Bitbucket Cloud connection with authz enabled, 1 Bitbucket Cloud matching auth provider",
+			cfg: conf.Unified{
+				Critical: schema.CriticalConfiguration{
+					AuthProviders: []schema.AuthProviders{{
+						BitbucketCloud: &schema.BitbucketCloudAuthProvider{
+							ClientID:     "clientID",
+							ClientSecret: "clientSecret",
+							Type:         "bitbucketCloud",
+							Url:          "https://bitbucket.org/",
+						},
+					}},
+				},
+			},
+			bitbucketCloudConnections: []*schema.BitbucketCloudConnection{
+				{
+					Authorization: &schema.BitbucketCloudAuthorization{Ttl: "15m"},
+					Url:           "https://bitbucket.org",
+					Username:      "admin",
+					AppPassword:   "app-password",
+				},
+			},
+			expAuthzAllowAccessByDefault: true,
+			expAuthzProviders: func(t *testing.T, have []authz.Provider) {
+				if len(have) != 1 {
+					t.Fatalf("expected 1 provider, got %d", len(have))
+				}
+
+				p, ok := have[0].(*bbcauthz.Provider)
+				if !ok {
+					t.Fatalf("no Bitbucket Cloud authz provider returned")
+				}
+
+				if have, want := p.ServiceID(), "https://bitbucket.org/"; have != want {
+					t.Errorf("ServiceID: have %q, want %q", have, want)
+				}
+			},
+		},
+		{
+			description: "1 Bitbucket Cloud connection with authz enabled, missing auth provider",
+			bitbucketCloudConnections: []*schema.BitbucketCloudConnection{
+				{
+					Authorization: &schema.BitbucketCloudAuthorization{Ttl: "15m"},
+					Url:           "https://bitbucket.org",
+					Username:      "admin",
+					AppPassword:   "app-password",
+				},
+			},
+			expAuthzAllowAccessByDefault: false,
+			expSeriousProblems:           []string{"Did not find authentication provider matching \"https://bitbucket.org\""},
+		},
+		{
+			description: "1 Gitolite connection with authz enabled",
+			gitoliteConnections: []*schema.GitoliteConnection{
+				{
+					Authorization: &schema.GitoliteAuthorization{
+						IdentityProvider: schema.GitoliteIdentityProvider{
+							Email: &schema.GitoliteEmailIdentity{Type: "email"},
+						},
+					},
+					Host:   "git@gitolite.mycorp.org",
+					Prefix: "gitolite.mycorp.org/",
+				},
+			},
+			expAuthzAllowAccessByDefault: true,
+			expAuthzProviders: func(t *testing.T, have []authz.Provider) {
+				if len(have) != 1 {
+					t.Fatalf("expected 1 provider, got %d", len(have))
+				}
+
+				if _, ok := have[0].(*gitoliteauthz.Provider); !ok {
+					t.Fatalf("no Gitolite authz provider returned")
+				}
+			},
+		},
+		{
+			description: "Gitolite connection without identity provider",
+			gitoliteConnections: []*schema.GitoliteConnection{
+				{
+					Authorization: &schema.GitoliteAuthorization{},
+					Host:          "git@gitolite.mycorp.org",
+					Prefix:        "gitolite.mycorp.org/",
+				},
+			},
+			expAuthzAllowAccessByDefault: false,
+			expSeriousProblems:           []string{"No identityProvider was specified"},
+		},
+	}
+
+	// The Gitolite authz provider reads its access rules from gitserver when validated.
+	git.Mocks.ResolveRevision = func(spec string, opt *git.ResolveRevisionOptions) (api.CommitID, error) {
+		return "", errors.New("not cloned")
 	}
+	defer git.ResetMocks()
 
 	for _, test := range tests {
 		t.Logf("Test %q", test.description)
@@ -443,6 +556,8 @@ func Test_providersFromConfig(t *testing.T) {
 		store := fakeStore{
 			gitlabs:          test.gitlabConnections,
 			bitbucketServers: test.bitbucketServerConnections,
+			bitbucketClouds:  test.bitbucketCloudConnections,
+			gitolites:        test.gitoliteConnections,
 		}
 
 		allowAccessByDefault, authzProviders, seriousProblems, _ := ProvidersFromConfig(context.Background(), &test.cfg, &store, nil)
@@ -478,6 +593,8 @@ type fakeStore struct {
 	gitlabs          []*schema.GitLabConnection
 	githubs          []*schema.GitHubConnection
 	bitbucketServers []*schema.BitbucketServerConnection
+	bitbucketClouds  []*schema.BitbucketCloudConnection
+	gitolites        []*schema.GitoliteConnection
 }
 
 func (s fakeStore) ListGitHubConnections(context.Context) ([]*schema.GitHubConnection, error) {
@@ -491,3 +608,11 @@ func (s fakeStore) ListGitLabConnections(context.Context) ([]*schema.GitLabConne
 func (s fakeStore) ListBitbucketServerConnections(context.Context) ([]*schema.BitbucketServerConnection, error) {
 	return s.bitbucketServers, nil
 }
+
+func (s fakeStore) ListBitbucketCloudConnections(context.Context) ([]*schema.BitbucketCloudConnection, error) {
+	return s.bitbucketClouds, nil
+}
+
+func (s fakeStore) ListGitoliteConnections(context.Context) ([]*schema.GitoliteConnection, error) {
+	return s.gitolites, nil
+}
@@ -0,0 +1,407 @@
+// Package bitbucketcloud contains an authorization provider for Bitbucket Cloud.
+package bitbucketcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/pkg/rcache"
+	"golang.org/x/oauth2"
+)
+
+// Provider implements authz.Provider for Bitbucket Cloud repository permissions.
+type Provider struct {
+	client       *bitbucketcloud.Client
+	teams        []string
+	oauth2Config *oauth2.Config
+	codeHost     *extsvc.CodeHost
+	cacheTTL     time.Duration
+	cache        cache
+}
+
+var _ authz.Provider = ((*Provider)(nil))
+
+// ProviderOp configures a new Provider.
+type ProviderOp struct {
+	// BaseURL is the URL of Bitbucket Cloud.
+	BaseURL *url.URL
+
+	// Client must be authenticated with the credentials of the external service connection,
+	// which are used to determine whether a repository is public.
+	Client *bitbucketcloud.Client
+
+	// Teams are the teams of the external service connection, whose repositories are synced in
+	// addition to the ones of the connection's account.
+	Teams []string
+
+	// OAuth2Config is the config of the Bitbucket Cloud authentication provider that creates
+	// the users' external accounts. It's used to refresh their expired OAuth tokens.
+	OAuth2Config *oauth2.Config
+
+	// CacheTTL is the TTL of cached permissions lists from the Bitbucket Cloud API.
+	CacheTTL time.Duration
+
+	// MockCache, if non-nil, replaces the default Redis-based cache with the supplied cache mock.
+	// Should only be used in tests.
+	MockCache cache
+}
+
+// NewProvider returns a new Bitbucket Cloud authorization provider. The permissions of a user
+// are computed with the OAuth token stored in their Bitbucket Cloud external account, which is
+// created when they sign in with Bitbucket Cloud.
+func NewProvider(op ProviderOp) *Provider {
+	p := &Provider{
+		client:       op.Client,
+		teams:        op.Teams,
+		oauth2Config: op.OAuth2Config,
+		codeHost:     extsvc.NewCodeHost(op.BaseURL, bitbucketcloud.ServiceType),
+		cache:        op.MockCache,
+		cacheTTL:     op.CacheTTL,
+	}
+	// Note: this will use the same underlying Redis instance and key namespace for every instance
+	// of Provider.  This is by design, so that different instances, even in different processes,
+	// will share cache entries.
+	if p.cache == nil {
+		p.cache = rcache.NewWithTTL(fmt.Sprintf("bitbucketCloudAuthz:%s", op.BaseURL.String()), int(math.Ceil(op.CacheTTL.Seconds())))
+	}
+	return p
+}
+
+// RepoPerms implements the authz.Provider interface.
+//
+// A user can read a repository if they are a member of it or if the repository is public.
+// Membership is computed by listing all the repositories the user is a member of with their
+// OAuth token, and publicness by listing all the repositories of the external service connection
+// with its credentials. Both are cached in Redis for the configured TTL.
+func (p *Provider) RepoPerms(ctx context.Context, userAccount *extsvc.ExternalAccount, repos []*types.Repo) ([]authz.RepoPerms, error) {
+	if len(repos) == 0 {
+		return nil, nil
+	}
+
+	perms := make([]authz.RepoPerms, 0, len(repos))
+	remaining := repos
+
+	fetched := map[string]bool{} // the external IDs of the repos not found in the cache
+	defer func() { authz.RecordCacheStats(ctx, len(repos)-len(fetched), len(fetched)) }()
+
+	populate := func(isReadable map[string]bool) {
+		nextRemaining := []*types.Repo{}
+		for _, repo := range remaining {
+			if isReadable[repo.ExternalRepo.ID] {
+				perms = append(perms, authz.RepoPerms{Repo: repo, Perms: authz.Read})
+				continue
+			}
+			nextRemaining = append(nextRemaining, repo)
+		}
+		remaining = nextRemaining
+	}
+
+	if userAccount != nil {
+		isMember, err := p.getCachedUserRepos(ctx, userAccount, remaining)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only fetch the user's repositories if any of the remaining ones isn't cached.
+		for _, repo := range remaining {
+			if _, ok := isMember[repo.ExternalRepo.ID]; !ok {
+				if isMember, err = p.fetchAndSetUserRepos(ctx, userAccount, remaining); err != nil {
+					return nil, err
+				}
+				for _, r := range remaining {
+					fetched[r.ExternalRepo.ID] = true
+				}
+				break
+			}
+		}
+
+		populate(isMember)
+		if len(remaining) == 0 {
+			return perms, nil
+		}
+	}
+
+	isPublic, err := p.getCachedPublicRepos(ctx, remaining)
+	if err != nil {
+		return nil, err
+	}
+
+	var uncached []*types.Repo
+	for _, repo := range remaining {
+		if _, ok := isPublic[repo.ExternalRepo.ID]; !ok {
+			uncached = append(uncached, repo)
+			fetched[repo.ExternalRepo.ID] = true
+		}
+	}
+
+	if len(uncached) > 0 {
+		fetched, err := p.fetchAndSetPublicRepos(ctx, uncached)
+		if err != nil {
+			return nil, err
+		}
+		for id, public := range fetched {
+			isPublic[id] = public
+		}
+	}
+
+	populate(isPublic)
+	for _, repo := range remaining {
+		if _, ok := isPublic[repo.ExternalRepo.ID]; ok {
+			perms = append(perms, authz.RepoPerms{Repo: repo, Perms: authz.None})
+		}
+	}
+
+	return perms, nil
+}
+
+// fetchAndSetUserRepos accepts a user account and a set of repos. It returns a map from
+// repository UUID to true/false indicating whether the given user is a member of each repo. As
+// a side effect, it caches the returned map.
+func (p *Provider) fetchAndSetUserRepos(ctx context.Context, userAccount *extsvc.ExternalAccount, repos []*types.Repo) (map[string]bool, error) {
+	userRepos, err := p.fetchUserRepos(ctx, userAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember := make(map[string]bool, len(repos))
+	for _, r := range repos {
+		_, isMember[r.ExternalRepo.ID] = userRepos[r.ExternalRepo.ID]
+	}
+
+	if err := p.setCachedUserRepos(ctx, userAccount, isMember); err != nil {
+		return nil, err
+	}
+	return isMember, nil
+}
+
+// fetchUserRepos returns the set of UUIDs of all the repositories the given user is a member
+// of.
+func (p *Provider) fetchUserRepos(ctx context.Context, userAccount *extsvc.ExternalAccount) (map[string]struct{}, error) {
+	tok, err := p.userToken(ctx, userAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	cli := p.client.WithToken(tok.AccessToken)
+	userRepos := make(map[string]struct{})
+	next := &bitbucketcloud.PageToken{Pagelen: 100}
+	for {
+		repos, page, err := cli.UserRepos(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range repos {
+			userRepos[r.UUID] = struct{}{}
+		}
+		if !page.HasMore() {
+			break
+		}
+		next = page
+	}
+
+	return userRepos, nil
+}
+
+// userToken returns the OAuth token of the given user account. Bitbucket Cloud access tokens
+// expire after a couple of hours, so an expired token is refreshed and the new one is saved in the
+// user account.
+func (p *Provider) userToken(ctx context.Context, userAccount *extsvc.ExternalAccount) (*oauth2.Token, error) {
+	usr, tok, err := bitbucketcloud.GetExternalAccountData(&userAccount.ExternalAccountData)
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return nil, errors.Errorf("no OAuth token for Bitbucket Cloud external account %d", userAccount.ID)
+	}
+
+	if tok.Valid() || tok.RefreshToken == "" || p.oauth2Config == nil {
+		return tok, nil
+	}
+
+	refreshed, err := p.oauth2Config.TokenSource(ctx, tok).Token()
+	if err != nil {
+		return nil, errors.Wrapf(err, "refreshing OAuth token of Bitbucket Cloud external account %d", userAccount.ID)
+	}
+
+	var data extsvc.ExternalAccountData
+	bitbucketcloud.SetExternalAccountData(&data, usr, refreshed)
+	if err := db.ExternalAccounts.AssociateUserAndSave(ctx, userAccount.UserID, userAccount.ExternalAccountSpec, data); err != nil {
+		return nil, err
+	}
+
+	return refreshed, nil
+}
+
+// setCachedUserRepos updates the cache with a map from repo UUID to true/false indicating
+// whether the user is a member of the repo.
+//
+// Internally, it sets a separate cache key for each user and repo UUID.
+func (p *Provider) setCachedUserRepos(ctx context.Context, userAccount *extsvc.ExternalAccount, isMember map[string]bool) error {
+	setArgs := make([][2]string, 0, len(isMember))
+	for k, v := range isMember {
+		rkey, err := json.Marshal(userRepoCacheKey{User: userAccount.AccountID, Repo: k})
+		if err != nil {
+			return err
+		}
+		rval, err := json.Marshal(userRepoCacheVal{Read: v, TTL: p.cacheTTL})
+		if err != nil {
+			return err
+		}
+		setArgs = append(setArgs, [2]string{string(rkey), string(rval)})
+	}
+	p.cache.SetMulti(setArgs...)
+	return nil
+}
+
+// getCachedUserRepos accepts a user account and set of repos and returns a map from repo UUID
+// to true/false indicating whether the user is a member of the repo. The returned map may be
+// incomplete (i.e., not every input repo may be represented in the key set) due to cache
+// incompleteness.
+func (p *Provider) getCachedUserRepos(ctx context.Context, userAccount *extsvc.ExternalAccount, repos []*types.Repo) (map[string]bool, error) {
+	getArgs := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		rkey, err := json.Marshal(userRepoCacheKey{
+			User: userAccount.AccountID,
+			Repo: repo.ExternalRepo.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		getArgs = append(getArgs, string(rkey))
+	}
+
+	isMember := make(map[string]bool)
+	for i, v := range p.cache.GetMulti(getArgs...) {
+		if len(v) == 0 {
+			continue
+		}
+		var val userRepoCacheVal
+		if err := json.Unmarshal(v, &val); err != nil {
+			return nil, err
+		}
+		if p.cacheTTL < val.TTL {
+			// if the cache TTL is now less than the cache entry TTL, invalidate that entry
+			continue
+		}
+		isMember[repos[i].ExternalRepo.ID] = val.Read
+	}
+	return isMember, nil
+}
+
+// fetchAndSetPublicRepos accepts a set of repositories and returns a map from repository UUID
+// to true/false indicating whether the repository is public or private. As a side effect, it
+// caches the publicness of the repos.
+func (p *Provider) fetchAndSetPublicRepos(ctx context.Context, repos []*types.Repo) (map[string]bool, error) {
+	isPublic, err := p.fetchPublicRepos(ctx, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	setArgs := make([][2]string, 0, len(isPublic))
+	for k, v := range isPublic {
+		val, err := json.Marshal(publicRepoCacheVal{Public: v, TTL: p.cacheTTL})
+		if err != nil {
+			return nil, err
+		}
+		setArgs = append(setArgs, [2]string{publicRepoCacheKey(k), string(val)})
+	}
+	p.cache.SetMulti(setArgs...)
+
+	return isPublic, nil
+}
+
+// fetchPublicRepos returns a map from repository UUID to true/false indicating whether a
+// repository is public (true) or private (false). Rather than looking up each of the given
+// repositories, it pages through all the repositories of the external service connection's
+// account and teams, which are the ones it syncs, so that they all get cached at once. The given
+// repositories missing from these are reported as private.
+func (p *Provider) fetchPublicRepos(ctx context.Context, repos []*types.Repo) (map[string]bool, error) {
+	isPublic := make(map[string]bool, len(repos))
+	for _, account := range append([]string{p.client.Username}, p.teams...) {
+		next := &bitbucketcloud.PageToken{Pagelen: 100}
+		for {
+			rs, page, err := p.client.Repos(ctx, next, account)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range rs {
+				isPublic[r.UUID] = !r.IsPrivate
+			}
+			if !page.HasMore() {
+				break
+			}
+			next = page
+		}
+	}
+
+	for _, repo := range repos {
+		if _, ok := isPublic[repo.ExternalRepo.ID]; !ok {
+			isPublic[repo.ExternalRepo.ID] = false
+		}
+	}
+	return isPublic, nil
+}
+
+// getCachedPublicRepos accepts a set of repos and returns a map from repo UUID to true/false
+// indicating whether the repo is public or private. The returned map may be incomplete (i.e.,
+// not every input repo may be represented in the key set) due to cache incompleteness.
+func (p *Provider) getCachedPublicRepos(ctx context.Context, repos []*types.Repo) (map[string]bool, error) {
+	isPublic := make(map[string]bool)
+	if len(repos) == 0 {
+		return isPublic, nil
+	}
+
+	getArgs := make([]string, 0, len(repos))
+	for _, r := range repos {
+		getArgs = append(getArgs, publicRepoCacheKey(r.ExternalRepo.ID))
+	}
+
+	for i, v := range p.cache.GetMulti(getArgs...) {
+		if len(v) == 0 {
+			continue
+		}
+		var val publicRepoCacheVal
+		if err := json.Unmarshal(v, &val); err != nil {
+			return nil, err
+		}
+		if p.cacheTTL < val.TTL {
+			// if the cache TTL is now less than the cache entry TTL, invalidate that entry
+			continue
+		}
+		isPublic[repos[i].ExternalRepo.ID] = val.Public
+	}
+	return isPublic, nil
+}
+
+// FetchAccount implements the authz.Provider interface. It always returns nil, because the
+// Bitbucket Cloud API doesn't allow looking up a user's account and OAuth token on their behalf.
+// Bitbucket Cloud external accounts are created by the Bitbucket Cloud authentication provider
+// when users sign in.
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (mine *extsvc.ExternalAccount, err error) {
+	return nil, nil
+}
+
+// ServiceID returns the absolute URL that identifies the Bitbucket Cloud instance.
+func (p *Provider) ServiceID() string {
+	return p.codeHost.ServiceID
+}
+
+// ServiceType returns the type of this Provider, "bitbucketCloud".
+func (p *Provider) ServiceType() string {
+	return p.codeHost.ServiceType
+}
+
+// Validate implements the authz.Provider interface.
+func (p *Provider) Validate() (problems []string) {
+	return nil
+}
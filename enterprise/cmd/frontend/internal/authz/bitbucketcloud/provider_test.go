@@ -0,0 +1,360 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketcloud"
+	"golang.org/x/oauth2"
+)
+
+func TestProvider_RepoPerms(t *testing.T) {
+	bb := newMockBitbucketCloud(t, []*bitbucketcloud.Repo{
+		{UUID: "{u0-private}", IsPrivate: true},
+		{UUID: "{u0-public}"},
+		{UUID: "{u1-private}", IsPrivate: true},
+		{UUID: "{u99-private}", IsPrivate: true},
+		{UUID: "{u99-public}"},
+	}, map[string][]string{
+		"admin": {"{u0-private}", "{u0-public}", "{u1-private}"},
+		"team":  {"{u99-private}", "{u99-public}"},
+	}, map[string][]string{
+		"t0": {"{u0-private}", "{u0-public}"},
+		"t1": {"{u1-private}"},
+	})
+	defer bb.Close()
+
+	repos := map[string]*types.Repo{
+		"r0":  rp("r0", "{u0-private}"),
+		"r1":  rp("r1", "{u0-public}"),
+		"r2":  rp("r2", "{u1-private}"),
+		"r3":  rp("r3", "{u99-private}"),
+		"r4":  rp("r4", "{u99-public}"),
+		"r00": rp("r00", "{404}"),
+	}
+	all := []*types.Repo{repos["r0"], repos["r1"], repos["r2"], repos["r3"], repos["r4"], repos["r00"]}
+
+	calls := []struct {
+		description string
+		userAccount *extsvc.ExternalAccount
+		repos       []*types.Repo
+		wantPerms   []authz.RepoPerms
+	}{
+		{
+			description: "t0_repos",
+			userAccount: ua("u0", "t0"),
+			repos:       all,
+			wantPerms: []authz.RepoPerms{
+				{Repo: repos["r0"], Perms: authz.Read},
+				{Repo: repos["r1"], Perms: authz.Read},
+				{Repo: repos["r2"], Perms: authz.None},
+				{Repo: repos["r3"], Perms: authz.None},
+				{Repo: repos["r4"], Perms: authz.Read},
+				{Repo: repos["r00"], Perms: authz.None},
+			},
+		},
+		{
+			description: "t1_repos",
+			userAccount: ua("u1", "t1"),
+			repos:       all,
+			wantPerms: []authz.RepoPerms{
+				{Repo: repos["r0"], Perms: authz.None},
+				{Repo: repos["r1"], Perms: authz.Read},
+				{Repo: repos["r2"], Perms: authz.Read},
+				{Repo: repos["r3"], Perms: authz.None},
+				{Repo: repos["r4"], Perms: authz.Read},
+				{Repo: repos["r00"], Perms: authz.None},
+			},
+		},
+		{
+			description: "no_account",
+			repos:       all,
+			wantPerms: []authz.RepoPerms{
+				{Repo: repos["r0"], Perms: authz.None},
+				{Repo: repos["r1"], Perms: authz.Read},
+				{Repo: repos["r2"], Perms: authz.None},
+				{Repo: repos["r3"], Perms: authz.None},
+				{Repo: repos["r4"], Perms: authz.Read},
+				{Repo: repos["r00"], Perms: authz.None},
+			},
+		},
+		{
+			description: "no_repos",
+			userAccount: ua("u0", "t0"),
+		},
+	}
+
+	provider := NewProvider(ProviderOp{
+		BaseURL:   mustURL(t, "https://bitbucket.org"),
+		Client:    bb.client(),
+		Teams:     []string{"team"},
+		CacheTTL:  3 * time.Hour,
+		MockCache: make(authz.MockCache),
+	})
+	for j := 0; j < 2; j++ { // run twice for cache coherency
+		for _, c := range calls {
+			t.Run(fmt.Sprintf("%s: run %d", c.description, j), func(t *testing.T) {
+				bb.requests = 0
+
+				gotPerms, err := provider.RepoPerms(context.Background(), c.userAccount, c.repos)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for _, perms := range [][]authz.RepoPerms{gotPerms, c.wantPerms} {
+					sort.Slice(perms, func(i, j int) bool {
+						return perms[i].Repo.Name <= perms[j].Repo.Name
+					})
+				}
+
+				if !reflect.DeepEqual(gotPerms, c.wantPerms) {
+					dmp := diffmatchpatch.New()
+					t.Errorf("expected perms did not equal actual, diff:\n%s",
+						dmp.DiffPrettyText(dmp.DiffMain(spew.Sdump(c.wantPerms), spew.Sdump(gotPerms), false)))
+				}
+
+				if j == 1 && bb.requests > 0 {
+					t.Errorf("expected entries to be fully cached, but made %d requests", bb.requests)
+				}
+			})
+		}
+	}
+}
+
+func TestProvider_RepoPerms_cacheTTL(t *testing.T) {
+	bb := newMockBitbucketCloud(t, []*bitbucketcloud.Repo{
+		{UUID: "{u0-private}", IsPrivate: true},
+	}, map[string][]string{
+		"admin": {"{u0-private}"},
+	}, map[string][]string{
+		"t0": {"{u0-private}"},
+	})
+	defer bb.Close()
+
+	cache := make(authz.MockCache)
+	repos := []*types.Repo{rp("r0", "{u0-private}")}
+	want := []authz.RepoPerms{{Repo: repos[0], Perms: authz.Read}}
+
+	for _, tc := range []struct {
+		ttl          time.Duration
+		wantRequests int
+	}{
+		{ttl: 3 * time.Hour, wantRequests: 1},
+		{ttl: 3 * time.Hour, wantRequests: 0},
+		{ttl: 2 * time.Hour, wantRequests: 1},
+		{ttl: 3 * time.Hour, wantRequests: 0},
+	} {
+		bb.requests = 0
+		provider := NewProvider(ProviderOp{
+			BaseURL:   mustURL(t, "https://bitbucket.org"),
+			Client:    bb.client(),
+			CacheTTL:  tc.ttl,
+			MockCache: cache,
+		})
+		have, err := provider.RepoPerms(context.Background(), ua("u0", "t0"), repos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("ttl %s: have perms %s, want %s", tc.ttl, spew.Sdump(have), spew.Sdump(want))
+		}
+		if bb.requests != tc.wantRequests {
+			t.Errorf("ttl %s: have %d requests, want %d", tc.ttl, bb.requests, tc.wantRequests)
+		}
+	}
+}
+
+func TestProvider_RepoPerms_refreshToken(t *testing.T) {
+	bb := newMockBitbucketCloud(t, []*bitbucketcloud.Repo{
+		{UUID: "{u0-private}", IsPrivate: true},
+	}, map[string][]string{
+		"admin": {"{u0-private}"},
+	}, map[string][]string{
+		"t0": {"{u0-private}"},
+	})
+	defer bb.Close()
+
+	var saved *oauth2.Token
+	db.Mocks.ExternalAccounts.AssociateUserAndSave = func(userID int32, spec extsvc.ExternalAccountSpec, data extsvc.ExternalAccountData) error {
+		if have, want := spec.AccountID, "u0"; have != want {
+			t.Errorf("have account ID %q, want %q", have, want)
+		}
+		_, tok, err := bitbucketcloud.GetExternalAccountData(&data)
+		saved = tok
+		return err
+	}
+	defer func() { db.Mocks.ExternalAccounts.AssociateUserAndSave = nil }()
+
+	account := ua("u0", "expired")
+	bitbucketcloud.SetExternalAccountData(&account.ExternalAccountData, nil, &oauth2.Token{
+		AccessToken:  "expired",
+		RefreshToken: "refresh-t0",
+		Expiry:       time.Now().Add(-time.Minute),
+	})
+
+	provider := NewProvider(ProviderOp{
+		BaseURL: mustURL(t, "https://bitbucket.org"),
+		Client:  bb.client(),
+		OAuth2Config: &oauth2.Config{
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+			Endpoint:     bitbucketcloud.OAuth2Endpoint(mustURL(t, bb.URL)),
+		},
+		CacheTTL:  3 * time.Hour,
+		MockCache: make(authz.MockCache),
+	})
+
+	repos := []*types.Repo{rp("r0", "{u0-private}")}
+	have, err := provider.RepoPerms(context.Background(), account, repos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []authz.RepoPerms{{Repo: repos[0], Perms: authz.Read}}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have perms %s, want %s", spew.Sdump(have), spew.Sdump(want))
+	}
+
+	if saved == nil || saved.AccessToken != "t0" {
+		t.Errorf("have saved token %+v, want the refreshed token", saved)
+	}
+}
+
+// mockBitbucketCloud is a fake Bitbucket Cloud API that serves the endpoints used by Provider.
+// It lists repositories two per page.
+type mockBitbucketCloud struct {
+	*httptest.Server
+	repos        map[string]*bitbucketcloud.Repo
+	accountRepos map[string][]string
+	tokenRepos   map[string][]string
+	requests     int
+}
+
+func newMockBitbucketCloud(t *testing.T, repos []*bitbucketcloud.Repo, accountRepos, tokenRepos map[string][]string) *mockBitbucketCloud {
+	m := &mockBitbucketCloud{
+		repos:        make(map[string]*bitbucketcloud.Repo, len(repos)),
+		accountRepos: accountRepos,
+		tokenRepos:   tokenRepos,
+	}
+	for _, r := range repos {
+		m.repos[r.UUID] = r
+	}
+
+	page := func(r *http.Request, ids []string) interface{} {
+		n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if n == 0 {
+			n = 1
+		}
+
+		values := []*bitbucketcloud.Repo{}
+		for i := 2 * (n - 1); i < len(ids) && i < 2*n; i++ {
+			values = append(values, m.repos[ids[i]])
+		}
+
+		result := map[string]interface{}{"values": values}
+		if len(ids) > 2*n {
+			next := *r.URL
+			next.Scheme, next.Host = "http", r.Host
+			q := next.Query()
+			q.Set("page", strconv.Itoa(n+1))
+			next.RawQuery = q.Encode()
+			result["next"] = next.String()
+		}
+		return result
+	}
+
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.requests++
+
+		var result interface{}
+		switch {
+		case r.URL.Path == "/site/oauth2/access_token":
+			if have, want := r.FormValue("refresh_token"), "refresh-t0"; have != want {
+				t.Errorf("have refresh token %q, want %q", have, want)
+			}
+			result = map[string]interface{}{
+				"access_token":  "t0",
+				"refresh_token": "refresh-t0",
+				"token_type":    "bearer",
+				"expires_in":    7200,
+			}
+		case r.URL.Path == "/2.0/repositories" && r.URL.Query().Get("role") == "member":
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			result = page(r, m.tokenRepos[token])
+		case strings.HasPrefix(r.URL.Path, "/2.0/repositories/"):
+			if _, _, ok := r.BasicAuth(); !ok {
+				t.Errorf("expected repository listing to be authenticated with basic auth")
+			}
+			account := strings.TrimPrefix(r.URL.Path, "/2.0/repositories/")
+			ids, ok := m.accountRepos[account]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			result = page(r, ids)
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			t.Error(err)
+		}
+	}))
+
+	return m
+}
+
+func (m *mockBitbucketCloud) client() *bitbucketcloud.Client {
+	cli := bitbucketcloud.NewClient(nil)
+	cli.URL, _ = url.Parse(m.URL)
+	cli.Username = "admin"
+	cli.AppPassword = "app-password"
+	return cli
+}
+
+func mustURL(t *testing.T, u string) *url.URL {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func ua(accountID, token string) *extsvc.ExternalAccount {
+	var a extsvc.ExternalAccount
+	a.AccountID = accountID
+	bitbucketcloud.SetExternalAccountData(&a.ExternalAccountData, nil, &oauth2.Token{
+		AccessToken: token,
+	})
+	return &a
+}
+
+func rp(name, uuid string) *types.Repo {
+	return &types.Repo{
+		Name: api.RepoName(name),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          uuid,
+			ServiceType: bitbucketcloud.ServiceType,
+			ServiceID:   "https://bitbucket.org/",
+		},
+	}
+}
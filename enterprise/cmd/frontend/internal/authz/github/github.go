@@ -107,12 +107,12 @@ func (p *Provider) RepoPerms(ctx context.Context, userAccount *extsvc.ExternalAc
 	if err := populatePerms(p.getCachedUserRepos); err != nil {
 		return nil, err
 	}
-	if len(remaining) == 0 {
-		return perms, nil
-	}
-	if err := populatePermsPublic(p.getCachedPublicRepos); err != nil {
-		return nil, err
+	if len(remaining) > 0 {
+		if err := populatePermsPublic(p.getCachedPublicRepos); err != nil {
+			return nil, err
+		}
 	}
+	authz.RecordCacheStats(ctx, len(repos)-len(remaining), len(remaining))
 	if len(remaining) == 0 {
 		return perms, nil
 	}
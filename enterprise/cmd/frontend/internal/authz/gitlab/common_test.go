@@ -3,7 +3,9 @@ package gitlab
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"testing"
 
@@ -138,6 +140,9 @@ func (m *mockGitLab) GetProject(c *gitlab.Client, ctx context.Context, op gitlab
 	if proj.Visibility == gitlab.Internal && m.isClientAuthenticated(c) {
 		return proj, nil
 	}
+	if m.isClientAdmin(c) {
+		return proj, nil
+	}
 
 	acctID := m.getAcctID(c)
 	for _, accessibleProjID := range append(m.privateGuest[acctID], m.privateRepo[acctID]...) {
@@ -186,6 +191,37 @@ func (m *mockGitLab) ListTree(c *gitlab.Client, ctx context.Context, op gitlab.L
 	return nil, gitlab.ErrNotFound
 }
 
+func (m *mockGitLab) ListProjectMembers(c *gitlab.Client, ctx context.Context, urlStr string) (members []*gitlab.Member, nextPageURL *string, err error) {
+	var projID int
+	if _, err := fmt.Sscanf(urlStr, "projects/%d/members/all", &projID); err != nil {
+		m.t.Fatalf("could not parse ListProjectMembers urlStr %q: %s", urlStr, err)
+	}
+	if _, ok := m.projs[projID]; !ok {
+		return nil, nil, gitlab.ErrNotFound
+	}
+
+	levels := map[int32]gitlab.AccessLevel{}
+	for userID, projIDs := range m.privateGuest {
+		for _, id := range projIDs {
+			if id == projID {
+				levels[userID] = 10
+			}
+		}
+	}
+	for userID, projIDs := range m.privateRepo {
+		for _, id := range projIDs {
+			if id == projID {
+				levels[userID] = 30
+			}
+		}
+	}
+	for userID, level := range levels {
+		members = append(members, &gitlab.Member{User: gitlab.User{ID: userID}, AccessLevel: level})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+	return members, nil, nil
+}
+
 // isClientAuthenticated returns true if the client is authenticated. User is authenticated if OAuth
 // token is non-empty (note: this mock impl doesn't verify validity of the OAuth token) or if the
 // personal access token is non-empty (note: this mock impl requires that the PAT be equivalent to
@@ -194,6 +230,12 @@ func (m *mockGitLab) isClientAuthenticated(c *gitlab.Client) bool {
 	return c.OAuthToken != "" || (m.sudoTok != "" && c.PersonalAccessToken == m.sudoTok)
 }
 
+// isClientAdmin returns true if the client uses the mock GitLab sudo token without impersonating a
+// user, which means it can access all projects.
+func (m *mockGitLab) isClientAdmin(c *gitlab.Client) bool {
+	return m.sudoTok != "" && c.PersonalAccessToken == m.sudoTok && c.Sudo == ""
+}
+
 func (m *mockGitLab) getAcctID(c *gitlab.Client) int32 {
 	if c.OAuthToken != "" {
 		return m.oauthToks[c.OAuthToken]
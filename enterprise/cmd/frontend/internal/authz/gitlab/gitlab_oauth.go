@@ -93,6 +93,7 @@ func (p *GitLabOAuthAuthzProvider) RepoPerms(ctx context.Context, account *extsv
 		if vis, exists := cacheGetRepoVisibility(p.cache, projID, p.cacheTTL); exists {
 			if v := vis.Visibility; v == gitlab.Public || (v == gitlab.Internal && accountID != "") {
 				perms = append(perms, authz.RepoPerms{Repo: repo, Perms: authz.Read})
+				authz.RecordCacheStats(ctx, 1, 0)
 				continue
 			}
 		}
@@ -105,6 +106,7 @@ func (p *GitLabOAuthAuthzProvider) RepoPerms(ctx context.Context, account *extsv
 					rp.Perms = authz.Read
 				}
 				perms = append(perms, rp)
+				authz.RecordCacheStats(ctx, 1, 0)
 				continue
 			}
 		}
@@ -120,6 +122,7 @@ func (p *GitLabOAuthAuthzProvider) RepoPerms(ctx context.Context, account *extsv
 			oauthToken = tok.AccessToken
 		}
 
+		authz.RecordCacheStats(ctx, 0, 1)
 		isAccessible, vis, isContentAccessible, err := p.fetchProjVis(ctx, oauthToken, projID)
 		if err != nil {
 			log15.Error("Failed to fetch visibility for GitLab project", "projectID", projID, "gitlabHost", p.codeHost.BaseURL.String(), "error", err)
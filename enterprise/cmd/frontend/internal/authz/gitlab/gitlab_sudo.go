@@ -44,7 +44,7 @@ type SudoProvider struct {
 	cacheTTL          time.Duration
 }
 
-var _ authz.Provider = ((*SudoProvider)(nil))
+var _ authz.RepoUsersProvider = ((*SudoProvider)(nil))
 
 type SudoProviderOp struct {
 	// BaseURL is the URL of the GitLab instance.
@@ -135,6 +135,7 @@ func (p *SudoProvider) RepoPerms(ctx context.Context, account *extsvc.ExternalAc
 		if vis, exists := cacheGetRepoVisibility(p.cache, projID, p.cacheTTL); exists {
 			if v := vis.Visibility; v == gitlab.Public || (v == gitlab.Internal && accountID != "") {
 				perms = append(perms, authz.RepoPerms{Repo: repo, Perms: authz.Read})
+				authz.RecordCacheStats(ctx, 1, 0)
 				continue
 			}
 		}
@@ -147,6 +148,7 @@ func (p *SudoProvider) RepoPerms(ctx context.Context, account *extsvc.ExternalAc
 					rp.Perms = authz.Read
 				}
 				perms = append(perms, rp)
+				authz.RecordCacheStats(ctx, 1, 0)
 				continue
 			}
 		}
@@ -162,6 +164,7 @@ func (p *SudoProvider) RepoPerms(ctx context.Context, account *extsvc.ExternalAc
 			sudo = strconv.Itoa(int(usr.ID))
 		}
 
+		authz.RecordCacheStats(ctx, 0, 1)
 		isAccessible, vis, isContentAccessible, err := p.fetchProjVis(ctx, sudo, projID)
 		if err != nil {
 			log15.Error("Failed to fetch visibility for GitLab project", "projectID", projID, "gitlabHost", p.codeHost.BaseURL.String(), "error", err)
@@ -300,6 +303,46 @@ func (p *SudoProvider) FetchAccount(ctx context.Context, user *types.User, curre
 	return &glExternalAccount, nil
 }
 
+// RepoUsers satisfies the authz.RepoUsersProvider interface. Every user can read public and
+// internal projects. The users that can read a private project are its members (including members
+// of its ancestor groups) whose access level is at least Reporter.
+func (p *SudoProvider) RepoUsers(ctx context.Context, repo *types.Repo) (accountIDs []string, everyone bool, err error) {
+	projID, err := strconv.Atoi(repo.ExternalRepo.ID)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "GitLab repo external ID did not parse to int")
+	}
+
+	client := p.clientProvider.GetPATClient(p.sudoToken, "")
+	proj, err := client.GetProject(ctx, gitlab.GetProjectOp{
+		ID:       projID,
+		CommonOp: gitlab.CommonOp{NoCache: true},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if proj.Visibility != gitlab.Private {
+		return nil, true, nil
+	}
+
+	for urlStr := fmt.Sprintf("projects/%d/members/all?per_page=100", projID); urlStr != ""; {
+		members, next, err := client.ListProjectMembers(ctx, urlStr)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, m := range members {
+			if m.AccessLevel >= gitlab.AccessLevelReporter {
+				accountIDs = append(accountIDs, strconv.Itoa(int(m.ID)))
+			}
+		}
+
+		urlStr = ""
+		if next != nil {
+			urlStr = *next
+		}
+	}
+	return accountIDs, false, nil
+}
+
 func (p *SudoProvider) fetchAccountByExternalUID(ctx context.Context, uid string) (*gitlab.User, error) {
 	q := make(url.Values)
 	q.Add("extern_uid", uid)
@@ -392,3 +392,52 @@ func Test_SudoProvider_RepoPerms(t *testing.T) {
 		})
 	}
 }
+
+func Test_SudoProvider_RepoUsers(t *testing.T) {
+	gitlabMock := newMockGitLab(mockGitLabOp{
+		t:             t,
+		publicProjs:   []int{1},
+		internalProjs: []int{2},
+		privateProjs: map[int][2][]int32{
+			3: {[]int32{11}, []int32{12, 13}},
+		},
+		sudoTok: "sudo-token",
+	})
+	gitlab.MockGetProject = gitlabMock.GetProject
+	gitlab.MockListProjectMembers = gitlabMock.ListProjectMembers
+	defer func() {
+		gitlab.MockGetProject = nil
+		gitlab.MockListProjectMembers = nil
+	}()
+
+	p := NewSudoProvider(SudoProviderOp{
+		BaseURL:   mustURL(t, "https://gitlab.mine"),
+		SudoToken: "sudo-token",
+		MockCache: make(mockCache),
+	})
+
+	tests := []struct {
+		description string
+		repo        *types.Repo
+		expAccounts []string
+		expEveryone bool
+	}{
+		{description: "public", repo: repo("public/repo1", gitlab.ServiceType, "https://gitlab.mine/", "1"), expEveryone: true},
+		{description: "internal", repo: repo("internal/repo1", gitlab.ServiceType, "https://gitlab.mine/", "2"), expEveryone: true},
+		{description: "private omits guests", repo: repo("u1/repo1", gitlab.ServiceType, "https://gitlab.mine/", "3"), expAccounts: []string{"12", "13"}},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			accounts, everyone, err := p.RepoUsers(context.Background(), test.repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if everyone != test.expEveryone {
+				t.Errorf("got everyone %v, want %v", everyone, test.expEveryone)
+			}
+			if !reflect.DeepEqual(accounts, test.expAccounts) {
+				t.Errorf("got accounts %v, want %v", accounts, test.expAccounts)
+			}
+		})
+	}
+}
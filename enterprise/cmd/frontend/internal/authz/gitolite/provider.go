@@ -0,0 +1,257 @@
+// Package gitolite contains an authorization provider for Gitolite.
+package gitolite
+
+import (
+	"context"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitolite"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+	"golang.org/x/sync/singleflight"
+)
+
+// Identity types that map Sourcegraph users to Gitolite usernames.
+const (
+	// IdentityUsername maps a Sourcegraph user to the Gitolite user with the same username.
+	IdentityUsername = "username"
+	// IdentityEmail maps a Sourcegraph user to the Gitolite user whose username is one of
+	// the Sourcegraph user's verified email addresses.
+	IdentityEmail = "email"
+)
+
+// configPath is the path of the main configuration file in the Gitolite admin repository.
+const configPath = "conf/gitolite.conf"
+
+// Provider implements authz.Provider for Gitolite repository permissions. It computes
+// permissions from the access rules defined in the Gitolite admin repository, which it
+// reads through gitserver.
+type Provider struct {
+	serviceID string
+	adminRepo api.RepoName
+	identity  string
+	ttl       time.Duration
+
+	fetches singleflight.Group // deduplicates concurrent reloads of the access rules
+
+	mu        sync.Mutex // guards the fields below, but is not held while reading the admin repository
+	rules     *accessRules
+	commit    api.CommitID
+	fetchedAt time.Time
+}
+
+var _ authz.Provider = ((*Provider)(nil))
+
+var clock = time.Now
+
+// NewProvider returns a new Gitolite authorization provider for the Gitolite host with the
+// given service ID. The access rules are read from the Sourcegraph repository adminRepo, which
+// must be the mirror of the Gitolite admin repository, and are cached for the given TTL.
+// The identity argument is one of IdentityUsername or IdentityEmail.
+func NewProvider(serviceID string, adminRepo api.RepoName, identity string, ttl time.Duration) *Provider {
+	return &Provider{
+		serviceID: serviceID,
+		adminRepo: adminRepo,
+		identity:  identity,
+		ttl:       ttl,
+	}
+}
+
+// RepoPerms implements the authz.Provider interface. A nil account is granted the
+// permissions of Gitolite's special "daemon" user, which represents unauthenticated access.
+func (p *Provider) RepoPerms(ctx context.Context, acct *extsvc.ExternalAccount, repos []*types.Repo) ([]authz.RepoPerms, error) {
+	if len(repos) == 0 {
+		return nil, nil
+	}
+
+	rules := p.cachedAccessRules()
+	if rules != nil {
+		authz.RecordCacheStats(ctx, len(repos), 0)
+	} else {
+		var err error
+		if rules, err = p.accessRules(ctx); err != nil {
+			return nil, err
+		}
+		authz.RecordCacheStats(ctx, 0, len(repos))
+	}
+
+	user := anonymousUser
+	if acct != nil && acct.ServiceID == p.serviceID && acct.ServiceType == gitolite.ServiceType {
+		user = acct.AccountID
+	}
+
+	perms := make([]authz.RepoPerms, 0, len(repos))
+	for _, r := range repos {
+		perm := authz.None
+		if rules.canRead(user, r.ExternalRepo.ID) {
+			perm = authz.Read
+		}
+		perms = append(perms, authz.RepoPerms{Repo: r, Perms: perm})
+	}
+	return perms, nil
+}
+
+// FetchAccount implements the authz.Provider interface. It returns an account for the Gitolite
+// user that the given user maps to with the configured identity type, or nil if the access
+// rules don't mention that Gitolite user. If the access rules grant permissions to "@all",
+// every Gitolite user is relevant, so an account is returned even if its name isn't mentioned.
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (mine *extsvc.ExternalAccount, err error) {
+	if user == nil {
+		return nil, nil
+	}
+
+	rules, err := p.accessRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := rules.users()
+	_, all := known["@all"]
+
+	var candidates []string
+	switch p.identity {
+	case IdentityUsername:
+		candidates = []string{user.Username}
+	case IdentityEmail:
+		emails, err := db.UserEmails.ListByUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range emails {
+			if e.VerifiedAt != nil {
+				candidates = append(candidates, e.Email)
+			}
+		}
+		sort.Strings(candidates)
+	default:
+		return nil, errors.Errorf("unknown identity type %q", p.identity)
+	}
+
+	for _, name := range candidates {
+		if _, ok := known[name]; (!ok && !all) || name == anonymousUser {
+			continue
+		}
+		return &extsvc.ExternalAccount{
+			UserID: user.ID,
+			ExternalAccountSpec: extsvc.ExternalAccountSpec{
+				ServiceType: gitolite.ServiceType,
+				ServiceID:   p.serviceID,
+				AccountID:   name,
+			},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// ServiceID returns the Gitolite host (e.g. "git@gitolite.example.com") that identifies the
+// Gitolite instance this provider is configured with.
+func (p *Provider) ServiceID() string { return p.serviceID }
+
+// ServiceType returns the type of this Provider, namely, "gitolite".
+func (p *Provider) ServiceType() string { return gitolite.ServiceType }
+
+// Validate implements the authz.Provider interface.
+func (p *Provider) Validate() (problems []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := p.accessRules(ctx); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+// accessRules returns the access rules of the admin repository, reloading them if they are
+// older than the provider's TTL.
+func (p *Provider) accessRules(ctx context.Context) (*accessRules, error) {
+	if rules := p.cachedAccessRules(); rules != nil {
+		return rules, nil
+	}
+
+	// Concurrent callers share a single reload, and callers that find fresh rules above don't
+	// wait for it.
+	v, err, _ := p.fetches.Do("", func() (interface{}, error) { return p.fetchAccessRules(ctx) })
+	if err != nil {
+		return nil, err
+	}
+	return v.(*accessRules), nil
+}
+
+// cachedAccessRules returns the cached access rules, or nil if they are older than the
+// provider's TTL.
+func (p *Provider) cachedAccessRules() *accessRules {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.rules != nil && clock().Sub(p.fetchedAt) < p.ttl {
+		return p.rules
+	}
+	return nil
+}
+
+// fetchAccessRules reads the access rules at the HEAD of the admin repository (unless they are
+// unchanged since the last read) and caches them.
+func (p *Provider) fetchAccessRules(ctx context.Context) (*accessRules, error) {
+	now := clock()
+	repo := gitserver.Repo{Name: p.adminRepo}
+	commit, err := git.ResolveRevision(ctx, repo, nil, "HEAD", nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving HEAD of Gitolite admin repository %q", p.adminRepo)
+	}
+
+	p.mu.Lock()
+	rules, prevCommit := p.rules, p.commit
+	p.mu.Unlock()
+
+	if rules == nil || commit != prevCommit {
+		rules, err = parseAccessRules(ctx, &gitFileReader{repo: repo, commit: commit}, configPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing access rules of Gitolite admin repository %q", p.adminRepo)
+		}
+	}
+
+	p.mu.Lock()
+	p.rules, p.commit, p.fetchedAt = rules, commit, now
+	p.mu.Unlock()
+	return rules, nil
+}
+
+// gitFileReader implements fileReader by reading files at a given commit through gitserver.
+type gitFileReader struct {
+	repo   gitserver.Repo
+	commit api.CommitID
+}
+
+func (r *gitFileReader) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	return git.ReadFile(ctx, r.repo, r.commit, name, 0)
+}
+
+func (r *gitFileReader) Glob(ctx context.Context, pattern string) ([]string, error) {
+	fis, err := git.ReadDir(ctx, r.repo, r.commit, path.Dir(pattern), false)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		name := path.Join(path.Dir(pattern), path.Base(fi.Name()))
+		if ok, err := path.Match(pattern, name); err != nil {
+			return nil, err
+		} else if ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
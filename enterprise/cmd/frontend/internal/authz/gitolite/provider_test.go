@@ -0,0 +1,282 @@
+package gitolite
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitolite"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+)
+
+const testServiceID = "git@gitolite.example.com"
+
+// mockAdminRepo mocks the Gitolite admin repository with the given files at HEAD and
+// returns a pointer to the number of times the configuration file was read.
+func mockAdminRepo(head *api.CommitID, files map[string]string) *int {
+	reads := 0
+	git.Mocks.ResolveRevision = func(spec string, opt *git.ResolveRevisionOptions) (api.CommitID, error) {
+		return *head, nil
+	}
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		if name == configPath {
+			reads++
+		}
+		return mapFileReader(files).ReadFile(context.Background(), name)
+	}
+	return &reads
+}
+
+func TestProvider_RepoPerms(t *testing.T) {
+	defer git.ResetMocks()
+	head := api.CommitID("deadbeef")
+	mockAdminRepo(&head, map[string]string{
+		configPath: "repo foo\n    R = alice\nrepo bar\n    R = @all daemon\n",
+	})
+
+	p := NewProvider(testServiceID, "gitolite.example.com/gitolite-admin", IdentityUsername, time.Hour)
+	repos := []*types.Repo{rp("foo"), rp("bar"), rp("baz")}
+
+	for _, tc := range []struct {
+		name string
+		acct *extsvc.ExternalAccount
+		want []authz.RepoPerms
+	}{
+		{
+			name: "alice",
+			acct: acct("alice"),
+			want: []authz.RepoPerms{
+				{Repo: repos[0], Perms: authz.Read},
+				{Repo: repos[1], Perms: authz.Read},
+				{Repo: repos[2], Perms: authz.None},
+			},
+		},
+		{
+			name: "bob",
+			acct: acct("bob"),
+			want: []authz.RepoPerms{
+				{Repo: repos[0], Perms: authz.None},
+				{Repo: repos[1], Perms: authz.Read},
+				{Repo: repos[2], Perms: authz.None},
+			},
+		},
+		{
+			name: "anonymous",
+			want: []authz.RepoPerms{
+				{Repo: repos[0], Perms: authz.None},
+				{Repo: repos[1], Perms: authz.Read},
+				{Repo: repos[2], Perms: authz.None},
+			},
+		},
+		{
+			name: "account of another Gitolite host",
+			acct: &extsvc.ExternalAccount{ExternalAccountSpec: extsvc.ExternalAccountSpec{
+				ServiceType: gitolite.ServiceType,
+				ServiceID:   "git@other.example.com",
+				AccountID:   "alice",
+			}},
+			want: []authz.RepoPerms{
+				{Repo: repos[0], Perms: authz.None},
+				{Repo: repos[1], Perms: authz.Read},
+				{Repo: repos[2], Perms: authz.None},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := p.RepoPerms(context.Background(), tc.acct, repos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(have, tc.want) {
+				t.Errorf("have perms %+v, want %+v", have, tc.want)
+			}
+		})
+	}
+}
+
+func TestProvider_accessRules_cache(t *testing.T) {
+	defer git.ResetMocks()
+	head := api.CommitID("c1")
+	reads := mockAdminRepo(&head, map[string]string{
+		configPath: "repo foo\n    R = alice\n",
+	})
+
+	now := time.Now()
+	clock = func() time.Time { return now }
+	defer func() { clock = time.Now }()
+
+	p := NewProvider(testServiceID, "gitolite.example.com/gitolite-admin", IdentityUsername, time.Hour)
+	ctx := context.Background()
+
+	for _, step := range []struct {
+		advance   time.Duration
+		head      api.CommitID
+		wantReads int
+	}{
+		{wantReads: 1},
+		{advance: 30 * time.Minute, head: "c2", wantReads: 1}, // cached
+		{advance: time.Hour, head: "c2", wantReads: 2},        // expired, new commit
+		{advance: time.Hour, head: "c2", wantReads: 2},        // expired, same commit
+	} {
+		now = now.Add(step.advance)
+		if step.head != "" {
+			head = step.head
+		}
+		if _, err := p.accessRules(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if *reads != step.wantReads {
+			t.Errorf("have %d reads, want %d", *reads, step.wantReads)
+		}
+	}
+}
+
+func TestProvider_accessRules_concurrent(t *testing.T) {
+	defer git.ResetMocks()
+	head := api.CommitID("c1")
+	reads := mockAdminRepo(&head, map[string]string{
+		configPath: "repo foo\n    R = alice\n",
+	})
+
+	now := time.Now()
+	clock = func() time.Time { return now }
+	defer func() { clock = time.Now }()
+
+	p := NewProvider(testServiceID, "gitolite.example.com/gitolite-admin", IdentityUsername, time.Hour)
+	ctx := context.Background()
+	if _, err := p.accessRules(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// While the expired rules are being reloaded, callers that find fresh rules must not wait
+	// for the reload, and concurrent reloads must be deduplicated.
+	resolving, unblock := make(chan struct{}), make(chan struct{})
+	git.Mocks.ResolveRevision = func(spec string, opt *git.ResolveRevisionOptions) (api.CommitID, error) {
+		close(resolving)
+		<-unblock
+		return "c2", nil
+	}
+	now = now.Add(2 * time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.accessRules(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	<-resolving
+
+	p.mu.Lock()
+	p.fetchedAt = now
+	p.mu.Unlock()
+	if _, err := p.accessRules(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	close(unblock)
+	wg.Wait()
+	if *reads != 2 {
+		t.Errorf("have %d reads, want 2", *reads)
+	}
+}
+
+func TestProvider_FetchAccount(t *testing.T) {
+	defer git.ResetMocks()
+	head := api.CommitID("deadbeef")
+	mockAdminRepo(&head, map[string]string{
+		configPath: "@devs = alice@example.com bob\nrepo foo\n    R = @devs\n",
+	})
+
+	now := time.Now()
+	db.Mocks.UserEmails.ListByUser = func(id int32) ([]*db.UserEmail, error) {
+		return []*db.UserEmail{
+			{UserID: id, Email: "alice@other.com", VerifiedAt: &now},
+			{UserID: id, Email: "alice@example.com", VerifiedAt: &now},
+			{UserID: id, Email: "bob"},
+		}, nil
+	}
+	defer func() { db.Mocks.UserEmails.ListByUser = nil }()
+
+	for _, tc := range []struct {
+		identity string
+		user     *types.User
+		want     *extsvc.ExternalAccount
+	}{
+		{identity: IdentityUsername, user: &types.User{ID: 1, Username: "bob"}, want: acct("bob")},
+		{identity: IdentityUsername, user: &types.User{ID: 1, Username: "carol"}},
+		{identity: IdentityEmail, user: &types.User{ID: 1, Username: "alice"}, want: acct("alice@example.com")},
+		{identity: IdentityEmail, user: nil},
+	} {
+		p := NewProvider(testServiceID, "gitolite.example.com/gitolite-admin", tc.identity, time.Hour)
+		have, err := p.FetchAccount(context.Background(), tc.user, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.want != nil {
+			tc.want.UserID = tc.user.ID
+		}
+		if !reflect.DeepEqual(have, tc.want) {
+			t.Errorf("%s %+v: have account %+v, want %+v", tc.identity, tc.user, have, tc.want)
+		}
+	}
+}
+
+func TestProvider_FetchAccount_all(t *testing.T) {
+	defer git.ResetMocks()
+	head := api.CommitID("deadbeef")
+	mockAdminRepo(&head, map[string]string{
+		configPath: "repo foo\n    R = alice\nrepo bar\n    R = @all\n",
+	})
+
+	p := NewProvider(testServiceID, "gitolite.example.com/gitolite-admin", IdentityUsername, time.Hour)
+	for _, tc := range []struct {
+		user *types.User
+		want *extsvc.ExternalAccount
+	}{
+		{user: &types.User{ID: 1, Username: "carol"}, want: acct("carol")},
+		{user: &types.User{ID: 1, Username: "daemon"}},
+	} {
+		have, err := p.FetchAccount(context.Background(), tc.user, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.want != nil {
+			tc.want.UserID = tc.user.ID
+		}
+		if !reflect.DeepEqual(have, tc.want) {
+			t.Errorf("%+v: have account %+v, want %+v", tc.user, have, tc.want)
+		}
+	}
+}
+
+func acct(username string) *extsvc.ExternalAccount {
+	return &extsvc.ExternalAccount{
+		ExternalAccountSpec: extsvc.ExternalAccountSpec{
+			ServiceType: gitolite.ServiceType,
+			ServiceID:   testServiceID,
+			AccountID:   username,
+		},
+	}
+}
+
+func rp(name string) *types.Repo {
+	return &types.Repo{
+		Name: api.RepoName("gitolite.example.com/" + name),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          name,
+			ServiceType: gitolite.ServiceType,
+			ServiceID:   testServiceID,
+		},
+	}
+}
@@ -0,0 +1,292 @@
+package gitolite
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// accessRules are the repository access rules of a Gitolite instance, as defined in the
+// conf/gitolite.conf file of its admin repository (and the files it includes).
+//
+// Only the subset of the configuration language that is relevant to read access is
+// understood: group definitions, repo blocks, access rules and the "deny-rules" option.
+// See https://gitolite.com/gitolite/conf.html for the full syntax.
+type accessRules struct {
+	groups map[string][]string // group name (including the "@") to members
+	blocks []*repoBlock
+
+	// groupPatterns are the compiled regular expressions of the wild repos that are group
+	// members, keyed by name.
+	groupPatterns map[string]*regexp.Regexp
+}
+
+// repoBlock is a "repo" line followed by the access rules and options that apply to the
+// repos listed in it.
+type repoBlock struct {
+	repos     []string
+	patterns  map[string]*regexp.Regexp // compiled regular expressions of the wild repos in repos
+	rules     []*accessRule
+	denyRules bool
+}
+
+// accessRule is a single access rule line, such as "RW+ master = @devs alice".
+type accessRule struct {
+	perm    string
+	refexes []string
+	users   []string
+}
+
+// anonymousUser is the special Gitolite user that represents unauthenticated access (e.g.
+// through git-daemon). Note that "@all" does not include it.
+const anonymousUser = "daemon"
+
+// fileReader reads files of the Gitolite admin repository.
+type fileReader interface {
+	// ReadFile returns the contents of the file at the given path.
+	ReadFile(ctx context.Context, name string) ([]byte, error)
+	// Glob returns the paths of the files that match the given pattern (as defined by
+	// path.Match).
+	Glob(ctx context.Context, pattern string) ([]string, error)
+}
+
+var (
+	permPattern     = regexp.MustCompile(`^(-|C|R|RW\+?C?D?M?)$`)
+	repoNamePattern = regexp.MustCompile(`^[0-9a-zA-Z._@/+-]+$`)
+)
+
+// maxIncludeDepth bounds recursive includes so that cyclic includes don't loop forever.
+const maxIncludeDepth = 10
+
+// parseAccessRules parses the Gitolite configuration file at the given path, following
+// includes relative to its directory.
+func parseAccessRules(ctx context.Context, r fileReader, name string) (*accessRules, error) {
+	rs := &accessRules{groups: map[string][]string{}, groupPatterns: map[string]*regexp.Regexp{}}
+	p := &rulesParser{rules: rs, reader: r, dir: path.Dir(name)}
+	if err := p.parseFile(ctx, name, 0); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+type rulesParser struct {
+	rules  *accessRules
+	reader fileReader
+	dir    string
+	block  *repoBlock
+}
+
+func (p *rulesParser) parseFile(ctx context.Context, name string, depth int) error {
+	if depth > maxIncludeDepth {
+		return errors.Errorf("%s: too many nested includes", name)
+	}
+
+	data, err := p.reader.ReadFile(ctx, name)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", name)
+	}
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; s.Scan(); n++ {
+		if err := p.parseLine(ctx, s.Text(), depth); err != nil {
+			return errors.Wrapf(err, "%s:%d", name, n)
+		}
+	}
+	return s.Err()
+}
+
+func (p *rulesParser) parseLine(ctx context.Context, line string, depth int) error {
+	if i := strings.Index(line, "#"); i >= 0 {
+		line = line[:i]
+	}
+
+	// Most directives are of the form "lhs = rhs", where whitespace around the "=" is optional.
+	var lhs, rhs []string
+	if i := strings.Index(line, "="); i >= 0 {
+		lhs, rhs = strings.Fields(line[:i]), strings.Fields(line[i+1:])
+	} else {
+		lhs = strings.Fields(line)
+	}
+
+	if len(lhs) == 0 {
+		return nil
+	}
+
+	isAssignment := strings.Contains(line, "=")
+	switch kw := lhs[0]; {
+	case kw == "include" && !isAssignment:
+		if len(lhs) != 2 {
+			return errors.New("malformed include")
+		}
+		return p.include(ctx, strings.Trim(lhs[1], `"'`), depth)
+
+	case kw == "subconf" && !isAssignment:
+		// Delegated configuration is not supported. Ignoring it can only deny access.
+		return nil
+
+	case kw == "repo" && !isAssignment:
+		p.block = &repoBlock{repos: lhs[1:], patterns: map[string]*regexp.Regexp{}}
+		compileWildRepos(lhs[1:], p.block.patterns)
+		p.rules.blocks = append(p.rules.blocks, p.block)
+		return nil
+
+	case strings.HasPrefix(kw, "@") && len(lhs) == 1 && isAssignment:
+		p.rules.groups[kw] = append(p.rules.groups[kw], rhs...)
+		compileWildRepos(rhs, p.rules.groupPatterns)
+		return nil
+
+	case kw == "option" && isAssignment:
+		if p.block != nil && len(lhs) == 2 && lhs[1] == "deny-rules" && len(rhs) == 1 {
+			p.block.denyRules = rhs[0] == "1"
+		}
+		return nil
+
+	case permPattern.MatchString(kw) && isAssignment:
+		if p.block == nil {
+			return errors.New("access rule outside of a repo block")
+		}
+		p.block.rules = append(p.block.rules, &accessRule{
+			perm:    kw,
+			refexes: lhs[1:],
+			users:   rhs,
+		})
+		return nil
+	}
+
+	// Other directives (e.g. "config") are ignored, since they can't grant read access.
+	return nil
+}
+
+// compileWildRepos adds the compiled regular expressions of the wild repos among the given
+// names to patterns. Names that aren't valid regular expressions are skipped, so that they never
+// match.
+func compileWildRepos(names []string, patterns map[string]*regexp.Regexp) {
+	for _, name := range names {
+		if repoNamePattern.MatchString(name) {
+			continue
+		}
+		if re, err := regexp.Compile("^(?:" + name + ")$"); err == nil {
+			patterns[name] = re
+		}
+	}
+}
+
+func (p *rulesParser) include(ctx context.Context, pattern string, depth int) error {
+	pattern = path.Join(p.dir, pattern)
+	if !strings.ContainsAny(pattern, "*?[") {
+		return p.parseFile(ctx, pattern, depth+1)
+	}
+
+	names, err := p.reader.Glob(ctx, pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := p.parseFile(ctx, name, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canRead returns true if the given Gitolite user can read the given repo.
+//
+// As in Gitolite, a user can read a repo if any access rule that applies to both grants it
+// "R" access. If the "deny-rules" option is set for the repo, rules are instead evaluated in
+// order and the first one that applies wins, so that "-" rules (without refexes) deny access.
+func (rs *accessRules) canRead(user, repo string) bool {
+	var matching []*repoBlock
+	denyRules := false
+	for _, b := range rs.blocks {
+		if rs.matchRepo(b, repo) {
+			matching = append(matching, b)
+			denyRules = denyRules || b.denyRules
+		}
+	}
+
+	for _, b := range matching {
+		for _, r := range b.rules {
+			if !rs.matchUser(r.users, user) {
+				continue
+			}
+			if r.perm == "-" {
+				if denyRules && len(r.refexes) == 0 {
+					return false
+				}
+				continue
+			}
+			if strings.Contains(r.perm, "R") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// users returns all the users that are mentioned in the access rules. It includes "@all" if
+// any access rule applies to all users.
+func (rs *accessRules) users() map[string]struct{} {
+	users := map[string]struct{}{}
+	var add func(names []string, seen map[string]bool)
+	add = func(names []string, seen map[string]bool) {
+		for _, name := range names {
+			if !strings.HasPrefix(name, "@") || name == "@all" {
+				users[name] = struct{}{}
+			} else if !seen[name] {
+				seen[name] = true
+				add(rs.groups[name], seen)
+			}
+		}
+	}
+
+	for _, b := range rs.blocks {
+		for _, r := range b.rules {
+			add(r.users, map[string]bool{})
+		}
+	}
+	return users
+}
+
+func (rs *accessRules) matchUser(names []string, user string) bool {
+	return rs.match(names, func(name string) bool {
+		return name == user || (name == "@all" && user != anonymousUser)
+	}, map[string]bool{})
+}
+
+func (rs *accessRules) matchRepo(b *repoBlock, repo string) bool {
+	return rs.match(b.repos, func(name string) bool {
+		if name == "@all" || name == repo {
+			return true
+		}
+		// Names with other characters are regular expressions ("wild repos"), which are
+		// compiled when the configuration is parsed.
+		re, ok := b.patterns[name]
+		if !ok {
+			re = rs.groupPatterns[name]
+		}
+		return re != nil && re.MatchString(repo)
+	}, map[string]bool{})
+}
+
+// match returns true if any of the given names satisfies the given predicate, recursively
+// expanding group names into their members.
+func (rs *accessRules) match(names []string, pred func(string) bool, seen map[string]bool) bool {
+	for _, name := range names {
+		if pred(name) {
+			return true
+		}
+		if members, ok := rs.groups[name]; ok && !seen[name] {
+			seen[name] = true
+			if rs.match(members, pred, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package gitolite
+
+import (
+	"context"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const testConfig = `
+# Groups can be defined in several lines.
+@admins   = alice
+@devs     = bob @admins
+@devs     = carol
+@frontend = web-app web-ui
+@ops      = ops/[a-z]+
+
+repo gitolite-admin
+    RW+     =   @admins
+
+repo @frontend
+    RW+         =   @devs
+    R           =   dave
+
+repo secret
+    RW  master  =   eve
+    RW+=bob
+
+repo public
+    R   =   @all daemon
+
+repo team/[a-z]+ @ops
+    RW  =   frank
+
+repo restricted
+    option deny-rules = 1
+    -       =   carol
+    R       =   @devs
+
+include "repos/*.conf"
+`
+
+type mapFileReader map[string]string
+
+func (r mapFileReader) ReadFile(_ context.Context, name string) ([]byte, error) {
+	data, ok := r[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(data), nil
+}
+
+func (r mapFileReader) Glob(_ context.Context, pattern string) ([]string, error) {
+	var names []string
+	for name := range r {
+		if ok, _ := path.Match(pattern, name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func TestAccessRules_canRead(t *testing.T) {
+	rules, err := parseAccessRules(context.Background(), mapFileReader{
+		"conf/gitolite.conf":     testConfig,
+		"conf/repos/extra.conf":  "repo extra\n    R = carol\n",
+		"conf/repos/README.md":   "not a config file",
+		"conf/repos/broken.conf": "repo broken\n    R = @devs\n",
+	}, "conf/gitolite.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		user, repo string
+		want       bool
+	}{
+		{"alice", "gitolite-admin", true},
+		{"bob", "gitolite-admin", false},
+
+		// Repo and user groups, including nested groups.
+		{"alice", "web-app", true},
+		{"bob", "web-ui", true},
+		{"carol", "web-app", true},
+		{"dave", "web-app", true},
+		{"eve", "web-app", false},
+
+		// Rules with refexes, and without whitespace around "=".
+		{"eve", "secret", true},
+		{"bob", "secret", true},
+		{"alice", "secret", false},
+
+		// @all doesn't include the anonymous user.
+		{"eve", "public", true},
+		{anonymousUser, "public", true},
+		{anonymousUser, "web-app", false},
+
+		// Wild repos.
+		{"frank", "team/backend", true},
+		{"frank", "team/Backend", false},
+		{"frank", "other/backend", false},
+		{"frank", "ops/deploy", true},
+		{"frank", "ops/Deploy", false},
+
+		// Deny rules.
+		{"carol", "restricted", false},
+		{"bob", "restricted", true},
+
+		// Included files.
+		{"carol", "extra", true},
+		{"carol", "broken", true},
+		{"dave", "extra", false},
+
+		{"alice", "unknown", false},
+	} {
+		if have := rules.canRead(tc.user, tc.repo); have != tc.want {
+			t.Errorf("canRead(%q, %q): have %t, want %t", tc.user, tc.repo, have, tc.want)
+		}
+	}
+}
+
+func TestAccessRules_users(t *testing.T) {
+	rules, err := parseAccessRules(context.Background(), mapFileReader{
+		"conf/gitolite.conf": "@g = bob @g\nrepo foo\n    R = alice @g\n",
+	}, "conf/gitolite.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	have := rules.users()
+	want := map[string]struct{}{"alice": {}, "bob": {}}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have users %v, want %v", have, want)
+	}
+
+	rules, err = parseAccessRules(context.Background(), mapFileReader{
+		"conf/gitolite.conf": "@g = @all\nrepo foo\n    R = alice @g\n",
+	}, "conf/gitolite.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	have = rules.users()
+	want = map[string]struct{}{"alice": {}, "@all": {}}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have users %v, want %v", have, want)
+	}
+}
+
+func TestParseAccessRules_errors(t *testing.T) {
+	for name, config := range map[string]string{
+		"rule outside of repo block": "R = alice\n",
+		"cyclic include":             "include \"gitolite.conf\"\n",
+		"missing include":            "include \"missing.conf\"\n",
+	} {
+		_, err := parseAccessRules(context.Background(), mapFileReader{
+			"conf/gitolite.conf": config,
+		}, "conf/gitolite.conf")
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -15,6 +15,8 @@ type ExternalServicesStore interface {
 	ListGitLabConnections(context.Context) ([]*schema.GitLabConnection, error)
 	ListGitHubConnections(context.Context) ([]*schema.GitHubConnection, error)
 	ListBitbucketServerConnections(context.Context) ([]*schema.BitbucketServerConnection, error)
+	ListBitbucketCloudConnections(context.Context) ([]*schema.BitbucketCloudConnection, error)
+	ListGitoliteConnections(context.Context) ([]*schema.GitoliteConnection, error)
 }
 
 // ProvidersFromConfig returns the set of permission-related providers derived from the site config.
@@ -67,5 +69,23 @@ func ProvidersFromConfig(
 		warnings = append(warnings, warns...)
 	}
 
+	if bitbucketClouds, err := s.ListBitbucketCloudConnections(ctx); err != nil {
+		seriousProblems = append(seriousProblems, fmt.Sprintf("Could not load Bitbucket Cloud external service configs: %s", err))
+	} else {
+		ps, problems, warns := bitbucketCloudProviders(ctx, cfg, bitbucketClouds)
+		authzProviders = append(authzProviders, ps...)
+		seriousProblems = append(seriousProblems, problems...)
+		warnings = append(warnings, warns...)
+	}
+
+	if gitolites, err := s.ListGitoliteConnections(ctx); err != nil {
+		seriousProblems = append(seriousProblems, fmt.Sprintf("Could not load Gitolite external service configs: %s", err))
+	} else {
+		ps, problems, warns := gitoliteProviders(ctx, gitolites)
+		authzProviders = append(authzProviders, ps...)
+		seriousProblems = append(seriousProblems, problems...)
+		warnings = append(warnings, warns...)
+	}
+
 	return allowAccessByDefault, authzProviders, seriousProblems, warnings
 }
@@ -0,0 +1,313 @@
+package authz
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"golang.org/x/time/rate"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// A PermsSyncer periodically syncs the repository permissions of all users
+// from the authz providers into the permissions store, so that they don't
+// have to be fetched from the code hosts on the request path. Between the
+// syncs of each user, the users of new repositories are synced from the
+// providers that can list them (authz.RepoUsersProvider), so that new
+// repositories are covered for all users at once. It only syncs while
+// "permissions.backgroundSync" is enabled in site config.
+type PermsSyncer struct {
+	// Providers returns the current authz providers.
+	Providers func() []authz.Provider
+
+	mu sync.Mutex
+	// limiters rate limit the syncs of each authz provider, by service ID.
+	limiters map[string]*rate.Limiter
+}
+
+// Run syncs the permissions that are due to be synced until ctx is done.
+func (s *PermsSyncer) Run(ctx context.Context) error {
+	// Providers are asked about repos as an internal actor, so that the repos
+	// aren't themselves filtered by the permissions being synced.
+	ctx = actor.WithActor(ctx, &actor.Actor{Internal: true})
+
+	for {
+		seconds := 30 + rand.Intn(30)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(seconds) * time.Second):
+		}
+
+		if conf.PermissionsSyncEnabled() {
+			s.syncAll(ctx)
+		}
+	}
+}
+
+// syncAll syncs the permissions that are due to be synced from each authz
+// provider concurrently, so that a slow code host doesn't hold up the others.
+func (s *PermsSyncer) syncAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, p := range s.Providers() {
+		wg.Add(1)
+		go func(p authz.Provider) {
+			defer wg.Done()
+			if err := s.SyncProvider(ctx, p); err != nil {
+				log15.Error("PermsSyncer.SyncProvider", "serviceID", p.ServiceID(), "error", err)
+			}
+		}(p)
+	}
+	wg.Wait()
+}
+
+// SyncProvider syncs the permissions of the users with an external account
+// on the authz provider whose permissions weren't synced from it within the
+// sync interval, least recently synced first. Then, if the provider can list
+// the users of a repo, it syncs the users of the repos that were added since.
+func (s *PermsSyncer) SyncProvider(ctx context.Context, p authz.Provider) error {
+	if err := s.syncUsers(ctx, p); err != nil {
+		return err
+	}
+	if rp, ok := p.(authz.RepoUsersProvider); ok {
+		return s.syncNewRepos(ctx, rp)
+	}
+	return nil
+}
+
+// syncUsers syncs the permissions of the users whose permissions are due to
+// be synced from the authz provider.
+func (s *PermsSyncer) syncUsers(ctx context.Context, p authz.Provider) error {
+	accts, err := db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{
+		ServiceType: p.ServiceType(),
+		ServiceID:   p.ServiceID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	syncedAt, err := db.Permissions.ListSyncTimes(ctx, authz.Read, p.ServiceType(), p.ServiceID())
+	if err != nil {
+		return err
+	}
+
+	due := accountsToSync(accts, syncedAt, conf.PermissionsSyncInterval(), time.Now())
+	if len(due) == 0 {
+		return nil
+	}
+
+	repos, err := db.Repos.List(ctx, db.ReposListOptions{
+		Enabled:     true,
+		Disabled:    true,
+		ServiceID:   p.ServiceID(),
+		OnlyRepoIDs: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	limiter := s.limiter(p.ServiceID(), conf.PermissionsSyncsPerMinute())
+	for _, acct := range due {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		if err := s.syncUser(ctx, p, acct, repos); err != nil {
+			permsSyncs.WithLabelValues(p.ServiceType(), "error").Inc()
+			log15.Warn("Could not sync permissions of user", "userID", acct.UserID, "serviceID", p.ServiceID(), "error", err)
+			continue
+		}
+		permsSyncs.WithLabelValues(p.ServiceType(), "success").Inc()
+	}
+	return nil
+}
+
+// syncUser fetches the permissions of the user with the given external
+// account on the repos from the authz provider and stores them.
+func (s *PermsSyncer) syncUser(ctx context.Context, p authz.Provider, acct *extsvc.ExternalAccount, repos []*types.Repo) error {
+	now := time.Now()
+	perms, err := p.RepoPerms(ctx, acct, repos)
+	if err != nil {
+		return err
+	}
+	return db.Permissions.SetUserPermissions(ctx, userPermissions(p, acct.UserID, repos, perms, now))
+}
+
+// syncNewRepos syncs the users of the repos that were added since the
+// permissions of some users were last synced from the authz provider, in the
+// order they were added, and extends the users' permissions to cover them.
+// If fetching the users of a repo fails, the permissions are only extended
+// to cover the repos before it.
+func (s *PermsSyncer) syncNewRepos(ctx context.Context, p authz.RepoUsersProvider) error {
+	covered, ok, err := db.Permissions.MinMaxRepoID(ctx, authz.Read, p.ServiceType(), p.ServiceID())
+	if err != nil || !ok {
+		return err
+	}
+
+	repos, err := db.Repos.List(ctx, db.ReposListOptions{
+		Enabled:     true,
+		Disabled:    true,
+		ServiceID:   p.ServiceID(),
+		OnlyRepoIDs: true,
+	})
+	if err != nil {
+		return err
+	}
+	repos = newRepos(repos, covered)
+	if len(repos) == 0 {
+		return nil
+	}
+
+	accts, err := db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{
+		ServiceType: p.ServiceType(),
+		ServiceID:   p.ServiceID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	limiter := s.limiter(p.ServiceID(), conf.PermissionsSyncsPerMinute())
+	users := map[int32]*roaring.Bitmap{}
+	var maxRepoID api.RepoID
+	for _, r := range repos {
+		if err := limiter.Wait(ctx); err != nil {
+			break
+		}
+
+		accountIDs, everyone, err := p.RepoUsers(ctx, r)
+		if err != nil {
+			permsSyncs.WithLabelValues(p.ServiceType(), "error").Inc()
+			log15.Warn("Could not sync users of repo", "repoID", r.ID, "serviceID", p.ServiceID(), "error", err)
+			break
+		}
+		for _, userID := range repoUsers(accts, accountIDs, everyone) {
+			if users[userID] == nil {
+				users[userID] = roaring.NewBitmap()
+			}
+			users[userID].Add(uint32(r.ID))
+		}
+		maxRepoID = r.ID
+		permsSyncs.WithLabelValues(p.ServiceType(), "success").Inc()
+	}
+
+	if maxRepoID == 0 {
+		return ctx.Err()
+	}
+	return db.Permissions.ExtendUserPermissions(ctx, authz.Read, p.ServiceType(), p.ServiceID(), maxRepoID, users)
+}
+
+// newRepos returns the repos whose IDs are higher than covered, in ascending
+// order of their IDs.
+func newRepos(repos []*types.Repo, covered api.RepoID) []*types.Repo {
+	var rs []*types.Repo
+	for _, r := range repos {
+		if r.ID > covered {
+			rs = append(rs, r)
+		}
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].ID < rs[j].ID })
+	return rs
+}
+
+// repoUsers returns the IDs of the users whose external accounts have the
+// given account IDs, or of all users with an account if everyone is true.
+func repoUsers(accts []*extsvc.ExternalAccount, accountIDs []string, everyone bool) []int32 {
+	ids := make(map[string]bool, len(accountIDs))
+	for _, id := range accountIDs {
+		ids[id] = true
+	}
+
+	var userIDs []int32
+	for _, acct := range accts {
+		if everyone || ids[acct.AccountID] {
+			userIDs = append(userIDs, acct.UserID)
+		}
+	}
+	return userIDs
+}
+
+// limiter returns the rate limiter of the authz provider with the given
+// service ID, which allows n syncs per minute.
+func (s *PermsSyncer) limiter(serviceID string, n int) *rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit := rate.Every(time.Minute / time.Duration(n))
+	l, ok := s.limiters[serviceID]
+	if !ok {
+		if s.limiters == nil {
+			s.limiters = map[string]*rate.Limiter{}
+		}
+		l = rate.NewLimiter(limit, 1)
+		s.limiters[serviceID] = l
+	} else if l.Limit() != limit {
+		l.SetLimit(limit)
+	}
+	return l
+}
+
+// accountsToSync returns the accounts whose users' permissions are due to be
+// synced, given when they were last synced by user ID. Accounts whose
+// permissions were never synced come first, then the least recently synced.
+func accountsToSync(accts []*extsvc.ExternalAccount, syncedAt map[int32]time.Time, interval time.Duration, now time.Time) []*extsvc.ExternalAccount {
+	due := make([]*extsvc.ExternalAccount, 0, len(accts))
+	for _, acct := range accts {
+		if t, ok := syncedAt[acct.UserID]; !ok || now.Sub(t) >= interval {
+			due = append(due, acct)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return syncedAt[due[i].UserID].Before(syncedAt[due[j].UserID])
+	})
+	return due
+}
+
+// userPermissions returns the read permissions of the user on the repos that
+// were checked, given the permissions the authz provider returned.
+func userPermissions(p authz.Provider, userID int32, repos []*types.Repo, perms []authz.RepoPerms, syncedAt time.Time) *db.UserPermissions {
+	up := &db.UserPermissions{
+		UserID:      userID,
+		Perm:        authz.Read,
+		ServiceType: p.ServiceType(),
+		ServiceID:   p.ServiceID(),
+		RepoIDs:     roaring.NewBitmap(),
+		SyncedAt:    syncedAt,
+	}
+
+	for _, r := range repos {
+		if r.ID > up.MaxRepoID {
+			up.MaxRepoID = r.ID
+		}
+	}
+
+	for _, rp := range perms {
+		if rp.Perms.Include(authz.Read) && rp.Repo.ID != 0 && rp.Repo.ID <= up.MaxRepoID {
+			up.RepoIDs.Add(uint32(rp.Repo.ID))
+		}
+	}
+	up.RepoIDs.RunOptimize()
+	return up
+}
+
+var permsSyncs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "src",
+	Subsystem: "authz",
+	Name:      "perms_syncs_total",
+	Help:      "The total number of users whose permissions were synced in the background, by code host type and result.",
+}, []string{"service_type", "result"})
+
+func init() {
+	prometheus.MustRegister(permsSyncs)
+}
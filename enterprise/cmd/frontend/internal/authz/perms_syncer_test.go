@@ -0,0 +1,113 @@
+package authz
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+)
+
+func TestAccountsToSync(t *testing.T) {
+	now := time.Now()
+	accts := []*extsvc.ExternalAccount{{UserID: 1}, {UserID: 2}, {UserID: 3}, {UserID: 4}}
+	syncedAt := map[int32]time.Time{
+		1: now.Add(-2 * time.Hour),
+		2: now.Add(-10 * time.Minute),
+		3: now.Add(-3 * time.Hour),
+	}
+
+	var got []int32
+	for _, acct := range accountsToSync(accts, syncedAt, time.Hour, now) {
+		got = append(got, acct.UserID)
+	}
+	if want := []int32{4, 3, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got users %v, want %v", got, want)
+	}
+}
+
+func TestUserPermissions(t *testing.T) {
+	p := fakeProvider{serviceType: "gitlab", serviceID: "https://gitlab.mine/"}
+	repos := []*types.Repo{{ID: 1}, {ID: 4}, {ID: 2}}
+	perms := []authz.RepoPerms{
+		{Repo: &types.Repo{ID: 1}, Perms: authz.Read},
+		{Repo: &types.Repo{ID: 2}, Perms: authz.None},
+		{Repo: &types.Repo{ID: 4}, Perms: authz.Read | authz.Write},
+		{Repo: &types.Repo{ID: 5}, Perms: authz.Read}, // not checked
+	}
+	now := time.Now()
+
+	up := userPermissions(p, 7, repos, perms, now)
+	if up.UserID != 7 || up.Perm != authz.Read || up.ServiceType != "gitlab" || up.ServiceID != "https://gitlab.mine/" || !up.SyncedAt.Equal(now) {
+		t.Errorf("got unexpected permissions %+v", up)
+	}
+	if want := api.RepoID(4); up.MaxRepoID != want {
+		t.Errorf("got max repo ID %d, want %d", up.MaxRepoID, want)
+	}
+	if got, want := up.RepoIDs.ToArray(), []uint32{1, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got repo IDs %v, want %v", got, want)
+	}
+}
+
+func TestNewRepos(t *testing.T) {
+	repos := []*types.Repo{{ID: 5}, {ID: 2}, {ID: 4}, {ID: 3}}
+
+	var got []api.RepoID
+	for _, r := range newRepos(repos, 2) {
+		got = append(got, r.ID)
+	}
+	if want := []api.RepoID{3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got repos %v, want %v", got, want)
+	}
+}
+
+func TestRepoUsers(t *testing.T) {
+	accts := []*extsvc.ExternalAccount{
+		{UserID: 1, ExternalAccountSpec: extsvc.ExternalAccountSpec{AccountID: "101"}},
+		{UserID: 2, ExternalAccountSpec: extsvc.ExternalAccountSpec{AccountID: "102"}},
+		{UserID: 3, ExternalAccountSpec: extsvc.ExternalAccountSpec{AccountID: "103"}},
+	}
+
+	if got, want := repoUsers(accts, []string{"103", "101", "999"}, false), []int32{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got users %v, want %v", got, want)
+	}
+	if got, want := repoUsers(accts, nil, true), []int32{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got users %v for everyone, want %v", got, want)
+	}
+}
+
+func TestPermsSyncer_limiter(t *testing.T) {
+	var s PermsSyncer
+	l := s.limiter("https://gitlab.mine/", 60)
+	if l != s.limiter("https://gitlab.mine/", 60) {
+		t.Error("got a different limiter for the same provider")
+	}
+	if l == s.limiter("https://github.com/", 60) {
+		t.Error("got the same limiter for different providers")
+	}
+
+	s.limiter("https://gitlab.mine/", 120)
+	if got, want := float64(l.Limit()), 2.0; got != want {
+		t.Errorf("got limit %v per second, want %v", got, want)
+	}
+}
+
+type fakeProvider struct {
+	serviceType, serviceID string
+}
+
+func (p fakeProvider) RepoPerms(ctx context.Context, account *extsvc.ExternalAccount, repos []*types.Repo) ([]authz.RepoPerms, error) {
+	return nil, nil
+}
+
+func (p fakeProvider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (*extsvc.ExternalAccount, error) {
+	return nil, nil
+}
+
+func (p fakeProvider) ServiceType() string { return p.serviceType }
+func (p fakeProvider) ServiceID() string   { return p.serviceID }
+func (p fakeProvider) Validate() []string  { return nil }
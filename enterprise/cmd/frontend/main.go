@@ -59,17 +59,43 @@ func main() {
 		}()
 		go licensing.StartMaxUserCount(&usersStore{})
 
+		permsSyncer := &iauthz.PermsSyncer{
+			Providers: func() []authz.Provider {
+				_, providers := authz.GetProviders()
+				return providers
+			},
+		}
+		go func() {
+			if err := permsSyncer.Run(ctx); err != nil {
+				log15.Error("PermsSyncer.Run", "err", err)
+			}
+		}()
+
+		a8nStore := a8n.NewStore(dbconn.Global)
+		reposStore := repos.NewDBStore(dbconn.Global, sql.TxOptions{})
+		cf := repos.NewHTTPClientFactory()
+
 		syncer := &a8n.ChangesetSyncer{
-			Store:       a8n.NewStore(dbconn.Global),
-			ReposStore:  repos.NewDBStore(dbconn.Global, sql.TxOptions{}),
-			HTTPFactory: repos.NewHTTPClientFactory(),
+			Store:       a8nStore,
+			ReposStore:  reposStore,
+			HTTPFactory: cf,
 		}
 
+		hooks.GitHubWebhook = a8n.NewGitHubWebhook(a8nStore, reposStore, cf)
+		hooks.GitLabWebhook = a8n.NewGitLabWebhook(a8nStore, reposStore, cf)
+
 		go func() {
 			if err := syncer.Run(ctx); err != nil {
 				log15.Error("ChangesetSyncer.Run", "err", err)
 			}
 		}()
+
+		runner := resolvers.NewRunner(a8nStore, cf)
+		go func() {
+			if err := runner.Run(ctx); err != nil {
+				log15.Error("Runner.Run", "err", err)
+			}
+		}()
 	}
 
 	debug, _ := strconv.ParseBool(os.Getenv("DEBUG"))
@@ -195,6 +221,8 @@ func initResolvers() {
 
 type usersStore struct{}
 
+// Count returns the number of users that count toward the license's user limit. Suspended users
+// don't count.
 func (usersStore) Count(ctx context.Context) (int, error) {
-	return db.Users.Count(ctx, nil)
+	return db.Users.Count(ctx, &db.UsersListOptions{ExcludeSuspended: true})
 }
@@ -0,0 +1,313 @@
+package a8n
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/pkg/a8n"
+	"golang.org/x/time/rate"
+	"gopkg.in/inconshreveable/log15.v2"
+)
+
+// changesetActionInterval is the minimum interval between two changesets a
+// ChangesetAction is run on, so that running an action over a big campaign
+// doesn't exhaust the rate limits of the code hosts.
+const changesetActionInterval = time.Second
+
+// changesetChecksPollInterval is how long a merge ChangesetActionJob waits
+// before trying again to merge a Changeset whose checks are still running.
+const changesetChecksPollInterval = time.Minute
+
+// A ChangesetFilter selects a subset of the Changesets of a Campaign. Zero
+// valued fields don't filter.
+type ChangesetFilter struct {
+	IDs         []int64
+	State       a8n.ChangesetState
+	ReviewState a8n.ChangesetReviewState
+}
+
+// Match returns true if the given Changeset matches the filter.
+func (f *ChangesetFilter) Match(c *a8n.Changeset) (bool, error) {
+	if f.State != "" {
+		s, err := c.State()
+		if err != nil {
+			return false, err
+		}
+		if s != f.State {
+			return false, nil
+		}
+	}
+
+	if f.ReviewState != "" {
+		s, err := c.ReviewState()
+		if err != nil {
+			return false, err
+		}
+		if s != f.ReviewState {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// CreateChangesetAction creates the given ChangesetAction and a
+// ChangesetActionJob for every Changeset of its Campaign that matches the
+// given filter. The jobs are run in the background, one Changeset after the
+// other. Merging a Changeset whose checks are still running is retried until
+// they finished.
+func (r *Runner) CreateChangesetAction(ctx context.Context, a *a8n.ChangesetAction, f ChangesetFilter) error {
+	if !a.Action.Valid() {
+		return errors.Errorf("invalid changeset action %q", a.Action)
+	}
+
+	if a.Action == a8n.ChangesetActionComment && a.Body == "" {
+		return errors.New("comment body is empty")
+	}
+
+	cs, jobs, err := r.createChangesetAction(ctx, a, f)
+	if err != nil {
+		return err
+	}
+
+	// The jobs are only run once the transaction that created them has been
+	// committed.
+	go r.runChangesetActionJobs(a.Clone(), cs, jobs)
+
+	return nil
+}
+
+func (r *Runner) createChangesetAction(ctx context.Context, a *a8n.ChangesetAction, f ChangesetFilter) (_ []*a8n.Changeset, _ []*a8n.ChangesetActionJob, err error) {
+	tx, err := r.Store.Transact(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Done(&err)
+
+	var cs []*a8n.Changeset
+	for cursor := int64(-1); cursor != 0; {
+		opts := ListChangesetsOpts{
+			CampaignID: a.CampaignID,
+			IDs:        f.IDs,
+			Cursor:     cursor,
+			Limit:      1000,
+		}
+
+		page, next, err := tx.ListChangesets(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, c := range page {
+			ok, err := f.Match(c)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok {
+				cs = append(cs, c)
+			}
+		}
+
+		cursor = next
+	}
+
+	if len(cs) == 0 {
+		return nil, nil, errors.New("no changesets of the campaign match the filter")
+	}
+
+	if err = tx.CreateChangesetAction(ctx, a); err != nil {
+		return nil, nil, err
+	}
+
+	jobs := make([]*a8n.ChangesetActionJob, 0, len(cs))
+	for _, c := range cs {
+		jobs = append(jobs, &a8n.ChangesetActionJob{
+			ChangesetActionID: a.ID,
+			ChangesetID:       c.ID,
+		})
+	}
+
+	if err = tx.CreateChangesetActionJobs(ctx, jobs...); err != nil {
+		return nil, nil, err
+	}
+
+	return cs, jobs, nil
+}
+
+// resumeChangesetActionJobs runs the given ChangesetActionJobs in the
+// background, grouped by ChangesetAction.
+func (r *Runner) resumeChangesetActionJobs(ctx context.Context, jobs []*a8n.ChangesetActionJob) error {
+	byAction := map[int64][]*a8n.ChangesetActionJob{}
+	for _, j := range jobs {
+		byAction[j.ChangesetActionID] = append(byAction[j.ChangesetActionID], j)
+	}
+
+	for actionID, jobs := range byAction {
+		a, err := r.Store.GetChangesetAction(ctx, GetChangesetActionOpts{ID: actionID})
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int64, 0, len(jobs))
+		for _, j := range jobs {
+			ids = append(ids, j.ChangesetID)
+		}
+
+		byID := make(map[int64]*a8n.Changeset, len(ids))
+		for cursor := int64(-1); cursor != 0; {
+			page, next, err := r.Store.ListChangesets(ctx, ListChangesetsOpts{
+				IDs:    ids,
+				Cursor: cursor,
+				Limit:  1000,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, c := range page {
+				byID[c.ID] = c
+			}
+
+			cursor = next
+		}
+
+		cs := make([]*a8n.Changeset, 0, len(jobs))
+		for _, j := range jobs {
+			c, ok := byID[j.ChangesetID]
+			if !ok {
+				return errors.Errorf("changeset %d of changeset action job %d not found", j.ChangesetID, j.ID)
+			}
+			cs = append(cs, c)
+		}
+
+		go r.runChangesetActionJobs(a, cs, jobs)
+	}
+
+	return nil
+}
+
+func (r *Runner) runChangesetActionJobs(a *a8n.ChangesetAction, cs []*a8n.Changeset, jobs []*a8n.ChangesetActionJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobsTimeout)
+	defer cancel()
+
+	limiter := rate.NewLimiter(rate.Every(changesetActionInterval), 1)
+
+	finish := func(job *a8n.ChangesetActionJob, err error) {
+		if err != nil {
+			job.Error = err.Error()
+		}
+		job.FinishedAt = r.Store.now()
+		if err := r.Store.UpdateChangesetActionJobs(ctx, job); err != nil {
+			log15.Error("Runner.runChangesetActionJobs", "changeset_action_job_id", job.ID, "error", err)
+		}
+	}
+
+	pending := make([]int, 0, len(jobs))
+	for i := range jobs {
+		pending = append(pending, i)
+	}
+
+	for len(pending) > 0 {
+		var retry []int
+		for _, i := range pending {
+			job := jobs[i]
+
+			if err := limiter.Wait(ctx); err != nil {
+				finish(job, err)
+				continue
+			}
+
+			if job.StartedAt.IsZero() {
+				job.StartedAt = r.Store.now()
+			}
+
+			err := r.runChangesetActionJob(ctx, a, cs[i])
+			if errors.Cause(err) != repos.ErrChangesetChecksPending {
+				finish(job, err)
+				continue
+			}
+
+			// The checks of the changeset are still running, so we try to
+			// merge it again later. Updating the job keeps it from being
+			// claimed as stale in the meantime.
+			retry = append(retry, i)
+			if err := r.Store.UpdateChangesetActionJobs(ctx, job); err != nil {
+				log15.Error("Runner.runChangesetActionJobs", "changeset_action_job_id", job.ID, "error", err)
+			}
+		}
+
+		if pending = retry; len(pending) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			for _, i := range pending {
+				finish(jobs[i], errors.Wrap(repos.ErrChangesetChecksPending, "timed out waiting for checks"))
+			}
+			return
+		case <-time.After(changesetChecksPollInterval):
+		}
+	}
+}
+
+// runChangesetActionJob runs the given ChangesetAction on the code host of
+// the given Changeset and syncs the Changeset afterwards, so that its state
+// transitions are recorded.
+func (r *Runner) runChangesetActionJob(ctx context.Context, a *a8n.ChangesetAction, c *a8n.Changeset) error {
+	rs, err := r.ReposStore.ListRepos(ctx, repos.StoreListReposArgs{IDs: []uint32{uint32(c.RepoID)}})
+	if err != nil {
+		return err
+	}
+
+	if len(rs) != 1 {
+		return errors.Errorf("repo %d not found", c.RepoID)
+	}
+	repo := rs[0]
+
+	cs, err := r.changesetSource(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	src, ok := cs.(repos.ChangesetActionSource)
+	if !ok {
+		return repos.ErrChangesetActionNotSupported
+	}
+
+	// Run the action on a copy of the Changeset with freshly loaded metadata,
+	// since some code hosts reject actions on stale versions of a changeset.
+	rc := &repos.Changeset{Changeset: c.Clone(), Repo: repo}
+	if err = cs.LoadChangesets(ctx, rc); err != nil {
+		return err
+	}
+
+	switch a.Action {
+	case a8n.ChangesetActionComment:
+		err = src.CommentOnChangeset(ctx, rc, a.Body)
+	case a8n.ChangesetActionClose:
+		err = src.CloseChangeset(ctx, rc)
+	case a8n.ChangesetActionReopen:
+		err = src.ReopenChangeset(ctx, rc)
+	case a8n.ChangesetActionRequestReview:
+		err = src.RequestChangesetReview(ctx, rc)
+	case a8n.ChangesetActionMerge:
+		err = src.MergeChangeset(ctx, rc)
+	default:
+		err = errors.Errorf("invalid changeset action %q", a.Action)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	syncer := ChangesetSyncer{
+		Store:       r.Store,
+		ReposStore:  r.ReposStore,
+		HTTPFactory: r.HTTPFactory,
+	}
+
+	return syncer.Sync(ctx, c)
+}
@@ -0,0 +1,71 @@
+package a8n
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/a8n"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
+)
+
+func TestChangesetFilterMatch(t *testing.T) {
+	githubChangeset := func(state string, reviews ...string) *a8n.Changeset {
+		pr := &github.PullRequest{State: state}
+		for _, r := range reviews {
+			pr.Reviews = append(pr.Reviews, github.Review{State: r})
+		}
+		return &a8n.Changeset{Metadata: pr}
+	}
+
+	tests := []struct {
+		name      string
+		filter    ChangesetFilter
+		changeset *a8n.Changeset
+		want      bool
+	}{
+		{
+			name:      "empty filter",
+			changeset: githubChangeset("CLOSED"),
+			want:      true,
+		},
+		{
+			name:      "state matches",
+			filter:    ChangesetFilter{State: a8n.ChangesetStateOpen},
+			changeset: githubChangeset("OPEN"),
+			want:      true,
+		},
+		{
+			name:      "state doesn't match",
+			filter:    ChangesetFilter{State: a8n.ChangesetStateOpen},
+			changeset: githubChangeset("MERGED"),
+			want:      false,
+		},
+		{
+			name: "state and review state match",
+			filter: ChangesetFilter{
+				State:       a8n.ChangesetStateOpen,
+				ReviewState: a8n.ChangesetReviewStateApproved,
+			},
+			changeset: githubChangeset("OPEN", "APPROVED"),
+			want:      true,
+		},
+		{
+			name:      "review state doesn't match",
+			filter:    ChangesetFilter{ReviewState: a8n.ChangesetReviewStateApproved},
+			changeset: githubChangeset("OPEN", "APPROVED", "CHANGES_REQUESTED"),
+			want:      false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := tc.filter.Match(tc.changeset)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if have != tc.want {
+				t.Errorf("have %t, want %t", have, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package a8n
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/a8n"
+)
+
+// ChangesetCounts represents the states in which a given set of Changesets was
+// at a given point in time
+type ChangesetCounts struct {
+	Time                 time.Time
+	Total                int32
+	Merged               int32
+	Closed               int32
+	Open                 int32
+	OpenApproved         int32
+	OpenChangesRequested int32
+	OpenPending          int32
+}
+
+// CalcCounts calculates ChangesetCounts for the given Changesets and their
+// ChangesetEvents in the timeframe specified by the start and end
+// parameters. The returned slice has one entry for every day between start
+// and end, with the last entry being end itself. Changesets are only counted
+// from the time they were created on the code host onwards. Merged or closed
+// Changesets without any events changing their state are counted as merged
+// or closed from the time returned by their InitialEvents onwards.
+func CalcCounts(start, end time.Time, cs []*a8n.Changeset, es ...*a8n.ChangesetEvent) ([]*ChangesetCounts, error) {
+	ts := generateTimestamps(start, end)
+	counts := make([]*ChangesetCounts, len(ts))
+	for i, t := range ts {
+		counts[i] = &ChangesetCounts{Time: t}
+	}
+
+	byChangeset := make(map[int64][]*a8n.ChangesetEvent, len(cs))
+	for _, e := range es {
+		byChangeset[e.ChangesetID] = append(byChangeset[e.ChangesetID], e)
+	}
+
+	for _, c := range cs {
+		createdAt, err := c.ExternalCreatedAt()
+		if err != nil {
+			return nil, err
+		}
+
+		events := byChangeset[c.ID]
+		if !hasStateEvents(events) {
+			// Changesets that were already merged or closed when they were
+			// first synced may have no events recording it.
+			for _, e := range c.InitialEvents(c.UpdatedAt) {
+				if e.Kind == a8n.ChangesetEventKindMerged || e.Kind == a8n.ChangesetEventKindClosed {
+					events = append(events, e)
+				}
+			}
+		}
+
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Timestamp.Before(events[j].Timestamp)
+		})
+
+		h := changesetHistory{}
+		next := 0
+
+		for _, c := range counts {
+			if c.Time.Before(createdAt) {
+				continue
+			}
+
+			for ; next < len(events) && !events[next].Timestamp.After(c.Time); next++ {
+				h.apply(events[next])
+			}
+
+			c.Total++
+			switch h.state {
+			case a8n.ChangesetStateMerged:
+				c.Merged++
+			case a8n.ChangesetStateClosed:
+				c.Closed++
+			default:
+				c.Open++
+				switch h.reviewState() {
+				case a8n.ChangesetReviewStateApproved:
+					c.OpenApproved++
+				case a8n.ChangesetReviewStateChangesRequested:
+					c.OpenChangesRequested++
+				default:
+					c.OpenPending++
+				}
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// hasStateEvents returns true if any of the given events changed the state
+// of a Changeset, as opposed to its review state.
+func hasStateEvents(es []*a8n.ChangesetEvent) bool {
+	for _, e := range es {
+		switch e.Kind {
+		case a8n.ChangesetEventKindClosed, a8n.ChangesetEventKindMerged, a8n.ChangesetEventKindReopened:
+			return true
+		}
+	}
+	return false
+}
+
+// changesetHistory tracks the state of a Changeset while its events are
+// applied in chronological order.
+type changesetHistory struct {
+	state a8n.ChangesetState
+	// reviews holds the latest review state by actor. Events without an
+	// actor describe the review state of the Changeset as a whole.
+	reviews map[string]a8n.ChangesetReviewState
+}
+
+func (h *changesetHistory) apply(e *a8n.ChangesetEvent) {
+	switch e.Kind {
+	case a8n.ChangesetEventKindClosed:
+		h.state = a8n.ChangesetStateClosed
+	case a8n.ChangesetEventKindMerged:
+		h.state = a8n.ChangesetStateMerged
+	case a8n.ChangesetEventKindReopened:
+		h.state = a8n.ChangesetStateOpen
+	case a8n.ChangesetEventKindApproved:
+		h.review(e.Actor, a8n.ChangesetReviewStateApproved)
+	case a8n.ChangesetEventKindChangesRequested:
+		h.review(e.Actor, a8n.ChangesetReviewStateChangesRequested)
+	case a8n.ChangesetEventKindReviewDismissed:
+		h.review(e.Actor, a8n.ChangesetReviewStatePending)
+	}
+}
+
+func (h *changesetHistory) review(actor string, s a8n.ChangesetReviewState) {
+	if h.reviews == nil || actor == "" {
+		h.reviews = map[string]a8n.ChangesetReviewState{}
+	}
+	h.reviews[actor] = s
+}
+
+// reviewState returns the overall review state of the Changeset: changes
+// requested by anyone take precedence over approvals.
+func (h *changesetHistory) reviewState() a8n.ChangesetReviewState {
+	state := a8n.ChangesetReviewStatePending
+	for _, s := range h.reviews {
+		switch s {
+		case a8n.ChangesetReviewStateChangesRequested:
+			return s
+		case a8n.ChangesetReviewStateApproved:
+			state = s
+		}
+	}
+	return state
+}
+
+// generateTimestamps returns a timestamp for every day between start and
+// end, walking backwards from end so that end is always included.
+func generateTimestamps(start, end time.Time) []time.Time {
+	var ts []time.Time
+	for t := end; !t.Before(start); t = t.AddDate(0, 0, -1) {
+		ts = append(ts, t)
+	}
+
+	// Reverse so the timestamps are in chronological order.
+	for i, j := 0, len(ts)-1; i < j; i, j = i+1, j-1 {
+		ts[i], ts[j] = ts[j], ts[i]
+	}
+
+	return ts
+}
+
+// ChangesetCheckCounts represents the number of open Changesets in a given
+// set by the overall state of their CI checks.
+type ChangesetCheckCounts struct {
+	Total   int32
+	Passed  int32
+	Failed  int32
+	Pending int32
+	Unknown int32
+}
+
+// CalcCheckCounts calculates the ChangesetCheckCounts of the given
+// Changesets. Closed and merged Changesets are not counted.
+func CalcCheckCounts(cs []*a8n.Changeset) (*ChangesetCheckCounts, error) {
+	counts := &ChangesetCheckCounts{}
+
+	for _, c := range cs {
+		s, err := c.State()
+		if err != nil {
+			return nil, err
+		}
+
+		if s != a8n.ChangesetStateOpen {
+			continue
+		}
+
+		checkState, err := c.CheckState()
+		if err != nil {
+			return nil, err
+		}
+
+		counts.Total++
+		switch checkState {
+		case a8n.ChangesetCheckStatePassed:
+			counts.Passed++
+		case a8n.ChangesetCheckStateFailed:
+			counts.Failed++
+		case a8n.ChangesetCheckStatePending:
+			counts.Pending++
+		default:
+			counts.Unknown++
+		}
+	}
+
+	return counts, nil
+}
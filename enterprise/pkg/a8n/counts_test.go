@@ -0,0 +1,168 @@
+package a8n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/pkg/a8n"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
+)
+
+func TestCalcCounts(t *testing.T) {
+	now := time.Date(2019, 10, 7, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	githubChangeset := func(id int64, createdAt time.Time) *a8n.Changeset {
+		return &a8n.Changeset{
+			ID:       id,
+			Metadata: &github.PullRequest{CreatedAt: createdAt},
+		}
+	}
+
+	event := func(id int64, kind a8n.ChangesetEventKind, actor string, t time.Time) *a8n.ChangesetEvent {
+		return &a8n.ChangesetEvent{ChangesetID: id, Kind: kind, Actor: actor, Timestamp: t}
+	}
+
+	tests := []struct {
+		name       string
+		changesets []*a8n.Changeset
+		start      time.Time
+		end        time.Time
+		events     []*a8n.ChangesetEvent
+		want       []*ChangesetCounts
+	}{
+		{
+			name:       "single changeset open merged",
+			changesets: []*a8n.Changeset{githubChangeset(1, daysAgo(2))},
+			start:      daysAgo(3),
+			end:        now,
+			events: []*a8n.ChangesetEvent{
+				event(1, a8n.ChangesetEventKindMerged, "", daysAgo(1)),
+			},
+			want: []*ChangesetCounts{
+				{Time: daysAgo(3)},
+				{Time: daysAgo(2), Total: 1, Open: 1, OpenPending: 1},
+				{Time: daysAgo(1), Total: 1, Merged: 1},
+				{Time: now, Total: 1, Merged: 1},
+			},
+		},
+		{
+			name: "single changeset merged before it was synced",
+			changesets: []*a8n.Changeset{{
+				ID: 1,
+				Metadata: &github.PullRequest{
+					CreatedAt: daysAgo(3),
+					UpdatedAt: daysAgo(2),
+					State:     string(a8n.ChangesetStateMerged),
+				},
+			}},
+			start: daysAgo(3),
+			end:   now,
+			want: []*ChangesetCounts{
+				{Time: daysAgo(3), Total: 1, Open: 1, OpenPending: 1},
+				{Time: daysAgo(2), Total: 1, Merged: 1},
+				{Time: daysAgo(1), Total: 1, Merged: 1},
+				{Time: now, Total: 1, Merged: 1},
+			},
+		},
+		{
+			name:       "single changeset closed and reopened",
+			changesets: []*a8n.Changeset{githubChangeset(1, daysAgo(3))},
+			start:      daysAgo(3),
+			end:        now,
+			events: []*a8n.ChangesetEvent{
+				event(1, a8n.ChangesetEventKindClosed, "", daysAgo(2)),
+				event(1, a8n.ChangesetEventKindReopened, "", daysAgo(1)),
+			},
+			want: []*ChangesetCounts{
+				{Time: daysAgo(3), Total: 1, Open: 1, OpenPending: 1},
+				{Time: daysAgo(2), Total: 1, Closed: 1},
+				{Time: daysAgo(1), Total: 1, Open: 1, OpenPending: 1},
+				{Time: now, Total: 1, Open: 1, OpenPending: 1},
+			},
+		},
+		{
+			name:       "changes requested take precedence over approvals",
+			changesets: []*a8n.Changeset{githubChangeset(1, daysAgo(3))},
+			start:      daysAgo(3),
+			end:        now,
+			events: []*a8n.ChangesetEvent{
+				event(1, a8n.ChangesetEventKindApproved, "alice", daysAgo(3)),
+				event(1, a8n.ChangesetEventKindChangesRequested, "bob", daysAgo(2)),
+				event(1, a8n.ChangesetEventKindApproved, "bob", daysAgo(1)),
+			},
+			want: []*ChangesetCounts{
+				{Time: daysAgo(3), Total: 1, Open: 1, OpenApproved: 1},
+				{Time: daysAgo(2), Total: 1, Open: 1, OpenChangesRequested: 1},
+				{Time: daysAgo(1), Total: 1, Open: 1, OpenApproved: 1},
+				{Time: now, Total: 1, Open: 1, OpenApproved: 1},
+			},
+		},
+		{
+			name: "multiple changesets",
+			changesets: []*a8n.Changeset{
+				githubChangeset(1, daysAgo(2)),
+				githubChangeset(2, daysAgo(1)),
+			},
+			start: daysAgo(2),
+			end:   now,
+			events: []*a8n.ChangesetEvent{
+				event(2, a8n.ChangesetEventKindClosed, "", now),
+				event(1, a8n.ChangesetEventKindReviewDismissed, "alice", daysAgo(1)),
+				event(1, a8n.ChangesetEventKindApproved, "alice", daysAgo(2)),
+			},
+			want: []*ChangesetCounts{
+				{Time: daysAgo(2), Total: 1, Open: 1, OpenApproved: 1},
+				{Time: daysAgo(1), Total: 2, Open: 2, OpenPending: 2},
+				{Time: now, Total: 2, Open: 1, OpenPending: 1, Closed: 1},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := CalcCounts(tc.start, tc.end, tc.changesets, tc.events...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(have, tc.want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestCalcCheckCounts(t *testing.T) {
+	githubChangeset := func(state, checkConclusion string) *a8n.Changeset {
+		pr := &github.PullRequest{State: state}
+		if checkConclusion != "" {
+			pr.HeadCommit = &github.Commit{
+				CheckSuites: []github.CheckSuite{{
+					CheckRuns: []github.CheckRun{{
+						Status:     github.CheckStatusCompleted,
+						Conclusion: checkConclusion,
+					}},
+				}},
+			}
+		}
+		return &a8n.Changeset{Metadata: pr}
+	}
+
+	have, err := CalcCheckCounts([]*a8n.Changeset{
+		githubChangeset("OPEN", github.CheckConclusionSuccess),
+		githubChangeset("OPEN", github.CheckConclusionFailure),
+		githubChangeset("OPEN", github.CheckConclusionTimedOut),
+		githubChangeset("OPEN", ""),
+		githubChangeset("MERGED", github.CheckConclusionFailure),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &ChangesetCheckCounts{Total: 4, Passed: 1, Failed: 2, Unknown: 1}
+	if diff := cmp.Diff(have, want); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,243 @@
+package resolvers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	ee "github.com/sourcegraph/sourcegraph/enterprise/pkg/a8n"
+	"github.com/sourcegraph/sourcegraph/pkg/a8n"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/httpcli"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+)
+
+// runner returns an ee.Runner that uses the Resolver's store and
+// httpFactory.
+func (r *Resolver) runner() *ee.Runner {
+	return NewRunner(r.store, r.httpFactory)
+}
+
+// NewRunner returns an ee.Runner that uses the given store and HTTP client
+// factory and computes diffs with the replacer service.
+func NewRunner(store *ee.Store, cf *httpcli.Factory) *ee.Runner {
+	return &ee.Runner{
+		Store:         store,
+		ReposStore:    repos.NewDBStore(store.DB(), sql.TxOptions{}),
+		HTTPFactory:   cf,
+		ResolveRepos:  graphqlbackend.ResolveRepositoriesForQuery,
+		DefaultBranch: defaultBranch,
+		Diff: func(ctx context.Context, repo api.RepoName, commit api.CommitID, spec a8n.CampaignPlanSpec) (string, error) {
+			return graphqlbackend.CodemodDiff(ctx, repo, commit, protocol.RewriteSpecification{
+				MatchTemplate:    spec.MatchTemplate,
+				RewriteTemplate:  spec.RewriteTemplate,
+				FileExtension:    spec.FileExtension,
+				DirectoryExclude: spec.DirectoryExclude,
+			})
+		},
+	}
+}
+
+// defaultBranch returns the fully qualified name of the default branch of
+// the given repo and the commit it points to.
+func defaultBranch(ctx context.Context, repo *types.Repo) (string, api.CommitID, error) {
+	cachedRepo, err := backend.CachedGitRepo(ctx, repo)
+	if err != nil {
+		return "", "", err
+	}
+
+	refBytes, _, exitCode, err := git.ExecSafe(ctx, *cachedRepo, []string{"symbolic-ref", "HEAD"})
+	if err != nil {
+		return "", "", err
+	}
+
+	if exitCode != 0 {
+		return "", "", errors.Errorf("resolving HEAD of %s failed with exit code %d", repo.Name, exitCode)
+	}
+
+	commit, err := git.ResolveRevision(ctx, *cachedRepo, nil, "HEAD", &git.ResolveRevisionOptions{NoEnsureRevision: true})
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(bytes.TrimSpace(refBytes)), commit, nil
+}
+
+func (r *Resolver) PreviewCampaignPlan(ctx context.Context, args graphqlbackend.PreviewCampaignPlanArgs) (graphqlbackend.CampaignPlanResolver, error) {
+	// 🚨 SECURITY: Only site admins may create campaign plans for now.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	spec := a8n.CampaignPlanSpec{
+		ScopeQuery:      args.Specification.ScopeQuery,
+		MatchTemplate:   args.Specification.MatchTemplate,
+		RewriteTemplate: args.Specification.RewriteTemplate,
+	}
+
+	if args.Specification.FileExtension != nil {
+		spec.FileExtension = *args.Specification.FileExtension
+	}
+
+	if args.Specification.DirectoryExclude != nil {
+		spec.DirectoryExclude = *args.Specification.DirectoryExclude
+	}
+
+	plan, err := r.runner().CreateCampaignPlan(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &campaignPlanResolver{store: r.store, campaignPlan: plan}, nil
+}
+
+func (r *Resolver) CampaignPlanByID(ctx context.Context, id graphql.ID) (graphqlbackend.CampaignPlanResolver, error) {
+	// 🚨 SECURITY: Only site admins may access campaign plans for now.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	planID, err := unmarshalCampaignPlanID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := r.store.GetCampaignPlan(ctx, ee.GetCampaignPlanOpts{ID: planID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &campaignPlanResolver{store: r.store, campaignPlan: plan}, nil
+}
+
+type campaignPlanResolver struct {
+	store        *ee.Store
+	campaignPlan *a8n.CampaignPlan
+}
+
+const campaignPlanIDKind = "CampaignPlan"
+
+func marshalCampaignPlanID(id int64) graphql.ID {
+	return relay.MarshalID(campaignPlanIDKind, id)
+}
+
+func unmarshalCampaignPlanID(id graphql.ID) (campaignPlanID int64, err error) {
+	err = relay.UnmarshalSpec(id, &campaignPlanID)
+	return
+}
+
+func (r *campaignPlanResolver) ID() graphql.ID {
+	return marshalCampaignPlanID(r.campaignPlan.ID)
+}
+
+func (r *campaignPlanResolver) Status(ctx context.Context) (graphqlbackend.BackgroundProcessStatus, error) {
+	status, err := r.store.GetCampaignPlanStatus(ctx, r.campaignPlan.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &backgroundProcessStatusResolver{status}, nil
+}
+
+func (r *campaignPlanResolver) Changesets(ctx context.Context, args *graphqlutil.ConnectionArgs) graphqlbackend.ChangesetPlansConnectionResolver {
+	return &changesetPlansConnectionResolver{
+		store: r.store,
+		opts: ee.ListCampaignJobsOpts{
+			CampaignPlanID: r.campaignPlan.ID,
+			Limit:          int(args.GetFirst()),
+			OnlyWithDiff:   true,
+		},
+	}
+}
+
+type changesetPlansConnectionResolver struct {
+	store *ee.Store
+	opts  ee.ListCampaignJobsOpts
+
+	// cache results because they are used by multiple fields
+	once sync.Once
+	jobs []*a8n.CampaignJob
+	next int64
+	err  error
+}
+
+func (r *changesetPlansConnectionResolver) Nodes(ctx context.Context) ([]graphqlbackend.ChangesetPlanResolver, error) {
+	jobs, _, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]graphqlbackend.ChangesetPlanResolver, 0, len(jobs))
+	for _, j := range jobs {
+		resolvers = append(resolvers, &changesetPlanResolver{job: j})
+	}
+	return resolvers, nil
+}
+
+func (r *changesetPlansConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	opts := ee.CountCampaignJobsOpts{
+		CampaignPlanID: r.opts.CampaignPlanID,
+		OnlyWithDiff:   r.opts.OnlyWithDiff,
+	}
+	count, err := r.store.CountCampaignJobs(ctx, opts)
+	return int32(count), err
+}
+
+func (r *changesetPlansConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	_, next, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(next != 0), nil
+}
+
+func (r *changesetPlansConnectionResolver) compute(ctx context.Context) ([]*a8n.CampaignJob, int64, error) {
+	r.once.Do(func() {
+		r.jobs, r.next, r.err = r.store.ListCampaignJobs(ctx, r.opts)
+	})
+	return r.jobs, r.next, r.err
+}
+
+type changesetPlanResolver struct {
+	job *a8n.CampaignJob
+}
+
+func (r *changesetPlanResolver) Repository(ctx context.Context) (*graphqlbackend.RepositoryResolver, error) {
+	return graphqlbackend.RepositoryByIDInt32(ctx, api.RepoID(r.job.RepoID))
+}
+
+func (r *changesetPlanResolver) BaseRef() string {
+	return r.job.BaseRef
+}
+
+func (r *changesetPlanResolver) Diff() string {
+	return r.job.Diff
+}
+
+type backgroundProcessStatusResolver struct {
+	status *a8n.BackgroundProcessStatus
+}
+
+func (r *backgroundProcessStatusResolver) CompletedCount() int32 {
+	return r.status.Completed
+}
+
+func (r *backgroundProcessStatusResolver) PendingCount() int32 {
+	return r.status.Pending
+}
+
+func (r *backgroundProcessStatusResolver) State() a8n.BackgroundProcessState {
+	return r.status.State()
+}
+
+func (r *backgroundProcessStatusResolver) Errors() []string {
+	return r.status.Errors
+}
@@ -0,0 +1,286 @@
+package resolvers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	ee "github.com/sourcegraph/sourcegraph/enterprise/pkg/a8n"
+	"github.com/sourcegraph/sourcegraph/pkg/a8n"
+)
+
+func (r *Resolver) RunChangesetAction(ctx context.Context, args *graphqlbackend.RunChangesetActionArgs) (graphqlbackend.ChangesetActionResolver, error) {
+	user, err := db.Users.GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%v", backend.ErrNotAuthenticated)
+	}
+
+	// 🚨 SECURITY: Only site admins may run changeset actions for now.
+	if !user.SiteAdmin {
+		return nil, backend.ErrMustBeSiteAdmin
+	}
+
+	campaignID, err := unmarshalCampaignID(args.Campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	action := &a8n.ChangesetAction{
+		CampaignID: campaignID,
+		AuthorID:   user.ID,
+		Action:     args.Input.Action,
+	}
+
+	if args.Input.Body != nil {
+		action.Body = *args.Input.Body
+	}
+
+	var filter ee.ChangesetFilter
+	if args.Input.Changesets != nil {
+		for _, id := range *args.Input.Changesets {
+			changesetID, err := unmarshalChangesetID(id)
+			if err != nil {
+				return nil, err
+			}
+			filter.IDs = append(filter.IDs, changesetID)
+		}
+
+		if len(filter.IDs) == 0 {
+			return nil, errors.New("no changesets given")
+		}
+	}
+
+	if args.Input.State != nil {
+		filter.State = *args.Input.State
+	}
+
+	if args.Input.ReviewState != nil {
+		filter.ReviewState = *args.Input.ReviewState
+	}
+
+	if err = r.runner().CreateChangesetAction(ctx, action, filter); err != nil {
+		return nil, err
+	}
+
+	return &changesetActionResolver{store: r.store, ChangesetAction: action}, nil
+}
+
+func (r *Resolver) ChangesetActionByID(ctx context.Context, id graphql.ID) (graphqlbackend.ChangesetActionResolver, error) {
+	// 🚨 SECURITY: Only site admins may access changeset actions for now.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	actionID, err := unmarshalChangesetActionID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := r.store.GetChangesetAction(ctx, ee.GetChangesetActionOpts{ID: actionID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &changesetActionResolver{store: r.store, ChangesetAction: action}, nil
+}
+
+type changesetActionsConnectionResolver struct {
+	store *ee.Store
+	opts  ee.ListChangesetActionsOpts
+
+	// cache results because they are used by multiple fields
+	once    sync.Once
+	actions []*a8n.ChangesetAction
+	next    int64
+	err     error
+}
+
+func (r *changesetActionsConnectionResolver) Nodes(ctx context.Context) ([]graphqlbackend.ChangesetActionResolver, error) {
+	actions, _, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]graphqlbackend.ChangesetActionResolver, 0, len(actions))
+	for _, a := range actions {
+		resolvers = append(resolvers, &changesetActionResolver{store: r.store, ChangesetAction: a})
+	}
+	return resolvers, nil
+}
+
+func (r *changesetActionsConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	opts := ee.CountChangesetActionsOpts{CampaignID: r.opts.CampaignID}
+	count, err := r.store.CountChangesetActions(ctx, opts)
+	return int32(count), err
+}
+
+func (r *changesetActionsConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	_, next, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(next != 0), nil
+}
+
+func (r *changesetActionsConnectionResolver) compute(ctx context.Context) ([]*a8n.ChangesetAction, int64, error) {
+	r.once.Do(func() {
+		r.actions, r.next, r.err = r.store.ListChangesetActions(ctx, r.opts)
+	})
+	return r.actions, r.next, r.err
+}
+
+type changesetActionResolver struct {
+	store *ee.Store
+	*a8n.ChangesetAction
+}
+
+const changesetActionIDKind = "ChangesetAction"
+
+func marshalChangesetActionID(id int64) graphql.ID {
+	return relay.MarshalID(changesetActionIDKind, id)
+}
+
+func unmarshalChangesetActionID(id graphql.ID) (changesetActionID int64, err error) {
+	err = relay.UnmarshalSpec(id, &changesetActionID)
+	return
+}
+
+func (r *changesetActionResolver) ID() graphql.ID {
+	return marshalChangesetActionID(r.ChangesetAction.ID)
+}
+
+func (r *changesetActionResolver) Action() a8n.ChangesetActionType {
+	return r.ChangesetAction.Action
+}
+
+func (r *changesetActionResolver) Body() string {
+	return r.ChangesetAction.Body
+}
+
+func (r *changesetActionResolver) Campaign(ctx context.Context) (graphqlbackend.CampaignResolver, error) {
+	campaign, err := r.store.GetCampaign(ctx, ee.GetCampaignOpts{ID: r.CampaignID})
+	if err != nil {
+		return nil, err
+	}
+	return &campaignResolver{store: r.store, Campaign: campaign}, nil
+}
+
+func (r *changesetActionResolver) Author(ctx context.Context) (*graphqlbackend.UserResolver, error) {
+	return graphqlbackend.UserByIDInt32(ctx, r.AuthorID)
+}
+
+func (r *changesetActionResolver) Status(ctx context.Context) (graphqlbackend.BackgroundProcessStatus, error) {
+	status, err := r.store.GetChangesetActionStatus(ctx, r.ChangesetAction.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &backgroundProcessStatusResolver{status}, nil
+}
+
+func (r *changesetActionResolver) Results(ctx context.Context, args *graphqlutil.ConnectionArgs) graphqlbackend.ChangesetActionResultsConnectionResolver {
+	return &changesetActionResultsConnectionResolver{
+		store: r.store,
+		opts: ee.ListChangesetActionJobsOpts{
+			ChangesetActionID: r.ChangesetAction.ID,
+			Limit:             int(args.GetFirst()),
+		},
+	}
+}
+
+func (r *changesetActionResolver) CreatedAt() graphqlbackend.DateTime {
+	return graphqlbackend.DateTime{Time: r.ChangesetAction.CreatedAt}
+}
+
+type changesetActionResultsConnectionResolver struct {
+	store *ee.Store
+	opts  ee.ListChangesetActionJobsOpts
+
+	// cache results because they are used by multiple fields
+	once sync.Once
+	jobs []*a8n.ChangesetActionJob
+	next int64
+	err  error
+}
+
+func (r *changesetActionResultsConnectionResolver) Nodes(ctx context.Context) ([]graphqlbackend.ChangesetActionResultResolver, error) {
+	jobs, _, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]graphqlbackend.ChangesetActionResultResolver, 0, len(jobs))
+	for _, j := range jobs {
+		resolvers = append(resolvers, &changesetActionResultResolver{store: r.store, job: j})
+	}
+	return resolvers, nil
+}
+
+func (r *changesetActionResultsConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	opts := ee.CountChangesetActionJobsOpts{ChangesetActionID: r.opts.ChangesetActionID}
+	count, err := r.store.CountChangesetActionJobs(ctx, opts)
+	return int32(count), err
+}
+
+func (r *changesetActionResultsConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	_, next, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(next != 0), nil
+}
+
+func (r *changesetActionResultsConnectionResolver) compute(ctx context.Context) ([]*a8n.ChangesetActionJob, int64, error) {
+	r.once.Do(func() {
+		r.jobs, r.next, r.err = r.store.ListChangesetActionJobs(ctx, r.opts)
+	})
+	return r.jobs, r.next, r.err
+}
+
+type changesetActionResultResolver struct {
+	store *ee.Store
+	job   *a8n.ChangesetActionJob
+}
+
+func (r *changesetActionResultResolver) Changeset(ctx context.Context) (graphqlbackend.ChangesetResolver, error) {
+	changeset, err := r.store.GetChangeset(ctx, ee.GetChangesetOpts{ID: r.job.ChangesetID})
+	if err != nil {
+		return nil, err
+	}
+	return &changesetResolver{store: r.store, Changeset: changeset}, nil
+}
+
+func (r *changesetActionResultResolver) State() a8n.BackgroundProcessState {
+	switch {
+	case r.job.FinishedAt.IsZero():
+		return a8n.BackgroundProcessStateProcessing
+	case r.job.Error != "":
+		return a8n.BackgroundProcessStateErrored
+	default:
+		return a8n.BackgroundProcessStateCompleted
+	}
+}
+
+func (r *changesetActionResultResolver) Error() *string {
+	if r.job.Error == "" {
+		return nil
+	}
+	return &r.job.Error
+}
+
+func (r *changesetActionResultResolver) StartedAt() *graphqlbackend.DateTime {
+	if r.job.StartedAt.IsZero() {
+		return nil
+	}
+	return &graphqlbackend.DateTime{Time: r.job.StartedAt}
+}
+
+func (r *changesetActionResultResolver) FinishedAt() *graphqlbackend.DateTime {
+	if r.job.FinishedAt.IsZero() {
+		return nil
+	}
+	return &graphqlbackend.DateTime{Time: r.job.FinishedAt}
+}
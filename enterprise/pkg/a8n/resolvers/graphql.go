@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"path"
 	"sync"
+	"time"
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
@@ -152,6 +153,12 @@ func (r *Resolver) CreateCampaign(ctx context.Context, args *graphqlbackend.Crea
 		AuthorID:    user.ID,
 	}
 
+	if args.Input.Plan != nil {
+		if campaign.CampaignPlanID, err = unmarshalCampaignPlanID(*args.Input.Plan); err != nil {
+			return nil, err
+		}
+	}
+
 	switch relay.UnmarshalKind(args.Input.Namespace) {
 	case "User":
 		relay.UnmarshalSpec(args.Input.Namespace, &campaign.NamespaceUserID)
@@ -161,7 +168,7 @@ func (r *Resolver) CreateCampaign(ctx context.Context, args *graphqlbackend.Crea
 		return nil, errors.Errorf("Invalid namespace %q", args.Input.Namespace)
 	}
 
-	if err := r.store.CreateCampaign(ctx, campaign); err != nil {
+	if err := r.runner().CreateCampaign(ctx, campaign); err != nil {
 		return nil, err
 	}
 
@@ -354,6 +361,133 @@ func (r *campaignResolver) Changesets(ctx context.Context, args struct {
 	}
 }
 
+func (r *campaignResolver) Plan(ctx context.Context) (graphqlbackend.CampaignPlanResolver, error) {
+	if r.Campaign.CampaignPlanID == 0 {
+		return nil, nil
+	}
+
+	plan, err := r.store.GetCampaignPlan(ctx, ee.GetCampaignPlanOpts{ID: r.Campaign.CampaignPlanID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &campaignPlanResolver{store: r.store, campaignPlan: plan}, nil
+}
+
+func (r *campaignResolver) ChangesetCreationStatus(ctx context.Context) (graphqlbackend.BackgroundProcessStatus, error) {
+	status, err := r.store.GetCampaignStatus(ctx, r.Campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &backgroundProcessStatusResolver{status}, nil
+}
+
+func (r *campaignResolver) ChangesetActions(ctx context.Context, args *graphqlutil.ConnectionArgs) graphqlbackend.ChangesetActionsConnectionResolver {
+	return &changesetActionsConnectionResolver{
+		store: r.store,
+		opts: ee.ListChangesetActionsOpts{
+			CampaignID: r.Campaign.ID,
+			Limit:      int(args.GetFirst()),
+		},
+	}
+}
+
+func (r *campaignResolver) ChangesetCheckCounts(ctx context.Context) (graphqlbackend.ChangesetCheckCountsResolver, error) {
+	var cs []*a8n.Changeset
+	for cursor := int64(-1); cursor != 0; {
+		opts := ee.ListChangesetsOpts{
+			CampaignID: r.Campaign.ID,
+			Cursor:     cursor,
+			Limit:      1000,
+		}
+
+		page, next, err := r.store.ListChangesets(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		cs, cursor = append(cs, page...), next
+	}
+
+	counts, err := ee.CalcCheckCounts(cs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &changesetCheckCountsResolver{counts: counts}, nil
+}
+
+type changesetCheckCountsResolver struct {
+	counts *ee.ChangesetCheckCounts
+}
+
+func (r *changesetCheckCountsResolver) Total() int32   { return r.counts.Total }
+func (r *changesetCheckCountsResolver) Passed() int32  { return r.counts.Passed }
+func (r *changesetCheckCountsResolver) Failed() int32  { return r.counts.Failed }
+func (r *changesetCheckCountsResolver) Pending() int32 { return r.counts.Pending }
+func (r *changesetCheckCountsResolver) Unknown() int32 { return r.counts.Unknown }
+
+func (r *campaignResolver) ChangesetCountsOverTime(
+	ctx context.Context,
+	args *graphqlbackend.ChangesetCountsArgs,
+) ([]graphqlbackend.ChangesetCountsResolver, error) {
+	start := r.Campaign.CreatedAt.UTC()
+	if args.From != nil {
+		start = args.From.Time.UTC()
+	}
+
+	end := time.Now().UTC()
+	if args.To != nil && args.To.Time.Before(end) {
+		end = args.To.Time.UTC()
+	}
+
+	var cs []*a8n.Changeset
+	for cursor := int64(-1); cursor != 0; {
+		opts := ee.ListChangesetsOpts{CampaignID: r.Campaign.ID, Cursor: cursor, Limit: 1000}
+		page, next, err := r.store.ListChangesets(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		cs, cursor = append(cs, page...), next
+	}
+
+	var es []*a8n.ChangesetEvent
+	for cursor := int64(-1); cursor != 0; {
+		opts := ee.ListChangesetEventsOpts{CampaignID: r.Campaign.ID, Cursor: cursor, Limit: 1000}
+		page, next, err := r.store.ListChangesetEvents(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		es, cursor = append(es, page...), next
+	}
+
+	counts, err := ee.CalcCounts(start, end, cs, es...)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]graphqlbackend.ChangesetCountsResolver, 0, len(counts))
+	for _, c := range counts {
+		resolvers = append(resolvers, &changesetCountsResolver{counts: c})
+	}
+
+	return resolvers, nil
+}
+
+type changesetCountsResolver struct {
+	counts *ee.ChangesetCounts
+}
+
+func (r *changesetCountsResolver) Date() graphqlbackend.DateTime {
+	return graphqlbackend.DateTime{Time: r.counts.Time}
+}
+func (r *changesetCountsResolver) Total() int32                { return r.counts.Total }
+func (r *changesetCountsResolver) Merged() int32               { return r.counts.Merged }
+func (r *changesetCountsResolver) Closed() int32               { return r.counts.Closed }
+func (r *changesetCountsResolver) Open() int32                 { return r.counts.Open }
+func (r *changesetCountsResolver) OpenApproved() int32         { return r.counts.OpenApproved }
+func (r *changesetCountsResolver) OpenChangesRequested() int32 { return r.counts.OpenChangesRequested }
+func (r *changesetCountsResolver) OpenPending() int32          { return r.counts.OpenPending }
+
 func (r *Resolver) CreateChangesets(ctx context.Context, args *graphqlbackend.CreateChangesetsArgs) (_ []graphqlbackend.ChangesetResolver, err error) {
 	// 🚨 SECURITY: Only site admins may create changesets for now
 	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
@@ -581,6 +715,44 @@ func (r *changesetResolver) ReviewState() (a8n.ChangesetReviewState, error) {
 	return r.Changeset.ReviewState()
 }
 
+func (r *changesetResolver) CheckState() (a8n.ChangesetCheckState, error) {
+	return r.Changeset.CheckState()
+}
+
+func (r *changesetResolver) Checks() ([]graphqlbackend.ChangesetCheckResolver, error) {
+	checks, err := r.Changeset.Checks()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]graphqlbackend.ChangesetCheckResolver, 0, len(checks))
+	for _, c := range checks {
+		resolvers = append(resolvers, &changesetCheckResolver{check: c})
+	}
+	return resolvers, nil
+}
+
+type changesetCheckResolver struct {
+	check *a8n.ChangesetCheck
+}
+
+func (r *changesetCheckResolver) Name() string                   { return r.check.Name }
+func (r *changesetCheckResolver) State() a8n.ChangesetCheckState { return r.check.State }
+
+func (r *changesetCheckResolver) Description() *string {
+	if r.check.Description == "" {
+		return nil
+	}
+	return &r.check.Description
+}
+
+func (r *changesetCheckResolver) ExternalURL() *string {
+	if r.check.URL == "" {
+		return nil
+	}
+	return &r.check.URL
+}
+
 func marshalRepositoryID(repo api.RepoID) graphql.ID { return relay.MarshalID("Repository", repo) }
 func unmarshalRepositoryID(id graphql.ID) (repo api.RepoID, err error) {
 	err = relay.UnmarshalSpec(id, &repo)
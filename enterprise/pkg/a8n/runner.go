@@ -0,0 +1,542 @@
+package a8n
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/pkg/a8n"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/httpcli"
+	"gopkg.in/inconshreveable/log15.v2"
+)
+
+// maxConcurrentJobs is the maximum number of CampaignJobs or ChangesetJobs
+// a Runner executes concurrently for a single CampaignPlan or Campaign.
+const maxConcurrentJobs = 8
+
+// jobsTimeout is the maximum amount of time a Runner spends executing all
+// the jobs of a single CampaignPlan or Campaign in the background.
+const jobsTimeout = 2 * time.Hour
+
+// A Runner creates CampaignPlans and runs their CampaignJobs in the
+// background. When a Campaign is created from a CampaignPlan, it also runs
+// the ChangesetJobs that turn the diffs of the plan into Changesets on the
+// code hosts, which are then kept up to date by the ChangesetSyncer. The
+// state of the jobs is kept in the Store, and Run resumes the jobs that were
+// interrupted.
+type Runner struct {
+	Store       *Store
+	ReposStore  repos.Store
+	HTTPFactory *httpcli.Factory
+
+	// ResolveRepos returns the repositories matched by the repository
+	// filters of the given search query.
+	ResolveRepos func(ctx context.Context, query string) ([]*types.Repo, error)
+	// DefaultBranch returns the fully qualified name of the default branch
+	// of the given repository and the commit it points to.
+	DefaultBranch func(ctx context.Context, repo *types.Repo) (ref string, commit api.CommitID, err error)
+	// Diff runs the rewrite of a CampaignPlanSpec over a commit of the given
+	// repository, returning the resulting unified diff.
+	Diff func(ctx context.Context, repo api.RepoName, commit api.CommitID, spec a8n.CampaignPlanSpec) (string, error)
+}
+
+// CreateCampaignPlan creates a CampaignPlan with the given spec and a
+// CampaignJob for every repository matched by its scope query. The jobs
+// are run in the background.
+func (r *Runner) CreateCampaignPlan(ctx context.Context, spec a8n.CampaignPlanSpec) (_ *a8n.CampaignPlan, err error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	rs, err := r.ResolveRepos(ctx, spec.ScopeQuery)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving repositories of scope query")
+	}
+
+	if len(rs) == 0 {
+		return nil, errors.Errorf("scope query %q matches no repositories", spec.ScopeQuery)
+	}
+
+	plan, jobs, err := r.createCampaignPlan(ctx, spec, rs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The jobs are only run once the transaction that created them has been
+	// committed.
+	go r.runCampaignJobs(plan, rs, jobs)
+
+	return plan, nil
+}
+
+func (r *Runner) createCampaignPlan(ctx context.Context, spec a8n.CampaignPlanSpec, rs []*types.Repo) (_ *a8n.CampaignPlan, _ []*a8n.CampaignJob, err error) {
+	tx, err := r.Store.Transact(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Done(&err)
+
+	plan := &a8n.CampaignPlan{Spec: spec}
+	if err = tx.CreateCampaignPlan(ctx, plan); err != nil {
+		return nil, nil, err
+	}
+
+	jobs := make([]*a8n.CampaignJob, 0, len(rs))
+	for _, repo := range rs {
+		jobs = append(jobs, &a8n.CampaignJob{
+			CampaignPlanID: plan.ID,
+			RepoID:         int32(repo.ID),
+		})
+	}
+
+	if err = tx.CreateCampaignJobs(ctx, jobs...); err != nil {
+		return nil, nil, err
+	}
+
+	return plan, jobs, nil
+}
+
+func (r *Runner) runCampaignJobs(plan *a8n.CampaignPlan, rs []*types.Repo, jobs []*a8n.CampaignJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobsTimeout)
+	defer cancel()
+
+	run(len(jobs), func(i int) {
+		job := jobs[i]
+
+		job.StartedAt = r.Store.now()
+		if err := r.Store.UpdateCampaignJobs(ctx, job); err != nil {
+			log15.Error("Runner.runCampaignJobs", "campaign_job_id", job.ID, "error", err)
+			return
+		}
+
+		if err := r.runCampaignJob(ctx, plan, rs[i], job); err != nil {
+			job.Error = err.Error()
+		}
+
+		job.FinishedAt = r.Store.now()
+		if err := r.Store.UpdateCampaignJobs(ctx, job); err != nil {
+			log15.Error("Runner.runCampaignJobs", "campaign_job_id", job.ID, "error", err)
+		}
+	})
+}
+
+func (r *Runner) runCampaignJob(ctx context.Context, plan *a8n.CampaignPlan, repo *types.Repo, job *a8n.CampaignJob) (err error) {
+	job.BaseRef, job.Rev, err = r.DefaultBranch(ctx, repo)
+	if err != nil {
+		return errors.Wrapf(err, "resolving default branch of %s", repo.Name)
+	}
+
+	job.Diff, err = r.Diff(ctx, repo.Name, job.Rev, plan.Spec)
+	if err != nil {
+		return errors.Wrapf(err, "computing diff of %s", repo.Name)
+	}
+
+	return nil
+}
+
+// CreateCampaign creates the given Campaign. If the Campaign has a
+// CampaignPlan, a ChangesetJob is created for every CampaignJob of the plan
+// that produced a diff. The ChangesetJobs are run in the background, adding
+// the resulting Changesets to the Campaign as they're created.
+func (r *Runner) CreateCampaign(ctx context.Context, c *a8n.Campaign) error {
+	campaignJobs, jobs, err := r.createCampaign(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	// The jobs are only run once the transaction that created them has been
+	// committed.
+	if len(jobs) > 0 {
+		go r.runChangesetJobs(c.Clone(), campaignJobs, jobs)
+	}
+
+	return nil
+}
+
+func (r *Runner) createCampaign(ctx context.Context, c *a8n.Campaign) (_ []*a8n.CampaignJob, _ []*a8n.ChangesetJob, err error) {
+	tx, err := r.Store.Transact(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Done(&err)
+
+	if c.CampaignPlanID == 0 {
+		return nil, nil, tx.CreateCampaign(ctx, c)
+	}
+
+	status, err := tx.GetCampaignPlanStatus(ctx, c.CampaignPlanID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if status.Total == 0 {
+		return nil, nil, errors.Errorf("campaign plan %d not found", c.CampaignPlanID)
+	}
+
+	if status.Pending > 0 {
+		return nil, nil, errors.Errorf("campaign plan %d is still being processed", c.CampaignPlanID)
+	}
+
+	campaignJobs, err := listAllCampaignJobs(ctx, tx, ListCampaignJobsOpts{
+		CampaignPlanID: c.CampaignPlanID,
+		OnlyWithDiff:   true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = tx.CreateCampaign(ctx, c); err != nil {
+		return nil, nil, err
+	}
+
+	if len(campaignJobs) == 0 {
+		return nil, nil, nil
+	}
+
+	jobs := make([]*a8n.ChangesetJob, 0, len(campaignJobs))
+	for _, cj := range campaignJobs {
+		jobs = append(jobs, &a8n.ChangesetJob{
+			CampaignID:    c.ID,
+			CampaignJobID: cj.ID,
+		})
+	}
+
+	if err = tx.CreateChangesetJobs(ctx, jobs...); err != nil {
+		return nil, nil, err
+	}
+
+	return campaignJobs, jobs, nil
+}
+
+// listAllCampaignJobs pages through all the CampaignJobs matching opts.
+func listAllCampaignJobs(ctx context.Context, s *Store, opts ListCampaignJobsOpts) (all []*a8n.CampaignJob, err error) {
+	opts.Cursor, opts.Limit = -1, 1000
+	for opts.Cursor != 0 {
+		var js []*a8n.CampaignJob
+		if js, opts.Cursor, err = s.ListCampaignJobs(ctx, opts); err != nil {
+			return nil, err
+		}
+		all = append(all, js...)
+	}
+	return all, nil
+}
+
+func (r *Runner) runChangesetJobs(c *a8n.Campaign, campaignJobs []*a8n.CampaignJob, jobs []*a8n.ChangesetJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobsTimeout)
+	defer cancel()
+
+	run(len(jobs), func(i int) {
+		job := jobs[i]
+
+		job.StartedAt = r.Store.now()
+		if err := r.Store.UpdateChangesetJobs(ctx, job); err != nil {
+			log15.Error("Runner.runChangesetJobs", "changeset_job_id", job.ID, "error", err)
+			return
+		}
+
+		if err := r.runChangesetJob(ctx, c, campaignJobs[i], job); err != nil {
+			job.Error = err.Error()
+			job.FinishedAt = r.Store.now()
+			if err := r.Store.UpdateChangesetJobs(ctx, job); err != nil {
+				log15.Error("Runner.runChangesetJobs", "changeset_job_id", job.ID, "error", err)
+			}
+		}
+	})
+}
+
+// runChangesetJob pushes the diff of the given CampaignJob to a new branch
+// on the code host, opens a Changeset for it and adds it to the Campaign.
+func (r *Runner) runChangesetJob(ctx context.Context, c *a8n.Campaign, cj *a8n.CampaignJob, job *a8n.ChangesetJob) (err error) {
+	rs, err := r.ReposStore.ListRepos(ctx, repos.StoreListReposArgs{IDs: []uint32{uint32(cj.RepoID)}})
+	if err != nil {
+		return err
+	}
+
+	if len(rs) != 1 {
+		return errors.Errorf("repo %d not found", cj.RepoID)
+	}
+	repo := rs[0]
+
+	src, err := r.changesetSource(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	// The commit is dated with the job's creation time, so that a resumed
+	// job creates the exact same commit. Pushing it again is then a no-op,
+	// while pushing over a branch the campaign didn't create fails.
+	headRef := campaignBranch(c)
+	_, err = gitserver.DefaultClient.CreateCommitFromPatch(ctx, protocol.CreateCommitFromPatchRequest{
+		Repo:       api.RepoName(repo.Name),
+		BaseCommit: cj.Rev,
+		Patch:      cj.Diff,
+		TargetRef:  headRef,
+		CommitInfo: protocol.PatchCommitInfo{
+			Message: c.Name,
+			Date:    job.CreatedAt,
+		},
+		Push: true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating branch")
+	}
+
+	cs := &repos.Changeset{
+		Title:   c.Name,
+		Body:    c.Description,
+		HeadRef: headRef,
+		BaseRef: cj.BaseRef,
+		Repo:    repo,
+		Changeset: &a8n.Changeset{
+			RepoID:      cj.RepoID,
+			CampaignIDs: []int64{c.ID},
+		},
+	}
+
+	// If a previous run of the job created the changeset but failed to
+	// record it, the code host refuses to create another one and the source
+	// uses the existing one instead.
+	if err = src.CreateChangeset(ctx, cs); err != nil {
+		return errors.Wrap(err, "creating changeset")
+	}
+
+	tx, err := r.Store.Transact(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Done(&err)
+
+	if err = tx.CreateChangesets(ctx, cs.Changeset); err != nil {
+		return err
+	}
+
+	// The campaign's row is locked since the jobs of a campaign add their
+	// changesets to it concurrently.
+	campaign, err := tx.GetCampaign(ctx, GetCampaignOpts{ID: c.ID, ForUpdate: true})
+	if err != nil {
+		return err
+	}
+
+	campaign.ChangesetIDs = append(campaign.ChangesetIDs, cs.Changeset.ID)
+	if err = tx.UpdateCampaign(ctx, campaign); err != nil {
+		return err
+	}
+
+	job.ChangesetID = cs.Changeset.ID
+	job.FinishedAt = r.Store.now()
+
+	return tx.UpdateChangesetJobs(ctx, job)
+}
+
+// staleJobsTimeout is how long an unfinished job may go without updates
+// before it's considered abandoned by the Runner that ran it, e.g. because its
+// process restarted. The run it was part of has timed out by then.
+const staleJobsTimeout = jobsTimeout + 10*time.Minute
+
+// Run periodically resumes the CampaignJobs, ChangesetJobs and
+// ChangesetActionJobs that a Runner abandoned before finishing them, e.g.
+// because its process restarted. It returns when ctx is done.
+func (r *Runner) Run(ctx context.Context) error {
+	for {
+		r.resumeStaleJobs(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Minute):
+		}
+	}
+}
+
+func (r *Runner) resumeStaleJobs(ctx context.Context) {
+	before := r.Store.now().Add(-staleJobsTimeout)
+
+	campaignJobs, err := r.Store.ClaimStaleCampaignJobs(ctx, before)
+	if err != nil {
+		log15.Error("Runner.ClaimStaleCampaignJobs", "error", err)
+	} else if err = r.resumeCampaignJobs(ctx, campaignJobs); err != nil {
+		log15.Error("Runner.resumeCampaignJobs", "error", err)
+	}
+
+	changesetJobs, err := r.Store.ClaimStaleChangesetJobs(ctx, before)
+	if err != nil {
+		log15.Error("Runner.ClaimStaleChangesetJobs", "error", err)
+	} else if err = r.resumeChangesetJobs(ctx, changesetJobs); err != nil {
+		log15.Error("Runner.resumeChangesetJobs", "error", err)
+	}
+
+	actionJobs, err := r.Store.ClaimStaleChangesetActionJobs(ctx, before)
+	if err != nil {
+		log15.Error("Runner.ClaimStaleChangesetActionJobs", "error", err)
+	} else if err = r.resumeChangesetActionJobs(ctx, actionJobs); err != nil {
+		log15.Error("Runner.resumeChangesetActionJobs", "error", err)
+	}
+}
+
+// resumeCampaignJobs runs the given CampaignJobs in the background, grouped
+// by CampaignPlan.
+func (r *Runner) resumeCampaignJobs(ctx context.Context, jobs []*a8n.CampaignJob) error {
+	byPlan := map[int64][]*a8n.CampaignJob{}
+	for _, j := range jobs {
+		byPlan[j.CampaignPlanID] = append(byPlan[j.CampaignPlanID], j)
+	}
+
+	for planID, jobs := range byPlan {
+		plan, err := r.Store.GetCampaignPlan(ctx, GetCampaignPlanOpts{ID: planID})
+		if err != nil {
+			return err
+		}
+
+		rs, jobs, err := r.campaignJobRepos(ctx, jobs)
+		if err != nil {
+			return err
+		}
+
+		go r.runCampaignJobs(plan, rs, jobs)
+	}
+
+	return nil
+}
+
+// campaignJobRepos returns the repositories of the given CampaignJobs, along
+// with the jobs whose repository exists. The other jobs are finished with an
+// error.
+func (r *Runner) campaignJobRepos(ctx context.Context, jobs []*a8n.CampaignJob) ([]*types.Repo, []*a8n.CampaignJob, error) {
+	ids := make([]uint32, 0, len(jobs))
+	for _, j := range jobs {
+		ids = append(ids, uint32(j.RepoID))
+	}
+
+	stored, err := r.ReposStore.ListRepos(ctx, repos.StoreListReposArgs{IDs: ids})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[int32]*repos.Repo, len(stored))
+	for _, repo := range stored {
+		byID[int32(repo.ID)] = repo
+	}
+
+	rs := make([]*types.Repo, 0, len(jobs))
+	found := make([]*a8n.CampaignJob, 0, len(jobs))
+	for _, j := range jobs {
+		repo, ok := byID[j.RepoID]
+		if !ok {
+			j.Error = fmt.Sprintf("repo %d not found", j.RepoID)
+			j.FinishedAt = r.Store.now()
+			if err := r.Store.UpdateCampaignJobs(ctx, j); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		rs = append(rs, &types.Repo{
+			ID:           api.RepoID(repo.ID),
+			ExternalRepo: repo.ExternalRepo,
+			Name:         api.RepoName(repo.Name),
+		})
+		found = append(found, j)
+	}
+
+	return rs, found, nil
+}
+
+// resumeChangesetJobs runs the given ChangesetJobs in the background, grouped
+// by Campaign.
+func (r *Runner) resumeChangesetJobs(ctx context.Context, jobs []*a8n.ChangesetJob) error {
+	byCampaign := map[int64][]*a8n.ChangesetJob{}
+	for _, j := range jobs {
+		byCampaign[j.CampaignID] = append(byCampaign[j.CampaignID], j)
+	}
+
+	for campaignID, jobs := range byCampaign {
+		c, err := r.Store.GetCampaign(ctx, GetCampaignOpts{ID: campaignID})
+		if err != nil {
+			return err
+		}
+
+		all, err := listAllCampaignJobs(ctx, r.Store, ListCampaignJobsOpts{
+			CampaignPlanID: c.CampaignPlanID,
+			OnlyWithDiff:   true,
+		})
+		if err != nil {
+			return err
+		}
+
+		byID := make(map[int64]*a8n.CampaignJob, len(all))
+		for _, cj := range all {
+			byID[cj.ID] = cj
+		}
+
+		campaignJobs := make([]*a8n.CampaignJob, 0, len(jobs))
+		for _, j := range jobs {
+			cj, ok := byID[j.CampaignJobID]
+			if !ok {
+				return errors.Errorf("campaign job %d of changeset job %d not found", j.CampaignJobID, j.ID)
+			}
+			campaignJobs = append(campaignJobs, cj)
+		}
+
+		go r.runChangesetJobs(c, campaignJobs, jobs)
+	}
+
+	return nil
+}
+
+// changesetSource returns a ChangesetSource for one of the external
+// services the given repo belongs to.
+func (r *Runner) changesetSource(ctx context.Context, repo *repos.Repo) (repos.ChangesetSource, error) {
+	es, err := r.ReposStore.ListExternalServices(ctx, repos.StoreListExternalServicesArgs{
+		IDs: repo.ExternalServiceIDs(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range es {
+		src, err := repos.NewSource(e, r.HTTPFactory)
+		if err != nil {
+			return nil, err
+		}
+
+		if css, ok := src.(repos.ChangesetSource); ok {
+			return css, nil
+		}
+	}
+
+	return nil, errors.Errorf("creating changesets is not supported for repo %q", repo.Name)
+}
+
+// campaignBranch returns the fully qualified name of the branch the changes
+// of the given Campaign are pushed to.
+func campaignBranch(c *a8n.Campaign) string {
+	return fmt.Sprintf("refs/heads/sourcegraph/campaign-%d", c.ID)
+}
+
+// run calls f with every index in [0, n), running at most maxConcurrentJobs
+// calls concurrently. It returns once all calls returned.
+func run(n int, f func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentJobs)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			f(i)
+		}(i)
+	}
+
+	wg.Wait()
+}
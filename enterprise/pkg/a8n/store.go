@@ -8,10 +8,13 @@ import (
 	"time"
 
 	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/pkg/a8n"
 	"github.com/sourcegraph/sourcegraph/pkg/db/dbutil"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
 )
 
 // Store exposes methods to read and write a8n domain models
@@ -291,10 +294,12 @@ func getChangesetQuery(opts *GetChangesetOpts) *sqlf.Query {
 // ListChangesetsOpts captures the query options needed for
 // listing changesets.
 type ListChangesetsOpts struct {
-	Cursor     int64
-	Limit      int
-	CampaignID int64
-	IDs        []int64
+	Cursor      int64
+	Limit       int
+	CampaignID  int64
+	IDs         []int64
+	RepoID      int32
+	ExternalIDs []string
 }
 
 // ListChangesets lists Changesets with the given filters.
@@ -362,6 +367,18 @@ func listChangesetsQuery(opts *ListChangesetsOpts) *sqlf.Query {
 		preds = append(preds, sqlf.Sprintf("id IN (%s)", sqlf.Join(ids, ",")))
 	}
 
+	if opts.RepoID != 0 {
+		preds = append(preds, sqlf.Sprintf("repo_id = %s", opts.RepoID))
+	}
+
+	if len(opts.ExternalIDs) > 0 {
+		ids := make([]*sqlf.Query, 0, len(opts.ExternalIDs))
+		for _, id := range opts.ExternalIDs {
+			ids = append(ids, sqlf.Sprintf("%s", id))
+		}
+		preds = append(preds, sqlf.Sprintf("external_id IN (%s)", sqlf.Join(ids, ",")))
+	}
+
 	return sqlf.Sprintf(
 		listChangesetsQueryFmtstr,
 		sqlf.Join(preds, "\n AND "),
@@ -449,7 +466,8 @@ INSERT INTO campaigns (
 	namespace_org_id,
 	created_at,
 	updated_at,
-	changeset_ids
+	changeset_ids,
+	campaign_plan_id
 )
 VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
 RETURNING
@@ -461,7 +479,8 @@ RETURNING
 	namespace_org_id,
 	created_at,
 	updated_at,
-	changeset_ids
+	changeset_ids,
+	campaign_plan_id
 `
 
 func (s *Store) createCampaignQuery(c *a8n.Campaign) (*sqlf.Query, error) {
@@ -488,6 +507,7 @@ func (s *Store) createCampaignQuery(c *a8n.Campaign) (*sqlf.Query, error) {
 		c.CreatedAt,
 		c.UpdatedAt,
 		changesetIDs,
+		nullInt64Column(c.CampaignPlanID),
 	), nil
 }
 
@@ -498,6 +518,13 @@ func nullInt32Column(n int32) *int32 {
 	return &n
 }
 
+func nullInt64Column(n int64) *int64 {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
+
 // UpdateCampaign updates the given Campaign.
 func (s *Store) UpdateCampaign(ctx context.Context, c *a8n.Campaign) error {
 	q, err := s.updateCampaignQuery(c)
@@ -521,7 +548,8 @@ SET (
 	namespace_user_id,
 	namespace_org_id,
 	updated_at,
-	changeset_ids
+	changeset_ids,
+	campaign_plan_id
 ) = (%s, %s, %s, %s, %s, %s, %s)
 WHERE id = %s
 RETURNING
@@ -533,7 +561,8 @@ RETURNING
 	namespace_org_id,
 	created_at,
 	updated_at,
-	changeset_ids
+	changeset_ids,
+	campaign_plan_id
 `
 
 func (s *Store) updateCampaignQuery(c *a8n.Campaign) (*sqlf.Query, error) {
@@ -553,6 +582,7 @@ func (s *Store) updateCampaignQuery(c *a8n.Campaign) (*sqlf.Query, error) {
 		nullInt32Column(c.NamespaceOrgID),
 		c.UpdatedAt,
 		changesetIDs,
+		nullInt64Column(c.CampaignPlanID),
 		c.ID,
 	), nil
 }
@@ -611,6 +641,9 @@ func countCampaignsQuery(opts *CountCampaignsOpts) *sqlf.Query {
 // GetCampaignOpts captures the query options needed for getting a Campaign
 type GetCampaignOpts struct {
 	ID int64
+	// ForUpdate locks the campaign's row until the end of the transaction,
+	// so that concurrent read-modify-write updates don't get lost.
+	ForUpdate bool
 }
 
 // GetCampaign gets a campaign matching the given options.
@@ -643,10 +676,12 @@ SELECT
 	namespace_org_id,
 	created_at,
 	updated_at,
-	changeset_ids
+	changeset_ids,
+	campaign_plan_id
 FROM campaigns
 WHERE %s
 LIMIT 1
+%s
 `
 
 func getCampaignQuery(opts *GetCampaignOpts) *sqlf.Query {
@@ -659,7 +694,12 @@ func getCampaignQuery(opts *GetCampaignOpts) *sqlf.Query {
 		preds = append(preds, sqlf.Sprintf("TRUE"))
 	}
 
-	return sqlf.Sprintf(getCampaignsQueryFmtstr, sqlf.Join(preds, "\n AND "))
+	lock := sqlf.Sprintf("")
+	if opts.ForUpdate {
+		lock = sqlf.Sprintf("FOR UPDATE")
+	}
+
+	return sqlf.Sprintf(getCampaignsQueryFmtstr, sqlf.Join(preds, "\n AND "), lock)
 }
 
 // ListCampaignsOpts captures the query options needed for
@@ -703,7 +743,8 @@ SELECT
 	namespace_org_id,
 	created_at,
 	updated_at,
-	changeset_ids
+	changeset_ids,
+	campaign_plan_id
 FROM campaigns
 WHERE %s
 ORDER BY id ASC
@@ -731,108 +772,1699 @@ func listCampaignsQuery(opts *ListCampaignsOpts) *sqlf.Query {
 	)
 }
 
-func (s *Store) exec(ctx context.Context, q *sqlf.Query, sc scanFunc) error {
-	_, _, err := s.query(ctx, q, sc)
-	return err
+// CreateCampaignPlan creates the given CampaignPlan.
+func (s *Store) CreateCampaignPlan(ctx context.Context, c *a8n.CampaignPlan) error {
+	q, err := s.createCampaignPlanQuery(c)
+	if err != nil {
+		return err
+	}
+
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanCampaignPlan(c, sc)
+		return int64(c.ID), 1, err
+	})
 }
 
-func (s *Store) query(ctx context.Context, q *sqlf.Query, sc scanFunc) (last, count int64, err error) {
-	rows, err := s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+var createCampaignPlanQueryFmtstr = `
+-- source: pkg/a8n/store.go:CreateCampaignPlan
+INSERT INTO campaign_plans (
+	spec,
+	created_at,
+	updated_at
+)
+VALUES (%s, %s, %s)
+RETURNING
+	id,
+	spec,
+	created_at,
+	updated_at
+`
+
+func (s *Store) createCampaignPlanQuery(c *a8n.CampaignPlan) (*sqlf.Query, error) {
+	spec, err := json.Marshal(c.Spec)
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
-	return scanAll(rows, sc)
+
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = s.now()
+	}
+
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = c.CreatedAt
+	}
+
+	return sqlf.Sprintf(
+		createCampaignPlanQueryFmtstr,
+		spec,
+		c.CreatedAt,
+		c.UpdatedAt,
+	), nil
 }
 
-// scanner captures the Scan method of sql.Rows and sql.Row
-type scanner interface {
-	Scan(dst ...interface{}) error
+// GetCampaignPlanOpts captures the query options needed for getting a CampaignPlan
+type GetCampaignPlanOpts struct {
+	ID int64
 }
 
-// a scanFunc scans one or more rows from a scanner, returning
-// the last id column scanned and the count of scanned rows.
-type scanFunc func(scanner) (last, count int64, err error)
+// GetCampaignPlan gets a campaign plan matching the given options.
+func (s *Store) GetCampaignPlan(ctx context.Context, opts GetCampaignPlanOpts) (*a8n.CampaignPlan, error) {
+	q := getCampaignPlanQuery(&opts)
 
-func scanAll(rows *sql.Rows, scan scanFunc) (last, count int64, err error) {
-	defer closeErr(rows, &err)
+	var c a8n.CampaignPlan
+	err := s.exec(ctx, q, func(sc scanner) (_, _ int64, err error) {
+		return 0, 0, scanCampaignPlan(&c, sc)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	last = -1
-	for rows.Next() {
-		var n int64
-		if last, n, err = scan(rows); err != nil {
-			return last, count, err
-		}
-		count += n
+	if c.ID == 0 {
+		return nil, ErrNoResults
 	}
 
-	return last, count, rows.Err()
+	return &c, nil
 }
 
-func closeErr(c io.Closer, err *error) {
-	if e := c.Close(); err != nil && *err == nil {
-		*err = e
+var getCampaignPlansQueryFmtstr = `
+-- source: pkg/a8n/store.go:GetCampaignPlan
+SELECT
+	id,
+	spec,
+	created_at,
+	updated_at
+FROM campaign_plans
+WHERE %s
+LIMIT 1
+`
+
+func getCampaignPlanQuery(opts *GetCampaignPlanOpts) *sqlf.Query {
+	var preds []*sqlf.Query
+	if opts.ID != 0 {
+		preds = append(preds, sqlf.Sprintf("id = %s", opts.ID))
+	}
+
+	if len(preds) == 0 {
+		preds = append(preds, sqlf.Sprintf("TRUE"))
+	}
+
+	return sqlf.Sprintf(getCampaignPlansQueryFmtstr, sqlf.Join(preds, "\n AND "))
+}
+
+// CreateCampaignJobs creates the given CampaignJobs.
+func (s *Store) CreateCampaignJobs(ctx context.Context, cs ...*a8n.CampaignJob) error {
+	q, err := s.createCampaignJobsQuery(cs)
+	if err != nil {
+		return err
 	}
+
+	i := -1
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		i++
+		err = scanCampaignJob(cs[i], sc)
+		return int64(cs[i].ID), 1, err
+	})
 }
 
-func scanChangeset(t *a8n.Changeset, s scanner) error {
-	var metadata json.RawMessage
+const campaignJobBatchQueryPrefix = `
+WITH batch AS (
+  SELECT * FROM ROWS FROM (
+  json_to_recordset(%s)
+  AS (
+      id               bigint,
+      campaign_plan_id bigint,
+      repo_id          integer,
+      rev              text,
+      base_ref         text,
+      diff             text,
+      error            text,
+      started_at       timestamptz,
+      finished_at      timestamptz,
+      created_at       timestamptz,
+      updated_at       timestamptz
+    )
+  )
+  WITH ORDINALITY
+)
+`
 
-	err := s.Scan(
-		&t.ID,
-		&t.RepoID,
-		&t.CreatedAt,
-		&t.UpdatedAt,
-		&metadata,
-		&dbutil.JSONInt64Set{Set: &t.CampaignIDs},
-		&t.ExternalID,
-		&t.ExternalServiceType,
-	)
+var createCampaignJobsQueryFmtstr = campaignJobBatchQueryPrefix + `,
+-- source: pkg/a8n/store.go:CreateCampaignJobs
+changed AS (
+  INSERT INTO campaign_jobs (
+    campaign_plan_id,
+    repo_id,
+    rev,
+    base_ref,
+    diff,
+    error,
+    started_at,
+    finished_at,
+    created_at,
+    updated_at
+  )
+  SELECT
+    campaign_plan_id,
+    repo_id,
+    rev,
+    base_ref,
+    diff,
+    error,
+    started_at,
+    finished_at,
+    created_at,
+    updated_at
+  FROM batch
+  RETURNING campaign_jobs.*
+)
+` + batchCampaignJobsQuerySuffix
+
+func (s *Store) createCampaignJobsQuery(cs []*a8n.CampaignJob) (*sqlf.Query, error) {
+	now := s.now()
+	for _, c := range cs {
+		if c.CreatedAt.IsZero() {
+			c.CreatedAt = now
+		}
+
+		if c.UpdatedAt.IsZero() {
+			c.UpdatedAt = c.CreatedAt
+		}
+	}
+	return batchCampaignJobsQuery(createCampaignJobsQueryFmtstr, cs)
+}
+
+func batchCampaignJobsQuery(fmtstr string, cs []*a8n.CampaignJob) (*sqlf.Query, error) {
+	type record struct {
+		ID             int64      `json:"id"`
+		CampaignPlanID int64      `json:"campaign_plan_id"`
+		RepoID         int32      `json:"repo_id"`
+		Rev            string     `json:"rev"`
+		BaseRef        string     `json:"base_ref"`
+		Diff           string     `json:"diff"`
+		Error          string     `json:"error"`
+		StartedAt      *time.Time `json:"started_at"`
+		FinishedAt     *time.Time `json:"finished_at"`
+		CreatedAt      time.Time  `json:"created_at"`
+		UpdatedAt      time.Time  `json:"updated_at"`
+	}
+
+	records := make([]record, 0, len(cs))
+
+	for _, c := range cs {
+		records = append(records, record{
+			ID:             c.ID,
+			CampaignPlanID: c.CampaignPlanID,
+			RepoID:         c.RepoID,
+			Rev:            string(c.Rev),
+			BaseRef:        c.BaseRef,
+			Diff:           c.Diff,
+			Error:          c.Error,
+			StartedAt:      nullTimeColumn(c.StartedAt),
+			FinishedAt:     nullTimeColumn(c.FinishedAt),
+			CreatedAt:      c.CreatedAt,
+			UpdatedAt:      c.UpdatedAt,
+		})
+	}
+
+	batch, err := json.MarshalIndent(records, "    ", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlf.Sprintf(fmtstr, string(batch)), nil
+}
+
+// UpdateCampaignJobs updates the given CampaignJobs.
+func (s *Store) UpdateCampaignJobs(ctx context.Context, cs ...*a8n.CampaignJob) error {
+	q, err := s.updateCampaignJobsQuery(cs)
 	if err != nil {
 		return err
 	}
 
-	switch t.ExternalServiceType {
-	case github.ServiceType:
-		t.Metadata = new(github.PullRequest)
-	default:
-		return nil
+	i := -1
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		i++
+		err = scanCampaignJob(cs[i], sc)
+		return int64(cs[i].ID), 1, err
+	})
+}
+
+const updateCampaignJobsQueryFmtstr = campaignJobBatchQueryPrefix + `,
+-- source: pkg/a8n/store.go:UpdateCampaignJobs
+changed AS (
+  UPDATE campaign_jobs
+  SET
+    campaign_plan_id = batch.campaign_plan_id,
+    repo_id          = batch.repo_id,
+    rev              = batch.rev,
+    base_ref         = batch.base_ref,
+    diff             = batch.diff,
+    error            = batch.error,
+    started_at       = batch.started_at,
+    finished_at      = batch.finished_at,
+    created_at       = batch.created_at,
+    updated_at       = batch.updated_at
+  FROM batch
+  WHERE campaign_jobs.id = batch.id
+  RETURNING campaign_jobs.*
+)
+` + batchCampaignJobsQuerySuffix
+
+const batchCampaignJobsQuerySuffix = `
+SELECT
+  changed.id,
+  changed.campaign_plan_id,
+  changed.repo_id,
+  changed.rev,
+  changed.base_ref,
+  changed.diff,
+  changed.error,
+  changed.started_at,
+  changed.finished_at,
+  changed.created_at,
+  changed.updated_at
+FROM changed
+LEFT JOIN batch ON batch.campaign_plan_id = changed.campaign_plan_id
+AND batch.repo_id = changed.repo_id
+ORDER BY batch.ordinality
+`
+
+func (s *Store) updateCampaignJobsQuery(cs []*a8n.CampaignJob) (*sqlf.Query, error) {
+	now := s.now()
+	for _, c := range cs {
+		c.UpdatedAt = now
 	}
+	return batchCampaignJobsQuery(updateCampaignJobsQueryFmtstr, cs)
+}
 
-	if err = json.Unmarshal(metadata, t.Metadata); err != nil {
-		return errors.Wrapf(err, "scanChangeset: failed to unmarshal %q metadata", t.ExternalServiceType)
+// CountCampaignJobsOpts captures the query options needed for
+// counting campaign jobs.
+type CountCampaignJobsOpts struct {
+	CampaignPlanID int64
+	// OnlyWithDiff restricts the count to the campaign jobs that finished
+	// with a non-empty diff.
+	OnlyWithDiff bool
+}
+
+// CountCampaignJobs returns the number of campaign jobs in the database.
+func (s *Store) CountCampaignJobs(ctx context.Context, opts CountCampaignJobsOpts) (count int64, _ error) {
+	q := countCampaignJobsQuery(&opts)
+	return count, s.exec(ctx, q, func(sc scanner) (_, _ int64, err error) {
+		err = sc.Scan(&count)
+		return 0, count, err
+	})
+}
+
+var countCampaignJobsQueryFmtstr = `
+-- source: pkg/a8n/store.go:CountCampaignJobs
+SELECT COUNT(id)
+FROM campaign_jobs
+WHERE %s
+`
+
+func countCampaignJobsQuery(opts *CountCampaignJobsOpts) *sqlf.Query {
+	var preds []*sqlf.Query
+	if opts.CampaignPlanID != 0 {
+		preds = append(preds, sqlf.Sprintf("campaign_plan_id = %s", opts.CampaignPlanID))
 	}
 
-	return nil
+	if opts.OnlyWithDiff {
+		preds = append(preds, sqlf.Sprintf("diff != ''"))
+	}
+
+	if len(preds) == 0 {
+		preds = append(preds, sqlf.Sprintf("TRUE"))
+	}
+
+	return sqlf.Sprintf(countCampaignJobsQueryFmtstr, sqlf.Join(preds, "\n AND "))
 }
 
-func scanCampaign(c *a8n.Campaign, s scanner) error {
-	return s.Scan(
-		&c.ID,
-		&c.Name,
-		&c.Description,
-		&c.AuthorID,
-		&dbutil.NullInt32{N: &c.NamespaceUserID},
-		&dbutil.NullInt32{N: &c.NamespaceOrgID},
-		&c.CreatedAt,
-		&c.UpdatedAt,
-		&dbutil.JSONInt64Set{Set: &c.ChangesetIDs},
-	)
+// ListCampaignJobsOpts captures the query options needed for
+// listing campaign jobs.
+type ListCampaignJobsOpts struct {
+	CampaignPlanID int64
+	Cursor         int64
+	Limit          int
+	// OnlyWithDiff restricts the list to the campaign jobs that finished
+	// with a non-empty diff.
+	OnlyWithDiff bool
 }
 
-func metadataColumn(metadata interface{}) (msg json.RawMessage, err error) {
-	switch m := metadata.(type) {
-	case nil:
-		msg = json.RawMessage("{}")
-	case string:
-		msg = json.RawMessage(m)
-	case []byte:
-		msg = m
-	case json.RawMessage:
-		msg = m
-	default:
-		msg, err = json.MarshalIndent(m, "        ", "    ")
+// ListCampaignJobs lists CampaignJobs with the given filters.
+func (s *Store) ListCampaignJobs(ctx context.Context, opts ListCampaignJobsOpts) (cs []*a8n.CampaignJob, next int64, err error) {
+	q := listCampaignJobsQuery(&opts)
+
+	cs = make([]*a8n.CampaignJob, 0, opts.Limit)
+	_, _, err = s.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var c a8n.CampaignJob
+		if err = scanCampaignJob(&c, sc); err != nil {
+			return 0, 0, err
+		}
+		cs = append(cs, &c)
+		return int64(c.ID), 1, err
+	})
+
+	if len(cs) == opts.Limit {
+		next = cs[len(cs)-1].ID
+		cs = cs[:len(cs)-1]
 	}
-	return
+
+	return cs, next, err
+}
+
+var listCampaignJobsQueryFmtstr = `
+-- source: pkg/a8n/store.go:ListCampaignJobs
+SELECT
+	id,
+	campaign_plan_id,
+	repo_id,
+	rev,
+	base_ref,
+	diff,
+	error,
+	started_at,
+	finished_at,
+	created_at,
+	updated_at
+FROM campaign_jobs
+WHERE %s
+ORDER BY id ASC
+LIMIT %s
+`
+
+func listCampaignJobsQuery(opts *ListCampaignJobsOpts) *sqlf.Query {
+	if opts.Limit == 0 {
+		opts.Limit = defaultListLimit
+	}
+	opts.Limit++
+
+	preds := []*sqlf.Query{
+		sqlf.Sprintf("id >= %s", opts.Cursor),
+	}
+
+	if opts.CampaignPlanID != 0 {
+		preds = append(preds, sqlf.Sprintf("campaign_plan_id = %s", opts.CampaignPlanID))
+	}
+
+	if opts.OnlyWithDiff {
+		preds = append(preds, sqlf.Sprintf("diff != ''"))
+	}
+
+	return sqlf.Sprintf(
+		listCampaignJobsQueryFmtstr,
+		sqlf.Join(preds, "\n AND "),
+		opts.Limit,
+	)
+}
+
+// ClaimStaleCampaignJobs returns the unfinished CampaignJobs that haven't
+// been updated since before the given time, after setting their updated_at to
+// now. Each stale job is claimed by a single caller, which is then in charge of
+// resuming it.
+func (s *Store) ClaimStaleCampaignJobs(ctx context.Context, before time.Time) (cs []*a8n.CampaignJob, err error) {
+	q := sqlf.Sprintf(claimStaleCampaignJobsQueryFmtstr, s.now(), before)
+
+	err = s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var c a8n.CampaignJob
+		if err = scanCampaignJob(&c, sc); err != nil {
+			return 0, 0, err
+		}
+		cs = append(cs, &c)
+		return int64(c.ID), 1, err
+	})
+
+	return cs, err
+}
+
+var claimStaleCampaignJobsQueryFmtstr = `
+-- source: pkg/a8n/store.go:ClaimStaleCampaignJobs
+UPDATE campaign_jobs
+SET updated_at = %s
+WHERE finished_at IS NULL
+AND updated_at < %s
+RETURNING
+	id,
+	campaign_plan_id,
+	repo_id,
+	rev,
+	base_ref,
+	diff,
+	error,
+	started_at,
+	finished_at,
+	created_at,
+	updated_at
+`
+
+// GetCampaignPlanStatus returns the aggregate status of the CampaignJobs
+// of the CampaignPlan with the given ID.
+func (s *Store) GetCampaignPlanStatus(ctx context.Context, id int64) (*a8n.BackgroundProcessStatus, error) {
+	return s.queryBackgroundProcessStatus(ctx, sqlf.Sprintf(
+		getCampaignPlanStatusQueryFmtstr,
+		id,
+	))
+}
+
+var getCampaignPlanStatusQueryFmtstr = `
+-- source: pkg/a8n/store.go:GetCampaignPlanStatus
+SELECT
+  COUNT(*) AS total,
+  COUNT(*) FILTER (WHERE finished_at IS NOT NULL) AS completed,
+  COUNT(*) FILTER (WHERE finished_at IS NULL) AS pending,
+  array_agg(error) FILTER (WHERE error != '') AS errors
+FROM campaign_jobs
+WHERE campaign_plan_id = %s
+`
+
+// CreateChangesetJobs creates the given ChangesetJobs.
+func (s *Store) CreateChangesetJobs(ctx context.Context, cs ...*a8n.ChangesetJob) error {
+	q, err := s.createChangesetJobsQuery(cs)
+	if err != nil {
+		return err
+	}
+
+	i := -1
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		i++
+		err = scanChangesetJob(cs[i], sc)
+		return int64(cs[i].ID), 1, err
+	})
+}
+
+const changesetJobBatchQueryPrefix = `
+WITH batch AS (
+  SELECT * FROM ROWS FROM (
+  json_to_recordset(%s)
+  AS (
+      id              bigint,
+      campaign_id     bigint,
+      campaign_job_id bigint,
+      changeset_id    bigint,
+      error           text,
+      started_at      timestamptz,
+      finished_at     timestamptz,
+      created_at      timestamptz,
+      updated_at      timestamptz
+    )
+  )
+  WITH ORDINALITY
+)
+`
+
+var createChangesetJobsQueryFmtstr = changesetJobBatchQueryPrefix + `,
+-- source: pkg/a8n/store.go:CreateChangesetJobs
+changed AS (
+  INSERT INTO changeset_jobs (
+    campaign_id,
+    campaign_job_id,
+    changeset_id,
+    error,
+    started_at,
+    finished_at,
+    created_at,
+    updated_at
+  )
+  SELECT
+    campaign_id,
+    campaign_job_id,
+    changeset_id,
+    error,
+    started_at,
+    finished_at,
+    created_at,
+    updated_at
+  FROM batch
+  RETURNING changeset_jobs.*
+)
+` + batchChangesetJobsQuerySuffix
+
+func (s *Store) createChangesetJobsQuery(cs []*a8n.ChangesetJob) (*sqlf.Query, error) {
+	now := s.now()
+	for _, c := range cs {
+		if c.CreatedAt.IsZero() {
+			c.CreatedAt = now
+		}
+
+		if c.UpdatedAt.IsZero() {
+			c.UpdatedAt = c.CreatedAt
+		}
+	}
+	return batchChangesetJobsQuery(createChangesetJobsQueryFmtstr, cs)
+}
+
+func batchChangesetJobsQuery(fmtstr string, cs []*a8n.ChangesetJob) (*sqlf.Query, error) {
+	type record struct {
+		ID            int64      `json:"id"`
+		CampaignID    int64      `json:"campaign_id"`
+		CampaignJobID int64      `json:"campaign_job_id"`
+		ChangesetID   *int64     `json:"changeset_id"`
+		Error         string     `json:"error"`
+		StartedAt     *time.Time `json:"started_at"`
+		FinishedAt    *time.Time `json:"finished_at"`
+		CreatedAt     time.Time  `json:"created_at"`
+		UpdatedAt     time.Time  `json:"updated_at"`
+	}
+
+	records := make([]record, 0, len(cs))
+
+	for _, c := range cs {
+		records = append(records, record{
+			ID:            c.ID,
+			CampaignID:    c.CampaignID,
+			CampaignJobID: c.CampaignJobID,
+			ChangesetID:   nullInt64Column(c.ChangesetID),
+			Error:         c.Error,
+			StartedAt:     nullTimeColumn(c.StartedAt),
+			FinishedAt:    nullTimeColumn(c.FinishedAt),
+			CreatedAt:     c.CreatedAt,
+			UpdatedAt:     c.UpdatedAt,
+		})
+	}
+
+	batch, err := json.MarshalIndent(records, "    ", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlf.Sprintf(fmtstr, string(batch)), nil
+}
+
+// UpdateChangesetJobs updates the given ChangesetJobs.
+func (s *Store) UpdateChangesetJobs(ctx context.Context, cs ...*a8n.ChangesetJob) error {
+	q, err := s.updateChangesetJobsQuery(cs)
+	if err != nil {
+		return err
+	}
+
+	i := -1
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		i++
+		err = scanChangesetJob(cs[i], sc)
+		return int64(cs[i].ID), 1, err
+	})
+}
+
+const updateChangesetJobsQueryFmtstr = changesetJobBatchQueryPrefix + `,
+-- source: pkg/a8n/store.go:UpdateChangesetJobs
+changed AS (
+  UPDATE changeset_jobs
+  SET
+    campaign_id     = batch.campaign_id,
+    campaign_job_id = batch.campaign_job_id,
+    changeset_id    = batch.changeset_id,
+    error           = batch.error,
+    started_at      = batch.started_at,
+    finished_at     = batch.finished_at,
+    created_at      = batch.created_at,
+    updated_at      = batch.updated_at
+  FROM batch
+  WHERE changeset_jobs.id = batch.id
+  RETURNING changeset_jobs.*
+)
+` + batchChangesetJobsQuerySuffix
+
+const batchChangesetJobsQuerySuffix = `
+SELECT
+  changed.id,
+  changed.campaign_id,
+  changed.campaign_job_id,
+  changed.changeset_id,
+  changed.error,
+  changed.started_at,
+  changed.finished_at,
+  changed.created_at,
+  changed.updated_at
+FROM changed
+LEFT JOIN batch ON batch.campaign_id = changed.campaign_id
+AND batch.campaign_job_id = changed.campaign_job_id
+ORDER BY batch.ordinality
+`
+
+func (s *Store) updateChangesetJobsQuery(cs []*a8n.ChangesetJob) (*sqlf.Query, error) {
+	now := s.now()
+	for _, c := range cs {
+		c.UpdatedAt = now
+	}
+	return batchChangesetJobsQuery(updateChangesetJobsQueryFmtstr, cs)
+}
+
+// ListChangesetJobsOpts captures the query options needed for
+// listing changeset jobs.
+type ListChangesetJobsOpts struct {
+	CampaignID int64
+	Cursor     int64
+	Limit      int
+}
+
+// ListChangesetJobs lists ChangesetJobs with the given filters.
+func (s *Store) ListChangesetJobs(ctx context.Context, opts ListChangesetJobsOpts) (cs []*a8n.ChangesetJob, next int64, err error) {
+	q := listChangesetJobsQuery(&opts)
+
+	cs = make([]*a8n.ChangesetJob, 0, opts.Limit)
+	_, _, err = s.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var c a8n.ChangesetJob
+		if err = scanChangesetJob(&c, sc); err != nil {
+			return 0, 0, err
+		}
+		cs = append(cs, &c)
+		return int64(c.ID), 1, err
+	})
+
+	if len(cs) == opts.Limit {
+		next = cs[len(cs)-1].ID
+		cs = cs[:len(cs)-1]
+	}
+
+	return cs, next, err
+}
+
+var listChangesetJobsQueryFmtstr = `
+-- source: pkg/a8n/store.go:ListChangesetJobs
+SELECT
+	id,
+	campaign_id,
+	campaign_job_id,
+	changeset_id,
+	error,
+	started_at,
+	finished_at,
+	created_at,
+	updated_at
+FROM changeset_jobs
+WHERE %s
+ORDER BY id ASC
+LIMIT %s
+`
+
+func listChangesetJobsQuery(opts *ListChangesetJobsOpts) *sqlf.Query {
+	if opts.Limit == 0 {
+		opts.Limit = defaultListLimit
+	}
+	opts.Limit++
+
+	preds := []*sqlf.Query{
+		sqlf.Sprintf("id >= %s", opts.Cursor),
+	}
+
+	if opts.CampaignID != 0 {
+		preds = append(preds, sqlf.Sprintf("campaign_id = %s", opts.CampaignID))
+	}
+
+	return sqlf.Sprintf(
+		listChangesetJobsQueryFmtstr,
+		sqlf.Join(preds, "\n AND "),
+		opts.Limit,
+	)
+}
+
+// ClaimStaleChangesetJobs returns the unfinished ChangesetJobs that haven't
+// been updated since before the given time, after setting their updated_at to
+// now. Each stale job is claimed by a single caller, which is then in charge of
+// resuming it.
+func (s *Store) ClaimStaleChangesetJobs(ctx context.Context, before time.Time) (cs []*a8n.ChangesetJob, err error) {
+	q := sqlf.Sprintf(claimStaleChangesetJobsQueryFmtstr, s.now(), before)
+
+	err = s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var c a8n.ChangesetJob
+		if err = scanChangesetJob(&c, sc); err != nil {
+			return 0, 0, err
+		}
+		cs = append(cs, &c)
+		return int64(c.ID), 1, err
+	})
+
+	return cs, err
+}
+
+var claimStaleChangesetJobsQueryFmtstr = `
+-- source: pkg/a8n/store.go:ClaimStaleChangesetJobs
+UPDATE changeset_jobs
+SET updated_at = %s
+WHERE finished_at IS NULL
+AND updated_at < %s
+RETURNING
+	id,
+	campaign_id,
+	campaign_job_id,
+	changeset_id,
+	error,
+	started_at,
+	finished_at,
+	created_at,
+	updated_at
+`
+
+// GetCampaignStatus returns the aggregate status of the ChangesetJobs
+// of the Campaign with the given ID.
+func (s *Store) GetCampaignStatus(ctx context.Context, id int64) (*a8n.BackgroundProcessStatus, error) {
+	return s.queryBackgroundProcessStatus(ctx, sqlf.Sprintf(
+		getCampaignStatusQueryFmtstr,
+		id,
+	))
+}
+
+var getCampaignStatusQueryFmtstr = `
+-- source: pkg/a8n/store.go:GetCampaignStatus
+SELECT
+  COUNT(*) AS total,
+  COUNT(*) FILTER (WHERE finished_at IS NOT NULL) AS completed,
+  COUNT(*) FILTER (WHERE finished_at IS NULL) AS pending,
+  array_agg(error) FILTER (WHERE error != '') AS errors
+FROM changeset_jobs
+WHERE campaign_id = %s
+`
+
+func (s *Store) queryBackgroundProcessStatus(ctx context.Context, q *sqlf.Query) (*a8n.BackgroundProcessStatus, error) {
+	var status a8n.BackgroundProcessStatus
+	err := s.exec(ctx, q, func(sc scanner) (_, _ int64, err error) {
+		return 0, 1, sc.Scan(
+			&status.Total,
+			&status.Completed,
+			&status.Pending,
+			pq.Array(&status.Errors),
+		)
+	})
+	return &status, err
+}
+
+// CreateChangesetAction creates the given ChangesetAction.
+func (s *Store) CreateChangesetAction(ctx context.Context, a *a8n.ChangesetAction) error {
+	q := s.createChangesetActionQuery(a)
+
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanChangesetAction(a, sc)
+		return int64(a.ID), 1, err
+	})
+}
+
+var createChangesetActionQueryFmtstr = `
+-- source: pkg/a8n/store.go:CreateChangesetAction
+INSERT INTO changeset_actions (
+	campaign_id,
+	author_id,
+	action,
+	body,
+	created_at,
+	updated_at
+)
+VALUES (%s, %s, %s, %s, %s, %s)
+RETURNING
+	id,
+	campaign_id,
+	author_id,
+	action,
+	body,
+	created_at,
+	updated_at
+`
+
+func (s *Store) createChangesetActionQuery(a *a8n.ChangesetAction) *sqlf.Query {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = s.now()
+	}
+
+	if a.UpdatedAt.IsZero() {
+		a.UpdatedAt = a.CreatedAt
+	}
+
+	return sqlf.Sprintf(
+		createChangesetActionQueryFmtstr,
+		a.CampaignID,
+		a.AuthorID,
+		string(a.Action),
+		a.Body,
+		a.CreatedAt,
+		a.UpdatedAt,
+	)
+}
+
+// GetChangesetActionOpts captures the query options needed for getting a
+// ChangesetAction.
+type GetChangesetActionOpts struct {
+	ID int64
+}
+
+// GetChangesetAction gets a changeset action matching the given options.
+func (s *Store) GetChangesetAction(ctx context.Context, opts GetChangesetActionOpts) (*a8n.ChangesetAction, error) {
+	q := getChangesetActionQuery(&opts)
+
+	var a a8n.ChangesetAction
+	err := s.exec(ctx, q, func(sc scanner) (_, _ int64, err error) {
+		return 0, 0, scanChangesetAction(&a, sc)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if a.ID == 0 {
+		return nil, ErrNoResults
+	}
+
+	return &a, nil
+}
+
+var getChangesetActionQueryFmtstr = `
+-- source: pkg/a8n/store.go:GetChangesetAction
+SELECT
+	id,
+	campaign_id,
+	author_id,
+	action,
+	body,
+	created_at,
+	updated_at
+FROM changeset_actions
+WHERE %s
+LIMIT 1
+`
+
+func getChangesetActionQuery(opts *GetChangesetActionOpts) *sqlf.Query {
+	var preds []*sqlf.Query
+	if opts.ID != 0 {
+		preds = append(preds, sqlf.Sprintf("id = %s", opts.ID))
+	}
+
+	if len(preds) == 0 {
+		preds = append(preds, sqlf.Sprintf("TRUE"))
+	}
+
+	return sqlf.Sprintf(getChangesetActionQueryFmtstr, sqlf.Join(preds, "\n AND "))
+}
+
+// CountChangesetActionsOpts captures the query options needed for
+// counting changeset actions.
+type CountChangesetActionsOpts struct {
+	CampaignID int64
+}
+
+// CountChangesetActions returns the number of changeset actions in the database.
+func (s *Store) CountChangesetActions(ctx context.Context, opts CountChangesetActionsOpts) (count int64, _ error) {
+	q := countChangesetActionsQuery(&opts)
+	return count, s.exec(ctx, q, func(sc scanner) (_, _ int64, err error) {
+		err = sc.Scan(&count)
+		return 0, count, err
+	})
+}
+
+var countChangesetActionsQueryFmtstr = `
+-- source: pkg/a8n/store.go:CountChangesetActions
+SELECT COUNT(id)
+FROM changeset_actions
+WHERE %s
+`
+
+func countChangesetActionsQuery(opts *CountChangesetActionsOpts) *sqlf.Query {
+	var preds []*sqlf.Query
+	if opts.CampaignID != 0 {
+		preds = append(preds, sqlf.Sprintf("campaign_id = %s", opts.CampaignID))
+	}
+
+	if len(preds) == 0 {
+		preds = append(preds, sqlf.Sprintf("TRUE"))
+	}
+
+	return sqlf.Sprintf(countChangesetActionsQueryFmtstr, sqlf.Join(preds, "\n AND "))
+}
+
+// ListChangesetActionsOpts captures the query options needed for
+// listing changeset actions.
+type ListChangesetActionsOpts struct {
+	CampaignID int64
+	Cursor     int64
+	Limit      int
+}
+
+// ListChangesetActions lists ChangesetActions with the given filters.
+func (s *Store) ListChangesetActions(ctx context.Context, opts ListChangesetActionsOpts) (as []*a8n.ChangesetAction, next int64, err error) {
+	q := listChangesetActionsQuery(&opts)
+
+	as = make([]*a8n.ChangesetAction, 0, opts.Limit)
+	_, _, err = s.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var a a8n.ChangesetAction
+		if err = scanChangesetAction(&a, sc); err != nil {
+			return 0, 0, err
+		}
+		as = append(as, &a)
+		return int64(a.ID), 1, err
+	})
+
+	if len(as) == opts.Limit {
+		next = as[len(as)-1].ID
+		as = as[:len(as)-1]
+	}
+
+	return as, next, err
+}
+
+var listChangesetActionsQueryFmtstr = `
+-- source: pkg/a8n/store.go:ListChangesetActions
+SELECT
+	id,
+	campaign_id,
+	author_id,
+	action,
+	body,
+	created_at,
+	updated_at
+FROM changeset_actions
+WHERE %s
+ORDER BY id ASC
+LIMIT %s
+`
+
+func listChangesetActionsQuery(opts *ListChangesetActionsOpts) *sqlf.Query {
+	if opts.Limit == 0 {
+		opts.Limit = defaultListLimit
+	}
+	opts.Limit++
+
+	preds := []*sqlf.Query{
+		sqlf.Sprintf("id >= %s", opts.Cursor),
+	}
+
+	if opts.CampaignID != 0 {
+		preds = append(preds, sqlf.Sprintf("campaign_id = %s", opts.CampaignID))
+	}
+
+	return sqlf.Sprintf(
+		listChangesetActionsQueryFmtstr,
+		sqlf.Join(preds, "\n AND "),
+		opts.Limit,
+	)
+}
+
+// CreateChangesetActionJobs creates the given ChangesetActionJobs.
+func (s *Store) CreateChangesetActionJobs(ctx context.Context, js ...*a8n.ChangesetActionJob) error {
+	q, err := s.createChangesetActionJobsQuery(js)
+	if err != nil {
+		return err
+	}
+
+	i := -1
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		i++
+		err = scanChangesetActionJob(js[i], sc)
+		return int64(js[i].ID), 1, err
+	})
+}
+
+const changesetActionJobBatchQueryPrefix = `
+WITH batch AS (
+  SELECT * FROM ROWS FROM (
+  json_to_recordset(%s)
+  AS (
+      id                  bigint,
+      changeset_action_id bigint,
+      changeset_id        bigint,
+      error               text,
+      started_at          timestamptz,
+      finished_at         timestamptz,
+      created_at          timestamptz,
+      updated_at          timestamptz
+    )
+  )
+  WITH ORDINALITY
+)
+`
+
+var createChangesetActionJobsQueryFmtstr = changesetActionJobBatchQueryPrefix + `,
+-- source: pkg/a8n/store.go:CreateChangesetActionJobs
+changed AS (
+  INSERT INTO changeset_action_jobs (
+    changeset_action_id,
+    changeset_id,
+    error,
+    started_at,
+    finished_at,
+    created_at,
+    updated_at
+  )
+  SELECT
+    changeset_action_id,
+    changeset_id,
+    error,
+    started_at,
+    finished_at,
+    created_at,
+    updated_at
+  FROM batch
+  RETURNING changeset_action_jobs.*
+)
+` + batchChangesetActionJobsQuerySuffix
+
+func (s *Store) createChangesetActionJobsQuery(js []*a8n.ChangesetActionJob) (*sqlf.Query, error) {
+	now := s.now()
+	for _, j := range js {
+		if j.CreatedAt.IsZero() {
+			j.CreatedAt = now
+		}
+
+		if j.UpdatedAt.IsZero() {
+			j.UpdatedAt = j.CreatedAt
+		}
+	}
+	return batchChangesetActionJobsQuery(createChangesetActionJobsQueryFmtstr, js)
+}
+
+func batchChangesetActionJobsQuery(fmtstr string, js []*a8n.ChangesetActionJob) (*sqlf.Query, error) {
+	type record struct {
+		ID                int64      `json:"id"`
+		ChangesetActionID int64      `json:"changeset_action_id"`
+		ChangesetID       int64      `json:"changeset_id"`
+		Error             string     `json:"error"`
+		StartedAt         *time.Time `json:"started_at"`
+		FinishedAt        *time.Time `json:"finished_at"`
+		CreatedAt         time.Time  `json:"created_at"`
+		UpdatedAt         time.Time  `json:"updated_at"`
+	}
+
+	records := make([]record, 0, len(js))
+
+	for _, j := range js {
+		records = append(records, record{
+			ID:                j.ID,
+			ChangesetActionID: j.ChangesetActionID,
+			ChangesetID:       j.ChangesetID,
+			Error:             j.Error,
+			StartedAt:         nullTimeColumn(j.StartedAt),
+			FinishedAt:        nullTimeColumn(j.FinishedAt),
+			CreatedAt:         j.CreatedAt,
+			UpdatedAt:         j.UpdatedAt,
+		})
+	}
+
+	batch, err := json.MarshalIndent(records, "    ", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlf.Sprintf(fmtstr, string(batch)), nil
+}
+
+// UpdateChangesetActionJobs updates the given ChangesetActionJobs.
+func (s *Store) UpdateChangesetActionJobs(ctx context.Context, js ...*a8n.ChangesetActionJob) error {
+	q, err := s.updateChangesetActionJobsQuery(js)
+	if err != nil {
+		return err
+	}
+
+	i := -1
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		i++
+		err = scanChangesetActionJob(js[i], sc)
+		return int64(js[i].ID), 1, err
+	})
+}
+
+const updateChangesetActionJobsQueryFmtstr = changesetActionJobBatchQueryPrefix + `,
+-- source: pkg/a8n/store.go:UpdateChangesetActionJobs
+changed AS (
+  UPDATE changeset_action_jobs
+  SET
+    changeset_action_id = batch.changeset_action_id,
+    changeset_id        = batch.changeset_id,
+    error               = batch.error,
+    started_at          = batch.started_at,
+    finished_at         = batch.finished_at,
+    created_at          = batch.created_at,
+    updated_at          = batch.updated_at
+  FROM batch
+  WHERE changeset_action_jobs.id = batch.id
+  RETURNING changeset_action_jobs.*
+)
+` + batchChangesetActionJobsQuerySuffix
+
+const batchChangesetActionJobsQuerySuffix = `
+SELECT
+  changed.id,
+  changed.changeset_action_id,
+  changed.changeset_id,
+  changed.error,
+  changed.started_at,
+  changed.finished_at,
+  changed.created_at,
+  changed.updated_at
+FROM changed
+LEFT JOIN batch ON batch.changeset_action_id = changed.changeset_action_id
+AND batch.changeset_id = changed.changeset_id
+ORDER BY batch.ordinality
+`
+
+func (s *Store) updateChangesetActionJobsQuery(js []*a8n.ChangesetActionJob) (*sqlf.Query, error) {
+	now := s.now()
+	for _, j := range js {
+		j.UpdatedAt = now
+	}
+	return batchChangesetActionJobsQuery(updateChangesetActionJobsQueryFmtstr, js)
+}
+
+// CountChangesetActionJobsOpts captures the query options needed for
+// counting changeset action jobs.
+type CountChangesetActionJobsOpts struct {
+	ChangesetActionID int64
+}
+
+// CountChangesetActionJobs returns the number of changeset action jobs in the database.
+func (s *Store) CountChangesetActionJobs(ctx context.Context, opts CountChangesetActionJobsOpts) (count int64, _ error) {
+	q := countChangesetActionJobsQuery(&opts)
+	return count, s.exec(ctx, q, func(sc scanner) (_, _ int64, err error) {
+		err = sc.Scan(&count)
+		return 0, count, err
+	})
+}
+
+var countChangesetActionJobsQueryFmtstr = `
+-- source: pkg/a8n/store.go:CountChangesetActionJobs
+SELECT COUNT(id)
+FROM changeset_action_jobs
+WHERE %s
+`
+
+func countChangesetActionJobsQuery(opts *CountChangesetActionJobsOpts) *sqlf.Query {
+	var preds []*sqlf.Query
+	if opts.ChangesetActionID != 0 {
+		preds = append(preds, sqlf.Sprintf("changeset_action_id = %s", opts.ChangesetActionID))
+	}
+
+	if len(preds) == 0 {
+		preds = append(preds, sqlf.Sprintf("TRUE"))
+	}
+
+	return sqlf.Sprintf(countChangesetActionJobsQueryFmtstr, sqlf.Join(preds, "\n AND "))
+}
+
+// ListChangesetActionJobsOpts captures the query options needed for
+// listing changeset action jobs.
+type ListChangesetActionJobsOpts struct {
+	ChangesetActionID int64
+	Cursor            int64
+	Limit             int
+}
+
+// ListChangesetActionJobs lists ChangesetActionJobs with the given filters.
+func (s *Store) ListChangesetActionJobs(ctx context.Context, opts ListChangesetActionJobsOpts) (js []*a8n.ChangesetActionJob, next int64, err error) {
+	q := listChangesetActionJobsQuery(&opts)
+
+	js = make([]*a8n.ChangesetActionJob, 0, opts.Limit)
+	_, _, err = s.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var j a8n.ChangesetActionJob
+		if err = scanChangesetActionJob(&j, sc); err != nil {
+			return 0, 0, err
+		}
+		js = append(js, &j)
+		return int64(j.ID), 1, err
+	})
+
+	if len(js) == opts.Limit {
+		next = js[len(js)-1].ID
+		js = js[:len(js)-1]
+	}
+
+	return js, next, err
+}
+
+var listChangesetActionJobsQueryFmtstr = `
+-- source: pkg/a8n/store.go:ListChangesetActionJobs
+SELECT
+	id,
+	changeset_action_id,
+	changeset_id,
+	error,
+	started_at,
+	finished_at,
+	created_at,
+	updated_at
+FROM changeset_action_jobs
+WHERE %s
+ORDER BY id ASC
+LIMIT %s
+`
+
+func listChangesetActionJobsQuery(opts *ListChangesetActionJobsOpts) *sqlf.Query {
+	if opts.Limit == 0 {
+		opts.Limit = defaultListLimit
+	}
+	opts.Limit++
+
+	preds := []*sqlf.Query{
+		sqlf.Sprintf("id >= %s", opts.Cursor),
+	}
+
+	if opts.ChangesetActionID != 0 {
+		preds = append(preds, sqlf.Sprintf("changeset_action_id = %s", opts.ChangesetActionID))
+	}
+
+	return sqlf.Sprintf(
+		listChangesetActionJobsQueryFmtstr,
+		sqlf.Join(preds, "\n AND "),
+		opts.Limit,
+	)
+}
+
+// ClaimStaleChangesetActionJobs returns the unfinished ChangesetActionJobs
+// that haven't been updated since before the given time, after setting their
+// updated_at to now. Each stale job is claimed by a single caller, which is
+// then in charge of resuming it.
+func (s *Store) ClaimStaleChangesetActionJobs(ctx context.Context, before time.Time) (js []*a8n.ChangesetActionJob, err error) {
+	q := sqlf.Sprintf(claimStaleChangesetActionJobsQueryFmtstr, s.now(), before)
+
+	err = s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var j a8n.ChangesetActionJob
+		if err = scanChangesetActionJob(&j, sc); err != nil {
+			return 0, 0, err
+		}
+		js = append(js, &j)
+		return int64(j.ID), 1, err
+	})
+
+	return js, err
+}
+
+var claimStaleChangesetActionJobsQueryFmtstr = `
+-- source: pkg/a8n/store.go:ClaimStaleChangesetActionJobs
+UPDATE changeset_action_jobs
+SET updated_at = %s
+WHERE finished_at IS NULL
+AND updated_at < %s
+RETURNING
+	id,
+	changeset_action_id,
+	changeset_id,
+	error,
+	started_at,
+	finished_at,
+	created_at,
+	updated_at
+`
+
+// GetChangesetActionStatus returns the aggregate status of the
+// ChangesetActionJobs of the ChangesetAction with the given ID.
+func (s *Store) GetChangesetActionStatus(ctx context.Context, id int64) (*a8n.BackgroundProcessStatus, error) {
+	return s.queryBackgroundProcessStatus(ctx, sqlf.Sprintf(
+		getChangesetActionStatusQueryFmtstr,
+		id,
+	))
+}
+
+var getChangesetActionStatusQueryFmtstr = `
+-- source: pkg/a8n/store.go:GetChangesetActionStatus
+SELECT
+  COUNT(*) AS total,
+  COUNT(*) FILTER (WHERE finished_at IS NOT NULL) AS completed,
+  COUNT(*) FILTER (WHERE finished_at IS NULL) AS pending,
+  array_agg(error) FILTER (WHERE error != '') AS errors
+FROM changeset_action_jobs
+WHERE changeset_action_id = %s
+`
+
+// UpsertChangesetEvents creates the given ChangesetEvents, or updates
+// them if an event with the same ChangesetID, Kind and Key already exists.
+func (s *Store) UpsertChangesetEvents(ctx context.Context, es ...*a8n.ChangesetEvent) error {
+	q, err := s.upsertChangesetEventsQuery(es)
+	if err != nil {
+		return err
+	}
+
+	i := -1
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		i++
+		err = scanChangesetEvent(es[i], sc)
+		return int64(es[i].ID), 1, err
+	})
+}
+
+var upsertChangesetEventsQueryFmtstr = `
+WITH batch AS (
+  SELECT * FROM ROWS FROM (
+  json_to_recordset(%s)
+  AS (
+      changeset_id bigint,
+      kind         text,
+      key          text,
+      actor        text,
+      "timestamp"  timestamptz,
+      created_at   timestamptz,
+      updated_at   timestamptz
+    )
+  )
+  WITH ORDINALITY
+),
+-- source: pkg/a8n/store.go:UpsertChangesetEvents
+changed AS (
+  INSERT INTO changeset_events (
+    changeset_id,
+    kind,
+    key,
+    actor,
+    "timestamp",
+    created_at,
+    updated_at
+  )
+  SELECT
+    changeset_id,
+    kind,
+    key,
+    actor,
+    "timestamp",
+    created_at,
+    updated_at
+  FROM batch
+  ON CONFLICT ON CONSTRAINT changeset_events_changeset_id_kind_key_key
+  DO UPDATE SET
+    actor       = excluded.actor,
+    "timestamp" = excluded."timestamp",
+    updated_at  = excluded.updated_at
+  RETURNING changeset_events.*
+)
+SELECT
+  changed.id,
+  changed.changeset_id,
+  changed.kind,
+  changed.key,
+  changed.actor,
+  changed."timestamp",
+  changed.created_at,
+  changed.updated_at
+FROM changed
+LEFT JOIN batch ON batch.changeset_id = changed.changeset_id
+AND batch.kind = changed.kind
+AND batch.key = changed.key
+ORDER BY batch.ordinality
+`
+
+func (s *Store) upsertChangesetEventsQuery(es []*a8n.ChangesetEvent) (*sqlf.Query, error) {
+	type record struct {
+		ChangesetID int64     `json:"changeset_id"`
+		Kind        string    `json:"kind"`
+		Key         string    `json:"key"`
+		Actor       string    `json:"actor"`
+		Timestamp   time.Time `json:"timestamp"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	}
+
+	now := s.now()
+	records := make([]record, 0, len(es))
+
+	for _, e := range es {
+		if e.CreatedAt.IsZero() {
+			e.CreatedAt = now
+		}
+		e.UpdatedAt = now
+
+		records = append(records, record{
+			ChangesetID: e.ChangesetID,
+			Kind:        string(e.Kind),
+			Key:         e.Key,
+			Actor:       e.Actor,
+			Timestamp:   e.Timestamp,
+			CreatedAt:   e.CreatedAt,
+			UpdatedAt:   e.UpdatedAt,
+		})
+	}
+
+	batch, err := json.MarshalIndent(records, "    ", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlf.Sprintf(upsertChangesetEventsQueryFmtstr, string(batch)), nil
+}
+
+// ListChangesetEventsOpts captures the query options needed for
+// listing changeset events.
+type ListChangesetEventsOpts struct {
+	Cursor       int64
+	Limit        int
+	ChangesetIDs []int64
+	CampaignID   int64
+}
+
+// ListChangesetEvents lists ChangesetEvents with the given filters.
+func (s *Store) ListChangesetEvents(ctx context.Context, opts ListChangesetEventsOpts) (es []*a8n.ChangesetEvent, next int64, err error) {
+	q := listChangesetEventsQuery(&opts)
+
+	es = make([]*a8n.ChangesetEvent, 0, opts.Limit)
+	_, _, err = s.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var e a8n.ChangesetEvent
+		if err = scanChangesetEvent(&e, sc); err != nil {
+			return 0, 0, err
+		}
+		es = append(es, &e)
+		return int64(e.ID), 1, err
+	})
+
+	if len(es) == opts.Limit {
+		next = es[len(es)-1].ID
+		es = es[:len(es)-1]
+	}
+
+	return es, next, err
+}
+
+var listChangesetEventsQueryFmtstr = `
+-- source: pkg/a8n/store.go:ListChangesetEvents
+SELECT
+	id,
+	changeset_id,
+	kind,
+	key,
+	actor,
+	"timestamp",
+	created_at,
+	updated_at
+FROM changeset_events
+WHERE %s
+ORDER BY id ASC
+LIMIT %s
+`
+
+func listChangesetEventsQuery(opts *ListChangesetEventsOpts) *sqlf.Query {
+	if opts.Limit == 0 {
+		opts.Limit = defaultListLimit
+	}
+	opts.Limit++
+
+	preds := []*sqlf.Query{
+		sqlf.Sprintf("id >= %s", opts.Cursor),
+	}
+
+	if len(opts.ChangesetIDs) > 0 {
+		preds = append(preds, sqlf.Sprintf("changeset_id = ANY(%s)", pq.Array(opts.ChangesetIDs)))
+	}
+
+	if opts.CampaignID != 0 {
+		preds = append(preds, sqlf.Sprintf(
+			"changeset_id IN (SELECT id FROM changesets WHERE campaign_ids ? %s)",
+			opts.CampaignID,
+		))
+	}
+
+	return sqlf.Sprintf(
+		listChangesetEventsQueryFmtstr,
+		sqlf.Join(preds, "\n AND "),
+		opts.Limit,
+	)
+}
+
+func (s *Store) exec(ctx context.Context, q *sqlf.Query, sc scanFunc) error {
+	_, _, err := s.query(ctx, q, sc)
+	return err
+}
+
+func (s *Store) query(ctx context.Context, q *sqlf.Query, sc scanFunc) (last, count int64, err error) {
+	rows, err := s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return 0, 0, err
+	}
+	return scanAll(rows, sc)
+}
+
+// scanner captures the Scan method of sql.Rows and sql.Row
+type scanner interface {
+	Scan(dst ...interface{}) error
+}
+
+// a scanFunc scans one or more rows from a scanner, returning
+// the last id column scanned and the count of scanned rows.
+type scanFunc func(scanner) (last, count int64, err error)
+
+func scanAll(rows *sql.Rows, scan scanFunc) (last, count int64, err error) {
+	defer closeErr(rows, &err)
+
+	last = -1
+	for rows.Next() {
+		var n int64
+		if last, n, err = scan(rows); err != nil {
+			return last, count, err
+		}
+		count += n
+	}
+
+	return last, count, rows.Err()
+}
+
+func closeErr(c io.Closer, err *error) {
+	if e := c.Close(); err != nil && *err == nil {
+		*err = e
+	}
+}
+
+func scanChangeset(t *a8n.Changeset, s scanner) error {
+	var metadata json.RawMessage
+
+	err := s.Scan(
+		&t.ID,
+		&t.RepoID,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&metadata,
+		&dbutil.JSONInt64Set{Set: &t.CampaignIDs},
+		&t.ExternalID,
+		&t.ExternalServiceType,
+	)
+	if err != nil {
+		return err
+	}
+
+	switch t.ExternalServiceType {
+	case github.ServiceType:
+		t.Metadata = new(github.PullRequest)
+	case gitlab.ServiceType:
+		t.Metadata = new(gitlab.MergeRequest)
+	case bitbucketserver.ServiceType:
+		t.Metadata = new(bitbucketserver.PullRequest)
+	default:
+		return nil
+	}
+
+	if err = json.Unmarshal(metadata, t.Metadata); err != nil {
+		return errors.Wrapf(err, "scanChangeset: failed to unmarshal %q metadata", t.ExternalServiceType)
+	}
+
+	return nil
+}
+
+func scanCampaign(c *a8n.Campaign, s scanner) error {
+	return s.Scan(
+		&c.ID,
+		&c.Name,
+		&c.Description,
+		&c.AuthorID,
+		&dbutil.NullInt32{N: &c.NamespaceUserID},
+		&dbutil.NullInt32{N: &c.NamespaceOrgID},
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&dbutil.JSONInt64Set{Set: &c.ChangesetIDs},
+		&dbutil.NullInt64{N: &c.CampaignPlanID},
+	)
+}
+
+func scanCampaignPlan(c *a8n.CampaignPlan, s scanner) error {
+	var spec json.RawMessage
+
+	err := s.Scan(
+		&c.ID,
+		&spec,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(spec, &c.Spec)
+}
+
+func scanCampaignJob(c *a8n.CampaignJob, s scanner) error {
+	return s.Scan(
+		&c.ID,
+		&c.CampaignPlanID,
+		&c.RepoID,
+		&c.Rev,
+		&c.BaseRef,
+		&c.Diff,
+		&c.Error,
+		&dbutil.NullTime{Time: &c.StartedAt},
+		&dbutil.NullTime{Time: &c.FinishedAt},
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+}
+
+func scanChangesetJob(c *a8n.ChangesetJob, s scanner) error {
+	return s.Scan(
+		&c.ID,
+		&c.CampaignID,
+		&c.CampaignJobID,
+		&dbutil.NullInt64{N: &c.ChangesetID},
+		&c.Error,
+		&dbutil.NullTime{Time: &c.StartedAt},
+		&dbutil.NullTime{Time: &c.FinishedAt},
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+}
+
+func scanChangesetAction(a *a8n.ChangesetAction, s scanner) error {
+	return s.Scan(
+		&a.ID,
+		&a.CampaignID,
+		&a.AuthorID,
+		&a.Action,
+		&a.Body,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+}
+
+func scanChangesetActionJob(j *a8n.ChangesetActionJob, s scanner) error {
+	return s.Scan(
+		&j.ID,
+		&j.ChangesetActionID,
+		&j.ChangesetID,
+		&j.Error,
+		&dbutil.NullTime{Time: &j.StartedAt},
+		&dbutil.NullTime{Time: &j.FinishedAt},
+		&j.CreatedAt,
+		&j.UpdatedAt,
+	)
+}
+
+func scanChangesetEvent(e *a8n.ChangesetEvent, s scanner) error {
+	return s.Scan(
+		&e.ID,
+		&e.ChangesetID,
+		&e.Kind,
+		&e.Key,
+		&e.Actor,
+		&e.Timestamp,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+}
+
+func metadataColumn(metadata interface{}) (msg json.RawMessage, err error) {
+	switch m := metadata.(type) {
+	case nil:
+		msg = json.RawMessage("{}")
+	case string:
+		msg = json.RawMessage(m)
+	case []byte:
+		msg = m
+	case json.RawMessage:
+		msg = m
+	default:
+		msg, err = json.MarshalIndent(m, "        ", "    ")
+	}
+	return
+}
+
+func nullTimeColumn(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
 }
 
 func jsonSetColumn(ids []int64) ([]byte, error) {
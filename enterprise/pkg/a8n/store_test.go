@@ -249,6 +249,20 @@ func TestStore(t *testing.T) {
 				}
 			})
 
+			t.Run("ForUpdate", func(t *testing.T) {
+				want := campaigns[0]
+				opts := GetCampaignOpts{ID: want.ID, ForUpdate: true}
+
+				have, err := s.GetCampaign(ctx, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if diff := cmp.Diff(have, want); diff != "" {
+					t.Fatal(diff)
+				}
+			})
+
 			t.Run("NoResults", func(t *testing.T) {
 				opts := GetCampaignOpts{ID: 0xdeadbeef}
 
@@ -426,6 +440,21 @@ func TestStore(t *testing.T) {
 				}
 			}
 
+			{
+				have, _, err := s.ListChangesets(ctx, ListChangesetsOpts{
+					RepoID:      42,
+					ExternalIDs: []string{changesets[1].ExternalID},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := changesets[1:2]
+				if diff := cmp.Diff(have, want); diff != "" {
+					t.Fatal(diff)
+				}
+			}
+
 			{
 				var cursor int64
 				for i := 1; i <= len(changesets); i++ {
@@ -547,4 +576,508 @@ func TestStore(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run("CampaignPlans", func(t *testing.T) {
+		plan := &a8n.CampaignPlan{
+			Spec: a8n.CampaignPlanSpec{
+				ScopeQuery:      "repo:github.com/sourcegraph/sourcegraph",
+				MatchTemplate:   "fmt.Sprintf(\":[1]\")",
+				RewriteTemplate: "fmt.Sprint(\":[1]\")",
+				FileExtension:   ".go",
+			},
+		}
+
+		t.Run("Create", func(t *testing.T) {
+			want := plan.Clone()
+
+			if err := s.CreateCampaignPlan(ctx, plan); err != nil {
+				t.Fatal(err)
+			}
+
+			if plan.ID == 0 {
+				t.Fatal("ID should not be zero")
+			}
+
+			want.ID = plan.ID
+			want.CreatedAt = now
+			want.UpdatedAt = now
+
+			if diff := cmp.Diff(plan, want); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+
+		t.Run("Get", func(t *testing.T) {
+			have, err := s.GetCampaignPlan(ctx, GetCampaignPlanOpts{ID: plan.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(have, plan); diff != "" {
+				t.Fatal(diff)
+			}
+
+			_, err = s.GetCampaignPlan(ctx, GetCampaignPlanOpts{ID: 0xdeadbeef})
+			if have, want := err, ErrNoResults; have != want {
+				t.Fatalf("have err %v, want %v", have, want)
+			}
+		})
+
+		jobs := make([]*a8n.CampaignJob, 0, 3)
+
+		t.Run("CreateJobs", func(t *testing.T) {
+			for i := 0; i < cap(jobs); i++ {
+				jobs = append(jobs, &a8n.CampaignJob{
+					CampaignPlanID: plan.ID,
+					RepoID:         int32(42 + i),
+				})
+			}
+
+			if err := s.CreateCampaignJobs(ctx, jobs...); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, have := range jobs {
+				if have.ID == 0 {
+					t.Fatal("id should not be zero")
+				}
+
+				if have.CreatedAt != now || have.UpdatedAt != now {
+					t.Fatalf("have timestamps %v, %v, want %v", have.CreatedAt, have.UpdatedAt, now)
+				}
+			}
+
+			status, err := s.GetCampaignPlanStatus(ctx, plan.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := &a8n.BackgroundProcessStatus{Total: 3, Pending: 3}
+			if diff := cmp.Diff(status, want); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+
+		t.Run("UpdateJobs", func(t *testing.T) {
+			for i, j := range jobs {
+				j.Rev = "deadbeef"
+				j.BaseRef = "refs/heads/master"
+				j.StartedAt = now
+				j.FinishedAt = now
+				switch i {
+				case 0:
+					j.Error = "boom"
+				case 1:
+					j.Diff = "--- a/main.go\n+++ b/main.go\n"
+				}
+			}
+
+			want := make([]*a8n.CampaignJob, 0, len(jobs))
+			for _, j := range jobs {
+				want = append(want, j.Clone())
+			}
+
+			if err := s.UpdateCampaignJobs(ctx, jobs...); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(jobs, want); diff != "" {
+				t.Fatal(diff)
+			}
+
+			status, err := s.GetCampaignPlanStatus(ctx, plan.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wantStatus := &a8n.BackgroundProcessStatus{
+				Total:     3,
+				Completed: 3,
+				Errors:    []string{"boom"},
+			}
+			if diff := cmp.Diff(status, wantStatus); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+
+		t.Run("ListJobs", func(t *testing.T) {
+			have, next, err := s.ListCampaignJobs(ctx, ListCampaignJobsOpts{
+				CampaignPlanID: plan.ID,
+				OnlyWithDiff:   true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if next != 0 {
+				t.Fatalf("have next %d, want 0", next)
+			}
+
+			if diff := cmp.Diff(have, jobs[1:2]); diff != "" {
+				t.Fatal(diff)
+			}
+
+			count, err := s.CountCampaignJobs(ctx, CountCampaignJobsOpts{CampaignPlanID: plan.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if have, want := count, int64(len(jobs)); have != want {
+				t.Fatalf("have count: %d, want: %d", have, want)
+			}
+		})
+
+		t.Run("ClaimStaleJobs", func(t *testing.T) {
+			stale := &a8n.CampaignJob{
+				CampaignPlanID: plan.ID,
+				RepoID:         45,
+				CreatedAt:      now.Add(-3 * time.Hour),
+			}
+
+			if err := s.CreateCampaignJobs(ctx, stale); err != nil {
+				t.Fatal(err)
+			}
+
+			have, err := s.ClaimStaleCampaignJobs(ctx, now.Add(-time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := stale.Clone()
+			want.UpdatedAt = now
+			if diff := cmp.Diff(have, []*a8n.CampaignJob{want}); diff != "" {
+				t.Fatal(diff)
+			}
+
+			// A claimed job isn't stale anymore.
+			have, err = s.ClaimStaleCampaignJobs(ctx, now.Add(-time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(have) != 0 {
+				t.Fatalf("have claimed jobs %+v, want none", have)
+			}
+		})
+	})
+
+	t.Run("ChangesetEvents", func(t *testing.T) {
+		changeset := &a8n.Changeset{
+			RepoID:              42,
+			Metadata:            &github.PullRequest{},
+			CampaignIDs:         []int64{},
+			ExternalID:          "changeset-events",
+			ExternalServiceType: "github",
+		}
+
+		if err := s.CreateChangesets(ctx, changeset); err != nil {
+			t.Fatal(err)
+		}
+
+		events := []*a8n.ChangesetEvent{
+			{
+				ChangesetID: changeset.ID,
+				Kind:        a8n.ChangesetEventKindClosed,
+				Key:         "closed-event-id",
+				Actor:       "mrnugget",
+				Timestamp:   now.Add(-time.Hour),
+			},
+			{
+				ChangesetID: changeset.ID,
+				Kind:        a8n.ChangesetEventKindReopened,
+				Key:         "reopened-event-id",
+				Actor:       "mrnugget",
+				Timestamp:   now,
+			},
+		}
+
+		t.Run("Upsert", func(t *testing.T) {
+			if err := s.UpsertChangesetEvents(ctx, events...); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, e := range events {
+				if e.ID == 0 {
+					t.Fatal("id should not be zero")
+				}
+			}
+
+			// Upserting the same events again must not create new rows.
+			want := make([]*a8n.ChangesetEvent, 0, len(events))
+			for _, e := range events {
+				want = append(want, e.Clone())
+			}
+
+			have := make([]*a8n.ChangesetEvent, 0, len(events))
+			for _, e := range events {
+				e := e.Clone()
+				e.ID = 0
+				have = append(have, e)
+			}
+
+			if err := s.UpsertChangesetEvents(ctx, have...); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(have, want); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+
+		t.Run("List", func(t *testing.T) {
+			have, next, err := s.ListChangesetEvents(ctx, ListChangesetEventsOpts{
+				ChangesetIDs: []int64{changeset.ID},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if next != 0 {
+				t.Fatalf("have next %d, want 0", next)
+			}
+
+			if diff := cmp.Diff(have, events); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	})
+
+	t.Run("ChangesetActions", func(t *testing.T) {
+		campaign := &a8n.Campaign{
+			Name:            "Close stale changesets",
+			AuthorID:        23,
+			NamespaceUserID: 42,
+		}
+
+		if err := s.CreateCampaign(ctx, campaign); err != nil {
+			t.Fatal(err)
+		}
+
+		changesets := make([]*a8n.Changeset, 0, 2)
+		for i := 0; i < cap(changesets); i++ {
+			changesets = append(changesets, &a8n.Changeset{
+				RepoID:              42,
+				Metadata:            &github.PullRequest{},
+				CampaignIDs:         []int64{campaign.ID},
+				ExternalID:          fmt.Sprintf("changeset-actions-%d", i),
+				ExternalServiceType: "github",
+			})
+		}
+
+		if err := s.CreateChangesets(ctx, changesets...); err != nil {
+			t.Fatal(err)
+		}
+
+		action := &a8n.ChangesetAction{
+			CampaignID: campaign.ID,
+			AuthorID:   23,
+			Action:     a8n.ChangesetActionComment,
+			Body:       "Friendly reminder to review this",
+		}
+
+		t.Run("Create", func(t *testing.T) {
+			want := action.Clone()
+
+			if err := s.CreateChangesetAction(ctx, action); err != nil {
+				t.Fatal(err)
+			}
+
+			if action.ID == 0 {
+				t.Fatal("ID should not be zero")
+			}
+
+			want.ID = action.ID
+			want.CreatedAt = now
+			want.UpdatedAt = now
+
+			if diff := cmp.Diff(action, want); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+
+		t.Run("Get", func(t *testing.T) {
+			have, err := s.GetChangesetAction(ctx, GetChangesetActionOpts{ID: action.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(have, action); diff != "" {
+				t.Fatal(diff)
+			}
+
+			_, err = s.GetChangesetAction(ctx, GetChangesetActionOpts{ID: 0xdeadbeef})
+			if have, want := err, ErrNoResults; have != want {
+				t.Fatalf("have err %v, want %v", have, want)
+			}
+		})
+
+		t.Run("List", func(t *testing.T) {
+			have, next, err := s.ListChangesetActions(ctx, ListChangesetActionsOpts{CampaignID: campaign.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if next != 0 {
+				t.Fatalf("have next %d, want 0", next)
+			}
+
+			if diff := cmp.Diff(have, []*a8n.ChangesetAction{action}); diff != "" {
+				t.Fatal(diff)
+			}
+
+			count, err := s.CountChangesetActions(ctx, CountChangesetActionsOpts{CampaignID: campaign.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if have, want := count, int64(1); have != want {
+				t.Fatalf("have count: %d, want: %d", have, want)
+			}
+		})
+
+		jobs := make([]*a8n.ChangesetActionJob, 0, len(changesets))
+
+		t.Run("CreateJobs", func(t *testing.T) {
+			for _, c := range changesets {
+				jobs = append(jobs, &a8n.ChangesetActionJob{
+					ChangesetActionID: action.ID,
+					ChangesetID:       c.ID,
+				})
+			}
+
+			if err := s.CreateChangesetActionJobs(ctx, jobs...); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, have := range jobs {
+				if have.ID == 0 {
+					t.Fatal("id should not be zero")
+				}
+
+				if have.CreatedAt != now || have.UpdatedAt != now {
+					t.Fatalf("have timestamps %v, %v, want %v", have.CreatedAt, have.UpdatedAt, now)
+				}
+			}
+
+			status, err := s.GetChangesetActionStatus(ctx, action.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := &a8n.BackgroundProcessStatus{Total: 2, Pending: 2}
+			if diff := cmp.Diff(status, want); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+
+		t.Run("UpdateJobs", func(t *testing.T) {
+			for i, j := range jobs {
+				j.StartedAt = now
+				j.FinishedAt = now
+				if i == 0 {
+					j.Error = "boom"
+				}
+			}
+
+			want := make([]*a8n.ChangesetActionJob, 0, len(jobs))
+			for _, j := range jobs {
+				want = append(want, j.Clone())
+			}
+
+			if err := s.UpdateChangesetActionJobs(ctx, jobs...); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(jobs, want); diff != "" {
+				t.Fatal(diff)
+			}
+
+			status, err := s.GetChangesetActionStatus(ctx, action.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wantStatus := &a8n.BackgroundProcessStatus{
+				Total:     2,
+				Completed: 2,
+				Errors:    []string{"boom"},
+			}
+			if diff := cmp.Diff(status, wantStatus); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+
+		t.Run("ListJobs", func(t *testing.T) {
+			have, next, err := s.ListChangesetActionJobs(ctx, ListChangesetActionJobsOpts{
+				ChangesetActionID: action.ID,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if next != 0 {
+				t.Fatalf("have next %d, want 0", next)
+			}
+
+			if diff := cmp.Diff(have, jobs); diff != "" {
+				t.Fatal(diff)
+			}
+
+			count, err := s.CountChangesetActionJobs(ctx, CountChangesetActionJobsOpts{
+				ChangesetActionID: action.ID,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if have, want := count, int64(len(jobs)); have != want {
+				t.Fatalf("have count: %d, want: %d", have, want)
+			}
+		})
+
+		t.Run("ClaimStaleJobs", func(t *testing.T) {
+			changeset := &a8n.Changeset{
+				RepoID:              42,
+				Metadata:            &github.PullRequest{},
+				CampaignIDs:         []int64{campaign.ID},
+				ExternalID:          "changeset-actions-stale",
+				ExternalServiceType: "github",
+			}
+
+			if err := s.CreateChangesets(ctx, changeset); err != nil {
+				t.Fatal(err)
+			}
+
+			stale := &a8n.ChangesetActionJob{
+				ChangesetActionID: action.ID,
+				ChangesetID:       changeset.ID,
+				CreatedAt:         now.Add(-3 * time.Hour),
+			}
+
+			if err := s.CreateChangesetActionJobs(ctx, stale); err != nil {
+				t.Fatal(err)
+			}
+
+			have, err := s.ClaimStaleChangesetActionJobs(ctx, now.Add(-time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := stale.Clone()
+			want.UpdatedAt = now
+			if diff := cmp.Diff(have, []*a8n.ChangesetActionJob{want}); diff != "" {
+				t.Fatal(diff)
+			}
+
+			// A claimed job isn't stale anymore.
+			have, err = s.ClaimStaleChangesetActionJobs(ctx, now.Add(-time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(have) != 0 {
+				t.Fatalf("have claimed jobs %+v, want none", have)
+			}
+		})
+	})
 }
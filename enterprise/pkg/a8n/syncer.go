@@ -12,6 +12,11 @@ import (
 	"gopkg.in/inconshreveable/log15.v2"
 )
 
+// webhookSafetyNetInterval is the interval at which changesets of code
+// hosts with configured webhooks are still polled, in case webhook events
+// get lost.
+const webhookSafetyNetInterval = time.Hour
+
 // A ChangesetSyncer periodically sync the metadata of the changesets
 // saved in the database
 type ChangesetSyncer struct {
@@ -39,6 +44,11 @@ func (s *ChangesetSyncer) syncAllChangesets(ctx context.Context) {
 		return
 	}
 
+	if cs, err = s.changesetsToPoll(ctx, cs); err != nil {
+		log15.Error("ChangesetSyncer.changesetsToPoll", "error", err)
+		return
+	}
+
 	if err := s.Sync(ctx, cs...); err != nil {
 		log15.Error("ChangesetSyncer", "error", err)
 	}
@@ -126,19 +136,122 @@ func (s *ChangesetSyncer) Sync(ctx context.Context, cs ...*a8n.Changeset) (err e
 		})
 	}
 
+	prev := make(map[*a8n.Changeset]*a8n.Changeset, len(cs))
+	for _, c := range cs {
+		prev[c] = c.Clone()
+	}
+
 	for _, b := range batches {
 		if err = b.LoadChangesets(ctx, b.Changesets...); err != nil {
 			return err
 		}
 	}
 
+	events := changesetEvents(cs, prev, s.Store.now())
+
 	if err = s.Store.UpdateChangesets(ctx, cs...); err != nil {
 		return err
 	}
 
+	if len(events) > 0 {
+		if err = s.Store.UpsertChangesetEvents(ctx, events...); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// changesetEvents returns the ChangesetEvents derived from the freshly
+// loaded metadata of the given changesets. For changesets whose metadata
+// doesn't carry their history, the state transitions from their previous
+// snapshot in prev are recorded as observed at the given time.
+func changesetEvents(cs []*a8n.Changeset, prev map[*a8n.Changeset]*a8n.Changeset, observed time.Time) []*a8n.ChangesetEvent {
+	type key struct {
+		changesetID int64
+		kind        a8n.ChangesetEventKind
+		key         string
+	}
+
+	var events []*a8n.ChangesetEvent
+	seen := map[key]bool{}
+
+	for _, c := range cs {
+		es := c.Events()
+		if es == nil {
+			es = c.StateTransitions(prev[c], observed)
+		}
+
+		for _, e := range es {
+			k := key{e.ChangesetID, e.Kind, e.Key}
+			if !seen[k] {
+				seen[k] = true
+				events = append(events, e)
+			}
+		}
+	}
+
+	return events
+}
+
+// changesetsToPoll returns the given changesets without the ones that are
+// kept up to date by webhook events and were synced within the
+// webhookSafetyNetInterval.
+func (s *ChangesetSyncer) changesetsToPoll(ctx context.Context, cs []*a8n.Changeset) ([]*a8n.Changeset, error) {
+	if len(cs) == 0 {
+		return cs, nil
+	}
+
+	var repoIDs []uint32
+	seen := map[uint32]bool{}
+	for _, c := range cs {
+		if id := uint32(c.RepoID); !seen[id] {
+			seen[id] = true
+			repoIDs = append(repoIDs, id)
+		}
+	}
+
+	es, err := s.ReposStore.ListExternalServices(ctx, repos.StoreListExternalServicesArgs{RepoIDs: repoIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := map[int64]bool{}
+	for _, e := range es {
+		secret, _, err := webhookConfig(e)
+		if err != nil {
+			return nil, err
+		}
+		webhooks[e.ID] = secret != ""
+	}
+
+	rs, err := s.ReposStore.ListRepos(ctx, repos.StoreListReposArgs{IDs: repoIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	webhookRepos := map[int32]bool{}
+	for _, r := range rs {
+		for _, id := range r.ExternalServiceIDs() {
+			if webhooks[id] {
+				webhookRepos[int32(r.ID)] = true
+				break
+			}
+		}
+	}
+
+	now := s.Store.now()
+	poll := cs[:0]
+	for _, c := range cs {
+		if webhookRepos[c.RepoID] && now.Sub(c.UpdatedAt) < webhookSafetyNetInterval {
+			continue
+		}
+		poll = append(poll, c)
+	}
+
+	return poll, nil
+}
+
 func (s *ChangesetSyncer) listAllChangesets(ctx context.Context) (all []*a8n.Changeset, err error) {
 	for cursor := int64(-1); cursor != 0; {
 		opts := ListChangesetsOpts{Cursor: cursor, Limit: 1000}
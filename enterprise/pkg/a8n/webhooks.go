@@ -0,0 +1,371 @@
+package a8n
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/pkg/a8n"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
+	"github.com/sourcegraph/sourcegraph/pkg/httpcli"
+	"github.com/sourcegraph/sourcegraph/schema"
+	"gopkg.in/inconshreveable/log15.v2"
+)
+
+// maxWebhookPayloadSize is the maximum size of a webhook event payload,
+// matching the limit GitHub imposes on its own payloads.
+const maxWebhookPayloadSize = 25 << 20
+
+// A Webhook receives the webhook events of a code host and syncs the
+// Changesets affected by them, so that they are kept up to date without
+// polling the code host. Events are verified with the webhook secret of one
+// of the code host's external services.
+type Webhook struct {
+	Store       *Store
+	ReposStore  repos.Store
+	HTTPFactory *httpcli.Factory
+}
+
+// GitHubWebhook receives GitHub webhook events about pull requests,
+// reviews, check runs and commit statuses.
+type GitHubWebhook struct{ *Webhook }
+
+// NewGitHubWebhook returns a new GitHubWebhook.
+func NewGitHubWebhook(store *Store, reposStore repos.Store, cf *httpcli.Factory) *GitHubWebhook {
+	return &GitHubWebhook{&Webhook{Store: store, ReposStore: reposStore, HTTPFactory: cf}}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *GitHubWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, github.ServiceType, func(secret string, payload []byte) bool {
+		return validGitHubSignature(secret, r.Header.Get("X-Hub-Signature"), payload)
+	}, func(payload []byte) (*webhookEvent, error) {
+		return parseGitHubEvent(r.Header.Get("X-GitHub-Event"), payload)
+	})
+}
+
+// GitLabWebhook receives GitLab webhook events about merge requests,
+// pipelines and comments.
+type GitLabWebhook struct{ *Webhook }
+
+// NewGitLabWebhook returns a new GitLabWebhook.
+func NewGitLabWebhook(store *Store, reposStore repos.Store, cf *httpcli.Factory) *GitLabWebhook {
+	return &GitLabWebhook{&Webhook{Store: store, ReposStore: reposStore, HTTPFactory: cf}}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *GitLabWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, gitlab.ServiceType, func(secret string, _ []byte) bool {
+		return validGitLabToken(secret, r.Header.Get("X-Gitlab-Token"))
+	}, func(payload []byte) (*webhookEvent, error) {
+		return parseGitLabEvent(r.Header.Get("X-Gitlab-Event"), payload)
+	})
+}
+
+// A webhookEvent identifies the Changesets affected by a webhook event.
+type webhookEvent struct {
+	// repo is the external ID of the repository of the affected Changesets.
+	repo string
+	// externalIDs are the external IDs of the affected Changesets. All
+	// Changesets of the repository are affected if it's nil.
+	externalIDs []string
+}
+
+func (h *Webhook) serve(
+	w http.ResponseWriter,
+	r *http.Request,
+	kind string,
+	verify func(secret string, payload []byte) bool,
+	parse func(payload []byte) (*webhookEvent, error),
+) {
+	payload, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx := r.Context()
+
+	// 🚨 SECURITY: Only events verified with the webhook secret of an
+	// external service are processed.
+	serviceID, err := h.verify(ctx, kind, func(secret string) bool {
+		return verify(secret, payload)
+	})
+	if err != nil {
+		log15.Error("Webhook.verify", "kind", kind, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if serviceID == "" {
+		http.Error(w, "webhook event could not be verified", http.StatusUnauthorized)
+		return
+	}
+
+	ev, err := parse(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if ev == nil {
+		// The event doesn't affect any Changesets.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	spec := api.ExternalRepoSpec{ID: ev.repo, ServiceType: kind, ServiceID: serviceID}
+	if err = h.sync(ctx, spec, ev.externalIDs); err != nil {
+		log15.Error("Webhook.sync", "kind", kind, "repo", ev.repo, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// verify returns the external service ID (i.e. the normalized base URL) of
+// the first external service of the given kind with a webhook secret that
+// verifies the event. It returns an empty string if there's none.
+func (h *Webhook) verify(ctx context.Context, kind string, verify func(secret string) bool) (string, error) {
+	es, err := h.ReposStore.ListExternalServices(ctx, repos.StoreListExternalServicesArgs{
+		Kinds: []string{kind},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, e := range es {
+		secret, baseURL, err := webhookConfig(e)
+		if err != nil {
+			return "", err
+		}
+
+		if secret == "" || !verify(secret) {
+			continue
+		}
+
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return "", err
+		}
+
+		return extsvc.NormalizeBaseURL(u).String(), nil
+	}
+
+	return "", nil
+}
+
+// sync syncs the Changesets with the given external IDs of the given
+// repository, or all of its Changesets if externalIDs is nil.
+func (h *Webhook) sync(ctx context.Context, spec api.ExternalRepoSpec, externalIDs []string) error {
+	rs, err := h.ReposStore.ListRepos(ctx, repos.StoreListReposArgs{
+		ExternalRepos: []api.ExternalRepoSpec{spec},
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(rs) == 0 {
+		// The repository isn't synced to Sourcegraph, so it has no
+		// Changesets.
+		return nil
+	}
+
+	var cs []*a8n.Changeset
+	for cursor := int64(-1); cursor != 0; {
+		opts := ListChangesetsOpts{
+			RepoID:      int32(rs[0].ID),
+			ExternalIDs: externalIDs,
+			Cursor:      cursor,
+			Limit:       1000,
+		}
+
+		page, next, err := h.Store.ListChangesets(ctx, opts)
+		if err != nil {
+			return err
+		}
+		cs, cursor = append(cs, page...), next
+	}
+
+	syncer := ChangesetSyncer{
+		Store:       h.Store,
+		ReposStore:  h.ReposStore,
+		HTTPFactory: h.HTTPFactory,
+	}
+
+	return syncer.Sync(ctx, cs...)
+}
+
+// webhookConfig returns the webhook secret and the base URL of the given
+// external service. The secret is empty if the external service doesn't
+// support webhooks or has none configured.
+func webhookConfig(e *repos.ExternalService) (secret, baseURL string, err error) {
+	cfg, err := e.Configuration()
+	if err != nil {
+		return "", "", err
+	}
+
+	switch c := cfg.(type) {
+	case *schema.GitHubConnection:
+		return c.WebhookSecret, c.Url, nil
+	case *schema.GitLabConnection:
+		return c.WebhookSecret, c.Url, nil
+	default:
+		return "", "", nil
+	}
+}
+
+// validGitHubSignature returns true if the given X-Hub-Signature header
+// value is the HMAC of the payload keyed with the secret.
+func validGitHubSignature(secret, signature string, payload []byte) bool {
+	const prefix = "sha1="
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+
+	sig, err := hex.DecodeString(signature[len(prefix):])
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(payload)
+
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// validGitLabToken returns true if the given X-Gitlab-Token header value
+// matches the secret.
+func validGitLabToken(secret, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1
+}
+
+type githubPullRequestNumbers []struct {
+	Number int `json:"number"`
+}
+
+// parseGitHubEvent returns the webhookEvent of the given GitHub event. It
+// returns nil if the event doesn't affect any Changesets.
+func parseGitHubEvent(kind string, payload []byte) (*webhookEvent, error) {
+	var e struct {
+		Repository struct {
+			NodeID string `json:"node_id"`
+		} `json:"repository"`
+		PullRequest struct {
+			Number int `json:"number"`
+		} `json:"pull_request"`
+		CheckRun struct {
+			PullRequests githubPullRequestNumbers `json:"pull_requests"`
+		} `json:"check_run"`
+		CheckSuite struct {
+			PullRequests githubPullRequestNumbers `json:"pull_requests"`
+		} `json:"check_suite"`
+	}
+
+	switch kind {
+	case "pull_request", "pull_request_review", "check_run", "check_suite", "status":
+	default:
+		return nil, nil
+	}
+
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, errors.Wrap(err, "decoding payload")
+	}
+
+	if e.Repository.NodeID == "" {
+		return nil, errors.New("payload has no repository")
+	}
+
+	ev := &webhookEvent{repo: e.Repository.NodeID}
+
+	var prs githubPullRequestNumbers
+	switch kind {
+	case "pull_request", "pull_request_review":
+		ev.externalIDs = []string{strconv.Itoa(e.PullRequest.Number)}
+		return ev, nil
+	case "check_run":
+		prs = e.CheckRun.PullRequests
+	case "check_suite":
+		prs = e.CheckSuite.PullRequests
+	case "status":
+		// Commit statuses don't reference the pull requests of the commit,
+		// so all Changesets of the repository are synced.
+		return ev, nil
+	}
+
+	if len(prs) == 0 {
+		return nil, nil
+	}
+
+	for _, pr := range prs {
+		ev.externalIDs = append(ev.externalIDs, strconv.Itoa(pr.Number))
+	}
+
+	return ev, nil
+}
+
+// parseGitLabEvent returns the webhookEvent of the given GitLab event. It
+// returns nil if the event doesn't affect any Changesets.
+func parseGitLabEvent(kind string, payload []byte) (*webhookEvent, error) {
+	var e struct {
+		Project struct {
+			ID int `json:"id"`
+		} `json:"project"`
+		ObjectAttributes struct {
+			IID          int    `json:"iid"`
+			NoteableType string `json:"noteable_type"`
+		} `json:"object_attributes"`
+		MergeRequest *struct {
+			IID int `json:"iid"`
+		} `json:"merge_request"`
+	}
+
+	switch kind {
+	case "Merge Request Hook", "Pipeline Hook", "Note Hook":
+	default:
+		return nil, nil
+	}
+
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, errors.Wrap(err, "decoding payload")
+	}
+
+	if e.Project.ID == 0 {
+		return nil, errors.New("payload has no project")
+	}
+
+	ev := &webhookEvent{repo: strconv.Itoa(e.Project.ID)}
+
+	switch kind {
+	case "Merge Request Hook":
+		ev.externalIDs = []string{strconv.Itoa(e.ObjectAttributes.IID)}
+	case "Pipeline Hook":
+		// Pipelines of branches rather than merge requests don't reference
+		// a merge request, so all Changesets of the project are synced.
+		if e.MergeRequest != nil {
+			ev.externalIDs = []string{strconv.Itoa(e.MergeRequest.IID)}
+		}
+	case "Note Hook":
+		if e.ObjectAttributes.NoteableType != "MergeRequest" || e.MergeRequest == nil {
+			return nil, nil
+		}
+		ev.externalIDs = []string{strconv.Itoa(e.MergeRequest.IID)}
+	}
+
+	return ev, nil
+}
@@ -0,0 +1,188 @@
+package a8n
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidGitHubSignature(t *testing.T) {
+	payload := []byte(`{"zen":"Keep it logically awesome."}`)
+
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write(payload)
+	signature := "sha1=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: "secret", signature: signature, want: true},
+		{name: "wrong secret", secret: "other", signature: signature, want: false},
+		{name: "missing prefix", secret: "secret", signature: signature[len("sha1="):], want: false},
+		{name: "not hex", secret: "secret", signature: "sha1=xyz", want: false},
+		{name: "empty", secret: "secret", signature: "", want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if have := validGitHubSignature(tc.secret, tc.signature, payload); have != tc.want {
+				t.Errorf("have %t, want %t", have, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidGitLabToken(t *testing.T) {
+	if !validGitLabToken("secret", "secret") {
+		t.Error("matching token not valid")
+	}
+
+	if validGitLabToken("secret", "other") {
+		t.Error("non-matching token valid")
+	}
+
+	if validGitLabToken("secret", "") {
+		t.Error("empty token valid")
+	}
+}
+
+func TestParseGitHubEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		payload string
+		want    *webhookEvent
+		err     string
+	}{
+		{
+			name:    "ping",
+			kind:    "ping",
+			payload: `{"zen":"Keep it logically awesome."}`,
+		},
+		{
+			name:    "pull request",
+			kind:    "pull_request",
+			payload: `{"repository":{"node_id":"R1"},"pull_request":{"number":12}}`,
+			want:    &webhookEvent{repo: "R1", externalIDs: []string{"12"}},
+		},
+		{
+			name:    "pull request review",
+			kind:    "pull_request_review",
+			payload: `{"repository":{"node_id":"R1"},"pull_request":{"number":7}}`,
+			want:    &webhookEvent{repo: "R1", externalIDs: []string{"7"}},
+		},
+		{
+			name:    "check run",
+			kind:    "check_run",
+			payload: `{"repository":{"node_id":"R1"},"check_run":{"pull_requests":[{"number":1},{"number":2}]}}`,
+			want:    &webhookEvent{repo: "R1", externalIDs: []string{"1", "2"}},
+		},
+		{
+			name:    "check suite without pull requests",
+			kind:    "check_suite",
+			payload: `{"repository":{"node_id":"R1"},"check_suite":{"pull_requests":[]}}`,
+		},
+		{
+			name:    "status",
+			kind:    "status",
+			payload: `{"repository":{"node_id":"R1"},"sha":"deadbeef"}`,
+			want:    &webhookEvent{repo: "R1"},
+		},
+		{
+			name:    "no repository",
+			kind:    "pull_request",
+			payload: `{"pull_request":{"number":12}}`,
+			err:     "payload has no repository",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := parseGitHubEvent(tc.kind, []byte(tc.payload))
+			if have, want := errString(err), tc.err; have != want {
+				t.Fatalf("error: have %q, want %q", have, want)
+			}
+
+			if diff := cmp.Diff(have, tc.want, cmp.AllowUnexported(webhookEvent{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestParseGitLabEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		payload string
+		want    *webhookEvent
+		err     string
+	}{
+		{
+			name:    "push",
+			kind:    "Push Hook",
+			payload: `{"project":{"id":5}}`,
+		},
+		{
+			name:    "merge request",
+			kind:    "Merge Request Hook",
+			payload: `{"project":{"id":5},"object_attributes":{"iid":3}}`,
+			want:    &webhookEvent{repo: "5", externalIDs: []string{"3"}},
+		},
+		{
+			name:    "merge request pipeline",
+			kind:    "Pipeline Hook",
+			payload: `{"project":{"id":5},"merge_request":{"iid":4}}`,
+			want:    &webhookEvent{repo: "5", externalIDs: []string{"4"}},
+		},
+		{
+			name:    "branch pipeline",
+			kind:    "Pipeline Hook",
+			payload: `{"project":{"id":5},"merge_request":null}`,
+			want:    &webhookEvent{repo: "5"},
+		},
+		{
+			name:    "merge request note",
+			kind:    "Note Hook",
+			payload: `{"project":{"id":5},"object_attributes":{"noteable_type":"MergeRequest"},"merge_request":{"iid":9}}`,
+			want:    &webhookEvent{repo: "5", externalIDs: []string{"9"}},
+		},
+		{
+			name:    "issue note",
+			kind:    "Note Hook",
+			payload: `{"project":{"id":5},"object_attributes":{"noteable_type":"Issue"}}`,
+		},
+		{
+			name:    "no project",
+			kind:    "Merge Request Hook",
+			payload: `{"object_attributes":{"iid":3}}`,
+			err:     "payload has no project",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := parseGitLabEvent(tc.kind, []byte(tc.payload))
+			if have, want := errString(err), tc.err; have != want {
+				t.Fatalf("error: have %q, want %q", have, want)
+			}
+
+			if diff := cmp.Diff(have, tc.want, cmp.AllowUnexported(webhookEvent{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP INDEX IF EXISTS repo_language_idx;
+DROP INDEX IF EXISTS repo_visibility_idx;
+DROP INDEX IF EXISTS repo_topics_gin_idx;
+
+ALTER TABLE repo DROP CONSTRAINT IF EXISTS repo_visibility_check;
+
+ALTER TABLE repo DROP COLUMN IF EXISTS visibility;
+ALTER TABLE repo DROP COLUMN IF EXISTS stars;
+ALTER TABLE repo DROP COLUMN IF EXISTS topics;
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- Normalized code host metadata extracted by repo-updater from the raw
+-- payloads stored in repo.metadata, so that it can be efficiently filtered on.
+ALTER TABLE repo ADD COLUMN IF NOT EXISTS topics text[] NOT NULL DEFAULT '{}';
+ALTER TABLE repo ADD COLUMN IF NOT EXISTS stars integer NOT NULL DEFAULT 0;
+ALTER TABLE repo ADD COLUMN IF NOT EXISTS visibility text;
+
+ALTER TABLE repo
+DROP CONSTRAINT IF EXISTS repo_visibility_check,
+ADD CONSTRAINT repo_visibility_check CHECK (visibility IN ('public', 'private', 'internal'));
+
+CREATE INDEX IF NOT EXISTS repo_topics_gin_idx ON repo USING GIN (topics);
+CREATE INDEX IF NOT EXISTS repo_visibility_idx ON repo (visibility);
+CREATE INDEX IF NOT EXISTS repo_language_idx ON repo (lower(language));
+
+COMMIT;
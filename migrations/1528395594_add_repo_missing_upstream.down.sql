@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS repo_missing_upstream_at_idx;
+
+ALTER TABLE repo DROP COLUMN IF EXISTS quarantined;
+ALTER TABLE repo DROP COLUMN IF EXISTS missing_upstream_at;
+
+COMMIT;
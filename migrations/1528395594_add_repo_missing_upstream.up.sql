@@ -0,0 +1,12 @@
+BEGIN;
+
+-- Repositories that are no longer returned by any of their code hosts are
+-- kept around for a grace period before being deleted. Repositories removed by
+-- a sync that dropped an unusually large share of an external service's
+-- repositories are quarantined: they are kept until a site admin reviews them.
+ALTER TABLE repo ADD COLUMN IF NOT EXISTS missing_upstream_at timestamptz;
+ALTER TABLE repo ADD COLUMN IF NOT EXISTS quarantined boolean NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS repo_missing_upstream_at_idx ON repo (missing_upstream_at) WHERE missing_upstream_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS changeset_jobs;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS campaign_plan_id;
+DROP TABLE IF EXISTS campaign_jobs;
+DROP TABLE IF EXISTS campaign_plans;
+
+COMMIT;
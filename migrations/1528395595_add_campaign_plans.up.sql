@@ -0,0 +1,52 @@
+BEGIN;
+
+-- A campaign plan previews the changes a campaign would make by running a
+-- rewrite over many repositories. Each repository gets a campaign job that
+-- holds the resulting diff. When a campaign is created from a plan, a
+-- changeset job turns each campaign job's diff into a changeset on the
+-- code host.
+CREATE TABLE IF NOT EXISTS campaign_plans (
+  id bigserial PRIMARY KEY,
+  spec jsonb NOT NULL DEFAULT '{}'
+    CHECK (jsonb_typeof(spec) = 'object'),
+  created_at timestamp with time zone NOT NULL DEFAULT now(),
+  updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS campaign_jobs (
+  id bigserial PRIMARY KEY,
+  campaign_plan_id bigint NOT NULL REFERENCES campaign_plans(id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE,
+  repo_id integer NOT NULL REFERENCES repo(id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE,
+  rev text NOT NULL DEFAULT '',
+  base_ref text NOT NULL DEFAULT '',
+  diff text NOT NULL DEFAULT '',
+  error text NOT NULL DEFAULT '',
+  started_at timestamp with time zone,
+  finished_at timestamp with time zone,
+  created_at timestamp with time zone NOT NULL DEFAULT now(),
+  updated_at timestamp with time zone NOT NULL DEFAULT now(),
+  UNIQUE (campaign_plan_id, repo_id)
+);
+
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS campaign_plan_id bigint
+  REFERENCES campaign_plans(id) ON DELETE SET NULL DEFERRABLE INITIALLY IMMEDIATE;
+
+CREATE TABLE IF NOT EXISTS changeset_jobs (
+  id bigserial PRIMARY KEY,
+  campaign_id bigint NOT NULL REFERENCES campaigns(id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE,
+  campaign_job_id bigint NOT NULL REFERENCES campaign_jobs(id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE,
+  changeset_id bigint REFERENCES changesets(id)
+    ON DELETE SET NULL DEFERRABLE INITIALLY IMMEDIATE,
+  error text NOT NULL DEFAULT '',
+  started_at timestamp with time zone,
+  finished_at timestamp with time zone,
+  created_at timestamp with time zone NOT NULL DEFAULT now(),
+  updated_at timestamp with time zone NOT NULL DEFAULT now(),
+  UNIQUE (campaign_id, campaign_job_id)
+);
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+-- Changeset events record the state transitions of changesets over time,
+-- so that the history of a campaign can be charted.
+CREATE TABLE IF NOT EXISTS changeset_events (
+  id bigserial PRIMARY KEY,
+  changeset_id bigint NOT NULL REFERENCES changesets(id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE,
+  kind text NOT NULL,
+  key text NOT NULL,
+  actor text NOT NULL DEFAULT '',
+  "timestamp" timestamp with time zone NOT NULL,
+  created_at timestamp with time zone NOT NULL DEFAULT now(),
+  updated_at timestamp with time zone NOT NULL DEFAULT now(),
+  UNIQUE (changeset_id, kind, key)
+);
+
+COMMIT;
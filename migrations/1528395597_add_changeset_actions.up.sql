@@ -0,0 +1,32 @@
+BEGIN;
+
+-- A changeset action is an action (e.g. comment, close, merge) run over a
+-- subset of the changesets of a campaign in the background. Each changeset
+-- gets a changeset action job that records the result of the action.
+CREATE TABLE IF NOT EXISTS changeset_actions (
+  id bigserial PRIMARY KEY,
+  campaign_id bigint NOT NULL REFERENCES campaigns(id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE,
+  author_id integer NOT NULL REFERENCES users(id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE,
+  action text NOT NULL,
+  body text NOT NULL DEFAULT '',
+  created_at timestamp with time zone NOT NULL DEFAULT now(),
+  updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS changeset_action_jobs (
+  id bigserial PRIMARY KEY,
+  changeset_action_id bigint NOT NULL REFERENCES changeset_actions(id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE,
+  changeset_id bigint NOT NULL REFERENCES changesets(id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE,
+  error text NOT NULL DEFAULT '',
+  started_at timestamp with time zone,
+  finished_at timestamp with time zone,
+  created_at timestamp with time zone NOT NULL DEFAULT now(),
+  updated_at timestamp with time zone NOT NULL DEFAULT now(),
+  UNIQUE (changeset_action_id, changeset_id)
+);
+
+COMMIT;
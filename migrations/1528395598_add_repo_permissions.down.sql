@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS repo_permissions;
+DROP TABLE IF EXISTS user_repo_permissions;
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+-- The repositories a user has a permission on according to one authz
+-- provider, as a roaring bitmap of repo IDs. Synced in the background.
+CREATE TABLE IF NOT EXISTS user_repo_permissions (
+  user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  permission text NOT NULL,
+  service_type text NOT NULL,
+  service_id text NOT NULL,
+  repo_ids bytea NOT NULL,
+  max_repo_id integer NOT NULL,
+  synced_at timestamp with time zone NOT NULL,
+  CONSTRAINT user_repo_permissions_unique UNIQUE (user_id, permission, service_type, service_id)
+);
+
+-- The users that have a permission on a repository, as a roaring bitmap of
+-- user IDs. Kept up to date with user_repo_permissions.
+CREATE TABLE IF NOT EXISTS repo_permissions (
+  repo_id integer NOT NULL REFERENCES repo(id) ON DELETE CASCADE,
+  permission text NOT NULL,
+  user_ids bytea NOT NULL,
+  updated_at timestamp with time zone NOT NULL,
+  CONSTRAINT repo_permissions_unique UNIQUE (repo_id, permission)
+);
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- Permissions on repositories granted explicitly by site admins, to a user
+-- or to the members of an org.
+CREATE TABLE IF NOT EXISTS repo_explicit_permissions (
+  repo_id integer NOT NULL REFERENCES repo(id) ON DELETE CASCADE,
+  user_id integer REFERENCES users(id) ON DELETE CASCADE,
+  org_id integer REFERENCES orgs(id) ON DELETE CASCADE,
+  permission text NOT NULL,
+  created_at timestamp with time zone NOT NULL DEFAULT now(),
+  CONSTRAINT repo_explicit_permissions_grantee_check CHECK ((user_id IS NULL) <> (org_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS repo_explicit_permissions_user_unique ON repo_explicit_permissions (repo_id, permission, user_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS repo_explicit_permissions_org_unique ON repo_explicit_permissions (repo_id, permission, org_id) WHERE org_id IS NOT NULL;
+
+COMMIT;
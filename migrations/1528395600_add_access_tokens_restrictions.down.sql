@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE access_tokens DROP COLUMN IF EXISTS repo_patterns;
+ALTER TABLE access_tokens DROP COLUMN IF EXISTS expires_at;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS repo_patterns text[] NOT NULL DEFAULT '{}';
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS expires_at timestamp with time zone;
+
+COMMIT;
@@ -0,0 +1,28 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id bigserial PRIMARY KEY,
+    -- Not a foreign key, so that entries outlive the users who performed them.
+    actor_user_id integer NOT NULL,
+    action text NOT NULL,
+    subject text NOT NULL DEFAULT '',
+    data jsonb NOT NULL DEFAULT '{}',
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at ON audit_log USING btree (created_at);
+CREATE INDEX IF NOT EXISTS audit_log_actor_user_id ON audit_log USING btree (actor_user_id);
+CREATE INDEX IF NOT EXISTS audit_log_action ON audit_log USING btree (action);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log FOR EACH ROW EXECUTE PROCEDURE audit_log_append_only();
+
+COMMIT;
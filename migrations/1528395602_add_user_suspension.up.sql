@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_at timestamp with time zone;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS invalidated_sessions_at timestamp with time zone;
+
+COMMIT;
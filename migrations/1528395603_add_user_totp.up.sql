@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id integer PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    -- The TOTP secret is not encrypted at rest (like the tokens in external
+    -- service configs), so access to the database must be restricted.
+    secret text NOT NULL,
+    recovery_code_hashes bytea[] NOT NULL DEFAULT '{}',
+    last_used_counter bigint NOT NULL DEFAULT 0,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    enabled_at timestamp with time zone
+);
+
+COMMIT;
@@ -247,6 +247,28 @@
 // 1528395591_create_events_logging_table.up.sql (1.192kB)
 // 1528395592_add_deletion_triggers_to_campaigns_and_changesets.down.sql (317B)
 // 1528395592_add_deletion_triggers_to_campaigns_and_changesets.up.sql (1.543kB)
+// 1528395594_add_repo_missing_upstream.down.sql (181B)
+// 1528395594_add_repo_missing_upstream.up.sql (612B)
+// 1528395595_add_campaign_plans.down.sql (189B)
+// 1528395595_add_campaign_plans.up.sql (2.109kB)
+// 1528395596_add_changeset_events.down.sql (56B)
+// 1528395596_add_changeset_events.up.sql (617B)
+// 1528395597_add_changeset_actions.down.sql (101B)
+// 1528395597_add_changeset_actions.up.sql (1.31kB)
+// 1528395598_add_repo_permissions.down.sql (100B)
+// 1528395598_add_repo_permissions.up.sql (987B)
+// 1528395599_add_repo_explicit_permissions.down.sql (65B)
+// 1528395599_add_repo_explicit_permissions.up.sql (868B)
+// 1528395600_add_access_tokens_restrictions.down.sql (140B)
+// 1528395600_add_access_tokens_restrictions.up.sql (200B)
+// 1528395601_add_audit_log.down.sql (98B)
+// 1528395601_add_audit_log.up.sql (982B)
+// 1528395593_add_repo_metadata_columns.down.sql (353B)
+// 1528395593_add_repo_metadata_columns.up.sql (761B)
+// 1528395602_add_user_suspension.down.sql (136B)
+// 1528395602_add_user_suspension.up.sql (192B)
+// 1528395603_add_user_totp.down.sql (49B)
+// 1528395603_add_user_totp.up.sql (514B)
 
 package migrations
 
@@ -5255,6 +5277,446 @@ func _1528395592_add_deletion_triggers_to_campaigns_and_changesetsUpSql() (*asse
 	return a, nil
 }
 
+var __1528395594_add_repo_missing_upstreamDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x2d\xc8\x8f\xcf\xcd\x2c\x2e\xce\xcc\x4b\x8f\x2f\x2d\x28\x2e\x29\x4a\x4d\xcc\x8d\x4f\x2c\x89\xcf\x4c\xa9\x00\xea\x71\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x05\x2b\x55\x00\x1b\xe2\xec\xef\x13\xea\xeb\x87\x64\x4a\x61\x69\x62\x51\x62\x5e\x49\x66\x5e\x6a\x8a\x35\xb1\x7a\xb0\x58\x0a\xb4\xd0\xd9\xdf\xd7\xd7\x33\xc4\x9a\x0b\x00\xca\xad\xb9\x45\xb5\x00\x00\x00")
+
+func _1528395594_add_repo_missing_upstreamDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395594_add_repo_missing_upstreamDownSql,
+		"1528395594_add_repo_missing_upstream.down.sql",
+	)
+}
+
+func _1528395594_add_repo_missing_upstreamDownSql() (*asset, error) {
+	bytes, err := _1528395594_add_repo_missing_upstreamDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395594_add_repo_missing_upstream.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x1a, 0x9e, 0xf1, 0x95, 0x9, 0xae, 0x1a, 0x53, 0x37, 0x3b, 0x1c, 0xfb, 0xa, 0xe9, 0x17, 0x46, 0x13, 0x10, 0x4, 0x42, 0x4c, 0x60, 0x91, 0x1d, 0xf5, 0xb2, 0x48, 0x4d, 0xd3, 0x21, 0x94, 0x1e}}
+	return a, nil
+}
+
+var __1528395594_add_repo_missing_upstreamUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x95\x91\xcd\x6e\xc2\x30\x10\x84\xef\x3c\xc5\xdc\xda\x1e\xe0\x01\xca\x29\x80\x69\x23\x85\x44\x0a\x41\xe5\x86\x0c\x59\xc0\xaa\x63\xa7\x6b\x87\x92\x3e\x7d\x6d\x90\x2a\x5a\x71\xe9\xc9\xd2\xfe\xcc\x7c\xb3\x9e\x88\x97\x34\x1f\x0f\x06\xc3\x21\x4a\x6a\xad\x53\xde\xb2\x22\x07\x7f\x94\x1e\x92\x09\xc6\x42\x5b\x73\x20\x06\x93\xef\xd8\x50\x8d\x6d\x0f\x69\x7a\xd8\x7d\x98\x22\xc5\xd8\xd9\x9a\x70\xb4\xce\xbb\xb8\x11\xa5\xde\xa9\x8d\xdb\xb6\x33\x35\xf6\x96\x21\x71\x60\xb9\x23\xb4\xc4\xca\x06\x01\x0a\x45\x0a\x8f\x32\x07\xd4\xa4\xc9\x53\x3d\xfa\xed\xcf\xd4\xd8\xd3\xc5\x2b\xea\x49\xb8\xde\xec\xae\x50\x35\xdb\xb6\x0d\x1d\x69\xd0\x99\xce\x75\x52\xeb\x1e\x5a\xf2\x81\xe0\x8e\x91\x38\x70\x85\x1e\x9d\x3d\xb1\x91\x1a\x8e\xf8\xa4\x76\xf4\xe0\xa2\x10\xdf\x7a\xc4\xe1\x8f\x4e\xb2\x34\x5e\x85\x5c\xcf\x31\x4e\x7f\xa9\x5e\xf8\xbb\x50\xd6\xd1\x5a\x79\x82\xac\x1b\x65\xc2\xfa\x49\xd1\x67\xbc\x0e\x35\xa3\x41\x92\x55\xa2\x44\x95\x4c\x32\x71\x11\x46\x32\x9b\x61\x5a\x64\xab\x45\x8e\x74\x8e\xbc\xa8\x20\xd6\xe9\xb2\x5a\xa2\x51\xce\x85\xac\x9b\xae\x75\x9e\x49\x36\x9b\x90\xc3\xab\x86\x9c\x97\x4d\xeb\xbf\xc6\xff\x90\xba\x01\xc6\xd6\x5a\x4d\x21\x6b\x6c\xe7\xab\x2c\xc3\x4c\xcc\x93\x55\x56\x61\x2f\xb5\xa3\xf0\xab\xd3\x52\x24\x95\x40\x9a\xcf\xc4\xfa\x8f\x4e\x74\xd9\xdc\xe1\xda\xa8\xfa\x8c\x22\xbf\x52\x3c\xde\x19\x78\xc2\xdb\xab\x28\xc5\xdd\x4c\xe9\xf2\x87\x25\xba\x17\x8b\x45\x5a\x8d\x07\xdf\x30\x8c\x29\x88\x64\x02\x00\x00")
+
+func _1528395594_add_repo_missing_upstreamUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395594_add_repo_missing_upstreamUpSql,
+		"1528395594_add_repo_missing_upstream.up.sql",
+	)
+}
+
+func _1528395594_add_repo_missing_upstreamUpSql() (*asset, error) {
+	bytes, err := _1528395594_add_repo_missing_upstreamUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395594_add_repo_missing_upstream.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd1, 0x6a, 0x7a, 0xd3, 0xf2, 0x86, 0x96, 0x5f, 0x22, 0xf8, 0x9a, 0x1a, 0xa2, 0xfb, 0x8e, 0xeb, 0xbf, 0x4f, 0x33, 0xa4, 0x64, 0xcf, 0xf7, 0xeb, 0x43, 0x89, 0x9d, 0x20, 0xf0, 0x78, 0x1b, 0xbc}}
+	return a, nil
+}
+
+var __1528395595_add_campaign_plansDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\xce\x48\xcc\x4b\x4f\x2d\x4e\x2d\x89\xcf\xca\x4f\x2a\xb6\xe6\x72\xf4\x09\x71\x0d\x82\xaa\x4a\x4e\xcc\x2d\x48\xcc\x4c\xcf\x2b\x56\x00\x6b\x75\xf6\xf7\x09\xf5\xf5\x43\xd6\x0b\x95\x8f\x2f\xc8\x49\xcc\x8b\xcf\x4c\xb1\xc6\x61\x05\x4c\x19\xc4\x06\xfc\x6a\x40\x46\x01\x15\x71\x39\xfb\xfb\xfa\x7a\x86\x58\x73\x01\x00\xb1\x52\x3c\x4d\xbd\x00\x00\x00")
+
+func _1528395595_add_campaign_plansDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395595_add_campaign_plansDownSql,
+		"1528395595_add_campaign_plans.down.sql",
+	)
+}
+
+func _1528395595_add_campaign_plansDownSql() (*asset, error) {
+	bytes, err := _1528395595_add_campaign_plansDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395595_add_campaign_plans.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x6e, 0x3f, 0x50, 0xe9, 0x42, 0xa7, 0x8a, 0x2b, 0xcf, 0x8b, 0xa2, 0xda, 0xe4, 0xd7, 0xae, 0x7a, 0x71, 0x8b, 0x97, 0x4a, 0x55, 0xfc, 0x55, 0xcc, 0x1, 0xe7, 0xf3, 0xb8, 0x9e, 0x1a, 0x97, 0x9b}}
+	return a, nil
+}
+
+var __1528395595_add_campaign_plansUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xed\x54\x4d\xaf\xda\x30\x10\xbc\xe7\x57\xec\x2d\x41\xe2\xf1\x07\x9e\x7a\xc8\x0b\xa6\x8d\x5e\x12\xda\x10\xd4\x72\x42\x26\x59\x88\x29\xd8\xc8\x36\x50\x5a\xf5\xbf\xd7\x0e\x1f\xe1\x51\x14\x68\x39\xf4\xd2\x4b\xa4\xc4\xb3\x33\xe3\xdd\xd9\xbc\x90\xf7\x61\xf2\xec\x38\x4f\x4f\xe0\x43\x4e\x97\x2b\xca\x66\x1c\x56\x0b\x6a\x1e\x12\x37\x0c\xb7\x0a\x74\x89\x90\x97\x94\xcf\x50\x01\xad\x41\x5b\xb1\x5e\x14\xb0\xa4\x5f\x11\x26\x3b\x90\x6b\xce\x19\x9f\x01\xb5\x54\x12\xb7\x92\x69\x04\xb1\x41\x69\x10\xdc\x1c\xe3\x4a\x28\xa6\x85\x64\xa8\x3a\x40\x68\x5e\xd6\x9f\x76\x30\x43\xfd\x86\x7a\x2e\x26\x46\x95\x6a\xcb\x55\x8a\x45\xb1\xf7\x20\x51\xad\x17\xda\x8a\x14\x6c\x3a\xed\xc0\xe7\x12\xf9\x79\x15\x53\x90\x4b\xa4\x1a\x0b\x98\x4a\xb1\x34\x47\xf6\x1e\xed\xbd\xa5\xc3\x05\x50\xef\xc9\xd7\x92\x2b\x40\x6b\xe3\x5c\xd4\x55\x15\x35\x30\xae\x85\x65\x3e\xd5\x08\x6e\x1d\x54\x3c\xa2\x40\xe3\x49\xe9\x8e\x13\xa4\xc4\xcf\x08\x64\xfe\x4b\x44\x20\xec\x41\xd2\xcf\x80\x7c\x09\x07\xd9\xe0\x44\x3a\xb6\x0e\x14\x78\x0e\x00\x2b\x60\xc2\x66\x0a\x25\xa3\x0b\xf8\x98\x86\xb1\x9f\x8e\xe0\x95\x8c\xda\xe6\x4c\xad\x30\x87\xb9\x12\x7c\x52\x91\x24\xc3\x28\x82\x2e\xe9\xf9\xc3\x28\x03\xf7\xc7\x4f\xd7\x40\x00\x82\x0f\x24\x78\x05\xaf\x82\x8d\xf5\x6e\x85\x62\xea\xd9\xc2\x16\xbc\x03\x57\x4c\xe6\x98\x6b\xb7\x65\xd9\x0e\x4d\x18\x53\x0d\x9a\x2d\x51\x69\x63\x06\xb6\x4c\x97\xd5\x2b\x7c\x17\x1c\x7f\x97\xe1\x62\xeb\x55\xd5\xeb\x55\xf1\x97\xd5\x4e\xcb\x04\xe9\x9e\xa6\x98\x4e\xdf\xee\xc9\x9b\x16\x8e\xf7\x48\x33\x97\x5a\x3b\x25\x3d\x92\x92\x24\x20\x97\xed\xf6\x58\xd1\xaa\x3a\xd6\x4f\x8c\xc1\x88\x18\x3b\x81\x3f\x08\xfc\x2e\xb1\x7e\x49\x9a\xee\xbd\x25\x61\x16\xfa\x51\x34\x82\x30\x8e\x49\x37\x34\xae\xad\xac\x4d\xa5\x55\x33\x52\x38\x33\xe9\xbd\x26\x67\x31\x0f\x8a\x6c\x40\xe3\x37\x7d\x65\xda\xae\x3d\x9f\x50\x85\x63\x89\xd3\x46\x50\x95\xd4\x26\x00\x4a\x29\x64\x23\xc2\x0c\x57\xde\x98\xb5\x85\x4d\x19\x67\xaa\xbc\x8d\xfb\x57\xc1\xb3\xd5\xc3\x24\xfc\x34\x24\xe0\x5d\xc6\xa6\x7d\x9c\xe8\x3e\x9e\x7e\x94\x91\xf4\x90\xce\x23\x54\x81\xdf\xed\x42\xd0\x8f\x86\x71\xd2\xb4\xc7\x75\x08\x8d\x5e\x63\xfa\xce\x42\x31\x20\xb5\xe3\x86\x54\xdc\xd8\x9c\xe3\x8f\xe8\x0f\x57\xe7\xbe\xad\x79\x6c\x61\xce\xb7\xfa\xde\x35\xb5\xb7\x78\x4c\xf4\xd4\x90\x5a\xf1\x5c\xe8\x78\x7c\x4d\xe5\xce\x89\xfc\xdf\x20\xbb\x3c\x17\xd3\x3d\xfc\xe3\xfb\x71\x1c\x66\xcf\xce\x2f\x53\xeb\xe1\x5a\x3d\x08\x00\x00")
+
+func _1528395595_add_campaign_plansUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395595_add_campaign_plansUpSql,
+		"1528395595_add_campaign_plans.up.sql",
+	)
+}
+
+func _1528395595_add_campaign_plansUpSql() (*asset, error) {
+	bytes, err := _1528395595_add_campaign_plansUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395595_add_campaign_plans.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x86, 0x40, 0xab, 0x42, 0xff, 0x3, 0x72, 0xd2, 0x4c, 0xe1, 0x1b, 0x14, 0x26, 0xfd, 0x50, 0xc2, 0xd9, 0xc3, 0x73, 0x33, 0x9a, 0x26, 0x82, 0x99, 0xd6, 0x9e, 0x25, 0x30, 0x50, 0x27, 0x58, 0x48}}
+	return a, nil
+}
+
+var __1528395596_add_changeset_eventsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\xce\x48\xcc\x4b\x4f\x2d\x4e\x2d\x89\x4f\x2d\x4b\xcd\x2b\x29\x06\xaa\x73\xf6\xf7\xf5\xf5\x0c\xb1\xe6\x02\x00\x63\x8c\xac\xd5\x38\x00\x00\x00")
+
+func _1528395596_add_changeset_eventsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395596_add_changeset_eventsDownSql,
+		"1528395596_add_changeset_events.down.sql",
+	)
+}
+
+func _1528395596_add_changeset_eventsDownSql() (*asset, error) {
+	bytes, err := _1528395596_add_changeset_eventsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395596_add_changeset_events.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x60, 0x23, 0xad, 0xb7, 0x2c, 0xb0, 0x5d, 0xd5, 0xe, 0x7d, 0x63, 0x28, 0x9, 0xc6, 0x5e, 0x18, 0x9, 0xbc, 0x50, 0x49, 0xa6, 0x6, 0xed, 0xf0, 0xaa, 0xf7, 0x60, 0xd8, 0xa3, 0x68, 0xe4, 0x46}}
+	return a, nil
+}
+
+var __1528395596_add_changeset_eventsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa5\x52\x4b\x6e\xc2\x30\x10\xdd\xe7\x14\x23\x36\x24\x52\xe8\x05\x58\x85\x30\x54\x56\x93\xd0\x06\x47\x2a\x2b\x64\x92\x29\xb1\x5a\x1c\x64\xbb\x50\x7a\xfa\xda\xa1\xe5\xa3\x2e\xba\xe8\x6e\x66\xfc\x3e\xe3\x67\x4f\xf0\x9e\x15\xe3\x20\x18\x8d\x20\x6d\x85\xda\x90\x21\x0b\xb4\x27\x65\x0d\x68\xaa\x3b\xdd\x80\x6d\x09\x8c\x15\x96\xc0\x6a\xa1\x8c\xb4\xb2\x53\x06\xba\x17\xa8\x7f\x08\xae\xdb\x93\x06\x2b\xb7\x14\x7b\x25\xd3\x39\x92\xb0\x3d\xb3\x95\xc6\x76\xfa\xe8\xf1\x02\x6a\xb1\xdd\x09\xb9\x51\xae\x50\xb0\x26\xaf\xa0\x2d\x35\x77\x41\x5a\x62\xc2\x11\x78\x32\xc9\x10\xd8\x0c\x8a\x39\x07\x7c\x66\x0b\xbe\xb8\xb8\xac\xbe\xd7\x0a\x03\x00\xd9\xc0\x5a\x6e\x0c\x69\x29\xde\xe0\xb1\x64\x79\x52\x2e\xe1\x01\x97\xb1\x3b\xbb\x10\x4e\x28\xa9\x6c\xaf\x57\x54\x59\x06\x25\xce\xb0\xc4\x22\xc5\x2b\x61\x13\xca\x26\x72\x44\x80\x79\x01\x53\xcc\xd0\x6d\x92\x26\x8b\x34\x99\xa2\x6b\x1d\xbe\x3c\xad\x55\x30\xce\x92\x2c\x5b\x02\xcb\x73\x9c\x32\xb7\xb0\xb7\x7b\x95\xca\x65\x44\x1f\x17\x93\x7e\x4a\xc7\xdf\x43\x51\xbb\x28\x6e\xc7\xde\x20\xa9\x32\x0e\xc3\xa1\x47\x0c\x7c\x86\x2e\xec\xed\x6e\x00\xe7\x12\x0e\xd2\xb6\x7d\x0b\x9f\x9d\xa2\x1b\xc9\x5a\x93\x7b\x99\x66\xe5\xd3\xfe\x0b\x7f\xf6\x52\xdd\x21\x8c\x3c\xfb\x7d\xd7\xfc\x83\x5d\x15\xec\xa9\x42\x08\xaf\x03\x8f\xfb\x3c\x62\x7f\xff\x28\x88\xdc\xc7\x4a\xe7\x79\xce\xf8\x38\xf8\x02\x4c\x1b\xaf\xf0\x69\x02\x00\x00")
+
+func _1528395596_add_changeset_eventsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395596_add_changeset_eventsUpSql,
+		"1528395596_add_changeset_events.up.sql",
+	)
+}
+
+func _1528395596_add_changeset_eventsUpSql() (*asset, error) {
+	bytes, err := _1528395596_add_changeset_eventsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395596_add_changeset_events.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf2, 0xa0, 0xfe, 0xe7, 0x1c, 0xa2, 0x51, 0x2e, 0x87, 0x35, 0x43, 0x71, 0x94, 0x6d, 0x9f, 0x7, 0x4d, 0x9, 0xba, 0x64, 0x6c, 0x5a, 0xe1, 0x27, 0x53, 0xe9, 0xc5, 0xb6, 0xf8, 0xfc, 0xee, 0x88}}
+	return a, nil
+}
+
+var __1528395597_add_changeset_actionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\xce\x48\xcc\x4b\x4f\x2d\x4e\x2d\x89\x4f\x4c\x2e\xc9\xcc\xcf\x8b\xcf\xca\x4f\x2a\xb6\x26\x4e\x2d\x50\x1d\x97\xb3\xbf\xaf\xaf\x67\x88\x35\x17\x00\x3c\xc8\x56\x98\x65\x00\x00\x00")
+
+func _1528395597_add_changeset_actionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395597_add_changeset_actionsDownSql,
+		"1528395597_add_changeset_actions.down.sql",
+	)
+}
+
+func _1528395597_add_changeset_actionsDownSql() (*asset, error) {
+	bytes, err := _1528395597_add_changeset_actionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395597_add_changeset_actions.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe9, 0xd0, 0x46, 0xbc, 0xce, 0x36, 0xa7, 0x5, 0x2b, 0xba, 0xac, 0x86, 0x37, 0x3, 0xa9, 0x40, 0x52, 0xe2, 0xa8, 0x97, 0x1e, 0x40, 0x2e, 0xa6, 0x5c, 0xa7, 0xf0, 0xec, 0x8e, 0x31, 0x90, 0x2e}}
+	return a, nil
+}
+
+var __1528395597_add_changeset_actionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xc5\x52\xcb\x6e\xdb\x30\x10\xbc\xeb\x2b\xe6\x16\x19\x70\xfc\x03\x39\x29\x36\x13\x10\x95\xe4\x56\x96\x81\xfa\x64\xd0\xd2\x46\x62\x6b\x91\x01\x49\x35\x6d\xbf\xbe\xa4\xd4\x44\xc8\x03\x75\x5a\x1f\x7a\xdc\xe5\x3c\x96\x3b\x7b\xcd\x6e\x79\x7e\x15\x45\x97\x97\x48\x50\xb5\x42\x35\x64\xc9\x41\x54\x4e\x6a\x05\x69\x21\xd4\x63\x11\xd3\xa2\x59\xa0\xd2\x5d\x47\xca\xcd\x51\x1d\xb5\xa5\x39\x3a\x32\x0d\xcd\x60\x7a\x05\xfd\x8d\x0c\x44\x90\xb2\xfd\x21\xa8\xe8\x3b\xb8\x96\x26\x59\x1b\x3a\x02\x95\xe8\xee\x85\x6c\xbc\xbc\x1a\xde\x0f\xa2\xfa\xda\x18\xdd\xab\x7a\x01\x26\xaa\x76\x22\x04\xad\x26\xf0\xc4\xeb\xd9\xbe\xe8\x83\x67\x0b\x07\x43\x95\x36\xb5\x1d\xa4\x0c\xd9\xfe\xf8\x64\x3c\x22\x17\xd1\xb2\x60\x49\xc9\x50\x26\xd7\x29\x03\xbf\x41\xbe\x2e\xc1\x3e\xf3\x4d\xb9\x99\x64\xf7\x23\xd8\x22\x8e\x00\x59\xe3\x20\x1b\x4b\x46\x8a\x23\x3e\x16\x3c\x4b\x8a\x1d\x3e\xb0\xdd\xdc\xbf\x3d\x4e\xbf\x1f\x41\x52\xb9\x41\x2f\xdf\xa6\x29\x0a\x76\xc3\x0a\x96\x2f\xd9\xe6\x09\x66\x63\x59\xcf\x3c\x0d\x58\xe7\x58\xb1\x94\xf9\x41\x96\xc9\x66\x99\xac\x98\x2f\x3d\xbc\x18\xa7\xca\x79\xc9\x93\x34\xdd\x81\x67\x19\x5b\x71\x3f\x6f\x30\x13\xbd\x6b\xb5\x09\x56\xde\x87\x1a\xbf\xdf\xb7\xbc\x7a\x3f\xe9\x99\x3e\xe3\x4e\x1d\x7d\x9f\x7e\x13\xfa\x07\x5d\xff\x78\xde\x0d\x62\xc9\x36\x2d\x71\x71\x31\x6c\xc3\x90\x70\x54\xef\x7d\x0e\x4e\x76\x64\x9d\xff\x36\x1e\xa4\x6b\x87\x12\x3f\xb5\xa2\xd7\x54\xa5\x1f\xe2\x59\x60\xf7\xf7\xf5\x3f\xb2\xa3\x99\x3f\xda\xbf\xc8\x75\xef\xcf\xe5\x1d\xd9\xbe\x64\x9d\xc8\xf8\xe5\xf1\x9c\x95\xc1\xa4\xf6\x4e\xd7\xf3\xec\xc8\x18\x6d\xfe\x98\xad\x8f\xc3\x9c\x48\x27\xc0\xee\xa4\x92\xb6\x3d\x8d\xfb\x5f\xa7\x12\xd8\xdb\x9c\x7f\xda\x32\xc4\x6f\x04\x3c\x7f\xb6\xf8\xdf\x87\xb5\xce\x32\x5e\x5e\x45\xbf\x00\x89\x77\x20\xf5\x1e\x05\x00\x00")
+
+func _1528395597_add_changeset_actionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395597_add_changeset_actionsUpSql,
+		"1528395597_add_changeset_actions.up.sql",
+	)
+}
+
+func _1528395597_add_changeset_actionsUpSql() (*asset, error) {
+	bytes, err := _1528395597_add_changeset_actionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395597_add_changeset_actions.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x39, 0x42, 0x99, 0x3, 0x37, 0xa1, 0xd4, 0xae, 0x37, 0x32, 0xd0, 0x2d, 0x59, 0xb6, 0x61, 0x89, 0xcf, 0x13, 0xb6, 0x5c, 0xe2, 0xf4, 0x22, 0x49, 0xa6, 0x89, 0x45, 0x1e, 0x3e, 0xbe, 0xb1, 0xc0}}
+	return a, nil
+}
+
+var __1528395598_add_repo_permissionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x2d\xc8\x8f\x2f\x48\x2d\xca\xcd\x2c\x2e\xce\xcc\xcf\x2b\xb6\xc6\xae\xac\xb4\x38\xb5\x28\x1e\x53\x2d\x97\xb3\xbf\xaf\xaf\x67\x88\x35\x17\x00\xb7\x2c\x9d\xff\x64\x00\x00\x00")
+
+func _1528395598_add_repo_permissionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395598_add_repo_permissionsDownSql,
+		"1528395598_add_repo_permissions.down.sql",
+	)
+}
+
+func _1528395598_add_repo_permissionsDownSql() (*asset, error) {
+	bytes, err := _1528395598_add_repo_permissionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395598_add_repo_permissions.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd0, 0xd0, 0x4f, 0xd6, 0x58, 0x7a, 0xfc, 0x14, 0x68, 0x87, 0xfb, 0xa7, 0xa, 0xb3, 0xd2, 0xa1, 0x8a, 0x85, 0xb4, 0x29, 0x8c, 0xca, 0xd6, 0x6d, 0x70, 0xa1, 0x97, 0x32, 0xf7, 0x9b, 0x8a, 0xb5}}
+	return a, nil
+}
+
+var __1528395598_add_repo_permissionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9d\x52\xc1\x8e\x82\x30\x10\xbd\xf3\x15\x73\xd4\x04\xfd\x01\x4f\x88\x75\x43\x56\x31\x0b\x98\xec\x8d\x54\x98\x95\x66\x03\xed\xb6\xc5\x15\xbf\x7e\xdb\x6a\x56\x8c\xb8\x9b\x98\x70\xa0\x79\x33\x6f\xe6\xbd\x79\x73\xf2\x12\xc5\x33\xcf\x9b\x4c\x20\xab\x10\x24\x0a\xae\x98\xe6\x92\xa1\x02\x0a\xad\x42\x09\x15\xb5\xbf\x02\x65\xcd\x94\x62\xbc\x01\xf3\xd1\xa2\xe0\xb2\x64\xcd\x1e\x34\x37\x6f\x04\xda\xea\xea\x64\x59\x84\xe4\x07\x56\xa2\xf4\xc1\xb5\x49\x4e\xa5\x2d\xdb\x31\x5d\x53\x01\xfc\xc3\x8d\x80\x68\xa1\xa6\x90\x76\x4d\x81\x25\xb0\x06\xb4\x19\xbd\xa3\xc5\xe7\x5e\xf2\xb6\x29\xa7\x5e\x98\x90\x20\x23\x90\x05\xf3\x15\x81\x68\x09\xf1\x26\x03\xf2\x1e\xa5\x59\xea\x56\xca\x2d\x47\x7e\xdd\x48\xc1\xc8\x83\x33\xc2\x2c\x9f\xc6\xbd\xd9\xdb\x36\xc5\xdb\xd5\x0a\x12\xb2\x24\x09\x89\x43\x72\xee\x56\x23\x56\x8e\x61\x13\xc3\x82\xac\x88\x99\x12\x06\x69\x18\x2c\x88\x6f\x28\x7a\x22\x35\x1e\xf5\x2f\x85\xc5\x4c\xe7\x81\x15\x98\xeb\x4e\xe0\x63\xd4\xcc\xbf\xc3\xdc\xb6\xac\x54\xb0\xeb\x34\xd2\x1b\xa8\xa6\xc7\xfc\x02\xdf\xed\xed\x68\x9d\x45\x39\xd5\xa0\x59\x8d\x4a\xd3\x5a\xc0\x37\xd3\x95\x7b\xc2\xc9\x3a\xdf\x2f\x0f\x37\x71\x9a\x25\x41\x14\x67\xc3\x3e\xe5\x6d\xc3\xbe\x5a\x84\x6d\x1c\xbd\x6d\x09\x8c\x2e\x96\xf9\x3d\xe1\xfe\x8d\x50\xbf\x27\x6c\xec\x8d\xaf\x41\x71\x46\x9a\xbb\x99\xcd\x2a\x7a\xc0\xfb\x80\x5c\xa3\xd4\x3d\x8a\x82\xe5\x72\x09\x73\x69\x78\x45\xa1\xa1\x15\x36\x50\x25\xd5\x78\x96\x39\xa8\xe2\xcf\x7c\x0c\x46\xe3\x91\xc5\xfd\x68\xd8\x9a\xe7\x92\x71\x71\x71\xe8\xbe\xad\xb0\x5a\x9e\x3a\xe0\x7f\xb7\xbb\x68\xea\xdf\xee\x7c\xa1\x70\xb3\x5e\x47\xd9\xcc\xfb\x01\xec\xdf\x81\xa4\xdb\x03\x00\x00")
+
+func _1528395598_add_repo_permissionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395598_add_repo_permissionsUpSql,
+		"1528395598_add_repo_permissions.up.sql",
+	)
+}
+
+func _1528395598_add_repo_permissionsUpSql() (*asset, error) {
+	bytes, err := _1528395598_add_repo_permissionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395598_add_repo_permissions.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x32, 0xa3, 0xd0, 0xd0, 0x91, 0x71, 0x7e, 0x5c, 0xb, 0x8, 0x90, 0x24, 0xd1, 0x7, 0xfe, 0xea, 0x67, 0x7a, 0xf1, 0x52, 0xa6, 0xff, 0x64, 0xc6, 0xd9, 0xe4, 0x62, 0x82, 0x3b, 0x9d, 0x0, 0x15}}
+	return a, nil
+}
+
+var __1528395599_add_repo_explicit_permissionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x2d\xc8\x8f\x4f\xad\x28\xc8\xc9\x4c\xce\x2c\x89\x2f\x48\x2d\xca\xcd\x2c\x2e\xce\xcc\xcf\x2b\x06\x6a\x70\xf6\xf7\xf5\xf5\x0c\xb1\xe6\x02\x00\x84\x4a\xb0\x0b\x41\x00\x00\x00")
+
+func _1528395599_add_repo_explicit_permissionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395599_add_repo_explicit_permissionsDownSql,
+		"1528395599_add_repo_explicit_permissions.down.sql",
+	)
+}
+
+func _1528395599_add_repo_explicit_permissionsDownSql() (*asset, error) {
+	bytes, err := _1528395599_add_repo_explicit_permissionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395599_add_repo_explicit_permissions.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x60, 0x73, 0x8c, 0x95, 0xcb, 0x1f, 0xd, 0x5f, 0xe4, 0x6f, 0x81, 0xd8, 0xdb, 0x5, 0x22, 0x80, 0x3b, 0x11, 0xb3, 0x4c, 0xf1, 0xf6, 0x26, 0xd3, 0xf, 0xc2, 0x54, 0xd0, 0x80, 0x8d, 0xed, 0x9}}
+	return a, nil
+}
+
+var __1528395599_add_repo_explicit_permissionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa5\x52\xc1\x6e\x82\x40\x14\xbc\xf3\x15\x73\x84\x44\xfb\x03\x36\x4d\x10\xd7\x4a\x8a\x4b\x0b\x98\x7a\x23\x28\xaf\xba\xa9\x80\xdd\x5d\xa3\xf6\xeb\xbb\xa0\x28\x17\x4d\xd3\x1e\xf7\x4d\x66\xde\xbc\x99\x1d\xb2\x67\x9f\x0f\x2c\xab\xdf\xc7\x2b\xc9\x42\x28\x25\xaa\x52\xa1\x2a\x21\x69\x5b\x29\xa1\x2b\x29\x48\x61\x25\xb3\x52\x53\x0e\x3a\x6c\x37\x62\x29\xf4\xe6\x88\xc5\x11\x06\x26\x64\x79\x21\x4a\xd5\x83\xae\x90\x61\xa7\x48\xd6\x5a\x95\xac\xdf\x7a\x4d\x28\xa8\x58\x90\x34\x8a\x1f\xc8\x4a\x33\x5f\x3d\x58\x5e\xc4\xdc\x84\x21\x71\x87\x01\x83\x3f\x06\x0f\x13\xb0\xb9\x1f\x27\x71\xb3\x34\x6d\x97\xa4\xdb\x8e\x23\xdb\xc2\x09\x15\x39\x84\xf1\xb2\x22\xd9\x10\xf9\x2c\x08\x10\xb1\x31\x8b\x18\xf7\xd8\x49\xc1\x16\xb9\x83\x90\x63\xc4\x02\x66\x16\x79\x6e\xec\xb9\x23\xd6\x33\x0a\xb5\xbf\xae\x42\x87\x58\x43\xea\x36\xd3\x38\xbf\x41\x34\xc8\x1d\xde\xf5\x06\x68\x3a\xe8\x8b\xe7\x1a\x5b\x4a\xca\x4c\xaa\x69\xa6\xa1\x45\x41\x4a\x67\xc5\x16\x7b\xa1\xd7\xcd\x13\xdf\x55\x49\xd7\x1b\x47\x6c\xec\xce\x82\x04\x65\xb5\xb7\x9d\x9a\xed\x85\x3c\x4e\x22\xd7\xe7\xc9\xed\xd8\xd2\x53\x71\x94\x2e\xd7\xb4\xfc\x84\x37\x61\xde\x0b\x6c\xbb\x8d\xc1\x8f\x1b\x6d\x07\x8f\x4f\xb0\xcf\x17\xb6\x33\xc7\x72\xcc\xbf\x38\x77\x35\xe3\xfe\xdb\xcc\x94\xc5\x47\x6c\xfe\xdb\xca\xd2\x66\xcb\xae\x14\x5f\x3b\xaa\xb3\xb9\xd3\xed\xb9\xd8\x5e\x27\xad\x5e\xdb\x95\x83\xf7\x89\x89\x1a\x5d\xcf\xe7\x4c\x06\xff\xb2\x57\xdf\xfb\x77\x77\xa7\xb4\x5a\x73\x9d\xec\x2e\xde\x2c\x2f\x9c\x4e\xfd\x64\x60\xfd\x00\x35\xfa\x0b\x7c\x64\x03\x00\x00")
+
+func _1528395599_add_repo_explicit_permissionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395599_add_repo_explicit_permissionsUpSql,
+		"1528395599_add_repo_explicit_permissions.up.sql",
+	)
+}
+
+func _1528395599_add_repo_explicit_permissionsUpSql() (*asset, error) {
+	bytes, err := _1528395599_add_repo_explicit_permissionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395599_add_repo_explicit_permissions.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x50, 0x51, 0xbd, 0xfb, 0x92, 0xbe, 0x95, 0x59, 0x98, 0xfe, 0x8b, 0x8a, 0xd3, 0x75, 0x4f, 0xd7, 0x31, 0x1, 0x4a, 0xfd, 0x85, 0xea, 0x12, 0x1f, 0xb2, 0xdf, 0xc9, 0xb9, 0x9, 0x34, 0x68, 0x1}}
+	return a, nil
+}
+
+var __1528395600_add_access_tokens_restrictionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x4c\x4e\x4e\x2d\x2e\x8e\x2f\xc9\xcf\x4e\xcd\x2b\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x2d\xc8\x8f\x2f\x48\x2c\x29\x49\x2d\xca\x2b\xb6\x26\x59\x7b\x6a\x45\x41\x66\x51\x6a\x71\x7c\x62\x09\xd0\x6e\x67\x7f\x5f\x5f\xcf\x10\x6b\x2e\x00\x36\xab\x5c\x73\x8c\x00\x00\x00")
+
+func _1528395600_add_access_tokens_restrictionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395600_add_access_tokens_restrictionsDownSql,
+		"1528395600_add_access_tokens_restrictions.down.sql",
+	)
+}
+
+func _1528395600_add_access_tokens_restrictionsDownSql() (*asset, error) {
+	bytes, err := _1528395600_add_access_tokens_restrictionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395600_add_access_tokens_restrictions.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x1c, 0xa9, 0x1a, 0xbd, 0x71, 0x38, 0x8, 0xa3, 0x3, 0x7b, 0x41, 0x20, 0x57, 0x13, 0xbd, 0xf5, 0xf, 0x4c, 0x8d, 0xc, 0xca, 0x8d, 0x1, 0xcd, 0x1b, 0x96, 0xd7, 0x24, 0x3, 0x5, 0xeb, 0x8f}}
+	return a, nil
+}
+
+var __1528395600_add_access_tokens_restrictionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9d\x8e\x41\x0a\x83\x30\x14\x05\xf7\x9e\xe2\xed\x3c\x44\x56\x51\x63\x09\xc4\x04\x34\x42\xa1\x14\x09\xf2\xa1\x52\xd4\x60\x3e\x54\x5a\x7a\xf7\x8a\x47\xe8\xf2\x31\xc3\xf0\x0a\x75\xd1\x56\x64\x99\x34\x5e\xb5\xf0\xb2\x30\x0a\x61\x1c\x29\xa5\x81\xd7\x27\x2d\x09\xb2\xaa\x50\x3a\xd3\x37\x16\xba\x86\x75\x1e\xea\xaa\x3b\xdf\x61\xa3\xb8\x0e\x31\x30\xd3\x76\x68\x4c\x3b\xdf\xee\x27\xb7\xbd\x31\xa8\x54\x2d\x7b\xe3\x91\x7f\xbe\xb9\xf8\x27\x4f\x7b\x9c\x36\x4a\x43\x60\xf0\x34\x53\xe2\x30\x47\xbc\x26\x7e\x9c\x13\xef\x75\xa1\xe3\x77\xe9\x9a\x46\x7b\x91\xfd\x00\x40\x1e\x86\x9f\xc8\x00\x00\x00")
+
+func _1528395600_add_access_tokens_restrictionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395600_add_access_tokens_restrictionsUpSql,
+		"1528395600_add_access_tokens_restrictions.up.sql",
+	)
+}
+
+func _1528395600_add_access_tokens_restrictionsUpSql() (*asset, error) {
+	bytes, err := _1528395600_add_access_tokens_restrictionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395600_add_access_tokens_restrictions.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfb, 0xc5, 0x10, 0xb, 0x22, 0x6b, 0xb9, 0xaa, 0x89, 0xbc, 0xe4, 0x6f, 0x39, 0xc9, 0xdc, 0x26, 0x19, 0x6d, 0xdd, 0xb4, 0xc8, 0xaf, 0x98, 0x31, 0x6a, 0xc3, 0x69, 0x9f, 0xb7, 0x13, 0xb1, 0x6b}}
+	return a, nil
+}
+
+var __1528395601_add_audit_logDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\x2c\x4d\xc9\x2c\x89\xcf\xc9\x4f\xb7\x86\xc8\xbb\x85\xfa\x39\x87\x78\xfa\xfb\x61\x53\x12\x9f\x58\x50\x90\x9a\x97\x12\x9f\x9f\x97\x53\xa9\xa1\x09\x34\xd1\xd9\xdf\xd7\xd7\x33\xc4\x9a\x0b\x00\xdf\x2f\xe8\xfc\x62\x00\x00\x00")
+
+func _1528395601_add_audit_logDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395601_add_audit_logDownSql,
+		"1528395601_add_audit_log.down.sql",
+	)
+}
+
+func _1528395601_add_audit_logDownSql() (*asset, error) {
+	bytes, err := _1528395601_add_audit_logDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395601_add_audit_log.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x59, 0x81, 0xea, 0x70, 0x10, 0x5c, 0xc8, 0xeb, 0xe5, 0x63, 0x79, 0xcb, 0x1c, 0xc6, 0x7d, 0x2, 0xe7, 0x7b, 0xb6, 0x13, 0x55, 0xd8, 0xba, 0x52, 0x8, 0x94, 0x44, 0x8b, 0x44, 0x56, 0xc, 0xf0}}
+	return a, nil
+}
+
+var __1528395601_add_audit_logUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x95\x52\xc1\x8e\x9b\x30\x10\xbd\xf3\x15\x73\x88\x94\x44\x4a\xfa\x03\x39\x39\x30\xa1\xa8\xc4\x20\x03\xea\xee\x09\x39\x61\x4a\xbc\x25\x98\x82\xd3\x74\x5b\xf5\xdf\x6b\xd8\xdd\x86\x74\x37\x55\xcb\x09\xcf\xbc\xf7\xe6\xcd\xb3\xd7\xe8\x07\x7c\xe5\x38\xae\x40\x96\x22\xa4\x6c\x1d\x22\x04\x1b\xe0\x51\x0a\x78\x17\x24\x69\x02\xf2\x54\x28\x93\x57\xba\x84\x99\x03\xf6\x53\x05\xec\x54\xd9\x51\xab\x64\x05\xb1\x08\xb6\x4c\xdc\xc3\x07\xbc\x5f\x0c\xdd\xe5\x12\xb8\x36\x20\xe1\x93\x6e\x49\x95\x35\x7c\xa6\xc7\x05\x74\x1a\xcc\x41\x1a\xa0\xda\xb4\x8a\x3a\xd0\x27\x53\xa9\xaf\x64\x8b\x04\x27\x2b\xd5\xc1\xf9\xa0\xa1\xa1\xd6\xb2\x8e\x54\xf4\xf5\xe3\xbb\x41\x4f\xee\x8d\x6e\xf3\x1e\x93\xdb\xc1\xaa\x36\x54\x52\x3b\xd8\xe3\x59\x18\x2e\x5e\x30\x4a\xd7\x60\xe8\x9b\xf9\xa3\xd3\x9d\x76\x0f\xb4\x37\xd7\x2d\xf0\x70\xc3\xb2\x30\x85\xe9\xf4\x09\x55\x48\x23\xe1\xa1\xd3\xf5\xee\x0d\xcc\x8f\x9f\xcf\xa8\x7d\x4b\xd2\x50\x91\xdb\x35\x8c\x3a\x52\x67\xe4\xb1\x81\xb3\x32\x87\xe1\x08\xdf\x75\x4d\xaf\xe9\xb5\x3e\xcf\xe6\xce\xfc\x92\x70\xc0\x3d\xbc\xbb\x95\x70\x3e\x9a\x11\xf1\x51\xf2\x59\x12\x70\x1f\x76\xa6\x25\x82\xd9\x05\x64\x75\xff\x49\xf6\x3a\xc4\xdb\xca\x57\xb8\xff\x10\xef\xd3\xff\xab\xaa\x05\x8c\x32\x88\x04\x08\x8c\x43\xe6\x22\x6c\x32\xee\xa6\xc1\x98\x9b\xcb\xa6\xa1\xba\xc8\x75\x5d\x3d\xce\xe6\x16\x98\x66\x82\x27\x60\xdf\x4d\x69\x6f\x7e\xb8\x89\x90\x71\x3f\x63\x3e\x42\x53\x35\x65\xf7\xa5\x1a\x8a\x2c\x81\xc9\xc4\x59\xf7\xef\x79\x38\x0b\x16\x24\x68\xdd\xba\x18\x0f\x03\xa6\x17\x77\xaa\x83\xa7\x21\xcb\x7e\xc8\x74\xe5\x20\xf7\x56\xce\x64\x62\x1d\x7a\x22\x8a\x21\x15\x81\xef\xa3\xe8\x37\x7e\xbd\xed\xc5\xdd\xd5\xca\xbf\xc3\x7a\x21\xbf\x4d\x59\xe3\x26\x12\x08\x59\xec\x3d\x07\xe1\x61\x88\xfd\xdf\x38\x3d\x0b\x01\x64\xee\x7b\x10\xd1\x47\xeb\x00\xdd\xcc\x22\x62\x11\xb9\xe8\x65\x96\x7c\x23\xaa\x3e\xdf\x68\xbb\x0d\xd2\x95\xf3\x0b\xee\x2b\xb8\x25\xd6\x03\x00\x00")
+
+func _1528395601_add_audit_logUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395601_add_audit_logUpSql,
+		"1528395601_add_audit_log.up.sql",
+	)
+}
+
+func _1528395601_add_audit_logUpSql() (*asset, error) {
+	bytes, err := _1528395601_add_audit_logUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395601_add_audit_log.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x19, 0x60, 0x3a, 0xca, 0x0, 0x6b, 0x9, 0x76, 0x1d, 0x7f, 0xe5, 0xb7, 0x25, 0x6b, 0xf2, 0x34, 0x4d, 0x91, 0xc9, 0xe1, 0x30, 0xd8, 0x22, 0x3a, 0xb1, 0x46, 0xc9, 0x44, 0x7d, 0x8e, 0x92, 0x98}}
+	return a, nil
+}
+
+var __1528395593_add_repo_metadata_columnsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8d\xcf\x4d\x0f\x82\x20\x18\xc0\xf1\x3b\x9f\x82\xef\xc1\x09\x95\x1a\x1b\x82\x03\xda\xbc\x31\x22\x47\xcf\x72\xea\x84\x5a\x7d\xfb\x9a\x1e\xea\x64\xde\x7f\xff\xe7\xa5\x60\x47\x2e\x09\x42\x95\x56\x0d\xe6\xb2\x62\x2d\xe6\x07\xcc\x5a\x6e\xac\xc1\x73\x37\x8d\xae\xf7\x43\xbc\xfb\xd8\x39\xb8\x3c\xc9\x86\x7b\x40\x82\x33\xf4\x90\x5f\xff\x64\x1e\x27\x08\xc9\x45\x18\x56\x89\xa8\xb0\x4c\x63\x4b\x0b\xc1\x16\x81\x97\xb6\x54\xd2\x58\x4d\xb9\xb4\x1b\xab\xc2\xb5\x0b\xb7\x8d\x11\xe2\x54\xcb\x9f\xfc\x5b\x92\xbd\x49\xca\x7e\x4e\xbb\xf5\xfa\xdb\xe7\xa0\x52\xd5\x35\xb7\x04\xbd\x01\x0a\x78\x96\xa0\x61\x01\x00\x00")
+
+func _1528395593_add_repo_metadata_columnsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395593_add_repo_metadata_columnsDownSql,
+		"1528395593_add_repo_metadata_columns.down.sql",
+	)
+}
+
+func _1528395593_add_repo_metadata_columnsDownSql() (*asset, error) {
+	bytes, err := _1528395593_add_repo_metadata_columnsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395593_add_repo_metadata_columns.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x90, 0x24, 0x9d, 0x2f, 0x2, 0xac, 0xfc, 0x86, 0xe2, 0x19, 0x7f, 0xa0, 0x22, 0x85, 0x49, 0xda, 0xf4, 0x3f, 0x4d, 0x19, 0x89, 0xb3, 0x5d, 0x0, 0x7f, 0x73, 0x70, 0xca, 0x7a, 0xc9, 0xe1, 0xa9}}
+	return a, nil
+}
+
+var __1528395593_add_repo_metadata_columnsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x95\x91\x5f\x4f\xc2\x30\x14\xc5\xdf\xf7\x29\xee\xdb\x20\x01\xe2\x3b\x4f\x83\x15\x5c\x1c\x9d\x61\x5d\x62\x62\x0c\xe9\xba\x0e\x1a\xcb\xba\x74\xe5\xcf\x34\x7e\x77\xbb\x4d\x14\xd1\x44\x79\x6b\x72\xce\xfd\xdd\x73\x6e\x27\x68\x1e\xe0\xb1\xe3\x0c\x87\x80\x95\xde\x52\x29\x5e\x78\x06\x4c\x65\x1c\x36\xaa\x32\xb0\xe5\x86\x66\xd4\x50\xe0\x47\xa3\x29\x33\x56\x4c\x6b\xd0\xbc\x54\xc3\x5d\x69\x05\xae\x21\xd7\x6a\x0b\x66\xc3\x41\xd3\x43\xc3\x29\x69\x2d\x15\xcd\x2a\xa8\x8c\xd2\xd6\x2f\x8a\xd6\x3f\x3a\xa1\x06\x50\x29\xeb\xa7\x06\x84\x01\x46\x0b\x48\x39\xf0\x3c\x17\x4c\xf0\xc2\xc8\x1a\x72\x21\x2d\xd6\x0e\xaa\x62\xe4\x78\x21\x41\x4b\x20\xde\x24\x44\x2d\x05\x3c\xdf\x87\x69\x14\x26\x0b\x0c\xc1\x0c\x70\x44\x00\x3d\x04\x31\x89\xc1\xa8\x52\xb0\x0a\x8c\x0d\xfa\xf8\xd4\x0a\x38\x09\x43\xf0\xd1\xcc\x4b\x42\x02\xee\xeb\x9b\x3b\xbe\x02\x57\x19\xaa\x2b\x9b\xdd\xf0\xb5\xed\xf8\x03\x77\x73\x0d\x6b\x2f\x2a\x91\x0a\x29\x4c\xdd\xc6\xb3\xe7\xbe\x9c\x75\xfc\x65\x74\x6f\x87\x71\x4c\x96\x5e\x80\x49\x03\xf8\x18\x6e\xe4\xd5\x17\x61\xc5\x36\x9c\x3d\x0f\x9c\x6e\xd9\xa7\xff\x57\x17\x4c\x6f\xd1\xf4\x0e\x7a\x67\xfb\x03\x0c\x3d\xb7\xdc\xa5\x52\x30\x77\x00\x6e\xa9\xc5\xde\x7e\x62\xf3\x6c\xaa\xea\x82\x4a\xb7\xdf\xb7\x01\xa7\x4b\xe4\x11\x64\xed\x3e\x7a\xb8\x68\xd3\xae\xea\xae\xbd\x5a\x8b\x62\x25\xb2\x23\x44\xb8\x3b\x41\x12\x07\x78\x0e\xf3\x66\x4b\xe7\xb0\xac\xbf\x50\x67\xa9\xcf\x51\x67\xa9\xff\x01\x91\xb4\x58\xef\xe8\x9a\x7f\x47\x48\x75\xe0\xba\x77\xd2\xba\x62\xd1\x62\x11\x90\xb1\xf3\x0e\xb5\x97\xc0\x14\xf9\x02\x00\x00")
+
+func _1528395593_add_repo_metadata_columnsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395593_add_repo_metadata_columnsUpSql,
+		"1528395593_add_repo_metadata_columns.up.sql",
+	)
+}
+
+func _1528395593_add_repo_metadata_columnsUpSql() (*asset, error) {
+	bytes, err := _1528395593_add_repo_metadata_columnsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395593_add_repo_metadata_columns.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xca, 0xfc, 0x33, 0x12, 0x57, 0x3b, 0xd1, 0xfd, 0x5, 0x21, 0xd5, 0xce, 0xcc, 0x2c, 0xfc, 0xaa, 0x62, 0xe6, 0x7b, 0x6d, 0xbd, 0x18, 0x6a, 0x60, 0xe0, 0x69, 0xf8, 0x18, 0xe5, 0x26, 0xa8, 0xb8}}
+	return a, nil
+}
+
+var __1528395602_add_user_suspensionDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x2d\x4e\x2d\x2a\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x2e\x2d\x2e\x48\xcd\x4b\x49\x4d\x89\x4f\x2c\xb1\x26\x5a\x57\x66\x5e\x59\x62\x4e\x66\x4a\x62\x09\x50\x5f\x71\x6a\x71\x71\x66\x7e\x5e\x31\xd8\x00\x2e\x67\x7f\x5f\x5f\xcf\x10\x6b\x2e\x00\xe9\x39\xdf\xb3\x88\x00\x00\x00")
+
+func _1528395602_add_user_suspensionDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395602_add_user_suspensionDownSql,
+		"1528395602_add_user_suspension.down.sql",
+	)
+}
+
+func _1528395602_add_user_suspensionDownSql() (*asset, error) {
+	bytes, err := _1528395602_add_user_suspensionDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395602_add_user_suspension.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf7, 0x51, 0x70, 0xa7, 0xa9, 0x5b, 0xcc, 0x2a, 0xe7, 0x70, 0xa, 0x89, 0xf1, 0x13, 0x91, 0x18, 0xb9, 0xe1, 0xb5, 0x15, 0xc2, 0x2c, 0x88, 0xb6, 0x40, 0xe2, 0x33, 0xe2, 0xdd, 0x3a, 0xbf, 0xa4}}
+	return a, nil
+}
+
+var __1528395602_add_user_suspensionUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x95\xcc\x4b\x0a\xc2\x30\x14\x05\xd0\x79\x56\x71\xf7\x91\x51\xda\x46\x09\xe4\x03\x36\x82\x33\x09\xe4\x81\x01\x9b\x96\xbe\xd4\x42\x57\xaf\xb8\x00\xc1\xe1\x99\x9c\x4e\x9f\x8d\x97\x42\x28\x1b\xf5\x05\x51\x75\x56\x63\x63\x5a\x19\x6a\x18\xd0\x07\x7b\x75\x1e\xe6\x04\x1f\x22\xf4\xcd\x8c\x71\x04\x6f\xbc\x50\xcd\x94\xef\xa9\xa1\x95\x89\xb8\xa5\x69\xc1\x5e\xda\xe3\x4b\x1c\x73\x25\xf9\xcf\x58\xea\x2b\x3d\x4b\x4e\xed\x73\x32\x31\x97\xb9\xf2\xef\x5c\xf4\xc1\x39\x13\xa5\x78\x03\x3e\x79\x34\x37\xc0\x00\x00\x00")
+
+func _1528395602_add_user_suspensionUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395602_add_user_suspensionUpSql,
+		"1528395602_add_user_suspension.up.sql",
+	)
+}
+
+func _1528395602_add_user_suspensionUpSql() (*asset, error) {
+	bytes, err := _1528395602_add_user_suspensionUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395602_add_user_suspension.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xeb, 0x99, 0xe, 0xdd, 0x7c, 0x83, 0xb3, 0xe4, 0x11, 0x50, 0xd0, 0xf2, 0x2d, 0xf9, 0x98, 0xde, 0x3c, 0xe2, 0x5d, 0xac, 0x73, 0x90, 0x72, 0x20, 0x1, 0xc1, 0xff, 0xf5, 0x58, 0xe1, 0xc4, 0x92}}
+	return a, nil
+}
+
+var __1528395603_add_user_totpDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x2d\x4e\x2d\x8a\x2f\xc9\x2f\x29\x00\x2a\x70\xf6\xf7\xf5\xf5\x0c\xb1\xe6\x02\x00\xce\xa7\x28\x7a\x31\x00\x00\x00")
+
+func _1528395603_add_user_totpDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395603_add_user_totpDownSql,
+		"1528395603_add_user_totp.down.sql",
+	)
+}
+
+func _1528395603_add_user_totpDownSql() (*asset, error) {
+	bytes, err := _1528395603_add_user_totpDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395603_add_user_totp.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x33, 0xa4, 0x39, 0x17, 0xf5, 0xab, 0x7a, 0x7a, 0xf8, 0x8a, 0x7, 0x70, 0xc6, 0xb5, 0xc7, 0xdd, 0x75, 0x85, 0x80, 0x2, 0x1f, 0x16, 0x4d, 0xd2, 0xd9, 0xac, 0x2e, 0xb5, 0x46, 0x59, 0xbe, 0x24}}
+	return a, nil
+}
+
+var __1528395603_add_user_totpUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7d\x91\xc1\x4e\xc3\x30\x10\x44\xef\xf9\x8a\xb9\xd1\x4a\x80\xb8\x73\x0a\xc5\x45\x11\x69\x8a\x52\x23\x81\x10\x8a\x1c\x67\x69\xad\xb6\x76\x65\x6f\x81\x82\xf8\x77\x96\x04\xb8\x20\xe1\xdb\x4a\xfb\x66\x66\xc7\x17\xea\xaa\xa8\xce\xb3\x6c\x52\xab\x5c\x2b\xe8\xfc\xa2\x54\x28\xa6\xa8\xe6\x1a\xea\xae\x58\xe8\x05\xf6\x89\x62\xc3\x81\x77\x18\x65\x90\xd7\xcf\xae\x83\xf3\x4c\x4b\x8a\xb8\xa9\x8b\x59\x5e\xdf\xe3\x5a\xdd\xa3\x56\x53\x55\xab\x6a\xa2\x06\x2c\x8d\x5c\x37\xc6\xbc\xc2\xa5\x2a\x95\xc8\x4f\xf2\xc5\x24\xbf\x54\xc7\xbd\xce\xc9\x09\xf4\x8a\xa0\xe7\xfa\x06\x89\x6c\x24\x86\x4b\xf0\x81\x41\xde\xc6\xc3\x8e\xa9\x83\x61\x44\x4a\x8c\xd1\xc6\xad\x09\x2c\xeb\x1c\xd6\xe4\x93\x98\x83\x5e\x99\xa2\x37\x9b\x1f\x31\xf1\x7b\x76\x96\x60\x83\x7f\x72\xcb\x34\x3e\x46\x0a\x30\xd6\x52\x4a\x42\xf5\x70\x67\xd8\xb4\x26\x11\xb6\x7b\x11\x6d\xa9\x17\x8f\xce\x8a\xd5\x69\x2f\xf3\x9d\x83\x45\xbb\xaf\xa0\xba\x2d\xcb\x21\x6d\x24\x1b\x9e\x29\x1e\x1a\x1b\x3a\x6a\x56\x26\xad\x28\xa1\x3d\x30\x99\x87\xc7\xdf\x55\xb9\x73\x9a\xdf\x96\x1a\x47\xef\x1f\x47\x03\xb7\x31\x89\x1b\xe9\xa2\x13\x70\x2f\x8d\x45\xb4\x6e\x29\xd5\xfd\x65\xce\x06\x40\x02\x18\xc9\xd3\xc8\xe9\xec\xb6\x92\xcf\x6c\x77\x78\x71\xbc\xea\x47\xbc\x05\x4f\x7f\x59\x1f\x5e\x46\xe3\x81\x27\x6f\xda\xcd\xff\x7c\x36\xfe\xfa\xf1\xf9\x6c\x56\xe8\xf3\xec\x13\x1d\x60\x14\x86\x02\x02\x00\x00")
+
+func _1528395603_add_user_totpUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395603_add_user_totpUpSql,
+		"1528395603_add_user_totp.up.sql",
+	)
+}
+
+func _1528395603_add_user_totpUpSql() (*asset, error) {
+	bytes, err := _1528395603_add_user_totpUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395603_add_user_totp.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x34, 0x9d, 0x8e, 0xb, 0xe0, 0x3f, 0x2, 0xc1, 0x34, 0x46, 0xb0, 0x8, 0xd5, 0xc7, 0x43, 0x1f, 0x3d, 0xff, 0xca, 0xdf, 0xfd, 0x1a, 0x9, 0xd8, 0xa0, 0x67, 0x5b, 0x89, 0x77, 0x94, 0x4f, 0xea}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -5839,17 +6301,63 @@ var _bindata = map[string]func() (*asset, error){
 	"1528395592_add_deletion_triggers_to_campaigns_and_changesets.down.sql": _1528395592_add_deletion_triggers_to_campaigns_and_changesetsDownSql,
 
 	"1528395592_add_deletion_triggers_to_campaigns_and_changesets.up.sql": _1528395592_add_deletion_triggers_to_campaigns_and_changesetsUpSql,
+
+	"1528395594_add_repo_missing_upstream.down.sql": _1528395594_add_repo_missing_upstreamDownSql,
+
+	"1528395594_add_repo_missing_upstream.up.sql": _1528395594_add_repo_missing_upstreamUpSql,
+
+	"1528395595_add_campaign_plans.down.sql": _1528395595_add_campaign_plansDownSql,
+
+	"1528395595_add_campaign_plans.up.sql": _1528395595_add_campaign_plansUpSql,
+
+	"1528395596_add_changeset_events.down.sql": _1528395596_add_changeset_eventsDownSql,
+
+	"1528395596_add_changeset_events.up.sql": _1528395596_add_changeset_eventsUpSql,
+
+	"1528395597_add_changeset_actions.down.sql": _1528395597_add_changeset_actionsDownSql,
+
+	"1528395597_add_changeset_actions.up.sql": _1528395597_add_changeset_actionsUpSql,
+
+	"1528395598_add_repo_permissions.down.sql": _1528395598_add_repo_permissionsDownSql,
+
+	"1528395598_add_repo_permissions.up.sql": _1528395598_add_repo_permissionsUpSql,
+
+	"1528395599_add_repo_explicit_permissions.down.sql": _1528395599_add_repo_explicit_permissionsDownSql,
+
+	"1528395599_add_repo_explicit_permissions.up.sql": _1528395599_add_repo_explicit_permissionsUpSql,
+
+	"1528395600_add_access_tokens_restrictions.down.sql": _1528395600_add_access_tokens_restrictionsDownSql,
+
+	"1528395600_add_access_tokens_restrictions.up.sql": _1528395600_add_access_tokens_restrictionsUpSql,
+
+	"1528395601_add_audit_log.down.sql": _1528395601_add_audit_logDownSql,
+
+	"1528395601_add_audit_log.up.sql": _1528395601_add_audit_logUpSql,
+
+	"1528395593_add_repo_metadata_columns.down.sql": _1528395593_add_repo_metadata_columnsDownSql,
+
+	"1528395593_add_repo_metadata_columns.up.sql": _1528395593_add_repo_metadata_columnsUpSql,
+
+	"1528395602_add_user_suspension.down.sql": _1528395602_add_user_suspensionDownSql,
+
+	"1528395602_add_user_suspension.up.sql": _1528395602_add_user_suspensionUpSql,
+
+	"1528395603_add_user_totp.down.sql": _1528395603_add_user_totpDownSql,
+
+	"1528395603_add_user_totp.up.sql": _1528395603_add_user_totpUpSql,
 }
 
 // AssetDir returns the file names below a certain
 // directory embedded in the file by go-bindata.
 // For example if you run go-bindata on data/... and data contains the
 // following hierarchy:
-//     data/
-//       foo.txt
-//       img/
-//         a.png
-//         b.png
+//
+//	data/
+//	  foo.txt
+//	  img/
+//	    a.png
+//	    b.png
+//
 // then AssetDir("data") would return []string{"foo.txt", "img"},
 // AssetDir("data/img") would return []string{"a.png", "b.png"},
 // AssetDir("foo.txt") and AssetDir("notexist") would return an error, and
@@ -6129,6 +6637,28 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"1528395591_create_events_logging_table.up.sql":                         {_1528395591_create_events_logging_tableUpSql, map[string]*bintree{}},
 	"1528395592_add_deletion_triggers_to_campaigns_and_changesets.down.sql": {_1528395592_add_deletion_triggers_to_campaigns_and_changesetsDownSql, map[string]*bintree{}},
 	"1528395592_add_deletion_triggers_to_campaigns_and_changesets.up.sql":   {_1528395592_add_deletion_triggers_to_campaigns_and_changesetsUpSql, map[string]*bintree{}},
+	"1528395594_add_repo_missing_upstream.down.sql":                         {_1528395594_add_repo_missing_upstreamDownSql, map[string]*bintree{}},
+	"1528395594_add_repo_missing_upstream.up.sql":                           {_1528395594_add_repo_missing_upstreamUpSql, map[string]*bintree{}},
+	"1528395595_add_campaign_plans.down.sql":                                {_1528395595_add_campaign_plansDownSql, map[string]*bintree{}},
+	"1528395595_add_campaign_plans.up.sql":                                  {_1528395595_add_campaign_plansUpSql, map[string]*bintree{}},
+	"1528395596_add_changeset_events.down.sql":                              {_1528395596_add_changeset_eventsDownSql, map[string]*bintree{}},
+	"1528395596_add_changeset_events.up.sql":                                {_1528395596_add_changeset_eventsUpSql, map[string]*bintree{}},
+	"1528395597_add_changeset_actions.down.sql":                             {_1528395597_add_changeset_actionsDownSql, map[string]*bintree{}},
+	"1528395597_add_changeset_actions.up.sql":                               {_1528395597_add_changeset_actionsUpSql, map[string]*bintree{}},
+	"1528395598_add_repo_permissions.down.sql":                              {_1528395598_add_repo_permissionsDownSql, map[string]*bintree{}},
+	"1528395598_add_repo_permissions.up.sql":                                {_1528395598_add_repo_permissionsUpSql, map[string]*bintree{}},
+	"1528395599_add_repo_explicit_permissions.down.sql":                     {_1528395599_add_repo_explicit_permissionsDownSql, map[string]*bintree{}},
+	"1528395599_add_repo_explicit_permissions.up.sql":                       {_1528395599_add_repo_explicit_permissionsUpSql, map[string]*bintree{}},
+	"1528395600_add_access_tokens_restrictions.down.sql":                    {_1528395600_add_access_tokens_restrictionsDownSql, map[string]*bintree{}},
+	"1528395600_add_access_tokens_restrictions.up.sql":                      {_1528395600_add_access_tokens_restrictionsUpSql, map[string]*bintree{}},
+	"1528395601_add_audit_log.down.sql":                                     {_1528395601_add_audit_logDownSql, map[string]*bintree{}},
+	"1528395601_add_audit_log.up.sql":                                       {_1528395601_add_audit_logUpSql, map[string]*bintree{}},
+	"1528395593_add_repo_metadata_columns.down.sql":                         {_1528395593_add_repo_metadata_columnsDownSql, map[string]*bintree{}},
+	"1528395593_add_repo_metadata_columns.up.sql":                           {_1528395593_add_repo_metadata_columnsUpSql, map[string]*bintree{}},
+	"1528395602_add_user_suspension.down.sql":                               {_1528395602_add_user_suspensionDownSql, map[string]*bintree{}},
+	"1528395602_add_user_suspension.up.sql":                                 {_1528395602_add_user_suspensionUpSql, map[string]*bintree{}},
+	"1528395603_add_user_totp.down.sql":                                     {_1528395603_add_user_totpDownSql, map[string]*bintree{}},
+	"1528395603_add_user_totp.up.sql":                                       {_1528395603_add_user_totpUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
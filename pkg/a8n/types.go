@@ -1,10 +1,14 @@
 package a8n
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
 )
 
 // A Campaign of changesets over multiple Repos over time.
@@ -18,6 +22,7 @@ type Campaign struct {
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	ChangesetIDs    []int64
+	CampaignPlanID  int64
 }
 
 // Clone returns a clone of a Campaign.
@@ -27,6 +32,187 @@ func (c *Campaign) Clone() *Campaign {
 	return &cc
 }
 
+// A CampaignPlan is a preview of the changes a Campaign would make to the
+// Repos matched by the ScopeQuery of its Spec. The changes are computed in
+// the background, one CampaignJob per Repo.
+type CampaignPlan struct {
+	ID        int64
+	Spec      CampaignPlanSpec
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Clone returns a clone of a CampaignPlan.
+func (c *CampaignPlan) Clone() *CampaignPlan {
+	cc := *c
+	return &cc
+}
+
+// A CampaignPlanSpec specifies the rewrite a CampaignPlan runs over the
+// Repos matched by its ScopeQuery.
+type CampaignPlanSpec struct {
+	// ScopeQuery is a search query whose repository filters (e.g. repo:,
+	// repogroup:, fork:) select the Repos the rewrite runs over.
+	ScopeQuery string `json:"scopeQuery"`
+	// MatchTemplate and RewriteTemplate are the comby templates of the rewrite.
+	MatchTemplate   string `json:"matchTemplate"`
+	RewriteTemplate string `json:"rewriteTemplate"`
+	// FileExtension, if set, restricts the rewrite to files with the given extension.
+	FileExtension string `json:"fileExtension,omitempty"`
+	// DirectoryExclude, if set, excludes the given directory from the rewrite.
+	DirectoryExclude string `json:"directoryExclude,omitempty"`
+}
+
+// Validate returns an error if the CampaignPlanSpec is incomplete.
+func (s *CampaignPlanSpec) Validate() error {
+	switch {
+	case s.ScopeQuery == "":
+		return errors.New("campaign plan: scope query is empty")
+	case s.MatchTemplate == "":
+		return errors.New("campaign plan: match template is empty")
+	default:
+		return nil
+	}
+}
+
+// A CampaignJob is the result of running the rewrite of a CampaignPlan over
+// the head commit of the default branch of a single Repo.
+type CampaignJob struct {
+	ID             int64
+	CampaignPlanID int64
+	RepoID         int32
+	Rev            api.CommitID
+	BaseRef        string
+	Diff           string
+	Error          string
+	StartedAt      time.Time
+	FinishedAt     time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Clone returns a clone of a CampaignJob.
+func (c *CampaignJob) Clone() *CampaignJob {
+	cc := *c
+	return &cc
+}
+
+// A ChangesetJob turns the Diff of a CampaignJob into a Changeset of a
+// Campaign, by pushing it as a new branch to the code host and opening a
+// changeset (e.g. a pull request) for it.
+type ChangesetJob struct {
+	ID            int64
+	CampaignID    int64
+	CampaignJobID int64
+	ChangesetID   int64
+	Error         string
+	StartedAt     time.Time
+	FinishedAt    time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Clone returns a clone of a ChangesetJob.
+func (c *ChangesetJob) Clone() *ChangesetJob {
+	cc := *c
+	return &cc
+}
+
+// ChangesetActionType defines the actions that can be run on Changesets.
+type ChangesetActionType string
+
+// ChangesetActionType constants.
+const (
+	ChangesetActionComment       ChangesetActionType = "COMMENT"
+	ChangesetActionClose         ChangesetActionType = "CLOSE"
+	ChangesetActionReopen        ChangesetActionType = "REOPEN"
+	ChangesetActionRequestReview ChangesetActionType = "REQUEST_REVIEW"
+	ChangesetActionMerge         ChangesetActionType = "MERGE"
+)
+
+// Valid returns true if the given ChangesetActionType is valid.
+func (t ChangesetActionType) Valid() bool {
+	switch t {
+	case ChangesetActionComment,
+		ChangesetActionClose,
+		ChangesetActionReopen,
+		ChangesetActionRequestReview,
+		ChangesetActionMerge:
+		return true
+	default:
+		return false
+	}
+}
+
+// A ChangesetAction is an action run over a subset of the Changesets of a
+// Campaign in the background, one ChangesetActionJob per Changeset.
+type ChangesetAction struct {
+	ID         int64
+	CampaignID int64
+	AuthorID   int32
+	Action     ChangesetActionType
+	// Body is the body of the comment of a ChangesetActionComment.
+	Body      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Clone returns a clone of a ChangesetAction.
+func (a *ChangesetAction) Clone() *ChangesetAction {
+	aa := *a
+	return &aa
+}
+
+// A ChangesetActionJob records the result of running a ChangesetAction on
+// a single Changeset.
+type ChangesetActionJob struct {
+	ID                int64
+	ChangesetActionID int64
+	ChangesetID       int64
+	Error             string
+	StartedAt         time.Time
+	FinishedAt        time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// Clone returns a clone of a ChangesetActionJob.
+func (j *ChangesetActionJob) Clone() *ChangesetActionJob {
+	jj := *j
+	return &jj
+}
+
+// BackgroundProcessStatus is the aggregate status of the jobs of a
+// background process, such as the CampaignJobs of a CampaignPlan.
+type BackgroundProcessStatus struct {
+	Total     int32
+	Completed int32
+	Pending   int32
+	Errors    []string
+}
+
+// BackgroundProcessState defines the possible states of a background process.
+type BackgroundProcessState string
+
+// BackgroundProcessState constants.
+const (
+	BackgroundProcessStateProcessing BackgroundProcessState = "PROCESSING"
+	BackgroundProcessStateErrored    BackgroundProcessState = "ERRORED"
+	BackgroundProcessStateCompleted  BackgroundProcessState = "COMPLETED"
+)
+
+// State of the background process.
+func (s *BackgroundProcessStatus) State() BackgroundProcessState {
+	switch {
+	case s.Pending > 0:
+		return BackgroundProcessStateProcessing
+	case len(s.Errors) > 0:
+		return BackgroundProcessStateErrored
+	default:
+		return BackgroundProcessStateCompleted
+	}
+}
+
 // ChangesetState defines the possible states of a Changeset.
 type ChangesetState string
 
@@ -71,6 +257,41 @@ func (s ChangesetReviewState) Valid() bool {
 	}
 }
 
+// ChangesetCheckState defines the possible states of the CI checks of a
+// Changeset.
+type ChangesetCheckState string
+
+// ChangesetCheckState constants.
+const (
+	ChangesetCheckStatePending ChangesetCheckState = "PENDING"
+	ChangesetCheckStatePassed  ChangesetCheckState = "PASSED"
+	ChangesetCheckStateFailed  ChangesetCheckState = "FAILED"
+	ChangesetCheckStateUnknown ChangesetCheckState = "UNKNOWN"
+)
+
+// Valid returns true if the given ChangesetCheckState is valid.
+func (s ChangesetCheckState) Valid() bool {
+	switch s {
+	case ChangesetCheckStatePending,
+		ChangesetCheckStatePassed,
+		ChangesetCheckStateFailed,
+		ChangesetCheckStateUnknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// A ChangesetCheck is a single CI check (e.g. a GitHub check run, a GitHub
+// commit status or a GitLab pipeline job) run on the head commit of a
+// Changeset.
+type ChangesetCheck struct {
+	Name        string
+	State       ChangesetCheckState
+	Description string
+	URL         string
+}
+
 // A Changeset is a changeset on a code host belonging to a Repository and many
 // Campaigns.
 type Changeset struct {
@@ -96,6 +317,10 @@ func (t *Changeset) Title() (string, error) {
 	switch m := t.Metadata.(type) {
 	case *github.PullRequest:
 		return m.Title, nil
+	case *gitlab.MergeRequest:
+		return m.Title, nil
+	case *bitbucketserver.PullRequest:
+		return m.Title, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -106,6 +331,10 @@ func (t *Changeset) Body() (string, error) {
 	switch m := t.Metadata.(type) {
 	case *github.PullRequest:
 		return m.Body, nil
+	case *gitlab.MergeRequest:
+		return m.Description, nil
+	case *bitbucketserver.PullRequest:
+		return m.Description, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -116,6 +345,24 @@ func (t *Changeset) State() (s ChangesetState, err error) {
 	switch m := t.Metadata.(type) {
 	case *github.PullRequest:
 		s = ChangesetState(m.State)
+	case *gitlab.MergeRequest:
+		switch m.State {
+		case gitlab.MergeRequestStateOpened:
+			s = ChangesetStateOpen
+		case gitlab.MergeRequestStateClosed, gitlab.MergeRequestStateLocked:
+			s = ChangesetStateClosed
+		case gitlab.MergeRequestStateMerged:
+			s = ChangesetStateMerged
+		default:
+			s = ChangesetState(m.State)
+		}
+	case *bitbucketserver.PullRequest:
+		switch m.State {
+		case bitbucketserver.PullRequestStateDeclined:
+			s = ChangesetStateClosed
+		default:
+			s = ChangesetState(m.State)
+		}
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -132,6 +379,13 @@ func (t *Changeset) URL() (s string, err error) {
 	switch m := t.Metadata.(type) {
 	case *github.PullRequest:
 		return m.URL, nil
+	case *gitlab.MergeRequest:
+		return m.WebURL, nil
+	case *bitbucketserver.PullRequest:
+		if len(m.Links.Self) < 1 {
+			return "", errors.New("bitbucketserver pull request has no self links")
+		}
+		return m.Links.Self[0].Href, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -145,21 +399,442 @@ func (t *Changeset) ReviewState() (s ChangesetReviewState, err error) {
 		for _, r := range m.Reviews {
 			states[ChangesetReviewState(r.State)] = true
 		}
+		return selectReviewState(states), nil
+	case *gitlab.MergeRequest:
+		// GitLab has no notion of requesting changes, so a merge request is
+		// either approved or still pending.
+		if m.Approvals != nil && m.Approvals.Approved && len(m.Approvals.ApprovedBy) > 0 {
+			return ChangesetReviewStateApproved, nil
+		}
+		return ChangesetReviewStatePending, nil
+	case *bitbucketserver.PullRequest:
+		states := map[ChangesetReviewState]bool{}
+		for _, r := range m.Reviewers {
+			switch r.Status {
+			case bitbucketserver.ParticipantStatusNeedsWork:
+				states[ChangesetReviewStateChangesRequested] = true
+			case bitbucketserver.ParticipantStatusApproved:
+				states[ChangesetReviewStateApproved] = true
+			}
+		}
+		return selectReviewState(states), nil
+	default:
+		return "", errors.New("unknown changeset type")
+	}
+}
+
+// Checks returns the CI checks run on the head commit of the Changeset.
+func (t *Changeset) Checks() ([]*ChangesetCheck, error) {
+	switch m := t.Metadata.(type) {
+	case *github.PullRequest:
+		return githubChecks(m), nil
+	case *gitlab.MergeRequest:
+		return gitlabChecks(m), nil
+	case *bitbucketserver.PullRequest:
+		// Build statuses of Bitbucket Server pull requests are not synced.
+		return nil, nil
+	default:
+		return nil, errors.New("unknown changeset type")
+	}
+}
+
+// CheckState returns the overall state of the CI checks of the Changeset.
+func (t *Changeset) CheckState() (ChangesetCheckState, error) {
+	checks, err := t.Checks()
+	if err != nil {
+		return "", err
+	}
+	return selectCheckState(checks), nil
+}
+
+// selectCheckState returns the overall state of the given checks: any failed
+// check fails the whole, otherwise any pending check keeps it pending. Without
+// any checks the state is unknown.
+func selectCheckState(checks []*ChangesetCheck) ChangesetCheckState {
+	if len(checks) == 0 {
+		return ChangesetCheckStateUnknown
+	}
+
+	states := map[ChangesetCheckState]bool{}
+	for _, c := range checks {
+		states[c.State] = true
+	}
+
+	for _, state := range [...]ChangesetCheckState{
+		ChangesetCheckStateFailed,
+		ChangesetCheckStatePending,
+		ChangesetCheckStateUnknown,
+	} {
+		if states[state] {
+			return state
+		}
+	}
+
+	return ChangesetCheckStatePassed
+}
 
-		// If any review requested changes, that state takes precedence over all
-		// other review states, followed by explicit approval. Everything else is
-		// considered pending.
-		for _, state := range [...]ChangesetReviewState{
-			ChangesetReviewStateChangesRequested,
-			ChangesetReviewStateApproved,
-		} {
-			if states[state] {
-				return state, nil
+func githubChecks(pr *github.PullRequest) (checks []*ChangesetCheck) {
+	if pr.HeadCommit == nil {
+		return nil
+	}
+
+	for _, c := range pr.HeadCommit.Status.Contexts {
+		checks = append(checks, &ChangesetCheck{
+			Name:        c.Context,
+			State:       githubStatusCheckState(c.State),
+			Description: c.Description,
+			URL:         c.TargetURL,
+		})
+	}
+
+	for _, suite := range pr.HeadCommit.CheckSuites {
+		// GitHub creates a check suite for every installed app that
+		// subscribes to check events, even if the app never creates a check
+		// run, so only check runs are considered.
+		for _, r := range suite.CheckRuns {
+			checks = append(checks, &ChangesetCheck{
+				Name:        r.Name,
+				State:       githubCheckRunState(r.Status, r.Conclusion),
+				Description: suite.App.Name,
+				URL:         r.DetailsURL,
+			})
+		}
+	}
+
+	return checks
+}
+
+func githubStatusCheckState(state string) ChangesetCheckState {
+	switch state {
+	case "SUCCESS":
+		return ChangesetCheckStatePassed
+	case "ERROR", "FAILURE":
+		return ChangesetCheckStateFailed
+	case "EXPECTED", "PENDING":
+		return ChangesetCheckStatePending
+	default:
+		return ChangesetCheckStateUnknown
+	}
+}
+
+func githubCheckRunState(status, conclusion string) ChangesetCheckState {
+	if status != github.CheckStatusCompleted {
+		return ChangesetCheckStatePending
+	}
+
+	switch conclusion {
+	case github.CheckConclusionSuccess,
+		github.CheckConclusionNeutral,
+		github.CheckConclusionSkipped:
+		return ChangesetCheckStatePassed
+	case github.CheckConclusionFailure,
+		github.CheckConclusionCancelled,
+		github.CheckConclusionTimedOut,
+		github.CheckConclusionActionRequired:
+		return ChangesetCheckStateFailed
+	default:
+		return ChangesetCheckStateUnknown
+	}
+}
+
+func gitlabChecks(mr *gitlab.MergeRequest) (checks []*ChangesetCheck) {
+	p := mr.HeadPipeline
+	if p == nil {
+		return nil
+	}
+
+	// Without access to its jobs, the pipeline itself is the only check.
+	if len(p.Jobs) == 0 {
+		return []*ChangesetCheck{{
+			Name:  fmt.Sprintf("pipeline #%d", p.ID),
+			State: gitlabCheckState(p.Status, false),
+			URL:   p.WebURL,
+		}}
+	}
+
+	for _, j := range p.Jobs {
+		checks = append(checks, &ChangesetCheck{
+			Name:        j.Name,
+			State:       gitlabCheckState(j.Status, j.AllowFailure),
+			Description: j.Stage,
+			URL:         j.WebURL,
+		})
+	}
+
+	return checks
+}
+
+func gitlabCheckState(status gitlab.PipelineStatus, allowFailure bool) ChangesetCheckState {
+	switch status {
+	case gitlab.PipelineStatusSuccess,
+		gitlab.PipelineStatusSkipped,
+		gitlab.PipelineStatusManual:
+		return ChangesetCheckStatePassed
+	case gitlab.PipelineStatusFailed:
+		// Jobs that are allowed to fail don't fail their pipeline.
+		if allowFailure {
+			return ChangesetCheckStatePassed
+		}
+		return ChangesetCheckStateFailed
+	case gitlab.PipelineStatusCanceled:
+		return ChangesetCheckStateFailed
+	case gitlab.PipelineStatusCreated,
+		gitlab.PipelineStatusWaitingForResource,
+		gitlab.PipelineStatusPreparing,
+		gitlab.PipelineStatusPending,
+		gitlab.PipelineStatusRunning,
+		gitlab.PipelineStatusScheduled:
+		return ChangesetCheckStatePending
+	default:
+		return ChangesetCheckStateUnknown
+	}
+}
+
+// selectReviewState returns the overall review state of a Changeset given
+// the set of review states of its individual reviews.
+func selectReviewState(states map[ChangesetReviewState]bool) ChangesetReviewState {
+	// If any review requested changes, that state takes precedence over all
+	// other review states, followed by explicit approval. Everything else is
+	// considered pending.
+	for _, state := range [...]ChangesetReviewState{
+		ChangesetReviewStateChangesRequested,
+		ChangesetReviewStateApproved,
+	} {
+		if states[state] {
+			return state
+		}
+	}
+
+	return ChangesetReviewStatePending
+}
+
+// ExternalCreatedAt is the time the Changeset was created on the code host.
+func (t *Changeset) ExternalCreatedAt() (time.Time, error) {
+	switch m := t.Metadata.(type) {
+	case *github.PullRequest:
+		return m.CreatedAt, nil
+	case *gitlab.MergeRequest:
+		return m.CreatedAt, nil
+	case *bitbucketserver.PullRequest:
+		return time.Unix(0, m.CreatedDate*int64(time.Millisecond)), nil
+	default:
+		return time.Time{}, errors.New("unknown changeset type")
+	}
+}
+
+// Events returns the ChangesetEvents that can be derived from the Metadata
+// of the Changeset. Only GitHub pull requests carry their history in their
+// metadata. The events of other Changesets are recorded by comparing
+// consecutive snapshots of their metadata with StateTransitions.
+func (t *Changeset) Events() (events []*ChangesetEvent) {
+	pr, ok := t.Metadata.(*github.PullRequest)
+	if !ok {
+		return nil
+	}
+
+	for _, ti := range pr.TimelineItems {
+		var kind ChangesetEventKind
+		switch ti.Type {
+		case github.TimelineItemTypeClosed:
+			kind = ChangesetEventKindClosed
+		case github.TimelineItemTypeMerged:
+			kind = ChangesetEventKindMerged
+		case github.TimelineItemTypeReopened:
+			kind = ChangesetEventKindReopened
+		default:
+			continue
+		}
+
+		events = append(events, &ChangesetEvent{
+			ChangesetID: t.ID,
+			Kind:        kind,
+			Key:         ti.ID,
+			Actor:       ti.Actor.Login,
+			Timestamp:   ti.CreatedAt,
+		})
+	}
+
+	for _, r := range pr.Reviews {
+		var kind ChangesetEventKind
+		switch ChangesetReviewState(r.State) {
+		case ChangesetReviewStateApproved:
+			kind = ChangesetEventKindApproved
+		case ChangesetReviewStateChangesRequested:
+			kind = ChangesetEventKindChangesRequested
+		case "DISMISSED":
+			kind = ChangesetEventKindReviewDismissed
+		default:
+			continue
+		}
+
+		events = append(events, &ChangesetEvent{
+			ChangesetID: t.ID,
+			Kind:        kind,
+			Key:         r.ID,
+			Actor:       r.Author.Login,
+			Timestamp:   r.SubmittedAt,
+		})
+	}
+
+	return events
+}
+
+// StateTransitions returns the ChangesetEvents that transition the given
+// previous snapshot of a Changeset into its current state, as observed at
+// the given time. If there is no previous snapshot, it returns the
+// InitialEvents of the Changeset instead.
+func (t *Changeset) StateTransitions(prev *Changeset, observed time.Time) (events []*ChangesetEvent) {
+	if prev == nil || prev.Metadata == nil {
+		return t.InitialEvents(observed)
+	}
+
+	key := observed.UTC().Format(time.RFC3339Nano)
+
+	if from, err := prev.State(); err == nil {
+		if to, err := t.State(); err == nil && from != to {
+			kind := ChangesetEventKindReopened
+			switch to {
+			case ChangesetStateClosed:
+				kind = ChangesetEventKindClosed
+			case ChangesetStateMerged:
+				kind = ChangesetEventKindMerged
+			}
+
+			events = append(events, &ChangesetEvent{
+				ChangesetID: t.ID,
+				Kind:        kind,
+				Key:         key,
+				Timestamp:   observed,
+			})
+		}
+	}
+
+	if from, err := prev.ReviewState(); err == nil {
+		if to, err := t.ReviewState(); err == nil && from != to {
+			kind := ChangesetEventKindReviewDismissed
+			switch to {
+			case ChangesetReviewStateApproved:
+				kind = ChangesetEventKindApproved
+			case ChangesetReviewStateChangesRequested:
+				kind = ChangesetEventKindChangesRequested
 			}
+
+			events = append(events, &ChangesetEvent{
+				ChangesetID: t.ID,
+				Kind:        kind,
+				Key:         key,
+				Timestamp:   observed,
+			})
+		}
+	}
+
+	return events
+}
+
+// ChangesetEventKeyInitial is the Key of the ChangesetEvents returned by
+// InitialEvents.
+const ChangesetEventKeyInitial = "initial"
+
+// InitialEvents returns the ChangesetEvents that lead to the current state
+// and review state of a Changeset whose earlier history is unknown, e.g.
+// because it was already merged or closed when it was first synced. The
+// merged and closed events are timestamped with the time the code host
+// reports the Changeset was merged or closed, if it does, and all other
+// events with the given observed time.
+func (t *Changeset) InitialEvents(observed time.Time) (events []*ChangesetEvent) {
+	if s, err := t.State(); err == nil && s != ChangesetStateOpen {
+		kind := ChangesetEventKindMerged
+		if s == ChangesetStateClosed {
+			kind = ChangesetEventKindClosed
 		}
 
-		return ChangesetReviewStatePending, nil
+		timestamp := t.stateChangedAt()
+		if timestamp.IsZero() {
+			timestamp = observed
+		}
+
+		events = append(events, &ChangesetEvent{
+			ChangesetID: t.ID,
+			Kind:        kind,
+			Key:         ChangesetEventKeyInitial,
+			Timestamp:   timestamp,
+		})
+	}
+
+	if s, err := t.ReviewState(); err == nil && s != ChangesetReviewStatePending {
+		kind := ChangesetEventKindApproved
+		if s == ChangesetReviewStateChangesRequested {
+			kind = ChangesetEventKindChangesRequested
+		}
+
+		events = append(events, &ChangesetEvent{
+			ChangesetID: t.ID,
+			Kind:        kind,
+			Key:         ChangesetEventKeyInitial,
+			Timestamp:   observed,
+		})
+	}
+
+	return events
+}
+
+// stateChangedAt returns the time the Changeset was merged or closed on the
+// code host, or the last time it was updated if the code host doesn't report
+// it. It returns the zero time if neither is known.
+func (t *Changeset) stateChangedAt() time.Time {
+	switch m := t.Metadata.(type) {
+	case *github.PullRequest:
+		return m.UpdatedAt
+	case *gitlab.MergeRequest:
+		if m.MergedAt != nil {
+			return *m.MergedAt
+		}
+		if m.ClosedAt != nil {
+			return *m.ClosedAt
+		}
+		return m.UpdatedAt
+	case *bitbucketserver.PullRequest:
+		if m.UpdatedDate == 0 {
+			return time.Time{}
+		}
+		return time.Unix(0, m.UpdatedDate*int64(time.Millisecond))
 	default:
-		return "", errors.New("unknown changeset type")
+		return time.Time{}
 	}
 }
+
+// ChangesetEventKind defines the kinds of ChangesetEvents.
+type ChangesetEventKind string
+
+// ChangesetEventKind constants.
+const (
+	ChangesetEventKindClosed           ChangesetEventKind = "CLOSED"
+	ChangesetEventKindMerged           ChangesetEventKind = "MERGED"
+	ChangesetEventKindReopened         ChangesetEventKind = "REOPENED"
+	ChangesetEventKindApproved         ChangesetEventKind = "APPROVED"
+	ChangesetEventKindChangesRequested ChangesetEventKind = "CHANGES_REQUESTED"
+	ChangesetEventKindReviewDismissed  ChangesetEventKind = "REVIEW_DISMISSED"
+)
+
+// A ChangesetEvent is an event in the history of a Changeset that changed
+// its state or its review state. Events are unique per Changeset, Kind and
+// Key.
+type ChangesetEvent struct {
+	ID          int64
+	ChangesetID int64
+	Kind        ChangesetEventKind
+	// Key identifies the event on the code host, or the time it was observed
+	// if the code host doesn't provide the history of a Changeset.
+	Key string
+	// Actor is the login of the user who caused the event, if known.
+	Actor     string
+	Timestamp time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Clone returns a clone of a ChangesetEvent.
+func (e *ChangesetEvent) Clone() *ChangesetEvent {
+	ee := *e
+	return &ee
+}
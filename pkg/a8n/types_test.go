@@ -1,10 +1,13 @@
 package a8n
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
 )
 
 func TestChangesetMetadata(t *testing.T) {
@@ -75,3 +78,350 @@ func TestChangesetMetadata(t *testing.T) {
 		t.Errorf("changeset url wrong. want=%q, have=%q", want, have)
 	}
 }
+
+func TestChangesetStates(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		meta   interface{}
+		state  ChangesetState
+		review ChangesetReviewState
+		url    string
+	}{
+		{
+			name: "github changes requested",
+			meta: &github.PullRequest{
+				State: "OPEN",
+				Reviews: []github.Review{
+					{State: "APPROVED"},
+					{State: "CHANGES_REQUESTED"},
+				},
+			},
+			state:  ChangesetStateOpen,
+			review: ChangesetReviewStateChangesRequested,
+		},
+		{
+			name: "gitlab opened without approvals support",
+			meta: &gitlab.MergeRequest{
+				State:  gitlab.MergeRequestStateOpened,
+				WebURL: "https://gitlab.com/sourcegraph/sourcegraph/merge_requests/1",
+			},
+			state:  ChangesetStateOpen,
+			review: ChangesetReviewStatePending,
+			url:    "https://gitlab.com/sourcegraph/sourcegraph/merge_requests/1",
+		},
+		{
+			name: "gitlab locked approved",
+			meta: &gitlab.MergeRequest{
+				State: gitlab.MergeRequestStateLocked,
+				Approvals: &gitlab.MergeRequestApprovals{
+					Approved: true,
+					ApprovedBy: []*gitlab.Approver{
+						{User: &gitlab.User{Username: "tsenart"}},
+					},
+				},
+			},
+			state:  ChangesetStateClosed,
+			review: ChangesetReviewStateApproved,
+		},
+		{
+			name:   "gitlab merged",
+			meta:   &gitlab.MergeRequest{State: gitlab.MergeRequestStateMerged},
+			state:  ChangesetStateMerged,
+			review: ChangesetReviewStatePending,
+		},
+		{
+			name: "bitbucketserver declined needs work",
+			meta: &bitbucketserver.PullRequest{
+				State: bitbucketserver.PullRequestStateDeclined,
+				Reviewers: []bitbucketserver.PullRequestParticipant{
+					{Status: bitbucketserver.ParticipantStatusApproved},
+					{Status: bitbucketserver.ParticipantStatusNeedsWork},
+				},
+			},
+			state:  ChangesetStateClosed,
+			review: ChangesetReviewStateChangesRequested,
+		},
+		{
+			name: "bitbucketserver merged approved",
+			meta: &bitbucketserver.PullRequest{
+				State: bitbucketserver.PullRequestStateMerged,
+				Reviewers: []bitbucketserver.PullRequestParticipant{
+					{Status: bitbucketserver.ParticipantStatusApproved},
+					{Status: bitbucketserver.ParticipantStatusUnapproved},
+				},
+			},
+			state:  ChangesetStateMerged,
+			review: ChangesetReviewStateApproved,
+		},
+		{
+			name:   "bitbucketserver open unapproved",
+			meta:   &bitbucketserver.PullRequest{State: bitbucketserver.PullRequestStateOpen},
+			state:  ChangesetStateOpen,
+			review: ChangesetReviewStatePending,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Changeset{Metadata: tc.meta}
+
+			state, err := c.State()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if have, want := state, tc.state; have != want {
+				t.Errorf("changeset state wrong. want=%q, have=%q", want, have)
+			}
+
+			review, err := c.ReviewState()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if have, want := review, tc.review; have != want {
+				t.Errorf("changeset review state wrong. want=%q, have=%q", want, have)
+			}
+
+			if tc.url == "" {
+				return
+			}
+
+			url, err := c.URL()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if have, want := url, tc.url; have != want {
+				t.Errorf("changeset url wrong. want=%q, have=%q", want, have)
+			}
+		})
+	}
+}
+
+func TestChangesetChecks(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		meta   interface{}
+		state  ChangesetCheckState
+		checks []*ChangesetCheck
+	}{
+		{
+			name:  "github without head commit",
+			meta:  &github.PullRequest{},
+			state: ChangesetCheckStateUnknown,
+		},
+		{
+			name: "github status failed and check run pending",
+			meta: &github.PullRequest{
+				HeadCommit: &github.Commit{
+					Status: github.Status{
+						State: "FAILURE",
+						Contexts: []github.StatusContext{
+							{Context: "ci/buildkite", State: "FAILURE", TargetURL: "https://buildkite.com/1"},
+						},
+					},
+					CheckSuites: []github.CheckSuite{
+						{
+							Status: github.CheckStatusInProgress,
+							App:    struct{ Name string }{Name: "GitHub Actions"},
+							CheckRuns: []github.CheckRun{
+								{Name: "lint", Status: github.CheckStatusInProgress},
+							},
+						},
+						// Suites without check runs are ignored.
+						{Status: github.CheckStatusQueued},
+					},
+				},
+			},
+			state: ChangesetCheckStateFailed,
+			checks: []*ChangesetCheck{
+				{Name: "ci/buildkite", State: ChangesetCheckStateFailed, URL: "https://buildkite.com/1"},
+				{Name: "lint", State: ChangesetCheckStatePending, Description: "GitHub Actions"},
+			},
+		},
+		{
+			name: "github check runs passed",
+			meta: &github.PullRequest{
+				HeadCommit: &github.Commit{
+					CheckSuites: []github.CheckSuite{{
+						Status:     github.CheckStatusCompleted,
+						Conclusion: github.CheckConclusionSuccess,
+						CheckRuns: []github.CheckRun{
+							{Name: "test", Status: github.CheckStatusCompleted, Conclusion: github.CheckConclusionSuccess},
+							{Name: "deploy", Status: github.CheckStatusCompleted, Conclusion: github.CheckConclusionSkipped},
+						},
+					}},
+				},
+			},
+			state: ChangesetCheckStatePassed,
+			checks: []*ChangesetCheck{
+				{Name: "test", State: ChangesetCheckStatePassed},
+				{Name: "deploy", State: ChangesetCheckStatePassed},
+			},
+		},
+		{
+			name: "gitlab pipeline without jobs",
+			meta: &gitlab.MergeRequest{
+				HeadPipeline: &gitlab.Pipeline{ID: 7, Status: gitlab.PipelineStatusRunning, WebURL: "https://gitlab.com/p/7"},
+			},
+			state: ChangesetCheckStatePending,
+			checks: []*ChangesetCheck{
+				{Name: "pipeline #7", State: ChangesetCheckStatePending, URL: "https://gitlab.com/p/7"},
+			},
+		},
+		{
+			name: "gitlab jobs allowed to fail",
+			meta: &gitlab.MergeRequest{
+				HeadPipeline: &gitlab.Pipeline{
+					ID:     7,
+					Status: gitlab.PipelineStatusSuccess,
+					Jobs: []*gitlab.Job{
+						{Name: "test", Stage: "test", Status: gitlab.PipelineStatusSuccess},
+						{Name: "lint", Stage: "test", Status: gitlab.PipelineStatusFailed, AllowFailure: true},
+					},
+				},
+			},
+			state: ChangesetCheckStatePassed,
+			checks: []*ChangesetCheck{
+				{Name: "test", State: ChangesetCheckStatePassed, Description: "test"},
+				{Name: "lint", State: ChangesetCheckStatePassed, Description: "test"},
+			},
+		},
+		{
+			name:  "bitbucketserver",
+			meta:  &bitbucketserver.PullRequest{},
+			state: ChangesetCheckStateUnknown,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Changeset{Metadata: tc.meta}
+
+			checks, err := c.Checks()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if have, want := checks, tc.checks; !reflect.DeepEqual(have, want) {
+				t.Errorf("changeset checks wrong. want=%+v, have=%+v", want, have)
+			}
+
+			state, err := c.CheckState()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if have, want := state, tc.state; have != want {
+				t.Errorf("changeset check state wrong. want=%q, have=%q", want, have)
+			}
+		})
+	}
+}
+
+func TestBackgroundProcessStatusState(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status BackgroundProcessStatus
+		want   BackgroundProcessState
+	}{
+		{
+			name:   "pending",
+			status: BackgroundProcessStatus{Total: 2, Completed: 1, Pending: 1, Errors: []string{"boom"}},
+			want:   BackgroundProcessStateProcessing,
+		},
+		{
+			name:   "errored",
+			status: BackgroundProcessStatus{Total: 2, Completed: 2, Errors: []string{"boom"}},
+			want:   BackgroundProcessStateErrored,
+		},
+		{
+			name:   "completed",
+			status: BackgroundProcessStatus{Total: 2, Completed: 2},
+			want:   BackgroundProcessStateCompleted,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if have := tc.status.State(); have != tc.want {
+				t.Errorf("have state %q, want %q", have, tc.want)
+			}
+		})
+	}
+}
+
+func TestChangesetStateTransitions(t *testing.T) {
+	observed := time.Date(2019, 10, 7, 12, 0, 0, 0, time.UTC)
+	key := observed.Format(time.RFC3339Nano)
+	closedAt := observed.Add(-time.Hour)
+
+	mr := func(state gitlab.MergeRequestState) *Changeset {
+		return &Changeset{ID: 1, Metadata: &gitlab.MergeRequest{State: state}}
+	}
+
+	for _, tc := range []struct {
+		name string
+		prev *Changeset
+		cur  *Changeset
+		want []*ChangesetEvent
+	}{
+		{
+			name: "unknown previous state",
+			prev: &Changeset{ID: 1},
+			cur:  mr(gitlab.MergeRequestStateMerged),
+			want: []*ChangesetEvent{{
+				ChangesetID: 1,
+				Kind:        ChangesetEventKindMerged,
+				Key:         ChangesetEventKeyInitial,
+				Timestamp:   observed,
+			}},
+		},
+		{
+			name: "unknown previous state with closed at",
+			cur: &Changeset{ID: 1, Metadata: &gitlab.MergeRequest{
+				State:    gitlab.MergeRequestStateClosed,
+				ClosedAt: &closedAt,
+			}},
+			want: []*ChangesetEvent{{
+				ChangesetID: 1,
+				Kind:        ChangesetEventKindClosed,
+				Key:         ChangesetEventKeyInitial,
+				Timestamp:   closedAt,
+			}},
+		},
+		{
+			name: "unknown previous state of open changeset",
+			cur:  mr(gitlab.MergeRequestStateOpened),
+		},
+		{
+			name: "unchanged",
+			prev: mr(gitlab.MergeRequestStateOpened),
+			cur:  mr(gitlab.MergeRequestStateOpened),
+		},
+		{
+			name: "merged",
+			prev: mr(gitlab.MergeRequestStateOpened),
+			cur:  mr(gitlab.MergeRequestStateMerged),
+			want: []*ChangesetEvent{{
+				ChangesetID: 1,
+				Kind:        ChangesetEventKindMerged,
+				Key:         key,
+				Timestamp:   observed,
+			}},
+		},
+		{
+			name: "reopened",
+			prev: mr(gitlab.MergeRequestStateClosed),
+			cur:  mr(gitlab.MergeRequestStateOpened),
+			want: []*ChangesetEvent{{
+				ChangesetID: 1,
+				Kind:        ChangesetEventKindReopened,
+				Key:         key,
+				Timestamp:   observed,
+			}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			have := tc.cur.StateTransitions(tc.prev, observed)
+			if !reflect.DeepEqual(have, tc.want) {
+				t.Errorf("have events %+v, want %+v", have, tc.want)
+			}
+		})
+	}
+}
@@ -20,6 +20,10 @@ func AuthProviderType(p schema.AuthProviders) string {
 		return p.Github.Type
 	case p.Gitlab != nil:
 		return p.Gitlab.Type
+	case p.BitbucketCloud != nil:
+		return p.BitbucketCloud.Type
+	case p.Ldap != nil:
+		return p.Ldap.Type
 	default:
 		return ""
 	}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/conf/confdefaults"
@@ -326,3 +327,38 @@ func ExperimentalFeatures() schema.ExperimentalFeatures {
 	}
 	return *val
 }
+
+// PermissionsSyncEnabled reports whether repository permissions are synced in
+// the background and checked against the stored permissions.
+func PermissionsSyncEnabled() bool {
+	s := Get().PermissionsBackgroundSync
+	return s != nil && s.Enabled
+}
+
+// PermissionsSyncInterval returns how often the permissions of each user are
+// synced in the background. It defaults to 1 hour.
+func PermissionsSyncInterval() time.Duration {
+	if s := Get().PermissionsBackgroundSync; s != nil && s.Interval != "" {
+		if d, err := time.ParseDuration(s.Interval); err == nil && d > 0 {
+			return d
+		}
+	}
+	return time.Hour
+}
+
+// PermissionsSyncsPerMinute returns the maximum number of users whose
+// permissions are synced per minute from each authorization provider. It
+// defaults to 60.
+func PermissionsSyncsPerMinute() int {
+	if s := Get().PermissionsBackgroundSync; s != nil && s.SyncsPerMinute > 0 {
+		return s.SyncsPerMinute
+	}
+	return 60
+}
+
+// ExplicitPermissionsEnabled reports whether the repository permissions
+// granted explicitly by site admins are enforced.
+func ExplicitPermissionsEnabled() bool {
+	e := Get().PermissionsExplicit
+	return e != nil && e.Enabled
+}
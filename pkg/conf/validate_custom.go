@@ -3,6 +3,7 @@ package conf
 import (
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/pkg/conf/conftypes"
 )
@@ -51,6 +52,14 @@ func validateCustom(cfg Unified) (problems []string) {
 		}
 	}
 
+	if s := cfg.PermissionsBackgroundSync; s != nil && s.Interval != "" {
+		if d, err := time.ParseDuration(s.Interval); err != nil {
+			invalid("permissions.backgroundSync.interval: " + err.Error())
+		} else if d <= 0 {
+			invalid(`permissions.backgroundSync.interval must be positive`)
+		}
+	}
+
 	for _, f := range contributedValidators {
 		problems = append(problems, f(cfg)...)
 	}
@@ -41,6 +41,15 @@ func TestValidateCustom(t *testing.T) {
 			rawSite:     "{}",
 			wantErr:     "tagged union type must have a",
 		},
+		"invalid permissions.backgroundSync.interval": {
+			rawCritical: "{}",
+			rawSite:     `{"permissions.backgroundSync":{"interval":"hourly"}}`,
+			wantProblem: "permissions.backgroundSync.interval",
+		},
+		"valid permissions.backgroundSync.interval": {
+			rawCritical: "{}",
+			rawSite:     `{"permissions.backgroundSync":{"enabled":true,"interval":"30m"}}`,
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
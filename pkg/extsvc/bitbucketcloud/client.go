@@ -48,6 +48,10 @@ type Client struct {
 	// The username and app password credentials for accessing the server.
 	Username, AppPassword string
 
+	// Token is an OAuth access token. When set, it is used to authenticate
+	// requests instead of the username and app password credentials.
+	Token string
+
 	// RateLimit is the self-imposed rate limiter (since Bitbucket does not have a concept
 	// of rate limiting in HTTP response headers).
 	RateLimit *rate.Limiter
@@ -95,6 +99,56 @@ func (c *Client) Repos(ctx context.Context, pageToken *PageToken, accountName st
 	return repos, next, err
 }
 
+// UserRepos returns a list of repositories that the authenticated user is a member of.
+// It behaves like Repos in regards to pagination.
+func (c *Client) UserRepos(ctx context.Context, pageToken *PageToken) ([]*Repo, *PageToken, error) {
+	var repos []*Repo
+	var next *PageToken
+	var err error
+	if pageToken.HasMore() {
+		next, err = c.reqPage(ctx, pageToken.Next, &repos)
+	} else {
+		next, err = c.page(ctx, "/2.0/repositories", url.Values{"role": []string{"member"}}, pageToken, &repos)
+	}
+	return repos, next, err
+}
+
+// CurrentUser returns the user authenticated by the Client's credentials.
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	req, err := http.NewRequest("GET", "/2.0/user", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	if err := c.do(ctx, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CurrentUserEmails returns the email addresses of the user authenticated by the Client's
+// credentials. It behaves like Repos in regards to pagination.
+func (c *Client) CurrentUserEmails(ctx context.Context, pageToken *PageToken) ([]*UserEmail, *PageToken, error) {
+	var emails []*UserEmail
+	var next *PageToken
+	var err error
+	if pageToken.HasMore() {
+		next, err = c.reqPage(ctx, pageToken.Next, &emails)
+	} else {
+		next, err = c.page(ctx, "/2.0/user/emails", nil, pageToken, &emails)
+	}
+	return emails, next, err
+}
+
+// WithToken returns a copy of the Client authenticated as the user owning the given
+// OAuth access token.
+func (c *Client) WithToken(token string) *Client {
+	cc := *c
+	cc.Token = token
+	return &cc
+}
+
 func (c *Client) page(ctx context.Context, path string, qry url.Values, token *PageToken, results interface{}) (*PageToken, error) {
 	if qry == nil {
 		qry = make(url.Values)
@@ -186,6 +240,10 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 }
 
 func (c *Client) authenticate(req *http.Request) error {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		return nil
+	}
 	req.SetBasicAuth(c.Username, c.AppPassword)
 	return nil
 }
@@ -224,6 +282,7 @@ type Repo struct {
 	Description string `json:"description"`
 	Parent      *Repo  `json:"parent"`
 	IsPrivate   bool   `json:"is_private"`
+	Language    string `json:"language"`
 	Links       Links  `json:"links"`
 }
 
@@ -251,6 +310,12 @@ func (cl CloneLinks) HTTPS() (string, error) {
 	return "", errors.New("HTTPS clone link not found")
 }
 
+// IsNotFound reports whether err is a Bitbucket Cloud API not found error.
+func IsNotFound(err error) bool {
+	e, ok := errors.Cause(err).(*httpError)
+	return ok && e.NotFound()
+}
+
 type httpError struct {
 	StatusCode int
 	URL        *url.URL
@@ -0,0 +1,67 @@
+package bitbucketcloud
+
+import (
+	"net/url"
+
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"golang.org/x/oauth2"
+)
+
+// User is a Bitbucket Cloud user account.
+type User struct {
+	Username    string    `json:"username"`
+	UUID        string    `json:"uuid"`
+	DisplayName string    `json:"display_name"`
+	Links       UserLinks `json:"links"`
+}
+
+// UserLinks are the links of a Bitbucket Cloud user account.
+type UserLinks struct {
+	Avatar Link `json:"avatar"`
+}
+
+// UserEmail is an email address of a Bitbucket Cloud user account.
+type UserEmail struct {
+	Email       string `json:"email"`
+	IsPrimary   bool   `json:"is_primary"`
+	IsConfirmed bool   `json:"is_confirmed"`
+}
+
+// OAuth2Endpoint returns the endpoint of the OAuth 2 authorization server of the Bitbucket Cloud
+// at the given base URL, such as https://bitbucket.org.
+func OAuth2Endpoint(baseURL *url.URL) oauth2.Endpoint {
+	return oauth2.Endpoint{
+		AuthURL:  baseURL.ResolveReference(&url.URL{Path: "/site/oauth2/authorize"}).String(),
+		TokenURL: baseURL.ResolveReference(&url.URL{Path: "/site/oauth2/access_token"}).String(),
+	}
+}
+
+// GetExternalAccountData returns the Bitbucket Cloud user and OAuth token stored in the
+// given external account data.
+func GetExternalAccountData(data *extsvc.ExternalAccountData) (usr *User, tok *oauth2.Token, err error) {
+	var (
+		u User
+		t oauth2.Token
+	)
+
+	if data.AccountData != nil {
+		if err := data.GetAccountData(&u); err != nil {
+			return nil, nil, err
+		}
+		usr = &u
+	}
+	if data.AuthData != nil {
+		if err := data.GetAuthData(&t); err != nil {
+			return nil, nil, err
+		}
+		tok = &t
+	}
+	return usr, tok, nil
+}
+
+// SetExternalAccountData stores the Bitbucket Cloud user and OAuth token in the given
+// external account data.
+func SetExternalAccountData(data *extsvc.ExternalAccountData, user *User, token *oauth2.Token) {
+	data.SetAccountData(user)
+	data.SetAuthData(token)
+}
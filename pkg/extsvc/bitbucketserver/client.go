@@ -366,6 +366,208 @@ func (c *Client) CreateProject(ctx context.Context, p *Project) error {
 	return c.send(ctx, "POST", "rest/api/1.0/projects", nil, p, p)
 }
 
+// LoadPullRequest loads the given PullRequest returning an error in case of failure.
+// The PullRequest's ID and the Project key and slug of its target repository
+// (ToRef.Repository) must be set.
+func (c *Client) LoadPullRequest(ctx context.Context, pr *PullRequest) error {
+	if pr.ToRef.Repository.Slug == "" {
+		return errors.New("repository slug empty")
+	}
+
+	if pr.ToRef.Repository.Project == nil || pr.ToRef.Repository.Project.Key == "" {
+		return errors.New("project key empty")
+	}
+
+	path := fmt.Sprintf(
+		"rest/api/1.0/projects/%s/repos/%s/pull-requests/%d",
+		pr.ToRef.Repository.Project.Key,
+		pr.ToRef.Repository.Slug,
+		pr.ID,
+	)
+
+	return c.send(ctx, "GET", path, nil, nil, pr)
+}
+
+// CreatePullRequest creates the given PullRequest returning an error in case of failure.
+// The PullRequest's Title, FromRef and ToRef must be set, where the refs'
+// IDs are fully qualified (e.g. "refs/heads/master") and their Repository
+// has its Slug and Project key set. On success, the PullRequest is updated
+// with the response of the API.
+func (c *Client) CreatePullRequest(ctx context.Context, pr *PullRequest) error {
+	for _, ref := range []*Ref{&pr.FromRef, &pr.ToRef} {
+		if ref.ID == "" {
+			return errors.New("ref id empty")
+		}
+
+		if ref.Repository.Slug == "" {
+			return errors.New("repository slug empty")
+		}
+
+		if ref.Repository.Project == nil || ref.Repository.Project.Key == "" {
+			return errors.New("project key empty")
+		}
+	}
+
+	type repository struct {
+		Slug    string `json:"slug"`
+		Project struct {
+			Key string `json:"key"`
+		} `json:"project"`
+	}
+
+	type ref struct {
+		ID         string     `json:"id"`
+		Repository repository `json:"repository"`
+	}
+
+	toRef := func(r Ref) ref {
+		rr := ref{ID: r.ID}
+		rr.Repository.Slug = r.Repository.Slug
+		rr.Repository.Project.Key = r.Repository.Project.Key
+		return rr
+	}
+
+	payload := struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		State       string `json:"state"`
+		Open        bool   `json:"open"`
+		Closed      bool   `json:"closed"`
+		FromRef     ref    `json:"fromRef"`
+		ToRef       ref    `json:"toRef"`
+		Locked      bool   `json:"locked"`
+	}{
+		Title:       pr.Title,
+		Description: pr.Description,
+		State:       "OPEN",
+		Open:        true,
+		FromRef:     toRef(pr.FromRef),
+		ToRef:       toRef(pr.ToRef),
+	}
+
+	path := fmt.Sprintf(
+		"rest/api/1.0/projects/%s/repos/%s/pull-requests",
+		pr.ToRef.Repository.Project.Key,
+		pr.ToRef.Repository.Slug,
+	)
+
+	return c.send(ctx, "POST", path, nil, &payload, pr)
+}
+
+// ErrPullRequestNotFound is returned by FindOpenPullRequest when there is no
+// open pull request between the given refs.
+var ErrPullRequestNotFound = errors.New("Bitbucket pull request not found")
+
+// FindOpenPullRequest finds the open pull request from the given PullRequest's
+// FromRef into its ToRef (whose IDs are fully qualified, as for
+// CreatePullRequest) and updates the PullRequest with it. Bitbucket Server
+// allows at most one such pull request. It returns ErrPullRequestNotFound if
+// there is none.
+func (c *Client) FindOpenPullRequest(ctx context.Context, pr *PullRequest) error {
+	if pr.ToRef.Repository.Slug == "" {
+		return errors.New("repository slug empty")
+	}
+
+	if pr.ToRef.Repository.Project == nil || pr.ToRef.Repository.Project.Key == "" {
+		return errors.New("project key empty")
+	}
+
+	path := fmt.Sprintf(
+		"rest/api/1.0/projects/%s/repos/%s/pull-requests",
+		pr.ToRef.Repository.Project.Key,
+		pr.ToRef.Repository.Slug,
+	)
+
+	var token *PageToken
+	for token.HasMore() {
+		qry := url.Values{
+			"state":     {"OPEN"},
+			"direction": {"OUTGOING"},
+			"at":        {pr.FromRef.ID},
+		}
+
+		var prs []*PullRequest
+		next, err := c.page(ctx, path, qry, token, &prs)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range prs {
+			if p.FromRef.ID == pr.FromRef.ID && p.ToRef.ID == pr.ToRef.ID {
+				*pr = *p
+				return nil
+			}
+		}
+
+		token = next
+	}
+
+	return ErrPullRequestNotFound
+}
+
+// CreatePullRequestComment adds a comment with the given text to the given
+// PullRequest, whose ToRef must have its Repository's Slug and Project key set.
+func (c *Client) CreatePullRequestComment(ctx context.Context, pr *PullRequest, text string) error {
+	path, err := pullRequestPath(pr)
+	if err != nil {
+		return err
+	}
+
+	payload := struct {
+		Text string `json:"text"`
+	}{
+		Text: text,
+	}
+
+	return c.send(ctx, "POST", path+"/comments", nil, &payload, nil)
+}
+
+// DeclinePullRequest declines (i.e. closes) the given PullRequest, updating
+// it with the response of the API.
+func (c *Client) DeclinePullRequest(ctx context.Context, pr *PullRequest) error {
+	return c.transitionPullRequest(ctx, pr, "decline")
+}
+
+// ReopenPullRequest reopens the given declined PullRequest, updating it with
+// the response of the API.
+func (c *Client) ReopenPullRequest(ctx context.Context, pr *PullRequest) error {
+	return c.transitionPullRequest(ctx, pr, "reopen")
+}
+
+// MergePullRequest merges the given PullRequest, updating it with the
+// response of the API. Bitbucket Server refuses to merge pull requests
+// whose merge checks (e.g. required builds) haven't passed.
+func (c *Client) MergePullRequest(ctx context.Context, pr *PullRequest) error {
+	return c.transitionPullRequest(ctx, pr, "merge")
+}
+
+func (c *Client) transitionPullRequest(ctx context.Context, pr *PullRequest, action string) error {
+	path, err := pullRequestPath(pr)
+	if err != nil {
+		return err
+	}
+
+	qry := url.Values{"version": {strconv.Itoa(pr.Version)}}
+	return c.send(ctx, "POST", path+"/"+action, qry, nil, pr)
+}
+
+func pullRequestPath(pr *PullRequest) (string, error) {
+	if pr.ToRef.Repository.Slug == "" {
+		return "", errors.New("repository slug empty")
+	}
+
+	if pr.ToRef.Repository.Project == nil || pr.ToRef.Repository.Project.Key == "" {
+		return "", errors.New("project key empty")
+	}
+
+	return fmt.Sprintf(
+		"rest/api/1.0/projects/%s/repos/%s/pull-requests/%d",
+		pr.ToRef.Repository.Project.Key,
+		pr.ToRef.Repository.Slug,
+		pr.ID,
+	), nil
+}
+
 func (c *Client) Repo(ctx context.Context, projectKey, repoSlug string) (*Repo, error) {
 	u := fmt.Sprintf("rest/api/1.0/projects/%s/repos/%s", projectKey, repoSlug)
 	req, err := http.NewRequest("GET", u, nil)
@@ -714,6 +916,61 @@ type Project struct {
 	} `json:"links"`
 }
 
+// PullRequest state constants.
+const (
+	PullRequestStateOpen     = "OPEN"
+	PullRequestStateDeclined = "DECLINED"
+	PullRequestStateMerged   = "MERGED"
+)
+
+// PullRequestParticipant status constants.
+const (
+	ParticipantStatusUnapproved = "UNAPPROVED"
+	ParticipantStatusNeedsWork  = "NEEDS_WORK"
+	ParticipantStatusApproved   = "APPROVED"
+)
+
+// A PullRequest in a Bitbucket Server instance.
+type PullRequest struct {
+	ID           int                      `json:"id"`
+	Version      int                      `json:"version"`
+	Title        string                   `json:"title"`
+	Description  string                   `json:"description"`
+	State        string                   `json:"state"`
+	Open         bool                     `json:"open"`
+	Closed       bool                     `json:"closed"`
+	CreatedDate  int64                    `json:"createdDate"` // Milliseconds since the Unix epoch
+	UpdatedDate  int64                    `json:"updatedDate"` // Milliseconds since the Unix epoch
+	FromRef      Ref                      `json:"fromRef"`
+	ToRef        Ref                      `json:"toRef"`
+	Locked       bool                     `json:"locked"`
+	Author       PullRequestParticipant   `json:"author"`
+	Reviewers    []PullRequestParticipant `json:"reviewers"`
+	Participants []PullRequestParticipant `json:"participants"`
+	Links        struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+// A PullRequestParticipant is a user that authored, reviewed or otherwise
+// participated in a PullRequest.
+type PullRequestParticipant struct {
+	User     *User  `json:"user"`
+	Role     string `json:"role"`
+	Approved bool   `json:"approved"`
+	Status   string `json:"status"`
+}
+
+// A Ref is a Git reference in a Repo, e.g. the source or target branch of a PullRequest.
+type Ref struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+	Repository   Repo   `json:"repository"`
+}
+
 // IsNotFound reports whether err is a Bitbucket Server API not found error.
 func IsNotFound(err error) bool {
 	switch e := errors.Cause(err).(type) {
@@ -4,8 +4,11 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -292,3 +295,146 @@ func TestClient_Users(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_LoadPullRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/1.0/projects/SOUR/repos/vegeta/pull-requests/2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"id": 2,
+			"version": 3,
+			"title": "Add load testing docs",
+			"state": "DECLINED",
+			"reviewers": [{"user": {"name": "john"}, "role": "REVIEWER", "status": "NEEDS_WORK"}],
+			"links": {"self": [{"href": "http://127.0.0.1:7990/projects/SOUR/repos/vegeta/pull-requests/2"}]}
+		}`))
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cli := NewClient(u, nil)
+
+	pr := func(project, slug string, id int) *PullRequest {
+		pr := &PullRequest{ID: id}
+		pr.ToRef.Repository.Slug = slug
+		if project != "" {
+			pr.ToRef.Repository.Project = &Project{Key: project}
+		}
+		return pr
+	}
+
+	for _, tc := range []struct {
+		name string
+		pr   *PullRequest
+		want *PullRequest
+		err  string
+	}{
+		{
+			name: "repo not set",
+			pr:   pr("SOUR", "", 2),
+			err:  "repository slug empty",
+		},
+		{
+			name: "project not set",
+			pr:   pr("", "vegeta", 2),
+			err:  "project key empty",
+		},
+		{
+			name: "non existing pr",
+			pr:   pr("SOUR", "vegeta", 9999),
+			err:  "Bitbucket API HTTP error: code=404",
+		},
+		{
+			name: "success",
+			pr:   pr("SOUR", "vegeta", 2),
+			want: func() *PullRequest {
+				pr := pr("SOUR", "vegeta", 2)
+				pr.Version = 3
+				pr.Title = "Add load testing docs"
+				pr.State = PullRequestStateDeclined
+				pr.Reviewers = []PullRequestParticipant{
+					{User: &User{Name: "john"}, Role: "REVIEWER", Status: ParticipantStatusNeedsWork},
+				}
+				pr.Links.Self = append(pr.Links.Self, struct {
+					Href string `json:"href"`
+				}{Href: "http://127.0.0.1:7990/projects/SOUR/repos/vegeta/pull-requests/2"})
+				return pr
+			}(),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.err == "" {
+				tc.err = "<nil>"
+			}
+
+			err := cli.LoadPullRequest(context.Background(), tc.pr)
+			if have, want := fmt.Sprint(err), tc.err; !strings.HasPrefix(have, want) {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, want)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if have, want := tc.pr, tc.want; !reflect.DeepEqual(have, want) {
+				t.Error(cmp.Diff(have, want))
+			}
+		})
+	}
+}
+
+func TestClient_FindOpenPullRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/rest/api/1.0/projects/SOUR/repos/vegeta/pull-requests" || q.Get("state") != "OPEN" || q.Get("direction") != "OUTGOING" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if q.Get("at") != "refs/heads/campaign" {
+			_, _ = w.Write([]byte(`{"values": [], "isLastPage": true}`))
+			return
+		}
+		// The pull requests from the branch are spread over two pages.
+		if q.Get("start") == "" {
+			_, _ = w.Write([]byte(`{"values": [{"id": 1, "fromRef": {"id": "refs/heads/campaign"}, "toRef": {"id": "refs/heads/release"}}], "isLastPage": false, "nextPageStart": 1}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"values": [{"id": 2, "version": 1, "fromRef": {"id": "refs/heads/campaign"}, "toRef": {"id": "refs/heads/master"}}], "isLastPage": true}`))
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cli := NewClient(u, nil)
+
+	pr := func(fromRef string) *PullRequest {
+		pr := &PullRequest{}
+		pr.FromRef.ID = fromRef
+		pr.ToRef.ID = "refs/heads/master"
+		pr.ToRef.Repository.Slug = "vegeta"
+		pr.ToRef.Repository.Project = &Project{Key: "SOUR"}
+		return pr
+	}
+
+	found := pr("refs/heads/campaign")
+	if err := cli.FindOpenPullRequest(context.Background(), found); err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != 2 || found.Version != 1 {
+		t.Errorf("got pull request %d (version %d), want 2 (version 1)", found.ID, found.Version)
+	}
+
+	if err := cli.FindOpenPullRequest(context.Background(), pr("refs/heads/other")); err != ErrPullRequestNotFound {
+		t.Errorf("got error %v, want %v", err, ErrPullRequestNotFound)
+	}
+}
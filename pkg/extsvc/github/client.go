@@ -266,6 +266,14 @@ func (c *Client) requestGraphQL(ctx context.Context, token, query string, vars m
 	if err != nil {
 		return err
 	}
+
+	// Enable the checks API (check suites and check runs of commits). See
+	// https://developer.github.com/v4/previews/#checks
+	//
+	// Enable repository topics (the repositoryTopics field), which are still a preview on older
+	// versions of GitHub Enterprise. See https://developer.github.com/v4/previews/#repository-topics
+	req.Header.Add("Accept", "application/vnd.github.antiope-preview+json,application/vnd.github.mercy-preview+json")
+
 	var respBody struct {
 		Data   json.RawMessage `json:"data"`
 		Errors graphqlErrors   `json:"errors"`
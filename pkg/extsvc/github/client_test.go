@@ -22,6 +22,7 @@ import (
 	"github.com/sergi/go-diff/diffmatchpatch"
 	"github.com/sourcegraph/sourcegraph/pkg/httpcli"
 	"github.com/sourcegraph/sourcegraph/pkg/httptestutil"
+	"github.com/sourcegraph/sourcegraph/pkg/ratelimit"
 	"github.com/sourcegraph/sourcegraph/pkg/rcache"
 )
 
@@ -70,6 +71,37 @@ func TestUnmarshal(t *testing.T) {
 	}
 }
 
+func TestClient_previewMediaTypes(t *testing.T) {
+	var accept []string
+	c := &Client{
+		apiURL:    &url.URL{Scheme: "https", Host: "example.com", Path: "/"},
+		RateLimit: &ratelimit.Monitor{},
+		httpClient: httpcli.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			accept = append(accept, req.Header.Get("Accept"))
+			return &http.Response{
+				Request:    req,
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"data": {}}`)),
+			}, nil
+		}),
+	}
+
+	ctx := context.Background()
+	if err := c.requestGraphQL(ctx, "", "query {}", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.requestGet(ctx, "", "repos/o/r", &struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Repository topics require the mercy-preview media type in both APIs.
+	for i, a := range accept {
+		if !strings.Contains(a, "application/vnd.github.mercy-preview+json") {
+			t.Errorf("request %d: got Accept %q, want it to include the mercy-preview media type", i, a)
+		}
+	}
+}
+
 func TestNewRepoCache_GitHubDotCom(t *testing.T) {
 	url, _ := url.Parse("https://www.github.com")
 	token := "asdf"
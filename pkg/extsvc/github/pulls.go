@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 // An Actor represents an object which can take actions on GitHub. Typically a User or Bot.
@@ -25,6 +27,7 @@ type GitActor struct {
 
 // A Review of a PullRequest.
 type Review struct {
+	ID          string
 	Body        string
 	State       string
 	URL         string
@@ -41,6 +44,7 @@ type Commit struct {
 	URL           string
 	Committer     GitActor
 	Status        Status
+	CheckSuites   []CheckSuite
 	CommittedDate time.Time
 	PushedDate    time.Time
 }
@@ -62,6 +66,42 @@ type StatusContext struct {
 	Creator     Actor
 }
 
+// Statuses and conclusions of CheckSuites and CheckRuns.
+const (
+	CheckStatusQueued     = "QUEUED"
+	CheckStatusInProgress = "IN_PROGRESS"
+	CheckStatusCompleted  = "COMPLETED"
+
+	CheckConclusionSuccess        = "SUCCESS"
+	CheckConclusionNeutral        = "NEUTRAL"
+	CheckConclusionSkipped        = "SKIPPED"
+	CheckConclusionFailure        = "FAILURE"
+	CheckConclusionCancelled      = "CANCELLED"
+	CheckConclusionTimedOut       = "TIMED_OUT"
+	CheckConclusionActionRequired = "ACTION_REQUIRED"
+)
+
+// A CheckSuite is a collection of the CheckRuns created by a single GitHub
+// App for a Commit. Status is one of the CheckStatus constants and
+// Conclusion, set once the suite completed, one of the CheckConclusion
+// constants.
+type CheckSuite struct {
+	ID         string
+	Status     string
+	Conclusion string
+	App        struct{ Name string }
+	CheckRuns  []CheckRun
+}
+
+// A CheckRun is a single check, such as a CI build, run on a Commit.
+type CheckRun struct {
+	ID         string
+	Name       string
+	Status     string
+	Conclusion string
+	DetailsURL string
+}
+
 // PullRequest is a GitHub pull request.
 type PullRequest struct {
 	RepoWithOwner string `json:"-"`
@@ -74,10 +114,28 @@ type PullRequest struct {
 	Author        Actor
 	Participants  []Actor
 	Reviews       []Review
+	TimelineItems []TimelineItem
+	HeadCommit    *Commit
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
 
+// TimelineItem types.
+const (
+	TimelineItemTypeClosed   = "ClosedEvent"
+	TimelineItemTypeMerged   = "MergedEvent"
+	TimelineItemTypeReopened = "ReopenedEvent"
+)
+
+// A TimelineItem is an event in the timeline of a PullRequest that changed
+// its state. Type is one of the TimelineItemType constants.
+type TimelineItem struct {
+	Type      string `json:"__typename"`
+	ID        string
+	Actor     Actor
+	CreatedAt time.Time
+}
+
 // LoadPullRequests loads a list of PullRequests from Github.
 func (c *Client) LoadPullRequests(ctx context.Context, prs ...*PullRequest) error {
 	type repository struct {
@@ -109,35 +167,8 @@ func (c *Client) LoadPullRequests(ctx context.Context, prs ...*PullRequest) erro
 	}
 
 	var q strings.Builder
-	q.WriteString(`
-		fragment actor on Actor { avatarUrl, login, url }
-		fragment pr on PullRequest {
-		  id, title, body, state, url, number, createdAt, updatedAt
-		  author { ...actor }
-		  participants(first: 100) { nodes { ...actor } }
-		  reviews(first: 100) {
-			nodes {
-			  body, state, url, createdAt, submittedAt
-			  author { ...actor }
-			  commit {
-				oid, message, committedDate, pushedDate, url
-				committer {
-				  avatarUrl, email, name
-				  user { ...actor }
-				}
-				status {
-				  state
-				  contexts {
-					avatarUrl, context, description, state, targetUrl, createdAt
-					creator { ...actor }
-				  }
-				}
-			  }
-			}
-		  }
-		}
-		query {
-	`)
+	q.WriteString(pullRequestFragments)
+	q.WriteString("query {\n")
 
 	for repoLabel, r := range labeled {
 		q.WriteString(fmt.Sprintf("%s: repository(owner: %q, name: %q) {\n",
@@ -154,11 +185,7 @@ func (c *Client) LoadPullRequests(ctx context.Context, prs ...*PullRequest) erro
 
 	q.WriteString("}")
 
-	var results map[string]map[string]*struct {
-		PullRequest
-		Participants struct{ Nodes []Actor }
-		Reviews      struct{ Nodes []Review }
-	}
+	var results map[string]map[string]*pullRequestResult
 
 	err := c.requestGraphQL(ctx, "", q.String(), nil, &results)
 	if err != nil {
@@ -167,11 +194,335 @@ func (c *Client) LoadPullRequests(ctx context.Context, prs ...*PullRequest) erro
 
 	for repoLabel, prs := range results {
 		for prLabel, pr := range prs {
-			pr.PullRequest.Participants = pr.Participants.Nodes
-			pr.PullRequest.Reviews = pr.Reviews.Nodes
-			*labeled[repoLabel].PRs[prLabel] = pr.PullRequest
+			*labeled[repoLabel].PRs[prLabel] = pr.pullRequest()
 		}
 	}
 
 	return nil
 }
+
+// CreatePullRequestInput is the input of the CreatePullRequest method.
+type CreatePullRequestInput struct {
+	// The Node ID of the repository.
+	RepositoryID string `json:"repositoryId"`
+	// The name of the branch you want your changes pulled into. This should
+	// be an existing branch on the current repository.
+	BaseRefName string `json:"baseRefName"`
+	// The name of the branch where your changes are implemented.
+	HeadRefName string `json:"headRefName"`
+	// The title of the pull request.
+	Title string `json:"title"`
+	// The body of the pull request (optional).
+	Body string `json:"body"`
+}
+
+// CreatePullRequest creates a PullRequest on Github.
+func (c *Client) CreatePullRequest(ctx context.Context, in *CreatePullRequestInput) (*PullRequest, error) {
+	var q strings.Builder
+	q.WriteString(pullRequestFragments)
+	q.WriteString(`mutation CreatePullRequest($input: CreatePullRequestInput!) {
+  createPullRequest(input: $input) {
+    pullRequest {
+      ... pr
+    }
+  }
+}`)
+
+	var result struct {
+		CreatePullRequest struct {
+			PullRequest *pullRequestResult
+		}
+	}
+
+	input := map[string]interface{}{"input": in}
+	err := c.requestGraphQL(ctx, "", q.String(), input, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.CreatePullRequest.PullRequest == nil {
+		return nil, errors.New("github: createPullRequest returned no pull request")
+	}
+
+	pr := result.CreatePullRequest.PullRequest.pullRequest()
+	return &pr, nil
+}
+
+// ErrPullRequestNotFound is returned by GetOpenPullRequestByRefs when there
+// is no open pull request between the given refs.
+var ErrPullRequestNotFound = errors.New("GitHub pull request not found")
+
+// GetOpenPullRequestByRefs returns the open PullRequest of the repository
+// with the given name and owner ("org/repo-name") from the branch
+// headRefName into the branch baseRefName. GitHub allows at most one such
+// pull request.
+func (c *Client) GetOpenPullRequestByRefs(ctx context.Context, nameWithOwner, headRefName, baseRefName string) (*PullRequest, error) {
+	owner, name, err := SplitRepositoryNameWithOwner(nameWithOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	var q strings.Builder
+	q.WriteString(pullRequestFragments)
+	q.WriteString(`query GetOpenPullRequestByRefs($owner: String!, $name: String!, $head: String!, $base: String!) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(headRefName: $head, baseRefName: $base, states: OPEN, first: 1) {
+      nodes { ... pr }
+    }
+  }
+}`)
+
+	var result struct {
+		Repository *struct {
+			PullRequests struct{ Nodes []*pullRequestResult }
+		}
+	}
+
+	vars := map[string]interface{}{"owner": owner, "name": name, "head": headRefName, "base": baseRefName}
+	if err := c.requestGraphQL(ctx, "", q.String(), vars, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Repository == nil || len(result.Repository.PullRequests.Nodes) == 0 {
+		return nil, ErrPullRequestNotFound
+	}
+
+	pr := result.Repository.PullRequests.Nodes[0].pullRequest()
+	pr.RepoWithOwner = nameWithOwner
+	return &pr, nil
+}
+
+// AddComment adds a comment with the given body to the issue or pull
+// request with the given node ID.
+func (c *Client) AddComment(ctx context.Context, subjectID, body string) error {
+	q := `mutation AddComment($input: AddCommentInput!) {
+  addComment(input: $input) { subject { id } }
+}`
+
+	input := map[string]interface{}{"input": struct {
+		SubjectID string `json:"subjectId"`
+		Body      string `json:"body"`
+	}{
+		SubjectID: subjectID,
+		Body:      body,
+	}}
+
+	var result struct{}
+	return c.requestGraphQL(ctx, "", q, input, &result)
+}
+
+// ClosePullRequest closes the given PullRequest, updating it with the
+// result of the mutation.
+func (c *Client) ClosePullRequest(ctx context.Context, pr *PullRequest) error {
+	return c.mutatePullRequest(ctx, pr, "closePullRequest", "ClosePullRequestInput")
+}
+
+// ReopenPullRequest reopens the given closed PullRequest, updating it with
+// the result of the mutation.
+func (c *Client) ReopenPullRequest(ctx context.Context, pr *PullRequest) error {
+	return c.mutatePullRequest(ctx, pr, "reopenPullRequest", "ReopenPullRequestInput")
+}
+
+// MergePullRequest merges the given PullRequest, updating it with the
+// result of the mutation.
+func (c *Client) MergePullRequest(ctx context.Context, pr *PullRequest) error {
+	return c.mutatePullRequest(ctx, pr, "mergePullRequest", "MergePullRequestInput")
+}
+
+// mutatePullRequest runs the given mutation, whose input type only requires
+// a pullRequestId, and updates pr with the pull request it returns.
+func (c *Client) mutatePullRequest(ctx context.Context, pr *PullRequest, mutation, inputType string) error {
+	var q strings.Builder
+	q.WriteString(pullRequestFragments)
+	q.WriteString(fmt.Sprintf(`mutation($input: %s!) {
+  result: %s(input: $input) {
+    pullRequest {
+      ... pr
+    }
+  }
+}`, inputType, mutation))
+
+	var result struct {
+		Result struct {
+			PullRequest *pullRequestResult
+		}
+	}
+
+	input := map[string]interface{}{"input": struct {
+		PullRequestID string `json:"pullRequestId"`
+	}{
+		PullRequestID: pr.ID,
+	}}
+
+	err := c.requestGraphQL(ctx, "", q.String(), input, &result)
+	if err != nil {
+		return err
+	}
+
+	if result.Result.PullRequest == nil {
+		return errors.Errorf("github: %s returned no pull request", mutation)
+	}
+
+	repoWithOwner := pr.RepoWithOwner
+	*pr = result.Result.PullRequest.pullRequest()
+	pr.RepoWithOwner = repoWithOwner
+
+	return nil
+}
+
+// RequestReviews requests reviews of the given PullRequest from the users
+// with the given logins, in addition to its already requested reviewers.
+// Requesting a review from a user who already reviewed the pull request
+// asks them to review it again.
+func (c *Client) RequestReviews(ctx context.Context, pr *PullRequest, logins ...string) error {
+	if len(logins) == 0 {
+		return nil
+	}
+
+	var q strings.Builder
+	q.WriteString("query {\n")
+	for i, login := range logins {
+		q.WriteString(fmt.Sprintf("u%d: user(login: %q) { id }\n", i, login))
+	}
+	q.WriteString("}")
+
+	var users map[string]*struct{ ID string }
+	if err := c.requestGraphQL(ctx, "", q.String(), nil, &users); err != nil {
+		return err
+	}
+
+	userIDs := make([]string, 0, len(users))
+	for _, u := range users {
+		if u != nil {
+			userIDs = append(userIDs, u.ID)
+		}
+	}
+
+	m := `mutation RequestReviews($input: RequestReviewsInput!) {
+  requestReviews(input: $input) { pullRequest { id } }
+}`
+
+	input := map[string]interface{}{"input": struct {
+		PullRequestID string   `json:"pullRequestId"`
+		UserIDs       []string `json:"userIds"`
+		Union         bool     `json:"union"`
+	}{
+		PullRequestID: pr.ID,
+		UserIDs:       userIDs,
+		Union:         true,
+	}}
+
+	var result struct{}
+	return c.requestGraphQL(ctx, "", m, input, &result)
+}
+
+// pullRequestResult is the shape of a pull request as returned by the
+// GraphQL API when queried with the pr fragment in pullRequestFragments.
+type pullRequestResult struct {
+	PullRequest
+	Participants  struct{ Nodes []Actor }
+	Reviews       struct{ Nodes []Review }
+	TimelineItems struct{ Nodes []TimelineItem }
+	Commits       struct {
+		Nodes []struct{ Commit commitResult }
+	}
+}
+
+func (r *pullRequestResult) pullRequest() PullRequest {
+	pr := r.PullRequest
+	pr.Participants = r.Participants.Nodes
+	pr.Reviews = r.Reviews.Nodes
+	pr.TimelineItems = r.TimelineItems.Nodes
+	for _, n := range r.Commits.Nodes {
+		commit := n.Commit.commit()
+		pr.HeadCommit = &commit
+	}
+	return pr
+}
+
+// commitResult is the shape of the head commit of a pull request as returned
+// by the GraphQL API when queried with the pr fragment in
+// pullRequestFragments.
+type commitResult struct {
+	Commit
+	Status      *Status
+	CheckSuites struct {
+		Nodes []struct {
+			CheckSuite
+			CheckRuns struct{ Nodes []CheckRun }
+		}
+	}
+}
+
+func (r *commitResult) commit() Commit {
+	c := r.Commit
+	if r.Status != nil {
+		c.Status = *r.Status
+	}
+	c.CheckSuites = make([]CheckSuite, 0, len(r.CheckSuites.Nodes))
+	for _, n := range r.CheckSuites.Nodes {
+		cs := n.CheckSuite
+		cs.CheckRuns = n.CheckRuns.Nodes
+		c.CheckSuites = append(c.CheckSuites, cs)
+	}
+	return c
+}
+
+// pullRequestFragments are the GraphQL fragments used to query all the
+// fields of a PullRequest.
+const pullRequestFragments = `
+		fragment actor on Actor { avatarUrl, login, url }
+		fragment pr on PullRequest {
+		  id, title, body, state, url, number, createdAt, updatedAt
+		  author { ...actor }
+		  participants(first: 100) { nodes { ...actor } }
+		  reviews(first: 100) {
+			nodes {
+			  id, body, state, url, createdAt, submittedAt
+			  author { ...actor }
+			  commit {
+				oid, message, committedDate, pushedDate, url
+				committer {
+				  avatarUrl, email, name
+				  user { ...actor }
+				}
+				status {
+				  state
+				  contexts {
+					avatarUrl, context, description, state, targetUrl, createdAt
+					creator { ...actor }
+				  }
+				}
+			  }
+			}
+		  }
+		  timelineItems(first: 250, itemTypes: [CLOSED_EVENT, MERGED_EVENT, REOPENED_EVENT]) {
+			nodes {
+			  __typename
+			  ... on ClosedEvent { id, createdAt, actor { ...actor } }
+			  ... on MergedEvent { id, createdAt, actor { ...actor } }
+			  ... on ReopenedEvent { id, createdAt, actor { ...actor } }
+			}
+		  }
+		  commits(last: 1) {
+			nodes {
+			  commit {
+				oid, message, committedDate, pushedDate, url
+				status {
+				  state
+				  contexts { context, description, state, targetUrl, createdAt }
+				}
+				checkSuites(first: 20) {
+				  nodes {
+					id, status, conclusion
+					app { name }
+					checkRuns(first: 50) {
+					  nodes { id, name, status, conclusion, detailsUrl }
+					}
+				  }
+				}
+			  }
+			}
+		  }
+		}
+`
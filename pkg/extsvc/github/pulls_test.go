@@ -0,0 +1,41 @@
+package github
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClient_GetOpenPullRequestByRefs(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		response   string
+		wantNumber int
+		err        error
+	}{
+		{
+			name:       "found",
+			response:   `{"data": {"repository": {"pullRequests": {"nodes": [{"id": "MDExOlB1bGxSZXF1ZXN0MQ==", "number": 7, "state": "OPEN"}]}}}}`,
+			wantNumber: 7,
+		},
+		{
+			name:     "not found",
+			response: `{"data": {"repository": {"pullRequests": {"nodes": []}}}}`,
+			err:      ErrPullRequestNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestClient(t, newMockHTTPResponseBody(tc.response, 0))
+
+			pr, err := c.GetOpenPullRequestByRefs(context.Background(), "o/r", "campaign", "master")
+			if err != tc.err {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+			if err != nil {
+				return
+			}
+			if pr.Number != tc.wantNumber || pr.RepoWithOwner != "o/r" {
+				t.Errorf("got pull request %d of %q, want %d of %q", pr.Number, pr.RepoWithOwner, tc.wantNumber, "o/r")
+			}
+		})
+	}
+}
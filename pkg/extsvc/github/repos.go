@@ -26,15 +26,55 @@ func SplitRepositoryNameWithOwner(nameWithOwner string) (owner, repo string, err
 
 // Repository is a GitHub repository.
 type Repository struct {
-	ID               string // ID of repository (GitHub GraphQL ID, not GitHub database ID)
-	DatabaseID       int64  // The integer database id
-	NameWithOwner    string // full name of repository ("owner/name")
-	Description      string // description of repository
-	URL              string // the web URL of this repository ("https://github.com/foo/bar")
-	IsPrivate        bool   // whether the repository is private
-	IsFork           bool   // whether the repository is a fork of another repository
-	IsArchived       bool   // whether the repository is archived on the code host
-	ViewerPermission string // ADMIN, WRITE, READ, or empty if unknown. Only the graphql api populates this.
+	ID               string   // ID of repository (GitHub GraphQL ID, not GitHub database ID)
+	DatabaseID       int64    // The integer database id
+	NameWithOwner    string   // full name of repository ("owner/name")
+	Description      string   // description of repository
+	URL              string   // the web URL of this repository ("https://github.com/foo/bar")
+	IsPrivate        bool     // whether the repository is private
+	IsFork           bool     // whether the repository is a fork of another repository
+	IsArchived       bool     // whether the repository is archived on the code host
+	ViewerPermission string   // ADMIN, WRITE, READ, or empty if unknown. Only the graphql api populates this.
+	Language         string   // the primary programming language of the repository, if any
+	StargazerCount   int      // the number of stars the repository has
+	Topics           []string // the topics the repository is labeled with
+}
+
+// UnmarshalJSON unmarshals a Repository from either its own JSON encoding or
+// from the nested shape of the GraphQL RepositoryFields fragment.
+func (r *Repository) UnmarshalJSON(data []byte) error {
+	type repository Repository
+	v := struct {
+		*repository
+		PrimaryLanguage  *struct{ Name string }
+		Stargazers       *struct{ TotalCount int }
+		RepositoryTopics *struct {
+			Nodes []struct {
+				Topic struct{ Name string }
+			}
+		}
+	}{repository: (*repository)(r)}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if v.PrimaryLanguage != nil {
+		r.Language = v.PrimaryLanguage.Name
+	}
+
+	if v.Stargazers != nil {
+		r.StargazerCount = v.Stargazers.TotalCount
+	}
+
+	if v.RepositoryTopics != nil {
+		r.Topics = make([]string, 0, len(v.RepositoryTopics.Nodes))
+		for _, n := range v.RepositoryTopics.Nodes {
+			r.Topics = append(r.Topics, n.Topic.Name)
+		}
+	}
+
+	return nil
 }
 
 // repositoryFieldsGraphQLFragment returns a GraphQL fragment that contains the fields needed to populate the
@@ -52,6 +92,9 @@ fragment RepositoryFields on Repository {
 	isFork
 	isArchived
 	viewerPermission
+	primaryLanguage { name }
+	stargazers { totalCount }
+	repositoryTopics(first: 20) { nodes { topic { name } } }
 }
 	`
 	}
@@ -68,6 +111,9 @@ fragment RepositoryFields on Repository {
 	isPrivate
 	isFork
 	isArchived
+	primaryLanguage { name }
+	stargazers { totalCount }
+	repositoryTopics(first: 20) { nodes { topic { name } } }
 }
 	`
 }
@@ -245,6 +291,9 @@ type restRepository struct {
 	Private     bool
 	Fork        bool
 	Archived    bool
+	Language    string
+	Stargazers  int      `json:"stargazers_count"`
+	Topics      []string // only returned with the mercy-preview media type
 }
 
 // getRepositoryFromAPI attempts to fetch a repository from the GitHub API without use of the redis cache.
@@ -267,14 +316,17 @@ func (c *Client) getRepositoryFromAPI(ctx context.Context, owner, name string) (
 // to a standard format.
 func convertRestRepo(restRepo restRepository) *Repository {
 	return &Repository{
-		ID:            restRepo.ID,
-		DatabaseID:    restRepo.DatabaseID,
-		NameWithOwner: restRepo.FullName,
-		Description:   restRepo.Description,
-		URL:           restRepo.HTMLURL,
-		IsPrivate:     restRepo.Private,
-		IsFork:        restRepo.Fork,
-		IsArchived:    restRepo.Archived,
+		ID:             restRepo.ID,
+		DatabaseID:     restRepo.DatabaseID,
+		NameWithOwner:  restRepo.FullName,
+		Description:    restRepo.Description,
+		URL:            restRepo.HTMLURL,
+		IsPrivate:      restRepo.Private,
+		IsFork:         restRepo.Fork,
+		IsArchived:     restRepo.Archived,
+		Language:       restRepo.Language,
+		StargazerCount: restRepo.Stargazers,
+		Topics:         restRepo.Topics,
 	}
 }
 
@@ -3,6 +3,7 @@ package github
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -427,7 +428,7 @@ func repoListsAreEqual(a []*Repository, b []*Repository) bool {
 		return false
 	}
 	for i := 0; i < len(a); i++ {
-		if *a[i] != *b[i] {
+		if !reflect.DeepEqual(*a[i], *b[i]) {
 			return false
 		}
 	}
@@ -787,3 +788,34 @@ func TestClient_GetReposByNameWithOwner(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_UnmarshalJSON(t *testing.T) {
+	want := Repository{
+		ID:             "i",
+		NameWithOwner:  "o/n",
+		Language:       "Go",
+		StargazerCount: 42,
+		Topics:         []string{"search", "code"},
+	}
+
+	for name, data := range map[string]string{
+		"graphql": `{
+			"id": "i",
+			"nameWithOwner": "o/n",
+			"primaryLanguage": {"name": "Go"},
+			"stargazers": {"totalCount": 42},
+			"repositoryTopics": {"nodes": [{"topic": {"name": "search"}}, {"topic": {"name": "code"}}]}
+		}`,
+		"cached": `{"ID": "i", "NameWithOwner": "o/n", "Language": "Go", "StargazerCount": 42, "Topics": ["search", "code"]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var have Repository
+			if err := json.Unmarshal([]byte(data), &have); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(have, want) {
+				t.Errorf("have %s, want %s", spew.Sdump(have), spew.Sdump(want))
+			}
+		})
+	}
+}
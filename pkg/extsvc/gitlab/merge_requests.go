@@ -0,0 +1,333 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// MergeRequestState is the state of a GitLab merge request.
+type MergeRequestState string
+
+// MergeRequestState constants.
+const (
+	MergeRequestStateOpened MergeRequestState = "opened"
+	MergeRequestStateClosed MergeRequestState = "closed"
+	MergeRequestStateLocked MergeRequestState = "locked"
+	MergeRequestStateMerged MergeRequestState = "merged"
+)
+
+// MergeRequest is a GitLab merge request (equivalent to a GitHub pull request).
+type MergeRequest struct {
+	ID             int               `json:"id"`            // globally unique ID of the merge request
+	IID            int               `json:"iid"`           // ID of the merge request, unique within its project
+	ProjectID      int               `json:"project_id"`    // ID of the target project
+	Title          string            `json:"title"`         // title of the merge request
+	Description    string            `json:"description"`   // description (body) of the merge request
+	State          MergeRequestState `json:"state"`         // "opened", "closed", "locked", or "merged"
+	WebURL         string            `json:"web_url"`       // the web URL of this merge request
+	SourceBranch   string            `json:"source_branch"` // the branch containing the changes
+	TargetBranch   string            `json:"target_branch"` // the branch the changes will be merged into
+	WorkInProgress bool              `json:"work_in_progress"`
+	Author         *User             `json:"author"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	MergedAt       *time.Time        `json:"merged_at"`
+	ClosedAt       *time.Time        `json:"closed_at"`
+
+	// Approvals is the approval status of the merge request. It is nil if the
+	// GitLab instance doesn't support merge request approvals.
+	Approvals *MergeRequestApprovals `json:"approvals,omitempty"`
+
+	// HeadPipeline is the latest pipeline run for the head commit of the
+	// merge request. It is nil if no pipeline was run.
+	HeadPipeline *Pipeline `json:"head_pipeline,omitempty"`
+}
+
+// MergeRequestApprovals is the approval status of a GitLab merge request.
+type MergeRequestApprovals struct {
+	Approved          bool        `json:"approved"`
+	ApprovalsRequired int         `json:"approvals_required"`
+	ApprovalsLeft     int         `json:"approvals_left"`
+	ApprovedBy        []*Approver `json:"approved_by"`
+}
+
+// Approver is a user that approved a GitLab merge request.
+type Approver struct {
+	User *User `json:"user"`
+}
+
+type GetMergeRequestOp struct {
+	ProjID                int
+	ProjPathWithNamespace string
+	IID                   int
+	CommonOp
+}
+
+// GetMergeRequest gets a merge request, together with its approval status and the jobs of its
+// head pipeline, from the GitLab API.
+// Requests results are not cached by the client (i.e., setting op.NoCache to true does not
+// alter behavior), since merge requests change often and callers need their latest state.
+func (c *Client) GetMergeRequest(ctx context.Context, op GetMergeRequestOp) (*MergeRequest, error) {
+	if MockGetMergeRequest != nil {
+		return MockGetMergeRequest(c, ctx, op)
+	}
+
+	if op.ProjID != 0 && op.ProjPathWithNamespace != "" {
+		return nil, errors.New("invalid args (specify exactly one of id and projPathWithNamespace")
+	}
+
+	var projSpecifier string
+	if op.ProjID != 0 {
+		projSpecifier = strconv.Itoa(op.ProjID)
+	} else {
+		projSpecifier = url.PathEscape(op.ProjPathWithNamespace)
+	}
+
+	path := fmt.Sprintf("projects/%s/merge_requests/%d", projSpecifier, op.IID)
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mr MergeRequest
+	if _, err := c.do(ctx, req, &mr); err != nil {
+		return nil, err
+	}
+
+	if req, err = http.NewRequest("GET", path+"/approvals", nil); err != nil {
+		return nil, err
+	}
+
+	var approvals MergeRequestApprovals
+	switch _, err := c.do(ctx, req, &approvals); HTTPErrorCode(err) {
+	case 0:
+		if err != nil {
+			return nil, err
+		}
+		mr.Approvals = &approvals
+	case http.StatusNotFound, http.StatusForbidden:
+		// Merge request approvals are not available in all GitLab editions.
+	default:
+		return nil, err
+	}
+
+	if mr.HeadPipeline != nil {
+		// Failing to load the jobs is not fatal: the pipeline status is
+		// still useful without them.
+		jobs, err := c.listPipelineJobs(ctx, projSpecifier, mr.HeadPipeline.ID)
+		if err != nil {
+			log15.Warn("Failed to list GitLab pipeline jobs", "project", projSpecifier, "pipeline", mr.HeadPipeline.ID, "error", err)
+		}
+		mr.HeadPipeline.Jobs = jobs
+	}
+
+	return &mr, nil
+}
+
+type CreateMergeRequestOp struct {
+	ProjID       int
+	SourceBranch string
+	TargetBranch string
+	Title        string
+	Description  string
+}
+
+// CreateMergeRequest creates a merge request in the given project from
+// SourceBranch into TargetBranch.
+func (c *Client) CreateMergeRequest(ctx context.Context, op CreateMergeRequestOp) (*MergeRequest, error) {
+	if MockCreateMergeRequest != nil {
+		return MockCreateMergeRequest(c, ctx, op)
+	}
+
+	payload, err := json.Marshal(struct {
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+		Title        string `json:"title"`
+		Description  string `json:"description,omitempty"`
+	}{
+		SourceBranch: op.SourceBranch,
+		TargetBranch: op.TargetBranch,
+		Title:        op.Title,
+		Description:  op.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("projects/%d/merge_requests", op.ProjID)
+	req, err := http.NewRequest("POST", path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	var mr MergeRequest
+	if _, err := c.do(ctx, req, &mr); err != nil {
+		return nil, err
+	}
+
+	return &mr, nil
+}
+
+// ErrMergeRequestNotFound is returned by FindOpenMergeRequest when there is no
+// open merge request between the given branches.
+var ErrMergeRequestNotFound = errors.New("GitLab merge request not found")
+
+type FindOpenMergeRequestOp struct {
+	ProjID       int
+	SourceBranch string
+	TargetBranch string
+}
+
+// FindOpenMergeRequest gets the open merge request in the given project from
+// SourceBranch into TargetBranch, like GetMergeRequest. GitLab allows at most
+// one such merge request. It returns ErrMergeRequestNotFound if there is none.
+func (c *Client) FindOpenMergeRequest(ctx context.Context, op FindOpenMergeRequestOp) (*MergeRequest, error) {
+	if MockFindOpenMergeRequest != nil {
+		return MockFindOpenMergeRequest(c, ctx, op)
+	}
+
+	q := url.Values{
+		"state":         {"opened"},
+		"source_branch": {op.SourceBranch},
+		"target_branch": {op.TargetBranch},
+	}
+	path := fmt.Sprintf("projects/%d/merge_requests?%s", op.ProjID, q.Encode())
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mrs []*MergeRequest
+	if _, err := c.do(ctx, req, &mrs); err != nil {
+		return nil, err
+	}
+
+	if len(mrs) == 0 {
+		return nil, ErrMergeRequestNotFound
+	}
+
+	return c.GetMergeRequest(ctx, GetMergeRequestOp{ProjID: op.ProjID, IID: mrs[0].IID})
+}
+
+type CreateMergeRequestNoteOp struct {
+	ProjID int
+	IID    int
+	Body   string
+}
+
+// CreateMergeRequestNote adds a comment to a merge request.
+func (c *Client) CreateMergeRequestNote(ctx context.Context, op CreateMergeRequestNoteOp) error {
+	if MockCreateMergeRequestNote != nil {
+		return MockCreateMergeRequestNote(c, ctx, op)
+	}
+
+	payload, err := json.Marshal(struct {
+		Body string `json:"body"`
+	}{
+		Body: op.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("projects/%d/merge_requests/%d/notes", op.ProjID, op.IID)
+	req, err := http.NewRequest("POST", path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	var note struct{}
+	_, err = c.do(ctx, req, &note)
+	return err
+}
+
+// MergeRequestStateEvent is an event that changes the state of a merge request.
+type MergeRequestStateEvent string
+
+// MergeRequestStateEvent constants.
+const (
+	MergeRequestStateEventClose  MergeRequestStateEvent = "close"
+	MergeRequestStateEventReopen MergeRequestStateEvent = "reopen"
+)
+
+type UpdateMergeRequestOp struct {
+	ProjID     int
+	IID        int
+	StateEvent MergeRequestStateEvent
+}
+
+// UpdateMergeRequest updates a merge request, e.g. to close or reopen it.
+// The returned merge request doesn't include its approval status.
+func (c *Client) UpdateMergeRequest(ctx context.Context, op UpdateMergeRequestOp) (*MergeRequest, error) {
+	if MockUpdateMergeRequest != nil {
+		return MockUpdateMergeRequest(c, ctx, op)
+	}
+
+	payload, err := json.Marshal(struct {
+		StateEvent MergeRequestStateEvent `json:"state_event,omitempty"`
+	}{
+		StateEvent: op.StateEvent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("projects/%d/merge_requests/%d", op.ProjID, op.IID)
+	req, err := http.NewRequest("PUT", path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	var mr MergeRequest
+	if _, err := c.do(ctx, req, &mr); err != nil {
+		return nil, err
+	}
+
+	return &mr, nil
+}
+
+type AcceptMergeRequestOp struct {
+	ProjID int
+	IID    int
+	// MergeWhenPipelineSucceeds, when true, makes GitLab merge the merge
+	// request once its pipeline succeeds instead of merging it right away.
+	MergeWhenPipelineSucceeds bool
+}
+
+// AcceptMergeRequest merges a merge request.
+func (c *Client) AcceptMergeRequest(ctx context.Context, op AcceptMergeRequestOp) (*MergeRequest, error) {
+	if MockAcceptMergeRequest != nil {
+		return MockAcceptMergeRequest(c, ctx, op)
+	}
+
+	payload, err := json.Marshal(struct {
+		MergeWhenPipelineSucceeds bool `json:"merge_when_pipeline_succeeds"`
+	}{
+		MergeWhenPipelineSucceeds: op.MergeWhenPipelineSucceeds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("projects/%d/merge_requests/%d/merge", op.ProjID, op.IID)
+	req, err := http.NewRequest("PUT", path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	var mr MergeRequest
+	if _, err := c.do(ctx, req, &mr); err != nil {
+		return nil, err
+	}
+
+	return &mr, nil
+}
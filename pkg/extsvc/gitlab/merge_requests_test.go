@@ -0,0 +1,203 @@
+package gitlab
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockHTTPResponsePaths struct {
+	count     int
+	responses map[string]string // keyed by URL path
+}
+
+func (s *mockHTTPResponsePaths) Do(req *http.Request) (*http.Response, error) {
+	s.count++
+	body, ok := s.responses[req.URL.Path]
+	if !ok {
+		return &http.Response{
+			Request:    req,
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"404 Not found"}`)),
+		}, nil
+	}
+	return &http.Response{
+		Request:    req,
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func TestClient_GetMergeRequest(t *testing.T) {
+	const mr = `
+{
+	"id": 84,
+	"iid": 3,
+	"project_id": 1,
+	"title": "Fix a bunch of bugs",
+	"description": "This fixes a bunch of bugs",
+	"state": "opened",
+	"web_url": "https://example.com/n1/n2/r/merge_requests/3",
+	"source_branch": "fix-bugs",
+	"target_branch": "master",
+	"author": {"id": 1, "username": "alice"},
+	"created_at": "2019-09-25T12:00:00.000Z",
+	"updated_at": "2019-09-26T12:00:00.000Z"
+}`
+
+	created := time.Date(2019, 9, 25, 12, 0, 0, 0, time.UTC)
+	updated := time.Date(2019, 9, 26, 12, 0, 0, 0, time.UTC)
+
+	want := &MergeRequest{
+		ID:           84,
+		IID:          3,
+		ProjectID:    1,
+		Title:        "Fix a bunch of bugs",
+		Description:  "This fixes a bunch of bugs",
+		State:        MergeRequestStateOpened,
+		WebURL:       "https://example.com/n1/n2/r/merge_requests/3",
+		SourceBranch: "fix-bugs",
+		TargetBranch: "master",
+		Author:       &User{ID: 1, Username: "alice"},
+		CreatedAt:    created,
+		UpdatedAt:    updated,
+	}
+
+	for _, tc := range []struct {
+		name      string
+		responses map[string]string
+		op        GetMergeRequestOp
+		want      *MergeRequest
+		err       string
+	}{
+		{
+			name: "invalid args",
+			op:   GetMergeRequestOp{ProjID: 1, ProjPathWithNamespace: "n1/n2/r", IID: 3},
+			err:  "invalid args (specify exactly one of id and projPathWithNamespace",
+		},
+		{
+			name: "not found",
+			op:   GetMergeRequestOp{ProjID: 1, IID: 4},
+			err:  "unexpected response from GitLab API (https://example.com/projects/1/merge_requests/4): HTTP error status 404",
+		},
+		{
+			name: "approvals not supported",
+			responses: map[string]string{
+				"/projects/1/merge_requests/3": mr,
+			},
+			op:   GetMergeRequestOp{ProjID: 1, IID: 3},
+			want: want,
+		},
+		{
+			name: "approved",
+			responses: map[string]string{
+				"/projects/n1%2Fn2%2Fr/merge_requests/3":           mr,
+				"/projects/n1%2Fn2%2Fr/merge_requests/3/approvals": `{"approved": true, "approvals_required": 1, "approvals_left": 0, "approved_by": [{"user": {"id": 2, "username": "bob"}}]}`,
+			},
+			op: GetMergeRequestOp{ProjPathWithNamespace: "n1/n2/r", IID: 3},
+			want: func() *MergeRequest {
+				mr := *want
+				mr.Approvals = &MergeRequestApprovals{
+					Approved:          true,
+					ApprovalsRequired: 1,
+					ApprovedBy:        []*Approver{{User: &User{ID: 2, Username: "bob"}}},
+				}
+				return &mr
+			}(),
+		},
+		{
+			name: "head pipeline",
+			responses: map[string]string{
+				"/projects/1/merge_requests/3": strings.Replace(mr, `"author"`, `"head_pipeline": {"id": 7, "sha": "deadbeef", "ref": "fix-bugs", "status": "failed", "web_url": "https://example.com/n1/n2/r/pipelines/7"}, "author"`, 1),
+				"/projects/1/pipelines/7/jobs": `[{"id": 9, "name": "test", "stage": "test", "status": "failed", "web_url": "https://example.com/n1/n2/r/-/jobs/9", "allow_failure": false}]`,
+			},
+			op: GetMergeRequestOp{ProjID: 1, IID: 3},
+			want: func() *MergeRequest {
+				mr := *want
+				mr.HeadPipeline = &Pipeline{
+					ID:     7,
+					SHA:    "deadbeef",
+					Ref:    "fix-bugs",
+					Status: PipelineStatusFailed,
+					WebURL: "https://example.com/n1/n2/r/pipelines/7",
+					Jobs: []*Job{{
+						ID:     9,
+						Name:   "test",
+						Stage:  "test",
+						Status: PipelineStatusFailed,
+						WebURL: "https://example.com/n1/n2/r/-/jobs/9",
+					}},
+				}
+				return &mr
+			}(),
+		},
+		{
+			name: "head pipeline jobs error",
+			responses: map[string]string{
+				"/projects/1/merge_requests/3": strings.Replace(mr, `"author"`, `"head_pipeline": {"id": 7, "sha": "deadbeef", "ref": "fix-bugs", "status": "failed", "web_url": "https://example.com/n1/n2/r/pipelines/7"}, "author"`, 1),
+				"/projects/1/pipelines/7/jobs": `not json`,
+			},
+			op: GetMergeRequestOp{ProjID: 1, IID: 3},
+			want: func() *MergeRequest {
+				mr := *want
+				mr.HeadPipeline = &Pipeline{
+					ID:     7,
+					SHA:    "deadbeef",
+					Ref:    "fix-bugs",
+					Status: PipelineStatusFailed,
+					WebURL: "https://example.com/n1/n2/r/pipelines/7",
+				}
+				return &mr
+			}(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestClient(t)
+			c.httpClient = &mockHTTPResponsePaths{responses: tc.responses}
+
+			mr, err := c.GetMergeRequest(context.Background(), tc.op)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("error:\nhave: %v\nwant: %q", err, tc.err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(mr, tc.want) {
+				t.Error(cmp.Diff(mr, tc.want))
+			}
+		})
+	}
+}
+
+func TestClient_FindOpenMergeRequest(t *testing.T) {
+	op := FindOpenMergeRequestOp{ProjID: 1, SourceBranch: "campaign", TargetBranch: "master"}
+
+	c := newTestClient(t)
+	c.httpClient = &mockHTTPResponsePaths{responses: map[string]string{
+		"/projects/1/merge_requests":   `[{"iid": 3, "project_id": 1, "state": "opened"}]`,
+		"/projects/1/merge_requests/3": `{"id": 84, "iid": 3, "project_id": 1, "state": "opened", "title": "Campaign"}`,
+	}}
+	mr, err := c.FindOpenMergeRequest(context.Background(), op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mr.ID != 84 || mr.IID != 3 || mr.Title != "Campaign" {
+		t.Errorf("got merge request %+v, want the loaded merge request 3", mr)
+	}
+
+	c.httpClient = &mockHTTPResponsePaths{responses: map[string]string{
+		"/projects/1/merge_requests": `[]`,
+	}}
+	if _, err := c.FindOpenMergeRequest(context.Background(), op); err != ErrMergeRequestNotFound {
+		t.Errorf("got error %v, want %v", err, ErrMergeRequestNotFound)
+	}
+}
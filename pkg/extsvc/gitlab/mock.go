@@ -8,6 +8,9 @@ var MockListProjects func(c *Client, ctx context.Context, urlStr string) (proj [
 // MockListUsers, if non-nil, will be called instead of Client.ListUsers
 var MockListUsers func(c *Client, ctx context.Context, urlStr string) (users []*User, nextPageURL *string, err error)
 
+// MockListProjectMembers, if non-nil, will be called instead of Client.ListProjectMembers
+var MockListProjectMembers func(c *Client, ctx context.Context, urlStr string) (members []*Member, nextPageURL *string, err error)
+
 // MockGetUser, if non-nil, will be called instead of Client.GetUser
 var MockGetUser func(c *Client, ctx context.Context, id string) (*User, error)
 
@@ -16,3 +19,21 @@ var MockGetProject func(c *Client, ctx context.Context, op GetProjectOp) (*Proje
 
 // MockListTree, if non-nil, will be called instead of Client.ListTree
 var MockListTree func(c *Client, ctx context.Context, op ListTreeOp) ([]*Tree, error)
+
+// MockGetMergeRequest, if non-nil, will be called instead of Client.GetMergeRequest
+var MockGetMergeRequest func(c *Client, ctx context.Context, op GetMergeRequestOp) (*MergeRequest, error)
+
+// MockCreateMergeRequest, if non-nil, will be called instead of Client.CreateMergeRequest
+var MockCreateMergeRequest func(c *Client, ctx context.Context, op CreateMergeRequestOp) (*MergeRequest, error)
+
+// MockFindOpenMergeRequest, if non-nil, will be called instead of Client.FindOpenMergeRequest
+var MockFindOpenMergeRequest func(c *Client, ctx context.Context, op FindOpenMergeRequestOp) (*MergeRequest, error)
+
+// MockCreateMergeRequestNote, if non-nil, will be called instead of Client.CreateMergeRequestNote
+var MockCreateMergeRequestNote func(c *Client, ctx context.Context, op CreateMergeRequestNoteOp) error
+
+// MockUpdateMergeRequest, if non-nil, will be called instead of Client.UpdateMergeRequest
+var MockUpdateMergeRequest func(c *Client, ctx context.Context, op UpdateMergeRequestOp) (*MergeRequest, error)
+
+// MockAcceptMergeRequest, if non-nil, will be called instead of Client.AcceptMergeRequest
+var MockAcceptMergeRequest func(c *Client, ctx context.Context, op AcceptMergeRequestOp) (*MergeRequest, error)
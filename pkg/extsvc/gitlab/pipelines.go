@@ -0,0 +1,74 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// PipelineStatus is the status of a GitLab pipeline or of one of its jobs.
+type PipelineStatus string
+
+// PipelineStatus constants.
+const (
+	PipelineStatusCreated            PipelineStatus = "created"
+	PipelineStatusWaitingForResource PipelineStatus = "waiting_for_resource"
+	PipelineStatusPreparing          PipelineStatus = "preparing"
+	PipelineStatusPending            PipelineStatus = "pending"
+	PipelineStatusRunning            PipelineStatus = "running"
+	PipelineStatusSuccess            PipelineStatus = "success"
+	PipelineStatusFailed             PipelineStatus = "failed"
+	PipelineStatusCanceled           PipelineStatus = "canceled"
+	PipelineStatusSkipped            PipelineStatus = "skipped"
+	PipelineStatusManual             PipelineStatus = "manual"
+	PipelineStatusScheduled          PipelineStatus = "scheduled"
+)
+
+// Pipeline is a GitLab CI pipeline run for a commit.
+type Pipeline struct {
+	ID     int            `json:"id"`
+	SHA    string         `json:"sha"`
+	Ref    string         `json:"ref"`
+	Status PipelineStatus `json:"status"`
+	WebURL string         `json:"web_url"`
+
+	// Jobs are the jobs of the pipeline. It is nil if they couldn't be
+	// loaded, e.g. because the user can't access them or the request
+	// failed.
+	Jobs []*Job `json:"jobs,omitempty"`
+}
+
+// Job is a single job (e.g. a build or a test run) of a GitLab pipeline.
+type Job struct {
+	ID           int            `json:"id"`
+	Name         string         `json:"name"`
+	Stage        string         `json:"stage"`
+	Status       PipelineStatus `json:"status"`
+	WebURL       string         `json:"web_url"`
+	AllowFailure bool           `json:"allow_failure"`
+}
+
+// listPipelineJobs lists the jobs of the pipeline with the given ID in the
+// project with the given specifier (ID or escaped path). It returns nil and
+// no error if the jobs aren't accessible.
+func (c *Client) listPipelineJobs(ctx context.Context, projSpecifier string, pipelineID int) ([]*Job, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("projects/%s/pipelines/%d/jobs?per_page=100", projSpecifier, pipelineID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*Job
+	switch _, err := c.do(ctx, req, &jobs); HTTPErrorCode(err) {
+	case 0:
+		if err != nil {
+			return nil, err
+		}
+		return jobs, nil
+	case http.StatusNotFound, http.StatusForbidden:
+		// Jobs are hidden from users without access to the pipelines of
+		// the project.
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
@@ -19,6 +19,20 @@ type User struct {
 	Identities []Identity `json:"identities"`
 }
 
+// Member is a user who is a member of a GitLab project or group.
+type Member struct {
+	User
+	AccessLevel AccessLevel `json:"access_level"`
+}
+
+// AccessLevel is the access level of a member of a GitLab project or group. See
+// https://docs.gitlab.com/ee/api/members.html.
+type AccessLevel int
+
+// AccessLevelReporter is the lowest access level that can read the repository of a private project
+// (guests can't).
+const AccessLevelReporter AccessLevel = 20
+
 type Identity struct {
 	Provider  string `json:"provider"`
 	ExternUID string `json:"extern_uid"`
@@ -68,3 +82,27 @@ func (c *Client) GetUser(ctx context.Context, id string) (*User, error) {
 	}
 	return &usr, nil
 }
+
+// ListProjectMembers lists the members of a GitLab project, including those who are members
+// through the project's ancestor groups.
+func (c *Client) ListProjectMembers(ctx context.Context, urlStr string) (members []*Member, nextPageURL *string, err error) {
+	if MockListProjectMembers != nil {
+		return MockListProjectMembers(c, ctx, urlStr)
+	}
+
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	respHeader, err := c.do(ctx, req, &members)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get URL to next page. See https://docs.gitlab.com/ee/api/README.html#pagination-link-header.
+	if l := link.Parse(respHeader.Get("Link"))["next"]; l != nil {
+		nextPageURL = &l.URI
+	}
+
+	return members, nextPageURL, nil
+}
@@ -165,6 +165,11 @@ type CreateCommitFromPatchRequest struct {
 	TargetRef string
 	// CommitInfo is the information that will be used when creating the commit from a patch
 	CommitInfo PatchCommitInfo
+	// Push, when true, pushes the created commit to TargetRef on the
+	// repository's remote origin after the ref has been created locally. The
+	// push is never forced, so it fails if TargetRef exists on the remote
+	// and the commit doesn't descend from it.
+	Push bool
 }
 
 // PatchCommitInfo will be used for commit information when creating a commit from a patch
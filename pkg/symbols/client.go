@@ -101,6 +101,37 @@ func (c *Client) Search(ctx context.Context, args protocol.SearchArgs) (result *
 	return result, err
 }
 
+// Definition finds the candidate definitions of the identifier at a position
+// in a file on the symbols service.
+func (c *Client) Definition(ctx context.Context, args protocol.DefinitionArgs) (result *protocol.DefinitionResult, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "symbols.Client.Definition")
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+	span.SetTag("Repo", string(args.Repo))
+	span.SetTag("CommitID", string(args.CommitID))
+	span.SetTag("Path", args.Path)
+
+	resp, err := c.httpPost(ctx, "definition", key{repo: args.Repo, commitID: args.CommitID}, args)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// best-effort inclusion of body in error message
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, errors.Errorf("Symbol.Definition http status %d for %+v: %s", resp.StatusCode, args, string(body))
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
+
 func (c *Client) httpPost(ctx context.Context, method string, key key, payload interface{}) (resp *http.Response, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "symbols.Client.httpPost")
 	defer func() {
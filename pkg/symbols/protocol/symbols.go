@@ -42,6 +42,36 @@ type SearchResult struct {
 	Symbols []Symbol // code symbols
 }
 
+// DefinitionArgs are the arguments to find the definitions of the identifier
+// at a position in a file on the symbols service.
+type DefinitionArgs struct {
+	// Repo is the name of the repository the file is in.
+	Repo api.RepoName `json:"repo"`
+
+	// CommitID is the commit the file is in.
+	CommitID api.CommitID `json:"commitID"`
+
+	// Path is the path of the file.
+	Path string
+
+	// Line and Character are the zero-based position in the file.
+	Line, Character int
+
+	// First indicates that only the first n definitions should be returned.
+	First int
+}
+
+// DefinitionResult is the result of finding definitions on the symbols
+// service.
+type DefinitionResult struct {
+	// Identifier is the identifier at the position, or empty if there's none.
+	Identifier string
+
+	// Symbols are the candidate definitions of the identifier, most likely
+	// first.
+	Symbols []Symbol
+}
+
 // Symbol is a code symbol.
 type Symbol struct {
 	Name       string
@@ -54,5 +84,18 @@ type Symbol struct {
 	Signature  string
 	Pattern    string
 
+	// Scope is the fully qualified name of the symbol's container, e.g. its
+	// package and parent type. It's empty if the extractor doesn't know it.
+	Scope string
+
+	// Visibility is the symbol's access modifier as the language names it,
+	// e.g. "public", "private" or "protected". It's empty if the language has
+	// none or the extractor doesn't know it.
+	Visibility string
+
+	// Exported is whether the symbol is accessible outside of its package,
+	// module or class, as far as the extractor can tell.
+	Exported bool
+
 	FileLimited bool
 }
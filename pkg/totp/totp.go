@@ -0,0 +1,121 @@
+// Package totp implements time-based one-time passwords (TOTP) as specified in RFC 6238, with the
+// parameters that authenticator apps (such as Google Authenticator) use by default: HMAC-SHA1, 30
+// second time steps and 6 digit codes.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// period is the duration of a time step.
+	period = 30 * time.Second
+
+	// digits is the number of digits in a code, and modulus is 10^digits.
+	digits  = 6
+	modulus = 1000000
+
+	// skew is the number of time steps before and after the current time step whose codes are
+	// also accepted, to allow for clock drift and for the time it takes the user to enter the code.
+	skew = 1
+
+	// secretLen is the length (in bytes) of generated secrets, as recommended by RFC 4226.
+	secretLen = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random secret, encoded in unpadded base32 (which is how
+// authenticator apps expect secrets to be entered).
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Counter returns the time step counter for t.
+func Counter(t time.Time) int64 {
+	return t.Unix() / int64(period/time.Second)
+}
+
+// Code returns the code for the secret at the time step counter.
+func Code(secret string, counter int64) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, counter), nil
+}
+
+// Validate reports whether code is a valid code for the secret at time t. If it is valid, it also
+// returns the time step counter that the code was generated for. Callers should reject codes whose
+// counter is not greater than the counter of the last code that was accepted, so that a code can't
+// be used more than once.
+func Validate(secret, code string, t time.Time) (counter int64, ok bool, err error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, false, err
+	}
+	code = strings.Replace(code, " ", "", -1)
+	if len(code) != digits {
+		return 0, false, nil
+	}
+	now := Counter(t)
+	for c := now - skew; c <= now+skew; c++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, c)), []byte(code)) == 1 {
+			return c, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// URL returns the otpauth:// URL for the secret, which authenticator apps read (usually from a QR
+// code) to add the account. The issuer and account name are shown to the user in the app.
+func URL(issuer, accountName, secret string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(digits))
+	q.Set("period", fmt.Sprint(int(period/time.Second)))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + accountName,
+		RawQuery: q.Encode(),
+	}
+	return u.String()
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.TrimRight(strings.Replace(secret, " ", "", -1), "="))
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP secret: %s", err)
+	}
+	return key, nil
+}
+
+// hotp computes the HOTP value (RFC 4226) for the key and counter.
+func hotp(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0xf
+	v := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", digits, v%modulus)
+}
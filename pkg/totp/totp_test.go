@@ -0,0 +1,99 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 secret used in the test vectors of RFC 6238 Appendix B.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// The RFC's test vectors are 8 digit codes; 6 digit codes are their last 6 digits.
+	tests := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range tests {
+		got, err := Code(rfcSecret, Counter(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%d: got %q, want %q", unix, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	tests := map[string]struct {
+		code        string
+		wantCounter int64
+		wantOK      bool
+	}{
+		"current":             {code: "005924", wantCounter: Counter(now), wantOK: true},
+		"with spaces":         {code: "005 924", wantCounter: Counter(now), wantOK: true},
+		"previous time step":  {code: mustCode(t, Counter(now)-1), wantCounter: Counter(now) - 1, wantOK: true},
+		"next time step":      {code: mustCode(t, Counter(now)+1), wantCounter: Counter(now) + 1, wantOK: true},
+		"too old":             {code: mustCode(t, Counter(now)-2)},
+		"wrong":               {code: "123456"},
+		"wrong length":        {code: "05924"},
+		"empty":               {code: ""},
+		"8 digit RFC vectors": {code: "89005924"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			counter, ok, err := Validate(rfcSecret, test.code, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != test.wantOK || counter != test.wantCounter {
+				t.Errorf("got (%d, %v), want (%d, %v)", counter, ok, test.wantCounter, test.wantOK)
+			}
+		})
+	}
+
+	if _, _, err := Validate("not base32!", "123456", now); err == nil {
+		t.Error("invalid secret: got nil error")
+	}
+}
+
+func mustCode(t *testing.T, counter int64) string {
+	code, err := Code(rfcSecret, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(secret, "=") {
+		t.Errorf("secret %q is padded", secret)
+	}
+	code, err := Code(secret, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := Validate(strings.ToLower(secret), code, time.Unix(30, 0)); !ok {
+		t.Error("code for generated secret is not valid")
+	}
+}
+
+func TestURL(t *testing.T) {
+	got := URL("Sourcegraph", "alice", "ABC")
+	want := "otpauth://totp/Sourcegraph:alice?algorithm=SHA1&digits=6&issuer=Sourcegraph&period=30&secret=ABC"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+)
+
+// PathChanges are the paths of the files that differ between two commits.
+type PathChanges struct {
+	Added    []string
+	Modified []string
+	Deleted  []string
+}
+
+// DiffPaths returns the paths of the files that changed from commit a to
+// commit b. Renames are reported as a deletion and an addition.
+func DiffPaths(ctx context.Context, repo gitserver.Repo, a, b api.CommitID) (*PathChanges, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Git: DiffPaths")
+	span.SetTag("A", a)
+	span.SetTag("B", b)
+	defer span.Finish()
+
+	if err := checkSpecArgSafety(string(a)); err != nil {
+		return nil, err
+	}
+	if err := checkSpecArgSafety(string(b)); err != nil {
+		return nil, err
+	}
+
+	cmd := gitserver.DefaultClient.Command("git", "diff", "-z", "--name-status", "--no-renames", string(a), string(b), "--")
+	cmd.Repo = repo
+	out, err := cmd.CombinedOutput(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("git command %v failed (output: %q)", cmd.Args, out))
+	}
+	return parsePathChanges(out)
+}
+
+// parsePathChanges parses the output of git diff -z --name-status, which is
+// a NUL-separated list of alternating statuses and paths.
+func parsePathChanges(out []byte) (*PathChanges, error) {
+	fields := bytes.Split(bytes.TrimSuffix(out, []byte{0}), []byte{0})
+	if len(fields) == 1 && len(fields[0]) == 0 {
+		return &PathChanges{}, nil
+	}
+	if len(fields)%2 != 0 {
+		return nil, errors.Errorf("unexpected git diff output %q", out)
+	}
+
+	changes := &PathChanges{}
+	for i := 0; i < len(fields); i += 2 {
+		status, path := fields[i], string(fields[i+1])
+		if len(status) == 0 {
+			return nil, errors.Errorf("unexpected git diff output %q", out)
+		}
+		switch status[0] {
+		case 'A':
+			changes.Added = append(changes.Added, path)
+		case 'D':
+			changes.Deleted = append(changes.Deleted, path)
+		default:
+			// M (modified) and T (type changed). There are no renames or
+			// copies because of --no-renames.
+			changes.Modified = append(changes.Modified, path)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,31 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePathChanges(t *testing.T) {
+	tests := map[string]*PathChanges{
+		"": {},
+		"M\x00a.go\x00A\x00b/c.go\x00D\x00d.go\x00T\x00e\x00": {
+			Added:    []string{"b/c.go"},
+			Modified: []string{"a.go", "e"},
+			Deleted:  []string{"d.go"},
+		},
+	}
+	for out, want := range tests {
+		got, err := parsePathChanges([]byte(out))
+		if err != nil {
+			t.Errorf("%q: %s", out, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %+v, want %+v", out, got, want)
+		}
+	}
+
+	if _, err := parsePathChanges([]byte("M\x00a.go\x00A\x00")); err == nil {
+		t.Error("got no error for truncated output")
+	}
+}
@@ -49,6 +49,19 @@ const BitbucketCloudSchemaJSON = `{
       "type": "array",
       "items": { "type": "string", "pattern": "^\\w+$" },
       "examples": [["name"], ["kubernetes", "golang", "facebook"]]
+    },
+    "authorization": {
+      "title": "BitbucketCloudAuthorization",
+      "description": "If non-null, enforces Bitbucket Cloud repository permissions. This requires that there be an item in the ` + "`" + `auth.providers` + "`" + ` field of type \"bitbucketCloud\" with the same ` + "`" + `url` + "`" + ` field as specified in this ` + "`" + `BitbucketCloudConnection` + "`" + `. A user's permissions are computed with the OAuth token of the Bitbucket Cloud external account created when they sign in with Bitbucket Cloud.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "ttl": {
+          "description": "The TTL of how long to cache permissions data. This is 3 hours by default.\n\nDecreasing the TTL will increase the load on the code host API. If you have X repositories on your instance, it will take ~X/100 API requests to fetch the complete list for 1 user.  If you have Y users, you will incur X*Y/100 API requests per cache refresh period.\n\nIf set to zero, Sourcegraph will sync a user's entire accessible repository list on every request (NOT recommended).",
+          "type": "string",
+          "default": "3h"
+        }
+      }
     }
   }
 }
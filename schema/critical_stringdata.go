@@ -99,7 +99,7 @@ const CriticalSchemaJSON = `{
         "properties": {
           "type": {
             "type": "string",
-            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab"]
+            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "bitbucketCloud", "ldap"]
           }
         },
         "oneOf": [
@@ -108,7 +108,9 @@ const CriticalSchemaJSON = `{
           { "$ref": "#/definitions/OpenIDConnectAuthProvider" },
           { "$ref": "#/definitions/HTTPHeaderAuthProvider" },
           { "$ref": "#/definitions/GitHubAuthProvider" },
-          { "$ref": "#/definitions/GitLabAuthProvider" }
+          { "$ref": "#/definitions/GitLabAuthProvider" },
+          { "$ref": "#/definitions/BitbucketCloudAuthProvider" },
+          { "$ref": "#/definitions/LDAPAuthProvider" }
         ],
         "!go": {
           "taggedUnionType": true
@@ -164,6 +166,12 @@ const CriticalSchemaJSON = `{
           "description": "Allows new visitors to sign up for accounts. The sign-up page will be enabled and accessible to all visitors.\n\nSECURITY: If the site has no users (i.e., during initial setup), it will always allow the first user to sign up and become site admin **without any approval** (first user to sign up becomes the admin).",
           "type": "boolean",
           "default": false
+        },
+        "requireMFA": {
+          "description": "Requires users who sign in with a username and password to also enter a code from an authenticator app (multi-factor authentication with time-based one-time passwords). Users who have not enrolled in multi-factor authentication are asked to enroll when they sign in.\n\n- \"none\": multi-factor authentication is optional.\n- \"siteAdmins\": site admins must use multi-factor authentication.\n- \"all\": all users must use multi-factor authentication.\n\nUsers can always enroll in multi-factor authentication voluntarily in their account settings.",
+          "type": "string",
+          "enum": ["none", "siteAdmins", "all"],
+          "default": "none"
         }
       }
     },
@@ -202,6 +210,16 @@ const CriticalSchemaJSON = `{
           "description": "Only allow users to authenticate if their email domain is equal to this value (example: mycompany.com). Do not include a leading \"@\". If not set, all users on this OpenID Connect provider can authenticate to Sourcegraph.",
           "type": "string",
           "pattern": "^[^<@]"
+        },
+        "groupsClaim": {
+          "description": "The claim of the ID token or UserInfo response that lists the names of the groups the user belongs to, for groupMappings.",
+          "type": "string",
+          "default": "groups"
+        },
+        "groupMappings": {
+          "description": "Maps OpenID Connect groups (by name, as listed in groupsClaim) to Sourcegraph organization memberships and site admin status, which are synced each time a user signs in.",
+          "type": "array",
+          "items": { "$ref": "#/definitions/AuthGroupMapping" }
         }
       }
     },
@@ -277,6 +295,17 @@ const CriticalSchemaJSON = `{
           "description": "Whether the Service Provider should (insecurely) accept assertions from the Identity Provider without a valid signature.",
           "type": "boolean",
           "default": false
+        },
+        "groupsAttribute": {
+          "description": "The name (or friendly name) of the assertion attribute that lists the names of the groups the user belongs to, for groupMappings.",
+          "type": "string",
+          "default": "groups",
+          "examples": ["http://schemas.microsoft.com/ws/2008/06/identity/claims/groups", "memberOf"]
+        },
+        "groupMappings": {
+          "description": "Maps SAML groups (by name, as listed in groupsAttribute) to Sourcegraph organization memberships and site admin status, which are synced each time a user signs in.",
+          "type": "array",
+          "items": { "$ref": "#/definitions/AuthGroupMapping" }
         }
       }
     },
@@ -302,6 +331,111 @@ const CriticalSchemaJSON = `{
         }
       }
     },
+    "LDAPAuthProvider": {
+      "description": "Configures the LDAP authentication provider, which signs in users with the username and password of their account in an LDAP directory (such as Active Directory or OpenLDAP).\n\nTo sign in a user, Sourcegraph searches for the user's entry with the user filter (binding with bindDN and bindPassword, if set), then binds as that entry with the user's password.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type", "url", "userBaseDN"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "ldap"
+        },
+        "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" },
+        "url": {
+          "description": "The URL of the LDAP server. Use the ldaps scheme for LDAP over TLS, or the ldap scheme with startTLS.",
+          "type": "string",
+          "pattern": "^ldaps?://",
+          "examples": ["ldaps://ldap.example.com", "ldap://ldap.example.com:389"]
+        },
+        "startTLS": {
+          "description": "Upgrades the connection to the LDAP server to TLS with the StartTLS operation before binding. Only valid with the ldap scheme.",
+          "type": "boolean",
+          "default": false
+        },
+        "certificate": {
+          "description": "The TLS certificate of the CA that signed the LDAP server's certificate, used when connecting with the ldaps scheme or startTLS. This is only necessary if the certificate is self-signed or signed by an internal CA. To get the certificate run ` + "`" + `openssl s_client -connect HOST:636 -showcerts < /dev/null 2> /dev/null | openssl x509 -outform PEM` + "`" + `",
+          "type": "string",
+          "pattern": "^-----BEGIN CERTIFICATE-----\n",
+          "examples": ["-----BEGIN CERTIFICATE-----\n..."]
+        },
+        "bindDN": {
+          "description": "The DN to bind as when searching for users. If not set, the search is performed anonymously.",
+          "type": "string",
+          "examples": ["cn=sourcegraph,ou=services,dc=example,dc=com"]
+        },
+        "bindPassword": {
+          "description": "The password of bindDN.",
+          "type": "string"
+        },
+        "userBaseDN": {
+          "description": "The DN under which to search for users.",
+          "type": "string",
+          "examples": ["ou=people,dc=example,dc=com"]
+        },
+        "userFilter": {
+          "description": "The LDAP filter that matches the entry of the user signing in. \"{username}\" is replaced with the (escaped) username they entered. Extensible match filters are not supported.",
+          "type": "string",
+          "default": "(uid={username})",
+          "examples": ["(&(objectClass=user)(sAMAccountName={username}))"]
+        },
+        "usernameAttribute": {
+          "description": "The attribute of the user's entry that holds their username.",
+          "type": "string",
+          "default": "uid",
+          "examples": ["sAMAccountName"]
+        },
+        "emailAttribute": {
+          "description": "The attribute of the user's entry that holds their email address.",
+          "type": "string",
+          "default": "mail"
+        },
+        "trustEmailAddresses": {
+          "description": "Whether the email addresses in the LDAP directory are considered verified. Only enable this if users can't change their own email address in the directory. If false, users must verify their email address on Sourcegraph.",
+          "type": "boolean",
+          "default": false
+        },
+        "displayNameAttribute": {
+          "description": "The attribute of the user's entry that holds their display name.",
+          "type": "string",
+          "default": "cn",
+          "examples": ["displayName"]
+        },
+        "groupsAttribute": {
+          "description": "The attribute of the user's entry that lists the DNs of the groups they belong to, for groupMappings.",
+          "type": "string",
+          "default": "memberOf"
+        },
+        "groupMappings": {
+          "description": "Maps LDAP groups (by DN) to Sourcegraph organization memberships and site admin status, which are synced each time a user signs in.",
+          "type": "array",
+          "items": { "$ref": "#/definitions/AuthGroupMapping" }
+        }
+      }
+    },
+    "AuthGroupMapping": {
+      "description": "Maps a group of an authentication provider's users to Sourcegraph organizations and site admin status.\n\nEach time a user signs in, they are added to the organizations of the mappings whose group they belong to, and removed from the organizations of the other mappings. Organizations that don't exist are created. If any mapping sets siteAdmin, the user is a site admin if and only if they belong to the group of such a mapping.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["group"],
+      "properties": {
+        "group": {
+          "description": "The name of the group.",
+          "type": "string",
+          "minLength": 1,
+          "examples": ["cn=engineering,ou=groups,dc=example,dc=com"]
+        },
+        "orgs": {
+          "description": "The names of the organizations that members of the group belong to.",
+          "type": "array",
+          "items": { "type": "string" }
+        },
+        "siteAdmin": {
+          "description": "Whether members of the group are site admins.",
+          "type": "boolean"
+        }
+      }
+    },
     "GitHubAuthProvider": {
       "description": "Configures the GitHub (or GitHub Enterprise) OAuth authentication provider for SSO. In addition to specifying this configuration object, you must also create a OAuth App on your GitHub instance: https://developer.github.com/apps/building-oauth-apps/creating-an-oauth-app/. When a user signs into Sourcegraph or links their GitHub account to their existing Sourcegraph account, GitHub will prompt the user for the repo scope.",
       "type": "object",
@@ -359,6 +493,37 @@ const CriticalSchemaJSON = `{
         "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" }
       }
     },
+    "BitbucketCloudAuthProvider": {
+      "description": "Configures the Bitbucket Cloud OAuth authentication provider for SSO. In addition to specifying this configuration object, you must also create an OAuth consumer in the settings of your Bitbucket Cloud workspace: https://support.atlassian.com/bitbucket-cloud/docs/use-oauth-on-bitbucket-cloud/. The consumer should have the Account (Email and Read) and Repositories (Read) permissions and the callback URL set to the concatenation of your Sourcegraph instance URL and \"/.auth/bitbucketcloud/callback\".",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type", "clientID", "clientSecret"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "bitbucketCloud"
+        },
+        "url": {
+          "type": "string",
+          "description": "URL of Bitbucket Cloud.",
+          "default": "https://bitbucket.org/"
+        },
+        "clientID": {
+          "type": "string",
+          "description": "The Key of the Bitbucket Cloud OAuth consumer."
+        },
+        "clientSecret": {
+          "type": "string",
+          "description": "The Secret of the Bitbucket Cloud OAuth consumer."
+        },
+        "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" },
+        "allowSignup": {
+          "description": "Allows new visitors to sign up for accounts via Bitbucket Cloud authentication. If false, users signing in via Bitbucket Cloud must have an existing Sourcegraph account, which will be linked to their Bitbucket Cloud identity after sign-in.",
+          "default": false,
+          "type": "boolean"
+        }
+      }
+    },
     "AuthProviderCommon": {
       "$comment": "This schema is not used directly. The *AuthProvider schemas refer to its properties directly.",
       "description": "Common properties for authentication providers.",
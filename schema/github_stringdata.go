@@ -41,6 +41,11 @@ const GitHubSchemaJSON = `{
       "pattern": "^-----BEGIN CERTIFICATE-----\n",
       "examples": ["-----BEGIN CERTIFICATE-----\n..."]
     },
+    "webhookSecret": {
+      "description": "The secret used to verify the webhook events GitHub sends to Sourcegraph, which keep the campaign changesets on this code host up to date without polling. Configure it on the repository or organization webhooks that send \"pull_request\", \"pull_request_review\", \"check_run\", \"check_suite\" and \"status\" events to https://SOURCEGRAPH_URL/.api/webhooks/github (with content type application/json).",
+      "type": "string",
+      "minLength": 1
+    },
     "repos": {
       "description": "An array of repository \"owner/name\" strings specifying which GitHub or GitHub Enterprise repositories to mirror on Sourcegraph.",
       "type": "array",
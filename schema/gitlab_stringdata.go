@@ -41,6 +41,11 @@ const GitLabSchemaJSON = `{
       "pattern": "^-----BEGIN CERTIFICATE-----\n",
       "examples": ["-----BEGIN CERTIFICATE-----\n..."]
     },
+    "webhookSecret": {
+      "description": "The secret used to verify the webhook events GitLab sends to Sourcegraph, which keep the campaign changesets on this code host up to date without polling. Configure it as the secret token of the project or group webhooks that send merge request, pipeline and comment events to https://SOURCEGRAPH_URL/.api/webhooks/gitlab.",
+      "type": "string",
+      "minLength": 1
+    },
     "projects": {
       "description": "A list of projects to mirror from this GitLab instance. Supports including by name ({\"name\": \"group/name\"}) or by ID ({\"id\": 42}).",
       "type": "array",
@@ -75,6 +75,67 @@ const GitoliteSchemaJSON = `{
           "type": "string"
         }
       }
+    },
+    "authorization": {
+      "title": "GitoliteAuthorization",
+      "description": "If non-null, enforces Gitolite repository permissions as defined by the access rules in the Gitolite admin repository (conf/gitolite.conf and the files it includes). The admin repository must be mirrored on Sourcegraph (i.e. it must not be excluded), because its access rules are read through gitserver.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["identityProvider"],
+      "properties": {
+        "identityProvider": {
+          "description": "The source of identity to use when computing permissions. This defines how to compute the Gitolite username to use for a given Sourcegraph user. When 'username' is used, Sourcegraph assumes usernames are identical in Sourcegraph and Gitolite and ` + "`" + `auth.enableUsernameChanges` + "`" + ` must be set to false for security reasons. When 'email' is used, the Gitolite username is assumed to be one of the verified email addresses of the Sourcegraph user.",
+          "title": "GitoliteIdentityProvider",
+          "type": "object",
+          "required": ["type"],
+          "properties": {
+            "type": {
+              "type": "string",
+              "enum": ["username", "email"]
+            }
+          },
+          "oneOf": [{ "$ref": "#/definitions/UsernameIdentity" }, { "$ref": "#/definitions/EmailIdentity" }],
+          "!go": {
+            "taggedUnionType": true
+          }
+        },
+        "adminRepository": {
+          "description": "The name of the Gitolite admin repository on the Gitolite host.",
+          "type": "string",
+          "default": "gitolite-admin"
+        },
+        "ttl": {
+          "description": "The TTL of how long to cache the access rules read from the admin repository. This is 3 hours by default.",
+          "type": "string",
+          "default": "3h"
+        }
+      }
+    }
+  },
+  "definitions": {
+    "UsernameIdentity": {
+      "title": "GitoliteUsernameIdentity",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "username"
+        }
+      }
+    },
+    "EmailIdentity": {
+      "title": "GitoliteEmailIdentity",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "email"
+        }
+      }
     }
   }
 }
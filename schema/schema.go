@@ -33,17 +33,28 @@ type AuthAccessTokens struct {
 	Allow string `json:"allow,omitempty"`
 }
 
+// AuthGroupMapping description: Maps a group of an authentication provider's users to Sourcegraph organizations and site admin status.
+//
+// Each time a user signs in, they are added to the organizations of the mappings whose group they belong to, and removed from the organizations of the other mappings. Organizations that don't exist are created. If any mapping sets siteAdmin, the user is a site admin if and only if they belong to the group of such a mapping.
+type AuthGroupMapping struct {
+	Group     string   `json:"group"`
+	Orgs      []string `json:"orgs,omitempty"`
+	SiteAdmin bool     `json:"siteAdmin,omitempty"`
+}
+
 // AuthProviderCommon description: Common properties for authentication providers.
 type AuthProviderCommon struct {
 	DisplayName string `json:"displayName,omitempty"`
 }
 type AuthProviders struct {
-	Builtin       *BuiltinAuthProvider
-	Saml          *SAMLAuthProvider
-	Openidconnect *OpenIDConnectAuthProvider
-	HttpHeader    *HTTPHeaderAuthProvider
-	Github        *GitHubAuthProvider
-	Gitlab        *GitLabAuthProvider
+	Builtin        *BuiltinAuthProvider
+	Saml           *SAMLAuthProvider
+	Openidconnect  *OpenIDConnectAuthProvider
+	HttpHeader     *HTTPHeaderAuthProvider
+	Github         *GitHubAuthProvider
+	Gitlab         *GitLabAuthProvider
+	BitbucketCloud *BitbucketCloudAuthProvider
+	Ldap           *LDAPAuthProvider
 }
 
 func (v AuthProviders) MarshalJSON() ([]byte, error) {
@@ -65,6 +76,12 @@ func (v AuthProviders) MarshalJSON() ([]byte, error) {
 	if v.Gitlab != nil {
 		return json.Marshal(v.Gitlab)
 	}
+	if v.BitbucketCloud != nil {
+		return json.Marshal(v.BitbucketCloud)
+	}
+	if v.Ldap != nil {
+		return json.Marshal(v.Ldap)
+	}
 	return nil, errors.New("tagged union type must have exactly 1 non-nil field value")
 }
 func (v *AuthProviders) UnmarshalJSON(data []byte) error {
@@ -75,6 +92,8 @@ func (v *AuthProviders) UnmarshalJSON(data []byte) error {
 		return err
 	}
 	switch d.DiscriminantProperty {
+	case "bitbucketCloud":
+		return json.Unmarshal(data, &v.BitbucketCloud)
 	case "builtin":
 		return json.Unmarshal(data, &v.Builtin)
 	case "github":
@@ -83,22 +102,40 @@ func (v *AuthProviders) UnmarshalJSON(data []byte) error {
 		return json.Unmarshal(data, &v.Gitlab)
 	case "http-header":
 		return json.Unmarshal(data, &v.HttpHeader)
+	case "ldap":
+		return json.Unmarshal(data, &v.Ldap)
 	case "openidconnect":
 		return json.Unmarshal(data, &v.Openidconnect)
 	case "saml":
 		return json.Unmarshal(data, &v.Saml)
 	}
-	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab"})
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "bitbucketCloud", "ldap"})
+}
+
+// BitbucketCloudAuthProvider description: Configures the Bitbucket Cloud OAuth authentication provider for SSO. In addition to specifying this configuration object, you must also create an OAuth consumer in the settings of your Bitbucket Cloud workspace: https://support.atlassian.com/bitbucket-cloud/docs/use-oauth-on-bitbucket-cloud/. The consumer should have the Account (Email and Read) and Repositories (Read) permissions and the callback URL set to the concatenation of your Sourcegraph instance URL and "/.auth/bitbucketcloud/callback".
+type BitbucketCloudAuthProvider struct {
+	AllowSignup  bool   `json:"allowSignup,omitempty"`
+	ClientID     string `json:"clientID"`
+	ClientSecret string `json:"clientSecret"`
+	DisplayName  string `json:"displayName,omitempty"`
+	Type         string `json:"type"`
+	Url          string `json:"url,omitempty"`
+}
+
+// BitbucketCloudAuthorization description: If non-null, enforces Bitbucket Cloud repository permissions. This requires that there be an item in the `auth.providers` field of type "bitbucketCloud" with the same `url` field as specified in this `BitbucketCloudConnection`. A user's permissions are computed with the OAuth token of the Bitbucket Cloud external account created when they sign in with Bitbucket Cloud.
+type BitbucketCloudAuthorization struct {
+	Ttl string `json:"ttl,omitempty"`
 }
 
 // BitbucketCloudConnection description: Configuration for a connection to Bitbucket Cloud.
 type BitbucketCloudConnection struct {
-	AppPassword           string   `json:"appPassword"`
-	GitURLType            string   `json:"gitURLType,omitempty"`
-	RepositoryPathPattern string   `json:"repositoryPathPattern,omitempty"`
-	Teams                 []string `json:"teams,omitempty"`
-	Url                   string   `json:"url"`
-	Username              string   `json:"username"`
+	AppPassword           string                       `json:"appPassword"`
+	Authorization         *BitbucketCloudAuthorization `json:"authorization,omitempty"`
+	GitURLType            string                       `json:"gitURLType,omitempty"`
+	RepositoryPathPattern string                       `json:"repositoryPathPattern,omitempty"`
+	Teams                 []string                     `json:"teams,omitempty"`
+	Url                   string                       `json:"url"`
+	Username              string                       `json:"username"`
 }
 
 // BitbucketServerAuthorization description: If non-null, enforces Bitbucket Server repository permissions.
@@ -178,6 +215,7 @@ type Branding struct {
 // BuiltinAuthProvider description: Configures the builtin username-password authentication provider.
 type BuiltinAuthProvider struct {
 	AllowSignup bool   `json:"allowSignup,omitempty"`
+	RequireMFA  string `json:"requireMFA,omitempty"`
 	Type        string `json:"type"`
 }
 
@@ -283,6 +321,7 @@ type GitHubConnection struct {
 	RepositoryQuery             []string              `json:"repositoryQuery,omitempty"`
 	Token                       string                `json:"token"`
 	Url                         string                `json:"url"`
+	WebhookSecret               string                `json:"webhookSecret,omitempty"`
 }
 
 // GitLabAuthProvider description: Configures the GitLab OAuth authentication provider for SSO. In addition to specifying this configuration object, you must also create a OAuth App on your GitLab instance: https://docs.gitlab.com/ee/integration/oauth_provider.html. The application should have `api` and `read_user` scopes and the callback URL set to the concatenation of your Sourcegraph instance URL and "/.auth/gitlab/callback".
@@ -313,6 +352,7 @@ type GitLabConnection struct {
 	RepositoryPathPattern       string                      `json:"repositoryPathPattern,omitempty"`
 	Token                       string                      `json:"token"`
 	Url                         string                      `json:"url"`
+	WebhookSecret               string                      `json:"webhookSecret,omitempty"`
 }
 type GitLabNameTransformation struct {
 	Regex       string `json:"regex,omitempty"`
@@ -323,8 +363,16 @@ type GitLabProject struct {
 	Name string `json:"name,omitempty"`
 }
 
+// GitoliteAuthorization description: If non-null, enforces Gitolite repository permissions as defined by the access rules in the Gitolite admin repository (conf/gitolite.conf and the files it includes). The admin repository must be mirrored on Sourcegraph (i.e. it must not be excluded), because its access rules are read through gitserver.
+type GitoliteAuthorization struct {
+	AdminRepository  string                   `json:"adminRepository,omitempty"`
+	IdentityProvider GitoliteIdentityProvider `json:"identityProvider"`
+	Ttl              string                   `json:"ttl,omitempty"`
+}
+
 // GitoliteConnection description: Configuration for a connection to Gitolite.
 type GitoliteConnection struct {
+	Authorization              *GitoliteAuthorization  `json:"authorization,omitempty"`
 	Blacklist                  string                  `json:"blacklist,omitempty"`
 	Exclude                    []*ExcludedGitoliteRepo `json:"exclude,omitempty"`
 	Host                       string                  `json:"host"`
@@ -332,6 +380,44 @@ type GitoliteConnection struct {
 	PhabricatorMetadataCommand string                  `json:"phabricatorMetadataCommand,omitempty"`
 	Prefix                     string                  `json:"prefix"`
 }
+type GitoliteEmailIdentity struct {
+	Type string `json:"type"`
+}
+
+// GitoliteIdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Gitolite username to use for a given Sourcegraph user. When 'username' is used, Sourcegraph assumes usernames are identical in Sourcegraph and Gitolite and `auth.enableUsernameChanges` must be set to false for security reasons. When 'email' is used, the Gitolite username is assumed to be one of the verified email addresses of the Sourcegraph user.
+type GitoliteIdentityProvider struct {
+	Username *GitoliteUsernameIdentity
+	Email    *GitoliteEmailIdentity
+}
+
+func (v GitoliteIdentityProvider) MarshalJSON() ([]byte, error) {
+	if v.Username != nil {
+		return json.Marshal(v.Username)
+	}
+	if v.Email != nil {
+		return json.Marshal(v.Email)
+	}
+	return nil, errors.New("tagged union type must have exactly 1 non-nil field value")
+}
+func (v *GitoliteIdentityProvider) UnmarshalJSON(data []byte) error {
+	var d struct {
+		DiscriminantProperty string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	switch d.DiscriminantProperty {
+	case "email":
+		return json.Unmarshal(data, &v.Email)
+	case "username":
+		return json.Unmarshal(data, &v.Username)
+	}
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"username", "email"})
+}
+
+type GitoliteUsernameIdentity struct {
+	Type string `json:"type"`
+}
 
 // HTTPHeaderAuthProvider description: Configures the HTTP header authentication provider (which authenticates users by consulting an HTTP request header set by an authentication proxy such as https://github.com/bitly/oauth2_proxy).
 type HTTPHeaderAuthProvider struct {
@@ -385,6 +471,27 @@ func (v *IdentityProvider) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"oauth", "username", "external"})
 }
 
+// LDAPAuthProvider description: Configures the LDAP authentication provider, which signs in users with the username and password of their account in an LDAP directory (such as Active Directory or OpenLDAP).
+//
+// To sign in a user, Sourcegraph searches for the user's entry with the user filter (binding with bindDN and bindPassword, if set), then binds as that entry with the user's password.
+type LDAPAuthProvider struct {
+	BindDN               string              `json:"bindDN,omitempty"`
+	BindPassword         string              `json:"bindPassword,omitempty"`
+	Certificate          string              `json:"certificate,omitempty"`
+	DisplayName          string              `json:"displayName,omitempty"`
+	DisplayNameAttribute string              `json:"displayNameAttribute,omitempty"`
+	EmailAttribute       string              `json:"emailAttribute,omitempty"`
+	GroupMappings        []*AuthGroupMapping `json:"groupMappings,omitempty"`
+	GroupsAttribute      string              `json:"groupsAttribute,omitempty"`
+	StartTLS             bool                `json:"startTLS,omitempty"`
+	TrustEmailAddresses  bool                `json:"trustEmailAddresses,omitempty"`
+	Type                 string              `json:"type"`
+	Url                  string              `json:"url"`
+	UserBaseDN           string              `json:"userBaseDN"`
+	UserFilter           string              `json:"userFilter,omitempty"`
+	UsernameAttribute    string              `json:"usernameAttribute,omitempty"`
+}
+
 // Log description: Configuration for logging and alerting, including to external services.
 type Log struct {
 	Sentry *Sentry `json:"sentry,omitempty"`
@@ -400,13 +507,15 @@ type OAuthIdentity struct {
 
 // OpenIDConnectAuthProvider description: Configures the OpenID Connect authentication provider for SSO.
 type OpenIDConnectAuthProvider struct {
-	ClientID           string `json:"clientID"`
-	ClientSecret       string `json:"clientSecret"`
-	ConfigID           string `json:"configID,omitempty"`
-	DisplayName        string `json:"displayName,omitempty"`
-	Issuer             string `json:"issuer"`
-	RequireEmailDomain string `json:"requireEmailDomain,omitempty"`
-	Type               string `json:"type"`
+	ClientID           string              `json:"clientID"`
+	ClientSecret       string              `json:"clientSecret"`
+	ConfigID           string              `json:"configID,omitempty"`
+	DisplayName        string              `json:"displayName,omitempty"`
+	GroupMappings      []*AuthGroupMapping `json:"groupMappings,omitempty"`
+	GroupsClaim        string              `json:"groupsClaim,omitempty"`
+	Issuer             string              `json:"issuer"`
+	RequireEmailDomain string              `json:"requireEmailDomain,omitempty"`
+	Type               string              `json:"type"`
 }
 
 // OtherExternalServiceConnection description: Configuration for a Connection to Git repositories for which an external service integration isn't yet available.
@@ -421,6 +530,18 @@ type ParentSourcegraph struct {
 	Url string `json:"url,omitempty"`
 }
 
+// PermissionsBackgroundSync description: Sync the repository permissions of all users from all authorization providers in the background and store them in the database. Permissions are then checked against the stored permissions instead of the code host on each request. Stored permissions older than three sync intervals are ignored, and the code host is asked directly.
+type PermissionsBackgroundSync struct {
+	Enabled        bool   `json:"enabled,omitempty"`
+	Interval       string `json:"interval,omitempty"`
+	SyncsPerMinute int    `json:"syncsPerMinute,omitempty"`
+}
+
+// PermissionsExplicit description: Enforce the repository permissions granted explicitly by site admins with the grantRepositoryPermissions GraphQL mutation, for repositories on code hosts without a permissions API (such as Gitolite, Phabricator or other code hosts). A repository with explicit grants is only accessible to the users and organization members it's granted to, and to users with access through an authorization provider.
+type PermissionsExplicit struct {
+	Enabled bool `json:"enabled,omitempty"`
+}
+
 // Phabricator description: Phabricator instance that integrates with this Gitolite instance
 type Phabricator struct {
 	CallsignCommand string `json:"callsignCommand"`
@@ -447,17 +568,19 @@ type Repos struct {
 //
 // Note: if you are using IdP-initiated login, you must have *at most one* SAMLAuthProvider in the `auth.providers` array.
 type SAMLAuthProvider struct {
-	ConfigID                                 string `json:"configID,omitempty"`
-	DisplayName                              string `json:"displayName,omitempty"`
-	IdentityProviderMetadata                 string `json:"identityProviderMetadata,omitempty"`
-	IdentityProviderMetadataURL              string `json:"identityProviderMetadataURL,omitempty"`
-	InsecureSkipAssertionSignatureValidation bool   `json:"insecureSkipAssertionSignatureValidation,omitempty"`
-	NameIDFormat                             string `json:"nameIDFormat,omitempty"`
-	ServiceProviderCertificate               string `json:"serviceProviderCertificate,omitempty"`
-	ServiceProviderIssuer                    string `json:"serviceProviderIssuer,omitempty"`
-	ServiceProviderPrivateKey                string `json:"serviceProviderPrivateKey,omitempty"`
-	SignRequests                             *bool  `json:"signRequests,omitempty"`
-	Type                                     string `json:"type"`
+	ConfigID                                 string              `json:"configID,omitempty"`
+	DisplayName                              string              `json:"displayName,omitempty"`
+	GroupMappings                            []*AuthGroupMapping `json:"groupMappings,omitempty"`
+	GroupsAttribute                          string              `json:"groupsAttribute,omitempty"`
+	IdentityProviderMetadata                 string              `json:"identityProviderMetadata,omitempty"`
+	IdentityProviderMetadataURL              string              `json:"identityProviderMetadataURL,omitempty"`
+	InsecureSkipAssertionSignatureValidation bool                `json:"insecureSkipAssertionSignatureValidation,omitempty"`
+	NameIDFormat                             string              `json:"nameIDFormat,omitempty"`
+	ServiceProviderCertificate               string              `json:"serviceProviderCertificate,omitempty"`
+	ServiceProviderIssuer                    string              `json:"serviceProviderIssuer,omitempty"`
+	ServiceProviderPrivateKey                string              `json:"serviceProviderPrivateKey,omitempty"`
+	SignRequests                             *bool               `json:"signRequests,omitempty"`
+	Type                                     string              `json:"type"`
 }
 
 // SMTPServerConfig description: The SMTP server used to send transactional emails (such as email verifications, reset-password emails, and notifications).
@@ -528,10 +651,15 @@ type SiteConfiguration struct {
 	LsifVerificationGithubToken       string                      `json:"lsifVerificationGithubToken,omitempty"`
 	MaxReposToSearch                  int                         `json:"maxReposToSearch,omitempty"`
 	ParentSourcegraph                 *ParentSourcegraph          `json:"parentSourcegraph,omitempty"`
+	PermissionsBackgroundSync         *PermissionsBackgroundSync  `json:"permissions.backgroundSync,omitempty"`
+	PermissionsExplicit               *PermissionsExplicit        `json:"permissions.explicit,omitempty"`
+	RepoDeletionGracePeriod           *int                        `json:"repoDeletionGracePeriod,omitempty"`
+	RepoDeletionQuarantineThreshold   *int                        `json:"repoDeletionQuarantineThreshold,omitempty"`
 	RepoListUpdateInterval            int                         `json:"repoListUpdateInterval,omitempty"`
 	SearchIndexEnabled                *bool                       `json:"search.index.enabled,omitempty"`
 	SearchIndexSymbolsEnabled         *bool                       `json:"search.index.symbols.enabled,omitempty"`
 	SearchLargeFiles                  []string                    `json:"search.largeFiles,omitempty"`
+	SearchSymbolsExtractors           map[string]string           `json:"search.symbols.extractors,omitempty"`
 }
 type UsernameIdentity struct {
 	Type string `json:"type"`
@@ -43,6 +43,16 @@ const SiteSchemaJSON = `{
       "group": "Search",
       "examples": [["go.sum", "package-lock.json", "*.thrift"]]
     },
+    "search.symbols.extractors": {
+      "description": "The symbol extractor to use for each language, by language name. Languages that aren't listed use universal-ctags (\"ctags\"). The \"go\" extractor parses Go files with the Go parser and extracts richer kinds, scopes, signatures and visibility than ctags. Changes only apply to commits that are indexed afterwards.",
+      "type": "object",
+      "additionalProperties": {
+        "type": "string",
+        "enum": ["ctags", "go"]
+      },
+      "group": "Search",
+      "examples": [{ "Go": "go" }]
+    },
     "debug.search.symbolsParallelism": {
       "description": "(debug) controls the amount of symbol search parallelism. Defaults to 20. It is not recommended to change this outside of debugging scenarios. This option will be removed in a future version.",
       "type": "integer",
@@ -156,6 +166,23 @@ const SiteSchemaJSON = `{
       "default": 1,
       "group": "External services"
     },
+    "repoDeletionGracePeriod": {
+      "description": "Period (in hours) for which repositories that are no longer returned by their code host are kept before being deleted. During this period they are hidden from users but not removed from gitserver, so that they don't have to be recloned if they reappear (e.g. after a temporary code host error). Zero deletes them right away.",
+      "type": "integer",
+      "minimum": 0,
+      "default": 72,
+      "!go": { "pointer": true },
+      "group": "External services"
+    },
+    "repoDeletionQuarantineThreshold": {
+      "description": "Percentage of an external service's repositories that a single sync may find missing from the code host before quarantining them. Quarantined repositories stay accessible and are never deleted automatically; a site admin must review them. Zero disables quarantining.",
+      "type": "integer",
+      "minimum": 0,
+      "maximum": 100,
+      "default": 50,
+      "!go": { "pointer": true },
+      "group": "External services"
+    },
     "maxReposToSearch": {
       "description": "The maximum number of repositories to search across. The user is prompted to narrow their query if exceeded. Any value less than or equal to zero means unlimited.",
       "type": "integer",
@@ -197,6 +224,45 @@ const SiteSchemaJSON = `{
       ],
       "group": "Security"
     },
+    "permissions.backgroundSync": {
+      "description": "Sync the repository permissions of all users from all authorization providers in the background and store them in the database. Permissions are then checked against the stored permissions instead of the code host on each request. Stored permissions older than three sync intervals are ignored, and the code host is asked directly.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "description": "Whether to sync permissions in the background and check them against the stored permissions.",
+          "type": "boolean",
+          "default": false
+        },
+        "interval": {
+          "description": "How often the permissions of each user are synced, as a duration such as \"30m\" or \"2h\".",
+          "type": "string",
+          "default": "1h"
+        },
+        "syncsPerMinute": {
+          "description": "The maximum number of users whose permissions are synced per minute from each authorization provider, to stay within code host rate limits. Each sync may take several code host API requests.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 60
+        }
+      },
+      "examples": [{ "enabled": true, "interval": "30m", "syncsPerMinute": 120 }],
+      "group": "Security"
+    },
+    "permissions.explicit": {
+      "description": "Enforce the repository permissions granted explicitly by site admins with the grantRepositoryPermissions GraphQL mutation, for repositories on code hosts without a permissions API (such as Gitolite, Phabricator or other code hosts). A repository with explicit grants is only accessible to the users and organization members it's granted to, and to users with access through an authorization provider.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "description": "Whether to enforce explicitly granted repository permissions.",
+          "type": "boolean",
+          "default": false
+        }
+      },
+      "examples": [{ "enabled": true }],
+      "group": "Security"
+    },
     "branding": {
       "description": "Customize Sourcegraph homepage logo and search icon.\n\nOnly available in Sourcegraph Enterprise.",
       "type": "object",
@@ -0,0 +1,169 @@
+import { LoadingSpinner } from '@sourcegraph/react-loading-spinner'
+import { upperFirst } from 'lodash'
+import * as React from 'react'
+import { Form } from '../components/Form'
+import { TOTPEnrollmentInfo } from './TOTPEnrollmentInfo'
+
+/**
+ * The response to a sign-in (or sign-up) request when the user must use multi-factor authentication to sign
+ * in.
+ */
+export interface MFAResponse {
+    /** Whether the user must enter a code ("verify"), or enroll and then enter a code ("enroll"). */
+    mfa: 'verify' | 'enroll'
+
+    // Set if mfa === 'enroll'.
+    totpSecret?: string
+    totpURL?: string
+    recoveryCodes?: string[]
+}
+
+/**
+ * Reads the body of a successful sign-in (or sign-up) response, which is an MFAResponse if the user must enter
+ * a code to finish signing in and empty otherwise.
+ */
+export async function readMFAResponse(resp: Response): Promise<MFAResponse | undefined> {
+    const body = await resp.text()
+    const mfa: MFAResponse | undefined = body ? JSON.parse(body) : undefined
+    return mfa && mfa.mfa ? mfa : undefined
+}
+
+interface Props {
+    mfa: MFAResponse
+
+    /** Called when the user entered a valid code and is signed in. */
+    onSignedIn: () => void
+
+    /** Called when the pending sign-in expired (or the user was suspended), so they must enter their password again. */
+    onExpired: (message: string) => void
+}
+
+interface State {
+    code: string
+    useRecoveryCode: boolean
+    errorDescription: string
+    loading: boolean
+}
+
+/**
+ * The form for entering a code from an authenticator app (or a recovery code) after entering a valid password,
+ * for users who must use multi-factor authentication. If the user must enroll first, it shows them the new
+ * enrollment's secret and recovery codes.
+ */
+export class MFAForm extends React.Component<Props, State> {
+    public state: State = { code: '', useRecoveryCode: false, errorDescription: '', loading: false }
+
+    public render(): JSX.Element | null {
+        const { mfa } = this.props
+        return (
+            <Form className="signin-signup-form signin-form e2e-signin-mfa-form" onSubmit={this.handleSubmit}>
+                {this.state.errorDescription !== '' && (
+                    <div className="alert alert-danger my-2">Error: {upperFirst(this.state.errorDescription)}</div>
+                )}
+                {mfa.mfa === 'enroll' && mfa.totpSecret && mfa.totpURL && mfa.recoveryCodes ? (
+                    <>
+                        <p>Your site admin requires you to use multi-factor authentication to sign in.</p>
+                        <TOTPEnrollmentInfo
+                            secret={mfa.totpSecret}
+                            url={mfa.totpURL}
+                            recoveryCodes={mfa.recoveryCodes}
+                        />
+                        <p>Then enter the code shown in your authenticator app.</p>
+                    </>
+                ) : (
+                    <p>
+                        {this.state.useRecoveryCode
+                            ? 'Enter one of your recovery codes.'
+                            : 'Enter the code shown in your authenticator app.'}
+                    </p>
+                )}
+                <div className="form-group">
+                    <input
+                        className="form-control signin-signup-form__input"
+                        type="text"
+                        placeholder={this.state.useRecoveryCode ? 'Recovery code' : 'Code'}
+                        onChange={this.onCodeFieldChange}
+                        required={true}
+                        value={this.state.code}
+                        disabled={this.state.loading}
+                        autoCapitalize="off"
+                        autoComplete="one-time-code"
+                        autoFocus={true}
+                        inputMode={this.state.useRecoveryCode ? 'text' : 'numeric'}
+                    />
+                </div>
+                <div className="form-group">
+                    <button className="btn btn-primary btn-block" type="submit" disabled={this.state.loading}>
+                        Verify
+                    </button>
+                    {mfa.mfa === 'verify' && (
+                        <small className="form-text text-muted">
+                            <button
+                                type="button"
+                                className="btn btn-link p-0"
+                                onClick={this.toggleUseRecoveryCode}
+                                disabled={this.state.loading}
+                            >
+                                {this.state.useRecoveryCode
+                                    ? 'Use a code from your authenticator app'
+                                    : 'Use a recovery code'}
+                            </button>
+                        </small>
+                    )}
+                </div>
+                {this.state.loading && (
+                    <div className="signin-signup-form__loader">
+                        <LoadingSpinner className="icon-inline" />
+                    </div>
+                )}
+            </Form>
+        )
+    }
+
+    private onCodeFieldChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+        this.setState({ code: e.target.value })
+    }
+
+    private toggleUseRecoveryCode = () => {
+        this.setState(state => ({ useRecoveryCode: !state.useRecoveryCode, code: '', errorDescription: '' }))
+    }
+
+    private handleSubmit = (event: React.FormEvent<HTMLFormElement>) => {
+        event.preventDefault()
+        if (this.state.loading) {
+            return
+        }
+
+        this.setState({ loading: true })
+        fetch('/-/sign-in/mfa', {
+            credentials: 'same-origin',
+            method: 'POST',
+            headers: {
+                ...window.context.xhrHeaders,
+                Accept: 'application/json',
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify(
+                this.state.useRecoveryCode ? { recoveryCode: this.state.code } : { code: this.state.code }
+            ),
+        })
+            .then(async resp => {
+                if (resp.status === 200) {
+                    this.props.onSignedIn()
+                } else if (resp.status === 401 || resp.status === 403) {
+                    const message = (await resp.text()).trim()
+                    if (message !== 'Authentication failed') {
+                        this.props.onExpired(message)
+                        return
+                    }
+                    throw new Error('Code was incorrect')
+                } else {
+                    throw new Error('Unknown Error')
+                }
+            })
+            .catch(err => {
+                console.error('auth error: ', err)
+                this.setState({ loading: false, errorDescription: (err && err.message) || 'Unknown Error' })
+            })
+    }
+}
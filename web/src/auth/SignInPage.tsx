@@ -37,6 +37,8 @@ export class SignInPage extends React.Component<SignInPageProps> {
                             window.context.authProviders.map((p, i) =>
                                 p.isBuiltin ? (
                                     <UsernamePasswordSignInForm key={i} {...this.props} />
+                                ) : p.serviceType === 'ldap' ? (
+                                    <UsernamePasswordSignInForm key={i} {...this.props} provider={p} />
                                 ) : (
                                     <a key={i} href={p.authenticationURL} className="btn btn-primary mt-3 mb-1">
                                         Sign in with {p.displayName}
@@ -8,6 +8,7 @@ import { asError } from '../../../shared/src/util/errors'
 import { Form } from '../components/Form'
 import { eventLogger } from '../tracking/eventLogger'
 import { enterpriseTrial, signupTerms } from '../util/features'
+import { MFAForm, MFAResponse } from './MFAForm'
 import { EmailInput, PasswordInput, UsernameInput } from './SignInSignUpCommon'
 
 export interface SignUpArgs {
@@ -21,8 +22,14 @@ interface SignUpFormProps {
     location: H.Location
     history: H.History
 
-    /** Called to perform the signup on the server. */
-    doSignUp: (args: SignUpArgs) => Promise<void>
+    /**
+     * Called to perform the signup on the server. It resolves to the server's MFAResponse if the new user must
+     * use multi-factor authentication to sign in.
+     */
+    doSignUp: (args: SignUpArgs) => Promise<MFAResponse | undefined>
+
+    /** Called when the new user is signed in. */
+    onSignedIn: () => void
 
     buttonLabel?: string
 }
@@ -34,6 +41,9 @@ interface SignUpFormState {
     error?: Error
     loading: boolean
     requestedTrial: boolean
+
+    /** Set after the user signed up if they must use multi-factor authentication. */
+    mfa?: MFAResponse
 }
 
 export class SignUpForm extends React.Component<SignUpFormProps, SignUpFormState> {
@@ -51,6 +61,9 @@ export class SignUpForm extends React.Component<SignUpFormProps, SignUpFormState
     }
 
     public render(): JSX.Element | null {
+        if (this.state.mfa) {
+            return <MFAForm mfa={this.state.mfa} onSignedIn={this.props.onSignedIn} onExpired={this.onMFAExpired} />
+        }
         return (
             <Form className="signin-signup-form signup-form e2e-signup-form" onSubmit={this.handleSubmit}>
                 {this.state.error && (
@@ -146,6 +159,11 @@ export class SignUpForm extends React.Component<SignUpFormProps, SignUpFormState
         this.setState({ requestedTrial: e.target.checked })
     }
 
+    private onMFAExpired = () => {
+        // The user was created, so they must sign in with their password to finish enrolling.
+        window.location.replace('/sign-in')
+    }
+
     private handleSubmit = (event: React.FormEvent<HTMLFormElement>) => {
         event.preventDefault()
         if (this.state.loading) {
@@ -162,6 +180,13 @@ export class SignUpForm extends React.Component<SignUpFormProps, SignUpFormState
                         password: this.state.password,
                         requestedTrial: this.state.requestedTrial,
                     })
+                    .then(mfa => {
+                        if (mfa) {
+                            this.setState({ mfa, password: '', loading: false })
+                        } else {
+                            this.props.onSignedIn()
+                        }
+                    })
                     .catch(error => this.setState({ error: asError(error), loading: false }))
             ).subscribe()
         )
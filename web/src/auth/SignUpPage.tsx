@@ -6,6 +6,7 @@ import * as GQL from '../../../shared/src/graphql/schema'
 import { HeroPage } from '../components/HeroPage'
 import { PageTitle } from '../components/PageTitle'
 import { eventLogger } from '../tracking/eventLogger'
+import { MFAResponse, readMFAResponse } from './MFAForm'
 import { getReturnTo } from './SignInSignUpCommon'
 import { SignUpArgs, SignUpForm } from './SignUpForm'
 
@@ -43,7 +44,7 @@ export class SignUpPage extends React.Component<SignUpPageProps> {
                             <Link className="signin-signup-form__mode" to={`/sign-in${this.props.location.search}`}>
                                 Already have an account? Sign in.
                             </Link>
-                            <SignUpForm {...this.props} doSignUp={this.doSignUp} />
+                            <SignUpForm {...this.props} doSignUp={this.doSignUp} onSignedIn={this.onSignedIn} />
                         </div>
                     }
                 />
@@ -51,7 +52,7 @@ export class SignUpPage extends React.Component<SignUpPageProps> {
         )
     }
 
-    private doSignUp = (args: SignUpArgs): Promise<void> =>
+    private doSignUp = (args: SignUpArgs): Promise<MFAResponse | undefined> =>
         fetch('/-/sign-up', {
             credentials: 'same-origin',
             method: 'POST',
@@ -65,7 +66,9 @@ export class SignUpPage extends React.Component<SignUpPageProps> {
             if (resp.status !== 200) {
                 return resp.text().then(text => Promise.reject(new Error(text)))
             }
-            window.location.replace(getReturnTo(this.props.location))
-            return Promise.resolve()
+            // If the new user must use multi-factor authentication, the response tells us to ask for a code.
+            return readMFAResponse(resp)
         })
+
+    private onSignedIn = (): void => window.location.replace(getReturnTo(this.props.location))
 }
@@ -0,0 +1,35 @@
+import * as React from 'react'
+
+interface Props {
+    /** The TOTP secret (encoded in base32). */
+    secret: string
+
+    /** The otpauth:// URL of the secret. */
+    url: string
+
+    /** The recovery codes, which are only shown once. */
+    recoveryCodes: string[]
+}
+
+/**
+ * Shows the secret and recovery codes of a new multi-factor authentication (TOTP) enrollment, which the user
+ * must add to their authenticator app and store safely.
+ */
+export const TOTPEnrollmentInfo: React.FunctionComponent<Props> = ({ secret, url, recoveryCodes }) => (
+    <div className="totp-enrollment-info">
+        <p>
+            Add this account to your authenticator app (such as Google Authenticator or Authy) by entering this key,
+            or by <a href={url}>opening it in your authenticator app</a>:
+        </p>
+        <pre className="form-control-plaintext text-center e2e-totp-secret">
+            <code>{secret.replace(/(.{4})/g, '$1 ').trim()}</code>
+        </pre>
+        <p>
+            Store these recovery codes somewhere safe. If you lose access to your authenticator app, you can use each
+            of them once instead of a code. They won't be shown again.
+        </p>
+        <pre className="form-control-plaintext text-center">
+            <code>{recoveryCodes.join('\n')}</code>
+        </pre>
+    </div>
+)
@@ -5,11 +5,21 @@ import * as React from 'react'
 import { Link } from 'react-router-dom'
 import { Form } from '../components/Form'
 import { eventLogger } from '../tracking/eventLogger'
+import { MFAForm, MFAResponse, readMFAResponse } from './MFAForm'
 import { getReturnTo, PasswordInput } from './SignInSignUpCommon'
 
 interface Props {
     location: H.Location
     history: H.History
+
+    /**
+     * An external auth provider that authenticates users with a username and password (such as an LDAP
+     * provider). If not set, the builtin auth provider is used.
+     */
+    provider?: {
+        displayName: string
+        authenticationURL?: string
+    }
 }
 
 interface State {
@@ -17,10 +27,14 @@ interface State {
     password: string
     errorDescription: string
     loading: boolean
+
+    /** Set after the user entered a valid password if they must use multi-factor authentication. */
+    mfa?: MFAResponse
 }
 
 /**
- * The form for signing in with a username and password.
+ * The form for signing in with a username and password, either with the builtin auth provider or with an
+ * external auth provider that accepts a username and password.
  */
 export class UsernamePasswordSignInForm extends React.Component<Props, State> {
     constructor(props: Props) {
@@ -34,9 +48,12 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
     }
 
     public render(): JSX.Element | null {
+        if (this.state.mfa) {
+            return <MFAForm mfa={this.state.mfa} onSignedIn={this.onSignedIn} onExpired={this.onMFAExpired} />
+        }
         return (
             <Form className="signin-signup-form signin-form e2e-signin-form" onSubmit={this.handleSubmit}>
-                {window.context.allowSignup ? (
+                {this.props.provider ? null : window.context.allowSignup ? (
                     <Link className="signin-signup-form__mode" to={`/sign-up${this.props.location.search}`}>
                         Don't have an account? Sign up.
                     </Link>
@@ -50,14 +67,14 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
                     <input
                         className="form-control signin-signup-form__input"
                         type="text"
-                        placeholder="Username or email"
+                        placeholder={this.props.provider ? 'Username' : 'Username or email'}
                         onChange={this.onEmailFieldChange}
                         required={true}
                         value={this.state.email}
                         disabled={this.state.loading}
                         autoCapitalize="off"
                         autoFocus={true}
-                        autoComplete="username email"
+                        autoComplete={this.props.provider ? 'username' : 'username email'}
                     />
                 </div>
                 <div className="form-group">
@@ -72,9 +89,9 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
                 </div>
                 <div className="form-group">
                     <button className="btn btn-primary btn-block" type="submit" disabled={this.state.loading}>
-                        Sign in
+                        {this.props.provider ? `Sign in with ${this.props.provider.displayName}` : 'Sign in'}
                     </button>
-                    {window.context.resetPasswordEnabled && (
+                    {!this.props.provider && window.context.resetPasswordEnabled && (
                         <small className="form-text text-muted">
                             <Link to="/password-reset">Forgot password?</Link>
                         </small>
@@ -97,6 +114,15 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
         this.setState({ password: e.target.value })
     }
 
+    private onSignedIn = () => {
+        window.location.replace(getReturnTo(this.props.location))
+    }
+
+    private onMFAExpired = (message: string) => {
+        // The user must enter their password again.
+        this.setState({ mfa: undefined, loading: false, errorDescription: message })
+    }
+
     private handleSubmit = (event: React.FormEvent<HTMLFormElement>) => {
         event.preventDefault()
         if (this.state.loading) {
@@ -105,7 +131,8 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
 
         this.setState({ loading: true })
         eventLogger.log('InitiateSignIn')
-        fetch('/-/sign-in', {
+        const { provider } = this.props
+        fetch((provider && provider.authenticationURL) || '/-/sign-in', {
             credentials: 'same-origin',
             method: 'POST',
             headers: {
@@ -113,17 +140,25 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
                 Accept: 'application/json',
                 'Content-Type': 'application/json',
             },
-            body: JSON.stringify({
-                email: this.state.email,
-                password: this.state.password,
-            }),
+            body: JSON.stringify(
+                provider
+                    ? { username: this.state.email, password: this.state.password }
+                    : { email: this.state.email, password: this.state.password }
+            ),
         })
-            .then(resp => {
+            .then(async resp => {
                 if (resp.status === 200) {
-                    const returnTo = getReturnTo(this.props.location)
-                    window.location.replace(returnTo)
+                    // If the user must use multi-factor authentication, the response tells us to ask for a code.
+                    const mfa = await readMFAResponse(resp)
+                    if (mfa) {
+                        this.setState({ loading: false, errorDescription: '', password: '', mfa })
+                        return
+                    }
+                    this.onSignedIn()
                 } else if (resp.status === 401) {
                     throw new Error('User or password was incorrect')
+                } else if (resp.status === 403) {
+                    throw new Error(await resp.text())
                 } else {
                     throw new Error('Unknown Error')
                 }
@@ -82,6 +82,7 @@ export class DiscussionsComment extends React.PureComponent<Props> {
                         >
                             {comment.author.username}
                         </Link>
+                        {comment.author.suspended && <span className="badge badge-secondary mr-1">Suspended</span>}
                         <span className="mr-1">commented</span>
                         <Timestamp date={comment.createdAt} />
                     </span>
@@ -39,6 +39,7 @@ const DiscussionNode: React.FunctionComponent<DiscussionNodeProps> = ({ node, lo
                 <Link to={`/users/${node.author.username}`} data-tooltip={node.author.displayName}>
                     {node.author.username}
                 </Link>{' '}
+                {node.author.suspended && <span className="badge badge-secondary mr-1">Suspended</span>}
                 {withRepo && (
                     <>
                         in <Link to={node.target.repository.name}>{node.target.repository.name}</Link>
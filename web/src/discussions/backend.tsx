@@ -66,6 +66,7 @@ const discussionThreadFieldsFragment = gql`
         displayName
         username
         avatarURL
+        suspended
     }
 `
 
@@ -86,6 +86,9 @@ interface SourcegraphContext
     /** Whether the reset-password flow is enabled. */
     resetPasswordEnabled: boolean
 
+    /** Whether users can enroll in multi-factor authentication for signing in with a password. */
+    mfaEnabled: boolean
+
     /**
      * Likely running within a Docker container under a Mac host OS.
      */
@@ -107,6 +110,8 @@ interface SourcegraphContext
 
     /** Authentication provider instances in site config. */
     authProviders?: {
+        /** The type of the auth provider (e.g., "builtin", "openidconnect" or "ldap"). */
+        serviceType: string
         displayName: string
         isBuiltin: boolean
         authenticationURL?: string
@@ -16,7 +16,7 @@ import { PageTitle } from '../components/PageTitle'
 import { eventLogger } from '../tracking/eventLogger'
 import { userURL } from '../user'
 import { setUserEmailVerified } from '../user/settings/backend'
-import { deleteUser, fetchAllUsers, randomizeUserPassword, setUserIsSiteAdmin } from './backend'
+import { deleteUser, fetchAllUsers, randomizeUserPassword, setUserIsSiteAdmin, setUserSuspended } from './backend'
 
 interface UserNodeProps {
     /**
@@ -102,6 +102,7 @@ class UserNode extends React.PureComponent<UserNodeProps, UserNodeState> {
                         <Link to={`/users/${this.props.node.username}`}>
                             <strong>{this.props.node.username}</strong>
                         </Link>
+                        {this.props.node.suspended && <span className="badge badge-secondary ml-1">Suspended</span>}
                         <br />
                         <span className="text-muted">{this.props.node.displayName}</span>
                     </div>
@@ -140,6 +141,28 @@ class UserNode extends React.PureComponent<UserNodeProps, UserNodeState> {
                                     Promote to site admin
                                 </button>
                             ))}{' '}
+                        {this.props.node.id !== this.props.authenticatedUser.id &&
+                            (this.props.node.suspended ? (
+                                <button
+                                    type="button"
+                                    className="btn btn-sm btn-secondary"
+                                    onClick={this.unsuspendUser}
+                                    disabled={this.state.loading}
+                                >
+                                    Unsuspend
+                                </button>
+                            ) : (
+                                <button
+                                    type="button"
+                                    key="suspend"
+                                    className="btn btn-sm btn-secondary"
+                                    onClick={this.suspendUser}
+                                    disabled={this.state.loading}
+                                    data-tooltip="Block the user from signing in, and revoke their sessions and access tokens. Their data is retained."
+                                >
+                                    Suspend
+                                </button>
+                            ))}{' '}
                         {this.props.node.id !== this.props.authenticatedUser.id && (
                             <button
                                 type="button"
@@ -212,6 +235,38 @@ class UserNode extends React.PureComponent<UserNodeProps, UserNodeState> {
             )
     }
 
+    private suspendUser = () => this.setSuspended(true)
+    private unsuspendUser = () => this.setSuspended(false)
+
+    private setSuspended(suspended: boolean): void {
+        if (
+            !window.confirm(
+                suspended
+                    ? `Suspend user ${this.props.node.username}? They will be signed out and their access tokens will be revoked.`
+                    : `Unsuspend user ${this.props.node.username}?`
+            )
+        ) {
+            return
+        }
+
+        this.setState({
+            errorDescription: undefined,
+            loading: true,
+        })
+
+        setUserSuspended(this.props.node.id, suspended)
+            .toPromise()
+            .then(
+                () => {
+                    this.setState({ loading: false })
+                    if (this.props.onDidUpdate) {
+                        this.props.onDidUpdate()
+                    }
+                },
+                err => this.setState({ loading: false, errorDescription: err.message })
+            )
+    }
+
     private randomizePassword = () => {
         if (
             !window.confirm(
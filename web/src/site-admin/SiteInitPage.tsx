@@ -1,6 +1,7 @@
 import * as React from 'react'
 import { Redirect, RouteComponentProps } from 'react-router'
 import * as GQL from '../../../shared/src/graphql/schema'
+import { MFAResponse, readMFAResponse } from '../auth/MFAForm'
 import { SignUpArgs, SignUpForm } from '../auth/SignUpForm'
 import { submitTrialRequest } from '../marketing/backend'
 
@@ -40,6 +41,7 @@ export class SiteInitPage extends React.Component<Props> {
                             <SignUpForm
                                 buttonLabel="Create admin account & continue"
                                 doSignUp={this.doSiteInit}
+                                onSignedIn={this.onSignedIn}
                                 location={this.props.location}
                                 history={this.props.history}
                             />
@@ -50,7 +52,7 @@ export class SiteInitPage extends React.Component<Props> {
         )
     }
 
-    private doSiteInit = (args: SignUpArgs): Promise<void> =>
+    private doSiteInit = (args: SignUpArgs): Promise<MFAResponse | undefined> =>
         fetch('/-/site-init', {
             credentials: 'same-origin',
             method: 'POST',
@@ -68,7 +70,9 @@ export class SiteInitPage extends React.Component<Props> {
                 submitTrialRequest(args.email)
             }
 
-            window.location.replace('/site-admin')
-            return Promise.resolve()
+            // If site admins must use multi-factor authentication, the response tells us to ask for a code.
+            return readMFAResponse(resp)
         })
+
+    private onSignedIn = (): void => window.location.replace('/site-admin')
 }
@@ -25,6 +25,7 @@ export function fetchAllUsers(args: { first?: number; query?: string }): Observa
                         }
                         createdAt
                         siteAdmin
+                        suspended
                         latestSettings {
                             createdAt
                             contents
@@ -362,6 +363,22 @@ export function setUserIsSiteAdmin(userID: GQL.ID, siteAdmin: boolean): Observab
     )
 }
 
+export function setUserSuspended(user: GQL.ID, suspended: boolean): Observable<void> {
+    return mutateGraphQL(
+        gql`
+            mutation SetUserSuspended($user: ID!, $suspended: Boolean!) {
+                setUserSuspended(user: $user, suspended: $suspended) {
+                    alwaysNil
+                }
+            }
+        `,
+        { user, suspended }
+    ).pipe(
+        map(dataOrThrowErrors),
+        map(() => undefined)
+    )
+}
+
 export function randomizeUserPassword(user: GQL.ID): Observable<GQL.IRandomizeUserPasswordResult> {
     return mutateGraphQL(
         gql`
@@ -85,6 +85,8 @@ function editWithComment(config: string, path: JSONPath, value: any, comment: st
 const editorActionComments = {
     enablePermissions:
         '// Prerequisite: you must configure GitHub as an OAuth auth provider in the critical site config (https://docs.sourcegraph.com/admin/auth#github). Otherwise, access to all repositories will be disallowed.',
+    enablePermissionsBitbucketCloud:
+        '// Prerequisite: you must configure Bitbucket Cloud as an OAuth auth provider in the critical site config (https://docs.sourcegraph.com/admin/auth#bitbucket-cloud). Otherwise, access to all repositories will be disallowed.',
     enforcePermissionsOAuth: `// Prerequisite: you must first update the critical site configuration to
     // include GitLab OAuth as an auth provider.
     // See https://docs.sourcegraph.com/admin/auth#gitlab for instructions.`,
@@ -328,6 +330,18 @@ export const ALL_EXTERNAL_SERVICES: Record<GQL.ExternalServiceKind, ExternalServ
                     return { edits, selectText: value }
                 },
             },
+            {
+                id: 'enablePermissions',
+                label: 'Enforce permissions',
+                run: config => {
+                    const value = {
+                        COMMENT_SENTINEL: true,
+                    }
+                    const comment = editorActionComments.enablePermissionsBitbucketCloud
+                    const edit = editWithComment(config, ['authorization'], value, comment)
+                    return { edits: [edit], selectText: comment }
+                },
+            },
         ],
     },
     [GQL.ExternalServiceKind.BITBUCKETSERVER]: {
@@ -665,6 +679,17 @@ export const ALL_EXTERNAL_SERVICES: Record<GQL.ExternalServiceKind, ExternalServ
                     return { edits, selectText: value }
                 },
             },
+            {
+                id: 'enablePermissions',
+                label: 'Enforce permissions',
+                run: config => {
+                    const value = {
+                        identityProvider: { type: 'username' },
+                    }
+                    const edits = setProperty(config, ['authorization'], value, defaultFormattingOptions)
+                    return { edits, selectText: 'username' }
+                },
+            },
         ],
     },
     [GQL.ExternalServiceKind.PHABRICATOR]: {
@@ -37,6 +37,7 @@ const fetchUser = (args: { username: string }): Observable<GQL.IUser | null> =>
                     avatarURL
                     viewerCanAdminister
                     siteAdmin
+                    suspended
                     createdAt
                     emails {
                         email
@@ -33,6 +33,7 @@ export const UserAreaHeader: React.FunctionComponent<Props> = ({ url, navItems,
                         ) : (
                             props.user.username
                         )}
+                        {props.user.suspended && <span className="badge badge-secondary ml-2">Suspended</span>}
                     </h2>
                     <div className="d-flex align-items-end justify-content-between">
                         <ul className="nav nav-tabs border-bottom-0">
@@ -0,0 +1,254 @@
+import { LoadingSpinner } from '@sourcegraph/react-loading-spinner'
+import { upperFirst } from 'lodash'
+import * as React from 'react'
+import { RouteComponentProps } from 'react-router'
+import { Observable, Subject, Subscription } from 'rxjs'
+import { catchError, map, startWith, switchMap, tap } from 'rxjs/operators'
+import { gql } from '../../../../../shared/src/graphql/graphql'
+import * as GQL from '../../../../../shared/src/graphql/schema'
+import { asError, createAggregateError, ErrorLike, isErrorLike } from '../../../../../shared/src/util/errors'
+import { TOTPEnrollmentInfo } from '../../../auth/TOTPEnrollmentInfo'
+import { queryGraphQL } from '../../../backend/graphql'
+import { Form } from '../../../components/Form'
+import { PageTitle } from '../../../components/PageTitle'
+import { eventLogger } from '../../../tracking/eventLogger'
+import { confirmTOTPEnrollment, enrollTOTP, resetTOTP } from '../backend'
+
+function queryUserTOTPEnabled(user: GQL.ID): Observable<boolean> {
+    return queryGraphQL(
+        gql`
+            query UserTOTPEnabled($user: ID!) {
+                node(id: $user) {
+                    ... on User {
+                        totpEnabled
+                    }
+                }
+            }
+        `,
+        { user }
+    ).pipe(
+        map(({ data, errors }) => {
+            if (!data || !data.node) {
+                throw createAggregateError(errors)
+            }
+            return (data.node as GQL.IUser).totpEnabled
+        })
+    )
+}
+
+interface Props extends RouteComponentProps<{}> {
+    user: GQL.IUser
+    authenticatedUser: GQL.IUser
+}
+
+interface State {
+    /** Whether the user is enrolled, or an error, or undefined while loading. */
+    totpEnabledOrError?: boolean | ErrorLike
+
+    /** The pending enrollment, after the user clicked the enroll button. */
+    enrollment?: GQL.ITOTPEnrollment
+    code: string
+
+    /** The code, recovery code or password that the user entered to reset their enrollment. */
+    resetCode: string
+    resetPassword: string
+
+    loading: boolean
+    error?: ErrorLike
+}
+
+/**
+ * The page for enrolling in and resetting multi-factor authentication for signing in with a username and password.
+ */
+export class UserSettingsMFAPage extends React.Component<Props, State> {
+    public state: State = { code: '', resetCode: '', resetPassword: '', loading: false }
+
+    private refreshRequests = new Subject<void>()
+    private subscriptions = new Subscription()
+
+    public componentDidMount(): void {
+        eventLogger.logViewEvent('UserSettingsMFA')
+        this.subscriptions.add(
+            this.refreshRequests
+                .pipe(
+                    startWith(undefined),
+                    switchMap(() => queryUserTOTPEnabled(this.props.user.id).pipe(catchError(err => [asError(err)])))
+                )
+                .subscribe(totpEnabledOrError => this.setState({ totpEnabledOrError }))
+        )
+    }
+
+    public componentWillUnmount(): void {
+        this.subscriptions.unsubscribe()
+    }
+
+    public render(): JSX.Element | null {
+        const isCurrentUser = this.props.authenticatedUser.id === this.props.user.id
+        return (
+            <div className="user-settings-mfa-page">
+                <PageTitle title="Multi-factor authentication" />
+                <h2>Multi-factor authentication</h2>
+                <p>
+                    With multi-factor authentication, signing in with a username and password also requires a code
+                    from an authenticator app on your phone.
+                </p>
+                {this.state.error && <p className="alert alert-danger">{upperFirst(this.state.error.message)}</p>}
+                {this.state.totpEnabledOrError === undefined ? (
+                    <LoadingSpinner className="icon-inline" />
+                ) : isErrorLike(this.state.totpEnabledOrError) ? (
+                    <p className="alert alert-danger">{upperFirst(this.state.totpEnabledOrError.message)}</p>
+                ) : this.state.totpEnabledOrError ? (
+                    <>
+                        <p className="alert alert-success">
+                            {isCurrentUser ? 'You are' : `${this.props.user.username} is`} enrolled in multi-factor
+                            authentication.
+                        </p>
+                        {this.props.authenticatedUser.siteAdmin ? (
+                            <button
+                                type="button"
+                                className="btn btn-danger"
+                                onClick={this.reset}
+                                disabled={this.state.loading}
+                            >
+                                Reset multi-factor authentication
+                            </button>
+                        ) : (
+                            <Form onSubmit={this.reset}>
+                                <div className="form-group">
+                                    <label>
+                                        To reset, enter a code from your authenticator app (or a recovery code), or
+                                        your password
+                                    </label>
+                                    <input
+                                        className="form-control"
+                                        type="text"
+                                        placeholder="Code"
+                                        value={this.state.resetCode}
+                                        onChange={this.onResetCodeFieldChange}
+                                        disabled={this.state.loading}
+                                        autoComplete="one-time-code"
+                                    />
+                                    <input
+                                        className="form-control mt-2"
+                                        type="password"
+                                        placeholder="Password"
+                                        value={this.state.resetPassword}
+                                        onChange={this.onResetPasswordFieldChange}
+                                        disabled={this.state.loading}
+                                        autoComplete="current-password"
+                                    />
+                                </div>
+                                <button
+                                    type="submit"
+                                    className="btn btn-danger"
+                                    disabled={
+                                        this.state.loading || (!this.state.resetCode && !this.state.resetPassword)
+                                    }
+                                >
+                                    Reset multi-factor authentication
+                                </button>
+                            </Form>
+                        )}
+                    </>
+                ) : !isCurrentUser ? (
+                    <p>{this.props.user.username} is not enrolled in multi-factor authentication.</p>
+                ) : this.state.enrollment ? (
+                    <Form onSubmit={this.confirm}>
+                        <TOTPEnrollmentInfo
+                            secret={this.state.enrollment.secret}
+                            url={this.state.enrollment.url}
+                            recoveryCodes={this.state.enrollment.recoveryCodes}
+                        />
+                        <div className="form-group">
+                            <label>Then enter the code shown in your authenticator app</label>
+                            <input
+                                className="form-control"
+                                type="text"
+                                value={this.state.code}
+                                onChange={this.onCodeFieldChange}
+                                required={true}
+                                disabled={this.state.loading}
+                                autoComplete="one-time-code"
+                            />
+                        </div>
+                        <button type="submit" className="btn btn-primary" disabled={this.state.loading}>
+                            Enable multi-factor authentication
+                        </button>
+                    </Form>
+                ) : (
+                    <button
+                        type="button"
+                        className="btn btn-primary"
+                        onClick={this.enroll}
+                        disabled={this.state.loading}
+                    >
+                        Set up multi-factor authentication
+                    </button>
+                )}
+                {this.state.loading && <LoadingSpinner className="icon-inline" />}
+            </div>
+        )
+    }
+
+    private onCodeFieldChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+        this.setState({ code: e.target.value })
+    }
+
+    private onResetCodeFieldChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+        this.setState({ resetCode: e.target.value })
+    }
+
+    private onResetPasswordFieldChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+        this.setState({ resetPassword: e.target.value })
+    }
+
+    private enroll = () => {
+        this.run(enrollTOTP().pipe(tap(enrollment => this.setState({ enrollment, code: '' }))))
+    }
+
+    private confirm = (event: React.FormEvent<HTMLFormElement>) => {
+        event.preventDefault()
+        this.run(
+            confirmTOTPEnrollment(this.state.code).pipe(
+                tap(() => {
+                    this.setState({ enrollment: undefined, code: '' })
+                    this.refreshRequests.next()
+                })
+            )
+        )
+    }
+
+    private reset = (event?: React.FormEvent<HTMLFormElement> | React.MouseEvent<HTMLButtonElement>) => {
+        if (event) {
+            event.preventDefault()
+        }
+        if (!window.confirm('Reset multi-factor authentication? Signing in will no longer require a code.')) {
+            return
+        }
+        // Codes from authenticator apps are 6 digits, and recovery codes contain letters.
+        const code = this.state.resetCode.trim()
+        const credentials = /^\d{6}$/.test(code)
+            ? { code }
+            : code
+            ? { recoveryCode: code }
+            : { password: this.state.resetPassword }
+        this.run(
+            resetTOTP(this.props.user.id, credentials).pipe(
+                tap(() => {
+                    this.setState({ resetCode: '', resetPassword: '' })
+                    this.refreshRequests.next()
+                })
+            )
+        )
+    }
+
+    private run(action: Observable<unknown>): void {
+        this.setState({ loading: true, error: undefined })
+        this.subscriptions.add(
+            action.subscribe(
+                () => this.setState({ loading: false }),
+                err => this.setState({ loading: false, error: asError(err) })
+            )
+        )
+    }
+}
@@ -59,6 +59,85 @@ export function updatePassword(args: { oldPassword: string; newPassword: string
     )
 }
 
+/**
+ * Starts enrolling the current user in multi-factor authentication. The enrollment takes effect when it is
+ * confirmed with {@link confirmTOTPEnrollment}.
+ */
+export function enrollTOTP(): Observable<GQL.ITOTPEnrollment> {
+    return mutateGraphQL(
+        gql`
+            mutation EnrollTOTP {
+                enrollTOTP {
+                    secret
+                    url
+                    recoveryCodes
+                }
+            }
+        `
+    ).pipe(
+        map(({ data, errors }) => {
+            if (!data || !data.enrollTOTP) {
+                throw createAggregateError(errors)
+            }
+            return data.enrollTOTP
+        })
+    )
+}
+
+/**
+ * Confirms the current user's pending multi-factor authentication enrollment with a code from their
+ * authenticator app.
+ */
+export function confirmTOTPEnrollment(code: string): Observable<void> {
+    return mutateGraphQL(
+        gql`
+            mutation ConfirmTOTPEnrollment($code: String!) {
+                confirmTOTPEnrollment(code: $code) {
+                    alwaysNil
+                }
+            }
+        `,
+        { code }
+    ).pipe(
+        map(({ data, errors }) => {
+            if (!data || !data.confirmTOTPEnrollment) {
+                eventLogger.log('ConfirmTOTPEnrollmentFailed')
+                throw createAggregateError(errors)
+            }
+            eventLogger.log('TOTPEnrollmentConfirmed')
+        })
+    )
+}
+
+/**
+ * Resets a user's multi-factor authentication enrollment.
+ *
+ * @param user the user's GraphQL ID
+ * @param credentials a code, recovery code or password of the user (which are not required for site admins)
+ */
+export function resetTOTP(
+    user: GQL.ID,
+    credentials: { code?: string; recoveryCode?: string; password?: string } = {}
+): Observable<void> {
+    return mutateGraphQL(
+        gql`
+            mutation ResetTOTP($user: ID!, $code: String, $recoveryCode: String, $password: String) {
+                resetTOTP(user: $user, code: $code, recoveryCode: $recoveryCode, password: $password) {
+                    alwaysNil
+                }
+            }
+        `,
+        { user, ...credentials }
+    ).pipe(
+        map(({ data, errors }) => {
+            if (!data || !data.resetTOTP) {
+                throw createAggregateError(errors)
+            }
+            eventLogger.log('TOTPReset')
+        })
+    )
+}
+
 /**
  * Set the verification state for a user email address.
  *
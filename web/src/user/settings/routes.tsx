@@ -37,6 +37,12 @@ export const userSettingsAreaRoutes: readonly UserSettingsAreaRoute[] = [
         exact: true,
         render: lazyComponent(() => import('./auth/UserSettingsPasswordPage'), 'UserSettingsPasswordPage'),
     },
+    {
+        path: '/mfa',
+        exact: true,
+        render: lazyComponent(() => import('./auth/UserSettingsMFAPage'), 'UserSettingsMFAPage'),
+        condition: () => window.context.mfaEnabled,
+    },
     {
         path: '/emails',
         exact: true,
@@ -19,6 +19,12 @@ export const userSettingsSideBarItems: UserSettingsSidebarItems = {
             // Only the builtin auth provider has a password.
             condition: ({ authProviders }) => authProviders.some(({ isBuiltin }) => isBuiltin),
         },
+        {
+            label: 'Multi-factor authentication',
+            to: '/mfa',
+            exact: true,
+            condition: () => window.context.mfaEnabled,
+        },
         {
             label: 'Emails',
             to: '/emails',